@@ -68,17 +68,21 @@ func newClient(mattermostSiteURL string, httpClient *http.Client) (*Client, erro
 	return c, nil
 }
 
-// newRequest creates an API request, JSON-encoding any given body parameter.
+// newRequest creates an API request, JSON-encoding any given body parameter that is not an
+// io.Reader, whose content is sent as is.
 func (c *Client) newRequest(method, endpoint string, body interface{}) (*http.Request, error) {
 	u, err := c.BaseURL.Parse(buildAPIURL(endpoint))
 	if err != nil {
 		return nil, errors.Wrapf(err, "invalid endpoint %s", endpoint)
 	}
 
-	var buf io.ReadWriter
-	if body != nil {
-		buf = &bytes.Buffer{}
-		enc := json.NewEncoder(buf)
+	var buf io.Reader
+	if r, ok := body.(io.Reader); ok {
+		buf = r
+	} else if body != nil {
+		encoded := &bytes.Buffer{}
+		buf = encoded
+		enc := json.NewEncoder(encoded)
 		enc.SetEscapeHTML(false)
 		err = enc.Encode(body)
 		if err != nil {
@@ -100,6 +104,16 @@ func (c *Client) newRequest(method, endpoint string, body interface{}) (*http.Re
 	return req, nil
 }
 
+// playbookRunVersionKey is the key of the expected version of a playbook run in a context.
+type playbookRunVersionKey struct{}
+
+// WithPlaybookRunVersion returns a context whose requests modifying a playbook run only succeed if
+// the run is still at the given version, as last read. Otherwise they fail with an ErrorResponse
+// with http.StatusPreconditionFailed, and the run should be read again.
+func WithPlaybookRunVersion(ctx context.Context, version int64) context.Context {
+	return context.WithValue(ctx, playbookRunVersionKey{}, version)
+}
+
 // buildAPIURL constructs the path to the given endpoint.
 func buildAPIURL(endpoint string) string {
 	return fmt.Sprintf("plugins/%s/api/%s/%s", manifestID, apiVersion, endpoint)
@@ -116,6 +130,9 @@ func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) (*htt
 		return nil, errors.New("context must be non-nil")
 	}
 	req = req.WithContext(ctx)
+	if version, ok := ctx.Value(playbookRunVersionKey{}).(int64); ok {
+		req.Header.Set("If-Match", `"`+strconv.FormatInt(version, 10)+`"`)
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -1,29 +1,48 @@
 package client
 
+import "encoding/json"
+
 // Playbook represents the planning before a playbook run is initiated.
 type Playbook struct {
-	ID                            string      `json:"id"`
-	Title                         string      `json:"title"`
-	Description                   string      `json:"description"`
-	TeamID                        string      `json:"team_id"`
-	CreatePublicPlaybookRun       bool        `json:"create_public_playbook_run"`
-	CreateAt                      int64       `json:"create_at"`
-	DeleteAt                      int64       `json:"delete_at"`
-	NumStages                     int64       `json:"num_stages"`
-	NumSteps                      int64       `json:"num_steps"`
-	Checklists                    []Checklist `json:"checklists"`
-	MemberIDs                     []string    `json:"member_ids"`
-	BroadcastChannelID            string      `json:"broadcast_channel_id"`
-	ReminderMessageTemplate       string      `json:"reminder_message_template"`
-	ReminderTimerDefaultSeconds   int64       `json:"reminder_timer_default_seconds"`
-	InvitedUserIDs                []string    `json:"invited_user_ids"`
-	InvitedGroupIDs               []string    `json:"invited_group_ids"`
-	InvitedUsersEnabled           bool        `json:"invited_users_enabled"`
-	DefaultOwnerID                string      `json:"default_owner_id"`
-	DefaultOwnerEnabled           bool        `json:"default_owner_enabled"`
-	AnnouncementChannelID         string      `json:"announcement_channel_id"`
-	AnnouncementChannelEnabled    bool        `json:"announcement_channel_enabled"`
-	ExportChannelOnArchiveEnabled bool        `json:"export_channel_on_archive_enabled"`
+	ID                            string                `json:"id"`
+	Title                         string                `json:"title"`
+	Description                   string                `json:"description"`
+	TeamID                        string                `json:"team_id"`
+	CreatePublicPlaybookRun       bool                  `json:"create_public_playbook_run"`
+	CreatorID                     string                `json:"creator_id"` // Blank if it was created before creators were recorded
+	CreateAt                      int64                 `json:"create_at"`
+	DeleteAt                      int64                 `json:"delete_at"`
+	Revision                      int64                 `json:"revision"` // The number of its latest PlaybookRevision
+	NumStages                     int64                 `json:"num_stages"`
+	NumSteps                      int64                 `json:"num_steps"`
+	Checklists                    []Checklist           `json:"checklists"`
+	MemberIDs                     []string              `json:"member_ids"`
+	BroadcastChannelID            string                `json:"broadcast_channel_id"`
+	ReminderMessageTemplate       string                `json:"reminder_message_template"`
+	ReminderTimerDefaultSeconds   int64                 `json:"reminder_timer_default_seconds"`
+	InvitedUserIDs                []string              `json:"invited_user_ids"`
+	InvitedGroupIDs               []string              `json:"invited_group_ids"`
+	InvitedUsersEnabled           bool                  `json:"invited_users_enabled"`
+	DefaultOwnerID                string                `json:"default_owner_id"`
+	DefaultOwnerEnabled           bool                  `json:"default_owner_enabled"`
+	AnnouncementChannelID         string                `json:"announcement_channel_id"`
+	AnnouncementChannelEnabled    bool                  `json:"announcement_channel_enabled"`
+	WebhookOnCreationURL          string                `json:"webhook_on_creation_url"`
+	WebhookOnCreationEnabled      bool                  `json:"webhook_on_creation_enabled"`
+	WebhookOnStatusUpdateURL      string                `json:"webhook_on_status_update_url"`
+	WebhookOnStatusUpdateEnabled  bool                  `json:"webhook_on_status_update_enabled"`
+	WebhookSecret                 string                `json:"webhook_secret"`     // Used to sign webhook requests, see WebhookSignatureHeader; write-only, so always empty when read
+	HasWebhookSecret              bool                  `json:"has_webhook_secret"` // Keeps the webhook secret on update while WebhookSecret is empty
+	ExportChannelOnArchiveEnabled bool                  `json:"export_channel_on_archive_enabled"`
+	SeverityLevels                []string              `json:"severity_levels"`
+	DefaultSeverity               string                `json:"default_severity"`
+	StatusWorkflow                StatusWorkflow        `json:"status_workflow"`
+	ReminderEscalation            ReminderEscalation    `json:"reminder_escalation"`
+	CustomFields                  []CustomField         `json:"custom_fields"`
+	WebhookSubscriptions          []WebhookSubscription `json:"webhook_subscriptions"`
+	InboundWebhook                InboundWebhook        `json:"inbound_webhook"`
+	HasInboundWebhookToken        bool                  `json:"has_inbound_webhook_token"` // Keeps the inbound webhook token on update while its Token is empty
+	SyncPath                      string                `json:"sync_path"`                 // The file the playbook is synced from, if any
 }
 
 // Checklist represents a checklist in a playbook
@@ -35,37 +54,221 @@ type Checklist struct {
 
 // ChecklistItem represents an item in a checklist
 type ChecklistItem struct {
-	ID                     string `json:"id"`
-	Title                  string `json:"title"`
-	State                  string `json:"state"`
-	StateModified          int64  `json:"state_modified"`
-	StateModifiedPostID    string `json:"state_modified_post_id"`
-	AssigneeID             string `json:"assignee_id"`
-	AssigneeModified       int64  `json:"assignee_modified"`
-	AssigneeModifiedPostID string `json:"assignee_modified_post_id"`
-	Command                string `json:"command"`
-	CommandLastRun         int64  `json:"command_last_run"`
-	Description            string `json:"description"`
+	ID                     string                 `json:"id"`
+	Title                  string                 `json:"title"`
+	State                  string                 `json:"state"`
+	StateModified          int64                  `json:"state_modified"`
+	StateModifiedPostID    string                 `json:"state_modified_post_id"`
+	AssigneeID             string                 `json:"assignee_id"`
+	AssigneeModified       int64                  `json:"assignee_modified"`
+	AssigneeModifiedPostID string                 `json:"assignee_modified_post_id"`
+	Command                string                 `json:"command"`
+	CommandLastRun         int64                  `json:"command_last_run"`
+	Description            string                 `json:"description"`
+	SkipReason             string                 `json:"skip_reason"`
+	DueAt                  int64                  `json:"due_at"`            // The deadline in milliseconds, 0 if none
+	DueAfterSeconds        int64                  `json:"due_after_seconds"` // The deadline relative to DueRelativeTo
+	DueRelativeTo          string                 `json:"due_relative_to"`   // "run", "stage" or empty
+	DependsOn              []string               `json:"depends_on"`        // The IDs of the items that must be closed first
+	Blocked                bool                   `json:"blocked"`           // Whether some of those items are still open
+	CommandTrigger         string                 `json:"command_trigger"`   // "run_start", "stage_start", "status" or empty
+	CommandTriggerStatus   string                 `json:"command_trigger_status"`
+	CommandRunAs           string                 `json:"command_run_as"`       // "owner", "bot" or empty for the owner
+	CommandTriggeredAt     int64                  `json:"command_triggered_at"` // When the command was triggered, 0 if not
+	SubItems               []ChecklistSubItem     `json:"sub_items"`
+	Comments               []ChecklistItemComment `json:"comments"`         // Oldest first
+	CommentsPostID         string                 `json:"comments_post_id"` // The post starting the thread of the comments
+}
+
+// ChecklistSubItem represents a step of a checklist item
+type ChecklistSubItem struct {
+	ID               string `json:"id"`
+	Title            string `json:"title"`
+	State            string `json:"state"`
+	StateModified    int64  `json:"state_modified"`
+	AssigneeID       string `json:"assignee_id"`
+	AssigneeModified int64  `json:"assignee_modified"`
+}
+
+// ChecklistItemComment represents a comment on a checklist item, posted in the playbook run's channel
+type ChecklistItemComment struct {
+	PostID   string `json:"post_id"`
+	UserID   string `json:"user_id"`
+	Message  string `json:"message"`
+	CreateAt int64  `json:"create_at"`
+}
+
+// StatusWorkflow describes the statuses a playbook run can go through. Runs start in the first
+// status of the workflow.
+type StatusWorkflow struct {
+	States []StatusState `json:"states"`
+}
+
+// StatusState represents a named status in a status workflow.
+type StatusState struct {
+	Name        string   `json:"name"`
+	Transitions []string `json:"transitions"`
+	Resolved    bool     `json:"resolved"`
+	Closed      bool     `json:"closed"`
+}
+
+// ReminderEscalation describes how the owner of a playbook run missing status update reminders is
+// escalated: after BackupAfterMissed missed reminders, the backup user and the members of the
+// backup group are sent a DM, and after BroadcastAfterMissed ones, a message is posted in the
+// broadcast channel. A step is skipped when its number of missed reminders is 0.
+type ReminderEscalation struct {
+	BackupAfterMissed    int    `json:"backup_after_missed"`
+	BackupUserID         string `json:"backup_user_id"`
+	BackupGroupID        string `json:"backup_group_id"`
+	BroadcastAfterMissed int    `json:"broadcast_after_missed"`
+}
+
+// CustomFieldType determines which values a custom field accepts.
+type CustomFieldType string
+
+const (
+	CustomFieldTypeText        CustomFieldType = "text"
+	CustomFieldTypeNumber      CustomFieldType = "number"
+	CustomFieldTypeSelect      CustomFieldType = "select"
+	CustomFieldTypeMultiSelect CustomFieldType = "multiselect"
+	CustomFieldTypeUser        CustomFieldType = "user"
+	CustomFieldTypeURL         CustomFieldType = "url"
+)
+
+// CustomField represents a typed field defined by a playbook, whose values are stored per run.
+type CustomField struct {
+	ID       string          `json:"id"`
+	Name     string          `json:"name"`
+	Type     CustomFieldType `json:"type"`
+	Options  []string        `json:"options"`
+	Required bool            `json:"required"`
+}
+
+// WebhookSubscription sends a WebhookEvent to each of its URLs whenever one of its events happens
+// in a run of the playbook.
+type WebhookSubscription struct {
+	Events []WebhookEventType `json:"events"`
+	URLs   []string           `json:"urls"`
+}
+
+// InboundWebhookFormat is the kind of payload a playbook's inbound webhook receives.
+type InboundWebhookFormat string
+
+const (
+	InboundWebhookFormatGeneric      InboundWebhookFormat = "generic"
+	InboundWebhookFormatAlertmanager InboundWebhookFormat = "alertmanager"
+)
+
+// InboundWebhook lets monitoring systems start runs of a playbook by posting alerts to
+// /plugins/{plugin_id}/webhooks/playbooks/{playbook_id}, and post status updates to its runs through
+// /plugins/{plugin_id}/webhooks/runs/{playbook_run_id}/status, authenticated by Token. The fields are
+// dot-separated paths into the alert's JSON; the ones left empty default to the format's paths.
+type InboundWebhook struct {
+	Enabled          bool                 `json:"enabled"`
+	Token            string               `json:"token"` // Generated when enabling the webhook without one; write-only, so always empty when read
+	Format           InboundWebhookFormat `json:"format"`
+	NameField        string               `json:"name_field"`
+	DescriptionField string               `json:"description_field"`
+	SeverityField    string               `json:"severity_field"`
+	FingerprintField string               `json:"fingerprint_field"`
+	ServiceUserID    string               `json:"service_user_id"` // A bot posting the status updates instead of the plugin's bot
 }
 
 // PlaybookCreateOptions specifies the parameters for PlaybooksService.Create method.
 type PlaybookCreateOptions struct {
-	Title                       string      `json:"title"`
-	Description                 string      `json:"description"`
-	TeamID                      string      `json:"team_id"`
-	CreatePublicPlaybookRun     bool        `json:"create_public_playbook_run"`
-	Checklists                  []Checklist `json:"checklists"`
-	MemberIDs                   []string    `json:"member_ids"`
-	BroadcastChannelID          string      `json:"broadcast_channel_id"`
-	ReminderMessageTemplate     string      `json:"reminder_message_template"`
-	ReminderTimerDefaultSeconds int64       `json:"reminder_timer_default_seconds"`
-	InvitedUserIDs              []string    `json:"invited_user_ids"`
-	InvitedGroupIDs             []string    `json:"invited_group_ids"`
-	InviteUsersEnabled          bool        `json:"invite_users_enabled"`
-	DefaultOwnerID              string      `json:"default_owner_id"`
-	DefaultOwnerEnabled         bool        `json:"default_owner_enabled"`
-	AnnouncementChannelID       string      `json:"announcement_channel_id"`
-	AnnouncementChannelEnabled  bool        `json:"announcement_channel_enabled"`
+	Title                        string                `json:"title"`
+	Description                  string                `json:"description"`
+	TeamID                       string                `json:"team_id"`
+	CreatePublicPlaybookRun      bool                  `json:"create_public_playbook_run"`
+	Checklists                   []Checklist           `json:"checklists"`
+	MemberIDs                    []string              `json:"member_ids"`
+	BroadcastChannelID           string                `json:"broadcast_channel_id"`
+	ReminderMessageTemplate      string                `json:"reminder_message_template"`
+	ReminderTimerDefaultSeconds  int64                 `json:"reminder_timer_default_seconds"`
+	InvitedUserIDs               []string              `json:"invited_user_ids"`
+	InvitedGroupIDs              []string              `json:"invited_group_ids"`
+	InviteUsersEnabled           bool                  `json:"invite_users_enabled"`
+	DefaultOwnerID               string                `json:"default_owner_id"`
+	DefaultOwnerEnabled          bool                  `json:"default_owner_enabled"`
+	AnnouncementChannelID        string                `json:"announcement_channel_id"`
+	AnnouncementChannelEnabled   bool                  `json:"announcement_channel_enabled"`
+	WebhookOnCreationURL         string                `json:"webhook_on_creation_url"`
+	WebhookOnCreationEnabled     bool                  `json:"webhook_on_creation_enabled"`
+	WebhookOnStatusUpdateURL     string                `json:"webhook_on_status_update_url"`
+	WebhookOnStatusUpdateEnabled bool                  `json:"webhook_on_status_update_enabled"`
+	WebhookSecret                string                `json:"webhook_secret"` // Used to sign webhook requests, see WebhookSignatureHeader
+	SeverityLevels               []string              `json:"severity_levels"`
+	DefaultSeverity              string                `json:"default_severity"`
+	StatusWorkflow               StatusWorkflow        `json:"status_workflow"`
+	ReminderEscalation           ReminderEscalation    `json:"reminder_escalation"`
+	CustomFields                 []CustomField         `json:"custom_fields"`
+	WebhookSubscriptions         []WebhookSubscription `json:"webhook_subscriptions"`
+	InboundWebhook               InboundWebhook        `json:"inbound_webhook"`
+}
+
+// PlaybookRevision is an immutable snapshot of a playbook, saved each time the playbook is created
+// or updated. Revisions of a playbook are numbered from 1, in the order they were saved.
+type PlaybookRevision struct {
+	PlaybookID string           `json:"playbook_id"`
+	Revision   int64            `json:"revision"`
+	UserID     string           `json:"user_id"`
+	CreateAt   int64            `json:"create_at"`
+	Changes    []PlaybookChange `json:"changes"`            // Compared to the previous revision
+	Playbook   *Playbook        `json:"playbook,omitempty"` // Left out of revision lists
+}
+
+// PlaybookChange is a setting of a playbook that changed between two revisions, with its old and
+// new values as JSON. The values of secrets are left out.
+type PlaybookChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+// PlaybookExportFormat is the encoding of a playbook export.
+type PlaybookExportFormat string
+
+const (
+	PlaybookExportFormatJSON PlaybookExportFormat = "json"
+	PlaybookExportFormatYAML PlaybookExportFormat = "yaml"
+)
+
+// PlaybookImportResult is the result of the PlaybooksService.Import and Duplicate methods: the ID
+// of the new playbook, and the settings that could not be carried over.
+type PlaybookImportResult struct {
+	ID       string   `json:"id"`
+	Warnings []string `json:"warnings"`
+}
+
+// PlaybookSyncAction is what syncing does to a playbook.
+type PlaybookSyncAction string
+
+const (
+	PlaybookSyncActionCreate PlaybookSyncAction = "create"
+	PlaybookSyncActionUpdate PlaybookSyncAction = "update"
+	PlaybookSyncActionDelete PlaybookSyncAction = "delete"
+	PlaybookSyncActionNone   PlaybookSyncAction = "none"
+)
+
+// PlaybookSyncResult is the result of the PlaybooksService.Sync method: what was done, or would be
+// done for a dry run, to each synced playbook of the team.
+type PlaybookSyncResult struct {
+	DryRun    bool             `json:"dry_run"`
+	Playbooks []SyncedPlaybook `json:"playbooks"`
+}
+
+// SyncedPlaybook is the outcome of syncing a playbook with its file.
+type SyncedPlaybook struct {
+	Path       string             `json:"path"`
+	PlaybookID string             `json:"playbook_id"`
+	Title      string             `json:"title"`
+	Action     PlaybookSyncAction `json:"action"`
+	Changes    []PlaybookChange   `json:"changes"`
+	Warnings   []string           `json:"warnings"`
+
+	// InboundWebhookToken is the token generated by the sync for the playbook's inbound webhook,
+	// if any. Tokens are write-only, so this is the only time it is returned.
+	InboundWebhookToken string `json:"inbound_webhook_token,omitempty"`
 }
 
 // PlaybookListOptions specifies the optional parameters to the
@@ -73,6 +276,21 @@ type PlaybookCreateOptions struct {
 type PlaybookListOptions struct {
 	Sort      Sort          `url:"sort,omitempty"`
 	Direction SortDirection `url:"direction,omitempty"`
+
+	// SearchTerm filters playbooks whose title or description contains the term, ignoring case.
+	SearchTerm string `url:"search_term,omitempty"`
+
+	// MemberID filters playbooks that have this member.
+	MemberID string `url:"member_id,omitempty"`
+
+	// CreatorID filters playbooks created by this user.
+	CreatorID string `url:"creator_id,omitempty"`
+
+	// KeywordsEnabled filters playbooks by whether their keywords are enabled, if not nil.
+	KeywordsEnabled *bool `url:"keywords_enabled,omitempty"`
+
+	// IncludeArchived includes the deleted playbooks.
+	IncludeArchived bool `url:"include_archived,omitempty"`
 }
 
 type GetPlaybooksResults struct {
@@ -4,30 +4,45 @@ import "time"
 
 // PlaybookRun represents a playbook run.
 type PlaybookRun struct {
-	ID                            string          `json:"id"`
-	Name                          string          `json:"name"`
-	Description                   string          `json:"description"`
-	OwnerUserID                   string          `json:"owner_user_id"`
-	ReporterUserID                string          `json:"reporter_user_id"`
-	TeamID                        string          `json:"team_id"`
-	ChannelID                     string          `json:"channel_id"`
-	CreateAt                      int64           `json:"create_at"`
-	EndAt                         int64           `json:"end_at"`
-	DeleteAt                      int64           `json:"delete_at"`
-	ActiveStage                   int             `json:"active_stage"`
-	ActiveStageTitle              string          `json:"active_stage_title"`
-	PostID                        string          `json:"post_id"`
-	PlaybookID                    string          `json:"playbook_id"`
-	Checklists                    []Checklist     `json:"checklists"`
-	StatusPosts                   []StatusPost    `json:"status_posts"`
-	ReminderPostID                string          `json:"reminder_post_id"`
-	PreviousReminder              time.Duration   `json:"previous_reminder"`
-	BroadcastChannelID            string          `json:"broadcast_channel_id"`
-	ReminderMessageTemplate       string          `json:"reminder_message_template"`
-	InvitedUserIDs                []string        `json:"invited_user_ids"`
-	InvitedGroupIDs               []string        `json:"invited_group_ids"`
-	TimelineEvents                []TimelineEvent `json:"timeline_events"`
-	ExportChannelOnArchiveEnabled bool            `json:"export_channel_on_archive_enabled"`
+	ID                                   string                `json:"id"`
+	Name                                 string                `json:"name"`
+	Description                          string                `json:"description"`
+	OwnerUserID                          string                `json:"owner_user_id"`
+	ReporterUserID                       string                `json:"reporter_user_id"`
+	TeamID                               string                `json:"team_id"`
+	ChannelID                            string                `json:"channel_id"`
+	CreateAt                             int64                 `json:"create_at"`
+	EndAt                                int64                 `json:"end_at"`
+	DeleteAt                             int64                 `json:"delete_at"`
+	ActiveStage                          int                   `json:"active_stage"`
+	ActiveStageTitle                     string                `json:"active_stage_title"`
+	PostID                               string                `json:"post_id"`
+	PlaybookID                           string                `json:"playbook_id"`
+	PlaybookRevision                     int64                 `json:"playbook_revision"` // The revision of the playbook the run was started from
+	Checklists                           []Checklist           `json:"checklists"`
+	StatusPosts                          []StatusPost          `json:"status_posts"`
+	ReminderPostID                       string                `json:"reminder_post_id"`
+	PreviousReminder                     time.Duration         `json:"previous_reminder"`
+	ReminderCount                        int                   `json:"reminder_count"` // The number of status update reminders posted since the last status update
+	ReminderEscalation                   ReminderEscalation    `json:"reminder_escalation"`
+	BroadcastChannelID                   string                `json:"broadcast_channel_id"`
+	ReminderMessageTemplate              string                `json:"reminder_message_template"`
+	InvitedUserIDs                       []string              `json:"invited_user_ids"`
+	InvitedGroupIDs                      []string              `json:"invited_group_ids"`
+	TimelineEvents                       []TimelineEvent       `json:"timeline_events"`
+	WebhookOnCreationURL                 string                `json:"webhook_on_creation_url"`
+	WebhookOnStatusUpdateURL             string                `json:"webhook_on_status_update_url"`
+	RetrospectiveReminderIntervalSeconds int64                 `json:"retrospective_reminder_interval_seconds"`
+	MessageOnJoin                        string                `json:"message_on_join"`
+	ExportChannelOnArchiveEnabled        bool                  `json:"export_channel_on_archive_enabled"`
+	Severity                             string                `json:"severity"`
+	SeverityLevels                       []string              `json:"severity_levels"`
+	StatusWorkflow                       StatusWorkflow        `json:"status_workflow"`
+	CustomFields                         []CustomField         `json:"custom_fields"`
+	CustomFieldValues                    map[string][]string   `json:"custom_field_values"`
+	WebhookSubscriptions                 []WebhookSubscription `json:"webhook_subscriptions"`
+	AlertFingerprint                     string                `json:"alert_fingerprint"` // Set when started by an alert of the playbook's inbound webhook
+	Version                              int64                 `json:"version"`           // Incremented on every update, see WithPlaybookRunVersion
 }
 
 // StatusPost is information added to the playbook run when selecting from the db and sent to the
@@ -57,6 +72,9 @@ const (
 	OwnerChanged       TimelineEventType = "owner_changed"
 	AssigneeChanged    TimelineEventType = "assignee_changed"
 	RanSlashCommand    TimelineEventType = "ran_slash_command"
+	RunUpdated         TimelineEventType = "run_updated"
+	SeverityChanged    TimelineEventType = "severity_changed"
+	ReminderEscalated  TimelineEventType = "reminder_escalated"
 )
 
 // TimelineEvent represents an event recorded to a playbook run's timeline.
@@ -74,6 +92,33 @@ type TimelineEvent struct {
 	CreatorUserID string            `json:"creator_user_id"`
 }
 
+// WebhookDeliveryStatus is the state of a webhook delivery.
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery represents an outgoing webhook request of a playbook run, along with the
+// outcome of the attempts made to deliver it.
+type WebhookDelivery struct {
+	ID             string                `json:"id"`
+	PlaybookRunID  string                `json:"playbook_run_id"`
+	EventType      string                `json:"event_type"`
+	URL            string                `json:"url"`
+	Payload        string                `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	LastStatusCode int                   `json:"last_status_code"`
+	LastLatencyMs  int64                 `json:"last_latency_ms"`
+	LastError      string                `json:"last_error"`
+	NextAttemptAt  int64                 `json:"next_attempt_at"`
+	CreateAt       int64                 `json:"create_at"`
+	UpdateAt       int64                 `json:"update_at"`
+}
+
 // PlaybookRunCreateOptions specifies the parameters for PlaybookRunService.Create method.
 type PlaybookRunCreateOptions struct {
 	Name        string `json:"name"`
@@ -82,6 +127,69 @@ type PlaybookRunCreateOptions struct {
 	Description string `json:"description"`
 	PostID      string `json:"post_id"`
 	PlaybookID  string `json:"playbook_id"`
+
+	// CustomFieldValues holds the values of the playbook's custom fields, keyed by custom field ID.
+	CustomFieldValues map[string][]string `json:"custom_field_values,omitempty"`
+}
+
+// PlaybookRunUpdateOptions specifies the parameters for PlaybookRunService.Update method.
+// Fields left nil are not modified.
+type PlaybookRunUpdateOptions struct {
+	Name                                 *string `json:"name,omitempty"`
+	Description                          *string `json:"description,omitempty"`
+	BroadcastChannelID                   *string `json:"broadcast_channel_id,omitempty"`
+	ReminderMessageTemplate              *string `json:"reminder_message_template,omitempty"`
+	WebhookOnCreationURL                 *string `json:"webhook_on_creation_url,omitempty"`
+	WebhookOnStatusUpdateURL             *string `json:"webhook_on_status_update_url,omitempty"`
+	MessageOnJoin                        *string `json:"message_on_join,omitempty"`
+	RetrospectiveReminderIntervalSeconds *int64  `json:"retrospective_reminder_interval_seconds,omitempty"`
+
+	// CustomFieldValues sets the values of the given custom fields, keyed by custom field ID.
+	// Fields left out are not modified; an empty list clears the field.
+	CustomFieldValues map[string][]string `json:"custom_field_values,omitempty"`
+}
+
+// ChecklistOperationType enumerates the changes a checklist operation can make.
+type ChecklistOperationType string
+
+const (
+	// ChecklistOperationSetState moves an item to NewState. Skipping it requires a Reason.
+	ChecklistOperationSetState ChecklistOperationType = "set_state"
+
+	// ChecklistOperationSetAssignee assigns an item to AssigneeID, or unassigns it if empty.
+	ChecklistOperationSetAssignee ChecklistOperationType = "set_assignee"
+
+	// ChecklistOperationEdit replaces the Title, Command and Description of an item.
+	ChecklistOperationEdit ChecklistOperationType = "edit"
+
+	// ChecklistOperationMove moves an item to NewLocation in its checklist.
+	ChecklistOperationMove ChecklistOperationType = "move"
+
+	// ChecklistOperationAdd appends Item to a checklist, open and unassigned.
+	ChecklistOperationAdd ChecklistOperationType = "add"
+)
+
+// ChecklistOperation is one change to the checklists of a playbook run, applied with
+// PlaybookRunService.BulkUpdateChecklists. The indices of an operation refer to the checklists as
+// left by the operations before it.
+type ChecklistOperation struct {
+	Type         ChecklistOperationType `json:"type"`
+	ChecklistNum int                    `json:"checklist_num"`
+	ItemNum      int                    `json:"item_num"`
+
+	NewState string `json:"new_state,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Override bool   `json:"override,omitempty"`
+
+	AssigneeID string `json:"assignee_id,omitempty"`
+
+	Title       string `json:"title,omitempty"`
+	Command     string `json:"command,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	NewLocation int `json:"new_location,omitempty"`
+
+	Item *ChecklistItem `json:"item,omitempty"`
 }
 
 // Sort enumerates the available fields we can sort on.
@@ -106,6 +214,9 @@ const (
 	// SortByEndAt sorts by the "end_at" field.
 	SortByEndAt Sort = "end_at"
 
+	// SortBySeverity sorts playbook runs by their severity, relative to their severity levels.
+	SortBySeverity Sort = "severity"
+
 	// SortBySteps sorts playbooks by the number of steps in the playbook.
 	SortBySteps Sort = "steps"
 
@@ -114,6 +225,12 @@ const (
 
 	// SortByTitle sorts by the "title" field.
 	SortByTitle Sort = "title"
+
+	// SortByDueAt sorts checklist items by their due date, with the items with no due date last.
+	SortByDueAt Sort = "due_at"
+
+	// SortByAssignedAt sorts checklist items by when they were assigned, i.e. by their age.
+	SortByAssignedAt Sort = "assigned_at"
 )
 
 // SortDirection determines whether results are sorted ascending or descending.
@@ -139,6 +256,10 @@ type PlaybookRunListOptions struct {
 	// Status filters by All, Ongoing, or Ended; defaults to All.
 	Status Status `url:"status,omitempty"`
 
+	// ExcludeClosed filters out playbook runs whose current status is flagged as closed in their
+	// status workflow. Defaults to false (no filter).
+	ExcludeClosed bool `url:"exclude_closed,omitempty"`
+
 	// OwnerID filters by owner's Mattermost user ID. Defaults to blank (no filter).
 	OwnerID string `url:"owner_user_id,omitempty"`
 
@@ -154,6 +275,15 @@ type PlaybookRunListOptions struct {
 	// Defaults to blank (no filter).
 	PlaybookID string `url:"playbook_id,omitempty"`
 
+	// Severity filters playbook runs that currently have this severity.
+	// Defaults to blank (no filter).
+	Severity string `url:"severity,omitempty"`
+
+	// CustomFieldID and CustomFieldValue filter playbook runs that have this value in the custom
+	// field with this ID. They must be given together. Defaults to blank (no filter).
+	CustomFieldID    string `url:"custom_field_id,omitempty"`
+	CustomFieldValue string `url:"custom_field_value,omitempty"`
+
 	// ActiveGTE filters playbook runs that were active after (or equal) to the unix time given (in millis).
 	// A value of 0 means the filter is ignored (which is the default).
 	ActiveGTE int64 `url:"active_gte,omitempty"`
@@ -171,6 +301,34 @@ type PlaybookRunListOptions struct {
 	StartedLT int64 `url:"started_lt,omitempty"`
 }
 
+// TaskListOptions specifies the optional parameters to the PlaybookRunService.ListTasks method.
+type TaskListOptions struct {
+	// TeamID filters tasks to those of the playbook runs in the given team.
+	TeamID string `url:"team_id,omitempty"`
+
+	// States filters by the given checklist item states, where "open" stands for the open state.
+	// Defaults to the open and in_progress states.
+	States []string `url:"state,omitempty"`
+
+	// Sort sorts by SortByDueAt or SortByAssignedAt; defaults to SortByDueAt.
+	Sort      Sort          `url:"sort,omitempty"`
+	Direction SortDirection `url:"direction,omitempty"`
+}
+
+// Task is a checklist item assigned to the user, along with its position in its playbook run and
+// the run's channel.
+type Task struct {
+	ChecklistItem
+	PlaybookRunID   string `json:"playbook_run_id"`
+	PlaybookRunName string `json:"playbook_run_name"`
+	TeamID          string `json:"team_id"`
+	ChannelID       string `json:"channel_id"`
+	ChannelName     string `json:"channel_name"`
+	ChecklistNum    int    `json:"checklist_num"`
+	ChecklistTitle  string `json:"checklist_title"`
+	ItemNum         int    `json:"item_num"`
+}
+
 // PlaybookRunList contains the paginated result.
 type PlaybookRunList struct {
 	TotalCount int  `json:"total_count"`
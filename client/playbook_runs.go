@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 )
 
 // PlaybookRunService handles communication with the playbook run related
@@ -96,6 +97,29 @@ func (s *PlaybookRunService) List(ctx context.Context, page, perPage int, opts P
 	return result, nil
 }
 
+// ListTasks returns the open checklist items assigned to the user in the active playbook runs
+// they can view.
+func (s *PlaybookRunService) ListTasks(ctx context.Context, opts TaskListOptions) ([]Task, error) {
+	tasksURL, err := addOptions("runs/tasks", opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build options: %w", err)
+	}
+
+	req, err := s.client.newRequest(http.MethodGet, tasksURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	var tasks []Task
+	resp, err := s.client.do(ctx, req, &tasks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	resp.Body.Close()
+
+	return tasks, nil
+}
+
 // Create a playbook run.
 func (s *PlaybookRunService) Create(ctx context.Context, opts PlaybookRunCreateOptions) (*PlaybookRun, error) {
 	playbookRunURL := "runs"
@@ -118,6 +142,46 @@ func (s *PlaybookRunService) Create(ctx context.Context, opts PlaybookRunCreateO
 	return playbookRun, nil
 }
 
+// Update modifies the given fields of a playbook run and returns the updated playbook run.
+func (s *PlaybookRunService) Update(ctx context.Context, playbookRunID string, opts PlaybookRunUpdateOptions) (*PlaybookRun, error) {
+	playbookRunURL := fmt.Sprintf("runs/%s", playbookRunID)
+	req, err := s.client.newRequest(http.MethodPatch, playbookRunURL, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	playbookRun := new(PlaybookRun)
+	resp, err := s.client.do(ctx, req, playbookRun)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return playbookRun, nil
+}
+
+// UpdateSeverity changes the severity of a playbook run to one of its severity levels, or clears
+// it when severity is empty.
+func (s *PlaybookRunService) UpdateSeverity(ctx context.Context, playbookRunID, severity string) error {
+	severityURL := fmt.Sprintf("runs/%s/severity", playbookRunID)
+	opts := struct {
+		Severity string `json:"severity"`
+	}{
+		Severity: severity,
+	}
+	req, err := s.client.newRequest(http.MethodPost, severityURL, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (s *PlaybookRunService) UpdateStatus(ctx context.Context, playbookRunID string, status Status, description, message string, reminderInSeconds int64) error {
 	updateURL := fmt.Sprintf("runs/%s/status", playbookRunID)
 	opts := StatusUpdateOptions{
@@ -138,3 +202,231 @@ func (s *PlaybookRunService) UpdateStatus(ctx context.Context, playbookRunID str
 
 	return nil
 }
+
+// SetChecklistItemDueDate sets the deadline of a checklist item of a playbook run, in milliseconds,
+// replacing its relative deadline if any. A zero dueAt removes the deadline.
+func (s *PlaybookRunService) SetChecklistItemDueDate(ctx context.Context, playbookRunID string, checklistNum, itemNum int, dueAt int64) error {
+	dueURL := fmt.Sprintf("runs/%s/checklists/%d/item/%d/due", playbookRunID, checklistNum, itemNum)
+	opts := struct {
+		DueAt int64 `json:"due_at"`
+	}{
+		DueAt: dueAt,
+	}
+	req, err := s.client.newRequest(http.MethodPut, dueURL, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetChecklistItemDependencies replaces the IDs of the items of a playbook run that must be closed
+// before one of its checklist items can be.
+func (s *PlaybookRunService) SetChecklistItemDependencies(ctx context.Context, playbookRunID string, checklistNum, itemNum int, dependsOn []string) error {
+	dependenciesURL := fmt.Sprintf("runs/%s/checklists/%d/item/%d/dependencies", playbookRunID, checklistNum, itemNum)
+	opts := struct {
+		DependsOn []string `json:"depends_on"`
+	}{
+		DependsOn: dependsOn,
+	}
+	req, err := s.client.newRequest(http.MethodPut, dependenciesURL, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SkipChecklistItem skips a checklist item of a playbook run. A reason is required, and it is
+// recorded in the run's timeline.
+func (s *PlaybookRunService) SkipChecklistItem(ctx context.Context, playbookRunID string, checklistNum, itemNum int, reason string) error {
+	stateURL := fmt.Sprintf("runs/%s/checklists/%d/item/%d/state", playbookRunID, checklistNum, itemNum)
+	opts := struct {
+		NewState string `json:"new_state"`
+		Reason   string `json:"reason"`
+	}{
+		NewState: "skipped",
+		Reason:   reason,
+	}
+	req, err := s.client.newRequest(http.MethodPut, stateURL, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// BulkUpdateChecklists applies the operations to the checklists of a playbook run at once: either
+// all of them succeed, or none is applied.
+func (s *PlaybookRunService) BulkUpdateChecklists(ctx context.Context, playbookRunID string, operations []ChecklistOperation) error {
+	bulkURL := fmt.Sprintf("runs/%s/checklists/bulk", playbookRunID)
+	opts := struct {
+		Operations []ChecklistOperation `json:"operations"`
+	}{
+		Operations: operations,
+	}
+	req, err := s.client.newRequest(http.MethodPost, bulkURL, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddChecklistSubItem adds a sub-item to a checklist item of a playbook run.
+func (s *PlaybookRunService) AddChecklistSubItem(ctx context.Context, playbookRunID string, checklistNum, itemNum int, subItem ChecklistSubItem) error {
+	subItemsURL := fmt.Sprintf("runs/%s/checklists/%d/item/%d/subitems", playbookRunID, checklistNum, itemNum)
+	req, err := s.client.newRequest(http.MethodPost, subItemsURL, subItem)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RemoveChecklistSubItem removes a sub-item from a checklist item of a playbook run.
+func (s *PlaybookRunService) RemoveChecklistSubItem(ctx context.Context, playbookRunID string, checklistNum, itemNum, subItemNum int) error {
+	subItemURL := fmt.Sprintf("runs/%s/checklists/%d/item/%d/subitems/%d", playbookRunID, checklistNum, itemNum, subItemNum)
+	req, err := s.client.newRequest(http.MethodDelete, subItemURL, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetChecklistSubItemState sets the state of a sub-item of a checklist item of a playbook run.
+func (s *PlaybookRunService) SetChecklistSubItemState(ctx context.Context, playbookRunID string, checklistNum, itemNum, subItemNum int, state string) error {
+	stateURL := fmt.Sprintf("runs/%s/checklists/%d/item/%d/subitems/%d/state", playbookRunID, checklistNum, itemNum, subItemNum)
+	opts := struct {
+		NewState string `json:"new_state"`
+	}{
+		NewState: state,
+	}
+	req, err := s.client.newRequest(http.MethodPut, stateURL, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetChecklistSubItemAssignee sets the assignee of a sub-item of a checklist item of a playbook
+// run. An empty assigneeID removes the assignee.
+func (s *PlaybookRunService) SetChecklistSubItemAssignee(ctx context.Context, playbookRunID string, checklistNum, itemNum, subItemNum int, assigneeID string) error {
+	assigneeURL := fmt.Sprintf("runs/%s/checklists/%d/item/%d/subitems/%d/assignee", playbookRunID, checklistNum, itemNum, subItemNum)
+	opts := struct {
+		AssigneeID string `json:"assignee_id"`
+	}{
+		AssigneeID: assigneeID,
+	}
+	req, err := s.client.newRequest(http.MethodPut, assigneeURL, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddChecklistItemComment comments on a checklist item of a playbook run, and returns the comment.
+func (s *PlaybookRunService) AddChecklistItemComment(ctx context.Context, playbookRunID string, checklistNum, itemNum int, message string) (*ChecklistItemComment, error) {
+	commentsURL := fmt.Sprintf("runs/%s/checklists/%d/item/%d/comments", playbookRunID, checklistNum, itemNum)
+	opts := struct {
+		Message string `json:"message"`
+	}{
+		Message: message,
+	}
+	req, err := s.client.newRequest(http.MethodPost, commentsURL, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := new(ChecklistItemComment)
+	resp, err := s.client.do(ctx, req, comment)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return comment, nil
+}
+
+// GetWebhookDeliveries returns the webhook deliveries of a playbook run, most recent first. An
+// empty status returns deliveries in any status.
+func (s *PlaybookRunService) GetWebhookDeliveries(ctx context.Context, playbookRunID string, status WebhookDeliveryStatus) ([]WebhookDelivery, error) {
+	deliveriesURL := fmt.Sprintf("runs/%s/webhooks", playbookRunID)
+	if status != "" {
+		deliveriesURL += "?status=" + url.QueryEscape(string(status))
+	}
+	req, err := s.client.newRequest(http.MethodGet, deliveriesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := []WebhookDelivery{}
+	resp, err := s.client.do(ctx, req, &deliveries)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return deliveries, nil
+}
+
+// RedeliverWebhook queues a new delivery of the payload of a failed webhook delivery, and returns it.
+func (s *PlaybookRunService) RedeliverWebhook(ctx context.Context, playbookRunID, deliveryID string) (*WebhookDelivery, error) {
+	redeliverURL := fmt.Sprintf("runs/%s/webhooks/%s/redeliver", playbookRunID, deliveryID)
+	req, err := s.client.newRequest(http.MethodPost, redeliverURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := new(WebhookDelivery)
+	resp, err := s.client.do(ctx, req, delivery)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return delivery, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/client"
 	"github.com/mattermost/mattermost-server/v5/model"
@@ -72,3 +73,153 @@ func ExamplePlaybookRunService_List() {
 		fmt.Printf("Playbook Run Name: %s\n", playbookRun.Name)
 	}
 }
+
+func ExamplePlaybookRunService_Update() {
+	ctx := context.Background()
+
+	client4 := model.NewAPIv4Client("http://localhost:8065")
+	client4.Login("test@example.com", "testtest")
+
+	c, err := client.New(client4)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	playbookRunID := "h4n3h7s1qjf5pkis4dn6cuxgwa"
+	playbookRun, err := c.PlaybookRuns.Update(ctx, playbookRunID, client.PlaybookRunUpdateOptions{
+		Name:        model.NewString("Database outage"),
+		Description: model.NewString("Primary database is unreachable."),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Playbook Run Name: %s\n", playbookRun.Name)
+}
+
+func ExamplePlaybookRunService_UpdateSeverity() {
+	ctx := context.Background()
+
+	client4 := model.NewAPIv4Client("http://localhost:8065")
+	client4.Login("test@example.com", "testtest")
+
+	c, err := client.New(client4)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	playbookRunID := "h4n3h7s1qjf5pkis4dn6cuxgwa"
+	if err := c.PlaybookRuns.UpdateSeverity(ctx, playbookRunID, "SEV-1"); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExamplePlaybookRunService_SetChecklistItemDueDate() {
+	ctx := context.Background()
+
+	client4 := model.NewAPIv4Client("http://localhost:8065")
+	client4.Login("test@example.com", "testtest")
+
+	c, err := client.New(client4)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	playbookRunID := "h4n3h7s1qjf5pkis4dn6cuxgwa"
+	dueAt := time.Now().Add(time.Hour).UnixNano() / int64(time.Millisecond)
+	if err := c.PlaybookRuns.SetChecklistItemDueDate(ctx, playbookRunID, 0, 0, dueAt); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExamplePlaybookRunService_AddChecklistSubItem() {
+	ctx := context.Background()
+
+	client4 := model.NewAPIv4Client("http://localhost:8065")
+	client4.Login("test@example.com", "testtest")
+
+	c, err := client.New(client4)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	playbookRunID := "h4n3h7s1qjf5pkis4dn6cuxgwa"
+	subItem := client.ChecklistSubItem{Title: "Roll back the database migration"}
+	if err := c.PlaybookRuns.AddChecklistSubItem(ctx, playbookRunID, 0, 0, subItem); err != nil {
+		log.Fatal(err)
+	}
+
+	if _, err := c.PlaybookRuns.AddChecklistItemComment(ctx, playbookRunID, 0, 0, "Waiting on the DBA."); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExamplePlaybookRunService_BulkUpdateChecklists() {
+	ctx := context.Background()
+
+	client4 := model.NewAPIv4Client("http://localhost:8065")
+	client4.Login("test@example.com", "testtest")
+
+	c, err := client.New(client4)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	playbookRunID := "h4n3h7s1qjf5pkis4dn6cuxgwa"
+	operations := []client.ChecklistOperation{
+		{Type: client.ChecklistOperationSetState, ChecklistNum: 0, ItemNum: 0, NewState: "closed"},
+		{Type: client.ChecklistOperationSetState, ChecklistNum: 0, ItemNum: 1, NewState: "skipped", Reason: "Not a customer-facing incident"},
+		{Type: client.ChecklistOperationSetAssignee, ChecklistNum: 1, ItemNum: 0, AssigneeID: "jrrnicdkjtbf8q4hy5ew6wfz8w"},
+		{Type: client.ChecklistOperationAdd, ChecklistNum: 1, Item: &client.ChecklistItem{Title: "Write the postmortem"}},
+	}
+	if err := c.PlaybookRuns.BulkUpdateChecklists(ctx, playbookRunID, operations); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExamplePlaybookRunService_RedeliverWebhook() {
+	ctx := context.Background()
+
+	client4 := model.NewAPIv4Client("http://localhost:8065")
+	client4.Login("test@example.com", "testtest")
+
+	c, err := client.New(client4)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	playbookRunID := "h4n3h7s1qjf5pkis4dn6cuxgwa"
+	deliveries, err := c.PlaybookRuns.GetWebhookDeliveries(ctx, playbookRunID, client.WebhookDeliveryFailed)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, delivery := range deliveries {
+		if _, err := c.PlaybookRuns.RedeliverWebhook(ctx, playbookRunID, delivery.ID); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func ExamplePlaybookRunService_ListTasks() {
+	ctx := context.Background()
+
+	client4 := model.NewAPIv4Client("http://localhost:8065")
+	client4.Login("test@example.com", "testtest")
+
+	c, err := client.New(client4)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tasks, err := c.PlaybookRuns.ListTasks(ctx, client.TaskListOptions{
+		Sort: client.SortByDueAt,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, task := range tasks {
+		fmt.Printf("%s (~%s): %s\n", task.PlaybookRunName, task.ChannelName, task.Title)
+	}
+}
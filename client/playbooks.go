@@ -4,6 +4,7 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"net/http"
@@ -117,3 +118,194 @@ func (s *PlaybooksService) Delete(ctx context.Context, playbookID string) error
 
 	return nil
 }
+
+// Undelete restores a deleted playbook.
+func (s *PlaybooksService) Undelete(ctx context.Context, playbookID string) error {
+	undeleteURL := fmt.Sprintf("playbooks/%s/undelete", playbookID)
+	req, err := s.client.newRequest(http.MethodPost, undeleteURL, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Export a playbook in the given format, referring to users, groups and channels by name so that
+// it can be imported into another team or server. Secrets are not exported.
+func (s *PlaybooksService) Export(ctx context.Context, playbookID string, format PlaybookExportFormat) ([]byte, error) {
+	exportURL := fmt.Sprintf("playbooks/%s/export", playbookID)
+	exportURL, err := addOption(exportURL, "format", string(format))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build options: %w", err)
+	}
+
+	req, err := s.client.newRequest(http.MethodGet, exportURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var export bytes.Buffer
+	resp, err := s.client.do(ctx, req, &export)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return export.Bytes(), nil
+}
+
+// Import a playbook export of the given format into a team.
+func (s *PlaybooksService) Import(ctx context.Context, teamID string, format PlaybookExportFormat, export []byte) (*PlaybookImportResult, error) {
+	importURL, err := addOption("playbooks/import", "team_id", teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build options: %w", err)
+	}
+	importURL, err = addOption(importURL, "format", string(format))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build options: %w", err)
+	}
+
+	req, err := s.client.newRequest(http.MethodPost, importURL, bytes.NewReader(export))
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(PlaybookImportResult)
+	resp, err := s.client.do(ctx, req, result)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("expected status code %d", http.StatusCreated)
+	}
+
+	return result, nil
+}
+
+// Duplicate a playbook into a team, or into its own team if teamID is empty. Channels and users
+// are remapped to those of the same name in the team.
+func (s *PlaybooksService) Duplicate(ctx context.Context, playbookID, teamID string) (*PlaybookImportResult, error) {
+	duplicateURL := fmt.Sprintf("playbooks/%s/duplicate", playbookID)
+	if teamID != "" {
+		var err error
+		duplicateURL, err = addOption(duplicateURL, "team_id", teamID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build options: %w", err)
+		}
+	}
+
+	req, err := s.client.newRequest(http.MethodPost, duplicateURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(PlaybookImportResult)
+	resp, err := s.client.do(ctx, req, result)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("expected status code %d", http.StatusCreated)
+	}
+
+	return result, nil
+}
+
+// Sync syncs the playbooks of a team with a zip archive of playbook files, or with the team's
+// directory on the server if archive is nil. Only system admins may sync playbooks.
+func (s *PlaybooksService) Sync(ctx context.Context, teamID string, dryRun bool, archive []byte) (*PlaybookSyncResult, error) {
+	syncURL, err := addOption("playbooks/sync", "team_id", teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build options: %w", err)
+	}
+	if dryRun {
+		syncURL, err = addOption(syncURL, "dry_run", "true")
+		if err != nil {
+			return nil, fmt.Errorf("failed to build options: %w", err)
+		}
+	}
+
+	var req *http.Request
+	if archive != nil {
+		req, err = s.client.newRequest(http.MethodPost, syncURL, bytes.NewReader(archive))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/zip")
+		}
+	} else {
+		req, err = s.client.newRequest(http.MethodPost, syncURL, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(PlaybookSyncResult)
+	resp, err := s.client.do(ctx, req, result)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return result, nil
+}
+
+// GetRevisions lists the revisions of a playbook, most recent first, without their snapshots.
+func (s *PlaybooksService) GetRevisions(ctx context.Context, playbookID string) ([]PlaybookRevision, error) {
+	revisionsURL := fmt.Sprintf("playbooks/%s/revisions", playbookID)
+	req, err := s.client.newRequest(http.MethodGet, revisionsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []PlaybookRevision
+	resp, err := s.client.do(ctx, req, &revisions)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return revisions, nil
+}
+
+// GetRevision gets a revision of a playbook, with the snapshot of the playbook.
+func (s *PlaybooksService) GetRevision(ctx context.Context, playbookID string, revision int64) (*PlaybookRevision, error) {
+	revisionURL := fmt.Sprintf("playbooks/%s/revisions/%d", playbookID, revision)
+	req, err := s.client.newRequest(http.MethodGet, revisionURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	playbookRevision := new(PlaybookRevision)
+	resp, err := s.client.do(ctx, req, playbookRevision)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return playbookRevision, nil
+}
+
+// RestoreRevision updates a playbook to its settings in a revision, saving them as a new revision.
+// Secrets are not restored.
+func (s *PlaybooksService) RestoreRevision(ctx context.Context, playbookID string, revision int64) error {
+	restoreURL := fmt.Sprintf("playbooks/%s/revisions/%d/restore", playbookID, revision)
+	req, err := s.client.newRequest(http.MethodPost, restoreURL, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
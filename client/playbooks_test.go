@@ -71,3 +71,33 @@ func ExamplePlaybooksService_List() {
 		fmt.Printf("Playbook Name: %s\n", playbook.Title)
 	}
 }
+
+func ExamplePlaybooksService_Export() {
+	ctx := context.Background()
+
+	client4 := model.NewAPIv4Client("http://localhost:8065")
+	_, response := client4.Login("test@example.com", "testtest")
+	if response.Error != nil {
+		log.Fatal(response.Error.Error())
+	}
+
+	c, err := client.New(client4)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	export, err := c.Playbooks.Export(ctx, "h4n3h7s1qjf5pkis4dn6cuxgwa", client.PlaybookExportFormatYAML)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	result, err := c.Playbooks.Import(ctx, "nt8ukpjibtrmxxjf8jd3gxkofe", client.PlaybookExportFormatYAML, export)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Imported Playbook ID: %s\n", result.ID)
+	for _, warning := range result.Warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+}
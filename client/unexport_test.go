@@ -2,3 +2,4 @@ package client
 
 var BuildAPIURL = buildAPIURL
 var NewClient = newClient
+var VerifyWebhookSignatureAt = verifyWebhookSignature
@@ -0,0 +1,139 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package client
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WebhookEventVersion is the version of the WebhookEvent envelope understood by this package.
+const WebhookEventVersion = 1
+
+// WebhookEventType is the event of a playbook run that triggered a webhook.
+type WebhookEventType string
+
+const (
+	WebhookEventPlaybookRunCreated           WebhookEventType = "playbook_run_created"
+	WebhookEventStatusUpdated                WebhookEventType = "status_updated"
+	WebhookEventOwnerChanged                 WebhookEventType = "owner_changed"
+	WebhookEventChecklistItemStateChanged    WebhookEventType = "checklist_item_state_changed"
+	WebhookEventChecklistItemAssigneeChanged WebhookEventType = "checklist_item_assignee_changed"
+	WebhookEventTimelineEventAdded           WebhookEventType = "timeline_event_added"
+	WebhookEventParticipantJoined            WebhookEventType = "participant_joined"
+	WebhookEventParticipantLeft              WebhookEventType = "participant_left"
+	WebhookEventRetrospectivePublished       WebhookEventType = "retrospective_published"
+	WebhookEventRetrospectiveCanceled        WebhookEventType = "retrospective_canceled"
+)
+
+// WebhookEvent is the body of the requests sent to the URLs of webhook subscriptions.
+type WebhookEvent struct {
+	Version     int                 `json:"version"`
+	ID          string              `json:"id"` // Identical across the attempts to deliver an event
+	Type        WebhookEventType    `json:"type"`
+	CreateAt    int64               `json:"create_at"`
+	UserID      string              `json:"user_id"` // The user who triggered the event, if any
+	PlaybookRun PlaybookRun         `json:"playbook_run"`
+	ChannelURL  string              `json:"channel_url"`
+	DetailsURL  string              `json:"details_url"`
+	Details     WebhookEventDetails `json:"details"`
+}
+
+// WebhookEventDetails describes what happened in a WebhookEvent. Only the fields relevant to the
+// event's type are set.
+type WebhookEventDetails struct {
+	PreviousOwnerUserID string                `json:"previous_owner_user_id"`
+	ChecklistItem       *WebhookChecklistItem `json:"checklist_item"`
+	TimelineEvent       *TimelineEvent        `json:"timeline_event"`
+	ParticipantUserID   string                `json:"participant_user_id"`
+}
+
+// WebhookChecklistItem locates the checklist item of a WebhookEvent in the playbook run's
+// checklists, and holds its previous state and assignee.
+type WebhookChecklistItem struct {
+	ChecklistNum       int    `json:"checklist_num"`
+	ItemNum            int    `json:"item_num"`
+	PreviousState      string `json:"previous_state"`
+	PreviousAssigneeID string `json:"previous_assignee_id"`
+}
+
+// WebhookSignatureHeader is the header carrying the signature of the outgoing webhook requests of
+// playbook runs whose playbook has a webhook secret. Its value has the form
+//
+//	t=<timestamp>,v1=<signature>
+//
+// where timestamp is the number of seconds since the Unix epoch at which the request was sent,
+// and signature is the hex-encoded HMAC-SHA256, keyed with the webhook secret, of the timestamp,
+// a period and the request body.
+const WebhookSignatureHeader = "X-Playbooks-Signature"
+
+// DefaultWebhookTolerance is the recommended maximum difference between the time a webhook
+// request was signed and the time it is verified. Rejecting older requests protects receivers
+// against replayed requests.
+const DefaultWebhookTolerance = 5 * time.Minute
+
+// ErrInvalidWebhookSignature is returned when a webhook request was not signed with the expected
+// secret, or was signed outside the tolerated time window.
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// VerifyWebhookSignature checks that the value of a WebhookSignatureHeader was computed for body
+// with secret, less than tolerance ago. It returns an error wrapping ErrInvalidWebhookSignature
+// otherwise.
+func VerifyWebhookSignature(secret, signatureHeader string, body []byte, tolerance time.Duration) error {
+	return verifyWebhookSignature(secret, signatureHeader, body, tolerance, time.Now())
+}
+
+func verifyWebhookSignature(secret, signatureHeader string, body []byte, tolerance time.Duration, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(signatureHeader, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "t":
+			timestamp = kv[1]
+		case "v1":
+			signatures = append(signatures, kv[1])
+		}
+	}
+
+	if timestamp == "" || len(signatures) == 0 {
+		return errors.Wrap(ErrInvalidWebhookSignature, "missing timestamp or signature")
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.Wrapf(ErrInvalidWebhookSignature, "invalid timestamp %s", timestamp)
+	}
+
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > tolerance || age < -tolerance {
+		return errors.Wrapf(ErrInvalidWebhookSignature, "timestamp %s is outside the tolerated window of %s", timestamp, tolerance)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+
+	for _, signature := range signatures {
+		decoded, err := hex.DecodeString(signature)
+		if err != nil {
+			continue
+		}
+		if hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+
+	return errors.Wrap(ErrInvalidWebhookSignature, "no signature matches the body")
+}
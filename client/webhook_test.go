@@ -0,0 +1,80 @@
+package client_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/client"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("%d.", timestamp)))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	secret := "s3cr3t"
+	body := []byte(`{"id": "h4n3h7s1qjf5pkis4dn6cuxgwa"}`)
+	now := time.Unix(1625000000, 0)
+	signedAt := now.Add(-time.Minute).Unix()
+
+	for name, tc := range map[string]struct {
+		header string
+		body   []byte
+		valid  bool
+	}{
+		"valid signature": {
+			header: fmt.Sprintf("t=%d,v1=%s", signedAt, sign(secret, signedAt, body)),
+			body:   body,
+			valid:  true,
+		},
+		"one of several signatures is valid": {
+			header: fmt.Sprintf("t=%d, v1=%s, v1=%s", signedAt, sign("old", signedAt, body), sign(secret, signedAt, body)),
+			body:   body,
+			valid:  true,
+		},
+		"wrong secret": {
+			header: fmt.Sprintf("t=%d,v1=%s", signedAt, sign("other", signedAt, body)),
+			body:   body,
+		},
+		"tampered body": {
+			header: fmt.Sprintf("t=%d,v1=%s", signedAt, sign(secret, signedAt, body)),
+			body:   []byte(`{"id": "tampered"}`),
+		},
+		"tampered timestamp": {
+			header: fmt.Sprintf("t=%d,v1=%s", signedAt+1, sign(secret, signedAt, body)),
+			body:   body,
+		},
+		"replayed request": {
+			header: fmt.Sprintf("t=%d,v1=%s", now.Add(-time.Hour).Unix(), sign(secret, now.Add(-time.Hour).Unix(), body)),
+			body:   body,
+		},
+		"timestamp in the future": {
+			header: fmt.Sprintf("t=%d,v1=%s", now.Add(time.Hour).Unix(), sign(secret, now.Add(time.Hour).Unix(), body)),
+			body:   body,
+		},
+		"missing timestamp": {
+			header: "v1=" + sign(secret, signedAt, body),
+			body:   body,
+		},
+		"missing header": {
+			body: body,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := client.VerifyWebhookSignatureAt(secret, tc.header, tc.body, client.DefaultWebhookTolerance, now)
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, client.ErrInvalidWebhookSignature)
+		})
+	}
+}
@@ -18,6 +18,7 @@ require (
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.7.0
 	github.com/writeas/go-strip-markdown v2.0.1+incompatible
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 replace github.com/mattermost/mattermost-plugin-incident-collaboration/client => ./client
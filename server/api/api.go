@@ -19,8 +19,11 @@ type Handler struct {
 	*ErrorHandler
 	pluginAPI *pluginapi.Client
 	APIRouter *mux.Router
-	root      *mux.Router
-	config    config.Service
+	// WebhooksRouter routes the requests of external systems, which authenticate themselves
+	// instead of acting as a Mattermost user.
+	WebhooksRouter *mux.Router
+	root           *mux.Router
+	config         config.Service
 }
 
 // NewHandler constructs a new handler.
@@ -32,6 +35,9 @@ func NewHandler(pluginAPI *pluginapi.Client, config config.Service, log bot.Logg
 	}
 
 	root := mux.NewRouter()
+	webhooks := root.PathPrefix("/webhooks").Subrouter()
+	webhooks.NotFoundHandler = http.NotFoundHandler()
+
 	api := root.PathPrefix("/api/v0").Subrouter()
 	api.Use(MattermostAuthorizationRequired)
 
@@ -39,6 +45,7 @@ func NewHandler(pluginAPI *pluginapi.Client, config config.Service, log bot.Logg
 	api.NotFoundHandler = http.NotFoundHandler()
 
 	handler.APIRouter = api
+	handler.WebhooksRouter = webhooks
 	handler.root = root
 	handler.config = config
 
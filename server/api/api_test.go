@@ -140,3 +140,39 @@ func toAPIChecklists(internalChecklists []app.Checklist) []icClient.Checklist {
 
 	return apiChecklists
 }
+
+func toAPIStatusWorkflow(internalStatusWorkflow app.StatusWorkflow) icClient.StatusWorkflow {
+	var apiStatusWorkflow icClient.StatusWorkflow
+
+	statusWorkflowBytes, _ := json.Marshal(internalStatusWorkflow)
+	err := json.Unmarshal(statusWorkflowBytes, &apiStatusWorkflow)
+	if err != nil {
+		panic(err)
+	}
+
+	return apiStatusWorkflow
+}
+
+func toAPICustomFields(internalCustomFields []app.CustomField) []icClient.CustomField {
+	var apiCustomFields []icClient.CustomField
+
+	customFieldsBytes, _ := json.Marshal(internalCustomFields)
+	err := json.Unmarshal(customFieldsBytes, &apiCustomFields)
+	if err != nil {
+		panic(err)
+	}
+
+	return apiCustomFields
+}
+
+func toAPIWebhookSubscriptions(internalWebhookSubscriptions []app.WebhookSubscription) []icClient.WebhookSubscription {
+	var apiWebhookSubscriptions []icClient.WebhookSubscription
+
+	webhookSubscriptionsBytes, _ := json.Marshal(internalWebhookSubscriptions)
+	err := json.Unmarshal(webhookSubscriptionsBytes, &apiWebhookSubscriptions)
+	if err != nil {
+		panic(err)
+	}
+
+	return apiWebhookSubscriptions
+}
@@ -6,6 +6,9 @@ package api
 import (
 	"net/http"
 
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/app"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
 )
 
@@ -13,8 +16,19 @@ type ErrorHandler struct {
 	log bot.Logger
 }
 
-// HandleError logs the internal error and sends a generic error as JSON in a 500 response.
+// HandleError logs the internal error and sends a generic error as JSON in a 500 response, in a
+// 409 response if a playbook run kept being updated concurrently, or in a 412 response if it was
+// not at the version given in the If-Match header.
 func (h *ErrorHandler) HandleError(w http.ResponseWriter, internalErr error) {
+	if errors.Is(internalErr, app.ErrPlaybookRunVersionConflict) {
+		h.HandleErrorWithCode(w, http.StatusConflict, "The playbook run was updated concurrently. Please try again.", internalErr)
+		return
+	}
+	if errors.Is(internalErr, app.ErrPlaybookRunPreconditionFailed) {
+		h.HandleErrorWithCode(w, http.StatusPreconditionFailed, "The playbook run was updated since it was read.", internalErr)
+		return
+	}
+
 	h.HandleErrorWithCode(w, http.StatusInternalServerError, "An internal error has occurred. Check app server logs for details.", internalErr)
 }
 
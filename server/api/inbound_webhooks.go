@@ -0,0 +1,180 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/app"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/config"
+)
+
+// maxInboundWebhookBodySize is the maximum size of the body of a request to an inbound webhook.
+const maxInboundWebhookBodySize = 1024 * 1024
+
+// InboundWebhookHandler is the API handler for the inbound webhooks of playbooks.
+type InboundWebhookHandler struct {
+	*ErrorHandler
+	config             config.Service
+	playbookRunService app.PlaybookRunService
+	playbookService    app.PlaybookService
+	log                bot.Logger
+}
+
+// InboundWebhookResult is the response to an alert posted to an inbound webhook.
+type InboundWebhookResult struct {
+	PlaybookRunID string `json:"playbook_run_id"` // Empty if the alert did not match or start a run
+	Created       bool   `json:"created"`
+}
+
+// InboundStatusUpdateResult is the response to a status update posted to an inbound webhook.
+type InboundStatusUpdateResult struct {
+	PlaybookRunID string `json:"playbook_run_id"`
+	Duplicate     bool   `json:"duplicate"` // The event ID was already applied, so the update was ignored
+}
+
+// NewInboundWebhookHandler creates a new inbound webhook API handler on the given webhooks router.
+func NewInboundWebhookHandler(router *mux.Router, playbookRunService app.PlaybookRunService, playbookService app.PlaybookService,
+	log bot.Logger, configService config.Service) *InboundWebhookHandler {
+	handler := &InboundWebhookHandler{
+		ErrorHandler:       &ErrorHandler{log: log},
+		config:             configService,
+		playbookRunService: playbookRunService,
+		playbookService:    playbookService,
+		log:                log,
+	}
+
+	router.HandleFunc("/playbooks/{id:[A-Za-z0-9]+}", handler.startPlaybookRunFromAlert).Methods(http.MethodPost)
+	router.HandleFunc("/runs/{id:[A-Za-z0-9]+}/status", handler.updateStatus).Methods(http.MethodPost)
+
+	return handler
+}
+
+// startPlaybookRunFromAlert handles the POST /webhooks/playbooks/{id} endpoint.
+func (h *InboundWebhookHandler) startPlaybookRunFromAlert(w http.ResponseWriter, r *http.Request) {
+	playbook, ok := h.authorize(w, r, mux.Vars(r)["id"])
+	if !ok {
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxInboundWebhookBodySize))
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to read alert", err)
+		return
+	}
+
+	alert, err := app.ParseInboundAlert(playbook.InboundWebhook, body)
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode alert", err)
+		return
+	}
+
+	playbookRun, created, err := h.playbookRunService.StartPlaybookRunFromAlert(playbook, alert)
+	if errors.Is(err, app.ErrMalformedPlaybookRun) || errors.Is(err, app.ErrChannelDisplayNameInvalid) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to start playbook run", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, errors.Wrapf(err, "unable to start playbook run"))
+		return
+	}
+
+	result := InboundWebhookResult{Created: created}
+	if playbookRun != nil {
+		result.PlaybookRunID = playbookRun.ID
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	ReturnJSON(w, &result, status)
+}
+
+// updateStatus handles the POST /webhooks/runs/{id}/status endpoint.
+func (h *InboundWebhookHandler) updateStatus(w http.ResponseWriter, r *http.Request) {
+	playbookRunID := mux.Vars(r)["id"]
+
+	playbookRun, err := h.playbookRunService.GetPlaybookRun(playbookRunID)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, http.StatusNotFound, "Not found", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	// Runs without a playbook have no inbound webhook: report them as missing, like the playbooks
+	// without one.
+	if playbookRun.PlaybookID == "" {
+		h.HandleErrorWithCode(w, http.StatusNotFound, "Not found", errors.Errorf("playbook run %s has no playbook", playbookRunID))
+		return
+	}
+
+	playbook, ok := h.authorize(w, r, playbookRun.PlaybookID)
+	if !ok {
+		return
+	}
+
+	var update app.InboundStatusUpdate
+	if err = json.NewDecoder(http.MaxBytesReader(w, r.Body, maxInboundWebhookBodySize)).Decode(&update); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode status update", err)
+		return
+	}
+	if err = update.Validate(); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	applied, err := h.playbookRunService.UpdateStatusFromWebhook(playbookRunID, playbook.InboundWebhook, update)
+	if errors.Is(err, app.ErrMalformedPlaybookRun) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid status", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, errors.Wrapf(err, "unable to update status"))
+		return
+	}
+
+	ReturnJSON(w, &InboundStatusUpdateResult{PlaybookRunID: playbookRunID, Duplicate: !applied}, http.StatusOK)
+}
+
+// authorize returns the playbook if it has an enabled inbound webhook whose token authenticates
+// the request, passed as a bearer token or in the token query parameter. Otherwise, it writes the
+// error response and returns false.
+func (h *InboundWebhookHandler) authorize(w http.ResponseWriter, r *http.Request, playbookID string) (app.Playbook, bool) {
+	playbook, err := h.playbookService.Get(playbookID)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, http.StatusNotFound, "Not found", err)
+		return app.Playbook{}, false
+	} else if err != nil {
+		h.HandleError(w, err)
+		return app.Playbook{}, false
+	}
+
+	// Playbooks without an enabled inbound webhook are reported as missing, to not disclose them.
+	if playbook.DeleteAt != 0 || !playbook.InboundWebhook.Enabled || playbook.InboundWebhook.Token == "" {
+		h.HandleErrorWithCode(w, http.StatusNotFound, "Not found", errors.Errorf("playbook %s has no inbound webhook", playbookID))
+		return app.Playbook{}, false
+	}
+
+	token := r.URL.Query().Get("token")
+	if authorization := r.Header.Get("Authorization"); strings.HasPrefix(authorization, "Bearer ") {
+		token = strings.TrimPrefix(authorization, "Bearer ")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(playbook.InboundWebhook.Token)) != 1 {
+		h.HandleErrorWithCode(w, http.StatusUnauthorized, "Not authorized", errors.Errorf("invalid inbound webhook token for playbook %s", playbookID))
+		return app.Playbook{}, false
+	}
+
+	if !app.IsOnEnabledTeam(playbook.TeamID, h.config) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "not enabled on this team", nil)
+		return app.Playbook{}, false
+	}
+
+	return playbook, true
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/app"
+	mock_app "github.com/mattermost/mattermost-plugin-incident-collaboration/server/app/mocks"
+	mock_poster "github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot/mocks"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/config"
+	mock_config "github.com/mattermost/mattermost-plugin-incident-collaboration/server/config/mocks"
+)
+
+func TestInboundWebhooks(t *testing.T) {
+	var mockCtrl *gomock.Controller
+	var handler *Handler
+	var logger *mock_poster.MockLogger
+	var configService *mock_config.MockService
+	var playbookService *mock_app.MockPlaybookService
+	var playbookRunService *mock_app.MockPlaybookRunService
+
+	const token = "9g64ig7q9pds8yjz8rsgd6e36e"
+
+	playbook := app.Playbook{
+		ID:                  "playbookid1",
+		Title:               "My Playbook",
+		TeamID:              model.NewId(),
+		DefaultOwnerEnabled: true,
+		DefaultOwnerID:      model.NewId(),
+		InboundWebhook:      app.InboundWebhook{Enabled: true, Token: token},
+	}
+
+	reset := func(t *testing.T) {
+		t.Helper()
+
+		mockCtrl = gomock.NewController(t)
+		configService = mock_config.NewMockService(mockCtrl)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		logger = mock_poster.NewMockLogger(mockCtrl)
+		handler = NewHandler(client, configService, logger)
+		playbookService = mock_app.NewMockPlaybookService(mockCtrl)
+		playbookRunService = mock_app.NewMockPlaybookRunService(mockCtrl)
+		NewInboundWebhookHandler(handler.WebhooksRouter, playbookRunService, playbookService, logger, configService)
+	}
+
+	post := func(t *testing.T, url, authorization, body string) *http.Response {
+		t.Helper()
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", url, strings.NewReader(body))
+		require.NoError(t, err)
+		if authorization != "" {
+			testreq.Header.Add("Authorization", authorization)
+		}
+		handler.ServeHTTP(testrecorder, testreq)
+
+		return testrecorder.Result()
+	}
+
+	t.Run("start a playbook run from an alert", func(t *testing.T) {
+		reset(t)
+
+		playbookService.EXPECT().Get(playbook.ID).Return(playbook, nil)
+		configService.EXPECT().GetConfiguration().Return(&config.Configuration{EnabledTeams: []string{}})
+		playbookRunService.EXPECT().
+			StartPlaybookRunFromAlert(playbook, app.InboundAlert{Name: "Database down", Severity: "high", Fingerprint: "db-1"}).
+			Return(&app.PlaybookRun{ID: "playbookRunID"}, true, nil)
+
+		resp := post(t, "/webhooks/playbooks/"+playbook.ID, "Bearer "+token, `{"title": "Database down", "severity": "high", "fingerprint": "db-1"}`)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var result InboundWebhookResult
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		require.Equal(t, InboundWebhookResult{PlaybookRunID: "playbookRunID", Created: true}, result)
+	})
+
+	t.Run("repeated alert with the token in the query", func(t *testing.T) {
+		reset(t)
+
+		playbookService.EXPECT().Get(playbook.ID).Return(playbook, nil)
+		configService.EXPECT().GetConfiguration().Return(&config.Configuration{EnabledTeams: []string{}})
+		playbookRunService.EXPECT().
+			StartPlaybookRunFromAlert(playbook, app.InboundAlert{Name: "Database down", Fingerprint: "db-1"}).
+			Return(&app.PlaybookRun{ID: "playbookRunID"}, false, nil)
+
+		resp := post(t, "/webhooks/playbooks/"+playbook.ID+"?token="+token, "", `{"title": "Database down", "fingerprint": "db-1"}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var result InboundWebhookResult
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		require.Equal(t, InboundWebhookResult{PlaybookRunID: "playbookRunID", Created: false}, result)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		reset(t)
+
+		playbookService.EXPECT().Get(playbook.ID).Return(playbook, nil)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		resp := post(t, "/webhooks/playbooks/"+playbook.ID, "Bearer wrong", `{"title": "Database down"}`)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		reset(t)
+
+		playbookService.EXPECT().Get(playbook.ID).Return(playbook, nil)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		resp := post(t, "/webhooks/playbooks/"+playbook.ID, "", `{"title": "Database down"}`)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("inbound webhook disabled", func(t *testing.T) {
+		reset(t)
+
+		disabled := playbook
+		disabled.InboundWebhook.Enabled = false
+		playbookService.EXPECT().Get(playbook.ID).Return(disabled, nil)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		resp := post(t, "/webhooks/playbooks/"+playbook.ID, "Bearer "+token, `{"title": "Database down"}`)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("playbook not found", func(t *testing.T) {
+		reset(t)
+
+		playbookService.EXPECT().Get("unknown").Return(app.Playbook{}, app.ErrNotFound)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		resp := post(t, "/webhooks/playbooks/unknown", "Bearer "+token, `{"title": "Database down"}`)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("disabled on the playbook's team", func(t *testing.T) {
+		reset(t)
+
+		playbookService.EXPECT().Get(playbook.ID).Return(playbook, nil)
+		configService.EXPECT().GetConfiguration().Return(&config.Configuration{EnabledTeams: []string{"notthisteam"}})
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		resp := post(t, "/webhooks/playbooks/"+playbook.ID, "Bearer "+token, `{"title": "Database down"}`)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("alert is not a JSON object", func(t *testing.T) {
+		reset(t)
+
+		playbookService.EXPECT().Get(playbook.ID).Return(playbook, nil)
+		configService.EXPECT().GetConfiguration().Return(&config.Configuration{EnabledTeams: []string{}})
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		resp := post(t, "/webhooks/playbooks/"+playbook.ID, "Bearer "+token, `not json`)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("post a status update", func(t *testing.T) {
+		reset(t)
+
+		playbookRunService.EXPECT().GetPlaybookRun("playbookRunID").Return(&app.PlaybookRun{ID: "playbookRunID", PlaybookID: playbook.ID}, nil)
+		playbookService.EXPECT().Get(playbook.ID).Return(playbook, nil)
+		configService.EXPECT().GetConfiguration().Return(&config.Configuration{EnabledTeams: []string{}})
+		playbookRunService.EXPECT().
+			UpdateStatusFromWebhook("playbookRunID", playbook.InboundWebhook, app.InboundStatusUpdate{EventID: "event", Message: "cleared", Resolve: true}).
+			Return(true, nil)
+
+		resp := post(t, "/webhooks/runs/playbookRunID/status", "Bearer "+token, `{"event_id": " event ", "message": "cleared", "resolve": true}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var result InboundStatusUpdateResult
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		require.Equal(t, InboundStatusUpdateResult{PlaybookRunID: "playbookRunID", Duplicate: false}, result)
+	})
+
+	t.Run("duplicate status update", func(t *testing.T) {
+		reset(t)
+
+		playbookRunService.EXPECT().GetPlaybookRun("playbookRunID").Return(&app.PlaybookRun{ID: "playbookRunID", PlaybookID: playbook.ID}, nil)
+		playbookService.EXPECT().Get(playbook.ID).Return(playbook, nil)
+		configService.EXPECT().GetConfiguration().Return(&config.Configuration{EnabledTeams: []string{}})
+		playbookRunService.EXPECT().
+			UpdateStatusFromWebhook("playbookRunID", playbook.InboundWebhook, app.InboundStatusUpdate{EventID: "event", Message: "cleared"}).
+			Return(false, nil)
+
+		resp := post(t, "/webhooks/runs/playbookRunID/status?token="+token, "", `{"event_id": "event", "message": "cleared"}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var result InboundStatusUpdateResult
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		require.Equal(t, InboundStatusUpdateResult{PlaybookRunID: "playbookRunID", Duplicate: true}, result)
+	})
+
+	t.Run("status update with an invalid token", func(t *testing.T) {
+		reset(t)
+
+		playbookRunService.EXPECT().GetPlaybookRun("playbookRunID").Return(&app.PlaybookRun{ID: "playbookRunID", PlaybookID: playbook.ID}, nil)
+		playbookService.EXPECT().Get(playbook.ID).Return(playbook, nil)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		resp := post(t, "/webhooks/runs/playbookRunID/status", "Bearer wrong", `{"event_id": "event", "message": "cleared"}`)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("status update of a run without a playbook", func(t *testing.T) {
+		reset(t)
+
+		playbookRunService.EXPECT().GetPlaybookRun("playbookRunID").Return(&app.PlaybookRun{ID: "playbookRunID"}, nil)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		resp := post(t, "/webhooks/runs/playbookRunID/status", "Bearer "+token, `{"event_id": "event", "message": "cleared"}`)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("status update without an event id", func(t *testing.T) {
+		reset(t)
+
+		playbookRunService.EXPECT().GetPlaybookRun("playbookRunID").Return(&app.PlaybookRun{ID: "playbookRunID", PlaybookID: playbook.ID}, nil)
+		playbookService.EXPECT().Get(playbook.ID).Return(playbook, nil)
+		configService.EXPECT().GetConfiguration().Return(&config.Configuration{EnabledTeams: []string{}})
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		resp := post(t, "/webhooks/runs/playbookRunID/status", "Bearer "+token, `{"message": "cleared"}`)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("status update to an unreachable status", func(t *testing.T) {
+		reset(t)
+
+		playbookRunService.EXPECT().GetPlaybookRun("playbookRunID").Return(&app.PlaybookRun{ID: "playbookRunID", PlaybookID: playbook.ID}, nil)
+		playbookService.EXPECT().Get(playbook.ID).Return(playbook, nil)
+		configService.EXPECT().GetConfiguration().Return(&config.Configuration{EnabledTeams: []string{}})
+		playbookRunService.EXPECT().
+			UpdateStatusFromWebhook("playbookRunID", playbook.InboundWebhook, gomock.Any()).
+			Return(false, app.ErrMalformedPlaybookRun)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		resp := post(t, "/webhooks/runs/playbookRunID/status", "Bearer "+token, `{"event_id": "event", "message": "cleared", "status": "Unknown"}`)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -52,6 +53,7 @@ func NewPlaybookRunHandler(router *mux.Router, playbookRunService app.PlaybookRu
 	playbookRunsRouter.HandleFunc("/dialog", handler.createPlaybookRunFromDialog).Methods(http.MethodPost)
 	playbookRunsRouter.HandleFunc("/add-to-timeline-dialog", handler.addToTimelineDialog).Methods(http.MethodPost)
 	playbookRunsRouter.HandleFunc("/owners", handler.getOwners).Methods(http.MethodGet)
+	playbookRunsRouter.HandleFunc("/tasks", handler.getTasks).Methods(http.MethodGet)
 	playbookRunsRouter.HandleFunc("/channels", handler.getChannels).Methods(http.MethodGet)
 	playbookRunsRouter.HandleFunc("/checklist-autocomplete", handler.getChecklistAutocomplete).Methods(http.MethodGet)
 	playbookRunsRouter.HandleFunc("/checklist-autocomplete-item", handler.getChecklistAutocompleteItem).Methods(http.MethodGet)
@@ -64,6 +66,7 @@ func NewPlaybookRunHandler(router *mux.Router, playbookRunService app.PlaybookRu
 	playbookRunRouterAuthorized.Use(handler.checkEditPermissions)
 	playbookRunRouterAuthorized.HandleFunc("", handler.updatePlaybookRun).Methods(http.MethodPatch)
 	playbookRunRouterAuthorized.HandleFunc("/owner", handler.changeOwner).Methods(http.MethodPost)
+	playbookRunRouterAuthorized.HandleFunc("/severity", handler.changeSeverity).Methods(http.MethodPost)
 	playbookRunRouterAuthorized.HandleFunc("/status", handler.status).Methods(http.MethodPost)
 	playbookRunRouterAuthorized.HandleFunc("/update-status-dialog", handler.updateStatusDialog).Methods(http.MethodPost)
 	playbookRunRouterAuthorized.HandleFunc("/reminder/button-update", handler.reminderButtonUpdate).Methods(http.MethodPost)
@@ -71,11 +74,14 @@ func NewPlaybookRunHandler(router *mux.Router, playbookRunService app.PlaybookRu
 	playbookRunRouterAuthorized.HandleFunc("/no-retrospective-button", handler.noRetrospectiveButton).Methods(http.MethodPost)
 	playbookRunRouterAuthorized.HandleFunc("/timeline/{eventID:[A-Za-z0-9]+}", handler.removeTimelineEvent).Methods(http.MethodDelete)
 	playbookRunRouterAuthorized.HandleFunc("/check-and-send-message-on-join/{channel_id:[A-Za-z0-9]+}", handler.checkAndSendMessageOnJoin).Methods(http.MethodGet)
+	playbookRunRouterAuthorized.HandleFunc("/webhooks", handler.getWebhookDeliveries).Methods(http.MethodGet)
+	playbookRunRouterAuthorized.HandleFunc("/webhooks/{deliveryID:[A-Za-z0-9]+}/redeliver", handler.redeliverWebhook).Methods(http.MethodPost)
 
 	channelRouter := playbookRunsRouter.PathPrefix("/channel").Subrouter()
 	channelRouter.HandleFunc("/{channel_id:[A-Za-z0-9]+}", handler.getPlaybookRunByChannel).Methods(http.MethodGet)
 
 	checklistsRouter := playbookRunRouterAuthorized.PathPrefix("/checklists").Subrouter()
+	checklistsRouter.HandleFunc("/bulk", handler.checklistsBulk).Methods(http.MethodPost)
 
 	checklistRouter := checklistsRouter.PathPrefix("/{checklist:[0-9]+}").Subrouter()
 	checklistRouter.HandleFunc("/add", handler.addChecklistItem).Methods(http.MethodPut)
@@ -87,7 +93,16 @@ func NewPlaybookRunHandler(router *mux.Router, playbookRunService app.PlaybookRu
 	checklistItem.HandleFunc("", handler.itemEdit).Methods(http.MethodPut)
 	checklistItem.HandleFunc("/state", handler.itemSetState).Methods(http.MethodPut)
 	checklistItem.HandleFunc("/assignee", handler.itemSetAssignee).Methods(http.MethodPut)
+	checklistItem.HandleFunc("/due", handler.itemSetDueDate).Methods(http.MethodPut)
+	checklistItem.HandleFunc("/dependencies", handler.itemSetDependencies).Methods(http.MethodPut)
 	checklistItem.HandleFunc("/run", handler.itemRun).Methods(http.MethodPost)
+	checklistItem.HandleFunc("/comments", handler.itemAddComment).Methods(http.MethodPost)
+	checklistItem.HandleFunc("/subitems", handler.subItemAdd).Methods(http.MethodPost)
+
+	checklistSubItem := checklistItem.PathPrefix("/subitems/{subitem:[0-9]+}").Subrouter()
+	checklistSubItem.HandleFunc("", handler.subItemDelete).Methods(http.MethodDelete)
+	checklistSubItem.HandleFunc("/state", handler.subItemSetState).Methods(http.MethodPut)
+	checklistSubItem.HandleFunc("/assignee", handler.subItemSetAssignee).Methods(http.MethodPut)
 
 	retrospectiveRouter := playbookRunRouterAuthorized.PathPrefix("/retrospective").Subrouter()
 	retrospectiveRouter.HandleFunc("", handler.updateRetrospective).Methods(http.MethodPost)
@@ -116,10 +131,57 @@ func (h *PlaybookRunHandler) checkEditPermissions(next http.Handler) http.Handle
 			return
 		}
 
+		if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+			if !matchesETag(ifMatch, playbookRunETag(playbookRun)) {
+				h.HandleErrorWithCode(w, http.StatusPreconditionFailed, "The playbook run was updated since it was read.",
+					errors.Errorf("If-Match %s does not match version %d of playbook run %s", ifMatch, playbookRun.Version, playbookRun.ID))
+				return
+			}
+
+			// The run may still be updated before the request changes it, so the change itself
+			// must expect the version matched.
+			if strings.TrimSpace(ifMatch) != "*" {
+				playbookRunService := h.playbookRunService.WithExpectedVersion(playbookRun.Version)
+				r = r.WithContext(context.WithValue(r.Context(), playbookRunServiceContextKey{}, playbookRunService))
+			}
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
 
+// playbookRunServiceContextKey is the key of the request context value holding the service
+// changing the playbook run of the request, see playbookRunServiceFor.
+type playbookRunServiceContextKey struct{}
+
+// playbookRunServiceFor returns the service with which to change the playbook run of the request,
+// which only changes the run if it is at the version given in the If-Match header, if any.
+func (h *PlaybookRunHandler) playbookRunServiceFor(r *http.Request) app.PlaybookRunService {
+	if playbookRunService, ok := r.Context().Value(playbookRunServiceContextKey{}).(app.PlaybookRunService); ok {
+		return playbookRunService
+	}
+
+	return h.playbookRunService
+}
+
+// playbookRunETag is the entity tag of the version of the playbook run, sent in the ETag header of
+// the responses with the run, and expected in the If-Match header of the requests modifying it.
+func playbookRunETag(playbookRun *app.PlaybookRun) string {
+	return `"` + strconv.FormatInt(playbookRun.Version, 10) + `"`
+}
+
+// matchesETag returns true if the If-Match header matches the entity tag.
+func matchesETag(ifMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
 // createPlaybookRunFromPost handles the POST /runs endpoint
 func (h *PlaybookRunHandler) createPlaybookRunFromPost(w http.ResponseWriter, r *http.Request) {
 	userID := r.Header.Get("Mattermost-User-ID")
@@ -143,6 +205,8 @@ func (h *PlaybookRunHandler) createPlaybookRunFromPost(w http.ResponseWriter, r
 			Description: playbookRunCreateOptions.Description,
 			PostID:      playbookRunCreateOptions.PostID,
 			PlaybookID:  playbookRunCreateOptions.PlaybookID,
+
+			CustomFieldValues: playbookRunCreateOptions.CustomFieldValues,
 		},
 		userID,
 	)
@@ -170,13 +234,13 @@ func (h *PlaybookRunHandler) createPlaybookRunFromPost(w http.ResponseWriter, r
 	ReturnJSON(w, &playbookRun, http.StatusCreated)
 }
 
-// Note that this currently does nothing. This is temporary given the removal of stages. Will be used by status.
+// updatePlaybookRun handles the PATCH /runs/{id} endpoint, modifying only the fields present in the body.
 func (h *PlaybookRunHandler) updatePlaybookRun(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	playbookRunID := vars["id"]
-	//userID := r.Header.Get("Mattermost-User-ID")
+	userID := r.Header.Get("Mattermost-User-ID")
 
-	oldPlaybookRun, err := h.playbookRunService.GetPlaybookRun(playbookRunID)
+	oldPlaybookRun, err := h.playbookRunServiceFor(r).GetPlaybookRun(playbookRunID)
 	if err != nil {
 		h.HandleError(w, err)
 		return
@@ -188,7 +252,37 @@ func (h *PlaybookRunHandler) updatePlaybookRun(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	updatedPlaybookRun := oldPlaybookRun
+	updates, err = updates.Validate()
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	if updates.BroadcastChannelID != nil &&
+		*updates.BroadcastChannelID != "" &&
+		*updates.BroadcastChannelID != oldPlaybookRun.BroadcastChannelID &&
+		!h.pluginAPI.User.HasPermissionToChannel(userID, *updates.BroadcastChannelID, model.PERMISSION_CREATE_POST) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to create posts in the channel %s",
+			userID,
+			*updates.BroadcastChannelID,
+		))
+		return
+	}
+
+	updatedPlaybookRun, err := h.playbookRunServiceFor(r).UpdatePlaybookRun(playbookRunID, userID, updates)
+	if errors.Is(err, app.ErrMalformedPlaybookRun) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to update playbook run", err)
+		return
+	}
+	if errors.Is(err, app.ErrChannelDisplayNameInvalid) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid name", err)
+		return
+	}
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
 
 	ReturnJSON(w, updatedPlaybookRun, http.StatusOK)
 }
@@ -229,17 +323,47 @@ func (h *PlaybookRunHandler) createPlaybookRunFromDialog(w http.ResponseWriter,
 		name = rawName
 	}
 
+	// Custom fields are only part of the dialog when it was opened for the submitted playbook.
+	var customFieldValues app.CustomFieldValues
+	showedCustomFields := playbookID != "" && state.CustomFieldsPlaybookID == playbookID
+	if showedCustomFields {
+		pb, pbErr := h.playbookService.Get(playbookID)
+		if pbErr != nil {
+			h.HandleError(w, pbErr)
+			return
+		}
+
+		customFieldValues = app.CustomFieldValuesFromDialog(pb.CustomFields, request.Submission)
+		if fieldErrors := validateCustomFieldDialogValues(pb.CustomFields, customFieldValues); len(fieldErrors) > 0 {
+			resp := &model.SubmitDialogResponse{Errors: fieldErrors}
+			_, _ = w.Write(resp.ToJson())
+			return
+		}
+	}
+
 	playbookRun, err := h.createPlaybookRun(
 		app.PlaybookRun{
-			OwnerUserID: request.UserId,
-			TeamID:      request.TeamId,
-			Name:        name,
-			PostID:      state.PostID,
-			PlaybookID:  playbookID,
+			OwnerUserID:       request.UserId,
+			TeamID:            request.TeamId,
+			Name:              name,
+			PostID:            state.PostID,
+			PlaybookID:        playbookID,
+			CustomFieldValues: customFieldValues,
 		},
 		request.UserId,
 	)
 	if err != nil {
+		// The dialog opened for several playbooks cannot show their custom fields.
+		if errors.Is(err, app.ErrMissingRequiredCustomField) && !showedCustomFields {
+			resp := &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					app.DialogFieldPlaybookIDKey: "This playbook has required fields. Please start the run from the playbook's page to fill them in.",
+				},
+			}
+			_, _ = w.Write(resp.ToJson())
+			return
+		}
+
 		if errors.Is(err, app.ErrMalformedPlaybookRun) {
 			h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to create playbook run", err)
 			return
@@ -281,6 +405,27 @@ func (h *PlaybookRunHandler) createPlaybookRunFromDialog(w http.ResponseWriter,
 	w.WriteHeader(http.StatusCreated)
 }
 
+// validateCustomFieldDialogValues returns the errors to show next to the custom field elements of
+// the create playbook run dialog, keyed by element name.
+func validateCustomFieldDialogValues(fields []app.CustomField, values app.CustomFieldValues) map[string]string {
+	fieldErrors := make(map[string]string)
+	for _, field := range fields {
+		key := app.DialogFieldCustomFieldKeyPrefix + field.ID
+
+		fieldValues, err := field.NormalizeValues(values[field.ID])
+		if err != nil {
+			fieldErrors[key] = err.Error()
+			continue
+		}
+
+		if field.Required && len(fieldValues) == 0 {
+			fieldErrors[key] = "This field is required."
+		}
+	}
+
+	return fieldErrors
+}
+
 // addToTimelineDialog handles the interactive dialog submission when a user clicks the
 // corresponding post action.
 func (h *PlaybookRunHandler) addToTimelineDialog(w http.ResponseWriter, r *http.Request) {
@@ -330,6 +475,7 @@ func (h *PlaybookRunHandler) addToTimelineDialog(w http.ResponseWriter, r *http.
 	w.WriteHeader(http.StatusOK)
 }
 
+// createPlaybookRun validates and creates the given playbook run.
 func (h *PlaybookRunHandler) createPlaybookRun(playbookRun app.PlaybookRun, userID string) (*app.PlaybookRun, error) {
 	if playbookRun.ID != "" {
 		return nil, errors.Wrap(app.ErrMalformedPlaybookRun, "playbook run already has an id")
@@ -372,49 +518,9 @@ func (h *PlaybookRunHandler) createPlaybookRun(playbookRun app.PlaybookRun, user
 			return nil, errors.New("userID is not a member of playbook")
 		}
 
-		playbookRun.Checklists = pb.Checklists
+		app.ApplyPlaybookDefaults(&playbookRun, pb)
 		public = pb.CreatePublicPlaybookRun
 
-		playbookRun.BroadcastChannelID = pb.BroadcastChannelID
-		playbookRun.Description = pb.Description
-		playbookRun.ReminderMessageTemplate = pb.ReminderMessageTemplate
-		playbookRun.PreviousReminder = time.Duration(pb.ReminderTimerDefaultSeconds) * time.Second
-		playbookRun.CategorizeChannelEnabled = pb.CategorizeChannelEnabled
-
-		playbookRun.InvitedUserIDs = []string{}
-		playbookRun.InvitedGroupIDs = []string{}
-		if pb.InviteUsersEnabled {
-			playbookRun.InvitedUserIDs = pb.InvitedUserIDs
-			playbookRun.InvitedGroupIDs = pb.InvitedGroupIDs
-		}
-
-		if pb.DefaultOwnerEnabled {
-			playbookRun.DefaultOwnerID = pb.DefaultOwnerID
-		}
-
-		if pb.AnnouncementChannelEnabled {
-			playbookRun.AnnouncementChannelID = pb.AnnouncementChannelID
-		}
-
-		if pb.WebhookOnCreationEnabled {
-			playbookRun.WebhookOnCreationURL = pb.WebhookOnCreationURL
-		}
-
-		if pb.WebhookOnStatusUpdateEnabled {
-			playbookRun.WebhookOnStatusUpdateURL = pb.WebhookOnStatusUpdateURL
-		}
-
-		if pb.MessageOnJoinEnabled {
-			playbookRun.MessageOnJoin = pb.MessageOnJoin
-		}
-
-		if pb.ExportChannelOnArchiveEnabled {
-			playbookRun.ExportChannelOnArchiveEnabled = pb.ExportChannelOnArchiveEnabled
-		}
-
-		playbookRun.RetrospectiveReminderIntervalSeconds = pb.RetrospectiveReminderIntervalSeconds
-		playbookRun.Retrospective = pb.RetrospectiveTemplate
-
 		playbook = &pb
 	}
 
@@ -437,6 +543,16 @@ func (h *PlaybookRunHandler) createPlaybookRun(playbookRun app.PlaybookRun, user
 			return nil, errors.New("user is not a member of the channel containing the playbook run's original post")
 		}
 	}
+
+	customFieldValues, err := app.NormalizeCustomFieldValues(playbookRun.CustomFields, playbookRun.CustomFieldValues)
+	if err != nil {
+		return nil, err
+	}
+	if field, missing := app.MissingRequiredCustomField(playbookRun.CustomFields, customFieldValues); missing {
+		return nil, errors.Wrapf(app.ErrMissingRequiredCustomField, "field %s", field.Name)
+	}
+	playbookRun.CustomFieldValues = customFieldValues
+
 	return h.playbookRunService.CreatePlaybookRun(&playbookRun, playbook, userID, public)
 }
 
@@ -485,6 +601,7 @@ func (h *PlaybookRunHandler) getPlaybookRun(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	w.Header().Set("ETag", playbookRunETag(playbookRunToGet))
 	ReturnJSON(w, playbookRunToGet, http.StatusOK)
 }
 
@@ -546,6 +663,7 @@ func (h *PlaybookRunHandler) getPlaybookRunByChannel(w http.ResponseWriter, r *h
 		return
 	}
 
+	w.Header().Set("ETag", playbookRunETag(playbookRunToGet))
 	ReturnJSON(w, playbookRunToGet, http.StatusOK)
 }
 
@@ -577,6 +695,36 @@ func (h *PlaybookRunHandler) getOwners(w http.ResponseWriter, r *http.Request) {
 	ReturnJSON(w, owners, http.StatusOK)
 }
 
+// getTasks handles the GET /runs/tasks endpoint, returning the checklist items assigned to the
+// user in the active playbook runs they can view.
+func (h *PlaybookRunHandler) getTasks(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	options, err := parseTasksFilterOptions(r.URL, userID)
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "Bad parameter", err)
+		return
+	}
+
+	requesterInfo, err := h.getRequesterInfo(userID)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	items, err := h.playbookRunService.GetChecklistItems(requesterInfo, options)
+	if err != nil {
+		h.HandleError(w, errors.Wrapf(err, "failed to get tasks"))
+		return
+	}
+
+	if items == nil {
+		items = []app.PlaybookRunChecklistItem{}
+	}
+
+	ReturnJSON(w, items, http.StatusOK)
+}
+
 func (h *PlaybookRunHandler) getChannels(w http.ResponseWriter, r *http.Request) {
 	filterOptions, err := parsePlaybookRunsFilterOptions(r.URL)
 	if err != nil {
@@ -618,7 +766,7 @@ func (h *PlaybookRunHandler) changeOwner(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	playbookRun, err := h.playbookRunService.GetPlaybookRun(vars["id"])
+	playbookRun, err := h.playbookRunServiceFor(r).GetPlaybookRun(vars["id"])
 	if err != nil {
 		h.HandleError(w, err)
 		return
@@ -635,7 +783,32 @@ func (h *PlaybookRunHandler) changeOwner(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.playbookRunService.ChangeOwner(vars["id"], userID, params.OwnerID); err != nil {
+	if err := h.playbookRunServiceFor(r).ChangeOwner(vars["id"], userID, params.OwnerID); err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, map[string]interface{}{}, http.StatusOK)
+}
+
+// changeSeverity handles the POST /runs/{id}/severity endpoint, user has edit permissions
+func (h *PlaybookRunHandler) changeSeverity(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var params struct {
+		Severity string `json:"severity"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "could not decode request body", err)
+		return
+	}
+
+	if err := h.playbookRunServiceFor(r).ChangeSeverity(vars["id"], userID, strings.TrimSpace(params.Severity)); err != nil {
+		if errors.Is(err, app.ErrMalformedPlaybookRun) {
+			h.HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
 		h.HandleError(w, err)
 		return
 	}
@@ -648,7 +821,7 @@ func (h *PlaybookRunHandler) status(w http.ResponseWriter, r *http.Request) {
 	playbookRunID := mux.Vars(r)["id"]
 	userID := r.Header.Get("Mattermost-User-ID")
 
-	playbookRunToModify, err := h.playbookRunService.GetPlaybookRun(playbookRunID)
+	playbookRunToModify, err := h.playbookRunServiceFor(r).GetPlaybookRun(playbookRunID)
 	if err != nil {
 		h.HandleError(w, err)
 		return
@@ -684,19 +857,18 @@ func (h *PlaybookRunHandler) status(w http.ResponseWriter, r *http.Request) {
 		h.HandleErrorWithCode(w, http.StatusBadRequest, "status must not be empty", errors.New("status field empty"))
 		return
 	}
-	switch options.Status {
-	case app.StatusActive:
-	case app.StatusArchived:
-	case app.StatusReported:
-	case app.StatusResolved:
-		break
-	default:
-		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid status", nil)
+	if !playbookRunToModify.Workflow().CanTransition(playbookRunToModify.CurrentStatus, options.Status) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid status",
+			errors.Errorf("cannot change status from '%s' to '%s'", playbookRunToModify.CurrentStatus, options.Status))
 		return
 	}
 
-	err = h.playbookRunService.UpdateStatus(playbookRunID, userID, options)
+	err = h.playbookRunServiceFor(r).UpdateStatus(playbookRunID, userID, options)
 	if err != nil {
+		if errors.Is(err, app.ErrMalformedPlaybookRun) {
+			h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid status", err)
+			return
+		}
 		h.HandleError(w, err)
 		return
 	}
@@ -711,7 +883,7 @@ func (h *PlaybookRunHandler) updateStatusDialog(w http.ResponseWriter, r *http.R
 	playbookRunID := mux.Vars(r)["id"]
 	userID := r.Header.Get("Mattermost-User-ID")
 
-	playbookRunToModify, err := h.playbookRunService.GetPlaybookRun(playbookRunID)
+	playbookRunToModify, err := h.playbookRunServiceFor(r).GetPlaybookRun(playbookRunID)
 	if err != nil {
 		h.HandleError(w, err)
 		return
@@ -757,19 +929,18 @@ func (h *PlaybookRunHandler) updateStatusDialog(w http.ResponseWriter, r *http.R
 		options.Status = status.(string)
 	}
 
-	switch options.Status {
-	case app.StatusActive:
-	case app.StatusArchived:
-	case app.StatusReported:
-	case app.StatusResolved:
-		break
-	default:
-		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid status", nil)
+	if !playbookRunToModify.Workflow().CanTransition(playbookRunToModify.CurrentStatus, options.Status) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid status",
+			errors.Errorf("cannot change status from '%s' to '%s'", playbookRunToModify.CurrentStatus, options.Status))
 		return
 	}
 
-	err = h.playbookRunService.UpdateStatus(playbookRunID, userID, options)
+	err = h.playbookRunServiceFor(r).UpdateStatus(playbookRunID, userID, options)
 	if err != nil {
+		if errors.Is(err, app.ErrMalformedPlaybookRun) {
+			h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid status", err)
+			return
+		}
 		h.HandleError(w, err)
 		return
 	}
@@ -786,7 +957,7 @@ func (h *PlaybookRunHandler) reminderButtonUpdate(w http.ResponseWriter, r *http
 		return
 	}
 
-	playbookRunID, err := h.playbookRunService.GetPlaybookRunIDForChannel(requestData.ChannelId)
+	playbookRunID, err := h.playbookRunServiceFor(r).GetPlaybookRunIDForChannel(requestData.ChannelId)
 	if err != nil {
 		h.HandleErrorWithCode(w, http.StatusInternalServerError, "error getting playbook run",
 			errors.Wrapf(err, "reminderButtonUpdate failed to find playbookRunID for channelID: %s", requestData.ChannelId))
@@ -802,7 +973,7 @@ func (h *PlaybookRunHandler) reminderButtonUpdate(w http.ResponseWriter, r *http
 		return
 	}
 
-	if err = h.playbookRunService.OpenUpdateStatusDialog(playbookRunID, requestData.TriggerId); err != nil {
+	if err = h.playbookRunServiceFor(r).OpenUpdateStatusDialog(playbookRunID, requestData.TriggerId); err != nil {
 		h.HandleError(w, errors.New("reminderButtonUpdate failed to open update status dialog"))
 		return
 	}
@@ -819,7 +990,7 @@ func (h *PlaybookRunHandler) reminderButtonDismiss(w http.ResponseWriter, r *htt
 		return
 	}
 
-	playbookRunID, err := h.playbookRunService.GetPlaybookRunIDForChannel(requestData.ChannelId)
+	playbookRunID, err := h.playbookRunServiceFor(r).GetPlaybookRunIDForChannel(requestData.ChannelId)
 	if err != nil {
 		h.log.Errorf("reminderButtonDismiss: no playbook run for requestData's channelID: %s", requestData.ChannelId)
 		h.HandleErrorWithCode(w, http.StatusBadRequest, "no playbook run for requestData's channelID", err)
@@ -835,7 +1006,7 @@ func (h *PlaybookRunHandler) reminderButtonDismiss(w http.ResponseWriter, r *htt
 		return
 	}
 
-	if err = h.playbookRunService.RemoveReminderPost(playbookRunID); err != nil {
+	if err = h.playbookRunServiceFor(r).RemoveReminderPost(playbookRunID); err != nil {
 		h.log.Errorf("reminderButtonDismiss: error removing reminder for channelID: %s; error: %s", requestData.ChannelId, err.Error())
 		h.HandleErrorWithCode(w, http.StatusBadRequest, "error removing reminder", err)
 		return
@@ -848,7 +1019,7 @@ func (h *PlaybookRunHandler) noRetrospectiveButton(w http.ResponseWriter, r *htt
 	playbookRunID := mux.Vars(r)["id"]
 	userID := r.Header.Get("Mattermost-User-ID")
 
-	playbookRunToCancelRetro, err := h.playbookRunService.GetPlaybookRun(playbookRunID)
+	playbookRunToCancelRetro, err := h.playbookRunServiceFor(r).GetPlaybookRun(playbookRunID)
 	if err != nil {
 		h.HandleError(w, err)
 		return
@@ -863,7 +1034,7 @@ func (h *PlaybookRunHandler) noRetrospectiveButton(w http.ResponseWriter, r *htt
 		return
 	}
 
-	if err := h.playbookRunService.CancelRetrospective(playbookRunID, userID); err != nil {
+	if err := h.playbookRunServiceFor(r).CancelRetrospective(playbookRunID, userID); err != nil {
 		h.HandleErrorWithCode(w, http.StatusInternalServerError, "unable to cancel retrospective", err)
 		return
 	}
@@ -879,7 +1050,7 @@ func (h *PlaybookRunHandler) removeTimelineEvent(w http.ResponseWriter, r *http.
 	userID := r.Header.Get("Mattermost-User-ID")
 	eventID := vars["eventID"]
 
-	if err := h.playbookRunService.RemoveTimelineEvent(id, userID, eventID); err != nil {
+	if err := h.playbookRunServiceFor(r).RemoveTimelineEvent(id, userID, eventID); err != nil {
 		h.HandleError(w, err)
 		return
 	}
@@ -894,10 +1065,50 @@ func (h *PlaybookRunHandler) checkAndSendMessageOnJoin(w http.ResponseWriter, r
 	channelID := vars["channel_id"]
 	userID := r.Header.Get("Mattermost-User-ID")
 
-	hasViewed := h.playbookRunService.CheckAndSendMessageOnJoin(userID, playbookRunID, channelID)
+	hasViewed := h.playbookRunServiceFor(r).CheckAndSendMessageOnJoin(userID, playbookRunID, channelID)
 	ReturnJSON(w, map[string]interface{}{"viewed": hasViewed}, http.StatusOK)
 }
 
+// getWebhookDeliveries handles the GET /runs/{id}/webhooks endpoint.
+func (h *PlaybookRunHandler) getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	playbookRunID := mux.Vars(r)["id"]
+
+	status := app.WebhookDeliveryStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", app.WebhookDeliveryPending, app.WebhookDeliverySucceeded, app.WebhookDeliveryFailed:
+	default:
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid status", errors.Errorf("unknown webhook delivery status '%s'", status))
+		return
+	}
+
+	deliveries, err := h.playbookRunServiceFor(r).GetWebhookDeliveries(playbookRunID, status)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, deliveries, http.StatusOK)
+}
+
+// redeliverWebhook handles the POST /runs/{id}/webhooks/{deliveryID}/redeliver endpoint.
+func (h *PlaybookRunHandler) redeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	delivery, err := h.playbookRunServiceFor(r).RedeliverWebhook(vars["id"], vars["deliveryID"])
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, http.StatusNotFound, "Not found", err)
+		return
+	} else if errors.Is(err, app.ErrMalformedPlaybookRun) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to redeliver webhook", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, delivery, http.StatusCreated)
+}
+
 func (h *PlaybookRunHandler) getChecklistAutocompleteItem(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 	channelID := query.Get("channel_id")
@@ -965,6 +1176,8 @@ func (h *PlaybookRunHandler) itemSetState(w http.ResponseWriter, r *http.Request
 
 	var params struct {
 		NewState string `json:"new_state"`
+		Override bool   `json:"override"`
+		Reason   string `json:"reason"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
 		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to unmarshal", err)
@@ -976,7 +1189,31 @@ func (h *PlaybookRunHandler) itemSetState(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err := h.playbookRunService.ModifyCheckedState(id, userID, params.NewState, checklistNum, itemNum); err != nil {
+	if params.NewState == app.ChecklistItemStateSkipped {
+		if strings.TrimSpace(params.Reason) == "" {
+			h.HandleErrorWithCode(w, http.StatusBadRequest, "bad parameter: reason",
+				errors.New("a reason is required to skip a checklist item"))
+			return
+		}
+
+		if err := h.playbookRunServiceFor(r).SkipChecklistItem(id, userID, checklistNum, itemNum, params.Reason); err != nil {
+			h.HandleError(w, err)
+			return
+		}
+
+		ReturnJSON(w, map[string]interface{}{}, http.StatusOK)
+		return
+	}
+
+	if err := h.playbookRunServiceFor(r).ModifyCheckedState(id, userID, params.NewState, checklistNum, itemNum, params.Override); err != nil {
+		if errors.Is(err, app.ErrChecklistItemBlocked) {
+			h.HandleErrorWithCode(w, http.StatusConflict, "checklist item is blocked by open prerequisites", err)
+			return
+		}
+		if errors.Is(err, app.ErrNoPermissions) {
+			h.HandleErrorWithCode(w, http.StatusForbidden, "not authorized", err)
+			return
+		}
 		h.HandleError(w, err)
 		return
 	}
@@ -1007,7 +1244,7 @@ func (h *PlaybookRunHandler) itemSetAssignee(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if err := h.playbookRunService.SetAssignee(id, userID, params.AssigneeID, checklistNum, itemNum); err != nil {
+	if err := h.playbookRunServiceFor(r).SetAssignee(id, userID, params.AssigneeID, checklistNum, itemNum); err != nil {
 		h.HandleError(w, err)
 		return
 	}
@@ -1015,6 +1252,246 @@ func (h *PlaybookRunHandler) itemSetAssignee(w http.ResponseWriter, r *http.Requ
 	ReturnJSON(w, map[string]interface{}{}, http.StatusOK)
 }
 
+func (h *PlaybookRunHandler) itemSetDueDate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	checklistNum, err := strconv.Atoi(vars["checklist"])
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to parse checklist", err)
+		return
+	}
+	itemNum, err := strconv.Atoi(vars["item"])
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to parse item", err)
+		return
+	}
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var params struct {
+		DueAt int64 `json:"due_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to unmarshal", err)
+		return
+	}
+
+	if params.DueAt < 0 {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid due date", errors.New("due_at must not be negative"))
+		return
+	}
+
+	if err := h.playbookRunServiceFor(r).SetChecklistItemDueDate(id, userID, checklistNum, itemNum, params.DueAt); err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, map[string]interface{}{}, http.StatusOK)
+}
+
+func (h *PlaybookRunHandler) itemSetDependencies(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	checklistNum, err := strconv.Atoi(vars["checklist"])
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to parse checklist", err)
+		return
+	}
+	itemNum, err := strconv.Atoi(vars["item"])
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to parse item", err)
+		return
+	}
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var params struct {
+		DependsOn []string `json:"depends_on"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to unmarshal", err)
+		return
+	}
+
+	if err := h.playbookRunServiceFor(r).SetChecklistItemDependencies(id, userID, checklistNum, itemNum, params.DependsOn); err != nil {
+		if errors.Is(err, app.ErrMalformedPlaybookRun) {
+			h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid dependencies", err)
+			return
+		}
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, map[string]interface{}{}, http.StatusOK)
+}
+
+func (h *PlaybookRunHandler) itemAddComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	checklistNum, err := strconv.Atoi(vars["checklist"])
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to parse checklist", err)
+		return
+	}
+	itemNum, err := strconv.Atoi(vars["item"])
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to parse item", err)
+		return
+	}
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var params struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to unmarshal", err)
+		return
+	}
+
+	if strings.TrimSpace(params.Message) == "" {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "bad parameter: comment message",
+			errors.New("comment message must not be blank"))
+		return
+	}
+
+	comment, err := h.playbookRunServiceFor(r).AddChecklistItemComment(id, userID, checklistNum, itemNum, params.Message)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, comment, http.StatusCreated)
+}
+
+func (h *PlaybookRunHandler) subItemAdd(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	checklistNum, err := strconv.Atoi(vars["checklist"])
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to parse checklist", err)
+		return
+	}
+	itemNum, err := strconv.Atoi(vars["item"])
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to parse item", err)
+		return
+	}
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var subItem app.ChecklistSubItem
+	if err := json.NewDecoder(r.Body).Decode(&subItem); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to decode ChecklistSubItem", err)
+		return
+	}
+
+	subItem.Title = strings.TrimSpace(subItem.Title)
+	if subItem.Title == "" {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "bad parameter: sub-item title",
+			errors.New("sub-item title must not be blank"))
+		return
+	}
+
+	if !app.IsValidChecklistSubItemState(subItem.State) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "bad parameter state", nil)
+		return
+	}
+
+	if err := h.playbookRunServiceFor(r).AddChecklistSubItem(id, userID, checklistNum, itemNum, subItem); err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *PlaybookRunHandler) subItemDelete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	checklistNum, itemNum, subItemNum, err := parseSubItemVars(vars)
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to parse sub-item", err)
+		return
+	}
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	if err := h.playbookRunServiceFor(r).RemoveChecklistSubItem(id, userID, checklistNum, itemNum, subItemNum); err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PlaybookRunHandler) subItemSetState(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	checklistNum, itemNum, subItemNum, err := parseSubItemVars(vars)
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to parse sub-item", err)
+		return
+	}
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var params struct {
+		NewState string `json:"new_state"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to unmarshal", err)
+		return
+	}
+
+	if !app.IsValidChecklistSubItemState(params.NewState) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "bad parameter new state", nil)
+		return
+	}
+
+	if err := h.playbookRunServiceFor(r).ModifyChecklistSubItemState(id, userID, params.NewState, checklistNum, itemNum, subItemNum); err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, map[string]interface{}{}, http.StatusOK)
+}
+
+func (h *PlaybookRunHandler) subItemSetAssignee(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	checklistNum, itemNum, subItemNum, err := parseSubItemVars(vars)
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to parse sub-item", err)
+		return
+	}
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var params struct {
+		AssigneeID string `json:"assignee_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to unmarshal", err)
+		return
+	}
+
+	if err := h.playbookRunServiceFor(r).SetChecklistSubItemAssignee(id, userID, params.AssigneeID, checklistNum, itemNum, subItemNum); err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, map[string]interface{}{}, http.StatusOK)
+}
+
+// parseSubItemVars parses the checklist, item and sub-item numbers of the route of a sub-item.
+func parseSubItemVars(vars map[string]string) (checklistNum, itemNum, subItemNum int, err error) {
+	if checklistNum, err = strconv.Atoi(vars["checklist"]); err != nil {
+		return 0, 0, 0, errors.Wrap(err, "failed to parse checklist")
+	}
+	if itemNum, err = strconv.Atoi(vars["item"]); err != nil {
+		return 0, 0, 0, errors.Wrap(err, "failed to parse item")
+	}
+	if subItemNum, err = strconv.Atoi(vars["subitem"]); err != nil {
+		return 0, 0, 0, errors.Wrap(err, "failed to parse sub-item")
+	}
+
+	return checklistNum, itemNum, subItemNum, nil
+}
+
 func (h *PlaybookRunHandler) itemRun(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	playbookRunID := vars["id"]
@@ -1030,7 +1507,7 @@ func (h *PlaybookRunHandler) itemRun(w http.ResponseWriter, r *http.Request) {
 	}
 	userID := r.Header.Get("Mattermost-User-ID")
 
-	triggerID, err := h.playbookRunService.RunChecklistItemSlashCommand(playbookRunID, userID, checklistNum, itemNum)
+	triggerID, err := h.playbookRunServiceFor(r).RunChecklistItemSlashCommand(playbookRunID, userID, checklistNum, itemNum)
 	if err != nil {
 		h.HandleError(w, err)
 		return
@@ -1039,6 +1516,42 @@ func (h *PlaybookRunHandler) itemRun(w http.ResponseWriter, r *http.Request) {
 	ReturnJSON(w, map[string]interface{}{"trigger_id": triggerID}, http.StatusOK)
 }
 
+// checklistsBulk handles the POST /runs/{id}/checklists/bulk endpoint, applying many checklist
+// operations at once.
+func (h *PlaybookRunHandler) checklistsBulk(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var params struct {
+		Operations []app.ChecklistOperation `json:"operations"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to unmarshal", err)
+		return
+	}
+
+	if len(params.Operations) == 0 {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "bad parameter: operations", errors.New("at least one operation is required"))
+		return
+	}
+
+	if err := h.playbookRunServiceFor(r).ApplyChecklistOperations(id, userID, params.Operations); err != nil {
+		switch {
+		case errors.Is(err, app.ErrMalformedPlaybookRun):
+			h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid checklist operations", err)
+		case errors.Is(err, app.ErrChecklistItemBlocked):
+			h.HandleErrorWithCode(w, http.StatusConflict, "checklist item is blocked by open prerequisites", err)
+		case errors.Is(err, app.ErrNoPermissions):
+			h.HandleErrorWithCode(w, http.StatusForbidden, "not authorized", err)
+		default:
+			h.HandleError(w, err)
+		}
+		return
+	}
+
+	ReturnJSON(w, map[string]interface{}{}, http.StatusOK)
+}
+
 func (h *PlaybookRunHandler) addChecklistItem(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -1062,7 +1575,7 @@ func (h *PlaybookRunHandler) addChecklistItem(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if err := h.playbookRunService.AddChecklistItem(id, userID, checklistNum, checklistItem); err != nil {
+	if err := h.playbookRunServiceFor(r).AddChecklistItem(id, userID, checklistNum, checklistItem); err != nil {
 		h.HandleError(w, err)
 		return
 	}
@@ -1112,7 +1625,7 @@ func (h *PlaybookRunHandler) addChecklistItemDialog(w http.ResponseWriter, r *ht
 		return
 	}
 
-	if err := h.playbookRunService.AddChecklistItem(playbookRunID, userID, checklistNum, checklistItem); err != nil {
+	if err := h.playbookRunServiceFor(r).AddChecklistItem(playbookRunID, userID, checklistNum, checklistItem); err != nil {
 		h.HandleError(w, err)
 		return
 	}
@@ -1136,7 +1649,7 @@ func (h *PlaybookRunHandler) itemDelete(w http.ResponseWriter, r *http.Request)
 	}
 	userID := r.Header.Get("Mattermost-User-ID")
 
-	if err := h.playbookRunService.RemoveChecklistItem(id, userID, checklistNum, itemNum); err != nil {
+	if err := h.playbookRunServiceFor(r).RemoveChecklistItem(id, userID, checklistNum, itemNum); err != nil {
 		h.HandleError(w, err)
 		return
 	}
@@ -1169,7 +1682,7 @@ func (h *PlaybookRunHandler) itemEdit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.playbookRunService.EditChecklistItem(id, userID, checklistNum, itemNum, params.Title, params.Command, params.Description); err != nil {
+	if err := h.playbookRunServiceFor(r).EditChecklistItem(id, userID, checklistNum, itemNum, params.Title, params.Command, params.Description); err != nil {
 		h.HandleError(w, err)
 		return
 	}
@@ -1196,7 +1709,7 @@ func (h *PlaybookRunHandler) reorderChecklist(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if err := h.playbookRunService.MoveChecklistItem(id, userID, checklistNum, modificationParams.ItemNum, modificationParams.NewLocation); err != nil {
+	if err := h.playbookRunServiceFor(r).MoveChecklistItem(id, userID, checklistNum, modificationParams.ItemNum, modificationParams.NewLocation); err != nil {
 		h.HandleError(w, err)
 		return
 	}
@@ -1232,7 +1745,7 @@ func (h *PlaybookRunHandler) updateRetrospective(w http.ResponseWriter, r *http.
 		return
 	}
 
-	if err := h.playbookRunService.UpdateRetrospective(playbookRunID, userID, retroUpdate.Retrospective); err != nil {
+	if err := h.playbookRunServiceFor(r).UpdateRetrospective(playbookRunID, userID, retroUpdate.Retrospective); err != nil {
 		h.HandleErrorWithCode(w, http.StatusInternalServerError, "unable to update retrospective", err)
 		return
 	}
@@ -1254,7 +1767,7 @@ func (h *PlaybookRunHandler) publishRetrospective(w http.ResponseWriter, r *http
 		return
 	}
 
-	if err := h.playbookRunService.PublishRetrospective(playbookRunID, retroUpdate.Retrospective, userID); err != nil {
+	if err := h.playbookRunServiceFor(r).PublishRetrospective(playbookRunID, retroUpdate.Retrospective, userID); err != nil {
 		h.HandleErrorWithCode(w, http.StatusInternalServerError, "unable to publish retrospective", err)
 		return
 	}
@@ -1262,6 +1775,32 @@ func (h *PlaybookRunHandler) publishRetrospective(w http.ResponseWriter, r *http
 	w.WriteHeader(http.StatusOK)
 }
 
+// parseTasksFilterOptions returns the options selecting the open checklist items assigned to the
+// user, or those in the states given by the state parameters, where "open" stands for the open state.
+func parseTasksFilterOptions(u *url.URL, userID string) (app.ChecklistItemFilterOptions, error) {
+	states := []string{}
+	for _, state := range u.Query()["state"] {
+		if state == "open" {
+			state = app.ChecklistItemStateOpen
+		}
+		states = append(states, state)
+	}
+	if len(states) == 0 {
+		states = []string{app.ChecklistItemStateOpen, app.ChecklistItemStateInProgress}
+	}
+
+	options := app.ChecklistItemFilterOptions{
+		TeamID:         u.Query().Get("team_id"),
+		AssigneeID:     userID,
+		States:         states,
+		ActiveRunsOnly: true,
+		Sort:           app.SortField(u.Query().Get("sort")),
+		Direction:      app.SortDirection(u.Query().Get("direction")),
+	}
+
+	return options.Validate()
+}
+
 // parsePlaybookRunsFilterOptions is only for parsing. Put validation logic in app.validateOptions.
 func parsePlaybookRunsFilterOptions(u *url.URL) (*app.PlaybookRunFilterOptions, error) {
 	teamID := u.Query().Get("team_id")
@@ -1288,6 +1827,7 @@ func parsePlaybookRunsFilterOptions(u *url.URL) (*app.PlaybookRunFilterOptions,
 	direction := u.Query().Get("direction")
 
 	statuses := u.Query()["statuses"]
+	excludeClosed, _ := strconv.ParseBool(u.Query().Get("exclude_closed"))
 
 	ownerID := u.Query().Get("owner_user_id")
 	searchTerm := u.Query().Get("search_term")
@@ -1296,6 +1836,11 @@ func parsePlaybookRunsFilterOptions(u *url.URL) (*app.PlaybookRunFilterOptions,
 
 	playbookID := u.Query().Get("playbook_id")
 
+	severity := u.Query().Get("severity")
+
+	customFieldID := u.Query().Get("custom_field_id")
+	customFieldValue := u.Query().Get("custom_field_value")
+
 	activeGTEParam := u.Query().Get("active_gte")
 	if activeGTEParam == "" {
 		activeGTEParam = "0"
@@ -1321,20 +1866,25 @@ func parsePlaybookRunsFilterOptions(u *url.URL) (*app.PlaybookRunFilterOptions,
 	startedLT, _ := strconv.ParseInt(startedLTParam, 10, 64)
 
 	options := app.PlaybookRunFilterOptions{
-		TeamID:     teamID,
-		Page:       page,
-		PerPage:    perPage,
-		Sort:       app.SortField(sort),
-		Direction:  app.SortDirection(direction),
-		Statuses:   statuses,
-		OwnerID:    ownerID,
-		SearchTerm: searchTerm,
-		MemberID:   memberID,
-		PlaybookID: playbookID,
-		ActiveGTE:  activeGTE,
-		ActiveLT:   activeLT,
-		StartedGTE: startedGTE,
-		StartedLT:  startedLT,
+		TeamID:        teamID,
+		Page:          page,
+		PerPage:       perPage,
+		Sort:          app.SortField(sort),
+		Direction:     app.SortDirection(direction),
+		Statuses:      statuses,
+		ExcludeClosed: excludeClosed,
+		OwnerID:       ownerID,
+		SearchTerm:    searchTerm,
+		MemberID:      memberID,
+		PlaybookID:    playbookID,
+		Severity:      severity,
+		ActiveGTE:     activeGTE,
+		ActiveLT:      activeLT,
+		StartedGTE:    startedGTE,
+		StartedLT:     startedLT,
+
+		CustomFieldID:    customFieldID,
+		CustomFieldValue: customFieldValue,
 	}
 
 	options, err = options.Validate()
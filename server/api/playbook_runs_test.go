@@ -248,6 +248,55 @@ func TestPlaybookRuns(t *testing.T) {
 		assert.Equal(t, http.StatusCreated, resp.StatusCode)
 	})
 
+	t.Run("create playbook run from dialog without custom fields, missing required custom field", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		teamID := model.NewId()
+		withid := app.Playbook{
+			ID:                      "playbookid1",
+			Title:                   "My Playbook",
+			TeamID:                  teamID,
+			CreatePublicPlaybookRun: true,
+			MemberIDs:               []string{"testUserID"},
+			CustomFields: []app.CustomField{
+				{ID: "regionfieldid", Name: "Region", Type: app.CustomFieldTypeSelect, Options: []string{"eu", "us"}, Required: true},
+			},
+		}
+
+		dialogRequest := model.SubmitDialogRequest{
+			TeamId: teamID,
+			UserId: "testUserID",
+			State:  "{}",
+			Submission: map[string]interface{}{
+				app.DialogFieldPlaybookIDKey: "playbookid1",
+				app.DialogFieldNameKey:       "playbookRunName",
+			},
+		}
+
+		playbookService.EXPECT().
+			Get("playbookid1").
+			Return(withid, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PERMISSION_CREATE_PUBLIC_CHANNEL).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PERMISSION_VIEW_TEAM).Return(true)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/runs/dialog", bytes.NewBuffer(dialogRequest.ToJson()))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq)
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		submitResponse := model.SubmitDialogResponseFromJson(resp.Body)
+		require.NotNil(t, submitResponse)
+		assert.Contains(t, submitResponse.Errors, app.DialogFieldPlaybookIDKey)
+	})
+
 	t.Run("create playbook run from dialog - no permissions for public channels", func(t *testing.T) {
 		reset(t)
 		setDefaultExpectations(t)
@@ -632,6 +681,102 @@ func TestPlaybookRuns(t *testing.T) {
 		assert.NotEmpty(t, resultPlaybookRun.ID)
 	})
 
+	t.Run("create valid playbook run with custom field values", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		teamID := model.NewId()
+		testPlaybook := app.Playbook{
+			ID:                      "playbookid1",
+			Title:                   "My Playbook",
+			TeamID:                  teamID,
+			CreatePublicPlaybookRun: true,
+			MemberIDs:               []string{"testUserID"},
+			CustomFields: []app.CustomField{
+				{ID: "regionfieldid", Name: "Region", Type: app.CustomFieldTypeSelect, Options: []string{"eu", "us"}, Required: true},
+				{ID: "customersfieldid", Name: "Customers", Type: app.CustomFieldTypeNumber},
+			},
+		}
+
+		testPlaybookRun := app.PlaybookRun{
+			OwnerUserID:     "testUserID",
+			TeamID:          teamID,
+			Name:            "playbookRunName",
+			PlaybookID:      testPlaybook.ID,
+			Checklists:      testPlaybook.Checklists,
+			InvitedUserIDs:  []string{},
+			InvitedGroupIDs: []string{},
+			CustomFields:    testPlaybook.CustomFields,
+			CustomFieldValues: app.CustomFieldValues{
+				"regionfieldid":    {"eu"},
+				"customersfieldid": {"12"},
+			},
+		}
+
+		playbookService.EXPECT().
+			Get("playbookid1").
+			Return(testPlaybook, nil).
+			Times(1)
+
+		retI := testPlaybookRun
+		retI.ID = "playbookRunID"
+		retI.ChannelID = "channelID"
+		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PERMISSION_CREATE_PUBLIC_CHANNEL).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PERMISSION_VIEW_TEAM).Return(true)
+		playbookRunService.EXPECT().CreatePlaybookRun(&testPlaybookRun, &testPlaybook, "testUserID", true).Return(&retI, nil)
+
+		poster.EXPECT().
+			PublishWebsocketEventToUser(gomock.Any(), gomock.Any(), gomock.Any())
+
+		resultPlaybookRun, err := c.PlaybookRuns.Create(context.TODO(), icClient.PlaybookRunCreateOptions{
+			Name:        testPlaybookRun.Name,
+			OwnerUserID: testPlaybookRun.OwnerUserID,
+			TeamID:      testPlaybookRun.TeamID,
+			PlaybookID:  testPlaybookRun.PlaybookID,
+			CustomFieldValues: map[string][]string{
+				"regionfieldid":    {" eu "},
+				"customersfieldid": {"12.0"},
+			},
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, resultPlaybookRun.ID)
+	})
+
+	t.Run("create playbook run, missing required custom field", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		teamID := model.NewId()
+		testPlaybook := app.Playbook{
+			ID:                      "playbookid1",
+			Title:                   "My Playbook",
+			TeamID:                  teamID,
+			CreatePublicPlaybookRun: true,
+			MemberIDs:               []string{"testUserID"},
+			CustomFields: []app.CustomField{
+				{ID: "regionfieldid", Name: "Region", Type: app.CustomFieldTypeSelect, Options: []string{"eu", "us"}, Required: true},
+			},
+		}
+
+		playbookService.EXPECT().
+			Get("playbookid1").
+			Return(testPlaybook, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PERMISSION_CREATE_PUBLIC_CHANNEL).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PERMISSION_VIEW_TEAM).Return(true)
+
+		resultPlaybookRun, err := c.PlaybookRuns.Create(context.TODO(), icClient.PlaybookRunCreateOptions{
+			Name:        "playbookRunName",
+			OwnerUserID: "testUserID",
+			TeamID:      teamID,
+			PlaybookID:  testPlaybook.ID,
+		})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+		assert.Nil(t, resultPlaybookRun)
+	})
+
 	t.Run("create valid playbook run, invite users enabled", func(t *testing.T) {
 		reset(t)
 		setDefaultExpectations(t)
@@ -867,16 +1012,21 @@ func TestPlaybookRuns(t *testing.T) {
 
 		teamID := model.NewId()
 		testPlaybookRun := app.PlaybookRun{
-			ID:              "playbookRunID",
-			OwnerUserID:     "testUserID",
-			TeamID:          teamID,
-			Name:            "playbookRunName",
-			ChannelID:       "channelID",
-			Checklists:      []app.Checklist{},
-			StatusPosts:     []app.StatusPost{},
-			InvitedUserIDs:  []string{},
-			InvitedGroupIDs: []string{},
-			TimelineEvents:  []app.TimelineEvent{},
+			ID:                   "playbookRunID",
+			OwnerUserID:          "testUserID",
+			TeamID:               teamID,
+			Name:                 "playbookRunName",
+			ChannelID:            "channelID",
+			Checklists:           []app.Checklist{},
+			StatusPosts:          []app.StatusPost{},
+			InvitedUserIDs:       []string{},
+			InvitedGroupIDs:      []string{},
+			TimelineEvents:       []app.TimelineEvent{},
+			SeverityLevels:       []string{},
+			StatusWorkflow:       app.StatusWorkflow{States: []app.StatusState{}},
+			CustomFields:         []app.CustomField{},
+			CustomFieldValues:    app.CustomFieldValues{},
+			WebhookSubscriptions: []app.WebhookSubscription{},
 		}
 
 		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
@@ -984,18 +1134,23 @@ func TestPlaybookRuns(t *testing.T) {
 
 		teamID := model.NewId()
 		testPlaybookRun := app.PlaybookRun{
-			ID:              "playbookRunID",
-			OwnerUserID:     "testUserID",
-			TeamID:          teamID,
-			Name:            "playbookRunName",
-			ChannelID:       "channelID",
-			PostID:          "",
-			PlaybookID:      "",
-			Checklists:      []app.Checklist{},
-			StatusPosts:     []app.StatusPost{},
-			InvitedUserIDs:  []string{},
-			InvitedGroupIDs: []string{},
-			TimelineEvents:  []app.TimelineEvent{},
+			ID:                   "playbookRunID",
+			OwnerUserID:          "testUserID",
+			TeamID:               teamID,
+			Name:                 "playbookRunName",
+			ChannelID:            "channelID",
+			PostID:               "",
+			PlaybookID:           "",
+			Checklists:           []app.Checklist{},
+			StatusPosts:          []app.StatusPost{},
+			InvitedUserIDs:       []string{},
+			InvitedGroupIDs:      []string{},
+			TimelineEvents:       []app.TimelineEvent{},
+			SeverityLevels:       []string{},
+			StatusWorkflow:       app.StatusWorkflow{States: []app.StatusState{}},
+			CustomFields:         []app.CustomField{},
+			CustomFieldValues:    app.CustomFieldValues{},
+			WebhookSubscriptions: []app.WebhookSubscription{},
 		}
 
 		pluginAPI.On("GetChannel", testPlaybookRun.ChannelID).
@@ -1058,18 +1213,23 @@ func TestPlaybookRuns(t *testing.T) {
 
 		teamID := model.NewId()
 		testPlaybookRun := app.PlaybookRun{
-			ID:              "playbookRunID",
-			OwnerUserID:     "testUserID",
-			TeamID:          teamID,
-			Name:            "playbookRunName",
-			ChannelID:       "channelID",
-			PostID:          "",
-			PlaybookID:      "",
-			Checklists:      []app.Checklist{},
-			StatusPosts:     []app.StatusPost{},
-			InvitedUserIDs:  []string{},
-			InvitedGroupIDs: []string{},
-			TimelineEvents:  []app.TimelineEvent{},
+			ID:                   "playbookRunID",
+			OwnerUserID:          "testUserID",
+			TeamID:               teamID,
+			Name:                 "playbookRunName",
+			ChannelID:            "channelID",
+			PostID:               "",
+			PlaybookID:           "",
+			Checklists:           []app.Checklist{},
+			StatusPosts:          []app.StatusPost{},
+			InvitedUserIDs:       []string{},
+			InvitedGroupIDs:      []string{},
+			TimelineEvents:       []app.TimelineEvent{},
+			SeverityLevels:       []string{},
+			StatusWorkflow:       app.StatusWorkflow{States: []app.StatusState{}},
+			CustomFields:         []app.CustomField{},
+			CustomFieldValues:    app.CustomFieldValues{},
+			WebhookSubscriptions: []app.WebhookSubscription{},
 		}
 
 		pluginAPI.On("GetChannel", testPlaybookRun.ChannelID).
@@ -1097,18 +1257,23 @@ func TestPlaybookRuns(t *testing.T) {
 
 		teamID := model.NewId()
 		testPlaybookRun := app.PlaybookRun{
-			ID:              "playbookRunID",
-			OwnerUserID:     "testUserID",
-			TeamID:          teamID,
-			Name:            "playbookRunName",
-			ChannelID:       "channelID",
-			PostID:          "",
-			PlaybookID:      "",
-			Checklists:      []app.Checklist{},
-			StatusPosts:     []app.StatusPost{},
-			InvitedUserIDs:  []string{},
-			InvitedGroupIDs: []string{},
-			TimelineEvents:  []app.TimelineEvent{},
+			ID:                   "playbookRunID",
+			OwnerUserID:          "testUserID",
+			TeamID:               teamID,
+			Name:                 "playbookRunName",
+			ChannelID:            "channelID",
+			PostID:               "",
+			PlaybookID:           "",
+			Checklists:           []app.Checklist{},
+			StatusPosts:          []app.StatusPost{},
+			InvitedUserIDs:       []string{},
+			InvitedGroupIDs:      []string{},
+			TimelineEvents:       []app.TimelineEvent{},
+			SeverityLevels:       []string{},
+			StatusWorkflow:       app.StatusWorkflow{States: []app.StatusState{}},
+			CustomFields:         []app.CustomField{},
+			CustomFieldValues:    app.CustomFieldValues{},
+			WebhookSubscriptions: []app.WebhookSubscription{},
 		}
 
 		pluginAPI.On("GetChannel", testPlaybookRun.ChannelID).
@@ -1339,16 +1504,21 @@ func TestPlaybookRuns(t *testing.T) {
 
 		teamID := model.NewId()
 		playbookRun1 := app.PlaybookRun{
-			ID:              "playbookRunID1",
-			OwnerUserID:     "testUserID1",
-			TeamID:          teamID,
-			Name:            "playbookRunName1",
-			ChannelID:       "channelID1",
-			Checklists:      []app.Checklist{},
-			StatusPosts:     []app.StatusPost{},
-			InvitedUserIDs:  []string{},
-			InvitedGroupIDs: []string{},
-			TimelineEvents:  []app.TimelineEvent{},
+			ID:                   "playbookRunID1",
+			OwnerUserID:          "testUserID1",
+			TeamID:               teamID,
+			Name:                 "playbookRunName1",
+			ChannelID:            "channelID1",
+			Checklists:           []app.Checklist{},
+			StatusPosts:          []app.StatusPost{},
+			InvitedUserIDs:       []string{},
+			InvitedGroupIDs:      []string{},
+			TimelineEvents:       []app.TimelineEvent{},
+			SeverityLevels:       []string{},
+			StatusWorkflow:       app.StatusWorkflow{States: []app.StatusState{}},
+			CustomFields:         []app.CustomField{},
+			CustomFieldValues:    app.CustomFieldValues{},
+			WebhookSubscriptions: []app.WebhookSubscription{},
 		}
 
 		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
@@ -1487,6 +1657,38 @@ func TestPlaybookRuns(t *testing.T) {
 		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
 	})
 
+	t.Run("update playbook run status, transition not allowed", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		teamID := model.NewId()
+		testPlaybookRun := app.PlaybookRun{
+			ID:            "playbookRunID",
+			OwnerUserID:   "testUserID",
+			TeamID:        teamID,
+			Name:          "playbookRunName",
+			ChannelID:     "channelID",
+			CurrentStatus: "Triage",
+			StatusWorkflow: app.StatusWorkflow{
+				States: []app.StatusState{
+					{Name: "Triage", Transitions: []string{"Mitigating"}},
+					{Name: "Mitigating", Transitions: []string{"Done"}},
+					{Name: "Done", Closed: true},
+				},
+			},
+		}
+
+		playbookRunService.EXPECT().GetPlaybookRunIDForChannel(testPlaybookRun.ChannelID).Return(testPlaybookRun.ID, nil)
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil).Times(2)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_CREATE_POST).Return(true)
+
+		err := c.PlaybookRuns.UpdateStatus(context.TODO(), "playbookRunID", "Done", "test description", "test message", 600)
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
 	t.Run("update playbook run status, no permission to post", func(t *testing.T) {
 		reset(t)
 		setDefaultExpectations(t)
@@ -1582,4 +1784,696 @@ func TestPlaybookRuns(t *testing.T) {
 		err := c.PlaybookRuns.UpdateStatus(context.TODO(), "playbookRunID", "Active", "  \r \n  ", "test message", 600)
 		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
 	})
+
+	t.Run("update playbook run", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+		broadcastChannelID := model.NewId()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil).Times(2)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		pluginAPI.On("HasPermissionToChannel", "testUserID", broadcastChannelID, model.PERMISSION_CREATE_POST).Return(true)
+
+		updatedPlaybookRun := testPlaybookRun
+		updatedPlaybookRun.Name = "new name"
+		updatedPlaybookRun.BroadcastChannelID = broadcastChannelID
+		playbookRunService.EXPECT().
+			UpdatePlaybookRun("playbookRunID", "testUserID", app.UpdateOptions{
+				Name:               model.NewString("new name"),
+				BroadcastChannelID: model.NewString(broadcastChannelID),
+			}).
+			Return(&updatedPlaybookRun, nil)
+
+		result, err := c.PlaybookRuns.Update(context.TODO(), "playbookRunID", icClient.PlaybookRunUpdateOptions{
+			Name:               model.NewString("new name"),
+			BroadcastChannelID: model.NewString(broadcastChannelID),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "new name", result.Name)
+		assert.Equal(t, broadcastChannelID, result.BroadcastChannelID)
+	})
+
+	t.Run("update playbook run, no permission to post in broadcast channel", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil).Times(2)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_CREATE_POST).Return(false)
+
+		_, err := c.PlaybookRuns.Update(context.TODO(), "playbookRunID", icClient.PlaybookRunUpdateOptions{
+			BroadcastChannelID: model.NewString(model.NewId()),
+		})
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("update playbook run, invalid webhook URL", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil).Times(2)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+
+		_, err := c.PlaybookRuns.Update(context.TODO(), "playbookRunID", icClient.PlaybookRunUpdateOptions{
+			WebhookOnStatusUpdateURL: model.NewString("ftp://example.com"),
+		})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("update playbook run, empty name", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil).Times(2)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+
+		_, err := c.PlaybookRuns.Update(context.TODO(), "playbookRunID", icClient.PlaybookRunUpdateOptions{
+			Name: model.NewString("  "),
+		})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("change playbook run severity", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:             "playbookRunID",
+			OwnerUserID:    "testUserID",
+			TeamID:         model.NewId(),
+			Name:           "playbookRunName",
+			ChannelID:      "channelID",
+			SeverityLevels: []string{"SEV-1", "SEV-2"},
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		playbookRunService.EXPECT().ChangeSeverity("playbookRunID", "testUserID", "SEV-1").Return(nil)
+
+		err := c.PlaybookRuns.UpdateSeverity(context.TODO(), "playbookRunID", " SEV-1 ")
+		require.NoError(t, err)
+	})
+
+	t.Run("change playbook run severity, invalid severity", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:             "playbookRunID",
+			OwnerUserID:    "testUserID",
+			TeamID:         model.NewId(),
+			Name:           "playbookRunName",
+			ChannelID:      "channelID",
+			SeverityLevels: []string{"SEV-1", "SEV-2"},
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		playbookRunService.EXPECT().ChangeSeverity("playbookRunID", "testUserID", "SEV-9").
+			Return(errors.Wrap(app.ErrMalformedPlaybookRun, "severity must be one of: SEV-1, SEV-2"))
+
+		err := c.PlaybookRuns.UpdateSeverity(context.TODO(), "playbookRunID", "SEV-9")
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("change playbook run severity, no permission", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(false)
+
+		err := c.PlaybookRuns.UpdateSeverity(context.TODO(), "playbookRunID", "SEV-1")
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("set checklist item dependencies", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		playbookRunService.EXPECT().SetChecklistItemDependencies("playbookRunID", "testUserID", 1, 0, []string{"itemid"}).Return(nil)
+
+		err := c.PlaybookRuns.SetChecklistItemDependencies(context.TODO(), "playbookRunID", 1, 0, []string{"itemid"})
+		require.NoError(t, err)
+	})
+
+	t.Run("set checklist item dependencies, cycle", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		playbookRunService.EXPECT().SetChecklistItemDependencies("playbookRunID", "testUserID", 0, 0, []string{"itemid"}).
+			Return(errors.Wrap(app.ErrMalformedPlaybookRun, "item 'itemid' depends on itself"))
+
+		err := c.PlaybookRuns.SetChecklistItemDependencies(context.TODO(), "playbookRunID", 0, 0, []string{"itemid"})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("add sub-item and comment", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil).Times(2)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		playbookRunService.EXPECT().AddChecklistSubItem("playbookRunID", "testUserID", 0, 1, app.ChecklistSubItem{Title: "Revert the migration"}).Return(nil)
+		playbookRunService.EXPECT().AddChecklistItemComment("playbookRunID", "testUserID", 0, 1, "Waiting on the DBA.").
+			Return(app.ChecklistItemComment{PostID: "postID", UserID: "testUserID", Message: "Waiting on the DBA."}, nil)
+
+		err := c.PlaybookRuns.AddChecklistSubItem(context.TODO(), "playbookRunID", 0, 1, icClient.ChecklistSubItem{Title: " Revert the migration "})
+		require.NoError(t, err)
+
+		comment, err := c.PlaybookRuns.AddChecklistItemComment(context.TODO(), "playbookRunID", 0, 1, "Waiting on the DBA.")
+		require.NoError(t, err)
+		require.Equal(t, "postID", comment.PostID)
+	})
+
+	t.Run("add sub-item without title", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+
+		err := c.PlaybookRuns.AddChecklistSubItem(context.TODO(), "playbookRunID", 0, 1, icClient.ChecklistSubItem{Title: " "})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("skip checklist item", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		playbookRunService.EXPECT().SkipChecklistItem("playbookRunID", "testUserID", 0, 1, "Not applicable")
+
+		err := c.PlaybookRuns.SkipChecklistItem(context.TODO(), "playbookRunID", 0, 1, "Not applicable")
+		require.NoError(t, err)
+	})
+
+	t.Run("skip checklist item without reason", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+
+		err := c.PlaybookRuns.SkipChecklistItem(context.TODO(), "playbookRunID", 0, 1, " ")
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("bulk update checklists", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		playbookRunService.EXPECT().ApplyChecklistOperations("playbookRunID", "testUserID", []app.ChecklistOperation{
+			{Type: app.ChecklistOperationSetState, ChecklistNum: 0, ItemNum: 1, NewState: app.ChecklistItemStateClosed},
+			{Type: app.ChecklistOperationAdd, ChecklistNum: 1, Item: &app.ChecklistItem{Title: "Write the postmortem"}},
+		})
+
+		err := c.PlaybookRuns.BulkUpdateChecklists(context.TODO(), "playbookRunID", []icClient.ChecklistOperation{
+			{Type: icClient.ChecklistOperationSetState, ChecklistNum: 0, ItemNum: 1, NewState: "closed"},
+			{Type: icClient.ChecklistOperationAdd, ChecklistNum: 1, Item: &icClient.ChecklistItem{Title: "Write the postmortem"}},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("bulk update checklists with invalid operations", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil).Times(2)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		playbookRunService.EXPECT().ApplyChecklistOperations("playbookRunID", "testUserID", gomock.Any()).
+			Return(errors.Wrap(app.ErrMalformedPlaybookRun, "invalid item number"))
+
+		err := c.PlaybookRuns.BulkUpdateChecklists(context.TODO(), "playbookRunID", nil)
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+
+		err = c.PlaybookRuns.BulkUpdateChecklists(context.TODO(), "playbookRunID", []icClient.ChecklistOperation{
+			{Type: icClient.ChecklistOperationSetState, ChecklistNum: 0, ItemNum: 9, NewState: "closed"},
+		})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("get playbook run sends its version as ETag", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+			Version:     3,
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		pluginAPI.On("GetChannel", mock.Anything).Return(&model.Channel{}, nil)
+		playbookRunService.EXPECT().GetPlaybookRun("playbookRunID").Return(&testPlaybookRun, nil)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("GET", "/api/v0/runs/playbookRunID", nil)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq)
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"3"`, resp.Header.Get("ETag"))
+	})
+
+	t.Run("modify playbook run with If-Match", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+			Version:     3,
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil).Times(2)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		withExpectedVersion := mock_app.NewMockPlaybookRunService(mockCtrl)
+		playbookRunService.EXPECT().WithExpectedVersion(int64(3)).Return(withExpectedVersion)
+		withExpectedVersion.EXPECT().SkipChecklistItem("playbookRunID", "testUserID", 0, 1, "Not applicable")
+
+		stale := icClient.WithPlaybookRunVersion(context.TODO(), 2)
+		err := c.PlaybookRuns.SkipChecklistItem(stale, "playbookRunID", 0, 1, "Not applicable")
+		requireErrorWithStatusCode(t, err, http.StatusPreconditionFailed)
+
+		current := icClient.WithPlaybookRunVersion(context.TODO(), 3)
+		err = c.PlaybookRuns.SkipChecklistItem(current, "playbookRunID", 0, 1, "Not applicable")
+		require.NoError(t, err)
+	})
+
+	t.Run("modify playbook run with If-Match, updated while the request is handled", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+			Version:     3,
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		withExpectedVersion := mock_app.NewMockPlaybookRunService(mockCtrl)
+		playbookRunService.EXPECT().WithExpectedVersion(int64(3)).Return(withExpectedVersion)
+		withExpectedVersion.EXPECT().SkipChecklistItem("playbookRunID", "testUserID", 0, 1, "Not applicable").
+			Return(errors.Wrap(app.ErrPlaybookRunPreconditionFailed, "playbook run is no longer at version 3"))
+
+		current := icClient.WithPlaybookRunVersion(context.TODO(), 3)
+		err := c.PlaybookRuns.SkipChecklistItem(current, "playbookRunID", 0, 1, "Not applicable")
+		requireErrorWithStatusCode(t, err, http.StatusPreconditionFailed)
+	})
+
+	t.Run("modify playbook run updated concurrently", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		playbookRunService.EXPECT().SkipChecklistItem("playbookRunID", "testUserID", 0, 1, "Not applicable").
+			Return(errors.Wrap(app.ErrPlaybookRunVersionConflict, "playbook run is no longer at version 0"))
+
+		err := c.PlaybookRuns.SkipChecklistItem(context.TODO(), "playbookRunID", 0, 1, "Not applicable")
+		requireErrorWithStatusCode(t, err, http.StatusConflict)
+	})
+
+	t.Run("list failed webhook deliveries", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+		failedDelivery := app.WebhookDelivery{
+			ID:             model.NewId(),
+			PlaybookRunID:  testPlaybookRun.ID,
+			EventType:      app.WebhookEventStatusUpdated,
+			URL:            "https://example.com/webhook",
+			Payload:        "{}",
+			Status:         app.WebhookDeliveryFailed,
+			Attempts:       app.MaxWebhookDeliveryAttempts,
+			LastStatusCode: http.StatusServiceUnavailable,
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		playbookRunService.EXPECT().GetWebhookDeliveries(testPlaybookRun.ID, app.WebhookDeliveryFailed).
+			Return([]app.WebhookDelivery{failedDelivery}, nil)
+
+		deliveries, err := c.PlaybookRuns.GetWebhookDeliveries(context.TODO(), testPlaybookRun.ID, icClient.WebhookDeliveryFailed)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.Equal(t, failedDelivery.ID, deliveries[0].ID)
+		require.Equal(t, icClient.WebhookDeliveryFailed, deliveries[0].Status)
+		require.Equal(t, app.MaxWebhookDeliveryAttempts, deliveries[0].Attempts)
+		require.Equal(t, http.StatusServiceUnavailable, deliveries[0].LastStatusCode)
+	})
+
+	t.Run("list webhook deliveries, invalid status", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+
+		_, err := c.PlaybookRuns.GetWebhookDeliveries(context.TODO(), testPlaybookRun.ID, "lost")
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("redeliver webhook", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+		redelivery := app.WebhookDelivery{
+			ID:            model.NewId(),
+			PlaybookRunID: testPlaybookRun.ID,
+			EventType:     app.WebhookEventStatusUpdated,
+			URL:           "https://example.com/webhook",
+			Payload:       "{}",
+			Status:        app.WebhookDeliveryPending,
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		playbookRunService.EXPECT().RedeliverWebhook(testPlaybookRun.ID, "failedDeliveryID").Return(&redelivery, nil)
+
+		delivery, err := c.PlaybookRuns.RedeliverWebhook(context.TODO(), testPlaybookRun.ID, "failedDeliveryID")
+		require.NoError(t, err)
+		require.Equal(t, redelivery.ID, delivery.ID)
+		require.Equal(t, icClient.WebhookDeliveryPending, delivery.Status)
+	})
+
+	t.Run("redeliver webhook, delivery not failed", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(true)
+		playbookRunService.EXPECT().RedeliverWebhook(testPlaybookRun.ID, "pendingDeliveryID").
+			Return(nil, errors.Wrap(app.ErrMalformedPlaybookRun, "only failed webhook deliveries can be redelivered"))
+
+		_, err := c.PlaybookRuns.RedeliverWebhook(context.TODO(), testPlaybookRun.ID, "pendingDeliveryID")
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("redeliver webhook, no permission", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PERMISSION_READ_CHANNEL).Return(false)
+
+		_, err := c.PlaybookRuns.RedeliverWebhook(context.TODO(), testPlaybookRun.ID, "failedDeliveryID")
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("list tasks", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		teamID := model.NewId()
+		task := app.PlaybookRunChecklistItem{
+			ChecklistItem: app.ChecklistItem{
+				ID:         "itemID",
+				Title:      "Page the DBA",
+				State:      app.ChecklistItemStateInProgress,
+				AssigneeID: "testUserID",
+				DueAt:      1000,
+			},
+			PlaybookRunID:   "playbookRunID",
+			PlaybookRunName: "playbookRunName",
+			TeamID:          teamID,
+			ChannelID:       "channelID",
+			ChannelName:     "channel-name",
+			ChecklistNum:    1,
+			ItemNum:         2,
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		pluginAPI.On("GetUser", "testUserID").Return(&model.User{}, nil)
+		playbookRunService.EXPECT().GetChecklistItems(gomock.Any(), app.ChecklistItemFilterOptions{
+			TeamID:         teamID,
+			AssigneeID:     "testUserID",
+			States:         []string{app.ChecklistItemStateOpen},
+			ActiveRunsOnly: true,
+			Sort:           app.SortByAssignedAt,
+			Direction:      app.DirectionDesc,
+		}).Return([]app.PlaybookRunChecklistItem{task}, nil)
+
+		tasks, err := c.PlaybookRuns.ListTasks(context.TODO(), icClient.TaskListOptions{
+			TeamID:    teamID,
+			States:    []string{"open"},
+			Sort:      icClient.SortByAssignedAt,
+			Direction: icClient.SortDesc,
+		})
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		require.Equal(t, "Page the DBA", tasks[0].Title)
+		require.Equal(t, "playbookRunID", tasks[0].PlaybookRunID)
+		require.Equal(t, "channel-name", tasks[0].ChannelName)
+		require.Equal(t, 1, tasks[0].ChecklistNum)
+		require.Equal(t, 2, tasks[0].ItemNum)
+	})
+
+	t.Run("list tasks, default options", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		pluginAPI.On("GetUser", "testUserID").Return(&model.User{}, nil)
+		playbookRunService.EXPECT().GetChecklistItems(gomock.Any(), app.ChecklistItemFilterOptions{
+			AssigneeID:     "testUserID",
+			States:         []string{app.ChecklistItemStateOpen, app.ChecklistItemStateInProgress},
+			ActiveRunsOnly: true,
+			Sort:           app.SortByDueAt,
+			Direction:      app.DirectionAsc,
+		}).Return(nil, nil)
+
+		tasks, err := c.PlaybookRuns.ListTasks(context.TODO(), icClient.TaskListOptions{})
+		require.NoError(t, err)
+		require.NotNil(t, tasks)
+		require.Len(t, tasks, 0)
+	})
+
+	t.Run("list tasks, invalid sort", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		_, err := c.PlaybookRuns.ListTasks(context.TODO(), icClient.TaskListOptions{Sort: icClient.SortByName})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("list tasks, invalid state", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		_, err := c.PlaybookRuns.ListTasks(context.TODO(), icClient.TaskListOptions{States: []string{"done"}})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
 }
@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -29,6 +30,8 @@ type PlaybookHandler struct {
 
 const SettingsKey = "global_settings"
 const maxPlaybooksToAutocomplete = 15
+const maxPlaybookImportSize = 1024 * 1024
+const maxPlaybookSyncArchiveSize = 20 * 1024 * 1024
 
 // NewPlaybookHandler returns a new playbook api handler
 func NewPlaybookHandler(router *mux.Router, playbookService app.PlaybookService, api *pluginapi.Client, log bot.Logger, configService config.Service) *PlaybookHandler {
@@ -47,11 +50,19 @@ func NewPlaybookHandler(router *mux.Router, playbookService app.PlaybookService,
 	playbooksRouter.HandleFunc("", handler.getPlaybooks).Methods(http.MethodGet)
 	playbooksRouter.HandleFunc("/autocomplete", handler.getPlaybooksAutoComplete).Methods(http.MethodGet)
 	playbooksRouter.HandleFunc("/count", handler.getPlaybookCount).Methods(http.MethodGet)
+	playbooksRouter.HandleFunc("/import", handler.importPlaybook).Methods(http.MethodPost)
+	playbooksRouter.HandleFunc("/sync", handler.syncPlaybooks).Methods(http.MethodPost)
 
 	playbookRouter := playbooksRouter.PathPrefix("/{id:[A-Za-z0-9]+}").Subrouter()
 	playbookRouter.HandleFunc("", handler.getPlaybook).Methods(http.MethodGet)
 	playbookRouter.HandleFunc("", handler.updatePlaybook).Methods(http.MethodPut)
 	playbookRouter.HandleFunc("", handler.deletePlaybook).Methods(http.MethodDelete)
+	playbookRouter.HandleFunc("/undelete", handler.undeletePlaybook).Methods(http.MethodPost)
+	playbookRouter.HandleFunc("/duplicate", handler.duplicatePlaybook).Methods(http.MethodPost)
+	playbookRouter.HandleFunc("/export", handler.exportPlaybook).Methods(http.MethodGet)
+	playbookRouter.HandleFunc("/revisions", handler.getPlaybookRevisions).Methods(http.MethodGet)
+	playbookRouter.HandleFunc("/revisions/{revision:[0-9]+}", handler.getPlaybookRevision).Methods(http.MethodGet)
+	playbookRouter.HandleFunc("/revisions/{revision:[0-9]+}/restore", handler.restorePlaybookRevision).Methods(http.MethodPost)
 
 	return handler
 }
@@ -70,53 +81,46 @@ func (h *PlaybookHandler) createPlaybook(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := app.CreatePlaybook(userID, playbook, h.config, h.pluginAPI, h.playbookService); err != nil {
-		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+	if playbook.SyncPath != "" {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "Playbooks can only be synced from files by syncing them", nil)
 		return
 	}
 
-	if playbook.WebhookOnCreationEnabled {
-		url, err := url.ParseRequestURI(playbook.WebhookOnCreationURL)
-		if err != nil {
-			h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid creation webhook URL", err)
-			return
-		}
+	app.KeepPlaybookSecrets(&playbook, app.Playbook{})
 
-		if url.Scheme != "http" && url.Scheme != "https" {
-			msg := fmt.Sprintf("protocol in creation webhook URL is %s; only HTTP and HTTPS are accepted", url.Scheme)
-			h.HandleErrorWithCode(w, http.StatusBadRequest, msg, errors.Errorf(msg))
-			return
-		}
+	if err := app.CreatePlaybook(userID, playbook, h.config, h.pluginAPI, h.playbookService); errors.Is(err, app.ErrMalformedPlaybook) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+		return
+	} else if errors.Is(err, app.ErrNoPermissions) || errors.Is(err, app.ErrLicensedFeature) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, err)
+		return
 	}
 
-	if playbook.WebhookOnStatusUpdateEnabled {
-		url, err := url.ParseRequestURI(playbook.WebhookOnStatusUpdateURL)
-		if err != nil {
-			h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid update webhook URL", err)
-			return
-		}
-
-		if url.Scheme != "http" && url.Scheme != "https" {
-			msg := fmt.Sprintf("protocol in update webhook URL is %s; only HTTP and HTTPS are accepted", url.Scheme)
-			h.HandleErrorWithCode(w, http.StatusBadRequest, msg, errors.Errorf(msg))
-			return
-		}
+	if err := doPlaybookModificationChecks(&playbook, userID, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
 	}
 
-	if len(playbook.SignalAnyKeywords) != 0 {
-		playbook.SignalAnyKeywords = removeDuplicates(playbook.SignalAnyKeywords)
+	if err := app.ValidatePlaybook(&playbook); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+		return
 	}
 
+	app.AssignCustomFieldIDs(playbook.CustomFields)
+	generatedToken := assignInboundWebhookToken(&playbook)
+
 	id, err := h.playbookService.Create(playbook, userID)
 	if err != nil {
 		h.HandleError(w, err)
 		return
 	}
 
-	result := struct {
-		ID string `json:"id"`
-	}{
-		ID: id,
+	result := savedPlaybookResult{
+		ID:                  id,
+		InboundWebhookToken: generatedToken,
 	}
 	w.Header().Add("Location", fmt.Sprintf("/api/v0/playbooks/%s", playbook.ID))
 	ReturnJSON(w, &result, http.StatusCreated)
@@ -137,6 +141,8 @@ func (h *PlaybookHandler) getPlaybook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	app.RedactPlaybookSecrets(&playbook)
+
 	ReturnJSON(w, &playbook, http.StatusOK)
 }
 
@@ -158,55 +164,72 @@ func (h *PlaybookHandler) updatePlaybook(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	app.KeepPlaybookSecrets(&playbook, oldPlaybook)
+
 	if err3 := app.PlaybookModify(userID, playbook, oldPlaybook, h.config, h.pluginAPI, h.playbookService); err3 != nil {
 		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err3)
 		return
 	}
 
+	if !h.checkPlaybookNotSynced(w, oldPlaybook) {
+		return
+	}
+	playbook.SyncPath = ""
+
 	if err4 := doPlaybookModificationChecks(&playbook, userID, h.pluginAPI); err4 != nil {
 		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err4)
 		return
 	}
 
-	if playbook.WebhookOnCreationEnabled {
-		url, err2 := url.ParseRequestURI(playbook.WebhookOnCreationURL)
-		if err2 != nil {
-			h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid creation webhook URL", err2)
-			return
-		}
-
-		if url.Scheme != "http" && url.Scheme != "https" {
-			msg := fmt.Sprintf("protocol in creation webhook URL is %s; only HTTP and HTTPS are accepted", url.Scheme)
-			h.HandleErrorWithCode(w, http.StatusBadRequest, msg, errors.Errorf(msg))
-			return
-		}
+	if err = app.ValidatePlaybook(&playbook); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+		return
 	}
 
-	if playbook.WebhookOnStatusUpdateEnabled {
-		url, err2 := url.ParseRequestURI(playbook.WebhookOnStatusUpdateURL)
-		if err2 != nil {
-			h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid update webhook URL", err2)
-			return
-		}
+	app.AssignCustomFieldIDs(playbook.CustomFields)
+	generatedToken := assignInboundWebhookToken(&playbook)
 
-		if url.Scheme != "http" && url.Scheme != "https" {
-			msg := fmt.Sprintf("protocol in update webhook URL is %s; only HTTP and HTTPS are accepted", url.Scheme)
-			h.HandleErrorWithCode(w, http.StatusBadRequest, msg, errors.Errorf(msg))
-			return
-		}
+	err = h.playbookService.Update(playbook, userID)
+	if errors.Is(err, app.ErrPlaybookRevisionConflict) {
+		h.HandleErrorWithCode(w, http.StatusConflict, "The playbook was updated by someone else, try again", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, err)
+		return
 	}
 
-	if len(playbook.SignalAnyKeywords) != 0 {
-		playbook.SignalAnyKeywords = removeDuplicates(playbook.SignalAnyKeywords)
+	ReturnJSON(w, &savedPlaybookResult{InboundWebhookToken: generatedToken}, http.StatusOK)
+}
+
+// savedPlaybookResult is the response to creating or updating a playbook. As inbound webhook
+// tokens are write-only, a token is only sent back when it was generated by the request.
+type savedPlaybookResult struct {
+	ID                  string `json:"id,omitempty"`
+	InboundWebhookToken string `json:"inbound_webhook_token,omitempty"`
+}
+
+// assignInboundWebhookToken generates a token for the playbook's inbound webhook if it is enabled
+// without one, returning the generated token, if any.
+func assignInboundWebhookToken(playbook *app.Playbook) string {
+	if playbook.InboundWebhook.Token != "" {
+		return ""
 	}
 
-	err = h.playbookService.Update(playbook, userID)
-	if err != nil {
-		h.HandleError(w, err)
-		return
+	app.AssignInboundWebhookToken(playbook)
+	return playbook.InboundWebhook.Token
+}
+
+// checkPlaybookNotSynced writes an error response and returns false if the playbook is synced from
+// a file, as it may then only be changed through its file.
+func (h *PlaybookHandler) checkPlaybookNotSynced(w http.ResponseWriter, playbook app.Playbook) bool {
+	if playbook.SyncPath == "" {
+		return true
 	}
 
-	w.WriteHeader(http.StatusOK)
+	h.HandleErrorWithCode(w, http.StatusForbidden,
+		fmt.Sprintf("The playbook is synced from the file %s, change that file instead", playbook.SyncPath),
+		errors.Errorf("playbook %s is synced from %s", playbook.ID, playbook.SyncPath))
+	return false
 }
 
 // doPlaybookModificationChecks performs permissions checks that can be resolved though modification of the input.
@@ -246,6 +269,31 @@ func doPlaybookModificationChecks(playbook *app.Playbook, userID string, pluginA
 		playbook.DefaultOwnerEnabled = false
 	}
 
+	escalation := &playbook.ReminderEscalation
+	removedBackup := false
+	if escalation.BackupUserID != "" && !app.IsMemberOfTeamID(escalation.BackupUserID, playbook.TeamID, pluginAPI) {
+		pluginAPI.Log.Warn("backup user is not a member of the playbook's team, removing from reminder escalation", "teamID", playbook.TeamID, "userID", escalation.BackupUserID)
+		escalation.BackupUserID = ""
+		removedBackup = true
+	}
+	if escalation.BackupGroupID != "" {
+		group, err := pluginAPI.Group.Get(escalation.BackupGroupID)
+		if err != nil || !group.AllowReference {
+			pluginAPI.Log.Warn("backup group does not allow references, removing from reminder escalation", "group_id", escalation.BackupGroupID)
+			escalation.BackupGroupID = ""
+			removedBackup = true
+		}
+	}
+	if removedBackup && escalation.BackupUserID == "" && escalation.BackupGroupID == "" {
+		pluginAPI.Log.Warn("no backup is left to notify, disabling the backup reminder escalation")
+		escalation.BackupAfterMissed = 0
+	}
+
+	if serviceUserID := playbook.InboundWebhook.ServiceUserID; serviceUserID != "" && !app.IsBotOfTeamID(serviceUserID, playbook.TeamID, pluginAPI) {
+		pluginAPI.Log.Warn("service user is not a bot of the playbook's team, posting inbound status updates as the plugin's bot", "teamID", playbook.TeamID, "userID", serviceUserID)
+		playbook.InboundWebhook.ServiceUserID = ""
+	}
+
 	if playbook.AnnouncementChannelID != "" &&
 		!pluginAPI.User.HasPermissionToChannel(userID, playbook.AnnouncementChannelID, model.PERMISSION_CREATE_POST) {
 		pluginAPI.Log.Warn("announcement channel is not valid, disabling announcement channel setting")
@@ -271,6 +319,10 @@ func (h *PlaybookHandler) deletePlaybook(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !h.checkPlaybookNotSynced(w, playbookToDelete) {
+		return
+	}
+
 	err = h.playbookService.Delete(playbookToDelete, userID)
 	if err != nil {
 		h.HandleError(w, err)
@@ -280,6 +332,342 @@ func (h *PlaybookHandler) deletePlaybook(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *PlaybookHandler) undeletePlaybook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	playbookToUndelete, err := h.playbookService.Get(vars["id"])
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	if err = app.PlaybookAccess(userID, playbookToUndelete, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	if playbookToUndelete.DeleteAt == 0 {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "Playbook is not deleted", nil)
+		return
+	}
+
+	if !h.checkPlaybookNotSynced(w, playbookToUndelete) {
+		return
+	}
+
+	// The playbook counts again towards the playbooks of the team, as if it was created.
+	if err = app.PlaybookLicensedFeatures(playbookToUndelete, h.config, h.playbookService); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	err = h.playbookService.Undelete(playbookToUndelete, userID)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// duplicatePlaybook creates a copy of the playbook in its team, or in the team given.
+func (h *PlaybookHandler) duplicatePlaybook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	playbook, err := h.playbookService.Get(vars["id"])
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	if err = app.PlaybookAccess(userID, playbook, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	teamID := r.URL.Query().Get("team_id")
+	if teamID == "" {
+		teamID = playbook.TeamID
+	}
+
+	result, err := h.playbookService.Duplicate(playbook, teamID, userID)
+	if errors.Is(err, app.ErrPermission) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	} else if errors.Is(err, app.ErrMalformedPlaybook) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+		return
+	} else if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	w.Header().Add("Location", fmt.Sprintf("/api/v0/playbooks/%s", result.ID))
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
+func (h *PlaybookHandler) exportPlaybook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	format, err := app.ParsePlaybookExportFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	playbook, err := h.playbookService.Get(vars["id"])
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	if err = app.PlaybookAccess(userID, playbook, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	data, err := app.MarshalPlaybookExport(h.playbookService.Export(playbook), format)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	contentType := "application/json"
+	if format == app.PlaybookExportFormatYAML {
+		contentType = "application/yaml"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"playbook-%s.%s\"", playbook.ID, format))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+func (h *PlaybookHandler) importPlaybook(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	teamID := r.URL.Query().Get("team_id")
+	if teamID == "" {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "Provide a team ID", nil)
+		return
+	}
+
+	// Without an explicit format, YAML documents are recognized by their content type.
+	formatName := r.URL.Query().Get("format")
+	if formatName == "" && strings.Contains(r.Header.Get("Content-Type"), "yaml") {
+		formatName = string(app.PlaybookExportFormatYAML)
+	}
+	format, err := app.ParsePlaybookExportFormat(formatName)
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxPlaybookImportSize))
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to read playbook export", err)
+		return
+	}
+
+	export, err := app.UnmarshalPlaybookExport(data, format)
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	result, err := h.playbookService.Import(export, teamID, userID)
+	if errors.Is(err, app.ErrPermission) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	} else if errors.Is(err, app.ErrMalformedPlaybook) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+		return
+	} else if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	w.Header().Add("Location", fmt.Sprintf("/api/v0/playbooks/%s", result.ID))
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
+// syncPlaybooks syncs the playbooks of a team with the playbook files of the uploaded zip archive,
+// or of the team's directory in the configured sync directory if no archive is uploaded.
+func (h *PlaybookHandler) syncPlaybooks(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	teamID := r.URL.Query().Get("team_id")
+	if teamID == "" {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "Provide a team ID", nil)
+		return
+	}
+
+	if !app.IsAdmin(userID, h.pluginAPI) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf("userID %s is not an admin", userID))
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+	var result app.PlaybookSyncResult
+	var err error
+	if strings.Contains(r.Header.Get("Content-Type"), "zip") {
+		var data []byte
+		data, err = ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxPlaybookSyncArchiveSize))
+		if err != nil {
+			h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to read playbooks archive", err)
+			return
+		}
+
+		var files map[string][]byte
+		files, err = app.ReadPlaybookSyncArchive(data)
+		if err != nil {
+			h.HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+
+		result, err = h.playbookService.Sync(teamID, userID, files, dryRun)
+	} else {
+		result, err = h.playbookService.SyncFromDirectory(teamID, userID, dryRun)
+	}
+
+	if errors.Is(err, app.ErrPlaybookSyncNotConfigured) || errors.Is(err, app.ErrMalformedPlaybook) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+		return
+	} else if errors.Is(err, app.ErrPermission) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, err.Error(), err)
+		return
+	} else if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, http.StatusNotFound, err.Error(), err)
+		return
+	} else if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, &result, http.StatusOK)
+}
+
+func (h *PlaybookHandler) getPlaybookRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	playbook, err := h.playbookService.Get(vars["id"])
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	if err = app.PlaybookAccess(userID, playbook, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	revisions, err := h.playbookService.GetRevisions(playbook.ID)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, revisions, http.StatusOK)
+}
+
+// getRevision returns the playbook and the revision of it addressed by the request, or writes the
+// error response and returns false.
+func (h *PlaybookHandler) getRevision(w http.ResponseWriter, r *http.Request) (app.Playbook, app.PlaybookRevision, bool) {
+	vars := mux.Vars(r)
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	revisionNumber, err := strconv.ParseInt(vars["revision"], 10, 64)
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "Invalid revision", err)
+		return app.Playbook{}, app.PlaybookRevision{}, false
+	}
+
+	playbook, err := h.playbookService.Get(vars["id"])
+	if err != nil {
+		h.HandleError(w, err)
+		return app.Playbook{}, app.PlaybookRevision{}, false
+	}
+
+	if err = app.PlaybookAccess(userID, playbook, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return app.Playbook{}, app.PlaybookRevision{}, false
+	}
+
+	revision, err := h.playbookService.GetRevision(playbook.ID, revisionNumber)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, http.StatusNotFound, "Revision not found", err)
+		return app.Playbook{}, app.PlaybookRevision{}, false
+	} else if err != nil {
+		h.HandleError(w, err)
+		return app.Playbook{}, app.PlaybookRevision{}, false
+	}
+
+	return playbook, revision, true
+}
+
+func (h *PlaybookHandler) getPlaybookRevision(w http.ResponseWriter, r *http.Request) {
+	_, revision, ok := h.getRevision(w, r)
+	if !ok {
+		return
+	}
+
+	ReturnJSON(w, &revision, http.StatusOK)
+}
+
+// restorePlaybookRevision updates the playbook to its settings in the revision, saving them as a
+// new revision.
+func (h *PlaybookHandler) restorePlaybookRevision(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	playbook, revision, ok := h.getRevision(w, r)
+	if !ok {
+		return
+	}
+
+	restored, err := revision.RestoredPlaybook(playbook)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	if err = app.PlaybookModify(userID, restored, playbook, h.config, h.pluginAPI, h.playbookService); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	if !h.checkPlaybookNotSynced(w, playbook) {
+		return
+	}
+
+	if err = doPlaybookModificationChecks(&restored, userID, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	if err = app.ValidatePlaybook(&restored); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	app.AssignCustomFieldIDs(restored.CustomFields)
+	generatedToken := assignInboundWebhookToken(&restored)
+
+	err = h.playbookService.Update(restored, userID)
+	if errors.Is(err, app.ErrPlaybookRevisionConflict) {
+		h.HandleErrorWithCode(w, http.StatusConflict, "The playbook was updated by someone else, try again", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, &savedPlaybookResult{InboundWebhookToken: generatedToken}, http.StatusOK)
+}
+
 func (h *PlaybookHandler) getPlaybooks(w http.ResponseWriter, r *http.Request) {
 	params := r.URL.Query()
 	teamID := params.Get("team_id")
@@ -329,6 +717,10 @@ func (h *PlaybookHandler) getPlaybooks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	for i := range playbookResults.Items {
+		app.RedactPlaybookSecrets(&playbookResults.Items[i])
+	}
+
 	ReturnJSON(w, playbookResults, http.StatusOK)
 }
 
@@ -443,24 +835,34 @@ func parseGetPlaybooksOptions(u *url.URL) (app.PlaybookFilterOptions, error) {
 		return app.PlaybookFilterOptions{}, errors.Errorf("bad parameter 'per_page': it should be a positive number")
 	}
 
-	return app.PlaybookFilterOptions{
-		Sort:      sortField,
-		Direction: sortDirection,
-		Page:      page,
-		PerPage:   perPage,
-	}, nil
-}
+	var keywordsEnabled *bool
+	if param = params.Get("keywords_enabled"); param != "" {
+		enabled, errParse := strconv.ParseBool(param)
+		if errParse != nil {
+			return app.PlaybookFilterOptions{}, errors.Wrapf(errParse, "bad parameter 'keywords_enabled': it should be true or false")
+		}
+		keywordsEnabled = &enabled
+	}
 
-func removeDuplicates(a []string) []string {
-	items := make(map[string]bool)
-	for _, item := range a {
-		if item != "" {
-			items[item] = true
+	includeArchived := false
+	if param = params.Get("include_archived"); param != "" {
+		includeArchived, err = strconv.ParseBool(param)
+		if err != nil {
+			return app.PlaybookFilterOptions{}, errors.Wrapf(err, "bad parameter 'include_archived': it should be true or false")
 		}
 	}
-	res := make([]string, 0, len(items))
-	for item := range items {
-		res = append(res, item)
+
+	options := app.PlaybookFilterOptions{
+		Sort:            sortField,
+		Direction:       sortDirection,
+		Page:            page,
+		PerPage:         perPage,
+		SearchTerm:      params.Get("search_term"),
+		MemberID:        params.Get("member_id"),
+		CreatorID:       params.Get("creator_id"),
+		KeywordsEnabled: keywordsEnabled,
+		IncludeArchived: includeArchived,
 	}
-	return res
+
+	return options.Validate()
 }
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -48,9 +49,13 @@ func TestPlaybooks(t *testing.T) {
 				},
 			},
 		},
-		MemberIDs:       []string{},
-		InvitedUserIDs:  []string{},
-		InvitedGroupIDs: []string{},
+		MemberIDs:            []string{},
+		InvitedUserIDs:       []string{},
+		InvitedGroupIDs:      []string{},
+		SeverityLevels:       []string{},
+		StatusWorkflow:       app.StatusWorkflow{States: []app.StatusState{}},
+		CustomFields:         []app.CustomField{},
+		WebhookSubscriptions: []app.WebhookSubscription{},
 	}
 	withid := app.Playbook{
 		ID:     "testplaybookid",
@@ -66,9 +71,13 @@ func TestPlaybooks(t *testing.T) {
 				},
 			},
 		},
-		MemberIDs:       []string{},
-		InvitedUserIDs:  []string{},
-		InvitedGroupIDs: []string{},
+		MemberIDs:            []string{},
+		InvitedUserIDs:       []string{},
+		InvitedGroupIDs:      []string{},
+		SeverityLevels:       []string{},
+		StatusWorkflow:       app.StatusWorkflow{States: []app.StatusState{}},
+		CustomFields:         []app.CustomField{},
+		WebhookSubscriptions: []app.WebhookSubscription{},
 	}
 
 	withMember := app.Playbook{
@@ -85,9 +94,13 @@ func TestPlaybooks(t *testing.T) {
 				},
 			},
 		},
-		MemberIDs:       []string{"testuserid"},
-		InvitedUserIDs:  []string{},
-		InvitedGroupIDs: []string{},
+		MemberIDs:            []string{"testuserid"},
+		InvitedUserIDs:       []string{},
+		InvitedGroupIDs:      []string{},
+		SeverityLevels:       []string{},
+		StatusWorkflow:       app.StatusWorkflow{States: []app.StatusState{}},
+		CustomFields:         []app.CustomField{},
+		WebhookSubscriptions: []app.WebhookSubscription{},
 	}
 	withBroadcastChannel := app.Playbook{
 		ID:     "testplaybookid",
@@ -103,10 +116,14 @@ func TestPlaybooks(t *testing.T) {
 				},
 			},
 		},
-		MemberIDs:          []string{"testuserid"},
-		BroadcastChannelID: "nonemptychannelid",
-		InvitedUserIDs:     []string{},
-		InvitedGroupIDs:    []string{},
+		MemberIDs:            []string{"testuserid"},
+		BroadcastChannelID:   "nonemptychannelid",
+		InvitedUserIDs:       []string{},
+		InvitedGroupIDs:      []string{},
+		SeverityLevels:       []string{},
+		StatusWorkflow:       app.StatusWorkflow{States: []app.StatusState{}},
+		CustomFields:         []app.CustomField{},
+		WebhookSubscriptions: []app.WebhookSubscription{},
 	}
 
 	var mockCtrl *gomock.Controller
@@ -202,12 +219,16 @@ func TestPlaybooks(t *testing.T) {
 			Times(1)
 
 		resultPlaybook, err := c.Playbooks.Create(context.TODO(), icClient.PlaybookCreateOptions{
-			Title:           playbooktest.Title,
-			TeamID:          playbooktest.TeamID,
-			Checklists:      toAPIChecklists(playbooktest.Checklists),
-			MemberIDs:       playbooktest.MemberIDs,
-			InvitedUserIDs:  playbooktest.InvitedUserIDs,
-			InvitedGroupIDs: playbooktest.InvitedGroupIDs,
+			Title:                playbooktest.Title,
+			TeamID:               playbooktest.TeamID,
+			Checklists:           toAPIChecklists(playbooktest.Checklists),
+			MemberIDs:            playbooktest.MemberIDs,
+			InvitedUserIDs:       playbooktest.InvitedUserIDs,
+			InvitedGroupIDs:      playbooktest.InvitedGroupIDs,
+			SeverityLevels:       playbooktest.SeverityLevels,
+			StatusWorkflow:       toAPIStatusWorkflow(playbooktest.StatusWorkflow),
+			CustomFields:         toAPICustomFields(playbooktest.CustomFields),
+			WebhookSubscriptions: toAPIWebhookSubscriptions(playbooktest.WebhookSubscriptions),
 		})
 		requireErrorWithStatusCode(t, err, http.StatusForbidden)
 		assert.Nil(t, resultPlaybook)
@@ -255,12 +276,16 @@ func TestPlaybooks(t *testing.T) {
 			Times(1)
 
 		resultPlaybook, err := c.Playbooks.Create(context.TODO(), icClient.PlaybookCreateOptions{
-			Title:           playbooktest.Title,
-			TeamID:          playbooktest.TeamID,
-			Checklists:      toAPIChecklists(playbooktest.Checklists),
-			MemberIDs:       playbooktest.MemberIDs,
-			InvitedUserIDs:  playbooktest.InvitedUserIDs,
-			InvitedGroupIDs: playbooktest.InvitedGroupIDs,
+			Title:                playbooktest.Title,
+			TeamID:               playbooktest.TeamID,
+			Checklists:           toAPIChecklists(playbooktest.Checklists),
+			MemberIDs:            playbooktest.MemberIDs,
+			InvitedUserIDs:       playbooktest.InvitedUserIDs,
+			InvitedGroupIDs:      playbooktest.InvitedGroupIDs,
+			SeverityLevels:       playbooktest.SeverityLevels,
+			StatusWorkflow:       toAPIStatusWorkflow(playbooktest.StatusWorkflow),
+			CustomFields:         toAPICustomFields(playbooktest.CustomFields),
+			WebhookSubscriptions: toAPIWebhookSubscriptions(playbooktest.WebhookSubscriptions),
 		})
 		require.NoError(t, err)
 		assert.NotEmpty(t, resultPlaybook.ID)
@@ -314,12 +339,16 @@ func TestPlaybooks(t *testing.T) {
 			Times(1)
 
 		resultPlaybook, err := c.Playbooks.Create(context.TODO(), icClient.PlaybookCreateOptions{
-			Title:           playbooktest.Title,
-			TeamID:          playbooktest.TeamID,
-			Checklists:      toAPIChecklists(playbooktest.Checklists),
-			MemberIDs:       playbooktest.MemberIDs,
-			InvitedUserIDs:  playbooktest.InvitedUserIDs,
-			InvitedGroupIDs: playbooktest.InvitedGroupIDs,
+			Title:                playbooktest.Title,
+			TeamID:               playbooktest.TeamID,
+			Checklists:           toAPIChecklists(playbooktest.Checklists),
+			MemberIDs:            playbooktest.MemberIDs,
+			InvitedUserIDs:       playbooktest.InvitedUserIDs,
+			InvitedGroupIDs:      playbooktest.InvitedGroupIDs,
+			SeverityLevels:       playbooktest.SeverityLevels,
+			StatusWorkflow:       toAPIStatusWorkflow(playbooktest.StatusWorkflow),
+			CustomFields:         toAPICustomFields(playbooktest.CustomFields),
+			WebhookSubscriptions: toAPIWebhookSubscriptions(playbooktest.WebhookSubscriptions),
 		})
 		require.NoError(t, err)
 		assert.NotEmpty(t, resultPlaybook.ID)
@@ -373,12 +402,16 @@ func TestPlaybooks(t *testing.T) {
 			Times(1)
 
 		resultPlaybook, err := c.Playbooks.Create(context.TODO(), icClient.PlaybookCreateOptions{
-			Title:           playbooktest.Title,
-			TeamID:          playbooktest.TeamID,
-			Checklists:      toAPIChecklists(playbooktest.Checklists),
-			MemberIDs:       playbooktest.MemberIDs,
-			InvitedUserIDs:  playbooktest.InvitedUserIDs,
-			InvitedGroupIDs: playbooktest.InvitedGroupIDs,
+			Title:                playbooktest.Title,
+			TeamID:               playbooktest.TeamID,
+			Checklists:           toAPIChecklists(playbooktest.Checklists),
+			MemberIDs:            playbooktest.MemberIDs,
+			InvitedUserIDs:       playbooktest.InvitedUserIDs,
+			InvitedGroupIDs:      playbooktest.InvitedGroupIDs,
+			SeverityLevels:       playbooktest.SeverityLevels,
+			StatusWorkflow:       toAPIStatusWorkflow(playbooktest.StatusWorkflow),
+			CustomFields:         toAPICustomFields(playbooktest.CustomFields),
+			WebhookSubscriptions: toAPIWebhookSubscriptions(playbooktest.WebhookSubscriptions),
 		})
 		require.NoError(t, err)
 		assert.NotEmpty(t, resultPlaybook.ID)
@@ -427,12 +460,16 @@ func TestPlaybooks(t *testing.T) {
 			Times(1)
 
 		resultPlaybook, err := c.Playbooks.Create(context.TODO(), icClient.PlaybookCreateOptions{
-			Title:           playbooktest.Title,
-			TeamID:          playbooktest.TeamID,
-			Checklists:      toAPIChecklists(playbooktest.Checklists),
-			MemberIDs:       playbooktest.MemberIDs,
-			InvitedUserIDs:  playbooktest.InvitedUserIDs,
-			InvitedGroupIDs: playbooktest.InvitedGroupIDs,
+			Title:                playbooktest.Title,
+			TeamID:               playbooktest.TeamID,
+			Checklists:           toAPIChecklists(playbooktest.Checklists),
+			MemberIDs:            playbooktest.MemberIDs,
+			InvitedUserIDs:       playbooktest.InvitedUserIDs,
+			InvitedGroupIDs:      playbooktest.InvitedGroupIDs,
+			SeverityLevels:       playbooktest.SeverityLevels,
+			StatusWorkflow:       toAPIStatusWorkflow(playbooktest.StatusWorkflow),
+			CustomFields:         toAPICustomFields(playbooktest.CustomFields),
+			WebhookSubscriptions: toAPIWebhookSubscriptions(playbooktest.WebhookSubscriptions),
 		})
 		require.NoError(t, err)
 		assert.NotEmpty(t, resultPlaybook.ID)
@@ -576,6 +613,29 @@ func TestPlaybooks(t *testing.T) {
 		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
 		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
 
+		resultPlaybook, err := c.Playbooks.Create(context.TODO(), icClient.PlaybookCreateOptions{
+			Title:                playbooktest.Title,
+			TeamID:               playbooktest.TeamID,
+			Checklists:           toAPIChecklists(playbooktest.Checklists),
+			MemberIDs:            playbooktest.MemberIDs,
+			InvitedUserIDs:       playbooktest.InvitedUserIDs,
+			InvitedGroupIDs:      playbooktest.InvitedGroupIDs,
+			SeverityLevels:       playbooktest.SeverityLevels,
+			StatusWorkflow:       toAPIStatusWorkflow(playbooktest.StatusWorkflow),
+			CustomFields:         toAPICustomFields(playbooktest.CustomFields),
+			WebhookSubscriptions: toAPIWebhookSubscriptions(playbooktest.WebhookSubscriptions),
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, resultPlaybook.ID)
+	})
+
+	t.Run("create playbook, invalid severity levels", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
 		resultPlaybook, err := c.Playbooks.Create(context.TODO(), icClient.PlaybookCreateOptions{
 			Title:           playbooktest.Title,
 			TeamID:          playbooktest.TeamID,
@@ -583,9 +643,49 @@ func TestPlaybooks(t *testing.T) {
 			MemberIDs:       playbooktest.MemberIDs,
 			InvitedUserIDs:  playbooktest.InvitedUserIDs,
 			InvitedGroupIDs: playbooktest.InvitedGroupIDs,
+			SeverityLevels:  []string{"SEV-1", "SEV-2"},
+			DefaultSeverity: "SEV-3",
 		})
-		require.NoError(t, err)
-		assert.NotEmpty(t, resultPlaybook.ID)
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+		assert.Nil(t, resultPlaybook)
+	})
+
+	t.Run("create playbook, invalid webhook secret", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
+		resultPlaybook, err := c.Playbooks.Create(context.TODO(), icClient.PlaybookCreateOptions{
+			Title:         playbooktest.Title,
+			TeamID:        playbooktest.TeamID,
+			Checklists:    toAPIChecklists(playbooktest.Checklists),
+			MemberIDs:     playbooktest.MemberIDs,
+			WebhookSecret: " s3cr3t ",
+		})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+		assert.Nil(t, resultPlaybook)
+	})
+
+	t.Run("create playbook, invalid webhook subscriptions", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
+		resultPlaybook, err := c.Playbooks.Create(context.TODO(), icClient.PlaybookCreateOptions{
+			Title:      playbooktest.Title,
+			TeamID:     playbooktest.TeamID,
+			Checklists: toAPIChecklists(playbooktest.Checklists),
+			MemberIDs:  playbooktest.MemberIDs,
+			WebhookSubscriptions: []icClient.WebhookSubscription{
+				{Events: []icClient.WebhookEventType{"channel_renamed"}, URLs: []string{"https://example.com"}},
+			},
+		})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+		assert.Nil(t, resultPlaybook)
 	})
 
 	t.Run("create playbook, as guest", func(t *testing.T) {
@@ -845,6 +945,79 @@ func TestPlaybooks(t *testing.T) {
 
 	})
 
+	t.Run("create playbook, service user that is not a bot", func(t *testing.T) {
+		reset(t)
+
+		serviceUserID := model.NewId()
+		playbook := playbooktest.Clone()
+		playbook.InboundWebhook.ServiceUserID = serviceUserID
+
+		playbookService.EXPECT().
+			Create(gomock.Any(), "testuserid").
+			DoAndReturn(func(created app.Playbook, userID string) (string, error) {
+				assert.Empty(t, created.InboundWebhook.ServiceUserID)
+				return model.NewId(), nil
+			}).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+		pluginAPI.On("GetUser", serviceUserID).Return(&model.User{Id: serviceUserID}, nil)
+		pluginAPI.On("LogWarn", "service user is not a bot of the playbook's team, posting inbound status updates as the plugin's bot",
+			"teamID", "testteamid", "userID", serviceUserID)
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks", jsonPlaybookReader(playbook))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+
+		handler.ServeHTTP(testrecorder, testreq)
+
+		resp := testrecorder.Result()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	t.Run("create playbook, backup outside the team", func(t *testing.T) {
+		reset(t)
+
+		backupUserID := model.NewId()
+		backupGroupID := model.NewId()
+		playbook := playbooktest.Clone()
+		playbook.ReminderEscalation = app.ReminderEscalation{
+			BackupAfterMissed:    2,
+			BackupUserID:         backupUserID,
+			BackupGroupID:        backupGroupID,
+			BroadcastAfterMissed: 3,
+		}
+
+		playbookService.EXPECT().
+			Create(gomock.Any(), "testuserid").
+			DoAndReturn(func(created app.Playbook, userID string) (string, error) {
+				assert.Equal(t, app.ReminderEscalation{BroadcastAfterMissed: 3}, created.ReminderEscalation)
+				return model.NewId(), nil
+			}).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+		pluginAPI.On("GetTeamMember", "testteamid", backupUserID).Return(nil, &model.AppError{})
+		pluginAPI.On("GetGroup", backupGroupID).Return(&model.Group{Id: backupGroupID, AllowReference: false}, nil)
+		pluginAPI.On("LogWarn", "backup user is not a member of the playbook's team, removing from reminder escalation",
+			"teamID", "testteamid", "userID", backupUserID)
+		pluginAPI.On("LogWarn", "backup group does not allow references, removing from reminder escalation", "group_id", backupGroupID)
+		pluginAPI.On("LogWarn", "no backup is left to notify, disabling the backup reminder escalation")
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks", jsonPlaybookReader(playbook))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+
+		handler.ServeHTTP(testrecorder, testreq)
+
+		resp := testrecorder.Result()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
 	t.Run("get playbook", func(t *testing.T) {
 		reset(t)
 
@@ -860,6 +1033,28 @@ func TestPlaybooks(t *testing.T) {
 		assert.Equal(t, withMember, toInternalPlaybook(*result))
 	})
 
+	t.Run("get playbook, secrets are redacted", func(t *testing.T) {
+		reset(t)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+
+		withSecrets := withMember
+		withSecrets.WebhookSecret = "s3cr3t"
+		withSecrets.InboundWebhook.Token = "9g64ig7q9pds8yjz8rsgd6e36e"
+
+		playbookService.EXPECT().
+			Get("testplaybookid").
+			Return(withSecrets, nil).
+			Times(1)
+
+		result, err := c.Playbooks.Get(context.TODO(), "testplaybookid")
+		require.NoError(t, err)
+		assert.Empty(t, result.WebhookSecret)
+		assert.True(t, result.HasWebhookSecret)
+		assert.Empty(t, result.InboundWebhook.Token)
+		assert.True(t, result.HasInboundWebhookToken)
+	})
+
 	t.Run("get playbooks", func(t *testing.T) {
 		reset(t)
 
@@ -1006,6 +1201,56 @@ func TestPlaybooks(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("update playbook, redacted secrets are kept", func(t *testing.T) {
+		reset(t)
+
+		withSecrets := withMember
+		withSecrets.WebhookSecret = "s3cr3t"
+		withSecrets.InboundWebhook.Token = "9g64ig7q9pds8yjz8rsgd6e36e"
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withSecrets, nil).
+			Times(1)
+
+		playbookService.EXPECT().
+			Update(withSecrets, "testuserid").
+			Return(nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
+		redacted := withSecrets
+		app.RedactPlaybookSecrets(&redacted)
+
+		err := c.Playbooks.Update(context.TODO(), toAPIPlaybook(redacted))
+		require.NoError(t, err)
+	})
+
+	t.Run("update playbook, clearing the webhook secret", func(t *testing.T) {
+		reset(t)
+
+		withSecret := withMember
+		withSecret.WebhookSecret = "s3cr3t"
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withSecret, nil).
+			Times(1)
+
+		playbookService.EXPECT().
+			Update(withMember, "testuserid").
+			Return(nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
+		err := c.Playbooks.Update(context.TODO(), toAPIPlaybook(withMember))
+		require.NoError(t, err)
+	})
+
 	t.Run("update playbook but no permissions in broadcast channel", func(t *testing.T) {
 		reset(t)
 		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
@@ -1064,12 +1309,16 @@ func TestPlaybooks(t *testing.T) {
 					},
 				},
 			},
-			MemberIDs:          []string{"testuserid"},
-			BroadcastChannelID: "nonemptychannelid",
-			InviteUsersEnabled: true,
-			InvitedUserIDs:     []string{"testInvitedUserID1", "testInvitedUserID2"},
-			InvitedGroupIDs:    []string{"testInvitedGroupID1", "testInvitedGroupID2"},
-			SignalAnyKeywords:  []string{},
+			MemberIDs:            []string{"testuserid"},
+			BroadcastChannelID:   "nonemptychannelid",
+			InviteUsersEnabled:   true,
+			InvitedUserIDs:       []string{"testInvitedUserID1", "testInvitedUserID2"},
+			InvitedGroupIDs:      []string{"testInvitedGroupID1", "testInvitedGroupID2"},
+			SignalAnyKeywords:    []string{},
+			SeverityLevels:       []string{},
+			StatusWorkflow:       app.StatusWorkflow{States: []app.StatusState{}},
+			CustomFields:         []app.CustomField{},
+			WebhookSubscriptions: []app.WebhookSubscription{},
 		}
 
 		testrecorder := httptest.NewRecorder()
@@ -1122,12 +1371,16 @@ func TestPlaybooks(t *testing.T) {
 					},
 				},
 			},
-			MemberIDs:          []string{"testuserid"},
-			BroadcastChannelID: "nonemptychannelid",
-			InviteUsersEnabled: false,
-			InvitedUserIDs:     []string{"testInvitedUserID1", "testInvitedUserID2"},
-			InvitedGroupIDs:    []string{"testInvitedGroupID1", "testInvitedGroupID2"},
-			SignalAnyKeywords:  []string{},
+			MemberIDs:            []string{"testuserid"},
+			BroadcastChannelID:   "nonemptychannelid",
+			InviteUsersEnabled:   false,
+			InvitedUserIDs:       []string{"testInvitedUserID1", "testInvitedUserID2"},
+			InvitedGroupIDs:      []string{"testInvitedGroupID1", "testInvitedGroupID2"},
+			SignalAnyKeywords:    []string{},
+			SeverityLevels:       []string{},
+			StatusWorkflow:       app.StatusWorkflow{States: []app.StatusState{}},
+			CustomFields:         []app.CustomField{},
+			WebhookSubscriptions: []app.WebhookSubscription{},
 		}
 
 		testrecorder := httptest.NewRecorder()
@@ -1181,12 +1434,16 @@ func TestPlaybooks(t *testing.T) {
 					},
 				},
 			},
-			MemberIDs:          []string{"testuserid"},
-			BroadcastChannelID: "nonemptychannelid",
-			InviteUsersEnabled: false,
-			InvitedUserIDs:     []string{"testInvitedUserID1", "testInvitedUserID2"},
-			InvitedGroupIDs:    []string{"testInvitedGroupID1", "testInvitedGroupID2"},
-			SignalAnyKeywords:  []string{},
+			MemberIDs:            []string{"testuserid"},
+			BroadcastChannelID:   "nonemptychannelid",
+			InviteUsersEnabled:   false,
+			InvitedUserIDs:       []string{"testInvitedUserID1", "testInvitedUserID2"},
+			InvitedGroupIDs:      []string{"testInvitedGroupID1", "testInvitedGroupID2"},
+			SignalAnyKeywords:    []string{},
+			SeverityLevels:       []string{},
+			StatusWorkflow:       app.StatusWorkflow{States: []app.StatusState{}},
+			CustomFields:         []app.CustomField{},
+			WebhookSubscriptions: []app.WebhookSubscription{},
 		}
 
 		testrecorder := httptest.NewRecorder()
@@ -1494,6 +1751,517 @@ func TestPlaybooks(t *testing.T) {
 		}
 		assert.Equal(t, expectedList, actualList)
 	})
+
+	t.Run("get playbooks with filters", func(t *testing.T) {
+		reset(t)
+
+		keywordsEnabled := true
+		playbookService.EXPECT().
+			GetPlaybooksForTeam(
+				app.RequesterInfo{
+					UserID:  "testuserid",
+					TeamID:  "testteamid",
+					IsAdmin: false,
+				},
+				"testteamid",
+				app.PlaybookFilterOptions{
+					Sort:            app.SortByTitle,
+					Direction:       app.DirectionAsc,
+					Page:            0,
+					PerPage:         100,
+					SearchTerm:      "outage",
+					MemberID:        "testmemberid00000000000000",
+					KeywordsEnabled: &keywordsEnabled,
+					IncludeArchived: true,
+				},
+			).
+			Return(app.GetPlaybooksResults{Items: []app.Playbook{}}, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(false)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
+		_, err := c.Playbooks.List(context.TODO(), "testteamid", 0, 100, icClient.PlaybookListOptions{
+			SearchTerm:      " outage ",
+			MemberID:        "testmemberid00000000000000",
+			KeywordsEnabled: &keywordsEnabled,
+			IncludeArchived: true,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("get playbooks with an invalid member filter", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		_, err := c.Playbooks.List(context.TODO(), "testteamid", 0, 100, icClient.PlaybookListOptions{
+			MemberID: "notanid",
+		})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("undelete playbook", func(t *testing.T) {
+		reset(t)
+
+		deleted := withMember.Clone()
+		deleted.DeleteAt = 100
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(deleted, nil).
+			Times(1)
+
+		playbookService.EXPECT().
+			Undelete(deleted, "testuserid").
+			Return(nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+
+		err := c.Playbooks.Undelete(context.TODO(), "playbookwithmember")
+		require.NoError(t, err)
+	})
+
+	t.Run("undelete playbook, not deleted", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+
+		err := c.Playbooks.Undelete(context.TODO(), "playbookwithmember")
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("undelete playbook by non-member", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+		mattermostUserID = "unknownMember"
+
+		deleted := withMember.Clone()
+		deleted.DeleteAt = 100
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(deleted, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "unknownMember", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+		pluginAPI.On("HasPermissionTo", "unknownMember", model.PERMISSION_MANAGE_SYSTEM).Return(false)
+
+		err := c.Playbooks.Undelete(context.TODO(), "playbookwithmember")
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("export playbook as yaml", func(t *testing.T) {
+		reset(t)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+
+		playbookService.EXPECT().
+			Get("testplaybookid").
+			Return(withMember, nil).
+			Times(1)
+		playbookService.EXPECT().
+			Export(withMember).
+			Return(app.PlaybookExport{Version: app.PlaybookExportVersion, Title: "My Playbook", Members: []string{"testuser"}}).
+			Times(1)
+
+		export, err := c.Playbooks.Export(context.TODO(), "testplaybookid", icClient.PlaybookExportFormatYAML)
+		require.NoError(t, err)
+		require.Contains(t, string(export), "title: My Playbook\n")
+		require.Contains(t, string(export), "members:\n- testuser\n")
+	})
+
+	t.Run("export playbook, not a member", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		mattermostUserID = "unknownMember"
+		pluginAPI.On("HasPermissionToTeam", "unknownMember", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+
+		playbookService.EXPECT().
+			Get("testplaybookid").
+			Return(withMember, nil).
+			Times(1)
+
+		_, err := c.Playbooks.Export(context.TODO(), "testplaybookid", icClient.PlaybookExportFormatJSON)
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("import playbook", func(t *testing.T) {
+		reset(t)
+
+		playbookService.EXPECT().
+			Import(app.PlaybookExport{Version: app.PlaybookExportVersion, Title: "My Playbook"}, "testteamid", "testuserid").
+			Return(app.PlaybookImportResult{ID: "testplaybookid", Warnings: []string{"members: user @carol not found"}}, nil).
+			Times(1)
+
+		result, err := c.Playbooks.Import(context.TODO(), "testteamid", icClient.PlaybookExportFormatYAML, []byte("version: 1\ntitle: My Playbook\n"))
+		require.NoError(t, err)
+		require.Equal(t, &icClient.PlaybookImportResult{ID: "testplaybookid", Warnings: []string{"members: user @carol not found"}}, result)
+	})
+
+	t.Run("import playbook, unsupported version", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		_, err := c.Playbooks.Import(context.TODO(), "testteamid", icClient.PlaybookExportFormatJSON, []byte(`{"version": 2, "title": "My Playbook"}`))
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("import playbook, no permissions", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		playbookService.EXPECT().
+			Import(gomock.Any(), "testteamid", "testuserid").
+			Return(app.PlaybookImportResult{}, errors.Wrap(app.ErrPermission, "not a playbook creator")).
+			Times(1)
+
+		_, err := c.Playbooks.Import(context.TODO(), "testteamid", icClient.PlaybookExportFormatJSON, []byte(`{"version": 1, "title": "My Playbook"}`))
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("import playbook, malformed", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		playbookService.EXPECT().
+			Import(gomock.Any(), "testteamid", "testuserid").
+			Return(app.PlaybookImportResult{}, errors.Wrap(app.ErrMalformedPlaybook, "invalid severity levels")).
+			Times(1)
+
+		_, err := c.Playbooks.Import(context.TODO(), "testteamid", icClient.PlaybookExportFormatJSON, []byte(`{"version": 1, "title": "My Playbook"}`))
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("duplicate playbook into another team", func(t *testing.T) {
+		reset(t)
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+
+		playbookService.EXPECT().
+			Duplicate(withMember, "otherteamid", "testuserid").
+			Return(app.PlaybookImportResult{ID: "testplaybookid", Warnings: []string{"broadcast channel: channel incidents not found"}}, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+
+		result, err := c.Playbooks.Duplicate(context.TODO(), "playbookwithmember", "otherteamid")
+		require.NoError(t, err)
+		require.Equal(t, &icClient.PlaybookImportResult{ID: "testplaybookid", Warnings: []string{"broadcast channel: channel incidents not found"}}, result)
+	})
+
+	t.Run("duplicate playbook into its team", func(t *testing.T) {
+		reset(t)
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+
+		playbookService.EXPECT().
+			Duplicate(withMember, "testteamid", "testuserid").
+			Return(app.PlaybookImportResult{ID: "testplaybookid", Warnings: []string{}}, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+
+		result, err := c.Playbooks.Duplicate(context.TODO(), "playbookwithmember", "")
+		require.NoError(t, err)
+		require.Equal(t, "testplaybookid", result.ID)
+	})
+
+	t.Run("duplicate playbook, not a member", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+		mattermostUserID = "unknownMember"
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "unknownMember", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+		pluginAPI.On("HasPermissionTo", "unknownMember", model.PERMISSION_MANAGE_SYSTEM).Return(false)
+
+		_, err := c.Playbooks.Duplicate(context.TODO(), "playbookwithmember", "otherteamid")
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("duplicate playbook, no permissions in the team", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+
+		playbookService.EXPECT().
+			Duplicate(withMember, "otherteamid", "testuserid").
+			Return(app.PlaybookImportResult{}, errors.Wrap(app.ErrPermission, "cannot view team")).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+
+		_, err := c.Playbooks.Duplicate(context.TODO(), "playbookwithmember", "otherteamid")
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("get playbook revisions", func(t *testing.T) {
+		reset(t)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+		playbookService.EXPECT().
+			GetRevisions("playbookwithmember").
+			Return([]app.PlaybookRevision{
+				{PlaybookID: "playbookwithmember", Revision: 2, UserID: "testuserid", CreateAt: 200, Changes: []app.PlaybookChange{{Field: "title", Old: []byte(`"Old title"`), New: []byte(`"My Playbook"`)}}},
+				{PlaybookID: "playbookwithmember", Revision: 1, UserID: "testuserid", CreateAt: 100, Changes: []app.PlaybookChange{}},
+			}, nil).
+			Times(1)
+
+		revisions, err := c.Playbooks.GetRevisions(context.TODO(), "playbookwithmember")
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		require.Equal(t, int64(2), revisions[0].Revision)
+		require.Equal(t, "title", revisions[0].Changes[0].Field)
+		require.Nil(t, revisions[0].Playbook)
+	})
+
+	t.Run("get playbook revisions, not a member", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		mattermostUserID = "unknownMember"
+		pluginAPI.On("HasPermissionToTeam", "unknownMember", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+
+		_, err := c.Playbooks.GetRevisions(context.TODO(), "playbookwithmember")
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("get playbook revision", func(t *testing.T) {
+		reset(t)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+
+		snapshot := withMember.Clone()
+		snapshot.Title = "Old title"
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+		playbookService.EXPECT().
+			GetRevision("playbookwithmember", int64(1)).
+			Return(app.PlaybookRevision{PlaybookID: "playbookwithmember", Revision: 1, CreateAt: 100, Changes: []app.PlaybookChange{}, Playbook: &snapshot}, nil).
+			Times(1)
+
+		revision, err := c.Playbooks.GetRevision(context.TODO(), "playbookwithmember", 1)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), revision.Revision)
+		require.NotNil(t, revision.Playbook)
+		require.Equal(t, "Old title", revision.Playbook.Title)
+	})
+
+	t.Run("get playbook revision, not found", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+		playbookService.EXPECT().
+			GetRevision("playbookwithmember", int64(5)).
+			Return(app.PlaybookRevision{}, errors.Wrap(app.ErrNotFound, "revision does not exist")).
+			Times(1)
+
+		_, err := c.Playbooks.GetRevision(context.TODO(), "playbookwithmember", 5)
+		requireErrorWithStatusCode(t, err, http.StatusNotFound)
+	})
+
+	t.Run("restore playbook revision", func(t *testing.T) {
+		reset(t)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
+		snapshot := withMember.Clone()
+		snapshot.Title = "Old title"
+		restored := withMember.Clone()
+		restored.Title = "Old title"
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+		playbookService.EXPECT().
+			GetRevision("playbookwithmember", int64(1)).
+			Return(app.PlaybookRevision{PlaybookID: "playbookwithmember", Revision: 1, Playbook: &snapshot}, nil).
+			Times(1)
+		playbookService.EXPECT().
+			Update(restored, "testuserid").
+			Return(nil).
+			Times(1)
+
+		err := c.Playbooks.RestoreRevision(context.TODO(), "playbookwithmember", 1)
+		require.NoError(t, err)
+	})
+
+	t.Run("restore playbook revision, concurrent update", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
+		snapshot := withMember.Clone()
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+		playbookService.EXPECT().
+			GetRevision("playbookwithmember", int64(1)).
+			Return(app.PlaybookRevision{PlaybookID: "playbookwithmember", Revision: 1, Playbook: &snapshot}, nil).
+			Times(1)
+		playbookService.EXPECT().
+			Update(gomock.Any(), "testuserid").
+			Return(errors.Wrap(app.ErrPlaybookRevisionConflict, "revision 2 already exists")).
+			Times(1)
+
+		err := c.Playbooks.RestoreRevision(context.TODO(), "playbookwithmember", 1)
+		requireErrorWithStatusCode(t, err, http.StatusConflict)
+	})
+
+	t.Run("sync playbooks from an archive", func(t *testing.T) {
+		reset(t)
+
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(true)
+
+		var archive bytes.Buffer
+		writer := zip.NewWriter(&archive)
+		w, err := writer.Create("outage.yaml")
+		require.NoError(t, err)
+		_, err = w.Write([]byte("version: 1\ntitle: Outage\n"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		playbookService.EXPECT().
+			Sync("testteamid", "testuserid", map[string][]byte{"outage.yaml": []byte("version: 1\ntitle: Outage\n")}, true).
+			Return(app.PlaybookSyncResult{DryRun: true, Playbooks: []app.SyncedPlaybook{
+				{Path: "outage.yaml", Title: "Outage", Action: app.PlaybookSyncActionCreate, Changes: []app.PlaybookChange{}, Warnings: []string{}},
+			}}, nil).
+			Times(1)
+
+		result, err := c.Playbooks.Sync(context.TODO(), "testteamid", true, archive.Bytes())
+		require.NoError(t, err)
+		require.True(t, result.DryRun)
+		require.Len(t, result.Playbooks, 1)
+		require.Equal(t, icClient.PlaybookSyncActionCreate, result.Playbooks[0].Action)
+	})
+
+	t.Run("sync playbooks from the server directory, not configured", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(true)
+
+		playbookService.EXPECT().
+			SyncFromDirectory("testteamid", "testuserid", false).
+			Return(app.PlaybookSyncResult{}, app.ErrPlaybookSyncNotConfigured).
+			Times(1)
+
+		_, err := c.Playbooks.Sync(context.TODO(), "testteamid", false, nil)
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("sync playbooks, not an admin", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PERMISSION_MANAGE_SYSTEM).Return(false)
+
+		_, err := c.Playbooks.Sync(context.TODO(), "testteamid", false, nil)
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("update synced playbook", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		synced := withMember.Clone()
+		synced.SyncPath = "outage.yaml"
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(synced, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
+		err := c.Playbooks.Update(context.TODO(), toAPIPlaybook(withMember))
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("delete synced playbook", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		synced := withMember.Clone()
+		synced.SyncPath = "outage.yaml"
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(synced, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PERMISSION_VIEW_TEAM).Return(true)
+
+		err := c.Playbooks.Delete(context.TODO(), "playbookwithmember")
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("create playbook with a sync path", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		playbook := playbooktest.Clone()
+		playbook.SyncPath = "outage.yaml"
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest("POST", "/api/v0/playbooks", jsonPlaybookReader(playbook))
+		testreq.Header.Add("Mattermost-User-ID", "testuserid")
+		require.NoError(t, err)
+
+		handler.ServeHTTP(testrecorder, testreq)
+
+		resp := testrecorder.Result()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
 }
 
 func TestSortingPlaybooks(t *testing.T) {
@@ -39,18 +39,19 @@ func NewStatsHandler(router *mux.Router, api *pluginapi.Client, log bot.Logger,
 }
 
 type PlaybookStats struct {
-	RunsInProgress                 int       `json:"runs_in_progress"`
-	ParticipantsActive             int       `json:"participants_active"`
-	RunsFinishedPrev30Days         int       `json:"runs_finished_prev_30_days"`
-	RunsFinishedPercentageChange   int       `json:"runs_finished_percentage_change"`
-	RunsStartedPerWeek             []int     `json:"runs_started_per_week"`
-	RunsStartedPerWeekLabels       []string  `json:"runs_started_per_week_labels"`
-	RunsStartedPerWeekTimes        [][]int64 `json:"runs_started_per_week_times"`
-	ActiveRunsPerDay               []int     `json:"active_runs_per_day"`
-	ActiveRunsPerDayLabels         []string  `json:"active_runs_per_day_labels"`
-	ActiveRunsPerDayTimes          [][]int64 `json:"active_runs_per_day_times"`
-	ActiveParticipantsPerDay       []int     `json:"active_participants_per_day"`
-	ActiveParticipantsPerDayLabels []string  `json:"active_participants_per_day_labels"`
+	RunsInProgress                 int                              `json:"runs_in_progress"`
+	ParticipantsActive             int                              `json:"participants_active"`
+	RunsFinishedPrev30Days         int                              `json:"runs_finished_prev_30_days"`
+	RunsFinishedPercentageChange   int                              `json:"runs_finished_percentage_change"`
+	RunsStartedPerWeek             []int                            `json:"runs_started_per_week"`
+	RunsStartedPerWeekLabels       []string                         `json:"runs_started_per_week_labels"`
+	RunsStartedPerWeekTimes        [][]int64                        `json:"runs_started_per_week_times"`
+	ActiveRunsPerDay               []int                            `json:"active_runs_per_day"`
+	ActiveRunsPerDayLabels         []string                         `json:"active_runs_per_day_labels"`
+	ActiveRunsPerDayTimes          [][]int64                        `json:"active_runs_per_day_times"`
+	ActiveParticipantsPerDay       []int                            `json:"active_participants_per_day"`
+	ActiveParticipantsPerDayLabels []string                         `json:"active_participants_per_day_labels"`
+	ChecklistItemSkipRates         []sqlstore.ChecklistItemSkipRate `json:"checklist_item_skip_rates"`
 }
 
 func parsePlaybookStatsFilters(u *url.URL) (*sqlstore.StatsFilters, error) {
@@ -109,5 +110,6 @@ func (h *StatsHandler) playbookStats(w http.ResponseWriter, r *http.Request) {
 		ActiveRunsPerDayTimes:          activeRunsPerDayTimes,
 		ActiveParticipantsPerDay:       activeParticipantsPerDay,
 		ActiveParticipantsPerDayLabels: activeParticipantsPerDayLabels,
+		ChecklistItemSkipRates:         h.statsStore.ChecklistItemSkipRates(filters),
 	}, http.StatusOK)
 }
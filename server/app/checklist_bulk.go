@@ -0,0 +1,384 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	stripmd "github.com/writeas/go-strip-markdown"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ChecklistOperationSetState moves an item to NewState. Skipping it requires a Reason, and
+	// closing it with open prerequisites requires Override, as with ModifyCheckedState.
+	ChecklistOperationSetState = "set_state"
+
+	// ChecklistOperationSetAssignee assigns an item to AssigneeID, or unassigns it if empty.
+	ChecklistOperationSetAssignee = "set_assignee"
+
+	// ChecklistOperationEdit replaces the Title, Command and Description of an item.
+	ChecklistOperationEdit = "edit"
+
+	// ChecklistOperationMove moves an item to NewLocation in its checklist.
+	ChecklistOperationMove = "move"
+
+	// ChecklistOperationAdd appends Item to a checklist. ItemNum is not used. The item is added open
+	// and unassigned: a set_state or set_assignee operation following it changes that, so that the
+	// change is checked and recorded as for any item.
+	ChecklistOperationAdd = "add"
+)
+
+// ChecklistOperation is one change to the checklists of a run, see ApplyChecklistOperations.
+// The indices of an operation refer to the checklists as left by the operations before it.
+type ChecklistOperation struct {
+	Type         string `json:"type"`
+	ChecklistNum int    `json:"checklist_num"`
+	ItemNum      int    `json:"item_num"`
+
+	NewState string `json:"new_state,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Override bool   `json:"override,omitempty"`
+
+	AssigneeID string `json:"assignee_id,omitempty"`
+
+	Title       string `json:"title,omitempty"`
+	Command     string `json:"command,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	NewLocation int `json:"new_location,omitempty"`
+
+	Item *ChecklistItem `json:"item,omitempty"`
+}
+
+// appliedChecklistOperation is the outcome of a checklist operation, which is recorded once the
+// run is updated. The indices are those of the item when the operation applied.
+type appliedChecklistOperation struct {
+	operationType string
+	checklistNum  int
+	itemNum       int
+	item          ChecklistItem
+	summary       string
+
+	// The changes of state or assignee are recorded in the timeline and sent to the webhooks.
+	eventType          timelineEventType
+	webhookEventType   WebhookEventType
+	previousState      string
+	previousAssigneeID string
+}
+
+// ApplyChecklistOperations applies the operations to the checklists of the run at once: either
+// all of them succeed and the run is updated once, or none is applied. A single message sums up
+// the changes in the run's channel, and a single websocket event updates the clients.
+func (s *PlaybookRunServiceImpl) ApplyChecklistOperations(playbookRunID, userID string, operations []ChecklistOperation) error {
+	return retryOnVersionConflict(func() error {
+		return s.applyChecklistOperations(playbookRunID, userID, operations)
+	})
+}
+
+// applyChecklistOperations applies ApplyChecklistOperations once, see retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) applyChecklistOperations(playbookRunID, userID string, operations []ChecklistOperation) error {
+	if len(operations) == 0 {
+		return nil
+	}
+
+	playbookRunToModify, err := s.store.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to retrieve playbook run")
+	}
+
+	if !s.hasPermissionToModifyPlaybookRun(playbookRunToModify, userID) {
+		return errors.Wrap(ErrNoPermissions, "user does not have permission to modify playbook run")
+	}
+
+	// Operate on a copy, so that the run is left untouched if any operation fails.
+	checklists := make([]Checklist, len(playbookRunToModify.Checklists))
+	for i, checklist := range playbookRunToModify.Checklists {
+		checklists[i] = checklist.Clone()
+	}
+
+	now := model.GetMillis()
+	var applied []appliedChecklistOperation
+	var summaries []string
+	var addedDueAts []int64
+	for i, operation := range operations {
+		result, opErr := s.applyChecklistOperation(playbookRunToModify, checklists, userID, operation, now)
+		if opErr != nil {
+			return errors.Wrapf(opErr, "operation %d (%s)", i, operation.Type)
+		}
+		if result == nil {
+			continue
+		}
+		applied = append(applied, *result)
+		summaries = append(summaries, result.summary)
+		if result.item.DueAt != 0 && operation.Type == ChecklistOperationAdd {
+			addedDueAts = append(addedDueAts, result.item.DueAt)
+		}
+	}
+	if len(applied) == 0 {
+		return nil
+	}
+
+	if err = ValidateChecklistItemDependencies(checklists); err != nil {
+		return errors.Wrap(ErrMalformedPlaybookRun, err.Error())
+	}
+
+	// Send the summary before the actual modification because we need the postID from the
+	// message. The items changed by the operations are those modified now.
+	message := "updated the checklists:\n* " + strings.Join(summaries, "\n* ")
+	post, err := s.modificationMessage(userID, playbookRunToModify.ChannelID, message)
+	if err != nil {
+		return err
+	}
+	for i := range checklists {
+		for j := range checklists[i].Items {
+			item := &checklists[i].Items[j]
+			if item.StateModified == now {
+				item.StateModifiedPostID = post.Id
+			}
+			if item.AssigneeModified == now {
+				item.AssigneeModifiedPostID = post.Id
+			}
+		}
+	}
+
+	playbookRunToModify.Checklists = checklists
+	playbookRunToModify.SetActiveStage()
+	dueAts := append(setChecklistItemDueDates(playbookRunToModify, now), addedDueAts...)
+
+	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
+		s.retractModificationMessage(post, err)
+		return errors.Wrapf(err, "failed to update playbook run")
+	}
+
+	s.scheduleChecklistItemDueAlerts(playbookRunID, dueAts)
+
+	for _, result := range applied {
+		s.trackChecklistOperation(playbookRunToModify, userID, result)
+
+		if result.eventType == "" {
+			continue
+		}
+
+		event := &TimelineEvent{
+			PlaybookRunID: playbookRunID,
+			CreateAt:      now,
+			EventAt:       now,
+			EventType:     result.eventType,
+			Summary:       result.summary,
+			PostID:        post.Id,
+			SubjectUserID: userID,
+		}
+		if _, err = s.store.CreateTimelineEvent(event); err != nil {
+			return errors.Wrap(err, "failed to create timeline event")
+		}
+
+		s.queueWebhookEvent(playbookRunToModify, result.webhookEventType, userID, WebhookEventDetails{
+			ChecklistItem: &WebhookChecklistItem{
+				ChecklistNum:       result.checklistNum,
+				ItemNum:            result.itemNum,
+				PreviousState:      result.previousState,
+				PreviousAssigneeID: result.previousAssigneeID,
+			},
+		})
+	}
+
+	s.runTriggeredChecklistCommands(playbookRunToModify)
+
+	s.poster.PublishWebsocketEventToChannel(playbookRunUpdatedWSEvent, playbookRunToModify, playbookRunToModify.ChannelID)
+
+	return nil
+}
+
+// applyChecklistOperation applies the operation to the checklists of the run. It returns nil if
+// the operation changes nothing.
+func (s *PlaybookRunServiceImpl) applyChecklistOperation(playbookRun *PlaybookRun, checklists []Checklist, userID string, operation ChecklistOperation, now int64) (*appliedChecklistOperation, error) {
+	if operation.ChecklistNum < 0 || operation.ChecklistNum >= len(checklists) {
+		return nil, errors.Wrap(ErrMalformedPlaybookRun, "invalid checklist number")
+	}
+
+	if operation.Type == ChecklistOperationAdd {
+		item, err := addChecklistOperationItem(playbookRun, checklists, operation)
+		if err != nil {
+			return nil, err
+		}
+		return &appliedChecklistOperation{
+			operationType: operation.Type,
+			checklistNum:  operation.ChecklistNum,
+			itemNum:       len(checklists[operation.ChecklistNum].Items) - 1,
+			item:          item,
+			summary:       fmt.Sprintf("added checklist item **%v**", stripmd.Strip(item.Title)),
+		}, nil
+	}
+
+	if !IsValidChecklistItemIndex(checklists, operation.ChecklistNum, operation.ItemNum) {
+		return nil, errors.Wrap(ErrMalformedPlaybookRun, "invalid item number")
+	}
+	item := &checklists[operation.ChecklistNum].Items[operation.ItemNum]
+	title := stripmd.Strip(item.Title)
+	result := &appliedChecklistOperation{
+		operationType:      operation.Type,
+		checklistNum:       operation.ChecklistNum,
+		itemNum:            operation.ItemNum,
+		previousState:      item.State,
+		previousAssigneeID: item.AssigneeID,
+	}
+
+	switch operation.Type {
+	case ChecklistOperationSetState:
+		if !IsValidChecklistItemState(operation.NewState) {
+			return nil, errors.Wrapf(ErrMalformedPlaybookRun, "invalid state '%s'", operation.NewState)
+		}
+		if operation.NewState == item.State {
+			return nil, nil
+		}
+
+		reason := strings.TrimSpace(operation.Reason)
+		result.summary = fmt.Sprintf("checked off checklist item **%v**", title)
+		switch operation.NewState {
+		case ChecklistItemStateOpen:
+			result.summary = fmt.Sprintf("unchecked checklist item **%v**", title)
+		case ChecklistItemStateSkipped:
+			if reason == "" {
+				return nil, errors.Wrap(ErrMalformedPlaybookRun, "a reason is required to skip a checklist item")
+			}
+			result.summary = fmt.Sprintf("skipped checklist item **%v**: %s", title, reason)
+		case ChecklistItemStateClosed:
+			if prerequisites := openPrerequisites(checklists, *item); len(prerequisites) > 0 {
+				if !operation.Override {
+					return nil, errors.Wrapf(ErrChecklistItemBlocked, "item '%s' depends on open item '%s'", item.Title, prerequisites[0].Title)
+				}
+				if playbookRun.OwnerUserID != userID {
+					return nil, errors.Wrap(ErrNoPermissions, "only the owner can override the prerequisites of an item")
+				}
+				result.summary += ", overriding its open prerequisites"
+			}
+		}
+
+		item.State = operation.NewState
+		item.StateModified = now
+		item.SkipReason = ""
+		if operation.NewState == ChecklistItemStateSkipped {
+			item.SkipReason = reason
+		}
+		result.eventType = TaskStateModified
+		result.webhookEventType = WebhookEventChecklistItemStateChanged
+
+	case ChecklistOperationSetAssignee:
+		if operation.AssigneeID == item.AssigneeID {
+			return nil, nil
+		}
+
+		newAssigneeUsername := noAssigneeName
+		if operation.AssigneeID != "" {
+			newUser, err := s.pluginAPI.User.Get(operation.AssigneeID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to to resolve user %s", operation.AssigneeID)
+			}
+			newAssigneeUsername = "@" + newUser.Username
+		}
+
+		oldAssigneeUsername := noAssigneeName
+		if item.AssigneeID != "" {
+			oldUser, err := s.pluginAPI.User.Get(item.AssigneeID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to to resolve user %s", item.AssigneeID)
+			}
+			oldAssigneeUsername = oldUser.Username
+		}
+
+		result.summary = fmt.Sprintf("changed assignee of checklist item **%s** from **%s** to **%s**",
+			title, oldAssigneeUsername, newAssigneeUsername)
+		item.AssigneeID = operation.AssigneeID
+		item.AssigneeModified = now
+		result.eventType = AssigneeChanged
+		result.webhookEventType = WebhookEventChecklistItemAssigneeChanged
+
+	case ChecklistOperationEdit:
+		if strings.TrimSpace(operation.Title) == "" {
+			return nil, errors.Wrap(ErrMalformedPlaybookRun, "a title is required to edit a checklist item")
+		}
+
+		item.Title = operation.Title
+		item.Command = operation.Command
+		item.Description = operation.Description
+		if err := item.ValidateCommandTrigger(playbookRun.Workflow()); err != nil {
+			return nil, errors.Wrap(ErrMalformedPlaybookRun, err.Error())
+		}
+		result.summary = fmt.Sprintf("edited checklist item **%v**", stripmd.Strip(item.Title))
+
+	case ChecklistOperationMove:
+		items := checklists[operation.ChecklistNum].Items
+		if operation.NewLocation < 0 || operation.NewLocation >= len(items) {
+			return nil, errors.Wrap(ErrMalformedPlaybookRun, "invalid new location")
+		}
+		if operation.NewLocation == operation.ItemNum {
+			return nil, nil
+		}
+
+		itemMoved := *item
+		items = append(items[:operation.ItemNum], items[operation.ItemNum+1:]...)
+		items = append(items, ChecklistItem{})
+		copy(items[operation.NewLocation+1:], items[operation.NewLocation:])
+		items[operation.NewLocation] = itemMoved
+		checklists[operation.ChecklistNum].Items = items
+
+		result.item = itemMoved
+		result.summary = fmt.Sprintf("moved checklist item **%v**", title)
+		return result, nil
+
+	default:
+		return nil, errors.Wrapf(ErrMalformedPlaybookRun, "unknown operation type '%s'", operation.Type)
+	}
+
+	result.item = *item
+
+	return result, nil
+}
+
+// addChecklistOperationItem appends the item of the add operation to its checklist, validating
+// it as AddChecklistItem does.
+func addChecklistOperationItem(playbookRun *PlaybookRun, checklists []Checklist, operation ChecklistOperation) (ChecklistItem, error) {
+	if operation.Item == nil || strings.TrimSpace(operation.Item.Title) == "" {
+		return ChecklistItem{}, errors.Wrap(ErrMalformedPlaybookRun, "an item with a title is required")
+	}
+
+	item := *operation.Item
+	if err := item.ValidateDue(); err != nil {
+		return ChecklistItem{}, errors.Wrap(ErrMalformedPlaybookRun, err.Error())
+	}
+	if err := item.ValidateCommandTrigger(playbookRun.Workflow()); err != nil {
+		return ChecklistItem{}, errors.Wrap(ErrMalformedPlaybookRun, err.Error())
+	}
+	if item.State != ChecklistItemStateOpen {
+		return ChecklistItem{}, errors.Wrap(ErrMalformedPlaybookRun, "items are added open, change their state with a set_state operation")
+	}
+	if item.AssigneeID != "" {
+		return ChecklistItem{}, errors.Wrap(ErrMalformedPlaybookRun, "items are added unassigned, assign them with a set_assignee operation")
+	}
+
+	if item.ID == "" {
+		item.ID = model.NewId()
+	}
+	checklists[operation.ChecklistNum].Items = append(checklists[operation.ChecklistNum].Items, item)
+
+	return item, nil
+}
+
+// trackChecklistOperation sends the telemetry of the checklist operation, as the service methods
+// applying the same change one at a time do.
+func (s *PlaybookRunServiceImpl) trackChecklistOperation(playbookRun *PlaybookRun, userID string, result appliedChecklistOperation) {
+	switch result.operationType {
+	case ChecklistOperationSetState:
+		s.telemetry.ModifyCheckedState(playbookRun.ID, userID, result.item, playbookRun.OwnerUserID == userID)
+	case ChecklistOperationSetAssignee:
+		s.telemetry.SetAssignee(playbookRun.ID, userID, result.item)
+	case ChecklistOperationEdit:
+		s.telemetry.RenameTask(playbookRun.ID, userID, result.item)
+	case ChecklistOperationMove:
+		s.telemetry.MoveTask(playbookRun.ID, userID, result.item)
+	case ChecklistOperationAdd:
+		s.telemetry.AddTask(playbookRun.ID, userID, result.item)
+	}
+}
@@ -0,0 +1,259 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ChecklistItemCommandTriggerRunStart runs the command of an item when its run starts.
+	ChecklistItemCommandTriggerRunStart = "run_start"
+
+	// ChecklistItemCommandTriggerStageStart runs the command of an item when its checklist becomes
+	// active. See ChecklistItemDueRelativeToStage.
+	ChecklistItemCommandTriggerStageStart = "stage_start"
+
+	// ChecklistItemCommandTriggerStatus runs the command of an item when its run reaches the
+	// item's CommandTriggerStatus.
+	ChecklistItemCommandTriggerStatus = "status"
+)
+
+const (
+	// ChecklistItemCommandRunAsOwner runs the triggered command of an item as the run's owner.
+	ChecklistItemCommandRunAsOwner = "owner"
+
+	// ChecklistItemCommandRunAsBot runs the triggered command of an item as the plugin's bot.
+	ChecklistItemCommandRunAsBot = "bot"
+)
+
+// ValidateCommandTrigger checks that the item's command trigger, if any, is known and that the
+// item has a command to run. The statuses are those of the workflow the item is run in.
+func (i ChecklistItem) ValidateCommandTrigger(workflow StatusWorkflow) error {
+	switch i.CommandRunAs {
+	case "", ChecklistItemCommandRunAsOwner, ChecklistItemCommandRunAsBot:
+	default:
+		return errors.Errorf("unknown command user '%s'", i.CommandRunAs)
+	}
+
+	switch i.CommandTrigger {
+	case "":
+		return nil
+	case ChecklistItemCommandTriggerRunStart, ChecklistItemCommandTriggerStageStart:
+	case ChecklistItemCommandTriggerStatus:
+		if _, ok := workflow.State(i.CommandTriggerStatus); !ok {
+			return errors.Errorf("command trigger status '%s' is not a status of the workflow", i.CommandTriggerStatus)
+		}
+	default:
+		return errors.Errorf("unknown command trigger '%s'", i.CommandTrigger)
+	}
+
+	if strings.TrimSpace(i.Command) == "" {
+		return errors.New("command trigger requires a command")
+	}
+
+	return nil
+}
+
+// ValidateChecklistItemCommandTriggers checks the command triggers of the playbook's checklist
+// items, see ChecklistItem.ValidateCommandTrigger.
+func (p Playbook) ValidateChecklistItemCommandTriggers() error {
+	workflow := p.StatusWorkflow
+	if workflow.IsEmpty() {
+		workflow = DefaultStatusWorkflow()
+	}
+
+	for _, checklist := range p.Checklists {
+		for _, item := range checklist.Items {
+			if err := item.ValidateCommandTrigger(workflow); err != nil {
+				return errors.Wrapf(err, "item '%s'", item.Title)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isChecklistCommandTriggered returns true if the trigger of the command of the run's item, in the
+// checklist with the given index, has fired and the command did not run automatically yet.
+func isChecklistCommandTriggered(playbookRun *PlaybookRun, checklistNumber int, item ChecklistItem, startedChecklists int) bool {
+	if item.CommandTriggeredAt != 0 || strings.TrimSpace(item.Command) == "" {
+		return false
+	}
+
+	switch item.CommandTrigger {
+	case ChecklistItemCommandTriggerRunStart:
+		return true
+	case ChecklistItemCommandTriggerStageStart:
+		return checklistNumber < startedChecklists
+	case ChecklistItemCommandTriggerStatus:
+		return item.CommandTriggerStatus == playbookRun.CurrentStatus
+	default:
+		return false
+	}
+}
+
+// ChecklistCommandsPrefix is the prefix of the keys of the jobs running the triggered commands of
+// a playbook run's checklist items. It is short, as the keys of jobs are limited to 44 characters.
+const ChecklistCommandsPrefix = "cmd_"
+
+// checklistCommandsJobKey returns the key of the job running the commands of the run's items
+// triggered at triggeredAt.
+func checklistCommandsJobKey(playbookRunID string, triggeredAt int64) string {
+	return ChecklistCommandsPrefix + playbookRunID + "_" + strconv.FormatInt(triggeredAt, 10)
+}
+
+// runTriggeredChecklistCommands records that the commands of the run's items whose trigger fired
+// were triggered, so that they only run once, and schedules running them. It is called once the
+// change of the run firing the triggers is stored, and reads the run anew if it was updated
+// concurrently since.
+func (s *PlaybookRunServiceImpl) runTriggeredChecklistCommands(playbookRun *PlaybookRun) {
+	triggeredAt := model.GetMillis()
+	triggered := 0
+	err := retryOnVersionConflict(func() error {
+		// The run was just stored by the caller, so it is only read anew after a conflict.
+		if triggered > 0 {
+			var err error
+			if playbookRun, err = s.store.GetPlaybookRun(playbookRun.ID); err != nil {
+				return errors.Wrapf(err, "failed to get playbook run")
+			}
+		}
+
+		triggered = markTriggeredChecklistCommands(playbookRun, triggeredAt)
+		if triggered == 0 {
+			return nil
+		}
+
+		return s.store.UpdatePlaybookRun(playbookRun)
+	})
+	if err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "failed to record triggered commands of playbook run id: %s", playbookRun.ID).Error())
+		return
+	}
+	if triggered == 0 {
+		return
+	}
+
+	if _, err = s.scheduler.ScheduleOnce(checklistCommandsJobKey(playbookRun.ID, triggeredAt), time.Now()); err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "failed to schedule triggered commands of playbook run id: %s", playbookRun.ID).Error())
+	}
+}
+
+// markTriggeredChecklistCommands sets the time the command of the run's items whose trigger fired
+// was triggered, giving an ID to those without one, and returns how many there are.
+func markTriggeredChecklistCommands(playbookRun *PlaybookRun, triggeredAt int64) int {
+	triggered := 0
+	started := startedChecklists(playbookRun.Checklists)
+	for i, checklist := range playbookRun.Checklists {
+		for j, item := range checklist.Items {
+			if !isChecklistCommandTriggered(playbookRun, i, item, started) {
+				continue
+			}
+
+			if item.ID == "" {
+				playbookRun.Checklists[i].Items[j].ID = model.NewId()
+			}
+			playbookRun.Checklists[i].Items[j].CommandTriggeredAt = triggeredAt
+			triggered++
+		}
+	}
+
+	return triggered
+}
+
+// handleChecklistCommands runs the commands of the run's items triggered at the time of the job.
+// The commands that ran are recorded in the run and its timeline, and the failures are reported in
+// the run's channel.
+func (s *PlaybookRunServiceImpl) handleChecklistCommands(key string) {
+	parts := strings.SplitN(strings.TrimPrefix(key, ChecklistCommandsPrefix), "_", 2)
+	if len(parts) != 2 {
+		s.logger.Errorf("handleChecklistCommands got a malformed key: %s", key)
+		return
+	}
+	playbookRunID := parts[0]
+	triggeredAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "handleChecklistCommands got a malformed key: %s", key).Error())
+		return
+	}
+
+	playbookRun, err := s.store.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "handleChecklistCommands failed to get playbook run id: %s", playbookRunID).Error())
+		return
+	}
+
+	lastRuns := map[string]int64{}
+	for _, checklist := range playbookRun.Checklists {
+		for _, item := range checklist.Items {
+			if item.CommandTriggeredAt != triggeredAt {
+				continue
+			}
+
+			userID := playbookRun.OwnerUserID
+			if item.CommandRunAs == ChecklistItemCommandRunAsBot {
+				userID = s.configService.GetConfiguration().BotUserID
+			}
+
+			_, err = s.pluginAPI.SlashCommand.Execute(&model.CommandArgs{
+				Command:   item.Command,
+				UserId:    userID,
+				TeamId:    playbookRun.TeamID,
+				ChannelId: playbookRun.ChannelID,
+			})
+			if err != nil {
+				s.logger.Warnf("failed to run triggered command of checklist item %s of playbook run %s: %v", item.ID, playbookRun.ID, err)
+				if _, err = s.poster.PostMessage(playbookRun.ChannelID, "Failed to run the slash command `%s` of the checklist item **%s**.", item.Command, item.Title); err != nil {
+					s.logger.Errorf(errors.Wrap(err, "failed to post triggered command failure").Error())
+				}
+				continue
+			}
+
+			eventTime := model.GetMillis()
+			lastRuns[item.ID] = eventTime
+			s.telemetry.RunTaskSlashCommand(playbookRun.ID, userID, item)
+
+			event := &TimelineEvent{
+				PlaybookRunID: playbookRun.ID,
+				CreateAt:      eventTime,
+				EventAt:       eventTime,
+				EventType:     RanSlashCommand,
+				Summary:       fmt.Sprintf("ran the slash command: `%s`, triggered automatically", item.Command),
+				SubjectUserID: userID,
+			}
+			if _, err = s.store.CreateTimelineEvent(event); err != nil {
+				s.logger.Errorf(errors.Wrap(err, "failed to create timeline event").Error())
+			}
+		}
+	}
+	if len(lastRuns) == 0 {
+		return
+	}
+
+	// The commands may have taken a while, so record their runs in the run as it is now.
+	err = retryOnVersionConflict(func() error {
+		if playbookRun, err = s.store.GetPlaybookRun(playbookRunID); err != nil {
+			return errors.Wrapf(err, "failed to get playbook run")
+		}
+
+		for i, checklist := range playbookRun.Checklists {
+			for j, item := range checklist.Items {
+				if lastRun, ok := lastRuns[item.ID]; ok {
+					playbookRun.Checklists[i].Items[j].CommandLastRun = lastRun
+				}
+			}
+		}
+
+		return s.store.UpdatePlaybookRun(playbookRun)
+	})
+	if err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "failed to record run of triggered commands of playbook run id: %s", playbookRunID).Error())
+		return
+	}
+
+	s.poster.PublishWebsocketEventToChannel(playbookRunUpdatedWSEvent, playbookRun, playbookRun.ChannelID)
+}
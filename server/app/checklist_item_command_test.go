@@ -0,0 +1,53 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecklistItem_ValidateCommandTrigger(t *testing.T) {
+	workflow := DefaultStatusWorkflow()
+
+	for name, tc := range map[string]struct {
+		item  ChecklistItem
+		valid bool
+	}{
+		"no trigger":         {ChecklistItem{Command: "/echo"}, true},
+		"no command":         {ChecklistItem{}, true},
+		"run start":          {ChecklistItem{Command: "/echo", CommandTrigger: ChecklistItemCommandTriggerRunStart}, true},
+		"stage start as bot": {ChecklistItem{Command: "/echo", CommandTrigger: ChecklistItemCommandTriggerStageStart, CommandRunAs: ChecklistItemCommandRunAsBot}, true},
+		"status":             {ChecklistItem{Command: "/echo", CommandTrigger: ChecklistItemCommandTriggerStatus, CommandTriggerStatus: StatusResolved}, true},
+		"unknown status":     {ChecklistItem{Command: "/echo", CommandTrigger: ChecklistItemCommandTriggerStatus, CommandTriggerStatus: "Unknown"}, false},
+		"unknown trigger":    {ChecklistItem{Command: "/echo", CommandTrigger: "run_end"}, false},
+		"unknown user":       {ChecklistItem{Command: "/echo", CommandTrigger: ChecklistItemCommandTriggerRunStart, CommandRunAs: "assignee"}, false},
+		"trigger no command": {ChecklistItem{Command: " ", CommandTrigger: ChecklistItemCommandTriggerRunStart}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.item.ValidateCommandTrigger(workflow)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestIsChecklistCommandTriggered(t *testing.T) {
+	run := &PlaybookRun{CurrentStatus: StatusActive}
+	command := func(trigger, status string) ChecklistItem {
+		return ChecklistItem{Command: "/echo", CommandTrigger: trigger, CommandTriggerStatus: status}
+	}
+
+	require.True(t, isChecklistCommandTriggered(run, 1, command(ChecklistItemCommandTriggerRunStart, ""), 1))
+	require.True(t, isChecklistCommandTriggered(run, 0, command(ChecklistItemCommandTriggerStageStart, ""), 1))
+	require.False(t, isChecklistCommandTriggered(run, 1, command(ChecklistItemCommandTriggerStageStart, ""), 1), "the stage has not started")
+	require.True(t, isChecklistCommandTriggered(run, 1, command(ChecklistItemCommandTriggerStatus, StatusActive), 1))
+	require.False(t, isChecklistCommandTriggered(run, 0, command(ChecklistItemCommandTriggerStatus, StatusResolved), 1))
+	require.False(t, isChecklistCommandTriggered(run, 0, command("", ""), 1))
+
+	triggered := command(ChecklistItemCommandTriggerRunStart, "")
+	triggered.CommandTriggeredAt = 1000
+	require.False(t, isChecklistCommandTriggered(run, 0, triggered, 1), "commands only run once")
+}
@@ -0,0 +1,226 @@
+package app
+
+import (
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// ValidateChecklistItemDependencies checks that the items of the checklists only depend on other
+// items of the checklists, by their ID, and that no item ends up depending on itself.
+func ValidateChecklistItemDependencies(checklists []Checklist) error {
+	dependencies := make(map[string][]string)
+	for _, checklist := range checklists {
+		for _, item := range checklist.Items {
+			if item.ID == "" {
+				if len(item.DependsOn) > 0 {
+					return errors.Errorf("item '%s' must have an ID to depend on other items", item.Title)
+				}
+				continue
+			}
+			if _, ok := dependencies[item.ID]; ok {
+				return errors.Errorf("item ID '%s' is duplicated", item.ID)
+			}
+			dependencies[item.ID] = item.DependsOn
+		}
+	}
+
+	for itemID, dependsOn := range dependencies {
+		for _, prerequisiteID := range dependsOn {
+			if _, ok := dependencies[prerequisiteID]; !ok {
+				return errors.Errorf("item '%s' depends on unknown item '%s'", itemID, prerequisiteID)
+			}
+		}
+	}
+
+	// Depth-first search for cycles, coloring the items being visited and the visited ones.
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	colors := make(map[string]int, len(dependencies))
+	var visit func(itemID string) error
+	visit = func(itemID string) error {
+		switch colors[itemID] {
+		case visiting:
+			return errors.Errorf("item '%s' depends on itself", itemID)
+		case visited:
+			return nil
+		}
+
+		colors[itemID] = visiting
+		for _, prerequisiteID := range dependencies[itemID] {
+			if err := visit(prerequisiteID); err != nil {
+				return err
+			}
+		}
+		colors[itemID] = visited
+
+		return nil
+	}
+	for itemID := range dependencies {
+		if err := visit(itemID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// openPrerequisites returns the items of the checklists the item depends on that are not done.
+// Skipped items do not block the items depending on them.
+func openPrerequisites(checklists []Checklist, item ChecklistItem) []ChecklistItem {
+	if len(item.DependsOn) == 0 {
+		return nil
+	}
+
+	var open []ChecklistItem
+	for _, prerequisiteID := range item.DependsOn {
+		for _, checklist := range checklists {
+			for _, prerequisite := range checklist.Items {
+				if prerequisite.ID == prerequisiteID && !prerequisite.IsDone() {
+					open = append(open, prerequisite)
+				}
+			}
+		}
+	}
+
+	return open
+}
+
+// setBlockedChecklistItems marks the items of the checklists whose prerequisites are not all
+// done as blocked.
+func setBlockedChecklistItems(checklists []Checklist) {
+	for i := range checklists {
+		for j := range checklists[i].Items {
+			checklists[i].Items[j].Blocked = len(openPrerequisites(checklists, checklists[i].Items[j])) > 0
+		}
+	}
+}
+
+// removeChecklistItemDependencies removes the dependencies of the checklists' items on the item
+// with the given ID, when it is removed.
+func removeChecklistItemDependencies(checklists []Checklist, itemID string) {
+	if itemID == "" {
+		return
+	}
+
+	for i := range checklists {
+		for j := range checklists[i].Items {
+			dependsOn := checklists[i].Items[j].DependsOn[:0]
+			for _, prerequisiteID := range checklists[i].Items[j].DependsOn {
+				if prerequisiteID != itemID {
+					dependsOn = append(dependsOn, prerequisiteID)
+				}
+			}
+			checklists[i].Items[j].DependsOn = dependsOn
+		}
+	}
+}
+
+// checklistItemTitles returns the titles of the items of the checklists with the given IDs.
+func checklistItemTitles(checklists []Checklist, itemIDs []string) []string {
+	var titles []string
+	for _, itemID := range itemIDs {
+		for _, checklist := range checklists {
+			for _, item := range checklist.Items {
+				if item.ID == itemID {
+					titles = append(titles, item.Title)
+				}
+			}
+		}
+	}
+
+	return titles
+}
+
+// importChecklistItemDependencies sets the dependencies of the checklists imported from the
+// exported ones, whose items depend on each other by title. The items involved get an ID, and
+// the dependencies on titles that are missing or ambiguous are dropped with a warning.
+func importChecklistItemDependencies(checklists []Checklist, exported []ExportedChecklist, warnf func(format string, args ...interface{})) {
+	itemsByTitle := make(map[string][]*ChecklistItem)
+	for i := range checklists {
+		for j := range checklists[i].Items {
+			item := &checklists[i].Items[j]
+			itemsByTitle[item.Title] = append(itemsByTitle[item.Title], item)
+		}
+	}
+
+	for i := range exported {
+		for j, exportedItem := range exported[i].Items {
+			item := &checklists[i].Items[j]
+			for _, title := range exportedItem.DependsOn {
+				prerequisites := itemsByTitle[title]
+				if len(prerequisites) != 1 {
+					warnf("checklist item '%s': dependency on '%s' not imported, as it matches %d items", item.Title, title, len(prerequisites))
+					continue
+				}
+				if prerequisites[0] == item {
+					warnf("checklist item '%s': dependency on itself not imported", item.Title)
+					continue
+				}
+				if prerequisites[0].ID == "" {
+					prerequisites[0].ID = model.NewId()
+				}
+				if item.ID == "" {
+					item.ID = model.NewId()
+				}
+				item.DependsOn = append(item.DependsOn, prerequisites[0].ID)
+			}
+		}
+	}
+}
+
+// SetActiveStage moves the run to the stage of its first checklist with items that are not
+// done, or to its last checklist once they all are. See ChecklistItemDueRelativeToStage.
+func (i *PlaybookRun) SetActiveStage() {
+	i.ActiveStage = 0
+	i.ActiveStageTitle = ""
+	if len(i.Checklists) == 0 {
+		return
+	}
+
+	i.ActiveStage = startedChecklists(i.Checklists) - 1
+	i.ActiveStageTitle = i.Checklists[i.ActiveStage].Title
+}
+
+// SetChecklistItemDependencies replaces the items the item depends on, by their IDs. The run's
+// items without an ID get one, so that they can be depended on.
+func (s *PlaybookRunServiceImpl) SetChecklistItemDependencies(playbookRunID, userID string, checklistNumber, itemNumber int, dependsOn []string) error {
+	return retryOnVersionConflict(func() error {
+		return s.setChecklistItemDependencies(playbookRunID, userID, checklistNumber, itemNumber, dependsOn)
+	})
+}
+
+// setChecklistItemDependencies applies SetChecklistItemDependencies once, see
+// retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) setChecklistItemDependencies(playbookRunID, userID string, checklistNumber, itemNumber int, dependsOn []string) error {
+	playbookRunToModify, err := s.checklistItemParamsVerify(playbookRunID, userID, checklistNumber, itemNumber)
+	if err != nil {
+		return err
+	}
+
+	if !IsValidChecklistItemIndex(playbookRunToModify.Checklists, checklistNumber, itemNumber) {
+		return errors.New("invalid checklist item indices")
+	}
+
+	for i := range playbookRunToModify.Checklists {
+		for j := range playbookRunToModify.Checklists[i].Items {
+			if playbookRunToModify.Checklists[i].Items[j].ID == "" {
+				playbookRunToModify.Checklists[i].Items[j].ID = model.NewId()
+			}
+		}
+	}
+	playbookRunToModify.Checklists[checklistNumber].Items[itemNumber].DependsOn = dependsOn
+
+	if err = ValidateChecklistItemDependencies(playbookRunToModify.Checklists); err != nil {
+		return errors.Wrap(ErrMalformedPlaybookRun, err.Error())
+	}
+
+	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
+		return errors.Wrapf(err, "failed to update playbook run")
+	}
+
+	s.poster.PublishWebsocketEventToChannel(playbookRunUpdatedWSEvent, playbookRunToModify, playbookRunToModify.ChannelID)
+
+	return nil
+}
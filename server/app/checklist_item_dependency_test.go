@@ -0,0 +1,145 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateChecklistItemDependencies(t *testing.T) {
+	for name, tc := range map[string]struct {
+		items []ChecklistItem
+		valid bool
+	}{
+		"no dependencies": {
+			[]ChecklistItem{{Title: "Page"}, {ID: "fix", Title: "Fix"}},
+			true,
+		},
+		"dependencies": {
+			[]ChecklistItem{{ID: "page"}, {ID: "fix", DependsOn: []string{"page"}}, {ID: "report", DependsOn: []string{"page", "fix"}}},
+			true,
+		},
+		"item without ID": {
+			[]ChecklistItem{{ID: "page"}, {DependsOn: []string{"page"}}},
+			false,
+		},
+		"duplicated ID": {
+			[]ChecklistItem{{ID: "page"}, {ID: "page"}},
+			false,
+		},
+		"unknown item": {
+			[]ChecklistItem{{ID: "fix", DependsOn: []string{"page"}}},
+			false,
+		},
+		"depends on itself": {
+			[]ChecklistItem{{ID: "fix", DependsOn: []string{"fix"}}},
+			false,
+		},
+		"cycle": {
+			[]ChecklistItem{{ID: "page", DependsOn: []string{"report"}}, {ID: "fix", DependsOn: []string{"page"}}, {ID: "report", DependsOn: []string{"fix"}}},
+			false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Dependencies go across checklists.
+			checklists := []Checklist{{}, {}}
+			for i, item := range tc.items {
+				checklists[i%2].Items = append(checklists[i%2].Items, item)
+			}
+
+			err := ValidateChecklistItemDependencies(checklists)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestBlockedChecklistItems(t *testing.T) {
+	run := &PlaybookRun{Checklists: []Checklist{
+		{Title: "Triage", Items: []ChecklistItem{
+			{ID: "page", State: ChecklistItemStateClosed},
+			{ID: "identify", State: ChecklistItemStateInProgress},
+		}},
+		{Title: "Resolve", Items: []ChecklistItem{
+			{ID: "rollback", DependsOn: []string{"page", "identify"}},
+			{ID: "notify", DependsOn: []string{"page"}},
+		}},
+	}}
+
+	require.Equal(t, []ChecklistItem{run.Checklists[0].Items[1]}, openPrerequisites(run.Checklists, run.Checklists[1].Items[0]))
+	require.Empty(t, openPrerequisites(run.Checklists, run.Checklists[1].Items[1]))
+
+	data, err := json.Marshal(run)
+	require.NoError(t, err)
+	var marshalled PlaybookRun
+	require.NoError(t, json.Unmarshal(data, &marshalled))
+	require.True(t, marshalled.Checklists[1].Items[0].Blocked)
+	require.False(t, marshalled.Checklists[1].Items[1].Blocked)
+	require.False(t, run.Checklists[1].Items[0].Blocked, "marshalling must not modify the run")
+
+	t.Run("removed prerequisite", func(t *testing.T) {
+		checklists := []Checklist{run.Checklists[0].Clone(), run.Checklists[1].Clone()}
+		removeChecklistItemDependencies(checklists, "identify")
+		require.Equal(t, []string{"page"}, checklists[1].Items[0].DependsOn)
+		require.Equal(t, []string{"page", "identify"}, run.Checklists[1].Items[0].DependsOn, "the clone must not share dependencies")
+	})
+}
+
+func TestPlaybookRun_SetActiveStage(t *testing.T) {
+	run := &PlaybookRun{Checklists: []Checklist{
+		{Title: "Triage", Items: []ChecklistItem{{State: ChecklistItemStateClosed}, {State: ChecklistItemStateOpen}}},
+		{Title: "Resolve", Items: []ChecklistItem{{State: ChecklistItemStateOpen}}},
+	}}
+
+	run.SetActiveStage()
+	require.Equal(t, 0, run.ActiveStage)
+	require.Equal(t, "Triage", run.ActiveStageTitle)
+
+	run.Checklists[0].Items[1].State = ChecklistItemStateClosed
+	run.SetActiveStage()
+	require.Equal(t, 1, run.ActiveStage)
+	require.Equal(t, "Resolve", run.ActiveStageTitle)
+
+	run.Checklists[1].Items[0].State = ChecklistItemStateClosed
+	run.SetActiveStage()
+	require.Equal(t, 1, run.ActiveStage, "the last stage stays active")
+
+	run.Checklists = nil
+	run.SetActiveStage()
+	require.Equal(t, 0, run.ActiveStage)
+	require.Empty(t, run.ActiveStageTitle)
+}
+
+func TestImportChecklistItemDependencies(t *testing.T) {
+	exported := []ExportedChecklist{
+		{Title: "Triage", Items: []ExportedChecklistItem{{Title: "Identify bad deploy"}, {Title: "Page"}, {Title: "Page"}}},
+		{Title: "Resolve", Items: []ExportedChecklistItem{
+			{Title: "Roll back", DependsOn: []string{"Identify bad deploy", "Page", "Unknown", "Roll back"}},
+			{Title: "Notify"},
+		}},
+	}
+	checklists := []Checklist{
+		{Title: "Triage", Items: []ChecklistItem{{Title: "Identify bad deploy"}, {Title: "Page"}, {Title: "Page"}}},
+		{Title: "Resolve", Items: []ChecklistItem{{Title: "Roll back"}, {Title: "Notify"}}},
+	}
+
+	var warnings []string
+	importChecklistItemDependencies(checklists, exported, func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	})
+
+	identify, rollback := checklists[0].Items[0], checklists[1].Items[0]
+	require.NotEmpty(t, identify.ID)
+	require.NotEmpty(t, rollback.ID)
+	require.Empty(t, checklists[1].Items[1].ID, "items without dependencies get no ID")
+	require.Equal(t, []string{identify.ID}, rollback.DependsOn)
+	require.Len(t, warnings, 3)
+	require.NoError(t, ValidateChecklistItemDependencies(checklists))
+
+	require.Equal(t, []string{"Identify bad deploy"}, checklistItemTitles(checklists, rollback.DependsOn))
+}
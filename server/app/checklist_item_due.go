@@ -0,0 +1,233 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// ChecklistItemDuePrefix prefixes the keys of the scheduled jobs that alert about overdue
+// checklist items.
+const ChecklistItemDuePrefix = "due_"
+
+const (
+	// ChecklistItemDueRelativeToRun makes an item due DueAfterSeconds after the run is created.
+	ChecklistItemDueRelativeToRun = "run"
+
+	// ChecklistItemDueRelativeToStage makes an item due DueAfterSeconds after its stage starts.
+	// The stages of a run are its checklists: the first one starts with the run, and each of the
+	// others once every item of the checklists before it is closed or skipped.
+	ChecklistItemDueRelativeToStage = "stage"
+)
+
+// IsOverdue returns true if the item has a deadline that passed at now, in milliseconds, and is
+// not done yet.
+func (i ChecklistItem) IsOverdue(now int64) bool {
+	return i.DueAt != 0 && i.DueAt <= now && !i.IsDone()
+}
+
+// ValidateDue checks that the item's deadline is either absolute or relative to something known,
+// with a positive delay.
+func (i ChecklistItem) ValidateDue() error {
+	if i.DueAt < 0 {
+		return errors.New("due date must not be negative")
+	}
+
+	switch i.DueRelativeTo {
+	case "":
+		if i.DueAfterSeconds != 0 {
+			return errors.New("relative due date must say what it is relative to")
+		}
+	case ChecklistItemDueRelativeToRun, ChecklistItemDueRelativeToStage:
+		if i.DueAfterSeconds <= 0 {
+			return errors.New("relative due date must be a positive number of seconds")
+		}
+	default:
+		return errors.Errorf("unknown due date reference '%s'", i.DueRelativeTo)
+	}
+
+	return nil
+}
+
+// ValidateChecklistItemDueDates checks the deadlines of the playbook's checklist items. Playbooks
+// only have relative deadlines, absolute ones being set on the items of runs.
+func (p Playbook) ValidateChecklistItemDueDates() error {
+	for _, checklist := range p.Checklists {
+		for _, item := range checklist.Items {
+			if item.DueAt != 0 {
+				return errors.Errorf("item '%s' must not have an absolute due date", item.Title)
+			}
+			if err := item.ValidateDue(); err != nil {
+				return errors.Wrapf(err, "item '%s'", item.Title)
+			}
+		}
+	}
+
+	return nil
+}
+
+// startedChecklists returns the number of checklists whose stage has started: the first one, and
+// each of the others whose previous checklists have all their items done.
+func startedChecklists(checklists []Checklist) int {
+	for i, checklist := range checklists {
+		for _, item := range checklist.Items {
+			if !item.IsDone() {
+				return i + 1
+			}
+		}
+	}
+
+	return len(checklists)
+}
+
+// setChecklistItemDueDates computes the deadlines of the run's items with a relative deadline that
+// started running, at now for the stages starting now. Deadlines that are already set are left
+// alone. It returns the deadlines it set.
+func setChecklistItemDueDates(playbookRun *PlaybookRun, now int64) []int64 {
+	var dueAts []int64
+
+	started := startedChecklists(playbookRun.Checklists)
+	for i := range playbookRun.Checklists {
+		items := playbookRun.Checklists[i].Items
+		for j := range items {
+			if items[j].DueAt != 0 || items[j].DueAfterSeconds <= 0 {
+				continue
+			}
+
+			var start int64
+			switch items[j].DueRelativeTo {
+			case ChecklistItemDueRelativeToRun:
+				start = playbookRun.CreateAt
+			case ChecklistItemDueRelativeToStage:
+				if i >= started {
+					continue
+				}
+				start = now
+				if i == 0 {
+					start = playbookRun.CreateAt
+				}
+			default:
+				continue
+			}
+
+			items[j].DueAt = start + items[j].DueAfterSeconds*1000
+			dueAts = append(dueAts, items[j].DueAt)
+		}
+	}
+
+	return dueAts
+}
+
+// checklistItemDueJobKey returns the key of the job alerting about the run's items due at dueAt.
+// The items due at the same time share a job.
+func checklistItemDueJobKey(playbookRunID string, dueAt int64) string {
+	return ChecklistItemDuePrefix + playbookRunID + "_" + strconv.FormatInt(dueAt, 10)
+}
+
+// scheduleChecklistItemDueAlerts schedules the alerts about the run's items due at dueAts.
+func (s *PlaybookRunServiceImpl) scheduleChecklistItemDueAlerts(playbookRunID string, dueAts []int64) {
+	scheduled := make(map[int64]bool, len(dueAts))
+	for _, dueAt := range dueAts {
+		if dueAt == 0 || scheduled[dueAt] {
+			continue
+		}
+		scheduled[dueAt] = true
+
+		if _, err := s.scheduler.ScheduleOnce(checklistItemDueJobKey(playbookRunID, dueAt), model.GetTimeForMillis(dueAt)); err != nil {
+			s.logger.Errorf(errors.Wrapf(err, "failed to schedule checklist item due alert for playbook run id: %s", playbookRunID).Error())
+		}
+	}
+}
+
+// handleChecklistItemDue sends a DM to the owner of the run, and to the assignee if any, about
+// each of the run's items that passed the deadline of the job and are still not done.
+func (s *PlaybookRunServiceImpl) handleChecklistItemDue(key string) {
+	parts := strings.SplitN(strings.TrimPrefix(key, ChecklistItemDuePrefix), "_", 2)
+	if len(parts) != 2 {
+		s.logger.Errorf("handleChecklistItemDue got a malformed key: %s", key)
+		return
+	}
+	playbookRunID := parts[0]
+	dueAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "handleChecklistItemDue got a malformed key: %s", key).Error())
+		return
+	}
+
+	playbookRun, err := s.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "handleChecklistItemDue failed to get playbook run id: %s", playbookRunID).Error())
+		return
+	}
+
+	// Finished runs have nothing left to do.
+	if !playbookRun.IsActive() {
+		return
+	}
+
+	runName := fmt.Sprintf("**%s**", playbookRun.Name)
+	if channel, errChannel := s.pluginAPI.Channel.Get(playbookRun.ChannelID); errChannel == nil {
+		runName = fmt.Sprintf("~%s", channel.Name)
+	}
+
+	for _, checklist := range playbookRun.Checklists {
+		for _, item := range checklist.Items {
+			// The deadline may have changed, or the item been done, since the alert was scheduled.
+			if item.DueAt != dueAt || item.IsDone() {
+				continue
+			}
+
+			message := fmt.Sprintf("The checklist item **%s** of %s is overdue.", item.Title, runName)
+			userIDs := []string{playbookRun.OwnerUserID}
+			if item.AssigneeID != "" && item.AssigneeID != playbookRun.OwnerUserID {
+				userIDs = append(userIDs, item.AssigneeID)
+			}
+			for _, userID := range userIDs {
+				if err = s.poster.DM(userID, &model.Post{Message: message}); err != nil {
+					s.logger.Errorf(errors.Wrapf(err, "failed to send checklist item due alert to user id: %s", userID).Error())
+				}
+			}
+		}
+	}
+}
+
+// SetChecklistItemDueDate sets the absolute deadline of the item, in milliseconds, replacing its
+// relative deadline if any. A zero dueAt removes the deadline.
+func (s *PlaybookRunServiceImpl) SetChecklistItemDueDate(playbookRunID, userID string, checklistNumber, itemNumber int, dueAt int64) error {
+	if dueAt < 0 {
+		return errors.New("due date must not be negative")
+	}
+
+	return retryOnVersionConflict(func() error {
+		return s.setChecklistItemDueDate(playbookRunID, userID, checklistNumber, itemNumber, dueAt)
+	})
+}
+
+// setChecklistItemDueDate applies SetChecklistItemDueDate once, see retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) setChecklistItemDueDate(playbookRunID, userID string, checklistNumber, itemNumber int, dueAt int64) error {
+	playbookRunToModify, err := s.checklistItemParamsVerify(playbookRunID, userID, checklistNumber, itemNumber)
+	if err != nil {
+		return err
+	}
+
+	if !IsValidChecklistItemIndex(playbookRunToModify.Checklists, checklistNumber, itemNumber) {
+		return errors.New("invalid checklist item indices")
+	}
+
+	item := &playbookRunToModify.Checklists[checklistNumber].Items[itemNumber]
+	item.DueAt = dueAt
+	item.DueAfterSeconds = 0
+	item.DueRelativeTo = ""
+
+	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
+		return errors.Wrapf(err, "failed to update playbook run")
+	}
+
+	s.scheduleChecklistItemDueAlerts(playbookRunID, []int64{dueAt})
+	s.poster.PublishWebsocketEventToChannel(playbookRunUpdatedWSEvent, playbookRunToModify, playbookRunToModify.ChannelID)
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecklistItem_ValidateDue(t *testing.T) {
+	for name, tc := range map[string]struct {
+		item  ChecklistItem
+		valid bool
+	}{
+		"no due date":             {ChecklistItem{}, true},
+		"absolute":                {ChecklistItem{DueAt: 1000}, true},
+		"relative to the run":     {ChecklistItem{DueAfterSeconds: 60, DueRelativeTo: ChecklistItemDueRelativeToRun}, true},
+		"relative to the stage":   {ChecklistItem{DueAfterSeconds: 60, DueRelativeTo: ChecklistItemDueRelativeToStage}, true},
+		"negative absolute":       {ChecklistItem{DueAt: -1}, false},
+		"relative to nothing":     {ChecklistItem{DueAfterSeconds: 60}, false},
+		"relative without delay":  {ChecklistItem{DueRelativeTo: ChecklistItemDueRelativeToRun}, false},
+		"negative delay":          {ChecklistItem{DueAfterSeconds: -60, DueRelativeTo: ChecklistItemDueRelativeToStage}, false},
+		"relative to the unknown": {ChecklistItem{DueAfterSeconds: 60, DueRelativeTo: "status"}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.item.ValidateDue()
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+
+	t.Run("playbooks have no absolute due dates", func(t *testing.T) {
+		playbook := Playbook{Checklists: []Checklist{{Items: []ChecklistItem{{Title: "Page", DueAt: 1000}}}}}
+		require.Error(t, playbook.ValidateChecklistItemDueDates())
+	})
+}
+
+func TestChecklistItem_IsOverdue(t *testing.T) {
+	require.False(t, ChecklistItem{}.IsOverdue(2000))
+	require.False(t, ChecklistItem{DueAt: 3000}.IsOverdue(2000))
+	require.True(t, ChecklistItem{DueAt: 2000}.IsOverdue(2000))
+	require.True(t, ChecklistItem{DueAt: 1000, State: ChecklistItemStateInProgress}.IsOverdue(2000))
+	require.False(t, ChecklistItem{DueAt: 1000, State: ChecklistItemStateClosed}.IsOverdue(2000))
+	require.False(t, ChecklistItem{DueAt: 1000, State: ChecklistItemStateSkipped}.IsOverdue(2000))
+}
+
+func TestStartedChecklists(t *testing.T) {
+	open := ChecklistItem{State: ChecklistItemStateOpen}
+	closed := ChecklistItem{State: ChecklistItemStateClosed}
+	skipped := ChecklistItem{State: ChecklistItemStateSkipped}
+
+	require.Equal(t, 0, startedChecklists(nil))
+	require.Equal(t, 1, startedChecklists([]Checklist{{Items: []ChecklistItem{open}}, {Items: []ChecklistItem{open}}}))
+	require.Equal(t, 2, startedChecklists([]Checklist{{Items: []ChecklistItem{closed}}, {Items: []ChecklistItem{open}}, {Items: []ChecklistItem{open}}}))
+	require.Equal(t, 3, startedChecklists([]Checklist{{}, {Items: []ChecklistItem{closed}}, {Items: []ChecklistItem{open}}}))
+	require.Equal(t, 2, startedChecklists([]Checklist{{Items: []ChecklistItem{closed}}, {Items: []ChecklistItem{closed}}}))
+	require.Equal(t, 2, startedChecklists([]Checklist{{Items: []ChecklistItem{closed, skipped}}, {Items: []ChecklistItem{open}}}))
+}
+
+func TestSetChecklistItemDueDates(t *testing.T) {
+	newRun := func() *PlaybookRun {
+		return &PlaybookRun{
+			CreateAt: 1000,
+			Checklists: []Checklist{
+				{Items: []ChecklistItem{
+					{Title: "Triage", DueAfterSeconds: 60, DueRelativeTo: ChecklistItemDueRelativeToStage},
+					{Title: "Page", DueAt: 5000},
+				}},
+				{Items: []ChecklistItem{
+					{Title: "Fix", DueAfterSeconds: 60, DueRelativeTo: ChecklistItemDueRelativeToStage},
+					{Title: "Report", DueAfterSeconds: 3600, DueRelativeTo: ChecklistItemDueRelativeToRun},
+					{Title: "Close"},
+				}},
+			},
+		}
+	}
+
+	t.Run("new run", func(t *testing.T) {
+		run := newRun()
+
+		dueAts := setChecklistItemDueDates(run, 2000)
+		require.Equal(t, []int64{61000, 3601000}, dueAts)
+		require.Equal(t, int64(61000), run.Checklists[0].Items[0].DueAt)
+		require.Equal(t, int64(5000), run.Checklists[0].Items[1].DueAt)
+		require.Zero(t, run.Checklists[1].Items[0].DueAt, "the second stage has not started")
+		require.Equal(t, int64(3601000), run.Checklists[1].Items[1].DueAt)
+		require.Zero(t, run.Checklists[1].Items[2].DueAt)
+	})
+
+	t.Run("next stage starts", func(t *testing.T) {
+		run := newRun()
+		setChecklistItemDueDates(run, 2000)
+		run.Checklists[0].Items[0].State = ChecklistItemStateClosed
+		run.Checklists[0].Items[1].State = ChecklistItemStateClosed
+
+		dueAts := setChecklistItemDueDates(run, 90000)
+		require.Equal(t, []int64{150000}, dueAts)
+		require.Equal(t, int64(150000), run.Checklists[1].Items[0].DueAt)
+
+		require.Empty(t, setChecklistItemDueDates(run, 100000), "due dates are only set once")
+	})
+}
@@ -0,0 +1,298 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	stripmd "github.com/writeas/go-strip-markdown"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// checklistSubItemTitles returns the titles of the sub-items, for exports.
+func checklistSubItemTitles(subItems []ChecklistSubItem) []string {
+	var titles []string
+	for _, subItem := range subItems {
+		titles = append(titles, subItem.Title)
+	}
+
+	return titles
+}
+
+// importChecklistSubItems returns open sub-items with the exported titles.
+func importChecklistSubItems(titles []string) []ChecklistSubItem {
+	var subItems []ChecklistSubItem
+	for _, title := range titles {
+		subItems = append(subItems, ChecklistSubItem{ID: model.NewId(), Title: title})
+	}
+
+	return subItems
+}
+
+// checklistSubItemParamsVerify retrieves the playbook run userID modifies, checking that the
+// sub-item exists.
+func (s *PlaybookRunServiceImpl) checklistSubItemParamsVerify(playbookRunID, userID string, checklistNumber, itemNumber, subItemNumber int) (*PlaybookRun, error) {
+	playbookRunToModify, err := s.checklistItemParamsVerify(playbookRunID, userID, checklistNumber, itemNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsValidChecklistSubItemIndex(playbookRunToModify.Checklists, checklistNumber, itemNumber, subItemNumber) {
+		return nil, errors.New("invalid sub-item number")
+	}
+
+	return playbookRunToModify, nil
+}
+
+// AddChecklistSubItem adds a sub-item to the specified checklist item
+func (s *PlaybookRunServiceImpl) AddChecklistSubItem(playbookRunID, userID string, checklistNumber, itemNumber int, subItem ChecklistSubItem) error {
+	return retryOnVersionConflict(func() error {
+		return s.addChecklistSubItem(playbookRunID, userID, checklistNumber, itemNumber, subItem)
+	})
+}
+
+// addChecklistSubItem applies AddChecklistSubItem once, see retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) addChecklistSubItem(playbookRunID, userID string, checklistNumber, itemNumber int, subItem ChecklistSubItem) error {
+	playbookRunToModify, err := s.checklistItemParamsVerify(playbookRunID, userID, checklistNumber, itemNumber)
+	if err != nil {
+		return err
+	}
+
+	if !IsValidChecklistSubItemState(subItem.State) {
+		return errors.Errorf("invalid sub-item state '%s'", subItem.State)
+	}
+
+	if subItem.ID == "" {
+		subItem.ID = model.NewId()
+	}
+
+	item := &playbookRunToModify.Checklists[checklistNumber].Items[itemNumber]
+	item.SubItems = append(item.SubItems, subItem)
+
+	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
+		return errors.Wrapf(err, "failed to update playbook run")
+	}
+
+	s.poster.PublishWebsocketEventToChannel(playbookRunUpdatedWSEvent, playbookRunToModify, playbookRunToModify.ChannelID)
+
+	return nil
+}
+
+// RemoveChecklistSubItem removes the sub-item at the given index from the given checklist item
+func (s *PlaybookRunServiceImpl) RemoveChecklistSubItem(playbookRunID, userID string, checklistNumber, itemNumber, subItemNumber int) error {
+	return retryOnVersionConflict(func() error {
+		return s.removeChecklistSubItem(playbookRunID, userID, checklistNumber, itemNumber, subItemNumber)
+	})
+}
+
+// removeChecklistSubItem applies RemoveChecklistSubItem once, see retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) removeChecklistSubItem(playbookRunID, userID string, checklistNumber, itemNumber, subItemNumber int) error {
+	playbookRunToModify, err := s.checklistSubItemParamsVerify(playbookRunID, userID, checklistNumber, itemNumber, subItemNumber)
+	if err != nil {
+		return err
+	}
+
+	item := &playbookRunToModify.Checklists[checklistNumber].Items[itemNumber]
+	item.SubItems = append(item.SubItems[:subItemNumber], item.SubItems[subItemNumber+1:]...)
+
+	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
+		return errors.Wrapf(err, "failed to update playbook run")
+	}
+
+	s.poster.PublishWebsocketEventToChannel(playbookRunUpdatedWSEvent, playbookRunToModify, playbookRunToModify.ChannelID)
+
+	return nil
+}
+
+// ModifyChecklistSubItemState checks or unchecks the specified sub-item. Idempotent, will not
+// perform any action if the sub-item is already in the given state.
+func (s *PlaybookRunServiceImpl) ModifyChecklistSubItemState(playbookRunID, userID, newState string, checklistNumber, itemNumber, subItemNumber int) error {
+	return retryOnVersionConflict(func() error {
+		return s.modifyChecklistSubItemState(playbookRunID, userID, newState, checklistNumber, itemNumber, subItemNumber)
+	})
+}
+
+// modifyChecklistSubItemState applies ModifyChecklistSubItemState once, see retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) modifyChecklistSubItemState(playbookRunID, userID, newState string, checklistNumber, itemNumber, subItemNumber int) error {
+	playbookRunToModify, err := s.checklistSubItemParamsVerify(playbookRunID, userID, checklistNumber, itemNumber, subItemNumber)
+	if err != nil {
+		return err
+	}
+
+	if !IsValidChecklistSubItemState(newState) {
+		return errors.Errorf("invalid sub-item state '%s'", newState)
+	}
+
+	item := playbookRunToModify.Checklists[checklistNumber].Items[itemNumber]
+	subItem := item.SubItems[subItemNumber]
+	if newState == subItem.State {
+		return nil
+	}
+
+	modifyMessage := fmt.Sprintf("checked off **%v** of checklist item **%v**", stripmd.Strip(subItem.Title), stripmd.Strip(item.Title))
+	if newState == ChecklistItemStateOpen {
+		modifyMessage = fmt.Sprintf("unchecked **%v** of checklist item **%v**", stripmd.Strip(subItem.Title), stripmd.Strip(item.Title))
+	}
+	post, err := s.modificationMessage(userID, playbookRunToModify.ChannelID, modifyMessage)
+	if err != nil {
+		return err
+	}
+
+	subItem.State = newState
+	subItem.StateModified = model.GetMillis()
+	playbookRunToModify.Checklists[checklistNumber].Items[itemNumber].SubItems[subItemNumber] = subItem
+
+	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
+		s.retractModificationMessage(post, err)
+		return errors.Wrapf(err, "failed to update playbook run, is now in inconsistent state")
+	}
+
+	event := &TimelineEvent{
+		PlaybookRunID: playbookRunID,
+		CreateAt:      subItem.StateModified,
+		EventAt:       subItem.StateModified,
+		EventType:     TaskStateModified,
+		Summary:       modifyMessage,
+		PostID:        post.Id,
+		SubjectUserID: userID,
+	}
+
+	if _, err = s.store.CreateTimelineEvent(event); err != nil {
+		return errors.Wrap(err, "failed to create timeline event")
+	}
+
+	return s.sendPlaybookRunToClient(playbookRunID)
+}
+
+// SetChecklistSubItemAssignee sets the assignee for the specified sub-item. Idempotent, will not
+// perform any actions if the sub-item is already assigned to assigneeID.
+func (s *PlaybookRunServiceImpl) SetChecklistSubItemAssignee(playbookRunID, userID, assigneeID string, checklistNumber, itemNumber, subItemNumber int) error {
+	return retryOnVersionConflict(func() error {
+		return s.setChecklistSubItemAssignee(playbookRunID, userID, assigneeID, checklistNumber, itemNumber, subItemNumber)
+	})
+}
+
+// setChecklistSubItemAssignee applies SetChecklistSubItemAssignee once, see retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) setChecklistSubItemAssignee(playbookRunID, userID, assigneeID string, checklistNumber, itemNumber, subItemNumber int) error {
+	playbookRunToModify, err := s.checklistSubItemParamsVerify(playbookRunID, userID, checklistNumber, itemNumber, subItemNumber)
+	if err != nil {
+		return err
+	}
+
+	item := playbookRunToModify.Checklists[checklistNumber].Items[itemNumber]
+	subItem := item.SubItems[subItemNumber]
+	if assigneeID == subItem.AssigneeID {
+		return nil
+	}
+
+	newAssigneeUsername := noAssigneeName
+	if assigneeID != "" {
+		newUser, userErr := s.pluginAPI.User.Get(assigneeID)
+		if userErr != nil {
+			return errors.Wrapf(userErr, "failed to to resolve user %s", assigneeID)
+		}
+		newAssigneeUsername = "@" + newUser.Username
+	}
+
+	oldAssigneeUsername := noAssigneeName
+	if subItem.AssigneeID != "" {
+		oldUser, userErr := s.pluginAPI.User.Get(subItem.AssigneeID)
+		if userErr != nil {
+			return errors.Wrapf(userErr, "failed to to resolve user %s", subItem.AssigneeID)
+		}
+		oldAssigneeUsername = oldUser.Username
+	}
+
+	modifyMessage := fmt.Sprintf("changed assignee of **%s** of checklist item **%s** from **%s** to **%s**",
+		stripmd.Strip(subItem.Title), stripmd.Strip(item.Title), oldAssigneeUsername, newAssigneeUsername)
+	post, err := s.modificationMessage(userID, playbookRunToModify.ChannelID, modifyMessage)
+	if err != nil {
+		return err
+	}
+
+	subItem.AssigneeID = assigneeID
+	subItem.AssigneeModified = model.GetMillis()
+	playbookRunToModify.Checklists[checklistNumber].Items[itemNumber].SubItems[subItemNumber] = subItem
+
+	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
+		s.retractModificationMessage(post, err)
+		return errors.Wrapf(err, "failed to update playbook run; it is now in an inconsistent state")
+	}
+
+	event := &TimelineEvent{
+		PlaybookRunID: playbookRunID,
+		CreateAt:      subItem.AssigneeModified,
+		EventAt:       subItem.AssigneeModified,
+		EventType:     AssigneeChanged,
+		Summary:       modifyMessage,
+		PostID:        post.Id,
+		SubjectUserID: userID,
+	}
+
+	if _, err = s.store.CreateTimelineEvent(event); err != nil {
+		return errors.Wrap(err, "failed to create timeline event")
+	}
+
+	return s.sendPlaybookRunToClient(playbookRunID)
+}
+
+// AddChecklistItemComment posts the comment of userID as a reply to the thread of the item's
+// comments in the run's channel, starting the thread with the bot the first time.
+func (s *PlaybookRunServiceImpl) AddChecklistItemComment(playbookRunID, userID string, checklistNumber, itemNumber int, message string) (ChecklistItemComment, error) {
+	var comment ChecklistItemComment
+	err := retryOnVersionConflict(func() error {
+		var err error
+		comment, err = s.addChecklistItemComment(playbookRunID, userID, checklistNumber, itemNumber, message)
+		return err
+	})
+
+	return comment, err
+}
+
+// addChecklistItemComment applies AddChecklistItemComment once, see retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) addChecklistItemComment(playbookRunID, userID string, checklistNumber, itemNumber int, message string) (ChecklistItemComment, error) {
+	playbookRunToModify, err := s.checklistItemParamsVerify(playbookRunID, userID, checklistNumber, itemNumber)
+	if err != nil {
+		return ChecklistItemComment{}, err
+	}
+
+	var rootPost *model.Post
+	item := &playbookRunToModify.Checklists[checklistNumber].Items[itemNumber]
+	if item.CommentsPostID == "" {
+		rootPost, err = s.poster.PostMessage(playbookRunToModify.ChannelID, "Comments on the checklist item **%s**", stripmd.Strip(item.Title))
+		if err != nil {
+			return ChecklistItemComment{}, errors.Wrap(err, "failed to post the thread of the checklist item comments")
+		}
+		item.CommentsPostID = rootPost.Id
+	}
+
+	post := &model.Post{
+		UserId:    userID,
+		ChannelId: playbookRunToModify.ChannelID,
+		RootId:    item.CommentsPostID,
+		Message:   message,
+	}
+	if err = s.pluginAPI.Post.CreatePost(post); err != nil {
+		return ChecklistItemComment{}, errors.Wrap(err, "failed to post the checklist item comment")
+	}
+
+	comment := ChecklistItemComment{
+		PostID:   post.Id,
+		UserID:   userID,
+		Message:  message,
+		CreateAt: post.CreateAt,
+	}
+	item.Comments = append(item.Comments, comment)
+
+	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
+		s.retractModificationMessage(post, err)
+		if rootPost != nil {
+			s.retractModificationMessage(rootPost, err)
+		}
+		return ChecklistItemComment{}, errors.Wrapf(err, "failed to update playbook run")
+	}
+
+	s.poster.PublishWebsocketEventToChannel(playbookRunUpdatedWSEvent, playbookRunToModify, playbookRunToModify.ChannelID)
+
+	return comment, nil
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/cluster"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// clusterMutexTimeout is how long to wait for a cluster mutex held by another request, possibly
+// on another server of the cluster.
+const clusterMutexTimeout = time.Minute
+
+// lockClusterMutex locks the mutex named after the given parts across the whole cluster, and
+// returns the function unlocking it. The parts are hashed, as mutex keys are stored in the
+// plugin's key-value store, whose keys are short.
+func lockClusterMutex(pluginAPI *pluginapi.Client, name string, parts ...string) (func(), error) {
+	digest := sha256.New()
+	for _, part := range parts {
+		_, _ = digest.Write([]byte(part))
+		_, _ = digest.Write([]byte{0})
+	}
+	key := "IR_" + name + "_" + hex.EncodeToString(digest.Sum(nil))[:24]
+
+	mutex, err := cluster.NewMutex(clusterMutexAPI{pluginAPI}, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cluster mutex")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clusterMutexTimeout)
+	defer cancel()
+	if err = mutex.LockWithContext(ctx); err != nil {
+		return nil, errors.Wrapf(err, "failed to lock cluster mutex %s", key)
+	}
+
+	return mutex.Unlock, nil
+}
+
+// clusterMutexAPI implements the plugin API needed by cluster mutexes with the plugin API client.
+type clusterMutexAPI struct {
+	pluginAPI *pluginapi.Client
+}
+
+func (a clusterMutexAPI) KVSetWithOptions(key string, value []byte, options model.PluginKVSetOptions) (bool, *model.AppError) {
+	setOptions := []pluginapi.KVSetOption{pluginapi.SetExpiry(time.Duration(options.ExpireInSeconds) * time.Second)}
+	if options.Atomic {
+		setOptions = append(setOptions, pluginapi.SetAtomic(options.OldValue))
+	}
+
+	written, err := a.pluginAPI.KV.Set(key, value, setOptions...)
+	if err != nil {
+		return false, model.NewAppError("KVSetWithOptions", "app.plugin.kv_set.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return written, nil
+}
+
+func (a clusterMutexAPI) LogError(msg string, keyValuePairs ...interface{}) {
+	a.pluginAPI.Log.Error(msg, keyValuePairs...)
+}
@@ -0,0 +1,253 @@
+package app
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// CustomFieldType determines which values a custom field accepts.
+type CustomFieldType string
+
+const (
+	CustomFieldTypeText        CustomFieldType = "text"
+	CustomFieldTypeNumber      CustomFieldType = "number"
+	CustomFieldTypeSelect      CustomFieldType = "select"
+	CustomFieldTypeMultiSelect CustomFieldType = "multiselect"
+	CustomFieldTypeUser        CustomFieldType = "user"
+	CustomFieldTypeURL         CustomFieldType = "url"
+)
+
+// MaxCustomFieldNameLength is the maximum number of characters in the name of a custom field.
+const MaxCustomFieldNameLength = 64
+
+// MaxCustomFieldValueLength is the maximum number of characters in a single custom field value,
+// or in a single option of a select or multiselect field.
+const MaxCustomFieldValueLength = 512
+
+// CustomField is a typed field defined by a playbook. Runs of the playbook copy its custom fields
+// when they start and store a value for each of them.
+type CustomField struct {
+	ID       string          `json:"id"`
+	Name     string          `json:"name"`
+	Type     CustomFieldType `json:"type"`
+	Options  []string        `json:"options"` // The allowed values of select and multiselect fields
+	Required bool            `json:"required"`
+}
+
+// CustomFieldValues holds the values of a run's custom fields, keyed by custom field ID. Only
+// multiselect fields have more than one value.
+type CustomFieldValues map[string][]string
+
+// Clone duplicates the given values.
+func (v CustomFieldValues) Clone() CustomFieldValues {
+	if v == nil {
+		return nil
+	}
+
+	newValues := make(CustomFieldValues, len(v))
+	for fieldID, values := range v {
+		newValues[fieldID] = append([]string(nil), values...)
+	}
+
+	return newValues
+}
+
+// CloneCustomFields duplicates the given custom fields.
+func CloneCustomFields(fields []CustomField) []CustomField {
+	if fields == nil {
+		return nil
+	}
+
+	newFields := make([]CustomField, 0, len(fields))
+	for _, field := range fields {
+		field.Options = append([]string(nil), field.Options...)
+		newFields = append(newFields, field)
+	}
+
+	return newFields
+}
+
+// AssignCustomFieldIDs gives an ID to the custom fields that do not have one yet, e.g., fields
+// just added to a playbook.
+func AssignCustomFieldIDs(fields []CustomField) {
+	for i := range fields {
+		if fields[i].ID == "" {
+			fields[i].ID = model.NewId()
+		}
+	}
+}
+
+// FindCustomField returns the custom field with the given ID, and false if there is none.
+func FindCustomField(fields []CustomField, fieldID string) (CustomField, bool) {
+	for _, field := range fields {
+		if field.ID == fieldID {
+			return field, true
+		}
+	}
+
+	return CustomField{}, false
+}
+
+// ValidateCustomFields checks that custom fields can be stored and used: every field must have a
+// unique (ignoring case), non-empty name and a known type. Select and multiselect fields must have
+// unique, non-empty options; other fields must have none.
+func ValidateCustomFields(fields []CustomField) error {
+	seenIDs := make(map[string]bool, len(fields))
+	seenNames := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if field.ID != "" {
+			if !model.IsValidId(field.ID) {
+				return errors.Errorf("custom field '%s' has an invalid id", field.Name)
+			}
+			if seenIDs[field.ID] {
+				return errors.Errorf("custom field id '%s' is duplicated", field.ID)
+			}
+			seenIDs[field.ID] = true
+		}
+
+		if strings.TrimSpace(field.Name) == "" {
+			return errors.New("custom field names must not be empty")
+		}
+		if utf8.RuneCountInString(field.Name) > MaxCustomFieldNameLength {
+			return errors.Errorf("custom field name '%s' must be at most %d characters", field.Name, MaxCustomFieldNameLength)
+		}
+		key := strings.ToLower(field.Name)
+		if seenNames[key] {
+			return errors.Errorf("custom field name '%s' is duplicated", field.Name)
+		}
+		seenNames[key] = true
+
+		switch field.Type {
+		case CustomFieldTypeSelect, CustomFieldTypeMultiSelect:
+			if len(field.Options) == 0 {
+				return errors.Errorf("custom field '%s' must have at least one option", field.Name)
+			}
+		case CustomFieldTypeText, CustomFieldTypeNumber, CustomFieldTypeUser, CustomFieldTypeURL:
+			if len(field.Options) != 0 {
+				return errors.Errorf("custom field '%s' of type %s must not have options", field.Name, field.Type)
+			}
+		default:
+			return errors.Errorf("custom field '%s' has unknown type '%s'", field.Name, field.Type)
+		}
+
+		seenOptions := make(map[string]bool, len(field.Options))
+		for _, option := range field.Options {
+			if strings.TrimSpace(option) == "" {
+				return errors.Errorf("options of custom field '%s' must not be empty", field.Name)
+			}
+			if utf8.RuneCountInString(option) > MaxCustomFieldValueLength {
+				return errors.Errorf("options of custom field '%s' must be at most %d characters", field.Name, MaxCustomFieldValueLength)
+			}
+			if seenOptions[option] {
+				return errors.Errorf("option '%s' of custom field '%s' is duplicated", option, field.Name)
+			}
+			seenOptions[option] = true
+		}
+	}
+
+	return nil
+}
+
+func (f CustomField) hasOption(value string) bool {
+	for _, option := range f.Options {
+		if option == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NormalizeValues returns the given values trimmed, without blanks and duplicates, and in the
+// canonical form for the field's type, or an error if the field does not accept them.
+func (f CustomField) NormalizeValues(values []string) ([]string, error) {
+	var normalized []string
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if utf8.RuneCountInString(value) > MaxCustomFieldValueLength {
+			return nil, errors.Errorf("values of %s must be at most %d characters", f.Name, MaxCustomFieldValueLength)
+		}
+
+		switch f.Type {
+		case CustomFieldTypeNumber:
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, errors.Errorf("%s must be a number", f.Name)
+			}
+			value = strconv.FormatFloat(number, 'f', -1, 64)
+		case CustomFieldTypeSelect, CustomFieldTypeMultiSelect:
+			if !f.hasOption(value) {
+				return nil, errors.Errorf("%s must be one of: %s", f.Name, strings.Join(f.Options, ", "))
+			}
+		case CustomFieldTypeUser:
+			if !model.IsValidId(value) {
+				return nil, errors.Errorf("%s must be a user id", f.Name)
+			}
+		case CustomFieldTypeURL:
+			parsedURL, err := url.ParseRequestURI(value)
+			if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+				return nil, errors.Errorf("%s must be an http or https URL", f.Name)
+			}
+		}
+
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		normalized = append(normalized, value)
+	}
+
+	if len(normalized) > 1 && f.Type != CustomFieldTypeMultiSelect {
+		return nil, errors.Errorf("%s accepts a single value", f.Name)
+	}
+
+	return normalized, nil
+}
+
+// NormalizeCustomFieldValues normalizes the values of the given custom fields, dropping fields
+// left without values, and returns nil if no field has a value. It returns an error wrapping ErrMalformedPlaybookRun if a value is given for
+// an unknown field or is not accepted by its field.
+func NormalizeCustomFieldValues(fields []CustomField, values CustomFieldValues) (CustomFieldValues, error) {
+	var normalized CustomFieldValues
+	for fieldID, fieldValues := range values {
+		field, ok := FindCustomField(fields, fieldID)
+		if !ok {
+			return nil, errors.Wrapf(ErrMalformedPlaybookRun, "unknown custom field '%s'", fieldID)
+		}
+
+		fieldValues, err := field.NormalizeValues(fieldValues)
+		if err != nil {
+			return nil, errors.Wrap(ErrMalformedPlaybookRun, err.Error())
+		}
+		if len(fieldValues) > 0 {
+			if normalized == nil {
+				normalized = CustomFieldValues{}
+			}
+			normalized[fieldID] = fieldValues
+		}
+	}
+
+	return normalized, nil
+}
+
+// MissingRequiredCustomField returns the first required field without a value, and false if every
+// required field has one.
+func MissingRequiredCustomField(fields []CustomField, values CustomFieldValues) (CustomField, bool) {
+	for _, field := range fields {
+		if field.Required && len(values[field.ID]) == 0 {
+			return field, true
+		}
+	}
+
+	return CustomField{}, false
+}
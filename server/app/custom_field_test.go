@@ -0,0 +1,227 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCustomFields(t *testing.T) {
+	for name, tc := range map[string]struct {
+		fields      []CustomField
+		expectedErr string
+	}{
+		"no fields": {},
+		"valid fields": {
+			fields: []CustomField{
+				{Name: "Region", Type: CustomFieldTypeSelect, Options: []string{"eu", "us"}, Required: true},
+				{Name: "Services", Type: CustomFieldTypeMultiSelect, Options: []string{"api", "web"}},
+				{ID: model.NewId(), Name: "Customers affected", Type: CustomFieldTypeNumber},
+				{Name: "Responder", Type: CustomFieldTypeUser},
+				{Name: "Ticket", Type: CustomFieldTypeURL},
+				{Name: "Notes", Type: CustomFieldTypeText},
+			},
+		},
+		"invalid id": {
+			fields:      []CustomField{{ID: "invalid", Name: "Notes", Type: CustomFieldTypeText}},
+			expectedErr: "invalid id",
+		},
+		"blank name": {
+			fields:      []CustomField{{Name: " ", Type: CustomFieldTypeText}},
+			expectedErr: "must not be empty",
+		},
+		"name too long": {
+			fields:      []CustomField{{Name: strings.Repeat("a", MaxCustomFieldNameLength+1), Type: CustomFieldTypeText}},
+			expectedErr: "at most",
+		},
+		"duplicate names ignoring case": {
+			fields: []CustomField{
+				{Name: "Notes", Type: CustomFieldTypeText},
+				{Name: "notes", Type: CustomFieldTypeURL},
+			},
+			expectedErr: "is duplicated",
+		},
+		"unknown type": {
+			fields:      []CustomField{{Name: "Notes", Type: "date"}},
+			expectedErr: "unknown type",
+		},
+		"select without options": {
+			fields:      []CustomField{{Name: "Region", Type: CustomFieldTypeSelect}},
+			expectedErr: "at least one option",
+		},
+		"options on a text field": {
+			fields:      []CustomField{{Name: "Notes", Type: CustomFieldTypeText, Options: []string{"a"}}},
+			expectedErr: "must not have options",
+		},
+		"duplicate options": {
+			fields:      []CustomField{{Name: "Region", Type: CustomFieldTypeSelect, Options: []string{"eu", "eu"}}},
+			expectedErr: "option 'eu' of custom field 'Region' is duplicated",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateCustomFields(tc.fields)
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestCustomField_NormalizeValues(t *testing.T) {
+	userID := model.NewId()
+
+	for name, tc := range map[string]struct {
+		field       CustomField
+		values      []string
+		expected    []string
+		expectedErr string
+	}{
+		"text is trimmed": {
+			field:    CustomField{Name: "Notes", Type: CustomFieldTypeText},
+			values:   []string{"  some notes "},
+			expected: []string{"some notes"},
+		},
+		"blank values are dropped": {
+			field:  CustomField{Name: "Notes", Type: CustomFieldTypeText},
+			values: []string{"", " "},
+		},
+		"single-valued field with several values": {
+			field:       CustomField{Name: "Notes", Type: CustomFieldTypeText},
+			values:      []string{"a", "b"},
+			expectedErr: "single value",
+		},
+		"number is canonicalized": {
+			field:    CustomField{Name: "Customers", Type: CustomFieldTypeNumber},
+			values:   []string{"042.50"},
+			expected: []string{"42.5"},
+		},
+		"invalid number": {
+			field:       CustomField{Name: "Customers", Type: CustomFieldTypeNumber},
+			values:      []string{"many"},
+			expectedErr: "must be a number",
+		},
+		"select option": {
+			field:    CustomField{Name: "Region", Type: CustomFieldTypeSelect, Options: []string{"eu", "us"}},
+			values:   []string{"us"},
+			expected: []string{"us"},
+		},
+		"unknown select option": {
+			field:       CustomField{Name: "Region", Type: CustomFieldTypeSelect, Options: []string{"eu", "us"}},
+			values:      []string{"apac"},
+			expectedErr: "must be one of: eu, us",
+		},
+		"multiselect options are deduplicated": {
+			field:    CustomField{Name: "Services", Type: CustomFieldTypeMultiSelect, Options: []string{"api", "web"}},
+			values:   []string{"web", "api", " web"},
+			expected: []string{"web", "api"},
+		},
+		"user id": {
+			field:    CustomField{Name: "Responder", Type: CustomFieldTypeUser},
+			values:   []string{userID},
+			expected: []string{userID},
+		},
+		"invalid user id": {
+			field:       CustomField{Name: "Responder", Type: CustomFieldTypeUser},
+			values:      []string{"someone"},
+			expectedErr: "must be a user id",
+		},
+		"url": {
+			field:    CustomField{Name: "Ticket", Type: CustomFieldTypeURL},
+			values:   []string{"https://tickets.example.com/123"},
+			expected: []string{"https://tickets.example.com/123"},
+		},
+		"url without http scheme": {
+			field:       CustomField{Name: "Ticket", Type: CustomFieldTypeURL},
+			values:      []string{"ftp://tickets.example.com/123"},
+			expectedErr: "http or https URL",
+		},
+		"value too long": {
+			field:       CustomField{Name: "Notes", Type: CustomFieldTypeText},
+			values:      []string{strings.Repeat("a", MaxCustomFieldValueLength+1)},
+			expectedErr: "at most",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			values, err := tc.field.NormalizeValues(tc.values)
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, values)
+		})
+	}
+}
+
+func TestNormalizeCustomFieldValues(t *testing.T) {
+	fields := []CustomField{
+		{ID: "regionfieldid", Name: "Region", Type: CustomFieldTypeSelect, Options: []string{"eu", "us"}},
+		{ID: "notesfieldid", Name: "Notes", Type: CustomFieldTypeText},
+	}
+
+	t.Run("values are normalized and empty fields dropped", func(t *testing.T) {
+		values, err := NormalizeCustomFieldValues(fields, CustomFieldValues{
+			"regionfieldid": {" eu "},
+			"notesfieldid":  {},
+		})
+		require.NoError(t, err)
+		require.Equal(t, CustomFieldValues{"regionfieldid": {"eu"}}, values)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		values, err := NormalizeCustomFieldValues(fields, nil)
+		require.NoError(t, err)
+		require.Nil(t, values)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := NormalizeCustomFieldValues(fields, CustomFieldValues{"unknownfieldid": {"a"}})
+		require.ErrorIs(t, err, ErrMalformedPlaybookRun)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		_, err := NormalizeCustomFieldValues(fields, CustomFieldValues{"regionfieldid": {"apac"}})
+		require.ErrorIs(t, err, ErrMalformedPlaybookRun)
+	})
+}
+
+func TestMissingRequiredCustomField(t *testing.T) {
+	fields := []CustomField{
+		{ID: "notesfieldid", Name: "Notes", Type: CustomFieldTypeText},
+		{ID: "regionfieldid", Name: "Region", Type: CustomFieldTypeSelect, Options: []string{"eu", "us"}, Required: true},
+	}
+
+	field, missing := MissingRequiredCustomField(fields, CustomFieldValues{"notesfieldid": {"a"}})
+	require.True(t, missing)
+	require.Equal(t, "Region", field.Name)
+
+	_, missing = MissingRequiredCustomField(fields, CustomFieldValues{"regionfieldid": {"eu"}})
+	require.False(t, missing)
+}
+
+func TestCustomFieldValuesFromDialog(t *testing.T) {
+	fields := []CustomField{
+		{ID: "servicesfieldid", Name: "Services", Type: CustomFieldTypeMultiSelect, Options: []string{"api", "web"}},
+		{ID: "customersfieldid", Name: "Customers", Type: CustomFieldTypeNumber},
+		{ID: "notesfieldid", Name: "Notes", Type: CustomFieldTypeText},
+		{ID: "ticketfieldid", Name: "Ticket", Type: CustomFieldTypeURL},
+	}
+
+	values := CustomFieldValuesFromDialog(fields, map[string]interface{}{
+		DialogFieldCustomFieldKeyPrefix + "servicesfieldid":  "api, web",
+		DialogFieldCustomFieldKeyPrefix + "customersfieldid": 12.0,
+		DialogFieldCustomFieldKeyPrefix + "notesfieldid":     " ",
+		DialogFieldNameKey: "Run name",
+	})
+
+	require.Equal(t, CustomFieldValues{
+		"servicesfieldid":  {"api", " web"},
+		"customersfieldid": {"12"},
+	}, values)
+}
@@ -0,0 +1,213 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/timeutils"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// DigestPrefix prefixes the keys of the scheduled jobs that send the daily digests.
+const DigestPrefix = "digest_"
+
+// DigestHour is the hour of the day, in the user's timezone, at which the daily digest is sent.
+const DigestHour = 9
+
+// digestDateLayout formats the day of a digest in the key of the job sending it.
+const digestDateLayout = "2006-01-02"
+
+// UserInfo holds the settings of a user, along with what was last sent to them.
+type UserInfo struct {
+	ID            string
+	DigestEnabled bool
+	LastDigestAt  int64
+}
+
+// OverdueStatusUpdateRun is an active playbook run whose status update reminder went off without
+// a status update being posted.
+type OverdueStatusUpdateRun struct {
+	PlaybookRunID      string
+	Name               string
+	TeamID             string
+	ChannelName        string
+	LastStatusUpdateAt int64
+	PreviousReminder   time.Duration
+}
+
+// StatusUpdateDueAt returns when the run's status update was due, in milliseconds.
+func (r OverdueStatusUpdateRun) StatusUpdateDueAt() int64 {
+	return r.LastStatusUpdateAt + r.PreviousReminder.Milliseconds()
+}
+
+// nextDigestAt returns the first DigestHour after now, in loc.
+func nextDigestAt(now time.Time, loc *time.Location) time.Time {
+	now = now.In(loc)
+	next := time.Date(now.Year(), now.Month(), now.Day(), DigestHour, 0, 0, 0, loc)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	return next
+}
+
+// digestJobKey returns the key of the job sending the user's digest at the given time. Each day
+// has its own key, so that the job sending a digest can schedule the next one.
+func digestJobKey(userID string, at time.Time) string {
+	return DigestPrefix + userID + "_" + at.Format(digestDateLayout)
+}
+
+// userLocation returns the location of the user's preferred timezone, or UTC if it is unknown.
+func (s *PlaybookRunServiceImpl) userLocation(userID string) *time.Location {
+	user, err := s.pluginAPI.User.Get(userID)
+	if err != nil {
+		s.logger.Warnf("failed to get user id %s, defaulting to UTC: %v", userID, err)
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(user.GetPreferredTimezone())
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// scheduleDigest schedules the user's first digest after the given time, in their location.
+func (s *PlaybookRunServiceImpl) scheduleDigest(userID string, after time.Time, loc *time.Location) error {
+	next := nextDigestAt(after, loc)
+	if _, err := s.scheduler.ScheduleOnce(digestJobKey(userID, next), next); err != nil {
+		return errors.Wrap(err, "unable to schedule digest")
+	}
+
+	return nil
+}
+
+// SetDigestEnabled opts the user in or out of the daily digest, scheduling the next one if in.
+func (s *PlaybookRunServiceImpl) SetDigestEnabled(userID string, enabled bool) error {
+	info, err := s.store.GetUserInfo(userID)
+	if errors.Is(err, ErrNotFound) {
+		info = UserInfo{ID: userID}
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to get user info")
+	}
+
+	info.DigestEnabled = enabled
+	if err = s.store.UpsertUserInfo(info); err != nil {
+		return errors.Wrapf(err, "failed to update user info")
+	}
+
+	// The job already scheduled, if any, does nothing once the user opted out.
+	if !enabled {
+		return nil
+	}
+
+	return s.scheduleDigest(userID, time.Now(), s.userLocation(userID))
+}
+
+// handleDigest sends the user their daily digest, unless they opted out since it was scheduled,
+// and schedules the next one.
+func (s *PlaybookRunServiceImpl) handleDigest(key string) {
+	userID := strings.TrimPrefix(key, DigestPrefix)
+	if i := strings.LastIndex(userID, "_"); i >= 0 {
+		userID = userID[:i]
+	}
+
+	info, err := s.store.GetUserInfo(userID)
+	if errors.Is(err, ErrNotFound) {
+		return
+	} else if err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "handleDigest failed to get user info for id: %s", userID).Error())
+		return
+	}
+
+	if !info.DigestEnabled {
+		return
+	}
+
+	// A digest already went out today, e.g. if the user opted out and back in.
+	now := time.Now()
+	loc := s.userLocation(userID)
+	lastDigestAt := model.GetTimeForMillis(info.LastDigestAt).In(loc)
+	if info.LastDigestAt == 0 || lastDigestAt.Format(digestDateLayout) != now.In(loc).Format(digestDateLayout) {
+		if err = s.sendDigest(userID, now, loc); err != nil {
+			s.logger.Errorf(errors.Wrapf(err, "failed to send digest to user id: %s", userID).Error())
+		}
+
+		info.LastDigestAt = model.GetMillisForTime(now)
+		if err = s.store.UpsertUserInfo(info); err != nil {
+			s.logger.Errorf(errors.Wrapf(err, "failed to update user info for id: %s", userID).Error())
+		}
+	}
+
+	// Jobs can't be rescheduled within themselves with the same key, so skip ahead to be sure
+	// the next one is tomorrow's even if this one fired a little early.
+	if err = s.scheduleDigest(userID, now.Add(time.Hour), loc); err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "failed to schedule next digest for user id: %s", userID).Error())
+	}
+}
+
+// sendDigest sends the user a DM listing the runs they take part in that are overdue for a status
+// update, and the open checklist items assigned to them, with times in loc. Nothing is sent if
+// both are empty.
+func (s *PlaybookRunServiceImpl) sendDigest(userID string, now time.Time, loc *time.Location) error {
+	enabledTeams := fromSliceToMap(s.configService.GetConfiguration().EnabledTeams)
+
+	runs, err := s.store.GetOverdueStatusUpdateRuns(userID, model.GetMillisForTime(now))
+	if err != nil {
+		return errors.Wrap(err, "failed to get runs overdue for a status update")
+	}
+
+	requesterInfo, err := GetRequesterInfo(userID, s.pluginAPI)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve permissions")
+	}
+
+	items, err := s.GetChecklistItems(requesterInfo, ChecklistItemFilterOptions{
+		AssigneeID:     userID,
+		States:         []string{ChecklistItemStateOpen, ChecklistItemStateInProgress},
+		ActiveRunsOnly: true,
+		Sort:           SortByDueAt,
+		Direction:      DirectionAsc,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to get assigned checklist items")
+	}
+
+	var message strings.Builder
+	for _, run := range runs {
+		if len(enabledTeams) != 0 && !enabledTeams[run.TeamID] {
+			continue
+		}
+		if message.Len() == 0 {
+			message.WriteString("##### Runs overdue for a status update\n")
+		}
+		overdue := timeutils.DurationString(timeutils.GetTimeForMillis(run.StatusUpdateDueAt()), now)
+		fmt.Fprintf(&message, "- ~%s, overdue by %s\n", run.ChannelName, overdue)
+	}
+
+	if len(items) > 0 {
+		if message.Len() > 0 {
+			message.WriteString("\n")
+		}
+		message.WriteString("##### Your open checklist items\n")
+		for _, item := range items {
+			due := ""
+			switch {
+			case item.IsOverdue(model.GetMillisForTime(now)):
+				due = " **(overdue since " + timeutils.GetTimeForMillis(item.DueAt).In(loc).Format("Jan 2 15:04") + ")**"
+			case item.DueAt != 0:
+				due = " (due " + timeutils.GetTimeForMillis(item.DueAt).In(loc).Format("Jan 2 15:04") + ")"
+			}
+			fmt.Fprintf(&message, "- %s in ~%s%s\n", item.Title, item.ChannelName, due)
+		}
+	}
+
+	if message.Len() == 0 {
+		return nil
+	}
+
+	return s.poster.DM(userID, &model.Post{Message: "#### Your daily digest\n" + message.String()})
+}
@@ -0,0 +1,185 @@
+package app_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/app"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/config"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/telemetry"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	mock_app "github.com/mattermost/mattermost-plugin-incident-collaboration/server/app/mocks"
+	mock_bot "github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot/mocks"
+	mock_config "github.com/mattermost/mattermost-plugin-incident-collaboration/server/config/mocks"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/cluster"
+)
+
+func TestHandleDigest(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	user := &model.User{
+		Id: "user_id",
+		Timezone: model.StringMap{
+			"useAutomaticTimezone": "false",
+			"manualTimezone":       "Europe/Paris",
+		},
+	}
+
+	setup := func(t *testing.T) (*mock_app.MockPlaybookRunStore, *mock_bot.MockPoster, *mock_app.MockJobOnceScheduler, *app.PlaybookRunServiceImpl) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		pluginAPI.On("GetUser", "user_id").Return(user, nil)
+		pluginAPI.On("HasPermissionTo", "user_id", mock.Anything).Return(false)
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		configService.EXPECT().GetConfiguration().Return(&config.Configuration{}).AnyTimes()
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{})
+		return store, poster, scheduler, s
+	}
+
+	expectNextDigest := func(t *testing.T, scheduler *mock_app.MockJobOnceScheduler) {
+		scheduler.EXPECT().ScheduleOnce(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(key string, runAt time.Time) (*cluster.JobOnce, error) {
+				runAt = runAt.In(paris)
+				require.Equal(t, app.DigestHour, runAt.Hour())
+				require.Equal(t, 0, runAt.Minute())
+				require.True(t, runAt.After(time.Now()))
+				require.True(t, runAt.Before(time.Now().Add(25*time.Hour)))
+				require.Equal(t, app.DigestPrefix+"user_id_"+runAt.Format("2006-01-02"), key)
+				return nil, nil
+			})
+	}
+
+	t.Run("digest lists overdue runs and open items", func(t *testing.T) {
+		store, poster, scheduler, s := setup(t)
+
+		now := model.GetMillis()
+		store.EXPECT().GetUserInfo("user_id").Return(app.UserInfo{ID: "user_id", DigestEnabled: true}, nil)
+		store.EXPECT().GetOverdueStatusUpdateRuns("user_id", gomock.Any()).Return([]app.OverdueStatusUpdateRun{
+			{
+				PlaybookRunID:      "run_id",
+				Name:               "Outage",
+				ChannelName:        "outage",
+				LastStatusUpdateAt: now - 2*time.Hour.Milliseconds(),
+				PreviousReminder:   time.Hour,
+			},
+		}, nil)
+		store.EXPECT().GetChecklistItems(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(requesterInfo app.RequesterInfo, options app.ChecklistItemFilterOptions) ([]app.PlaybookRunChecklistItem, error) {
+				require.Equal(t, "user_id", requesterInfo.UserID)
+				require.Equal(t, "user_id", options.AssigneeID)
+				require.True(t, options.ActiveRunsOnly)
+				return []app.PlaybookRunChecklistItem{
+					{ChecklistItem: app.ChecklistItem{Title: "Page the DBA", DueAt: now - 1000}, ChannelName: "outage"},
+					{ChecklistItem: app.ChecklistItem{Title: "Write the report"}, ChannelName: "breach"},
+				}, nil
+			})
+
+		var message string
+		poster.EXPECT().DM("user_id", gomock.Any()).
+			DoAndReturn(func(userID string, post *model.Post) error {
+				message = post.Message
+				return nil
+			})
+		store.EXPECT().UpsertUserInfo(gomock.Any()).
+			DoAndReturn(func(info app.UserInfo) error {
+				require.True(t, info.DigestEnabled)
+				require.GreaterOrEqual(t, info.LastDigestAt, now)
+				return nil
+			})
+		expectNextDigest(t, scheduler)
+
+		s.HandleReminder(app.DigestPrefix + "user_id_2021-06-30")
+
+		require.Contains(t, message, "- ~outage, overdue by 1h\n")
+		require.Contains(t, message, "- Page the DBA in ~outage **(overdue since ")
+		require.Contains(t, message, "- Write the report in ~breach\n")
+		require.Less(t, strings.Index(message, "Page the DBA"), strings.Index(message, "Write the report"))
+	})
+
+	t.Run("nothing is sent without overdue runs or open items", func(t *testing.T) {
+		store, _, scheduler, s := setup(t)
+
+		store.EXPECT().GetUserInfo("user_id").Return(app.UserInfo{ID: "user_id", DigestEnabled: true}, nil)
+		store.EXPECT().GetOverdueStatusUpdateRuns("user_id", gomock.Any()).Return([]app.OverdueStatusUpdateRun{}, nil)
+		store.EXPECT().GetChecklistItems(gomock.Any(), gomock.Any()).Return([]app.PlaybookRunChecklistItem{}, nil)
+		store.EXPECT().UpsertUserInfo(gomock.Any()).Return(nil)
+		expectNextDigest(t, scheduler)
+
+		s.HandleReminder(app.DigestPrefix + "user_id_2021-06-30")
+	})
+
+	t.Run("digest is sent once a day", func(t *testing.T) {
+		store, _, scheduler, s := setup(t)
+
+		store.EXPECT().GetUserInfo("user_id").Return(app.UserInfo{ID: "user_id", DigestEnabled: true, LastDigestAt: model.GetMillis()}, nil)
+		expectNextDigest(t, scheduler)
+
+		s.HandleReminder(app.DigestPrefix + "user_id_2021-06-30")
+	})
+
+	t.Run("users who opted out get no digest", func(t *testing.T) {
+		store, _, _, s := setup(t)
+
+		store.EXPECT().GetUserInfo("user_id").Return(app.UserInfo{ID: "user_id", DigestEnabled: false}, nil)
+
+		s.HandleReminder(app.DigestPrefix + "user_id_2021-06-30")
+	})
+}
+
+func TestSetDigestEnabled(t *testing.T) {
+	setup := func(t *testing.T) (*mock_app.MockPlaybookRunStore, *mock_app.MockJobOnceScheduler, *app.PlaybookRunServiceImpl) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id"}, nil)
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{})
+		return store, scheduler, s
+	}
+
+	t.Run("opting in schedules the next digest", func(t *testing.T) {
+		store, scheduler, s := setup(t)
+
+		store.EXPECT().GetUserInfo("user_id").Return(app.UserInfo{}, app.ErrNotFound)
+		store.EXPECT().UpsertUserInfo(app.UserInfo{ID: "user_id", DigestEnabled: true}).Return(nil)
+		scheduler.EXPECT().ScheduleOnce(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(key string, runAt time.Time) (*cluster.JobOnce, error) {
+				runAt = runAt.UTC()
+				require.Equal(t, app.DigestHour, runAt.Hour())
+				require.Equal(t, app.DigestPrefix+"user_id_"+runAt.Format("2006-01-02"), key)
+				return nil, nil
+			})
+
+		require.NoError(t, s.SetDigestEnabled("user_id", true))
+	})
+
+	t.Run("opting out keeps the last digest time", func(t *testing.T) {
+		store, _, s := setup(t)
+
+		store.EXPECT().GetUserInfo("user_id").Return(app.UserInfo{ID: "user_id", DigestEnabled: true, LastDigestAt: 1000}, nil)
+		store.EXPECT().UpsertUserInfo(app.UserInfo{ID: "user_id", LastDigestAt: 1000}).Return(nil)
+
+		require.NoError(t, s.SetDigestEnabled("user_id", false))
+	})
+}
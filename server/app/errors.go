@@ -20,5 +20,32 @@ var ErrPlaybookRunActive = errors.New("already active")
 // ErrMalformedPlaybookRun occurs when a playbook run is not valid.
 var ErrMalformedPlaybookRun = errors.New("malformed")
 
+// ErrMissingRequiredCustomField occurs when a playbook run is missing the value of one of its
+// required custom fields. It is also an ErrMalformedPlaybookRun.
+var ErrMissingRequiredCustomField = errors.Wrap(ErrMalformedPlaybookRun, "missing value for required custom field")
+
+// ErrMalformedPlaybook occurs when a playbook is not valid.
+var ErrMalformedPlaybook = errors.New("malformed playbook")
+
+// ErrPlaybookRevisionConflict occurs when a playbook is updated concurrently, and a revision with
+// the same number has already been saved.
+var ErrPlaybookRevisionConflict = errors.New("playbook was updated concurrently")
+
+// ErrPlaybookRunVersionConflict occurs when updating a playbook run that was updated concurrently
+// since it was read.
+var ErrPlaybookRunVersionConflict = errors.New("playbook run was updated concurrently")
+
+// ErrPlaybookRunPreconditionFailed occurs when changing a playbook run that is not at the version
+// the change expects, see PlaybookRunService.WithExpectedVersion.
+var ErrPlaybookRunPreconditionFailed = errors.New("playbook run is not at the expected version")
+
+// ErrPlaybookSyncNotConfigured occurs when syncing playbooks from the server's directory while
+// no directory is configured.
+var ErrPlaybookSyncNotConfigured = errors.New("playbooks sync directory is not configured")
+
 // ErrDuplicateEntry occurs when failing to insert because the entry already existed.
 var ErrDuplicateEntry = errors.New("duplicate entry")
+
+// ErrChecklistItemBlocked occurs when closing a checklist item whose prerequisites are not all
+// closed yet.
+var ErrChecklistItemBlocked = errors.New("checklist item is blocked by open prerequisites")
@@ -0,0 +1,441 @@
+package app
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// InboundWebhookFormat is the kind of payload a playbook's inbound webhook receives. It determines
+// which fields of the payload are read when the inbound webhook does not override them.
+type InboundWebhookFormat string
+
+const (
+	// InboundWebhookFormatGeneric reads the top-level title, description, severity and
+	// fingerprint fields of any JSON object.
+	InboundWebhookFormatGeneric InboundWebhookFormat = "generic"
+
+	// InboundWebhookFormatAlertmanager reads the notifications of Prometheus Alertmanager's
+	// webhook receiver, de-duplicating them by their group key.
+	InboundWebhookFormatAlertmanager InboundWebhookFormat = "alertmanager"
+)
+
+const (
+	// MinInboundWebhookTokenLength is the minimum length of an inbound webhook token.
+	MinInboundWebhookTokenLength = 16
+
+	// MaxInboundWebhookTokenLength is the maximum length of an inbound webhook token.
+	MaxInboundWebhookTokenLength = 128
+
+	// MaxInboundWebhookFieldLength is the maximum length of the path to a field of an alert.
+	MaxInboundWebhookFieldLength = 256
+
+	// MaxAlertFingerprintLength is the maximum length of a stored alert fingerprint. Longer
+	// fingerprints are stored as their SHA-256 digest.
+	MaxAlertFingerprintLength = 256
+
+	// MaxInboundEventIDLength is the maximum length of the event ID of an inbound status update.
+	MaxInboundEventIDLength = 256
+)
+
+// InboundWebhook lets monitoring systems start runs of a playbook by posting alerts to
+// /webhooks/playbooks/{id}, and post status updates to its runs through /webhooks/runs/{id}/status,
+// authenticated by Token. The fields are dot-separated paths into the alert's JSON, with numbers
+// indexing arrays; the ones left empty default to the format's paths.
+type InboundWebhook struct {
+	Enabled          bool                 `json:"enabled"`
+	Token            string               `json:"token"` // Generated when enabling the webhook without one
+	Format           InboundWebhookFormat `json:"format"`
+	NameField        string               `json:"name_field"`
+	DescriptionField string               `json:"description_field"`
+	SeverityField    string               `json:"severity_field"`
+	FingerprintField string               `json:"fingerprint_field"` // Alerts with the fingerprint of an active run are added to it
+	ServiceUserID    string               `json:"service_user_id"`   // The bot account posting the status updates, instead of the plugin's bot
+}
+
+// inboundWebhookFieldDefaults holds the default name, description, severity and fingerprint
+// paths of each format.
+var inboundWebhookFieldDefaults = map[InboundWebhookFormat][4]string{
+	InboundWebhookFormatGeneric:      {"title", "description", "severity", "fingerprint"},
+	InboundWebhookFormatAlertmanager: {"commonLabels.alertname", "commonAnnotations.description", "commonLabels.severity", "groupKey"},
+}
+
+// Fields returns the paths of the alert's name, description, severity and fingerprint, falling
+// back to the defaults of the webhook's format.
+func (w InboundWebhook) Fields() (name, description, severity, fingerprint string) {
+	defaults := inboundWebhookFieldDefaults[w.format()]
+
+	pick := func(field, defaultField string) string {
+		if field != "" {
+			return field
+		}
+		return defaultField
+	}
+
+	return pick(w.NameField, defaults[0]),
+		pick(w.DescriptionField, defaults[1]),
+		pick(w.SeverityField, defaults[2]),
+		pick(w.FingerprintField, defaults[3])
+}
+
+// format returns the webhook's format, treating an empty format as generic.
+func (w InboundWebhook) format() InboundWebhookFormat {
+	if w.Format == "" {
+		return InboundWebhookFormatGeneric
+	}
+
+	return w.Format
+}
+
+// ValidateInboundWebhook checks that the playbook's inbound webhook, if enabled, has a known
+// format, well-formed field paths and token, and that the playbook has a default owner to own the
+// runs it starts.
+func (p Playbook) ValidateInboundWebhook() error {
+	webhook := p.InboundWebhook
+
+	if _, ok := inboundWebhookFieldDefaults[webhook.format()]; !ok {
+		return errors.Errorf("unknown inbound webhook format '%s'", webhook.Format)
+	}
+
+	for _, field := range []string{webhook.NameField, webhook.DescriptionField, webhook.SeverityField, webhook.FingerprintField} {
+		if field == "" {
+			continue
+		}
+		if len(field) > MaxInboundWebhookFieldLength {
+			return errors.Errorf("inbound webhook field '%s' must be at most %d characters", field, MaxInboundWebhookFieldLength)
+		}
+		for _, key := range strings.Split(field, ".") {
+			if strings.TrimSpace(key) == "" {
+				return errors.Errorf("inbound webhook field '%s' must not have empty parts", field)
+			}
+		}
+	}
+
+	if webhook.ServiceUserID != "" && !model.IsValidId(webhook.ServiceUserID) {
+		return errors.New("invalid inbound webhook service user id")
+	}
+
+	if webhook.Token != "" {
+		if len(webhook.Token) < MinInboundWebhookTokenLength || len(webhook.Token) > MaxInboundWebhookTokenLength {
+			return errors.Errorf("inbound webhook token must be between %d and %d characters", MinInboundWebhookTokenLength, MaxInboundWebhookTokenLength)
+		}
+		if strings.ContainsAny(webhook.Token, " \t\r\n") {
+			return errors.New("inbound webhook token must not contain whitespace")
+		}
+	}
+
+	if webhook.Enabled && (!p.DefaultOwnerEnabled || p.DefaultOwnerID == "") {
+		return errors.New("the inbound webhook requires a default owner for the runs it starts")
+	}
+
+	return nil
+}
+
+// AssignInboundWebhookToken generates a token for the playbook's inbound webhook if it is enabled
+// without one.
+func AssignInboundWebhookToken(playbook *Playbook) {
+	if playbook.InboundWebhook.Enabled && playbook.InboundWebhook.Token == "" {
+		playbook.InboundWebhook.Token = model.NewId() + model.NewId()
+	}
+}
+
+// InboundAlert is an alert received by a playbook's inbound webhook, mapped to the attributes of
+// the run it starts.
+type InboundAlert struct {
+	Name        string
+	Description string
+	Severity    string
+	Fingerprint string
+	Resolved    bool // Alertmanager notifications for alerts that stopped firing
+}
+
+// ParseInboundAlert reads the alert in body according to the webhook's format and fields. Values
+// missing from the alert are left empty.
+func ParseInboundAlert(webhook InboundWebhook, body []byte) (InboundAlert, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var payload map[string]interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		return InboundAlert{}, errors.Wrap(err, "alert must be a JSON object")
+	}
+
+	nameField, descriptionField, severityField, fingerprintField := webhook.Fields()
+
+	alert := InboundAlert{
+		Name:        strings.TrimSpace(alertField(payload, nameField)),
+		Description: strings.TrimSpace(alertField(payload, descriptionField)),
+		Severity:    strings.TrimSpace(alertField(payload, severityField)),
+		Fingerprint: strings.TrimSpace(alertField(payload, fingerprintField)),
+	}
+
+	if webhook.format() == InboundWebhookFormatAlertmanager {
+		alert.Resolved = alertField(payload, "status") == "resolved"
+	}
+
+	if utf8.RuneCountInString(alert.Name) > model.CHANNEL_DISPLAY_NAME_MAX_RUNES {
+		alert.Name = string([]rune(alert.Name)[:model.CHANNEL_DISPLAY_NAME_MAX_RUNES])
+	}
+
+	if len(alert.Fingerprint) > MaxAlertFingerprintLength {
+		digest := sha256.Sum256([]byte(alert.Fingerprint))
+		alert.Fingerprint = hex.EncodeToString(digest[:])
+	}
+
+	return alert, nil
+}
+
+// alertField returns the scalar value at the dot-separated path in the alert, formatted as a
+// string, or "" if there is no such value.
+func alertField(payload map[string]interface{}, path string) string {
+	var value interface{} = payload
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[key]
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				return ""
+			}
+			value = v[index]
+		default:
+			return ""
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
+
+// StartPlaybookRunFromAlert starts a run of the playbook for an alert received by its inbound
+// webhook, with the playbook's defaults and the bot as the reporter. Alerts with the fingerprint
+// of one of the playbook's active runs are posted to that run instead, and resolved alerts never
+// start a run. Returns the run, if any, and whether it was started.
+func (s *PlaybookRunServiceImpl) StartPlaybookRunFromAlert(playbook Playbook, alert InboundAlert) (*PlaybookRun, bool, error) {
+	if alert.Fingerprint != "" {
+		// Monitoring systems often send the same alert to several receivers at once, possibly
+		// handled by different servers: serialize the alerts across the cluster so that they are
+		// not all taken for new ones.
+		unlock, err := lockClusterMutex(s.pluginAPI, "alert", playbook.ID, alert.Fingerprint)
+		if err != nil {
+			return nil, false, err
+		}
+		defer unlock()
+
+		playbookRunID, err := s.store.GetActivePlaybookRunIDForAlert(playbook.ID, alert.Fingerprint)
+		if err == nil {
+			return s.addAlertToPlaybookRun(playbookRunID, alert)
+		} else if !errors.Is(err, ErrNotFound) {
+			return nil, false, errors.Wrapf(err, "failed to find the active playbook run of the alert")
+		}
+	}
+
+	if alert.Resolved {
+		return nil, false, nil
+	}
+
+	if !playbook.DefaultOwnerEnabled || playbook.DefaultOwnerID == "" {
+		return nil, false, errors.Wrap(ErrMalformedPlaybookRun, "the playbook has no default owner for the runs started by alerts")
+	}
+
+	playbookRun := PlaybookRun{
+		Name:             alert.Name,
+		OwnerUserID:      playbook.DefaultOwnerID,
+		TeamID:           playbook.TeamID,
+		PlaybookID:       playbook.ID,
+		AlertFingerprint: alert.Fingerprint,
+	}
+	ApplyPlaybookDefaults(&playbookRun, playbook)
+
+	if playbookRun.Name == "" {
+		playbookRun.Name = playbook.Title
+	}
+	if alert.Description != "" {
+		playbookRun.Description = alert.Description
+	}
+	if level := FindSeverityLevel(playbookRun.SeverityLevels, alert.Severity); level != "" {
+		playbookRun.Severity = level
+	}
+
+	createdPlaybookRun, err := s.CreatePlaybookRun(&playbookRun, &playbook, s.configService.GetConfiguration().BotUserID, playbook.CreatePublicPlaybookRun)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Alerts cannot fill in custom fields: rather than dropping the alert, flag the run so that
+	// its participants fill in the required ones.
+	if field, missing := MissingRequiredCustomField(createdPlaybookRun.CustomFields, createdPlaybookRun.CustomFieldValues); missing {
+		if _, err = s.poster.PostMessage(createdPlaybookRun.ChannelID, "This run was started by an alert, so its required field **%s** has no value yet. Please fill it in.", field.Name); err != nil {
+			s.logger.Warnf("failed to flag the missing required fields of playbook run %s: %s", createdPlaybookRun.ID, err.Error())
+		}
+	}
+
+	return createdPlaybookRun, true, nil
+}
+
+// addAlertToPlaybookRun lets the channel of the playbook run started by an alert know that the
+// alert fired again or was resolved.
+func (s *PlaybookRunServiceImpl) addAlertToPlaybookRun(playbookRunID string, alert InboundAlert) (*PlaybookRun, bool, error) {
+	playbookRun, err := s.store.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to retrieve playbook run")
+	}
+
+	message := "The alert that started this run fired again."
+	if alert.Resolved {
+		message = "The alert that started this run has been resolved."
+	}
+	if _, err = s.poster.PostMessage(playbookRun.ChannelID, message); err != nil {
+		s.logger.Warnf("failed to post the repeated alert to playbook run %s: %s", playbookRun.ID, err.Error())
+	}
+
+	return playbookRun, false, nil
+}
+
+// InboundStatusUpdate is a status update posted to a run through its playbook's inbound webhook.
+type InboundStatusUpdate struct {
+	EventID     string `json:"event_id"` // Identifies the retried deliveries of the same update
+	Message     string `json:"message"`
+	Description string `json:"description"` // Defaults to the run's description
+	Status      string `json:"status"`      // Defaults to the run's status
+	Resolve     bool   `json:"resolve"`     // Moves the run to the first resolved status it can reach
+}
+
+// Validate trims the status update and checks that it has an event ID and a message, and does not
+// both set the status and resolve the run.
+func (u *InboundStatusUpdate) Validate() error {
+	u.EventID = strings.TrimSpace(u.EventID)
+	u.Message = strings.TrimSpace(u.Message)
+	u.Description = strings.TrimSpace(u.Description)
+	u.Status = strings.TrimSpace(u.Status)
+
+	if u.EventID == "" {
+		return errors.New("event_id must not be empty")
+	}
+	if len(u.EventID) > MaxInboundEventIDLength {
+		return errors.Errorf("event_id must be at most %d characters", MaxInboundEventIDLength)
+	}
+	if u.Message == "" {
+		return errors.New("message must not be empty")
+	}
+	if u.Status != "" && u.Resolve {
+		return errors.New("status and resolve must not both be set")
+	}
+
+	return nil
+}
+
+// resolvedStatus returns the status a run in status from moves to when resolved by an inbound
+// status update: from itself if already resolved, else the first resolved status it can move to,
+// preferring those that do not close the run.
+func resolvedStatus(workflow StatusWorkflow, from string) (string, bool) {
+	if workflow.IsResolved(from) {
+		return from, true
+	}
+
+	closed := ""
+	for _, status := range workflow.NextStatuses(from) {
+		if !workflow.IsResolved(status) {
+			continue
+		}
+		if !workflow.IsClosed(status) {
+			return status, true
+		}
+		if closed == "" {
+			closed = status
+		}
+	}
+
+	return closed, closed != ""
+}
+
+// UpdateStatusFromWebhook posts a status update received by the inbound webhook of the run's
+// playbook, as the webhook's service user or the bot. Updates whose event ID was already applied
+// to the run are ignored before being validated, so that retried deliveries are not posted twice
+// nor rejected once the run moved on. Returns whether the update was applied.
+func (s *PlaybookRunServiceImpl) UpdateStatusFromWebhook(playbookRunID string, webhook InboundWebhook, update InboundStatusUpdate) (bool, error) {
+	playbookRun, err := s.store.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to retrieve playbook run")
+	}
+
+	if err = s.store.CreateInboundWebhookEvent(playbookRunID, update.EventID); errors.Is(err, ErrDuplicateEntry) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrapf(err, "failed to record the inbound status update")
+	}
+
+	// Forgets the event of an update that was not applied, so that the retried delivery is.
+	forgetEvent := func() {
+		if err2 := s.store.DeleteInboundWebhookEvent(playbookRunID, update.EventID); err2 != nil {
+			s.logger.Warnf("failed to delete inbound status update %s of playbook run %s: %s", update.EventID, playbookRunID, err2.Error())
+		}
+	}
+
+	options, err := inboundStatusUpdateOptions(playbookRun, update)
+	if err != nil {
+		forgetEvent()
+		return false, err
+	}
+
+	userID := webhook.ServiceUserID
+	if userID == "" {
+		userID = s.configService.GetConfiguration().BotUserID
+	}
+
+	if err = s.UpdateStatus(playbookRunID, userID, options); err != nil {
+		forgetEvent()
+		return false, err
+	}
+
+	return true, nil
+}
+
+// inboundStatusUpdateOptions returns the status update of the run described by the inbound status
+// update, checking that the run can move to its status.
+func inboundStatusUpdateOptions(playbookRun *PlaybookRun, update InboundStatusUpdate) (StatusUpdateOptions, error) {
+	workflow := playbookRun.Workflow()
+	options := StatusUpdateOptions{
+		Status:      playbookRun.CurrentStatus,
+		Description: playbookRun.Description,
+		Message:     update.Message,
+		Reminder:    playbookRun.PreviousReminder,
+	}
+	if update.Description != "" {
+		options.Description = update.Description
+	}
+	if update.Status != "" {
+		options.Status = update.Status
+	} else if update.Resolve {
+		status, ok := resolvedStatus(workflow, playbookRun.CurrentStatus)
+		if !ok {
+			return options, errors.Wrapf(ErrMalformedPlaybookRun, "no resolved status can be reached from '%s'", playbookRun.CurrentStatus)
+		}
+		options.Status = status
+	}
+	if !workflow.CanTransition(playbookRun.CurrentStatus, options.Status) {
+		return options, errors.Wrapf(ErrMalformedPlaybookRun, "cannot change status from '%s' to '%s'", playbookRun.CurrentStatus, options.Status)
+	}
+	if workflow.IsResolved(options.Status) {
+		// Nobody is left to answer the reminders of the runs resolved by monitoring systems.
+		options.Reminder = 0
+	}
+
+	return options, nil
+}
@@ -0,0 +1,269 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaybook_ValidateInboundWebhook(t *testing.T) {
+	withOwner := func(webhook InboundWebhook) Playbook {
+		return Playbook{DefaultOwnerEnabled: true, DefaultOwnerID: model.NewId(), InboundWebhook: webhook}
+	}
+
+	for name, tc := range map[string]struct {
+		playbook    Playbook
+		expectedErr string
+	}{
+		"disabled": {},
+		"enabled generic": {
+			playbook: withOwner(InboundWebhook{Enabled: true}),
+		},
+		"enabled alertmanager with fields and token": {
+			playbook: withOwner(InboundWebhook{
+				Enabled:          true,
+				Token:            strings.Repeat("a", MinInboundWebhookTokenLength),
+				Format:           InboundWebhookFormatAlertmanager,
+				NameField:        "alerts.0.annotations.summary",
+				FingerprintField: "groupKey",
+			}),
+		},
+		"unknown format": {
+			playbook:    withOwner(InboundWebhook{Format: "pagerduty"}),
+			expectedErr: "unknown inbound webhook format",
+		},
+		"field with empty part": {
+			playbook:    withOwner(InboundWebhook{NameField: "labels..alertname"}),
+			expectedErr: "must not have empty parts",
+		},
+		"field too long": {
+			playbook:    withOwner(InboundWebhook{SeverityField: strings.Repeat("a", MaxInboundWebhookFieldLength+1)}),
+			expectedErr: "at most",
+		},
+		"token too short": {
+			playbook:    withOwner(InboundWebhook{Enabled: true, Token: "short"}),
+			expectedErr: "must be between",
+		},
+		"token with whitespace": {
+			playbook:    withOwner(InboundWebhook{Enabled: true, Token: strings.Repeat("a", 10) + " " + strings.Repeat("a", 10)}),
+			expectedErr: "must not contain whitespace",
+		},
+		"enabled without default owner": {
+			playbook:    Playbook{InboundWebhook: InboundWebhook{Enabled: true}},
+			expectedErr: "requires a default owner",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.playbook.ValidateInboundWebhook()
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestAssignInboundWebhookToken(t *testing.T) {
+	t.Run("generates a token when enabled without one", func(t *testing.T) {
+		playbook := Playbook{InboundWebhook: InboundWebhook{Enabled: true}}
+		AssignInboundWebhookToken(&playbook)
+		require.Len(t, playbook.InboundWebhook.Token, 52)
+		require.NoError(t, Playbook{DefaultOwnerEnabled: true, DefaultOwnerID: model.NewId(), InboundWebhook: playbook.InboundWebhook}.ValidateInboundWebhook())
+	})
+
+	t.Run("keeps an existing token", func(t *testing.T) {
+		playbook := Playbook{InboundWebhook: InboundWebhook{Enabled: true, Token: "existing-token-1234"}}
+		AssignInboundWebhookToken(&playbook)
+		require.Equal(t, "existing-token-1234", playbook.InboundWebhook.Token)
+	})
+
+	t.Run("does not generate a token when disabled", func(t *testing.T) {
+		playbook := Playbook{}
+		AssignInboundWebhookToken(&playbook)
+		require.Empty(t, playbook.InboundWebhook.Token)
+	})
+}
+
+func TestParseInboundAlert(t *testing.T) {
+	alertmanagerBody := `{
+		"status": "firing",
+		"groupKey": "{}:{alertname=\"HighErrorRate\"}",
+		"commonLabels": {"alertname": "HighErrorRate", "severity": "critical"},
+		"commonAnnotations": {"description": "Error rate above 5%"},
+		"alerts": [{"annotations": {"summary": "Checkout is failing"}, "labels": {"priority": 1}}]
+	}`
+
+	for name, tc := range map[string]struct {
+		webhook     InboundWebhook
+		body        string
+		expected    InboundAlert
+		expectedErr string
+	}{
+		"generic": {
+			body:     `{"title": " Database down ", "description": "Primary unreachable", "severity": "High", "fingerprint": "db-1"}`,
+			expected: InboundAlert{Name: "Database down", Description: "Primary unreachable", Severity: "High", Fingerprint: "db-1"},
+		},
+		"generic with missing and non-string values": {
+			body:     `{"title": "Database down", "severity": 2, "fingerprint": {"id": "db-1"}}`,
+			expected: InboundAlert{Name: "Database down", Severity: "2"},
+		},
+		"alertmanager": {
+			webhook: InboundWebhook{Format: InboundWebhookFormatAlertmanager},
+			body:    alertmanagerBody,
+			expected: InboundAlert{
+				Name:        "HighErrorRate",
+				Description: "Error rate above 5%",
+				Severity:    "critical",
+				Fingerprint: `{}:{alertname="HighErrorRate"}`,
+			},
+		},
+		"alertmanager resolved": {
+			webhook:  InboundWebhook{Format: InboundWebhookFormatAlertmanager},
+			body:     `{"status": "resolved", "groupKey": "group", "commonLabels": {"alertname": "HighErrorRate"}}`,
+			expected: InboundAlert{Name: "HighErrorRate", Fingerprint: "group", Resolved: true},
+		},
+		"generic status is not a resolution": {
+			body:     `{"title": "Database down", "status": "resolved"}`,
+			expected: InboundAlert{Name: "Database down"},
+		},
+		"overridden fields with array indexes": {
+			webhook: InboundWebhook{
+				Format:        InboundWebhookFormatAlertmanager,
+				NameField:     "alerts.0.annotations.summary",
+				SeverityField: "alerts.0.labels.priority",
+			},
+			body: alertmanagerBody,
+			expected: InboundAlert{
+				Name:        "Checkout is failing",
+				Description: "Error rate above 5%",
+				Severity:    "1",
+				Fingerprint: `{}:{alertname="HighErrorRate"}`,
+			},
+		},
+		"out of range array index": {
+			webhook:  InboundWebhook{NameField: "alerts.3.name"},
+			body:     `{"alerts": [{"name": "first"}]}`,
+			expected: InboundAlert{},
+		},
+		"long name is truncated": {
+			body:     `{"title": "` + strings.Repeat("é", model.CHANNEL_DISPLAY_NAME_MAX_RUNES+10) + `"}`,
+			expected: InboundAlert{Name: strings.Repeat("é", model.CHANNEL_DISPLAY_NAME_MAX_RUNES)},
+		},
+		"long fingerprint is hashed": {
+			body:     `{"fingerprint": "` + strings.Repeat("a", MaxAlertFingerprintLength+1) + `"}`,
+			expected: InboundAlert{Fingerprint: "e8d95cc2b4bc198c54b40bd214df958afb65f5e73d2c2eafe0593cf5c635c1f0"},
+		},
+		"not an object": {
+			body:        `["title"]`,
+			expectedErr: "alert must be a JSON object",
+		},
+		"invalid json": {
+			body:        `{"title":`,
+			expectedErr: "alert must be a JSON object",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			alert, err := ParseInboundAlert(tc.webhook, []byte(tc.body))
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, alert)
+		})
+	}
+}
+
+func TestInboundStatusUpdate_Validate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		update      InboundStatusUpdate
+		expected    InboundStatusUpdate
+		expectedErr string
+	}{
+		"trimmed": {
+			update:   InboundStatusUpdate{EventID: " event ", Message: " cleared ", Status: " Active "},
+			expected: InboundStatusUpdate{EventID: "event", Message: "cleared", Status: "Active"},
+		},
+		"resolve": {
+			update:   InboundStatusUpdate{EventID: "event", Message: "cleared", Resolve: true},
+			expected: InboundStatusUpdate{EventID: "event", Message: "cleared", Resolve: true},
+		},
+		"missing event id": {
+			update:      InboundStatusUpdate{Message: "cleared"},
+			expectedErr: "event_id must not be empty",
+		},
+		"event id too long": {
+			update:      InboundStatusUpdate{EventID: strings.Repeat("a", MaxInboundEventIDLength+1), Message: "cleared"},
+			expectedErr: "at most",
+		},
+		"missing message": {
+			update:      InboundStatusUpdate{EventID: "event", Message: " "},
+			expectedErr: "message must not be empty",
+		},
+		"status and resolve": {
+			update:      InboundStatusUpdate{EventID: "event", Message: "cleared", Status: "Active", Resolve: true},
+			expectedErr: "must not both be set",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.update.Validate()
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, tc.update)
+		})
+	}
+}
+
+func TestResolvedStatus(t *testing.T) {
+	custom := StatusWorkflow{States: []StatusState{
+		{Name: "Triage", Transitions: []string{"Mitigated", "Closed"}},
+		{Name: "Mitigated", Transitions: []string{"Triage"}},
+		{Name: "Fixed", Transitions: []string{"Closed"}, Resolved: true},
+		{Name: "Closed", Closed: true},
+	}}
+
+	for name, tc := range map[string]struct {
+		workflow       StatusWorkflow
+		from           string
+		expectedStatus string
+		expectedOK     bool
+	}{
+		"default workflow prefers resolved to archived": {
+			workflow:       DefaultStatusWorkflow(),
+			from:           StatusActive,
+			expectedStatus: StatusResolved,
+			expectedOK:     true,
+		},
+		"already resolved": {
+			workflow:       DefaultStatusWorkflow(),
+			from:           StatusArchived,
+			expectedStatus: StatusArchived,
+			expectedOK:     true,
+		},
+		"only a closed status is reachable": {
+			workflow:       custom,
+			from:           "Triage",
+			expectedStatus: "Closed",
+			expectedOK:     true,
+		},
+		"no resolved status is reachable": {
+			workflow: custom,
+			from:     "Mitigated",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			status, ok := resolvedStatus(tc.workflow, tc.from)
+			require.Equal(t, tc.expectedOK, ok)
+			require.Equal(t, tc.expectedStatus, status)
+		})
+	}
+}
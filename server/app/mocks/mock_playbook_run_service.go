@@ -49,6 +49,35 @@ func (mr *MockPlaybookRunServiceMockRecorder) AddChecklistItem(arg0, arg1, arg2,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddChecklistItem", reflect.TypeOf((*MockPlaybookRunService)(nil).AddChecklistItem), arg0, arg1, arg2, arg3)
 }
 
+// AddChecklistItemComment mocks base method
+func (m *MockPlaybookRunService) AddChecklistItemComment(arg0, arg1 string, arg2, arg3 int, arg4 string) (app.ChecklistItemComment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddChecklistItemComment", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(app.ChecklistItemComment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddChecklistItemComment indicates an expected call of AddChecklistItemComment
+func (mr *MockPlaybookRunServiceMockRecorder) AddChecklistItemComment(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddChecklistItemComment", reflect.TypeOf((*MockPlaybookRunService)(nil).AddChecklistItemComment), arg0, arg1, arg2, arg3, arg4)
+}
+
+// AddChecklistSubItem mocks base method
+func (m *MockPlaybookRunService) AddChecklistSubItem(arg0, arg1 string, arg2, arg3 int, arg4 app.ChecklistSubItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddChecklistSubItem", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddChecklistSubItem indicates an expected call of AddChecklistSubItem
+func (mr *MockPlaybookRunServiceMockRecorder) AddChecklistSubItem(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddChecklistSubItem", reflect.TypeOf((*MockPlaybookRunService)(nil).AddChecklistSubItem), arg0, arg1, arg2, arg3, arg4)
+}
+
 // AddPostToTimeline mocks base method
 func (m *MockPlaybookRunService) AddPostToTimeline(arg0, arg1, arg2, arg3 string) error {
 	m.ctrl.T.Helper()
@@ -63,6 +92,20 @@ func (mr *MockPlaybookRunServiceMockRecorder) AddPostToTimeline(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPostToTimeline", reflect.TypeOf((*MockPlaybookRunService)(nil).AddPostToTimeline), arg0, arg1, arg2, arg3)
 }
 
+// ApplyChecklistOperations mocks base method
+func (m *MockPlaybookRunService) ApplyChecklistOperations(arg0, arg1 string, arg2 []app.ChecklistOperation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyChecklistOperations", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyChecklistOperations indicates an expected call of ApplyChecklistOperations
+func (mr *MockPlaybookRunServiceMockRecorder) ApplyChecklistOperations(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyChecklistOperations", reflect.TypeOf((*MockPlaybookRunService)(nil).ApplyChecklistOperations), arg0, arg1, arg2)
+}
+
 // CancelRetrospective mocks base method
 func (m *MockPlaybookRunService) CancelRetrospective(arg0, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -105,6 +148,20 @@ func (mr *MockPlaybookRunServiceMockRecorder) ChangeOwner(arg0, arg1, arg2 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeOwner", reflect.TypeOf((*MockPlaybookRunService)(nil).ChangeOwner), arg0, arg1, arg2)
 }
 
+// ChangeSeverity mocks base method
+func (m *MockPlaybookRunService) ChangeSeverity(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeSeverity", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeSeverity indicates an expected call of ChangeSeverity
+func (mr *MockPlaybookRunServiceMockRecorder) ChangeSeverity(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeSeverity", reflect.TypeOf((*MockPlaybookRunService)(nil).ChangeSeverity), arg0, arg1, arg2)
+}
+
 // CheckAndSendMessageOnJoin mocks base method
 func (m *MockPlaybookRunService) CheckAndSendMessageOnJoin(arg0, arg1, arg2 string) bool {
 	m.ctrl.T.Helper()
@@ -178,6 +235,21 @@ func (mr *MockPlaybookRunServiceMockRecorder) GetChecklistItemAutocomplete(arg0
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChecklistItemAutocomplete", reflect.TypeOf((*MockPlaybookRunService)(nil).GetChecklistItemAutocomplete), arg0)
 }
 
+// GetChecklistItems mocks base method
+func (m *MockPlaybookRunService) GetChecklistItems(arg0 app.RequesterInfo, arg1 app.ChecklistItemFilterOptions) ([]app.PlaybookRunChecklistItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChecklistItems", arg0, arg1)
+	ret0, _ := ret[0].([]app.PlaybookRunChecklistItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChecklistItems indicates an expected call of GetChecklistItems
+func (mr *MockPlaybookRunServiceMockRecorder) GetChecklistItems(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChecklistItems", reflect.TypeOf((*MockPlaybookRunService)(nil).GetChecklistItems), arg0, arg1)
+}
+
 // GetOwners mocks base method
 func (m *MockPlaybookRunService) GetOwners(arg0 app.RequesterInfo, arg1 app.PlaybookRunFilterOptions) ([]app.OwnerInfo, error) {
 	m.ctrl.T.Helper()
@@ -253,6 +325,21 @@ func (mr *MockPlaybookRunServiceMockRecorder) GetPlaybookRuns(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaybookRuns", reflect.TypeOf((*MockPlaybookRunService)(nil).GetPlaybookRuns), arg0, arg1)
 }
 
+// GetWebhookDeliveries mocks base method
+func (m *MockPlaybookRunService) GetWebhookDeliveries(arg0 string, arg1 app.WebhookDeliveryStatus) ([]app.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]app.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries
+func (mr *MockPlaybookRunServiceMockRecorder) GetWebhookDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockPlaybookRunService)(nil).GetWebhookDeliveries), arg0, arg1)
+}
+
 // HandleReminder mocks base method
 func (m *MockPlaybookRunService) HandleReminder(arg0 string) {
 	m.ctrl.T.Helper()
@@ -280,17 +367,31 @@ func (mr *MockPlaybookRunServiceMockRecorder) IsOwner(arg0, arg1 interface{}) *g
 }
 
 // ModifyCheckedState mocks base method
-func (m *MockPlaybookRunService) ModifyCheckedState(arg0, arg1, arg2 string, arg3, arg4 int) error {
+func (m *MockPlaybookRunService) ModifyCheckedState(arg0, arg1, arg2 string, arg3, arg4 int, arg5 bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ModifyCheckedState", arg0, arg1, arg2, arg3, arg4)
+	ret := m.ctrl.Call(m, "ModifyCheckedState", arg0, arg1, arg2, arg3, arg4, arg5)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ModifyCheckedState indicates an expected call of ModifyCheckedState
-func (mr *MockPlaybookRunServiceMockRecorder) ModifyCheckedState(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+func (mr *MockPlaybookRunServiceMockRecorder) ModifyCheckedState(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifyCheckedState", reflect.TypeOf((*MockPlaybookRunService)(nil).ModifyCheckedState), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// ModifyChecklistSubItemState mocks base method
+func (m *MockPlaybookRunService) ModifyChecklistSubItemState(arg0, arg1, arg2 string, arg3, arg4, arg5 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ModifyChecklistSubItemState", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ModifyChecklistSubItemState indicates an expected call of ModifyChecklistSubItemState
+func (mr *MockPlaybookRunServiceMockRecorder) ModifyChecklistSubItemState(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifyCheckedState", reflect.TypeOf((*MockPlaybookRunService)(nil).ModifyCheckedState), arg0, arg1, arg2, arg3, arg4)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifyChecklistSubItemState", reflect.TypeOf((*MockPlaybookRunService)(nil).ModifyChecklistSubItemState), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
 // MoveChecklistItem mocks base method
@@ -391,6 +492,21 @@ func (mr *MockPlaybookRunServiceMockRecorder) PublishRetrospective(arg0, arg1, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishRetrospective", reflect.TypeOf((*MockPlaybookRunService)(nil).PublishRetrospective), arg0, arg1, arg2)
 }
 
+// RedeliverWebhook mocks base method
+func (m *MockPlaybookRunService) RedeliverWebhook(arg0, arg1 string) (*app.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeliverWebhook", arg0, arg1)
+	ret0, _ := ret[0].(*app.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeliverWebhook indicates an expected call of RedeliverWebhook
+func (mr *MockPlaybookRunServiceMockRecorder) RedeliverWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeliverWebhook", reflect.TypeOf((*MockPlaybookRunService)(nil).RedeliverWebhook), arg0, arg1)
+}
+
 // RemoveChecklistItem mocks base method
 func (m *MockPlaybookRunService) RemoveChecklistItem(arg0, arg1 string, arg2, arg3 int) error {
 	m.ctrl.T.Helper()
@@ -405,6 +521,20 @@ func (mr *MockPlaybookRunServiceMockRecorder) RemoveChecklistItem(arg0, arg1, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveChecklistItem", reflect.TypeOf((*MockPlaybookRunService)(nil).RemoveChecklistItem), arg0, arg1, arg2, arg3)
 }
 
+// RemoveChecklistSubItem mocks base method
+func (m *MockPlaybookRunService) RemoveChecklistSubItem(arg0, arg1 string, arg2, arg3, arg4 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveChecklistSubItem", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveChecklistSubItem indicates an expected call of RemoveChecklistSubItem
+func (mr *MockPlaybookRunServiceMockRecorder) RemoveChecklistSubItem(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveChecklistSubItem", reflect.TypeOf((*MockPlaybookRunService)(nil).RemoveChecklistSubItem), arg0, arg1, arg2, arg3, arg4)
+}
+
 // RemoveReminder mocks base method
 func (m *MockPlaybookRunService) RemoveReminder(arg0 string) {
 	m.ctrl.T.Helper()
@@ -474,6 +604,62 @@ func (mr *MockPlaybookRunServiceMockRecorder) SetAssignee(arg0, arg1, arg2, arg3
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAssignee", reflect.TypeOf((*MockPlaybookRunService)(nil).SetAssignee), arg0, arg1, arg2, arg3, arg4)
 }
 
+// SetChecklistItemDependencies mocks base method
+func (m *MockPlaybookRunService) SetChecklistItemDependencies(arg0, arg1 string, arg2, arg3 int, arg4 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetChecklistItemDependencies", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetChecklistItemDependencies indicates an expected call of SetChecklistItemDependencies
+func (mr *MockPlaybookRunServiceMockRecorder) SetChecklistItemDependencies(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetChecklistItemDependencies", reflect.TypeOf((*MockPlaybookRunService)(nil).SetChecklistItemDependencies), arg0, arg1, arg2, arg3, arg4)
+}
+
+// SetChecklistItemDueDate mocks base method
+func (m *MockPlaybookRunService) SetChecklistItemDueDate(arg0, arg1 string, arg2, arg3 int, arg4 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetChecklistItemDueDate", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetChecklistItemDueDate indicates an expected call of SetChecklistItemDueDate
+func (mr *MockPlaybookRunServiceMockRecorder) SetChecklistItemDueDate(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetChecklistItemDueDate", reflect.TypeOf((*MockPlaybookRunService)(nil).SetChecklistItemDueDate), arg0, arg1, arg2, arg3, arg4)
+}
+
+// SetChecklistSubItemAssignee mocks base method
+func (m *MockPlaybookRunService) SetChecklistSubItemAssignee(arg0, arg1, arg2 string, arg3, arg4, arg5 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetChecklistSubItemAssignee", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetChecklistSubItemAssignee indicates an expected call of SetChecklistSubItemAssignee
+func (mr *MockPlaybookRunServiceMockRecorder) SetChecklistSubItemAssignee(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetChecklistSubItemAssignee", reflect.TypeOf((*MockPlaybookRunService)(nil).SetChecklistSubItemAssignee), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// SetDigestEnabled mocks base method
+func (m *MockPlaybookRunService) SetDigestEnabled(arg0 string, arg1 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDigestEnabled", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDigestEnabled indicates an expected call of SetDigestEnabled
+func (mr *MockPlaybookRunServiceMockRecorder) SetDigestEnabled(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDigestEnabled", reflect.TypeOf((*MockPlaybookRunService)(nil).SetDigestEnabled), arg0, arg1)
+}
+
 // SetReminder mocks base method
 func (m *MockPlaybookRunService) SetReminder(arg0 string, arg1 time.Duration) error {
 	m.ctrl.T.Helper()
@@ -488,6 +674,36 @@ func (mr *MockPlaybookRunServiceMockRecorder) SetReminder(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReminder", reflect.TypeOf((*MockPlaybookRunService)(nil).SetReminder), arg0, arg1)
 }
 
+// SkipChecklistItem mocks base method
+func (m *MockPlaybookRunService) SkipChecklistItem(arg0, arg1 string, arg2, arg3 int, arg4 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SkipChecklistItem", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SkipChecklistItem indicates an expected call of SkipChecklistItem
+func (mr *MockPlaybookRunServiceMockRecorder) SkipChecklistItem(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SkipChecklistItem", reflect.TypeOf((*MockPlaybookRunService)(nil).SkipChecklistItem), arg0, arg1, arg2, arg3, arg4)
+}
+
+// StartPlaybookRunFromAlert mocks base method
+func (m *MockPlaybookRunService) StartPlaybookRunFromAlert(arg0 app.Playbook, arg1 app.InboundAlert) (*app.PlaybookRun, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartPlaybookRunFromAlert", arg0, arg1)
+	ret0, _ := ret[0].(*app.PlaybookRun)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// StartPlaybookRunFromAlert indicates an expected call of StartPlaybookRunFromAlert
+func (mr *MockPlaybookRunServiceMockRecorder) StartPlaybookRunFromAlert(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartPlaybookRunFromAlert", reflect.TypeOf((*MockPlaybookRunService)(nil).StartPlaybookRunFromAlert), arg0, arg1)
+}
+
 // ToggleCheckedState mocks base method
 func (m *MockPlaybookRunService) ToggleCheckedState(arg0, arg1 string, arg2, arg3 int) error {
 	m.ctrl.T.Helper()
@@ -502,6 +718,21 @@ func (mr *MockPlaybookRunServiceMockRecorder) ToggleCheckedState(arg0, arg1, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToggleCheckedState", reflect.TypeOf((*MockPlaybookRunService)(nil).ToggleCheckedState), arg0, arg1, arg2, arg3)
 }
 
+// UpdatePlaybookRun mocks base method
+func (m *MockPlaybookRunService) UpdatePlaybookRun(arg0, arg1 string, arg2 app.UpdateOptions) (*app.PlaybookRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePlaybookRun", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*app.PlaybookRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePlaybookRun indicates an expected call of UpdatePlaybookRun
+func (mr *MockPlaybookRunServiceMockRecorder) UpdatePlaybookRun(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePlaybookRun", reflect.TypeOf((*MockPlaybookRunService)(nil).UpdatePlaybookRun), arg0, arg1, arg2)
+}
+
 // UpdateRetrospective mocks base method
 func (m *MockPlaybookRunService) UpdateRetrospective(arg0, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -530,6 +761,21 @@ func (mr *MockPlaybookRunServiceMockRecorder) UpdateStatus(arg0, arg1, arg2 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockPlaybookRunService)(nil).UpdateStatus), arg0, arg1, arg2)
 }
 
+// UpdateStatusFromWebhook mocks base method
+func (m *MockPlaybookRunService) UpdateStatusFromWebhook(arg0 string, arg1 app.InboundWebhook, arg2 app.InboundStatusUpdate) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatusFromWebhook", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatusFromWebhook indicates an expected call of UpdateStatusFromWebhook
+func (mr *MockPlaybookRunServiceMockRecorder) UpdateStatusFromWebhook(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusFromWebhook", reflect.TypeOf((*MockPlaybookRunService)(nil).UpdateStatusFromWebhook), arg0, arg1, arg2)
+}
+
 // UserHasJoinedChannel mocks base method
 func (m *MockPlaybookRunService) UserHasJoinedChannel(arg0, arg1, arg2 string) {
 	m.ctrl.T.Helper()
@@ -553,3 +799,17 @@ func (mr *MockPlaybookRunServiceMockRecorder) UserHasLeftChannel(arg0, arg1, arg
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserHasLeftChannel", reflect.TypeOf((*MockPlaybookRunService)(nil).UserHasLeftChannel), arg0, arg1, arg2)
 }
+
+// WithExpectedVersion mocks base method
+func (m *MockPlaybookRunService) WithExpectedVersion(arg0 int64) app.PlaybookRunService {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithExpectedVersion", arg0)
+	ret0, _ := ret[0].(app.PlaybookRunService)
+	return ret0
+}
+
+// WithExpectedVersion indicates an expected call of WithExpectedVersion
+func (mr *MockPlaybookRunServiceMockRecorder) WithExpectedVersion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithExpectedVersion", reflect.TypeOf((*MockPlaybookRunService)(nil).WithExpectedVersion), arg0)
+}
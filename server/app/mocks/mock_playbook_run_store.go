@@ -48,6 +48,20 @@ func (mr *MockPlaybookRunStoreMockRecorder) ChangeCreationDate(arg0, arg1 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeCreationDate", reflect.TypeOf((*MockPlaybookRunStore)(nil).ChangeCreationDate), arg0, arg1)
 }
 
+// CreateInboundWebhookEvent mocks base method
+func (m *MockPlaybookRunStore) CreateInboundWebhookEvent(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInboundWebhookEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateInboundWebhookEvent indicates an expected call of CreateInboundWebhookEvent
+func (mr *MockPlaybookRunStoreMockRecorder) CreateInboundWebhookEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInboundWebhookEvent", reflect.TypeOf((*MockPlaybookRunStore)(nil).CreateInboundWebhookEvent), arg0, arg1)
+}
+
 // CreatePlaybookRun mocks base method
 func (m *MockPlaybookRunStore) CreatePlaybookRun(arg0 *app.PlaybookRun) (*app.PlaybookRun, error) {
 	m.ctrl.T.Helper()
@@ -78,6 +92,50 @@ func (mr *MockPlaybookRunStoreMockRecorder) CreateTimelineEvent(arg0 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTimelineEvent", reflect.TypeOf((*MockPlaybookRunStore)(nil).CreateTimelineEvent), arg0)
 }
 
+// CreateWebhookDelivery mocks base method
+func (m *MockPlaybookRunStore) CreateWebhookDelivery(arg0 *app.WebhookDelivery) (*app.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDelivery", arg0)
+	ret0, _ := ret[0].(*app.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookDelivery indicates an expected call of CreateWebhookDelivery
+func (mr *MockPlaybookRunStoreMockRecorder) CreateWebhookDelivery(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDelivery", reflect.TypeOf((*MockPlaybookRunStore)(nil).CreateWebhookDelivery), arg0)
+}
+
+// DeleteInboundWebhookEvent mocks base method
+func (m *MockPlaybookRunStore) DeleteInboundWebhookEvent(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteInboundWebhookEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteInboundWebhookEvent indicates an expected call of DeleteInboundWebhookEvent
+func (mr *MockPlaybookRunStoreMockRecorder) DeleteInboundWebhookEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteInboundWebhookEvent", reflect.TypeOf((*MockPlaybookRunStore)(nil).DeleteInboundWebhookEvent), arg0, arg1)
+}
+
+// GetActivePlaybookRunIDForAlert mocks base method
+func (m *MockPlaybookRunStore) GetActivePlaybookRunIDForAlert(arg0, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActivePlaybookRunIDForAlert", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActivePlaybookRunIDForAlert indicates an expected call of GetActivePlaybookRunIDForAlert
+func (mr *MockPlaybookRunStoreMockRecorder) GetActivePlaybookRunIDForAlert(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActivePlaybookRunIDForAlert", reflect.TypeOf((*MockPlaybookRunStore)(nil).GetActivePlaybookRunIDForAlert), arg0, arg1)
+}
+
 // GetAllPlaybookRunMembersCount mocks base method
 func (m *MockPlaybookRunStore) GetAllPlaybookRunMembersCount(arg0 string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -93,6 +151,36 @@ func (mr *MockPlaybookRunStoreMockRecorder) GetAllPlaybookRunMembersCount(arg0 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPlaybookRunMembersCount", reflect.TypeOf((*MockPlaybookRunStore)(nil).GetAllPlaybookRunMembersCount), arg0)
 }
 
+// GetChecklistItems mocks base method
+func (m *MockPlaybookRunStore) GetChecklistItems(arg0 app.RequesterInfo, arg1 app.ChecklistItemFilterOptions) ([]app.PlaybookRunChecklistItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChecklistItems", arg0, arg1)
+	ret0, _ := ret[0].([]app.PlaybookRunChecklistItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChecklistItems indicates an expected call of GetChecklistItems
+func (mr *MockPlaybookRunStoreMockRecorder) GetChecklistItems(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChecklistItems", reflect.TypeOf((*MockPlaybookRunStore)(nil).GetChecklistItems), arg0, arg1)
+}
+
+// GetOverdueStatusUpdateRuns mocks base method
+func (m *MockPlaybookRunStore) GetOverdueStatusUpdateRuns(arg0 string, arg1 int64) ([]app.OverdueStatusUpdateRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverdueStatusUpdateRuns", arg0, arg1)
+	ret0, _ := ret[0].([]app.OverdueStatusUpdateRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverdueStatusUpdateRuns indicates an expected call of GetOverdueStatusUpdateRuns
+func (mr *MockPlaybookRunStoreMockRecorder) GetOverdueStatusUpdateRuns(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdueStatusUpdateRuns", reflect.TypeOf((*MockPlaybookRunStore)(nil).GetOverdueStatusUpdateRuns), arg0, arg1)
+}
+
 // GetOwners mocks base method
 func (m *MockPlaybookRunStore) GetOwners(arg0 app.RequesterInfo, arg1 app.PlaybookRunFilterOptions) ([]app.OwnerInfo, error) {
 	m.ctrl.T.Helper()
@@ -168,6 +256,51 @@ func (mr *MockPlaybookRunStoreMockRecorder) GetTimelineEvent(arg0, arg1 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimelineEvent", reflect.TypeOf((*MockPlaybookRunStore)(nil).GetTimelineEvent), arg0, arg1)
 }
 
+// GetUserInfo mocks base method
+func (m *MockPlaybookRunStore) GetUserInfo(arg0 string) (app.UserInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserInfo", arg0)
+	ret0, _ := ret[0].(app.UserInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserInfo indicates an expected call of GetUserInfo
+func (mr *MockPlaybookRunStoreMockRecorder) GetUserInfo(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserInfo", reflect.TypeOf((*MockPlaybookRunStore)(nil).GetUserInfo), arg0)
+}
+
+// GetWebhookDeliveries mocks base method
+func (m *MockPlaybookRunStore) GetWebhookDeliveries(arg0 string, arg1 app.WebhookDeliveryStatus) ([]app.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]app.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries
+func (mr *MockPlaybookRunStoreMockRecorder) GetWebhookDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockPlaybookRunStore)(nil).GetWebhookDeliveries), arg0, arg1)
+}
+
+// GetWebhookDelivery mocks base method
+func (m *MockPlaybookRunStore) GetWebhookDelivery(arg0 string) (*app.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDelivery", arg0)
+	ret0, _ := ret[0].(*app.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDelivery indicates an expected call of GetWebhookDelivery
+func (mr *MockPlaybookRunStoreMockRecorder) GetWebhookDelivery(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDelivery", reflect.TypeOf((*MockPlaybookRunStore)(nil).GetWebhookDelivery), arg0)
+}
+
 // HasViewedChannel mocks base method
 func (m *MockPlaybookRunStore) HasViewedChannel(arg0, arg1 string) bool {
 	m.ctrl.T.Helper()
@@ -210,6 +343,20 @@ func (mr *MockPlaybookRunStoreMockRecorder) SetViewedChannel(arg0, arg1 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetViewedChannel", reflect.TypeOf((*MockPlaybookRunStore)(nil).SetViewedChannel), arg0, arg1)
 }
 
+// UpdateCustomFieldValues mocks base method
+func (m *MockPlaybookRunStore) UpdateCustomFieldValues(arg0 string, arg1 app.CustomFieldValues) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCustomFieldValues", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCustomFieldValues indicates an expected call of UpdateCustomFieldValues
+func (mr *MockPlaybookRunStoreMockRecorder) UpdateCustomFieldValues(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCustomFieldValues", reflect.TypeOf((*MockPlaybookRunStore)(nil).UpdateCustomFieldValues), arg0, arg1)
+}
+
 // UpdatePlaybookRun mocks base method
 func (m *MockPlaybookRunStore) UpdatePlaybookRun(arg0 *app.PlaybookRun) error {
 	m.ctrl.T.Helper()
@@ -251,3 +398,31 @@ func (mr *MockPlaybookRunStoreMockRecorder) UpdateTimelineEvent(arg0 interface{}
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTimelineEvent", reflect.TypeOf((*MockPlaybookRunStore)(nil).UpdateTimelineEvent), arg0)
 }
+
+// UpdateWebhookDelivery mocks base method
+func (m *MockPlaybookRunStore) UpdateWebhookDelivery(arg0 *app.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDelivery", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookDelivery indicates an expected call of UpdateWebhookDelivery
+func (mr *MockPlaybookRunStoreMockRecorder) UpdateWebhookDelivery(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDelivery", reflect.TypeOf((*MockPlaybookRunStore)(nil).UpdateWebhookDelivery), arg0)
+}
+
+// UpsertUserInfo mocks base method
+func (m *MockPlaybookRunStore) UpsertUserInfo(arg0 app.UserInfo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertUserInfo", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertUserInfo indicates an expected call of UpsertUserInfo
+func (mr *MockPlaybookRunStoreMockRecorder) UpsertUserInfo(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertUserInfo", reflect.TypeOf((*MockPlaybookRunStore)(nil).UpsertUserInfo), arg0)
+}
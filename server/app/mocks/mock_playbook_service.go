@@ -63,6 +63,35 @@ func (mr *MockPlaybookServiceMockRecorder) Delete(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPlaybookService)(nil).Delete), arg0, arg1)
 }
 
+// Duplicate mocks base method
+func (m *MockPlaybookService) Duplicate(arg0 app.Playbook, arg1, arg2 string) (app.PlaybookImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Duplicate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(app.PlaybookImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Duplicate indicates an expected call of Duplicate
+func (mr *MockPlaybookServiceMockRecorder) Duplicate(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Duplicate", reflect.TypeOf((*MockPlaybookService)(nil).Duplicate), arg0, arg1, arg2)
+}
+
+// Export mocks base method
+func (m *MockPlaybookService) Export(arg0 app.Playbook) app.PlaybookExport {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", arg0)
+	ret0, _ := ret[0].(app.PlaybookExport)
+	return ret0
+}
+
+// Export indicates an expected call of Export
+func (mr *MockPlaybookServiceMockRecorder) Export(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockPlaybookService)(nil).Export), arg0)
+}
+
 // Get mocks base method
 func (m *MockPlaybookService) Get(arg0 string) (app.Playbook, error) {
 	m.ctrl.T.Helper()
@@ -123,6 +152,36 @@ func (mr *MockPlaybookServiceMockRecorder) GetPlaybooksForTeam(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaybooksForTeam", reflect.TypeOf((*MockPlaybookService)(nil).GetPlaybooksForTeam), arg0, arg1, arg2)
 }
 
+// GetRevision mocks base method
+func (m *MockPlaybookService) GetRevision(arg0 string, arg1 int64) (app.PlaybookRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", arg0, arg1)
+	ret0, _ := ret[0].(app.PlaybookRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision
+func (mr *MockPlaybookServiceMockRecorder) GetRevision(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockPlaybookService)(nil).GetRevision), arg0, arg1)
+}
+
+// GetRevisions mocks base method
+func (m *MockPlaybookService) GetRevisions(arg0 string) ([]app.PlaybookRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevisions", arg0)
+	ret0, _ := ret[0].([]app.PlaybookRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevisions indicates an expected call of GetRevisions
+func (mr *MockPlaybookServiceMockRecorder) GetRevisions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevisions", reflect.TypeOf((*MockPlaybookService)(nil).GetRevisions), arg0)
+}
+
 // GetSuggestedPlaybooks mocks base method
 func (m *MockPlaybookService) GetSuggestedPlaybooks(arg0, arg1, arg2 string) ([]*app.CachedPlaybook, []string) {
 	m.ctrl.T.Helper()
@@ -138,6 +197,21 @@ func (mr *MockPlaybookServiceMockRecorder) GetSuggestedPlaybooks(arg0, arg1, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSuggestedPlaybooks", reflect.TypeOf((*MockPlaybookService)(nil).GetSuggestedPlaybooks), arg0, arg1, arg2)
 }
 
+// Import mocks base method
+func (m *MockPlaybookService) Import(arg0 app.PlaybookExport, arg1, arg2 string) (app.PlaybookImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", arg0, arg1, arg2)
+	ret0, _ := ret[0].(app.PlaybookImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import
+func (mr *MockPlaybookServiceMockRecorder) Import(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockPlaybookService)(nil).Import), arg0, arg1, arg2)
+}
+
 // MessageHasBeenPosted mocks base method
 func (m *MockPlaybookService) MessageHasBeenPosted(arg0 string, arg1 *model.Post) {
 	m.ctrl.T.Helper()
@@ -150,6 +224,50 @@ func (mr *MockPlaybookServiceMockRecorder) MessageHasBeenPosted(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MessageHasBeenPosted", reflect.TypeOf((*MockPlaybookService)(nil).MessageHasBeenPosted), arg0, arg1)
 }
 
+// Sync mocks base method
+func (m *MockPlaybookService) Sync(arg0, arg1 string, arg2 map[string][]byte, arg3 bool) (app.PlaybookSyncResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(app.PlaybookSyncResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sync indicates an expected call of Sync
+func (mr *MockPlaybookServiceMockRecorder) Sync(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockPlaybookService)(nil).Sync), arg0, arg1, arg2, arg3)
+}
+
+// SyncFromDirectory mocks base method
+func (m *MockPlaybookService) SyncFromDirectory(arg0, arg1 string, arg2 bool) (app.PlaybookSyncResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncFromDirectory", arg0, arg1, arg2)
+	ret0, _ := ret[0].(app.PlaybookSyncResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncFromDirectory indicates an expected call of SyncFromDirectory
+func (mr *MockPlaybookServiceMockRecorder) SyncFromDirectory(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncFromDirectory", reflect.TypeOf((*MockPlaybookService)(nil).SyncFromDirectory), arg0, arg1, arg2)
+}
+
+// Undelete mocks base method
+func (m *MockPlaybookService) Undelete(arg0 app.Playbook, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Undelete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Undelete indicates an expected call of Undelete
+func (mr *MockPlaybookServiceMockRecorder) Undelete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Undelete", reflect.TypeOf((*MockPlaybookService)(nil).Undelete), arg0, arg1)
+}
+
 // Update mocks base method
 func (m *MockPlaybookService) Update(arg0 app.Playbook, arg1 string) error {
 	m.ctrl.T.Helper()
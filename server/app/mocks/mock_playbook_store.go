@@ -48,6 +48,20 @@ func (mr *MockPlaybookStoreMockRecorder) Create(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPlaybookStore)(nil).Create), arg0)
 }
 
+// CreateRevision mocks base method
+func (m *MockPlaybookStore) CreateRevision(arg0 app.PlaybookRevision) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRevision", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRevision indicates an expected call of CreateRevision
+func (mr *MockPlaybookStoreMockRecorder) CreateRevision(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRevision", reflect.TypeOf((*MockPlaybookStore)(nil).CreateRevision), arg0)
+}
+
 // Delete mocks base method
 func (m *MockPlaybookStore) Delete(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -152,6 +166,51 @@ func (mr *MockPlaybookStoreMockRecorder) GetPlaybooksWithKeywords(arg0 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaybooksWithKeywords", reflect.TypeOf((*MockPlaybookStore)(nil).GetPlaybooksWithKeywords), arg0)
 }
 
+// GetRevision mocks base method
+func (m *MockPlaybookStore) GetRevision(arg0 string, arg1 int64) (app.PlaybookRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", arg0, arg1)
+	ret0, _ := ret[0].(app.PlaybookRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision
+func (mr *MockPlaybookStoreMockRecorder) GetRevision(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockPlaybookStore)(nil).GetRevision), arg0, arg1)
+}
+
+// GetRevisions mocks base method
+func (m *MockPlaybookStore) GetRevisions(arg0 string) ([]app.PlaybookRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevisions", arg0)
+	ret0, _ := ret[0].([]app.PlaybookRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevisions indicates an expected call of GetRevisions
+func (mr *MockPlaybookStoreMockRecorder) GetRevisions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevisions", reflect.TypeOf((*MockPlaybookStore)(nil).GetRevisions), arg0)
+}
+
+// GetSyncedPlaybookIDs mocks base method
+func (m *MockPlaybookStore) GetSyncedPlaybookIDs(arg0 string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSyncedPlaybookIDs", arg0)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSyncedPlaybookIDs indicates an expected call of GetSyncedPlaybookIDs
+func (mr *MockPlaybookStoreMockRecorder) GetSyncedPlaybookIDs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSyncedPlaybookIDs", reflect.TypeOf((*MockPlaybookStore)(nil).GetSyncedPlaybookIDs), arg0)
+}
+
 // GetTimeLastUpdated mocks base method
 func (m *MockPlaybookStore) GetTimeLastUpdated(arg0 bool) (int64, error) {
 	m.ctrl.T.Helper()
@@ -167,6 +226,20 @@ func (mr *MockPlaybookStoreMockRecorder) GetTimeLastUpdated(arg0 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeLastUpdated", reflect.TypeOf((*MockPlaybookStore)(nil).GetTimeLastUpdated), arg0)
 }
 
+// Undelete mocks base method
+func (m *MockPlaybookStore) Undelete(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Undelete", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Undelete indicates an expected call of Undelete
+func (mr *MockPlaybookStoreMockRecorder) Undelete(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Undelete", reflect.TypeOf((*MockPlaybookStore)(nil).Undelete), arg0)
+}
+
 // Update mocks base method
 func (m *MockPlaybookStore) Update(arg0 app.Playbook) error {
 	m.ctrl.T.Helper()
@@ -180,3 +253,17 @@ func (mr *MockPlaybookStoreMockRecorder) Update(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPlaybookStore)(nil).Update), arg0)
 }
+
+// UpdateWithRevision mocks base method
+func (m *MockPlaybookStore) UpdateWithRevision(arg0 app.Playbook, arg1 app.PlaybookRevision) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWithRevision", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWithRevision indicates an expected call of UpdateWithRevision
+func (mr *MockPlaybookStoreMockRecorder) UpdateWithRevision(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWithRevision", reflect.TypeOf((*MockPlaybookStore)(nil).UpdateWithRevision), arg0, arg1)
+}
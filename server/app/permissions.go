@@ -15,6 +15,12 @@ var ErrNoPermissions = errors.New("does not have permissions")
 // ErrLicensedFeature if the error is caused by the server not having the needed license for the feature
 var ErrLicensedFeature = errors.New("not covered by current server license")
 
+// isPermissionError returns true if the error is caused by the user not having permissions, or
+// the server not having the needed license.
+func isPermissionError(err error) bool {
+	return errors.Is(err, ErrNoPermissions) || errors.Is(err, ErrLicensedFeature)
+}
+
 // RequesterInfo holds the userID and teamID that this request is regarding, and permissions
 // for the user making the request
 type RequesterInfo struct {
@@ -112,6 +118,17 @@ func IsMemberOfTeamID(userID, teamID string, pluginAPI *pluginapi.Client) bool {
 	return teamMember.DeleteAt == 0
 }
 
+// IsBotOfTeamID returns true if the user is a bot account and a member of the team. Only those may
+// post the inbound status updates of a playbook, to not impersonate the team's users.
+func IsBotOfTeamID(userID, teamID string, pluginAPI *pluginapi.Client) bool {
+	user, err := pluginAPI.User.Get(userID)
+	if err != nil || !user.IsBot {
+		return false
+	}
+
+	return IsMemberOfTeamID(userID, teamID, pluginAPI)
+}
+
 var (
 	ErrChannelNotFound          = errors.Errorf("channel not found")
 	ErrChannelDeleted           = errors.Errorf("channel deleted")
@@ -256,7 +273,8 @@ func CreatePlaybook(userID string, playbook Playbook, cfgService config.Service,
 	if isGuest, err := IsGuest(userID, pluginAPI); err != nil {
 		return err
 	} else if isGuest {
-		return errors.Errorf(
+		return errors.Wrapf(
+			ErrNoPermissions,
 			"userID %s does not have permission to create playbook on teamID %s because they are a guest",
 			userID,
 			playbook.TeamID,
@@ -265,7 +283,8 @@ func CreatePlaybook(userID string, playbook Playbook, cfgService config.Service,
 
 	if playbook.BroadcastChannelID != "" &&
 		!pluginAPI.User.HasPermissionToChannel(userID, playbook.BroadcastChannelID, model.PERMISSION_CREATE_POST) {
-		return errors.Errorf(
+		return errors.Wrapf(
+			ErrNoPermissions,
 			"userID %s does not have permission to create posts in the channel %s",
 			userID,
 			playbook.BroadcastChannelID,
@@ -273,7 +292,8 @@ func CreatePlaybook(userID string, playbook Playbook, cfgService config.Service,
 	}
 
 	if !CanViewTeam(userID, playbook.TeamID, pluginAPI) {
-		return errors.Errorf(
+		return errors.Wrapf(
+			ErrNoPermissions,
 			"userID %s does not have permission to create playbook on teamID %s",
 			userID,
 			playbook.TeamID,
@@ -282,7 +302,8 @@ func CreatePlaybook(userID string, playbook Playbook, cfgService config.Service,
 
 	if playbook.AnnouncementChannelID != "" &&
 		!pluginAPI.User.HasPermissionToChannel(userID, playbook.AnnouncementChannelID, model.PERMISSION_CREATE_POST) {
-		return errors.Errorf(
+		return errors.Wrapf(
+			ErrNoPermissions,
 			"userID %s does not have permission to create posts in the channel %s",
 			userID,
 			playbook.AnnouncementChannelID,
@@ -292,7 +313,8 @@ func CreatePlaybook(userID string, playbook Playbook, cfgService config.Service,
 	// Check all invited users have permissions to the team.
 	for _, userID := range playbook.InvitedUserIDs {
 		if !pluginAPI.User.HasPermissionToTeam(userID, playbook.TeamID, model.PERMISSION_VIEW_TEAM) {
-			return errors.Errorf(
+			return errors.Wrapf(
+				ErrNoPermissions,
 				"invited user with ID %s does not have permission to playbook's team %s",
 				userID,
 				playbook.TeamID,
@@ -307,7 +329,8 @@ func CreatePlaybook(userID string, playbook Playbook, cfgService config.Service,
 		}
 
 		if !group.AllowReference {
-			return errors.Errorf(
+			return errors.Wrapf(
+				ErrNoPermissions,
 				"group %s does not allow references",
 				groupID,
 			)
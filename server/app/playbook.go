@@ -3,6 +3,7 @@ package app
 import (
 	"encoding/json"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/pkg/errors"
@@ -11,40 +12,53 @@ import (
 // Playbook represents a desired business outcome, from which playbook runs are started to solve
 // a specific instance.
 type Playbook struct {
-	ID                                   string      `json:"id"`
-	Title                                string      `json:"title"`
-	Description                          string      `json:"description"`
-	TeamID                               string      `json:"team_id"`
-	CreatePublicPlaybookRun              bool        `json:"create_public_playbook_run"`
-	CreateAt                             int64       `json:"create_at"`
-	UpdateAt                             int64       `json:"update_at"`
-	DeleteAt                             int64       `json:"delete_at"`
-	NumStages                            int64       `json:"num_stages"`
-	NumSteps                             int64       `json:"num_steps"`
-	Checklists                           []Checklist `json:"checklists"`
-	MemberIDs                            []string    `json:"member_ids"`
-	BroadcastChannelID                   string      `json:"broadcast_channel_id"`
-	ReminderMessageTemplate              string      `json:"reminder_message_template"`
-	ReminderTimerDefaultSeconds          int64       `json:"reminder_timer_default_seconds"`
-	InvitedUserIDs                       []string    `json:"invited_user_ids"`
-	InvitedGroupIDs                      []string    `json:"invited_group_ids"`
-	InviteUsersEnabled                   bool        `json:"invite_users_enabled"`
-	DefaultOwnerID                       string      `json:"default_owner_id"`
-	DefaultOwnerEnabled                  bool        `json:"default_owner_enabled"`
-	AnnouncementChannelID                string      `json:"announcement_channel_id"`
-	AnnouncementChannelEnabled           bool        `json:"announcement_channel_enabled"`
-	WebhookOnCreationURL                 string      `json:"webhook_on_creation_url"`
-	WebhookOnCreationEnabled             bool        `json:"webhook_on_creation_enabled"`
-	MessageOnJoin                        string      `json:"message_on_join"`
-	MessageOnJoinEnabled                 bool        `json:"message_on_join_enabled"`
-	RetrospectiveReminderIntervalSeconds int64       `json:"retrospective_reminder_interval_seconds"`
-	RetrospectiveTemplate                string      `json:"retrospective_template"`
-	WebhookOnStatusUpdateURL             string      `json:"webhook_on_status_update_url"`
-	WebhookOnStatusUpdateEnabled         bool        `json:"webhook_on_status_update_enabled"`
-	ExportChannelOnArchiveEnabled        bool        `json:"export_channel_on_archive_enabled"`
-	SignalAnyKeywords                    []string    `json:"signal_any_keywords"`
-	SignalAnyKeywordsEnabled             bool        `json:"signal_any_keywords_enabled"`
-	CategorizeChannelEnabled             bool        `json:"categorize_channel_enabled"`
+	ID                                   string                `json:"id"`
+	Title                                string                `json:"title"`
+	Description                          string                `json:"description"`
+	TeamID                               string                `json:"team_id"`
+	CreatePublicPlaybookRun              bool                  `json:"create_public_playbook_run"`
+	CreatorID                            string                `json:"creator_id"` // Blank if it was created before creators were recorded
+	CreateAt                             int64                 `json:"create_at"`
+	UpdateAt                             int64                 `json:"update_at"`
+	DeleteAt                             int64                 `json:"delete_at"`
+	Revision                             int64                 `json:"revision"` // The number of its latest PlaybookRevision
+	NumStages                            int64                 `json:"num_stages"`
+	NumSteps                             int64                 `json:"num_steps"`
+	Checklists                           []Checklist           `json:"checklists"`
+	MemberIDs                            []string              `json:"member_ids"`
+	BroadcastChannelID                   string                `json:"broadcast_channel_id"`
+	ReminderMessageTemplate              string                `json:"reminder_message_template"`
+	ReminderTimerDefaultSeconds          int64                 `json:"reminder_timer_default_seconds"`
+	InvitedUserIDs                       []string              `json:"invited_user_ids"`
+	InvitedGroupIDs                      []string              `json:"invited_group_ids"`
+	InviteUsersEnabled                   bool                  `json:"invite_users_enabled"`
+	DefaultOwnerID                       string                `json:"default_owner_id"`
+	DefaultOwnerEnabled                  bool                  `json:"default_owner_enabled"`
+	AnnouncementChannelID                string                `json:"announcement_channel_id"`
+	AnnouncementChannelEnabled           bool                  `json:"announcement_channel_enabled"`
+	WebhookOnCreationURL                 string                `json:"webhook_on_creation_url"`
+	WebhookOnCreationEnabled             bool                  `json:"webhook_on_creation_enabled"`
+	MessageOnJoin                        string                `json:"message_on_join"`
+	MessageOnJoinEnabled                 bool                  `json:"message_on_join_enabled"`
+	RetrospectiveReminderIntervalSeconds int64                 `json:"retrospective_reminder_interval_seconds"`
+	RetrospectiveTemplate                string                `json:"retrospective_template"`
+	WebhookOnStatusUpdateURL             string                `json:"webhook_on_status_update_url"`
+	WebhookOnStatusUpdateEnabled         bool                  `json:"webhook_on_status_update_enabled"`
+	WebhookSecret                        string                `json:"webhook_secret"` // Signs the requests of both webhooks, if not empty; write-only, see RedactPlaybookSecrets
+	HasWebhookSecret                     bool                  `json:"has_webhook_secret"`
+	ExportChannelOnArchiveEnabled        bool                  `json:"export_channel_on_archive_enabled"`
+	SignalAnyKeywords                    []string              `json:"signal_any_keywords"`
+	SignalAnyKeywordsEnabled             bool                  `json:"signal_any_keywords_enabled"`
+	CategorizeChannelEnabled             bool                  `json:"categorize_channel_enabled"`
+	SeverityLevels                       []string              `json:"severity_levels"`
+	DefaultSeverity                      string                `json:"default_severity"`
+	StatusWorkflow                       StatusWorkflow        `json:"status_workflow"`
+	ReminderEscalation                   ReminderEscalation    `json:"reminder_escalation"`
+	CustomFields                         []CustomField         `json:"custom_fields"`
+	WebhookSubscriptions                 []WebhookSubscription `json:"webhook_subscriptions"`
+	InboundWebhook                       InboundWebhook        `json:"inbound_webhook"` // Its token is write-only, see RedactPlaybookSecrets
+	HasInboundWebhookToken               bool                  `json:"has_inbound_webhook_token"`
+	SyncPath                             string                `json:"sync_path"` // The file the playbook is synced from, if any, which locks it against edits
 }
 
 func (p Playbook) Clone() Playbook {
@@ -64,6 +78,12 @@ func (p Playbook) Clone() Playbook {
 	if len(p.SignalAnyKeywords) != 0 {
 		newPlaybook.SignalAnyKeywords = append([]string(nil), p.SignalAnyKeywords...)
 	}
+	if len(p.SeverityLevels) != 0 {
+		newPlaybook.SeverityLevels = append([]string(nil), p.SeverityLevels...)
+	}
+	newPlaybook.StatusWorkflow = p.StatusWorkflow.Clone()
+	newPlaybook.CustomFields = CloneCustomFields(p.CustomFields)
+	newPlaybook.WebhookSubscriptions = CloneWebhookSubscriptions(p.WebhookSubscriptions)
 	return newPlaybook
 }
 
@@ -92,10 +112,212 @@ func (p Playbook) MarshalJSON() ([]byte, error) {
 	if old.SignalAnyKeywords == nil {
 		old.SignalAnyKeywords = []string{}
 	}
+	if old.SeverityLevels == nil {
+		old.SeverityLevels = []string{}
+	}
+	if old.StatusWorkflow.States == nil {
+		old.StatusWorkflow.States = []StatusState{}
+	}
+	if old.CustomFields == nil {
+		old.CustomFields = []CustomField{}
+	}
+	for j, field := range old.CustomFields {
+		if field.Options == nil {
+			old.CustomFields[j].Options = []string{}
+		}
+	}
+	if old.WebhookSubscriptions == nil {
+		old.WebhookSubscriptions = []WebhookSubscription{}
+	}
 
 	return json.Marshal(old)
 }
 
+// MaxSeverityLevelLength is the maximum length of a single severity level name.
+const MaxSeverityLevelLength = 64
+
+// ValidateSeverityLevels checks that the playbook's severity scale can be stored and used: every
+// level must be non-empty, unique (ignoring case), free of commas and at most
+// MaxSeverityLevelLength characters long, and the default severity, if any, must be one of them.
+func (p Playbook) ValidateSeverityLevels() error {
+	seen := make(map[string]bool, len(p.SeverityLevels))
+	for _, level := range p.SeverityLevels {
+		if strings.TrimSpace(level) == "" {
+			return errors.New("severity levels must not be empty")
+		}
+		if strings.Contains(level, ",") {
+			return errors.Errorf("severity level '%s' must not contain commas", level)
+		}
+		if utf8.RuneCountInString(level) > MaxSeverityLevelLength {
+			return errors.Errorf("severity level '%s' must be at most %d characters", level, MaxSeverityLevelLength)
+		}
+		key := strings.ToLower(level)
+		if seen[key] {
+			return errors.Errorf("severity level '%s' is duplicated", level)
+		}
+		seen[key] = true
+	}
+
+	if p.DefaultSeverity != "" && FindSeverityLevel(p.SeverityLevels, p.DefaultSeverity) != p.DefaultSeverity {
+		return errors.Errorf("default severity '%s' is not one of the severity levels", p.DefaultSeverity)
+	}
+
+	return nil
+}
+
+// ValidateStatusWorkflow checks that the playbook's status workflow, if any, is well formed.
+// Playbooks without a status workflow use DefaultStatusWorkflow.
+func (p Playbook) ValidateStatusWorkflow() error {
+	if p.StatusWorkflow.IsEmpty() {
+		return nil
+	}
+
+	return p.StatusWorkflow.Validate()
+}
+
+// ValidateCustomFields checks that the playbook's custom fields can be stored and used.
+func (p Playbook) ValidateCustomFields() error {
+	return ValidateCustomFields(p.CustomFields)
+}
+
+// ValidateWebhookSubscriptions checks the playbook's webhook subscriptions, see
+// ValidateWebhookSubscriptions.
+func (p Playbook) ValidateWebhookSubscriptions() error {
+	return ValidateWebhookSubscriptions(p.WebhookSubscriptions)
+}
+
+// MaxWebhookSecretLength is the maximum length of a playbook's webhook secret.
+const MaxWebhookSecretLength = 256
+
+// ValidateWebhookSecret checks that the playbook's webhook secret, if any, is at most
+// MaxWebhookSecretLength characters long and does not start or end with whitespace.
+func (p Playbook) ValidateWebhookSecret() error {
+	if len(p.WebhookSecret) > MaxWebhookSecretLength {
+		return errors.Errorf("webhook secret must be at most %d characters", MaxWebhookSecretLength)
+	}
+	if strings.TrimSpace(p.WebhookSecret) != p.WebhookSecret {
+		return errors.New("webhook secret must not start or end with whitespace")
+	}
+
+	return nil
+}
+
+// RedactPlaybookSecrets clears the webhook secret and the inbound webhook token of a playbook
+// about to be sent to a client, flagging whether it has them instead: they are write-only.
+func RedactPlaybookSecrets(playbook *Playbook) {
+	playbook.HasWebhookSecret = playbook.WebhookSecret != ""
+	playbook.WebhookSecret = ""
+	playbook.HasInboundWebhookToken = playbook.InboundWebhook.Token != ""
+	playbook.InboundWebhook.Token = ""
+}
+
+// KeepPlaybookSecrets keeps the current webhook secret and inbound webhook token of a playbook
+// being updated when the update leaves them empty but still flags them, as clients only ever
+// receive them redacted. Clearing a flag clears its secret.
+func KeepPlaybookSecrets(playbook *Playbook, current Playbook) {
+	if playbook.WebhookSecret == "" && playbook.HasWebhookSecret {
+		playbook.WebhookSecret = current.WebhookSecret
+	}
+	if playbook.InboundWebhook.Token == "" && playbook.HasInboundWebhookToken {
+		playbook.InboundWebhook.Token = current.InboundWebhook.Token
+	}
+
+	playbook.HasWebhookSecret = false
+	playbook.HasInboundWebhookToken = false
+}
+
+// ValidatePlaybook checks the settings of a playbook about to be saved: its webhook URLs, severity
+// levels, status workflow, reminder escalation, custom fields, webhook secret, webhook subscriptions, inbound webhook
+// and checklist item due dates, dependencies and command triggers. It also removes empty and duplicate keywords.
+func ValidatePlaybook(playbook *Playbook) error {
+	if playbook.WebhookOnCreationEnabled {
+		if err := validateEnabledWebhookURL(playbook.WebhookOnCreationURL); err != nil {
+			return errors.Wrap(err, "invalid creation webhook URL")
+		}
+	}
+
+	if playbook.WebhookOnStatusUpdateEnabled {
+		if err := validateEnabledWebhookURL(playbook.WebhookOnStatusUpdateURL); err != nil {
+			return errors.Wrap(err, "invalid update webhook URL")
+		}
+	}
+
+	if len(playbook.SignalAnyKeywords) != 0 {
+		keywords := make([]string, 0, len(playbook.SignalAnyKeywords))
+		seen := make(map[string]bool, len(playbook.SignalAnyKeywords))
+		for _, keyword := range playbook.SignalAnyKeywords {
+			if keyword != "" && !seen[keyword] {
+				seen[keyword] = true
+				keywords = append(keywords, keyword)
+			}
+		}
+		playbook.SignalAnyKeywords = keywords
+	}
+
+	if err := playbook.ValidateSeverityLevels(); err != nil {
+		return errors.Wrap(err, "invalid severity levels")
+	}
+
+	if err := playbook.ValidateStatusWorkflow(); err != nil {
+		return errors.Wrap(err, "invalid status workflow")
+	}
+
+	if err := playbook.ReminderEscalation.Validate(); err != nil {
+		return errors.Wrap(err, "invalid reminder escalation")
+	}
+
+	if err := playbook.ValidateCustomFields(); err != nil {
+		return errors.Wrap(err, "invalid custom fields")
+	}
+
+	if err := playbook.ValidateWebhookSecret(); err != nil {
+		return errors.Wrap(err, "invalid webhook secret")
+	}
+
+	if err := playbook.ValidateWebhookSubscriptions(); err != nil {
+		return errors.Wrap(err, "invalid webhook subscriptions")
+	}
+
+	if err := playbook.ValidateInboundWebhook(); err != nil {
+		return errors.Wrap(err, "invalid inbound webhook")
+	}
+
+	if err := playbook.ValidateChecklistItemDueDates(); err != nil {
+		return errors.Wrap(err, "invalid checklist item due dates")
+	}
+
+	if err := ValidateChecklistItemDependencies(playbook.Checklists); err != nil {
+		return errors.Wrap(err, "invalid checklist item dependencies")
+	}
+
+	if err := playbook.ValidateChecklistItemCommandTriggers(); err != nil {
+		return errors.Wrap(err, "invalid checklist item command triggers")
+	}
+
+	return nil
+}
+
+// validateEnabledWebhookURL checks that the URL of an enabled webhook is an absolute HTTP(S) URL.
+func validateEnabledWebhookURL(webhookURL string) error {
+	if webhookURL == "" {
+		return errors.New("URL must not be empty")
+	}
+
+	return validateWebhookURL(webhookURL)
+}
+
+// FindSeverityLevel returns the level in levels matching severity, ignoring case, or "" if there
+// is no such level.
+func FindSeverityLevel(levels []string, severity string) string {
+	for _, level := range levels {
+		if strings.EqualFold(level, severity) {
+			return level
+		}
+	}
+
+	return ""
+}
+
 // Checklist represents a checklist in a playbook
 type Checklist struct {
 	ID    string          `json:"id"`
@@ -106,6 +328,11 @@ type Checklist struct {
 func (c Checklist) Clone() Checklist {
 	newChecklist := c
 	newChecklist.Items = append([]ChecklistItem(nil), c.Items...)
+	for i := range newChecklist.Items {
+		newChecklist.Items[i].DependsOn = append([]string(nil), c.Items[i].DependsOn...)
+		newChecklist.Items[i].SubItems = append([]ChecklistSubItem(nil), c.Items[i].SubItems...)
+		newChecklist.Items[i].Comments = append([]ChecklistItemComment(nil), c.Items[i].Comments...)
+	}
 	return newChecklist
 }
 
@@ -122,6 +349,76 @@ type ChecklistItem struct {
 	Command                string `json:"command"`
 	CommandLastRun         int64  `json:"command_last_run"`
 	Description            string `json:"description"`
+
+	// SkipReason is why the item was skipped, when its state is ChecklistItemStateSkipped.
+	SkipReason string `json:"skip_reason"`
+
+	// DueAt is the deadline of the item in milliseconds, 0 if it has none. In a playbook run it is
+	// computed from DueAfterSeconds once the item's deadline starts running, see
+	// ChecklistItemDueRelativeToRun and ChecklistItemDueRelativeToStage.
+	DueAt int64 `json:"due_at"`
+
+	// DueAfterSeconds is the time the item is due after what DueRelativeTo refers to.
+	DueAfterSeconds int64 `json:"due_after_seconds"`
+
+	// DueRelativeTo is what the relative deadline of the item counts from, empty if it has none.
+	DueRelativeTo string `json:"due_relative_to"`
+
+	// DependsOn holds the IDs of the items, of the same playbook or run, that must be closed
+	// before this one can be.
+	DependsOn []string `json:"depends_on"`
+
+	// Blocked is true if some of the items the item depends on are still not closed. It is only
+	// computed for the items of playbook runs returned by the API, and never stored.
+	Blocked bool `json:"blocked"`
+
+	// CommandTrigger is when the item's command runs automatically, empty if it only runs when
+	// a user asks for it. See ChecklistItemCommandTriggerRunStart and the other triggers.
+	CommandTrigger string `json:"command_trigger"`
+
+	// CommandTriggerStatus is the status that triggers the item's command, for the
+	// ChecklistItemCommandTriggerStatus trigger.
+	CommandTriggerStatus string `json:"command_trigger_status"`
+
+	// CommandRunAs is who the triggered command runs as, the run's owner if empty.
+	CommandRunAs string `json:"command_run_as"`
+
+	// CommandTriggeredAt is when the item's command was triggered in a run, 0 if it was not.
+	CommandTriggeredAt int64 `json:"command_triggered_at"`
+
+	// SubItems are the steps the item is made of, each with its own state and assignee.
+	SubItems []ChecklistSubItem `json:"sub_items"`
+
+	// Comments is the discussion of the item in a playbook run, oldest first. Each comment is a
+	// reply to the post with CommentsPostID in the run's channel.
+	Comments []ChecklistItemComment `json:"comments"`
+
+	// CommentsPostID is the ID of the post starting the thread of the item's comments, empty
+	// until the item is first commented on.
+	CommentsPostID string `json:"comments_post_id"`
+}
+
+// IsDone returns true if the item no longer needs to be done, as it is closed or skipped.
+func (i ChecklistItem) IsDone() bool {
+	return i.State == ChecklistItemStateClosed || i.State == ChecklistItemStateSkipped
+}
+
+// ChecklistSubItem represents a step of a checklist item
+type ChecklistSubItem struct {
+	ID               string `json:"id"`
+	Title            string `json:"title"`
+	State            string `json:"state"`
+	StateModified    int64  `json:"state_modified"`
+	AssigneeID       string `json:"assignee_id"`
+	AssigneeModified int64  `json:"assignee_modified"`
+}
+
+// ChecklistItemComment represents a comment on a checklist item of a playbook run
+type ChecklistItemComment struct {
+	PostID   string `json:"post_id"`
+	UserID   string `json:"user_id"`
+	Message  string `json:"message"`
+	CreateAt int64  `json:"create_at"`
 }
 
 type GetPlaybooksResults struct {
@@ -170,14 +467,46 @@ type PlaybookService interface {
 	// GetSuggestedPlaybooks returns suggested playbooks and triggers for the user message
 	GetSuggestedPlaybooks(teamID, userID, message string) ([]*CachedPlaybook, []string)
 
-	// Update updates a playbook
+	// Update updates a playbook, saving its new revision.
+	// Returns ErrPlaybookRevisionConflict if the playbook was updated concurrently.
 	Update(playbook Playbook, userID string) error
 
 	// Delete deletes a playbook
 	Delete(playbook Playbook, userID string) error
 
+	// Undelete restores a deleted playbook
+	Undelete(playbook Playbook, userID string) error
+
+	// GetRevisions retrieves the revisions of a playbook, most recent first, without their snapshots
+	GetRevisions(playbookID string) ([]PlaybookRevision, error)
+
+	// GetRevision retrieves a revision of a playbook. Returns ErrNotFound if not found.
+	GetRevision(playbookID string, revision int64) (PlaybookRevision, error)
+
 	// MessageHasBeenPosted suggests playbooks to the user if triggered
 	MessageHasBeenPosted(sessionID string, post *model.Post)
+
+	// Export returns the portable form of a playbook, to be imported into another team or server
+	Export(playbook Playbook) PlaybookExport
+
+	// Import creates a playbook in the team from an export, on behalf of the user.
+	// Returns ErrPermission if the user cannot create it, ErrMalformedPlaybook if it is not valid.
+	Import(export PlaybookExport, teamID, userID string) (PlaybookImportResult, error)
+
+	// Duplicate creates a copy of a playbook in the team, on behalf of the user.
+	// Returns ErrPermission if the user cannot create it, ErrMalformedPlaybook if it is not valid.
+	Duplicate(playbook Playbook, teamID, userID string) (PlaybookImportResult, error)
+
+	// Sync reconciles the synced playbooks of the team with the playbook files, by their path, on
+	// behalf of the user: playbooks are created for new files, updated to the settings of changed
+	// files, and deleted when their file is removed. A dry run reports the changes without making
+	// them. Returns ErrMalformedPlaybook, changing no playbook, if a file is not a valid playbook.
+	Sync(teamID, userID string, files map[string][]byte, dryRun bool) (PlaybookSyncResult, error)
+
+	// SyncFromDirectory syncs the playbooks of the team with the files of its directory in the
+	// configured sync directory. Returns ErrPlaybookSyncNotConfigured if no directory is
+	// configured, ErrNotFound if the team has no directory.
+	SyncFromDirectory(teamID, userID string, dryRun bool) (PlaybookSyncResult, error)
 }
 
 // PlaybookStore is an interface for storing playbooks
@@ -207,11 +536,32 @@ type PlaybookStore interface {
 	// GetPlaybookIDsForUser retrieves playbooks user can access
 	GetPlaybookIDsForUser(userID, teamID string) ([]string, error)
 
+	// GetSyncedPlaybookIDs retrieves the IDs of the playbooks of the team that are synced from
+	// files, by the path of their file.
+	GetSyncedPlaybookIDs(teamID string) (map[string]string, error)
+
 	// Update updates a playbook
 	Update(playbook Playbook) error
 
+	// UpdateWithRevision updates a playbook and saves its new revision, atomically.
+	// Returns ErrPlaybookRevisionConflict if a revision with the same number already exists.
+	UpdateWithRevision(playbook Playbook, revision PlaybookRevision) error
+
 	// Delete deletes a playbook
 	Delete(id string) error
+
+	// Undelete restores a deleted playbook
+	Undelete(id string) error
+
+	// CreateRevision saves a revision of a playbook.
+	// Returns ErrPlaybookRevisionConflict if a revision with the same number already exists.
+	CreateRevision(revision PlaybookRevision) error
+
+	// GetRevisions retrieves the revisions of a playbook, most recent first, without their snapshots
+	GetRevisions(playbookID string) ([]PlaybookRevision, error)
+
+	// GetRevision retrieves a revision of a playbook. Returns ErrNotFound if not found.
+	GetRevision(playbookID string, revision int64) (PlaybookRevision, error)
 }
 
 // PlaybookTelemetry defines the methods that the Playbook service needs from the RudderTelemetry.
@@ -226,6 +576,9 @@ type PlaybookTelemetry interface {
 	// DeletePlaybook tracks the deletion of a playbook.
 	DeletePlaybook(playbook Playbook, userID string)
 
+	// UndeletePlaybook tracks the restoration of a deleted playbook.
+	UndeletePlaybook(playbook Playbook, userID string)
+
 	// FrontendTelemetryForPlaybook tracks an event originating from the frontend
 	FrontendTelemetryForPlaybook(playbook Playbook, userID, action string)
 }
@@ -234,18 +587,33 @@ const (
 	ChecklistItemStateOpen       = ""
 	ChecklistItemStateInProgress = "in_progress"
 	ChecklistItemStateClosed     = "closed"
+
+	// ChecklistItemStateSkipped is the state of the items that did not apply to the run, and were
+	// not done for the item's SkipReason.
+	ChecklistItemStateSkipped = "skipped"
 )
 
 func IsValidChecklistItemState(state string) bool {
 	return state == ChecklistItemStateClosed ||
 		state == ChecklistItemStateInProgress ||
-		state == ChecklistItemStateOpen
+		state == ChecklistItemStateOpen ||
+		state == ChecklistItemStateSkipped
+}
+
+// IsValidChecklistSubItemState returns true if the state is one of a sub-item's, which are not
+// skipped.
+func IsValidChecklistSubItemState(state string) bool {
+	return state != ChecklistItemStateSkipped && IsValidChecklistItemState(state)
 }
 
 func IsValidChecklistItemIndex(checklists []Checklist, checklistNum, itemNum int) bool {
 	return checklists != nil && checklistNum >= 0 && itemNum >= 0 && checklistNum < len(checklists) && itemNum < len(checklists[checklistNum].Items)
 }
 
+func IsValidChecklistSubItemIndex(checklists []Checklist, checklistNum, itemNum, subItemNum int) bool {
+	return IsValidChecklistItemIndex(checklists, checklistNum, itemNum) && subItemNum >= 0 && subItemNum < len(checklists[checklistNum].Items[itemNum].SubItems)
+}
+
 // PlaybookFilterOptions specifies the parameters when getting playbooks.
 type PlaybookFilterOptions struct {
 	Sort      SortField
@@ -254,11 +622,35 @@ type PlaybookFilterOptions struct {
 	// Pagination options.
 	Page    int
 	PerPage int
+
+	// SearchTerm filters playbooks whose title or description contains the term, ignoring case.
+	// Defaults to blank (no filter).
+	SearchTerm string
+
+	// MemberID filters playbooks that have this member. Defaults to blank (no filter).
+	MemberID string
+
+	// CreatorID filters playbooks created by this user. Some playbooks created before creators
+	// were recorded have no known creator. Defaults to blank (no filter).
+	CreatorID string
+
+	// KeywordsEnabled filters playbooks by whether their keywords are enabled. Defaults to nil
+	// (no filter).
+	KeywordsEnabled *bool
+
+	// IncludeArchived includes the deleted playbooks. Defaults to false.
+	IncludeArchived bool
 }
 
 // Clone duplicates the given options.
 func (o *PlaybookFilterOptions) Clone() PlaybookFilterOptions {
-	return *o
+	newPlaybookFilterOptions := *o
+	if o.KeywordsEnabled != nil {
+		keywordsEnabled := *o.KeywordsEnabled
+		newPlaybookFilterOptions.KeywordsEnabled = &keywordsEnabled
+	}
+
+	return newPlaybookFilterOptions
 }
 
 // Validate returns a new, validated filter options or returns an error if invalid.
@@ -291,5 +683,15 @@ func (o PlaybookFilterOptions) Validate() (PlaybookFilterOptions, error) {
 		return PlaybookFilterOptions{}, errors.Errorf("unsupported direction '%s'", options.Direction)
 	}
 
+	if options.MemberID != "" && !model.IsValidId(options.MemberID) {
+		return PlaybookFilterOptions{}, errors.New("bad parameter 'member_id': must be 26 characters or blank")
+	}
+
+	if options.CreatorID != "" && !model.IsValidId(options.CreatorID) {
+		return PlaybookFilterOptions{}, errors.New("bad parameter 'creator_id': must be 26 characters or blank")
+	}
+
+	options.SearchTerm = strings.TrimSpace(options.SearchTerm)
+
 	return options, nil
 }
@@ -0,0 +1,595 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// PlaybookExportVersion is the version of the format of playbook exports, bumped on changes that
+// older versions of the plugin cannot import.
+const PlaybookExportVersion = 1
+
+// PlaybookExportFormat is the encoding of a playbook export.
+type PlaybookExportFormat string
+
+const (
+	PlaybookExportFormatJSON PlaybookExportFormat = "json"
+	PlaybookExportFormatYAML PlaybookExportFormat = "yaml"
+)
+
+// ParsePlaybookExportFormat returns the format with the given name, ignoring case. An empty name
+// is JSON.
+func ParsePlaybookExportFormat(name string) (PlaybookExportFormat, error) {
+	switch PlaybookExportFormat(strings.ToLower(name)) {
+	case PlaybookExportFormatJSON, "":
+		return PlaybookExportFormatJSON, nil
+	case PlaybookExportFormatYAML, "yml":
+		return PlaybookExportFormatYAML, nil
+	default:
+		return "", errors.Errorf("unknown playbook export format '%s': it should be one of 'json' or 'yaml'", name)
+	}
+}
+
+// PlaybookExport is a playbook in a form that can be imported into another team or server. Users,
+// groups and channels are referred to by their username, group name and channel name, channels of
+// another team than the playbook's being prefixed by that team's name and a slash. Secrets, i.e. the
+// webhook secret and the inbound webhook token, are not exported.
+type PlaybookExport struct {
+	Version                              int                        `json:"version"`
+	Title                                string                     `json:"title"`
+	Description                          string                     `json:"description"`
+	CreatePublicPlaybookRun              bool                       `json:"create_public_playbook_run"`
+	Checklists                           []ExportedChecklist        `json:"checklists"`
+	Members                              []string                   `json:"members"`
+	BroadcastChannel                     string                     `json:"broadcast_channel"`
+	ReminderMessageTemplate              string                     `json:"reminder_message_template"`
+	ReminderTimerDefaultSeconds          int64                      `json:"reminder_timer_default_seconds"`
+	InvitedUsers                         []string                   `json:"invited_users"`
+	InvitedGroups                        []string                   `json:"invited_groups"`
+	InviteUsersEnabled                   bool                       `json:"invite_users_enabled"`
+	DefaultOwner                         string                     `json:"default_owner"`
+	DefaultOwnerEnabled                  bool                       `json:"default_owner_enabled"`
+	AnnouncementChannel                  string                     `json:"announcement_channel"`
+	AnnouncementChannelEnabled           bool                       `json:"announcement_channel_enabled"`
+	WebhookOnCreationURL                 string                     `json:"webhook_on_creation_url"`
+	WebhookOnCreationEnabled             bool                       `json:"webhook_on_creation_enabled"`
+	WebhookOnStatusUpdateURL             string                     `json:"webhook_on_status_update_url"`
+	WebhookOnStatusUpdateEnabled         bool                       `json:"webhook_on_status_update_enabled"`
+	MessageOnJoin                        string                     `json:"message_on_join"`
+	MessageOnJoinEnabled                 bool                       `json:"message_on_join_enabled"`
+	RetrospectiveReminderIntervalSeconds int64                      `json:"retrospective_reminder_interval_seconds"`
+	RetrospectiveTemplate                string                     `json:"retrospective_template"`
+	ExportChannelOnArchiveEnabled        bool                       `json:"export_channel_on_archive_enabled"`
+	SignalAnyKeywords                    []string                   `json:"signal_any_keywords"`
+	SignalAnyKeywordsEnabled             bool                       `json:"signal_any_keywords_enabled"`
+	CategorizeChannelEnabled             bool                       `json:"categorize_channel_enabled"`
+	SeverityLevels                       []string                   `json:"severity_levels"`
+	DefaultSeverity                      string                     `json:"default_severity"`
+	StatusWorkflow                       StatusWorkflow             `json:"status_workflow"`
+	ReminderEscalation                   ExportedReminderEscalation `json:"reminder_escalation"`
+	CustomFields                         []CustomField              `json:"custom_fields"` // Without their IDs, assigned again on import
+	WebhookSubscriptions                 []WebhookSubscription      `json:"webhook_subscriptions"`
+	InboundWebhook                       ExportedInboundWebhook     `json:"inbound_webhook"`
+}
+
+// ExportedChecklist is a checklist of a playbook export.
+type ExportedChecklist struct {
+	Title string                  `json:"title"`
+	Items []ExportedChecklistItem `json:"items"`
+}
+
+// ExportedChecklistItem is a checklist item of a playbook export.
+type ExportedChecklistItem struct {
+	Title           string   `json:"title"`
+	Description     string   `json:"description"`
+	Command         string   `json:"command"`
+	DueAfterSeconds int64    `json:"due_after_seconds,omitempty"`
+	DueRelativeTo   string   `json:"due_relative_to,omitempty"`
+	DependsOn       []string `json:"depends_on,omitempty"` // The titles of the items it depends on
+
+	CommandTrigger       string `json:"command_trigger,omitempty"`
+	CommandTriggerStatus string `json:"command_trigger_status,omitempty"`
+	CommandRunAs         string `json:"command_run_as,omitempty"`
+
+	SubItems []string `json:"sub_items,omitempty"` // The titles of its sub-items
+}
+
+// ExportedInboundWebhook is the inbound webhook of a playbook export, without its token.
+type ExportedInboundWebhook struct {
+	Enabled          bool                 `json:"enabled"`
+	Format           InboundWebhookFormat `json:"format"`
+	NameField        string               `json:"name_field"`
+	DescriptionField string               `json:"description_field"`
+	SeverityField    string               `json:"severity_field"`
+	FingerprintField string               `json:"fingerprint_field"`
+	ServiceUser      string               `json:"service_user"`
+}
+
+// ExportedReminderEscalation is the reminder escalation of a playbook export, its backup user and
+// group being referred to by username and group name.
+type ExportedReminderEscalation struct {
+	BackupAfterMissed    int    `json:"backup_after_missed"`
+	BackupUser           string `json:"backup_user"`
+	BackupGroup          string `json:"backup_group"`
+	BroadcastAfterMissed int    `json:"broadcast_after_missed"`
+}
+
+// PlaybookImportResult is the outcome of importing or duplicating a playbook: the ID of the new
+// playbook, and the settings that could not be carried over.
+type PlaybookImportResult struct {
+	ID       string   `json:"id"`
+	Warnings []string `json:"warnings"`
+}
+
+// ExportPlaybook returns the portable form of the playbook. References to users, groups and
+// channels that no longer exist are left out.
+func ExportPlaybook(playbook Playbook, pluginAPI *pluginapi.Client) PlaybookExport {
+	export := PlaybookExport{
+		Version:                              PlaybookExportVersion,
+		Title:                                playbook.Title,
+		Description:                          playbook.Description,
+		CreatePublicPlaybookRun:              playbook.CreatePublicPlaybookRun,
+		Checklists:                           []ExportedChecklist{},
+		Members:                              exportUsernames(playbook.MemberIDs, pluginAPI),
+		BroadcastChannel:                     exportChannelName(playbook.BroadcastChannelID, playbook.TeamID, pluginAPI),
+		ReminderMessageTemplate:              playbook.ReminderMessageTemplate,
+		ReminderTimerDefaultSeconds:          playbook.ReminderTimerDefaultSeconds,
+		InvitedUsers:                         exportUsernames(playbook.InvitedUserIDs, pluginAPI),
+		InvitedGroups:                        []string{},
+		InviteUsersEnabled:                   playbook.InviteUsersEnabled,
+		DefaultOwnerEnabled:                  playbook.DefaultOwnerEnabled,
+		AnnouncementChannel:                  exportChannelName(playbook.AnnouncementChannelID, playbook.TeamID, pluginAPI),
+		AnnouncementChannelEnabled:           playbook.AnnouncementChannelEnabled,
+		WebhookOnCreationURL:                 playbook.WebhookOnCreationURL,
+		WebhookOnCreationEnabled:             playbook.WebhookOnCreationEnabled,
+		WebhookOnStatusUpdateURL:             playbook.WebhookOnStatusUpdateURL,
+		WebhookOnStatusUpdateEnabled:         playbook.WebhookOnStatusUpdateEnabled,
+		MessageOnJoin:                        playbook.MessageOnJoin,
+		MessageOnJoinEnabled:                 playbook.MessageOnJoinEnabled,
+		RetrospectiveReminderIntervalSeconds: playbook.RetrospectiveReminderIntervalSeconds,
+		RetrospectiveTemplate:                playbook.RetrospectiveTemplate,
+		ExportChannelOnArchiveEnabled:        playbook.ExportChannelOnArchiveEnabled,
+		SignalAnyKeywords:                    append([]string{}, playbook.SignalAnyKeywords...),
+		SignalAnyKeywordsEnabled:             playbook.SignalAnyKeywordsEnabled,
+		CategorizeChannelEnabled:             playbook.CategorizeChannelEnabled,
+		SeverityLevels:                       append([]string{}, playbook.SeverityLevels...),
+		DefaultSeverity:                      playbook.DefaultSeverity,
+		StatusWorkflow:                       playbook.StatusWorkflow.Clone(),
+		ReminderEscalation: ExportedReminderEscalation{
+			BackupAfterMissed:    playbook.ReminderEscalation.BackupAfterMissed,
+			BroadcastAfterMissed: playbook.ReminderEscalation.BroadcastAfterMissed,
+		},
+		CustomFields:         CloneCustomFields(playbook.CustomFields),
+		WebhookSubscriptions: CloneWebhookSubscriptions(playbook.WebhookSubscriptions),
+		InboundWebhook: ExportedInboundWebhook{
+			Enabled:          playbook.InboundWebhook.Enabled,
+			Format:           playbook.InboundWebhook.Format,
+			NameField:        playbook.InboundWebhook.NameField,
+			DescriptionField: playbook.InboundWebhook.DescriptionField,
+			SeverityField:    playbook.InboundWebhook.SeverityField,
+			FingerprintField: playbook.InboundWebhook.FingerprintField,
+		},
+	}
+
+	for _, checklist := range playbook.Checklists {
+		exported := ExportedChecklist{Title: checklist.Title, Items: []ExportedChecklistItem{}}
+		for _, item := range checklist.Items {
+			exported.Items = append(exported.Items, ExportedChecklistItem{
+				Title:           item.Title,
+				Description:     item.Description,
+				Command:         item.Command,
+				DueAfterSeconds: item.DueAfterSeconds,
+				DueRelativeTo:   item.DueRelativeTo,
+				DependsOn:       checklistItemTitles(playbook.Checklists, item.DependsOn),
+
+				CommandTrigger:       item.CommandTrigger,
+				CommandTriggerStatus: item.CommandTriggerStatus,
+				CommandRunAs:         item.CommandRunAs,
+
+				SubItems: checklistSubItemTitles(item.SubItems),
+			})
+		}
+		export.Checklists = append(export.Checklists, exported)
+	}
+
+	for _, groupID := range playbook.InvitedGroupIDs {
+		if group, err := pluginAPI.Group.Get(groupID); err == nil && group.Name != nil {
+			export.InvitedGroups = append(export.InvitedGroups, *group.Name)
+		}
+	}
+
+	if usernames := exportUsernames([]string{playbook.DefaultOwnerID}, pluginAPI); len(usernames) == 1 {
+		export.DefaultOwner = usernames[0]
+	}
+	if usernames := exportUsernames([]string{playbook.ReminderEscalation.BackupUserID}, pluginAPI); len(usernames) == 1 {
+		export.ReminderEscalation.BackupUser = usernames[0]
+	}
+	if playbook.ReminderEscalation.BackupGroupID != "" {
+		if group, err := pluginAPI.Group.Get(playbook.ReminderEscalation.BackupGroupID); err == nil && group.Name != nil {
+			export.ReminderEscalation.BackupGroup = *group.Name
+		}
+	}
+	if usernames := exportUsernames([]string{playbook.InboundWebhook.ServiceUserID}, pluginAPI); len(usernames) == 1 {
+		export.InboundWebhook.ServiceUser = usernames[0]
+	}
+
+	for i := range export.CustomFields {
+		export.CustomFields[i].ID = ""
+	}
+
+	return export
+}
+
+// exportUsernames returns the usernames of the users that still exist.
+func exportUsernames(userIDs []string, pluginAPI *pluginapi.Client) []string {
+	usernames := []string{}
+	for _, userID := range userIDs {
+		if userID == "" {
+			continue
+		}
+		if user, err := pluginAPI.User.Get(userID); err == nil {
+			usernames = append(usernames, user.Username)
+		}
+	}
+
+	return usernames
+}
+
+// exportChannelName returns the name of the channel, prefixed by its team's name if it is not in
+// the given team, or "" if it no longer exists.
+func exportChannelName(channelID, teamID string, pluginAPI *pluginapi.Client) string {
+	if channelID == "" {
+		return ""
+	}
+
+	channel, err := pluginAPI.Channel.Get(channelID)
+	if err != nil {
+		return ""
+	}
+	if channel.TeamId == teamID {
+		return channel.Name
+	}
+
+	team, err := pluginAPI.Team.Get(channel.TeamId)
+	if err != nil {
+		return ""
+	}
+
+	return team.Name + "/" + channel.Name
+}
+
+// ImportPlaybook returns the playbook described by the export, to be created in the team by the
+// user. References that cannot be resolved, or that the user may not set, are left out, each
+// adding a warning, and the settings depending on them are disabled. The user is always a member
+// of the playbook.
+func ImportPlaybook(export PlaybookExport, teamID, userID string, pluginAPI *pluginapi.Client) (Playbook, []string) {
+	playbook, warnings := importPlaybookSettings(export, teamID, userID, pluginAPI)
+	playbook.MemberIDs = withFirstMember(playbook.MemberIDs, userID)
+
+	return playbook, warnings
+}
+
+// withFirstMember returns the member IDs with the user's first, and only once.
+func withFirstMember(memberIDs []string, userID string) []string {
+	withUser := []string{userID}
+	for _, memberID := range memberIDs {
+		if memberID != userID {
+			withUser = append(withUser, memberID)
+		}
+	}
+
+	return withUser
+}
+
+// DuplicatePlaybook returns a copy of the playbook, to be created in the team by the user. The
+// copy goes through an export, so that the channels of the playbook's team are remapped to the
+// channels of the same name in the given team, and users to the members of that team. References
+// that cannot be remapped are left out, each adding a warning, as are secrets. A copy of a
+// playbook restricted to its members always has the user as a member.
+func DuplicatePlaybook(playbook Playbook, teamID, userID string, pluginAPI *pluginapi.Client) (Playbook, []string) {
+	export := ExportPlaybook(playbook, pluginAPI)
+	export.Title = "Copy of " + playbook.Title
+
+	duplicate, warnings := importPlaybookSettings(export, teamID, userID, pluginAPI)
+	if len(playbook.MemberIDs) > 0 {
+		duplicate.MemberIDs = withFirstMember(duplicate.MemberIDs, userID)
+	}
+	if playbook.WebhookSecret != "" {
+		warnings = append(warnings, "webhook secret: not copied, set a new one to sign the webhook requests")
+	}
+
+	return duplicate, warnings
+}
+
+// importPlaybookSettings returns the playbook described by the export, with the members it lists
+// only. References that cannot be resolved, or that the user may not set, are left out, each
+// adding a warning.
+func importPlaybookSettings(export PlaybookExport, teamID, userID string, pluginAPI *pluginapi.Client) (Playbook, []string) {
+	warnings := []string{}
+	warnf := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	playbook := Playbook{
+		Title:                                export.Title,
+		Description:                          export.Description,
+		TeamID:                               teamID,
+		CreatePublicPlaybookRun:              export.CreatePublicPlaybookRun,
+		ReminderMessageTemplate:              export.ReminderMessageTemplate,
+		ReminderTimerDefaultSeconds:          export.ReminderTimerDefaultSeconds,
+		InviteUsersEnabled:                   export.InviteUsersEnabled,
+		DefaultOwnerEnabled:                  export.DefaultOwnerEnabled,
+		AnnouncementChannelEnabled:           export.AnnouncementChannelEnabled,
+		WebhookOnCreationURL:                 export.WebhookOnCreationURL,
+		WebhookOnCreationEnabled:             export.WebhookOnCreationEnabled,
+		WebhookOnStatusUpdateURL:             export.WebhookOnStatusUpdateURL,
+		WebhookOnStatusUpdateEnabled:         export.WebhookOnStatusUpdateEnabled,
+		MessageOnJoin:                        export.MessageOnJoin,
+		MessageOnJoinEnabled:                 export.MessageOnJoinEnabled,
+		RetrospectiveReminderIntervalSeconds: export.RetrospectiveReminderIntervalSeconds,
+		RetrospectiveTemplate:                export.RetrospectiveTemplate,
+		ExportChannelOnArchiveEnabled:        export.ExportChannelOnArchiveEnabled,
+		SignalAnyKeywords:                    append([]string(nil), export.SignalAnyKeywords...),
+		SignalAnyKeywordsEnabled:             export.SignalAnyKeywordsEnabled,
+		CategorizeChannelEnabled:             export.CategorizeChannelEnabled,
+		SeverityLevels:                       append([]string(nil), export.SeverityLevels...),
+		DefaultSeverity:                      export.DefaultSeverity,
+		StatusWorkflow:                       export.StatusWorkflow.Clone(),
+		ReminderEscalation: ReminderEscalation{
+			BackupAfterMissed:    export.ReminderEscalation.BackupAfterMissed,
+			BroadcastAfterMissed: export.ReminderEscalation.BroadcastAfterMissed,
+		},
+		CustomFields:         CloneCustomFields(export.CustomFields),
+		WebhookSubscriptions: CloneWebhookSubscriptions(export.WebhookSubscriptions),
+		InboundWebhook: InboundWebhook{
+			Enabled:          export.InboundWebhook.Enabled,
+			Format:           export.InboundWebhook.Format,
+			NameField:        export.InboundWebhook.NameField,
+			DescriptionField: export.InboundWebhook.DescriptionField,
+			SeverityField:    export.InboundWebhook.SeverityField,
+			FingerprintField: export.InboundWebhook.FingerprintField,
+		},
+	}
+
+	for _, checklist := range export.Checklists {
+		imported := Checklist{Title: checklist.Title, Items: []ChecklistItem{}}
+		for _, item := range checklist.Items {
+			imported.Items = append(imported.Items, ChecklistItem{
+				Title:           item.Title,
+				Description:     item.Description,
+				Command:         item.Command,
+				DueAfterSeconds: item.DueAfterSeconds,
+				DueRelativeTo:   item.DueRelativeTo,
+
+				CommandTrigger:       item.CommandTrigger,
+				CommandTriggerStatus: item.CommandTriggerStatus,
+				CommandRunAs:         item.CommandRunAs,
+
+				SubItems: importChecklistSubItems(item.SubItems),
+			})
+		}
+		playbook.Checklists = append(playbook.Checklists, imported)
+	}
+	importChecklistItemDependencies(playbook.Checklists, export.Checklists, warnf)
+
+	for i := range playbook.CustomFields {
+		playbook.CustomFields[i].ID = ""
+	}
+
+	// teamUserID returns the ID of the team member with the given username.
+	teamUserID := func(username, setting string) string {
+		user, err := pluginAPI.User.GetByUsername(strings.TrimPrefix(username, "@"))
+		if err != nil {
+			warnf("%s: user @%s not found", setting, username)
+			return ""
+		}
+		if !IsMemberOfTeamID(user.Id, teamID, pluginAPI) {
+			warnf("%s: user @%s is not a member of the team", setting, username)
+			return ""
+		}
+
+		return user.Id
+	}
+
+	for _, username := range export.Members {
+		if memberID := teamUserID(username, "members"); memberID != "" {
+			playbook.MemberIDs = append(playbook.MemberIDs, memberID)
+		}
+	}
+
+	for _, username := range export.InvitedUsers {
+		if invitedID := teamUserID(username, "invited users"); invitedID != "" {
+			playbook.InvitedUserIDs = append(playbook.InvitedUserIDs, invitedID)
+		}
+	}
+
+	for _, name := range export.InvitedGroups {
+		group, err := pluginAPI.Group.GetByName(name)
+		if err != nil {
+			warnf("invited groups: group %s not found", name)
+			continue
+		}
+		if !group.AllowReference {
+			warnf("invited groups: group %s does not allow references", name)
+			continue
+		}
+		playbook.InvitedGroupIDs = append(playbook.InvitedGroupIDs, group.Id)
+	}
+
+	if export.DefaultOwner != "" {
+		playbook.DefaultOwnerID = teamUserID(export.DefaultOwner, "default owner")
+	}
+	if playbook.DefaultOwnerID == "" && playbook.DefaultOwnerEnabled {
+		warnf("default owner: disabled, as the default owner could not be set")
+		playbook.DefaultOwnerEnabled = false
+	}
+
+	if export.ReminderEscalation.BackupUser != "" {
+		playbook.ReminderEscalation.BackupUserID = teamUserID(export.ReminderEscalation.BackupUser, "reminder escalation backup user")
+	}
+	if export.ReminderEscalation.BackupGroup != "" {
+		group, err := pluginAPI.Group.GetByName(export.ReminderEscalation.BackupGroup)
+		switch {
+		case err != nil:
+			warnf("reminder escalation backup group: group %s not found", export.ReminderEscalation.BackupGroup)
+		case !group.AllowReference:
+			warnf("reminder escalation backup group: group %s does not allow references", export.ReminderEscalation.BackupGroup)
+		default:
+			playbook.ReminderEscalation.BackupGroupID = group.Id
+		}
+	}
+	if playbook.ReminderEscalation.BackupAfterMissed > 0 &&
+		playbook.ReminderEscalation.BackupUserID == "" && playbook.ReminderEscalation.BackupGroupID == "" {
+		warnf("reminder escalation: the backup is not notified, as neither the backup user nor group could be set")
+		playbook.ReminderEscalation.BackupAfterMissed = 0
+	}
+
+	if export.InboundWebhook.ServiceUser != "" {
+		serviceUserID := teamUserID(export.InboundWebhook.ServiceUser, "inbound webhook service user")
+		if serviceUserID != "" {
+			if !IsBotOfTeamID(serviceUserID, teamID, pluginAPI) {
+				warnf("inbound webhook service user: user @%s is not a bot", export.InboundWebhook.ServiceUser)
+			} else {
+				playbook.InboundWebhook.ServiceUserID = serviceUserID
+			}
+		}
+	}
+	if playbook.InboundWebhook.Enabled && !playbook.DefaultOwnerEnabled {
+		warnf("inbound webhook: disabled, as it requires a default owner")
+		playbook.InboundWebhook.Enabled = false
+	}
+
+	// postableChannelID returns the ID of the channel with the given name, if the user may post to it.
+	postableChannelID := func(name, setting string) string {
+		channelTeamID, channelName := teamID, name
+		if i := strings.Index(name, "/"); i >= 0 {
+			team, err := pluginAPI.Team.GetByName(name[:i])
+			if err != nil {
+				warnf("%s: team %s not found", setting, name[:i])
+				return ""
+			}
+			channelTeamID, channelName = team.Id, name[i+1:]
+		}
+
+		channel, err := pluginAPI.Channel.GetByName(channelTeamID, channelName, false)
+		if err != nil {
+			warnf("%s: channel %s not found", setting, name)
+			return ""
+		}
+		if !pluginAPI.User.HasPermissionToChannel(userID, channel.Id, model.PERMISSION_CREATE_POST) {
+			warnf("%s: you cannot post to channel %s", setting, name)
+			return ""
+		}
+
+		return channel.Id
+	}
+
+	if export.BroadcastChannel != "" {
+		playbook.BroadcastChannelID = postableChannelID(export.BroadcastChannel, "broadcast channel")
+	}
+
+	if export.AnnouncementChannel != "" {
+		playbook.AnnouncementChannelID = postableChannelID(export.AnnouncementChannel, "announcement channel")
+	}
+	if playbook.AnnouncementChannelID == "" && playbook.AnnouncementChannelEnabled {
+		warnf("announcement channel: disabled, as the announcement channel could not be set")
+		playbook.AnnouncementChannelEnabled = false
+	}
+
+	return playbook, warnings
+}
+
+// MarshalPlaybookExport encodes the export in the given format. YAML exports have the same
+// fields, in the same order, as JSON exports.
+func MarshalPlaybookExport(export PlaybookExport, format PlaybookExportFormat) ([]byte, error) {
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode playbook export")
+	}
+	if format != PlaybookExportFormatYAML {
+		return data, nil
+	}
+
+	// JSON being YAML, decoding it into a MapSlice keeps the order of the fields.
+	var fields yaml.MapSlice
+	if err = yaml.Unmarshal(data, &fields); err != nil {
+		return nil, errors.Wrap(err, "failed to convert playbook export to YAML")
+	}
+
+	data, err = yaml.Marshal(fields)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode playbook export as YAML")
+	}
+
+	return data, nil
+}
+
+// UnmarshalPlaybookExport decodes an export in the given format, checking that its version is
+// supported.
+func UnmarshalPlaybookExport(data []byte, format PlaybookExportFormat) (PlaybookExport, error) {
+	if format == PlaybookExportFormatYAML {
+		var document interface{}
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return PlaybookExport{}, errors.Wrap(err, "failed to decode playbook export as YAML")
+		}
+
+		converted, err := yamlToJSON(document)
+		if err != nil {
+			return PlaybookExport{}, errors.Wrap(err, "failed to decode playbook export as YAML")
+		}
+
+		if data, err = json.Marshal(converted); err != nil {
+			return PlaybookExport{}, errors.Wrap(err, "failed to convert playbook export from YAML")
+		}
+	}
+
+	var export PlaybookExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return PlaybookExport{}, errors.Wrap(err, "failed to decode playbook export")
+	}
+
+	if export.Version != PlaybookExportVersion {
+		return PlaybookExport{}, errors.Errorf("unsupported playbook export version %d: only version %d is supported", export.Version, PlaybookExportVersion)
+	}
+
+	return export, nil
+}
+
+// yamlToJSON converts a decoded YAML document to values that can be encoded as JSON, i.e. with
+// maps keyed by strings.
+func yamlToJSON(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			keyString, ok := key.(string)
+			if !ok {
+				return nil, errors.Errorf("key %v is not a string", key)
+			}
+			convertedItem, err := yamlToJSON(item)
+			if err != nil {
+				return nil, err
+			}
+			converted[keyString] = convertedItem
+		}
+		return converted, nil
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, item := range v {
+			convertedItem, err := yamlToJSON(item)
+			if err != nil {
+				return nil, err
+			}
+			converted[i] = convertedItem
+		}
+		return converted, nil
+	default:
+		return v, nil
+	}
+}
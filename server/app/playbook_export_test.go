@@ -0,0 +1,291 @@
+package app
+
+import (
+	"testing"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportPlaybook(t *testing.T) {
+	pluginAPI := &plugintest.API{}
+	client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+
+	groupName := "oncall"
+	pluginAPI.On("GetUser", "userid1").Return(&model.User{Id: "userid1", Username: "alice"}, nil)
+	pluginAPI.On("GetUser", "botid").Return(&model.User{Id: "botid", Username: "alertbot", IsBot: true}, nil)
+	pluginAPI.On("GetUser", "deleteduserid").Return(nil, &model.AppError{StatusCode: 404})
+	pluginAPI.On("GetGroup", "groupid").Return(&model.Group{Id: "groupid", Name: &groupName}, nil)
+	pluginAPI.On("GetChannel", "channelid").Return(&model.Channel{Id: "channelid", TeamId: "teamid", Name: "incidents"}, nil)
+	pluginAPI.On("GetChannel", "otherchannelid").Return(&model.Channel{Id: "otherchannelid", TeamId: "otherteamid", Name: "announcements"}, nil)
+	pluginAPI.On("GetTeam", "otherteamid").Return(&model.Team{Id: "otherteamid", Name: "company"}, nil)
+
+	playbook := Playbook{
+		ID:     "playbookid",
+		Title:  "Outage",
+		TeamID: "teamid",
+		Checklists: []Checklist{{
+			ID:    "checklistid",
+			Title: "Triage",
+			Items: []ChecklistItem{{
+				ID:       "itemid",
+				Title:    "Page the on-call",
+				State:    ChecklistItemStateClosed,
+				Command:  "/page",
+				SubItems: []ChecklistSubItem{{ID: "subitemid", Title: "Page the DBA", State: ChecklistItemStateClosed}},
+			}},
+		}},
+		MemberIDs:                  []string{"userid1", "deleteduserid"},
+		BroadcastChannelID:         "channelid",
+		InvitedGroupIDs:            []string{"groupid"},
+		DefaultOwnerID:             "userid1",
+		DefaultOwnerEnabled:        true,
+		AnnouncementChannelID:      "otherchannelid",
+		AnnouncementChannelEnabled: true,
+		WebhookSecret:              "secret",
+		CustomFields:               []CustomField{{ID: "fieldid", Name: "Region", Type: CustomFieldTypeText}},
+		InboundWebhook:             InboundWebhook{Enabled: true, Token: "token", ServiceUserID: "botid"},
+	}
+
+	export := ExportPlaybook(playbook, client)
+
+	require.Equal(t, PlaybookExportVersion, export.Version)
+	require.Equal(t, "Outage", export.Title)
+	require.Equal(t, []ExportedChecklist{{
+		Title: "Triage",
+		Items: []ExportedChecklistItem{{Title: "Page the on-call", Command: "/page", SubItems: []string{"Page the DBA"}}},
+	}}, export.Checklists)
+	require.Equal(t, []string{"alice"}, export.Members)
+	require.Equal(t, "incidents", export.BroadcastChannel)
+	require.Equal(t, []string{"oncall"}, export.InvitedGroups)
+	require.Equal(t, "alice", export.DefaultOwner)
+	require.Equal(t, "company/announcements", export.AnnouncementChannel)
+	require.Equal(t, []CustomField{{Name: "Region", Type: CustomFieldTypeText}}, export.CustomFields)
+	require.Equal(t, ExportedInboundWebhook{Enabled: true, ServiceUser: "alertbot"}, export.InboundWebhook)
+	require.Equal(t, "fieldid", playbook.CustomFields[0].ID, "exporting must not modify the playbook")
+}
+
+func TestImportPlaybook(t *testing.T) {
+	setup := func() *pluginapi.Client {
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+
+		notFound := &model.AppError{StatusCode: 404}
+		pluginAPI.On("GetUserByUsername", "alice").Return(&model.User{Id: "aliceid", Username: "alice"}, nil)
+		pluginAPI.On("GetUserByUsername", "bob").Return(&model.User{Id: "bobid", Username: "bob"}, nil)
+		pluginAPI.On("GetUserByUsername", "alertbot").Return(&model.User{Id: "botid", Username: "alertbot", IsBot: true}, nil)
+		pluginAPI.On("GetUserByUsername", mock.Anything).Return(nil, notFound)
+		pluginAPI.On("GetUser", "botid").Return(&model.User{Id: "botid", Username: "alertbot", IsBot: true}, nil)
+		pluginAPI.On("GetUser", "aliceid").Return(&model.User{Id: "aliceid", Username: "alice"}, nil)
+		pluginAPI.On("GetTeamMember", "teamid", "aliceid").Return(&model.TeamMember{}, nil)
+		pluginAPI.On("GetTeamMember", "teamid", "botid").Return(&model.TeamMember{}, nil)
+		pluginAPI.On("GetTeamMember", "teamid", "bobid").Return(nil, notFound)
+		pluginAPI.On("GetGroupByName", "oncall").Return(&model.Group{Id: "groupid", AllowReference: true}, nil)
+		pluginAPI.On("GetGroupByName", "private").Return(&model.Group{Id: "privategroupid"}, nil)
+		pluginAPI.On("GetTeamByName", "company").Return(&model.Team{Id: "otherteamid", Name: "company"}, nil)
+		pluginAPI.On("GetChannelByName", "teamid", "incidents", false).Return(&model.Channel{Id: "channelid"}, nil)
+		pluginAPI.On("GetChannelByName", "otherteamid", "announcements", false).Return(&model.Channel{Id: "otherchannelid"}, nil)
+		pluginAPI.On("GetChannelByName", mock.Anything, mock.Anything, false).Return(nil, notFound)
+		pluginAPI.On("HasPermissionToChannel", "importerid", "channelid", model.PERMISSION_CREATE_POST).Return(true)
+		pluginAPI.On("HasPermissionToChannel", "importerid", "otherchannelid", model.PERMISSION_CREATE_POST).Return(false)
+
+		return client
+	}
+
+	t.Run("resolves references", func(t *testing.T) {
+		export := PlaybookExport{
+			Version:                    PlaybookExportVersion,
+			Title:                      "Outage",
+			Checklists:                 []ExportedChecklist{{Title: "Triage", Items: []ExportedChecklistItem{{Title: "Page the on-call"}}}},
+			Members:                    []string{"alice"},
+			BroadcastChannel:           "incidents",
+			InvitedUsers:               []string{"@alice"},
+			InvitedGroups:              []string{"oncall"},
+			DefaultOwner:               "alice",
+			DefaultOwnerEnabled:        true,
+			CustomFields:               []CustomField{{ID: "fieldid", Name: "Region", Type: CustomFieldTypeText}},
+			InboundWebhook:             ExportedInboundWebhook{Enabled: true, ServiceUser: "alertbot"},
+			AnnouncementChannelEnabled: false,
+		}
+
+		playbook, warnings := ImportPlaybook(export, "teamid", "importerid", setup())
+
+		require.Empty(t, warnings)
+		require.Equal(t, "teamid", playbook.TeamID)
+		require.Equal(t, []Checklist{{Title: "Triage", Items: []ChecklistItem{{Title: "Page the on-call"}}}}, playbook.Checklists)
+		require.Equal(t, []string{"importerid", "aliceid"}, playbook.MemberIDs)
+		require.Equal(t, "channelid", playbook.BroadcastChannelID)
+		require.Equal(t, []string{"aliceid"}, playbook.InvitedUserIDs)
+		require.Equal(t, []string{"groupid"}, playbook.InvitedGroupIDs)
+		require.Equal(t, "aliceid", playbook.DefaultOwnerID)
+		require.Equal(t, "", playbook.CustomFields[0].ID)
+		require.Equal(t, InboundWebhook{Enabled: true, ServiceUserID: "botid"}, playbook.InboundWebhook)
+	})
+
+	t.Run("leaves out unresolved references with warnings", func(t *testing.T) {
+		export := PlaybookExport{
+			Version:                    PlaybookExportVersion,
+			Title:                      "Outage",
+			Members:                    []string{"carol", "bob"},
+			BroadcastChannel:           "missing",
+			InvitedGroups:              []string{"private"},
+			DefaultOwner:               "carol",
+			DefaultOwnerEnabled:        true,
+			AnnouncementChannel:        "company/announcements",
+			AnnouncementChannelEnabled: true,
+			InboundWebhook:             ExportedInboundWebhook{Enabled: true, ServiceUser: "alice"},
+		}
+
+		playbook, warnings := ImportPlaybook(export, "teamid", "importerid", setup())
+
+		require.Equal(t, []string{
+			"members: user @carol not found",
+			"members: user @bob is not a member of the team",
+			"invited groups: group private does not allow references",
+			"default owner: user @carol not found",
+			"default owner: disabled, as the default owner could not be set",
+			"inbound webhook service user: user @alice is not a bot",
+			"inbound webhook: disabled, as it requires a default owner",
+			"broadcast channel: channel missing not found",
+			"announcement channel: you cannot post to channel company/announcements",
+			"announcement channel: disabled, as the announcement channel could not be set",
+		}, warnings)
+		require.Equal(t, []string{"importerid"}, playbook.MemberIDs)
+		require.Empty(t, playbook.BroadcastChannelID)
+		require.Empty(t, playbook.InvitedGroupIDs)
+		require.False(t, playbook.DefaultOwnerEnabled)
+		require.False(t, playbook.AnnouncementChannelEnabled)
+		require.Equal(t, InboundWebhook{}, playbook.InboundWebhook)
+	})
+}
+
+func TestDuplicatePlaybook(t *testing.T) {
+	setup := func() *pluginapi.Client {
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+
+		notFound := &model.AppError{StatusCode: 404}
+		pluginAPI.On("GetUser", "aliceid").Return(&model.User{Id: "aliceid", Username: "alice"}, nil)
+		pluginAPI.On("GetUser", "bobid").Return(&model.User{Id: "bobid", Username: "bob"}, nil)
+		pluginAPI.On("GetChannel", "sourcechannelid").Return(&model.Channel{Id: "sourcechannelid", Name: "incidents", TeamId: "sourceteamid"}, nil)
+		pluginAPI.On("GetChannel", "sourceannouncementsid").Return(&model.Channel{Id: "sourceannouncementsid", Name: "announcements", TeamId: "sourceteamid"}, nil)
+		pluginAPI.On("GetUserByUsername", "alice").Return(&model.User{Id: "aliceid", Username: "alice"}, nil)
+		pluginAPI.On("GetUserByUsername", "bob").Return(&model.User{Id: "bobid", Username: "bob"}, nil)
+		pluginAPI.On("GetTeamMember", "teamid", "aliceid").Return(&model.TeamMember{}, nil)
+		pluginAPI.On("GetTeamMember", "teamid", "bobid").Return(nil, notFound)
+		pluginAPI.On("GetChannelByName", "teamid", "incidents", false).Return(&model.Channel{Id: "channelid"}, nil)
+		pluginAPI.On("GetChannelByName", mock.Anything, mock.Anything, false).Return(nil, notFound)
+		pluginAPI.On("HasPermissionToChannel", "userid", "channelid", model.PERMISSION_CREATE_POST).Return(true)
+
+		return client
+	}
+
+	t.Run("remaps references to the team", func(t *testing.T) {
+		playbook := Playbook{
+			ID:                         "playbookid",
+			Title:                      "Outage",
+			TeamID:                     "sourceteamid",
+			Checklists:                 []Checklist{{ID: "checklistid", Title: "Triage", Items: []ChecklistItem{{Title: "Page the on-call"}}}},
+			MemberIDs:                  []string{"aliceid", "bobid"},
+			BroadcastChannelID:         "sourcechannelid",
+			AnnouncementChannelID:      "sourceannouncementsid",
+			AnnouncementChannelEnabled: true,
+			WebhookSecret:              "secret",
+			SyncPath:                   "outage.yaml",
+		}
+
+		duplicate, warnings := DuplicatePlaybook(playbook, "teamid", "userid", setup())
+
+		require.Equal(t, []string{
+			"members: user @bob is not a member of the team",
+			"announcement channel: channel announcements not found",
+			"announcement channel: disabled, as the announcement channel could not be set",
+			"webhook secret: not copied, set a new one to sign the webhook requests",
+		}, warnings)
+		require.Empty(t, duplicate.ID)
+		require.Equal(t, "Copy of Outage", duplicate.Title)
+		require.Equal(t, "teamid", duplicate.TeamID)
+		require.Equal(t, []Checklist{{Title: "Triage", Items: []ChecklistItem{{Title: "Page the on-call"}}}}, duplicate.Checklists)
+		require.Equal(t, []string{"userid", "aliceid"}, duplicate.MemberIDs)
+		require.Equal(t, "channelid", duplicate.BroadcastChannelID)
+		require.Empty(t, duplicate.AnnouncementChannelID)
+		require.False(t, duplicate.AnnouncementChannelEnabled)
+		require.Empty(t, duplicate.WebhookSecret)
+		require.Empty(t, duplicate.SyncPath)
+
+		duplicate.Checklists[0].Items[0].Title = "Page everyone"
+		require.Equal(t, "Page the on-call", playbook.Checklists[0].Items[0].Title)
+	})
+
+	t.Run("a playbook open to the team stays open", func(t *testing.T) {
+		playbook := Playbook{
+			Title:  "Outage",
+			TeamID: "sourceteamid",
+		}
+
+		duplicate, warnings := DuplicatePlaybook(playbook, "teamid", "userid", setup())
+
+		require.Empty(t, warnings)
+		require.Empty(t, duplicate.MemberIDs)
+	})
+}
+
+func TestMarshalPlaybookExport(t *testing.T) {
+	export := PlaybookExport{
+		Version:        PlaybookExportVersion,
+		Title:          "Outage",
+		Checklists:     []ExportedChecklist{{Title: "Triage", Items: []ExportedChecklistItem{{Title: "Page the on-call", Command: "/page"}}}},
+		Members:        []string{"alice"},
+		SeverityLevels: []string{"High", "Low"},
+		StatusWorkflow: DefaultStatusWorkflow(),
+		CustomFields:   []CustomField{{Name: "Region", Type: CustomFieldTypeSelect, Options: []string{"EU", "US"}}},
+		InboundWebhook: ExportedInboundWebhook{Enabled: true, Format: InboundWebhookFormatAlertmanager},
+	}
+
+	for _, format := range []PlaybookExportFormat{PlaybookExportFormatJSON, PlaybookExportFormatYAML} {
+		t.Run(string(format), func(t *testing.T) {
+			data, err := MarshalPlaybookExport(export, format)
+			require.NoError(t, err)
+
+			decoded, err := UnmarshalPlaybookExport(data, format)
+			require.NoError(t, err)
+			require.Equal(t, export, decoded)
+		})
+	}
+
+	t.Run("yaml keeps the order of the fields", func(t *testing.T) {
+		data, err := MarshalPlaybookExport(export, PlaybookExportFormatYAML)
+		require.NoError(t, err)
+		require.Regexp(t, "^version: 1\ntitle: Outage\n", string(data))
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := UnmarshalPlaybookExport([]byte("version: 2\ntitle: Outage\n"), PlaybookExportFormatYAML)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported playbook export version 2")
+	})
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		_, err := UnmarshalPlaybookExport([]byte("version: [1\n"), PlaybookExportFormatYAML)
+		require.Error(t, err)
+	})
+}
+
+func TestParsePlaybookExportFormat(t *testing.T) {
+	for name, expected := range map[string]PlaybookExportFormat{
+		"":     PlaybookExportFormatJSON,
+		"JSON": PlaybookExportFormatJSON,
+		"yaml": PlaybookExportFormatYAML,
+		"yml":  PlaybookExportFormatYAML,
+	} {
+		format, err := ParsePlaybookExportFormat(name)
+		require.NoError(t, err)
+		require.Equal(t, expected, format)
+	}
+
+	_, err := ParsePlaybookExportFormat("xml")
+	require.Error(t, err)
+}
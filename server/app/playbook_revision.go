@@ -0,0 +1,132 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PlaybookRevision is an immutable snapshot of a playbook, saved each time the playbook is created
+// or updated. Revisions of a playbook are numbered from 1, in the order they were saved.
+type PlaybookRevision struct {
+	PlaybookID string           `json:"playbook_id"`
+	Revision   int64            `json:"revision"`
+	UserID     string           `json:"user_id"` // Empty for the snapshot of a playbook saved before revisions were recorded
+	CreateAt   int64            `json:"create_at"`
+	Changes    []PlaybookChange `json:"changes"`            // Compared to the previous revision
+	Playbook   *Playbook        `json:"playbook,omitempty"` // Left out of revision lists
+}
+
+// PlaybookChange is a setting of a playbook that changed between two revisions, with its old and
+// new values as JSON. The values of secrets are left out.
+type PlaybookChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+// playbookFieldsWithoutChanges are the fields of a playbook not tracked by revisions, as they are
+// either set by the plugin or derived from other fields.
+var playbookFieldsWithoutChanges = map[string]bool{
+	"id":         true,
+	"creator_id": true,
+	"create_at":  true,
+	"update_at":  true,
+	"delete_at":  true,
+	"num_stages": true,
+	"num_steps":  true,
+	"revision":   true,
+
+	"has_webhook_secret":        true,
+	"has_inbound_webhook_token": true,
+}
+
+// redactedSecret is the value of secrets in revisions.
+var redactedSecret = json.RawMessage("null")
+
+// PlaybookSnapshot returns the playbook as saved in its revisions, without its secrets.
+func PlaybookSnapshot(playbook Playbook) Playbook {
+	snapshot := playbook.Clone()
+	snapshot.WebhookSecret = ""
+	snapshot.HasWebhookSecret = false
+	snapshot.InboundWebhook.Token = ""
+	snapshot.HasInboundWebhookToken = false
+
+	return snapshot
+}
+
+// DiffPlaybooks returns the settings that differ between the old and updated versions of a playbook,
+// in the order of the fields of Playbook. Changes of secrets are reported without their values.
+func DiffPlaybooks(old, updated Playbook) ([]PlaybookChange, error) {
+	// Playbooks are compared as JSON, where missing lists are encoded as empty ones.
+	oldFields, err := playbookJSONFields(PlaybookSnapshot(old))
+	if err != nil {
+		return nil, err
+	}
+	updatedFields, err := playbookJSONFields(PlaybookSnapshot(updated))
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []PlaybookChange{}
+	playbookType := reflect.TypeOf(Playbook{})
+	for i := 0; i < playbookType.NumField(); i++ {
+		field := strings.Split(playbookType.Field(i).Tag.Get("json"), ",")[0]
+		if field == "" || field == "-" || playbookFieldsWithoutChanges[field] {
+			continue
+		}
+
+		if !bytes.Equal(oldFields[field], updatedFields[field]) {
+			changes = append(changes, PlaybookChange{Field: field, Old: oldFields[field], New: updatedFields[field]})
+		}
+	}
+
+	if old.WebhookSecret != updated.WebhookSecret {
+		changes = append(changes, PlaybookChange{Field: "webhook_secret", Old: redactedSecret, New: redactedSecret})
+	}
+	if old.InboundWebhook.Token != updated.InboundWebhook.Token {
+		changes = append(changes, PlaybookChange{Field: "inbound_webhook.token", Old: redactedSecret, New: redactedSecret})
+	}
+
+	return changes, nil
+}
+
+// playbookJSONFields returns the JSON encoding of each field of the playbook.
+func playbookJSONFields(playbook Playbook) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(playbook)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode playbook")
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, errors.Wrap(err, "failed to decode playbook fields")
+	}
+
+	return fields, nil
+}
+
+// RestoredPlaybook returns the playbook as it was in the revision, to update the current playbook
+// with. The identity of the current playbook, its timestamps, the file it is synced from and its
+// secrets are kept, as secrets are not saved in revisions.
+func (r PlaybookRevision) RestoredPlaybook(current Playbook) (Playbook, error) {
+	if r.Playbook == nil {
+		return Playbook{}, errors.Errorf("revision %d of playbook %s has no snapshot", r.Revision, r.PlaybookID)
+	}
+
+	restored := r.Playbook.Clone()
+	restored.ID = current.ID
+	restored.TeamID = current.TeamID
+	restored.CreateAt = current.CreateAt
+	restored.UpdateAt = current.UpdateAt
+	restored.DeleteAt = current.DeleteAt
+	restored.Revision = current.Revision
+	restored.WebhookSecret = current.WebhookSecret
+	restored.InboundWebhook.Token = current.InboundWebhook.Token
+	restored.SyncPath = current.SyncPath
+
+	return restored, nil
+}
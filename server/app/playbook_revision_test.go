@@ -0,0 +1,130 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffPlaybooks(t *testing.T) {
+	old := Playbook{
+		ID:             "playbookid",
+		Title:          "Outage",
+		TeamID:         "teamid",
+		UpdateAt:       100,
+		Revision:       1,
+		MemberIDs:      []string{"userid1"},
+		WebhookSecret:  "secret",
+		InboundWebhook: InboundWebhook{Enabled: true, Token: "token"},
+	}
+
+	t.Run("no changes", func(t *testing.T) {
+		updated := old.Clone()
+		updated.UpdateAt = 200
+		updated.Revision = 2
+
+		changes, err := DiffPlaybooks(old, updated)
+		require.NoError(t, err)
+		require.Empty(t, changes)
+	})
+
+	t.Run("missing and empty lists are equal", func(t *testing.T) {
+		updated := old.Clone()
+		updated.Checklists = []Checklist{}
+		updated.InvitedUserIDs = []string{}
+
+		changes, err := DiffPlaybooks(old, updated)
+		require.NoError(t, err)
+		require.Empty(t, changes)
+	})
+
+	t.Run("changed settings in field order", func(t *testing.T) {
+		updated := old.Clone()
+		updated.Title = "Major outage"
+		updated.MemberIDs = []string{"userid1", "userid2"}
+
+		changes, err := DiffPlaybooks(old, updated)
+		require.NoError(t, err)
+		require.Equal(t, []PlaybookChange{
+			{Field: "title", Old: json.RawMessage(`"Outage"`), New: json.RawMessage(`"Major outage"`)},
+			{Field: "member_ids", Old: json.RawMessage(`["userid1"]`), New: json.RawMessage(`["userid1","userid2"]`)},
+		}, changes)
+	})
+
+	t.Run("secrets are left out", func(t *testing.T) {
+		updated := old.Clone()
+		updated.WebhookSecret = "othersecret"
+		updated.InboundWebhook.Token = "othertoken"
+
+		changes, err := DiffPlaybooks(old, updated)
+		require.NoError(t, err)
+		require.Equal(t, []PlaybookChange{
+			{Field: "webhook_secret", Old: redactedSecret, New: redactedSecret},
+			{Field: "inbound_webhook.token", Old: redactedSecret, New: redactedSecret},
+		}, changes)
+	})
+}
+
+func TestPlaybookSnapshot(t *testing.T) {
+	playbook := Playbook{
+		Title:          "Outage",
+		WebhookSecret:  "secret",
+		InboundWebhook: InboundWebhook{Enabled: true, Token: "token"},
+	}
+
+	snapshot := PlaybookSnapshot(playbook)
+	require.Empty(t, snapshot.WebhookSecret)
+	require.Empty(t, snapshot.InboundWebhook.Token)
+	require.True(t, snapshot.InboundWebhook.Enabled)
+	require.Equal(t, "secret", playbook.WebhookSecret, "the snapshot must not modify the playbook")
+}
+
+func TestPlaybookRevision_RestoredPlaybook(t *testing.T) {
+	current := Playbook{
+		ID:             "playbookid",
+		Title:          "Major outage",
+		TeamID:         "teamid",
+		CreateAt:       100,
+		UpdateAt:       300,
+		Revision:       3,
+		WebhookSecret:  "secret",
+		InboundWebhook: InboundWebhook{Enabled: true, Token: "token"},
+	}
+
+	t.Run("restores the settings of the revision", func(t *testing.T) {
+		revision := PlaybookRevision{
+			PlaybookID: "playbookid",
+			Revision:   1,
+			Playbook: &Playbook{
+				ID:             "playbookid",
+				Title:          "Outage",
+				TeamID:         "teamid",
+				CreateAt:       100,
+				UpdateAt:       100,
+				Revision:       1,
+				MemberIDs:      []string{"userid1"},
+				InboundWebhook: InboundWebhook{Enabled: false},
+			},
+		}
+
+		restored, err := revision.RestoredPlaybook(current)
+		require.NoError(t, err)
+		require.Equal(t, Playbook{
+			ID:             "playbookid",
+			Title:          "Outage",
+			TeamID:         "teamid",
+			CreateAt:       100,
+			UpdateAt:       300,
+			Revision:       3,
+			MemberIDs:      []string{"userid1"},
+			WebhookSecret:  "secret",
+			InboundWebhook: InboundWebhook{Enabled: false, Token: "token"},
+		}, restored)
+	})
+
+	t.Run("revision without snapshot", func(t *testing.T) {
+		_, err := PlaybookRevision{PlaybookID: "playbookid", Revision: 1}.RestoredPlaybook(current)
+		require.Error(t, err)
+	})
+}
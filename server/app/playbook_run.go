@@ -2,8 +2,10 @@ package app
 
 import (
 	"encoding/json"
+	"net/url"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/pkg/errors"
@@ -11,6 +13,7 @@ import (
 	"github.com/mattermost/mattermost-plugin-api/cluster"
 )
 
+// Statuses of the default status workflow.
 const (
 	StatusReported = "Reported"
 	StatusActive   = "Active"
@@ -23,42 +26,54 @@ const (
 // NOTE: when adding a column to the db, search for "When adding an Playbook Run column" to see where
 // that column needs to be added in the sqlstore code.
 type PlaybookRun struct {
-	ID                                   string          `json:"id"`
-	Name                                 string          `json:"name"` // Retrieved from playbook run channel
-	Description                          string          `json:"description"`
-	OwnerUserID                          string          `json:"owner_user_id"`
-	ReporterUserID                       string          `json:"reporter_user_id"`
-	TeamID                               string          `json:"team_id"`
-	ChannelID                            string          `json:"channel_id"`
-	CreateAt                             int64           `json:"create_at"` // Retrieved from playbook run channel
-	EndAt                                int64           `json:"end_at"`
-	DeleteAt                             int64           `json:"delete_at"` // Retrieved from playbook run channel
-	ActiveStage                          int             `json:"active_stage"`
-	ActiveStageTitle                     string          `json:"active_stage_title"`
-	PostID                               string          `json:"post_id"`
-	PlaybookID                           string          `json:"playbook_id"`
-	Checklists                           []Checklist     `json:"checklists"`
-	StatusPosts                          []StatusPost    `json:"status_posts"`
-	CurrentStatus                        string          `json:"current_status"`
-	LastStatusUpdateAt                   int64           `json:"last_status_update_at"`
-	ReminderPostID                       string          `json:"reminder_post_id"`
-	PreviousReminder                     time.Duration   `json:"previous_reminder"`
-	BroadcastChannelID                   string          `json:"broadcast_channel_id"`
-	ReminderMessageTemplate              string          `json:"reminder_message_template"`
-	InvitedUserIDs                       []string        `json:"invited_user_ids"`
-	InvitedGroupIDs                      []string        `json:"invited_group_ids"`
-	TimelineEvents                       []TimelineEvent `json:"timeline_events"`
-	DefaultOwnerID                       string          `json:"default_owner_id"`
-	AnnouncementChannelID                string          `json:"announcement_channel_id"`
-	WebhookOnCreationURL                 string          `json:"webhook_on_creation_url"`
-	WebhookOnStatusUpdateURL             string          `json:"webhook_on_status_update_url"`
-	Retrospective                        string          `json:"retrospective"`
-	RetrospectivePublishedAt             int64           `json:"retrospective_published_at"` // The last time a retrospective was published. 0 if never published.
-	RetrospectiveWasCanceled             bool            `json:"retrospective_was_canceled"`
-	RetrospectiveReminderIntervalSeconds int64           `json:"retrospective_reminder_interval_seconds"`
-	MessageOnJoin                        string          `json:"message_on_join"`
-	ExportChannelOnArchiveEnabled        bool            `json:"export_channel_on_archive_enabled"`
-	CategorizeChannelEnabled             bool            `json:"categorize_channel_enabled"`
+	ID                                   string                `json:"id"`
+	Name                                 string                `json:"name"` // Retrieved from playbook run channel
+	Description                          string                `json:"description"`
+	OwnerUserID                          string                `json:"owner_user_id"`
+	ReporterUserID                       string                `json:"reporter_user_id"`
+	TeamID                               string                `json:"team_id"`
+	ChannelID                            string                `json:"channel_id"`
+	CreateAt                             int64                 `json:"create_at"` // Retrieved from playbook run channel
+	EndAt                                int64                 `json:"end_at"`
+	DeleteAt                             int64                 `json:"delete_at"` // Retrieved from playbook run channel
+	ActiveStage                          int                   `json:"active_stage"`
+	ActiveStageTitle                     string                `json:"active_stage_title"`
+	PostID                               string                `json:"post_id"`
+	PlaybookID                           string                `json:"playbook_id"`
+	PlaybookRevision                     int64                 `json:"playbook_revision"` // The revision of the playbook the run was started from
+	Checklists                           []Checklist           `json:"checklists"`
+	StatusPosts                          []StatusPost          `json:"status_posts"`
+	CurrentStatus                        string                `json:"current_status"`
+	LastStatusUpdateAt                   int64                 `json:"last_status_update_at"`
+	ReminderPostID                       string                `json:"reminder_post_id"`
+	PreviousReminder                     time.Duration         `json:"previous_reminder"`
+	ReminderCount                        int                   `json:"reminder_count"`      // The number of status update reminders posted since the last status update
+	ReminderEscalation                   ReminderEscalation    `json:"reminder_escalation"` // Copied from the playbook
+	BroadcastChannelID                   string                `json:"broadcast_channel_id"`
+	ReminderMessageTemplate              string                `json:"reminder_message_template"`
+	InvitedUserIDs                       []string              `json:"invited_user_ids"`
+	InvitedGroupIDs                      []string              `json:"invited_group_ids"`
+	TimelineEvents                       []TimelineEvent       `json:"timeline_events"`
+	DefaultOwnerID                       string                `json:"default_owner_id"`
+	AnnouncementChannelID                string                `json:"announcement_channel_id"`
+	WebhookOnCreationURL                 string                `json:"webhook_on_creation_url"`
+	WebhookOnStatusUpdateURL             string                `json:"webhook_on_status_update_url"`
+	WebhookSecret                        string                `json:"-"` // Copied from the playbook; never serialized, so that it is not sent in webhook payloads
+	Retrospective                        string                `json:"retrospective"`
+	RetrospectivePublishedAt             int64                 `json:"retrospective_published_at"` // The last time a retrospective was published. 0 if never published.
+	RetrospectiveWasCanceled             bool                  `json:"retrospective_was_canceled"`
+	RetrospectiveReminderIntervalSeconds int64                 `json:"retrospective_reminder_interval_seconds"`
+	MessageOnJoin                        string                `json:"message_on_join"`
+	ExportChannelOnArchiveEnabled        bool                  `json:"export_channel_on_archive_enabled"`
+	CategorizeChannelEnabled             bool                  `json:"categorize_channel_enabled"`
+	Severity                             string                `json:"severity"`
+	SeverityLevels                       []string              `json:"severity_levels"` // Copied from the playbook, most severe first
+	StatusWorkflow                       StatusWorkflow        `json:"status_workflow"` // Copied from the playbook
+	CustomFields                         []CustomField         `json:"custom_fields"`   // Copied from the playbook
+	CustomFieldValues                    CustomFieldValues     `json:"custom_field_values"`
+	WebhookSubscriptions                 []WebhookSubscription `json:"webhook_subscriptions"` // Copied from the playbook
+	AlertFingerprint                     string                `json:"alert_fingerprint"`     // Set when started by an alert of the playbook's inbound webhook
+	Version                              int64                 `json:"version"`               // Incremented on every update, to detect concurrent ones
 }
 
 func (i *PlaybookRun) Clone() *PlaybookRun {
@@ -73,6 +88,11 @@ func (i *PlaybookRun) Clone() *PlaybookRun {
 	newPlaybookRun.TimelineEvents = append([]TimelineEvent(nil), i.TimelineEvents...)
 	newPlaybookRun.InvitedUserIDs = append([]string(nil), i.InvitedUserIDs...)
 	newPlaybookRun.InvitedGroupIDs = append([]string(nil), i.InvitedGroupIDs...)
+	newPlaybookRun.SeverityLevels = append([]string(nil), i.SeverityLevels...)
+	newPlaybookRun.StatusWorkflow = i.StatusWorkflow.Clone()
+	newPlaybookRun.CustomFields = CloneCustomFields(i.CustomFields)
+	newPlaybookRun.CustomFieldValues = i.CustomFieldValues.Clone()
+	newPlaybookRun.WebhookSubscriptions = CloneWebhookSubscriptions(i.WebhookSubscriptions)
 
 	return &newPlaybookRun
 }
@@ -90,6 +110,7 @@ func (i *PlaybookRun) MarshalJSON() ([]byte, error) {
 			old.Checklists[j].Items = []ChecklistItem{}
 		}
 	}
+	setBlockedChecklistItems(old.Checklists)
 	if old.StatusPosts == nil {
 		old.StatusPosts = []StatusPost{}
 	}
@@ -102,13 +123,54 @@ func (i *PlaybookRun) MarshalJSON() ([]byte, error) {
 	if old.TimelineEvents == nil {
 		old.TimelineEvents = []TimelineEvent{}
 	}
+	if old.SeverityLevels == nil {
+		old.SeverityLevels = []string{}
+	}
+	if old.StatusWorkflow.States == nil {
+		old.StatusWorkflow.States = []StatusState{}
+	}
+	if old.CustomFields == nil {
+		old.CustomFields = []CustomField{}
+	}
+	for j, field := range old.CustomFields {
+		if field.Options == nil {
+			old.CustomFields[j].Options = []string{}
+		}
+	}
+	if old.CustomFieldValues == nil {
+		old.CustomFieldValues = CustomFieldValues{}
+	}
+	if old.WebhookSubscriptions == nil {
+		old.WebhookSubscriptions = []WebhookSubscription{}
+	}
 
 	return json.Marshal(old)
 }
 
+// SeverityRank returns the position of the run's severity on its scale, counted from the least
+// severe level so that more severe runs rank higher. Runs without a severity rank 0.
+func (i *PlaybookRun) SeverityRank() int {
+	for j, level := range i.SeverityLevels {
+		if level == i.Severity {
+			return len(i.SeverityLevels) - j
+		}
+	}
+
+	return 0
+}
+
+// Workflow returns the run's status workflow, falling back to the default workflow for runs
+// created before workflows were configurable.
+func (i *PlaybookRun) Workflow() StatusWorkflow {
+	if i.StatusWorkflow.IsEmpty() {
+		return DefaultStatusWorkflow()
+	}
+
+	return i.StatusWorkflow.Clone()
+}
+
 func (i *PlaybookRun) IsActive() bool {
-	currentStatus := i.CurrentStatus
-	return currentStatus != StatusResolved && currentStatus != StatusArchived
+	return !i.Workflow().IsResolved(i.CurrentStatus)
 }
 
 func (i *PlaybookRun) ResolvedAt() int64 {
@@ -117,12 +179,13 @@ func (i *PlaybookRun) ResolvedAt() int64 {
 		return i.EndAt
 	}
 
+	workflow := i.Workflow()
 	var resolvedPost *StatusPost
 	for j := len(i.StatusPosts) - 1; j >= 0; j-- {
 		if i.StatusPosts[j].DeleteAt != 0 {
 			continue
 		}
-		if i.StatusPosts[j].Status != StatusResolved && i.StatusPosts[j].Status != StatusArchived {
+		if !workflow.IsResolved(i.StatusPosts[j].Status) {
 			break
 		}
 
@@ -136,6 +199,64 @@ func (i *PlaybookRun) ResolvedAt() int64 {
 	return resolvedPost.CreateAt
 }
 
+// ApplyPlaybookDefaults copies the playbook's checklists and the settings it enables to a new
+// playbook run of it.
+func ApplyPlaybookDefaults(playbookRun *PlaybookRun, pb Playbook) {
+	playbookRun.PlaybookRevision = pb.Revision
+	playbookRun.Checklists = pb.Checklists
+
+	playbookRun.BroadcastChannelID = pb.BroadcastChannelID
+	playbookRun.Description = pb.Description
+	playbookRun.ReminderMessageTemplate = pb.ReminderMessageTemplate
+	playbookRun.PreviousReminder = time.Duration(pb.ReminderTimerDefaultSeconds) * time.Second
+	playbookRun.CategorizeChannelEnabled = pb.CategorizeChannelEnabled
+	playbookRun.SeverityLevels = pb.SeverityLevels
+	playbookRun.Severity = pb.DefaultSeverity
+	playbookRun.StatusWorkflow = pb.StatusWorkflow
+	playbookRun.ReminderEscalation = pb.ReminderEscalation
+	playbookRun.CustomFields = pb.CustomFields
+
+	playbookRun.InvitedUserIDs = []string{}
+	playbookRun.InvitedGroupIDs = []string{}
+	if pb.InviteUsersEnabled {
+		playbookRun.InvitedUserIDs = pb.InvitedUserIDs
+		playbookRun.InvitedGroupIDs = pb.InvitedGroupIDs
+	}
+
+	if pb.DefaultOwnerEnabled {
+		playbookRun.DefaultOwnerID = pb.DefaultOwnerID
+	}
+
+	if pb.AnnouncementChannelEnabled {
+		playbookRun.AnnouncementChannelID = pb.AnnouncementChannelID
+	}
+
+	if pb.WebhookOnCreationEnabled {
+		playbookRun.WebhookOnCreationURL = pb.WebhookOnCreationURL
+	}
+
+	if pb.WebhookOnStatusUpdateEnabled {
+		playbookRun.WebhookOnStatusUpdateURL = pb.WebhookOnStatusUpdateURL
+	}
+
+	playbookRun.WebhookSubscriptions = pb.WebhookSubscriptions
+
+	if pb.WebhookOnCreationEnabled || pb.WebhookOnStatusUpdateEnabled || len(pb.WebhookSubscriptions) > 0 {
+		playbookRun.WebhookSecret = pb.WebhookSecret
+	}
+
+	if pb.MessageOnJoinEnabled {
+		playbookRun.MessageOnJoin = pb.MessageOnJoin
+	}
+
+	if pb.ExportChannelOnArchiveEnabled {
+		playbookRun.ExportChannelOnArchiveEnabled = pb.ExportChannelOnArchiveEnabled
+	}
+
+	playbookRun.RetrospectiveReminderIntervalSeconds = pb.RetrospectiveReminderIntervalSeconds
+	playbookRun.Retrospective = pb.RetrospectiveTemplate
+}
+
 type StatusPost struct {
 	ID       string `json:"id"`
 	Status   string `json:"status"`
@@ -143,7 +264,87 @@ type StatusPost struct {
 	DeleteAt int64  `json:"delete_at"`
 }
 
+// UpdateOptions encapsulates the fields that can be modified on an existing playbook run. Fields
+// left nil are not modified.
+// NOTE: changes made to this should be reflected in the client package.
 type UpdateOptions struct {
+	Name                                 *string `json:"name"`
+	Description                          *string `json:"description"`
+	BroadcastChannelID                   *string `json:"broadcast_channel_id"`
+	ReminderMessageTemplate              *string `json:"reminder_message_template"`
+	WebhookOnCreationURL                 *string `json:"webhook_on_creation_url"`
+	WebhookOnStatusUpdateURL             *string `json:"webhook_on_status_update_url"`
+	MessageOnJoin                        *string `json:"message_on_join"`
+	RetrospectiveReminderIntervalSeconds *int64  `json:"retrospective_reminder_interval_seconds"`
+
+	// CustomFieldValues sets the values of the given custom fields, keyed by custom field ID.
+	// Fields left out are not modified; an empty list clears the field.
+	CustomFieldValues CustomFieldValues `json:"custom_field_values"`
+}
+
+// Validate returns a new, validated update options or returns an error wrapping
+// ErrMalformedPlaybookRun if invalid.
+func (o UpdateOptions) Validate() (UpdateOptions, error) {
+	options := o
+
+	if options.Name != nil {
+		name := strings.TrimSpace(*options.Name)
+		if name == "" {
+			return UpdateOptions{}, errors.Wrap(ErrMalformedPlaybookRun, "name must not be empty")
+		}
+		if utf8.RuneCountInString(name) > model.CHANNEL_DISPLAY_NAME_MAX_RUNES {
+			return UpdateOptions{}, errors.Wrapf(ErrMalformedPlaybookRun, "name must be at most %d characters", model.CHANNEL_DISPLAY_NAME_MAX_RUNES)
+		}
+		options.Name = &name
+	}
+
+	if options.BroadcastChannelID != nil {
+		channelID := strings.TrimSpace(*options.BroadcastChannelID)
+		if channelID != "" && !model.IsValidId(channelID) {
+			return UpdateOptions{}, errors.Wrap(ErrMalformedPlaybookRun, "broadcast channel id is not valid")
+		}
+		options.BroadcastChannelID = &channelID
+	}
+
+	if options.WebhookOnCreationURL != nil {
+		webhookURL := strings.TrimSpace(*options.WebhookOnCreationURL)
+		if err := validateWebhookURL(webhookURL); err != nil {
+			return UpdateOptions{}, errors.Wrapf(ErrMalformedPlaybookRun, "invalid creation webhook URL: %s", err.Error())
+		}
+		options.WebhookOnCreationURL = &webhookURL
+	}
+
+	if options.WebhookOnStatusUpdateURL != nil {
+		webhookURL := strings.TrimSpace(*options.WebhookOnStatusUpdateURL)
+		if err := validateWebhookURL(webhookURL); err != nil {
+			return UpdateOptions{}, errors.Wrapf(ErrMalformedPlaybookRun, "invalid update webhook URL: %s", err.Error())
+		}
+		options.WebhookOnStatusUpdateURL = &webhookURL
+	}
+
+	if options.RetrospectiveReminderIntervalSeconds != nil && *options.RetrospectiveReminderIntervalSeconds < 0 {
+		return UpdateOptions{}, errors.Wrap(ErrMalformedPlaybookRun, "retrospective reminder interval must not be negative")
+	}
+
+	return options, nil
+}
+
+// validateWebhookURL accepts an empty URL (which disables the webhook) or an absolute HTTP(S) URL.
+func validateWebhookURL(webhookURL string) error {
+	if webhookURL == "" {
+		return nil
+	}
+
+	parsedURL, err := url.ParseRequestURI(webhookURL)
+	if err != nil {
+		return err
+	}
+
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return errors.Errorf("protocol is %s; only HTTP and HTTPS are accepted", parsedURL.Scheme)
+	}
+
+	return nil
 }
 
 // StatusUpdateOptions encapsulates the fields that can be set when updating an playbook run's status
@@ -177,6 +378,9 @@ const (
 	UserJoinedLeft         timelineEventType = "user_joined_left"
 	PublishedRetrospective timelineEventType = "published_retrospective"
 	CanceledRetrospective  timelineEventType = "canceled_retrospective"
+	RunUpdated             timelineEventType = "run_updated"
+	SeverityChanged        timelineEventType = "severity_changed"
+	ReminderEscalated      timelineEventType = "reminder_escalated"
 )
 
 type TimelineEvent struct {
@@ -207,6 +411,8 @@ type SQLStatusPost struct {
 	PostID        string
 	Status        string
 	EndAt         int64
+	Resolved      bool // Whether Status is flagged as resolved in the run's status workflow
+	Closed        bool // Whether Status is flagged as closed in the run's status workflow
 }
 
 func (r GetPlaybookRunsResults) Clone() GetPlaybookRunsResults {
@@ -244,6 +450,10 @@ type OwnerInfo struct {
 type DialogState struct {
 	PostID   string `json:"post_id"`
 	ClientID string `json:"client_id"`
+
+	// CustomFieldsPlaybookID is the ID of the playbook whose custom fields are shown in the
+	// dialog, if any. Custom fields are only shown when the dialog offers a single playbook.
+	CustomFieldsPlaybookID string `json:"custom_fields_playbook_id"`
 }
 
 type DialogStateAddToTimeline struct {
@@ -252,9 +462,18 @@ type DialogStateAddToTimeline struct {
 
 // PlaybookRunService is the playbook run service interface.
 type PlaybookRunService interface {
+	// WithExpectedVersion returns the service changing playbook runs only if they are at the given
+	// version, failing with ErrPlaybookRunPreconditionFailed otherwise. Only the first change
+	// stored is checked, and it is not retried if the run is updated concurrently.
+	WithExpectedVersion(version int64) PlaybookRunService
+
 	// GetPlaybookRuns returns filtered playbook runs and the total count before paging.
 	GetPlaybookRuns(requesterInfo RequesterInfo, options PlaybookRunFilterOptions) (*GetPlaybookRunsResults, error)
 
+	// GetChecklistItems returns the checklist items selected by options, of the playbook runs
+	// the requester can view.
+	GetChecklistItems(requesterInfo RequesterInfo, options ChecklistItemFilterOptions) ([]PlaybookRunChecklistItem, error)
+
 	// CreatePlaybookRun creates a new playbook run. userID is the user who initiated the CreatePlaybookRun.
 	CreatePlaybookRun(playbookRun *PlaybookRun, playbook *Playbook, userID string, public bool) (*PlaybookRun, error)
 
@@ -279,6 +498,10 @@ type PlaybookRunService interface {
 	// UpdateStatus updates a playbook run's status.
 	UpdateStatus(playbookRunID, userID string, options StatusUpdateOptions) error
 
+	// UpdatePlaybookRun applies the given options to a playbook run, recording the changes in
+	// its timeline, and returns the updated playbook run.
+	UpdatePlaybookRun(playbookRunID, userID string, options UpdateOptions) (*PlaybookRun, error)
+
 	// GetPlaybookRun gets a playbook run by ID. Returns error if it could not be found.
 	GetPlaybookRun(playbookRunID string) (*PlaybookRun, error)
 
@@ -299,9 +522,19 @@ type PlaybookRunService interface {
 	// to ownerID. Changing to the same ownerID is a no-op.
 	ChangeOwner(playbookRunID string, userID string, ownerID string) error
 
+	// ChangeSeverity processes a request from userID to change the severity of playbookRunID
+	// to one of its severity levels, or to clear it when severity is empty.
+	ChangeSeverity(playbookRunID, userID, severity string) error
+
 	// ModifyCheckedState modifies the state of the specified checklist item
 	// Idempotent, will not perform any actions if the checklist item is already in the specified state
-	ModifyCheckedState(playbookRunID, userID, newState string, checklistNumber int, itemNumber int) error
+	// Closing an item whose prerequisites are still open fails with ErrChecklistItemBlocked, unless
+	// the owner of the run overrides it.
+	ModifyCheckedState(playbookRunID, userID, newState string, checklistNumber int, itemNumber int, override bool) error
+
+	// SkipChecklistItem marks the specified checklist item as skipped for the given reason, which
+	// is required.
+	SkipChecklistItem(playbookRunID, userID string, checklistNumber, itemNumber int, reason string) error
 
 	// ToggleCheckedState checks or unchecks the specified checklist item
 	ToggleCheckedState(playbookRunID, userID string, checklistNumber, itemNumber int) error
@@ -325,6 +558,36 @@ type PlaybookRunService interface {
 	// MoveChecklistItem moves a checklist item from one position to anouther
 	MoveChecklistItem(playbookRunID, userID string, checklistNumber int, itemNumber int, newLocation int) error
 
+	// ApplyChecklistOperations applies the operations to the checklists of the run at once, or
+	// none of them if any fails, posting a single message that sums them up.
+	ApplyChecklistOperations(playbookRunID, userID string, operations []ChecklistOperation) error
+
+	// SetChecklistItemDueDate sets the absolute deadline of a checklist item, or removes it if
+	// dueAt is 0, and schedules the alert for when it passes.
+	SetChecklistItemDueDate(playbookRunID, userID string, checklistNumber, itemNumber int, dueAt int64) error
+
+	// SetChecklistItemDependencies replaces the IDs of the items that must be closed before a
+	// checklist item can be.
+	SetChecklistItemDependencies(playbookRunID, userID string, checklistNumber, itemNumber int, dependsOn []string) error
+
+	// AddChecklistSubItem adds a sub-item to the specified checklist item
+	AddChecklistSubItem(playbookRunID, userID string, checklistNumber, itemNumber int, subItem ChecklistSubItem) error
+
+	// RemoveChecklistSubItem removes a sub-item from the specified checklist item
+	RemoveChecklistSubItem(playbookRunID, userID string, checklistNumber, itemNumber, subItemNumber int) error
+
+	// ModifyChecklistSubItemState modifies the state of the specified sub-item
+	// Idempotent, will not perform any actions if the sub-item is already in the specified state
+	ModifyChecklistSubItemState(playbookRunID, userID, newState string, checklistNumber, itemNumber, subItemNumber int) error
+
+	// SetChecklistSubItemAssignee sets the assignee for the specified sub-item
+	// Idempotent, will not perform any actions if the sub-item is already assigned to assigneeID
+	SetChecklistSubItemAssignee(playbookRunID, userID, assigneeID string, checklistNumber, itemNumber, subItemNumber int) error
+
+	// AddChecklistItemComment posts a comment by userID on the specified checklist item, in the
+	// thread of the item's comments in the run's channel.
+	AddChecklistItemComment(playbookRunID, userID string, checklistNumber, itemNumber int, message string) (ChecklistItemComment, error)
+
 	// GetChecklistItemAutocomplete returns the list of checklist items for playbookRunID to be used in autocomplete
 	GetChecklistItemAutocomplete(playbookRunID string) ([]model.AutocompleteListItem, error)
 
@@ -370,6 +633,25 @@ type PlaybookRunService interface {
 	// CheckAndSendMessageOnJoin checks if userID has viewed channelID and sends
 	// playbooRun.MessageOnJoin if it exists. Returns true if the message was sent.
 	CheckAndSendMessageOnJoin(userID, playbookRunID, channelID string) bool
+
+	// GetWebhookDeliveries returns the webhook deliveries of a playbook run, most recent first,
+	// optionally restricted to the given status.
+	GetWebhookDeliveries(playbookRunID string, status WebhookDeliveryStatus) ([]WebhookDelivery, error)
+
+	// RedeliverWebhook queues a new delivery of the payload of a failed webhook delivery.
+	RedeliverWebhook(playbookRunID, deliveryID string) (*WebhookDelivery, error)
+
+	// StartPlaybookRunFromAlert starts a run of the playbook for an alert received by its inbound
+	// webhook, unless one of the playbook's active runs has the alert's fingerprint. Returns the
+	// run, if any, and whether it was started.
+	StartPlaybookRunFromAlert(playbook Playbook, alert InboundAlert) (*PlaybookRun, bool, error)
+
+	// UpdateStatusFromWebhook posts a status update received by the inbound webhook of the run's
+	// playbook, unless its event ID was already applied to the run. Returns whether it was applied.
+	UpdateStatusFromWebhook(playbookRunID string, webhook InboundWebhook, update InboundStatusUpdate) (bool, error)
+
+	// SetDigestEnabled opts the user in or out of the daily digest, scheduling the next one if in.
+	SetDigestEnabled(userID string, enabled bool) error
 }
 
 // PlaybookRunStore defines the methods the PlaybookRunServiceImpl needs from the interfaceStore.
@@ -380,12 +662,16 @@ type PlaybookRunStore interface {
 	// CreatePlaybookRun creates a new playbook run. If playbook run has an ID, that ID will be used.
 	CreatePlaybookRun(playbookRun *PlaybookRun) (*PlaybookRun, error)
 
-	// UpdatePlaybookRun updates a playbook run.
+	// UpdatePlaybookRun updates a playbook run, provided it is still at the version it was read
+	// at, and increments its version. Returns ErrPlaybookRunVersionConflict otherwise.
 	UpdatePlaybookRun(playbookRun *PlaybookRun) error
 
 	// UpdateStatus updates the status of a playbook run.
 	UpdateStatus(statusPost *SQLStatusPost) error
 
+	// UpdateCustomFieldValues replaces the custom field values of a playbook run.
+	UpdateCustomFieldValues(playbookRunID string, values CustomFieldValues) error
+
 	// GetTimelineEvent returns the timeline event for playbookRunID by the timeline event ID.
 	GetTimelineEvent(playbookRunID, eventID string) (*TimelineEvent, error)
 
@@ -395,12 +681,40 @@ type PlaybookRunStore interface {
 	// UpdateTimelineEvent updates an existing timeline event
 	UpdateTimelineEvent(event *TimelineEvent) error
 
+	// CreateWebhookDelivery inserts the webhook delivery into the DB and returns it with its new ID.
+	CreateWebhookDelivery(delivery *WebhookDelivery) (*WebhookDelivery, error)
+
+	// UpdateWebhookDelivery updates the status and the outcome of the last attempt of a webhook delivery.
+	UpdateWebhookDelivery(delivery *WebhookDelivery) error
+
+	// GetWebhookDelivery returns the webhook delivery with the given ID.
+	GetWebhookDelivery(deliveryID string) (*WebhookDelivery, error)
+
+	// GetWebhookDeliveries returns the webhook deliveries of a playbook run, most recent first,
+	// optionally restricted to the given status.
+	GetWebhookDeliveries(playbookRunID string, status WebhookDeliveryStatus) ([]WebhookDelivery, error)
+
+	// CreateInboundWebhookEvent records that the inbound status update with the given event ID was
+	// applied to the playbook run. Returns ErrDuplicateEntry if it already was.
+	CreateInboundWebhookEvent(playbookRunID, eventID string) error
+
+	// DeleteInboundWebhookEvent forgets the inbound status update with the given event ID.
+	DeleteInboundWebhookEvent(playbookRunID, eventID string) error
+
 	// GetPlaybookRun gets a playbook run by ID.
 	GetPlaybookRun(playbookRunID string) (*PlaybookRun, error)
 
+	// GetChecklistItems returns the checklist items selected by options, of the playbook runs
+	// the requester can view.
+	GetChecklistItems(requesterInfo RequesterInfo, options ChecklistItemFilterOptions) ([]PlaybookRunChecklistItem, error)
+
 	// GetPlaybookRunByChannel gets a playbook run associated with the given channel id.
 	GetPlaybookRunIDForChannel(channelID string) (string, error)
 
+	// GetActivePlaybookRunIDForAlert returns the ID of the most recent active run of the playbook
+	// started by an alert with the given fingerprint. Returns ErrNotFound if there is none.
+	GetActivePlaybookRunIDForAlert(playbookID, fingerprint string) (string, error)
+
 	// GetAllPlaybookRunMembersCount returns the count of all members of the
 	// playbook run associated with the given channel id since the beginning of the
 	// playbook run, excluding bots.
@@ -409,6 +723,16 @@ type PlaybookRunStore interface {
 	// GetOwners returns the owners of the playbook runs selected by options
 	GetOwners(requesterInfo RequesterInfo, options PlaybookRunFilterOptions) ([]OwnerInfo, error)
 
+	// GetOverdueStatusUpdateRuns returns the active playbook runs that userID owns or is a member
+	// of, whose status update reminder went off before now, in milliseconds, without a status update.
+	GetOverdueStatusUpdateRuns(userID string, now int64) ([]OverdueStatusUpdateRun, error)
+
+	// GetUserInfo returns the settings of the user. Returns ErrNotFound if they have none yet.
+	GetUserInfo(userID string) (UserInfo, error)
+
+	// UpsertUserInfo creates or replaces the settings of the user.
+	UpsertUserInfo(info UserInfo) error
+
 	// NukeDB removes all playbook run related data.
 	NukeDB() error
 
@@ -511,6 +835,10 @@ type PlaybookRunFilterOptions struct {
 	// Statuses filters by all statuses in the list (inclusive)
 	Statuses []string
 
+	// ExcludeClosed filters out playbook runs whose current status is flagged as closed in their
+	// status workflow. Defaults to false (no filter).
+	ExcludeClosed bool `url:"exclude_closed,omitempty"`
+
 	// OwnerID filters by owner's Mattermost user ID. Defaults to blank (no filter).
 	OwnerID string `url:"owner_user_id,omitempty"`
 
@@ -526,6 +854,10 @@ type PlaybookRunFilterOptions struct {
 	// Defaults to blank (no filter).
 	PlaybookID string `url:"playbook_id,omitempty"`
 
+	// Severity filters playbook runs that currently have this severity.
+	// Defaults to blank (no filter).
+	Severity string `url:"severity,omitempty"`
+
 	// ActiveGTE filters playbook runs that were active after (or equal) to the unix time given (in millis).
 	// A value of 0 means the filter is ignored (which is the default).
 	ActiveGTE int64 `url:"active_gte,omitempty"`
@@ -541,6 +873,90 @@ type PlaybookRunFilterOptions struct {
 	// StartedLT filters playbook runs that were started before the unix time given (in millis).
 	// A value of 0 means the filter is ignored (which is the default).
 	StartedLT int64 `url:"started_lt,omitempty"`
+
+	// CustomFieldID and CustomFieldValue filter playbook runs that have this value in the custom
+	// field with this ID. They must be given together. Defaults to blank (no filter).
+	CustomFieldID    string `url:"custom_field_id,omitempty"`
+	CustomFieldValue string `url:"custom_field_value,omitempty"`
+}
+
+// ChecklistItemFilterOptions selects the checklist items returned by GetChecklistItems.
+type ChecklistItemFilterOptions struct {
+	// TeamID filters by the team of the items' playbook runs. Defaults to blank (no filter).
+	TeamID string
+
+	// PlaybookRunIDs filters by the playbook runs the items are in. Defaults to empty (no filter).
+	PlaybookRunIDs []string
+
+	// AssigneeID filters by the Mattermost user ID of the items' assignee. Defaults to blank (no filter).
+	AssigneeID string
+
+	// States filters by all the item states in the list (inclusive). Defaults to empty (no filter).
+	States []string
+
+	// ActiveRunsOnly filters out the items of the playbook runs that are no longer active.
+	// Defaults to false (no filter).
+	ActiveRunsOnly bool
+
+	// Sort sorts by SortByDueAt, with the items with no due date last, or by SortByAssignedAt;
+	// defaults to SortByDueAt. Items are otherwise ordered as they are in their playbook runs.
+	Sort SortField
+
+	// Direction orders by ascending or descending, defaulting to ascending.
+	Direction SortDirection
+}
+
+// Validate returns a new, validated filter options or returns an error if invalid.
+func (o ChecklistItemFilterOptions) Validate() (ChecklistItemFilterOptions, error) {
+	options := o
+	options.PlaybookRunIDs = append([]string(nil), o.PlaybookRunIDs...)
+	options.States = append([]string(nil), o.States...)
+
+	options.Sort = SortField(strings.ToLower(string(options.Sort)))
+	switch options.Sort {
+	case SortByDueAt:
+	case SortByAssignedAt:
+	case "": // default
+		options.Sort = SortByDueAt
+	default:
+		return ChecklistItemFilterOptions{}, errors.Errorf("unsupported sort '%s'", options.Sort)
+	}
+
+	options.Direction = SortDirection(strings.ToUpper(string(options.Direction)))
+	switch options.Direction {
+	case DirectionAsc:
+	case DirectionDesc:
+	case "": //default
+		options.Direction = DirectionAsc
+	default:
+		return ChecklistItemFilterOptions{}, errors.Errorf("unsupported direction '%s'", options.Direction)
+	}
+
+	if options.TeamID != "" && !model.IsValidId(options.TeamID) {
+		return ChecklistItemFilterOptions{}, errors.New("bad parameter 'team_id': must be 26 characters or blank")
+	}
+
+	for _, state := range options.States {
+		if !IsValidChecklistItemState(state) {
+			return ChecklistItemFilterOptions{}, errors.Errorf("bad parameter 'state': unknown state '%s'", state)
+		}
+	}
+
+	return options, nil
+}
+
+// PlaybookRunChecklistItem is a checklist item along with its position in its playbook run, and
+// the run's channel.
+type PlaybookRunChecklistItem struct {
+	ChecklistItem
+	PlaybookRunID   string `json:"playbook_run_id"`
+	PlaybookRunName string `json:"playbook_run_name"`
+	TeamID          string `json:"team_id"`
+	ChannelID       string `json:"channel_id"`
+	ChannelName     string `json:"channel_name"`
+	ChecklistNum    int    `json:"checklist_num"`
+	ChecklistTitle  string `json:"checklist_title"`
+	ItemNum         int    `json:"item_num"`
 }
 
 // Clone duplicates the given options.
@@ -569,6 +985,7 @@ func (o PlaybookRunFilterOptions) Validate() (PlaybookRunFilterOptions, error) {
 	case SortByEndAt:
 	case SortByStatus:
 	case SortByLastStatusUpdateAt:
+	case SortBySeverity:
 	case "": // default
 		options.Sort = SortByCreateAt
 	default:
@@ -601,6 +1018,15 @@ func (o PlaybookRunFilterOptions) Validate() (PlaybookRunFilterOptions, error) {
 		return PlaybookRunFilterOptions{}, errors.New("bad parameter 'playbook_id': must be 26 characters or blank")
 	}
 
+	if options.CustomFieldID != "" && !model.IsValidId(options.CustomFieldID) {
+		return PlaybookRunFilterOptions{}, errors.New("bad parameter 'custom_field_id': must be 26 characters or blank")
+	}
+
+	options.CustomFieldValue = strings.TrimSpace(options.CustomFieldValue)
+	if (options.CustomFieldID == "") != (options.CustomFieldValue == "") {
+		return PlaybookRunFilterOptions{}, errors.New("bad parameters 'custom_field_id' and 'custom_field_value': must be given together")
+	}
+
 	if options.ActiveGTE < 0 {
 		options.ActiveGTE = 0
 	}
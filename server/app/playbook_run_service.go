@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -48,6 +49,10 @@ const DialogFieldPlaybookIDKey = "playbookID"
 // DialogFieldNameKey is the key for the playbook run name field used in OpenCreatePlaybookRunDialog.
 const DialogFieldNameKey = "playbookRunName"
 
+// DialogFieldCustomFieldKeyPrefix prefixes the ID of a custom field to build the key of its
+// element in OpenCreatePlaybookRunDialog.
+const DialogFieldCustomFieldKeyPrefix = "customfield_"
+
 // DialogFieldDescriptionKey is the key for the description textarea field used in UpdatePlaybookRunDialog
 const DialogFieldDescriptionKey = "description"
 
@@ -117,6 +122,29 @@ func (s *PlaybookRunServiceImpl) GetPlaybookRuns(requesterInfo RequesterInfo, op
 	}, nil
 }
 
+// GetChecklistItems returns the checklist items selected by options, of the playbook runs the
+// requester can view.
+func (s *PlaybookRunServiceImpl) GetChecklistItems(requesterInfo RequesterInfo, options ChecklistItemFilterOptions) ([]PlaybookRunChecklistItem, error) {
+	items, err := s.store.GetChecklistItems(requesterInfo, options)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get checklist items from the store")
+	}
+	enabledTeams := s.configService.GetConfiguration().EnabledTeams
+
+	if len(enabledTeams) == 0 { // no filter required
+		return items, nil
+	}
+
+	enabledTeamsMap := fromSliceToMap(enabledTeams)
+	filteredItems := []PlaybookRunChecklistItem{}
+	for _, item := range items {
+		if ok := enabledTeamsMap[item.TeamID]; ok {
+			filteredItems = append(filteredItems, item)
+		}
+	}
+	return filteredItems, nil
+}
+
 func fromSliceToMap(slice []string) map[string]bool {
 	result := make(map[string]bool, len(slice))
 	for _, item := range slice {
@@ -157,6 +185,10 @@ func (s *PlaybookRunServiceImpl) broadcastPlaybookRunCreation(playbook *Playbook
 		getPlaybookDetailsURL(*siteURL, team.Name, s.configService.GetManifest().Id, playbook.ID),
 	)
 
+	if playbookRun.Severity != "" {
+		announcementMsg += fmt.Sprintf(" Severity: **%s**.", playbookRun.Severity)
+	}
+
 	if playbookRunChannel.Type == model.CHANNEL_OPEN {
 		announcementMsg += fmt.Sprintf(
 			" Visit the link above for more information or join ~%v to participate.",
@@ -173,9 +205,9 @@ func (s *PlaybookRunServiceImpl) broadcastPlaybookRunCreation(playbook *Playbook
 	return nil
 }
 
-// sendWebhookOnCreation sends a POST request to the creation webhook URL.
-// It blocks until a response is received.
-func (s *PlaybookRunServiceImpl) sendWebhookOnCreation(playbookRun PlaybookRun) error {
+// queueWebhookOnCreation queues a POST request to the creation webhook URL. The request is sent,
+// and retried if it fails, by the webhook delivery jobs.
+func (s *PlaybookRunServiceImpl) queueWebhookOnCreation(playbookRun PlaybookRun) error {
 	siteURL := s.pluginAPI.Configuration.GetConfig().ServiceSettings.SiteURL
 	if siteURL == nil {
 		s.pluginAPI.Log.Warn("cannot send webhook on creation, please set siteURL")
@@ -211,24 +243,10 @@ func (s *PlaybookRunServiceImpl) sendWebhookOnCreation(playbookRun PlaybookRun)
 		return err
 	}
 
-	req, err := http.NewRequest("POST", playbookRun.WebhookOnCreationURL, bytes.NewReader(body))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
+	if _, err = s.queueWebhook(playbookRun.ID, WebhookEventPlaybookRunCreated, playbookRun.WebhookOnCreationURL, body); err != nil {
 		return err
 	}
 
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return errors.Errorf("response code is %d; expected a status code in the 2xx range", resp.StatusCode)
-	}
-
 	return nil
 }
 
@@ -276,7 +294,10 @@ func (s *PlaybookRunServiceImpl) CreatePlaybookRun(playbookRun *PlaybookRun, pb
 	playbookRun.ChannelID = channel.Id
 	playbookRun.CreateAt = now
 	playbookRun.LastStatusUpdateAt = now
-	playbookRun.CurrentStatus = StatusReported
+	if playbookRun.StatusWorkflow.IsEmpty() {
+		playbookRun.StatusWorkflow = DefaultStatusWorkflow()
+	}
+	playbookRun.CurrentStatus = playbookRun.StatusWorkflow.InitialStatus()
 
 	// Start with a blank playbook with one empty checklist if one isn't provided
 	if playbookRun.PlaybookID == "" {
@@ -288,11 +309,16 @@ func (s *PlaybookRunServiceImpl) CreatePlaybookRun(playbookRun *PlaybookRun, pb
 		}
 	}
 
+	playbookRun.SetActiveStage()
+	dueAts := setChecklistItemDueDates(playbookRun, now)
+
 	playbookRun, err = s.store.CreatePlaybookRun(playbookRun)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create playbook run")
 	}
 
+	s.scheduleChecklistItemDueAlerts(playbookRun.ID, dueAts)
+
 	s.telemetry.CreatePlaybookRun(playbookRun, userID, public)
 
 	// Add users to channel after creating playbook run so that all automations trigger.
@@ -419,14 +445,16 @@ func (s *PlaybookRunServiceImpl) CreatePlaybookRun(playbookRun *PlaybookRun, pb
 	playbookRun.TimelineEvents = append(playbookRun.TimelineEvents, *event)
 
 	if playbookRun.WebhookOnCreationURL != "" {
-		go func() {
-			if err = s.sendWebhookOnCreation(*playbookRun); err != nil {
-				s.pluginAPI.Log.Warn("failed to send a POST request to the creation webhook URL", "webhook URL", playbookRun.WebhookOnCreationURL, "error", err)
-				_, _ = s.poster.PostMessage(channel.Id, "Playbook run creation announcement through the outgoing webhook failed. Contact your System Admin for more information.")
-			}
-		}()
+		if err = s.queueWebhookOnCreation(*playbookRun); err != nil {
+			s.pluginAPI.Log.Warn("failed to queue a POST request to the creation webhook URL", "webhook URL", playbookRun.WebhookOnCreationURL, "error", err)
+			_, _ = s.poster.PostMessage(channel.Id, "Playbook run creation announcement through the outgoing webhook failed. Contact your System Admin for more information.")
+		}
 	}
 
+	s.queueWebhookEvent(playbookRun, WebhookEventPlaybookRunCreated, userID, WebhookEventDetails{})
+
+	s.runTriggeredChecklistCommands(playbookRun)
+
 	if playbookRun.PostID == "" {
 		return playbookRun, nil
 	}
@@ -488,7 +516,8 @@ func (s *PlaybookRunServiceImpl) OpenUpdateStatusDialog(playbookRunID string, tr
 		message = currentPlaybookRun.ReminderMessageTemplate
 	}
 
-	dialog, err := s.newUpdatePlaybookRunDialog(currentPlaybookRun.Description, message, currentPlaybookRun.BroadcastChannelID, currentPlaybookRun.CurrentStatus, currentPlaybookRun.PreviousReminder)
+	nextStatuses := currentPlaybookRun.Workflow().NextStatuses(currentPlaybookRun.CurrentStatus)
+	dialog, err := s.newUpdatePlaybookRunDialog(currentPlaybookRun.Description, message, currentPlaybookRun.BroadcastChannelID, currentPlaybookRun.CurrentStatus, nextStatuses, currentPlaybookRun.PreviousReminder)
 	if err != nil {
 		return errors.Wrap(err, "failed to create update status dialog")
 	}
@@ -510,13 +539,13 @@ func (s *PlaybookRunServiceImpl) OpenUpdateStatusDialog(playbookRunID string, tr
 
 func (s *PlaybookRunServiceImpl) OpenAddToTimelineDialog(requesterInfo RequesterInfo, postID, teamID, triggerID string) error {
 	options := PlaybookRunFilterOptions{
-		TeamID:    teamID,
-		MemberID:  requesterInfo.UserID,
-		Sort:      SortByCreateAt,
-		Direction: DirectionDesc,
-		Statuses:  []string{StatusReported, StatusActive, StatusResolved},
-		Page:      0,
-		PerPage:   PerPageDefault,
+		TeamID:        teamID,
+		MemberID:      requesterInfo.UserID,
+		Sort:          SortByCreateAt,
+		Direction:     DirectionDesc,
+		ExcludeClosed: true,
+		Page:          0,
+		PerPage:       PerPageDefault,
 	}
 
 	result, err := s.GetPlaybookRuns(requesterInfo, options)
@@ -598,7 +627,7 @@ func (s *PlaybookRunServiceImpl) AddPostToTimeline(playbookRunID, userID, postID
 		CreatorUserID: userID,
 	}
 
-	if _, err = s.store.CreateTimelineEvent(event); err != nil {
+	if event, err = s.store.CreateTimelineEvent(event); err != nil {
 		return errors.Wrap(err, "failed to create timeline event")
 	}
 
@@ -609,6 +638,10 @@ func (s *PlaybookRunServiceImpl) AddPostToTimeline(playbookRunID, userID, postID
 
 	s.telemetry.AddPostToTimeline(playbookRunModified, userID)
 
+	s.queueWebhookEvent(playbookRunModified, WebhookEventTimelineEventAdded, userID, WebhookEventDetails{
+		TimelineEvent: event,
+	})
+
 	if err = s.sendPlaybookRunToClient(playbookRunID); err != nil {
 		return err
 	}
@@ -661,7 +694,11 @@ func (s *PlaybookRunServiceImpl) broadcastStatusUpdate(statusUpdate string, play
 	duration := timeutils.DurationString(timeutils.GetTimeForMillis(playbookRun.CreateAt), time.Now())
 
 	broadcastedMsg := fmt.Sprintf("# Status Update: [%s](/%s/pl/%s)\n", playbookRunChannel.DisplayName, playbookRunTeam.Name, originalPostID)
-	broadcastedMsg += fmt.Sprintf("By @%s | Duration: %s | Status: %s\n", author.Username, duration, playbookRun.CurrentStatus)
+	broadcastedMsg += fmt.Sprintf("By @%s | Duration: %s | Status: %s", author.Username, duration, playbookRun.CurrentStatus)
+	if playbookRun.Severity != "" {
+		broadcastedMsg += fmt.Sprintf(" | Severity: %s", playbookRun.Severity)
+	}
+	broadcastedMsg += "\n"
 	broadcastedMsg += "***\n"
 	broadcastedMsg += statusUpdate
 
@@ -672,9 +709,9 @@ func (s *PlaybookRunServiceImpl) broadcastStatusUpdate(statusUpdate string, play
 	return nil
 }
 
-// sendWebhookOnUpdateStatus sends a POST request to the status update webhook URL.
-// It blocks until a response is received.
-func (s *PlaybookRunServiceImpl) sendWebhookOnUpdateStatus(playbookRun PlaybookRun) error {
+// queueWebhookOnUpdateStatus queues a POST request to the status update webhook URL. The request
+// is sent, and retried if it fails, by the webhook delivery jobs.
+func (s *PlaybookRunServiceImpl) queueWebhookOnUpdateStatus(playbookRun PlaybookRun) error {
 	siteURL := s.pluginAPI.Configuration.GetConfig().ServiceSettings.SiteURL
 	if siteURL == nil {
 		s.pluginAPI.Log.Warn("cannot send webhook on update, please set siteURL")
@@ -710,34 +747,32 @@ func (s *PlaybookRunServiceImpl) sendWebhookOnUpdateStatus(playbookRun PlaybookR
 		return err
 	}
 
-	req, err := http.NewRequest("POST", playbookRun.WebhookOnStatusUpdateURL, bytes.NewReader(body))
-	if err != nil {
+	if _, err = s.queueWebhook(playbookRun.ID, WebhookEventStatusUpdated, playbookRun.WebhookOnStatusUpdateURL, body); err != nil {
 		return err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return errors.Errorf("response code is %d; expected a status code in the 2xx range", resp.StatusCode)
-	}
-
 	return nil
 }
 
 // UpdateStatus updates a playbook run's status.
 func (s *PlaybookRunServiceImpl) UpdateStatus(playbookRunID, userID string, options StatusUpdateOptions) error {
+	return retryOnVersionConflict(func() error {
+		return s.updateStatus(playbookRunID, userID, options)
+	})
+}
+
+// updateStatus applies UpdateStatus once, see retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) updateStatus(playbookRunID, userID string, options StatusUpdateOptions) error {
 	playbookRunToModify, err := s.store.GetPlaybookRun(playbookRunID)
 	if err != nil {
 		return errors.Wrap(err, "failed to retrieve playbook run")
 	}
 
+	workflow := playbookRunToModify.Workflow()
 	previousStatus := playbookRunToModify.CurrentStatus
+	if !workflow.CanTransition(previousStatus, options.Status) {
+		return errors.Wrapf(ErrMalformedPlaybookRun, "cannot change status from '%s' to '%s'", previousStatus, options.Status)
+	}
 	playbookRunToModify.CurrentStatus = options.Status
 
 	post := model.Post{
@@ -759,10 +794,17 @@ func (s *PlaybookRunServiceImpl) UpdateStatus(playbookRunID, userID string, opti
 		})
 
 	playbookRunToModify.PreviousReminder = options.Reminder
+	playbookRunToModify.ReminderCount = 0
 	playbookRunToModify.Description = options.Description
 	playbookRunToModify.LastStatusUpdateAt = post.CreateAt
 
+	// The reminder post is removed along with the status update, so that removing it does not
+	// update the run a second time.
+	reminderPostID := playbookRunToModify.ReminderPostID
+	playbookRunToModify.ReminderPostID = ""
+
 	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
+		s.retractModificationMessage(&post, err)
 		return errors.Wrap(err, "failed to update playbook run")
 	}
 
@@ -771,6 +813,8 @@ func (s *PlaybookRunServiceImpl) UpdateStatus(playbookRunID, userID string, opti
 		PostID:        post.Id,
 		Status:        options.Status,
 		EndAt:         playbookRunToModify.ResolvedAt(),
+		Resolved:      workflow.IsResolved(options.Status),
+		Closed:        workflow.IsClosed(options.Status),
 	}); err != nil {
 		return errors.Wrap(err, "failed to write status post to store. There is now inconsistent state.")
 	}
@@ -782,14 +826,14 @@ func (s *PlaybookRunServiceImpl) UpdateStatus(playbookRunID, userID string, opti
 	// If we are resolving the playbook run, send the reminder to fill out the retrospective
 	// Also start the recurring reminder if enabled.
 	if playbookRunToModify.RetrospectivePublishedAt == 0 &&
-		options.Status == StatusResolved &&
-		previousStatus != StatusArchived &&
-		previousStatus != StatusResolved &&
+		workflow.IsResolved(options.Status) &&
+		!workflow.IsClosed(options.Status) &&
+		!workflow.IsResolved(previousStatus) &&
 		s.configService.IsAtLeastE10Licensed() {
 		if err = s.postRetrospectiveReminder(playbookRunToModify, true); err != nil {
 			return errors.Wrap(err, "couldn't post retrospective reminder")
 		}
-		s.scheduler.Cancel(RetrospectivePrefix + playbookRunID)
+		s.RemoveReminder(RetrospectivePrefix + playbookRunID)
 		if playbookRunToModify.RetrospectiveReminderIntervalSeconds != 0 {
 			if err = s.SetReminder(RetrospectivePrefix+playbookRunID, time.Duration(playbookRunToModify.RetrospectiveReminderIntervalSeconds)*time.Second); err != nil {
 				return errors.Wrap(err, "failed to set the retrospective reminder for playbook run")
@@ -800,13 +844,13 @@ func (s *PlaybookRunServiceImpl) UpdateStatus(playbookRunID, userID string, opti
 	// Remove pending reminder (if any), even if current reminder was set to "none" (0 minutes)
 	s.RemoveReminder(playbookRunID)
 
-	if options.Reminder != 0 && options.Status != StatusArchived {
+	if options.Reminder != 0 && !workflow.IsClosed(options.Status) {
 		if err = s.SetReminder(playbookRunID, options.Reminder); err != nil {
 			return errors.Wrap(err, "failed to set the reminder for playbook run")
 		}
 	}
 
-	if err = s.removeReminderPost(playbookRunToModify); err != nil {
+	if err = s.deleteReminderPost(reminderPostID); err != nil {
 		return errors.Wrap(err, "failed to remove reminder post")
 	}
 
@@ -830,20 +874,24 @@ func (s *PlaybookRunServiceImpl) UpdateStatus(playbookRunID, userID string, opti
 
 	s.telemetry.UpdateStatus(playbookRunToModify, userID)
 
+	if previousStatus != options.Status {
+		s.runTriggeredChecklistCommands(playbookRunToModify)
+	}
+
 	if err = s.sendPlaybookRunToClient(playbookRunID); err != nil {
 		return err
 	}
 
 	if playbookRunToModify.WebhookOnStatusUpdateURL != "" {
-		go func() {
-			if err := s.sendWebhookOnUpdateStatus(*playbookRunToModify); err != nil {
-				s.pluginAPI.Log.Warn("failed to send a POST request to the update status webhook URL", "webhook URL", playbookRunToModify.WebhookOnStatusUpdateURL, "error", err)
-				_, _ = s.poster.PostMessage(playbookRunToModify.ChannelID, "Playbook run update announcement through the outgoing webhook failed. Contact your System Admin for more information.")
-			}
-		}()
+		if err = s.queueWebhookOnUpdateStatus(*playbookRunToModify); err != nil {
+			s.pluginAPI.Log.Warn("failed to queue a POST request to the update status webhook URL", "webhook URL", playbookRunToModify.WebhookOnStatusUpdateURL, "error", err)
+			_, _ = s.poster.PostMessage(playbookRunToModify.ChannelID, "Playbook run update announcement through the outgoing webhook failed. Contact your System Admin for more information.")
+		}
 	}
 
-	if previousStatus != StatusArchived && options.Status == StatusArchived && playbookRunToModify.ExportChannelOnArchiveEnabled {
+	s.queueWebhookEvent(playbookRunToModify, WebhookEventStatusUpdated, userID, WebhookEventDetails{})
+
+	if !workflow.IsClosed(previousStatus) && workflow.IsClosed(options.Status) && playbookRunToModify.ExportChannelOnArchiveEnabled {
 
 		fileID, err := s.exportChannelToFile(playbookRunToModify.Name, playbookRunToModify.OwnerUserID, playbookRunToModify.ChannelID)
 		if err != nil {
@@ -866,6 +914,210 @@ func (s *PlaybookRunServiceImpl) UpdateStatus(playbookRunID, userID string, opti
 	return nil
 }
 
+// UpdatePlaybookRun applies the non-nil fields of options to the playbook run, records a timeline
+// event for every field that changed, and returns the updated playbook run.
+func (s *PlaybookRunServiceImpl) UpdatePlaybookRun(playbookRunID, userID string, options UpdateOptions) (*PlaybookRun, error) {
+	options, err := options.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var updatedPlaybookRun *PlaybookRun
+	err = retryOnVersionConflict(func() error {
+		updatedPlaybookRun, err = s.updatePlaybookRun(playbookRunID, userID, options)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedPlaybookRun, nil
+}
+
+// updatePlaybookRun applies UpdatePlaybookRun once, with validated options, see
+// retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) updatePlaybookRun(playbookRunID, userID string, options UpdateOptions) (*PlaybookRun, error) {
+	playbookRunToModify, err := s.store.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve playbook run")
+	}
+
+	var changes []string
+
+	oldName := playbookRunToModify.Name
+	renamed := false
+	if options.Name != nil && *options.Name != playbookRunToModify.Name {
+		changes = append(changes, fmt.Sprintf("renamed the run from **%s** to **%s**", playbookRunToModify.Name, *options.Name))
+		playbookRunToModify.Name = *options.Name
+		renamed = true
+	}
+
+	if options.Description != nil && *options.Description != playbookRunToModify.Description {
+		changes = append(changes, "updated the description")
+		playbookRunToModify.Description = *options.Description
+	}
+
+	if options.BroadcastChannelID != nil && *options.BroadcastChannelID != playbookRunToModify.BroadcastChannelID {
+		if *options.BroadcastChannelID == "" {
+			changes = append(changes, "removed the broadcast channel")
+		} else {
+			changes = append(changes, "changed the broadcast channel")
+		}
+		playbookRunToModify.BroadcastChannelID = *options.BroadcastChannelID
+	}
+
+	if options.ReminderMessageTemplate != nil && *options.ReminderMessageTemplate != playbookRunToModify.ReminderMessageTemplate {
+		changes = append(changes, "updated the status update template")
+		playbookRunToModify.ReminderMessageTemplate = *options.ReminderMessageTemplate
+	}
+
+	if options.WebhookOnCreationURL != nil && *options.WebhookOnCreationURL != playbookRunToModify.WebhookOnCreationURL {
+		changes = append(changes, "changed the creation webhook URL")
+		playbookRunToModify.WebhookOnCreationURL = *options.WebhookOnCreationURL
+	}
+
+	if options.WebhookOnStatusUpdateURL != nil && *options.WebhookOnStatusUpdateURL != playbookRunToModify.WebhookOnStatusUpdateURL {
+		changes = append(changes, "changed the status update webhook URL")
+		playbookRunToModify.WebhookOnStatusUpdateURL = *options.WebhookOnStatusUpdateURL
+	}
+
+	if options.MessageOnJoin != nil && *options.MessageOnJoin != playbookRunToModify.MessageOnJoin {
+		changes = append(changes, "updated the welcome message")
+		playbookRunToModify.MessageOnJoin = *options.MessageOnJoin
+	}
+
+	retrospectiveIntervalChanged := false
+	if options.RetrospectiveReminderIntervalSeconds != nil && *options.RetrospectiveReminderIntervalSeconds != playbookRunToModify.RetrospectiveReminderIntervalSeconds {
+		interval := time.Duration(*options.RetrospectiveReminderIntervalSeconds) * time.Second
+		if interval == 0 {
+			changes = append(changes, "disabled the retrospective reminder")
+		} else {
+			changes = append(changes, fmt.Sprintf("set the retrospective reminder interval to **%s**", timeutils.DurationString(time.Time{}, time.Time{}.Add(interval))))
+		}
+		playbookRunToModify.RetrospectiveReminderIntervalSeconds = *options.RetrospectiveReminderIntervalSeconds
+		retrospectiveIntervalChanged = true
+	}
+
+	customFieldValuesChanged := false
+	if options.CustomFieldValues != nil {
+		var customFieldValues CustomFieldValues
+		customFieldValues, err = NormalizeCustomFieldValues(playbookRunToModify.CustomFields, options.CustomFieldValues)
+		if err != nil {
+			return nil, err
+		}
+
+		newValues := playbookRunToModify.CustomFieldValues.Clone()
+		if newValues == nil {
+			newValues = CustomFieldValues{}
+		}
+
+		// Walk the fields rather than the map to record the changes in a stable order.
+		for _, field := range playbookRunToModify.CustomFields {
+			if _, ok := options.CustomFieldValues[field.ID]; !ok {
+				continue
+			}
+
+			values := customFieldValues[field.ID]
+			if sameCustomFieldValues(values, newValues[field.ID]) {
+				continue
+			}
+
+			if len(values) == 0 {
+				if field.Required {
+					return nil, errors.Wrapf(ErrMalformedPlaybookRun, "custom field %s is required", field.Name)
+				}
+				changes = append(changes, fmt.Sprintf("cleared **%s**", field.Name))
+				delete(newValues, field.ID)
+			} else {
+				changes = append(changes, fmt.Sprintf("set **%s** to **%s**", field.Name, s.customFieldValuesDisplay(field, values)))
+				newValues[field.ID] = values
+			}
+			customFieldValuesChanged = true
+		}
+
+		playbookRunToModify.CustomFieldValues = newValues
+	}
+
+	if len(changes) == 0 {
+		return playbookRunToModify, nil
+	}
+
+	// The run's name is the display name of its channel, so rename the channel before storing the
+	// run, as it is the step most likely to be rejected, and restore its name if storing fails.
+	if renamed {
+		if err = s.renamePlaybookRunChannel(playbookRunToModify.ChannelID, playbookRunToModify.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
+		if renamed {
+			if err2 := s.renamePlaybookRunChannel(playbookRunToModify.ChannelID, oldName); err2 != nil {
+				s.logger.Warnf("failed to restore the channel name of playbook run %s: %v", playbookRunID, err2)
+			}
+		}
+		return nil, errors.Wrap(err, "failed to update playbook run")
+	}
+
+	if customFieldValuesChanged {
+		if err = s.store.UpdateCustomFieldValues(playbookRunID, playbookRunToModify.CustomFieldValues); err != nil {
+			return nil, errors.Wrap(err, "failed to update custom field values")
+		}
+	}
+
+	// A pending retrospective reminder was scheduled with the previous interval.
+	if retrospectiveIntervalChanged && !playbookRunToModify.IsActive() && playbookRunToModify.RetrospectivePublishedAt == 0 {
+		s.RemoveReminder(RetrospectivePrefix + playbookRunID)
+		if playbookRunToModify.RetrospectiveReminderIntervalSeconds != 0 {
+			if err = s.SetReminder(RetrospectivePrefix+playbookRunID, time.Duration(playbookRunToModify.RetrospectiveReminderIntervalSeconds)*time.Second); err != nil {
+				return nil, errors.Wrap(err, "failed to reschedule retrospective reminder")
+			}
+		}
+	}
+
+	now := model.GetMillis()
+	for _, change := range changes {
+		event := &TimelineEvent{
+			PlaybookRunID: playbookRunID,
+			CreateAt:      now,
+			EventAt:       now,
+			EventType:     RunUpdated,
+			Summary:       change,
+			SubjectUserID: userID,
+		}
+
+		if _, err = s.store.CreateTimelineEvent(event); err != nil {
+			return nil, errors.Wrap(err, "failed to create timeline event")
+		}
+	}
+
+	if err = s.sendPlaybookRunToClient(playbookRunID); err != nil {
+		return nil, err
+	}
+
+	return s.store.GetPlaybookRun(playbookRunID)
+}
+
+// renamePlaybookRunChannel changes the display name of the playbook run's channel.
+func (s *PlaybookRunServiceImpl) renamePlaybookRunChannel(channelID, name string) error {
+	channel, err := s.pluginAPI.Channel.Get(channelID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get playbook run channel")
+	}
+
+	channel.DisplayName = name
+	if err = s.pluginAPI.Channel.Update(channel); err != nil {
+		if appErr, ok := err.(*model.AppError); ok &&
+			(appErr.Id == "model.channel.is_valid.display_name.app_error" ||
+				appErr.Id == "model.channel.is_valid.2_or_more.app_error") {
+			return ErrChannelDisplayNameInvalid
+		}
+		return errors.Wrapf(err, "failed to update playbook run channel")
+	}
+
+	return nil
+}
+
 func (s *PlaybookRunServiceImpl) exportChannelToFile(playbookRunName string, ownerUserID string, channelID string) (string, error) {
 	// set url and query string
 	exportPluginURL := fmt.Sprintf("plugins/com.mattermost.plugin-channel-export/api/v1/export?format=csv&channel_id=%s", channelID)
@@ -1030,6 +1282,13 @@ func (s *PlaybookRunServiceImpl) IsOwner(playbookRunID, userID string) bool {
 // ChangeOwner processes a request from userID to change the owner for playbookRunID
 // to ownerID. Changing to the same ownerID is a no-op.
 func (s *PlaybookRunServiceImpl) ChangeOwner(playbookRunID, userID, ownerID string) error {
+	return retryOnVersionConflict(func() error {
+		return s.changeOwner(playbookRunID, userID, ownerID)
+	})
+}
+
+// changeOwner applies ChangeOwner once, see retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) changeOwner(playbookRunID, userID, ownerID string) error {
 	playbookRunToModify, err := s.store.GetPlaybookRun(playbookRunID)
 	if err != nil {
 		return err
@@ -1048,6 +1307,7 @@ func (s *PlaybookRunServiceImpl) ChangeOwner(playbookRunID, userID, ownerID stri
 		return errors.Wrapf(err, "failed to to resolve user %s", ownerID)
 	}
 
+	previousOwnerID := playbookRunToModify.OwnerUserID
 	playbookRunToModify.OwnerUserID = ownerID
 	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
 		return errors.Wrapf(err, "failed to update playbook run")
@@ -1077,6 +1337,78 @@ func (s *PlaybookRunServiceImpl) ChangeOwner(playbookRunID, userID, ownerID stri
 
 	s.telemetry.ChangeOwner(playbookRunToModify, userID)
 
+	s.queueWebhookEvent(playbookRunToModify, WebhookEventOwnerChanged, userID, WebhookEventDetails{
+		PreviousOwnerUserID: previousOwnerID,
+	})
+
+	if err = s.sendPlaybookRunToClient(playbookRunID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ChangeSeverity processes a request from userID to change the severity of playbookRunID to
+// severity, matched case-insensitively against the run's severity levels. An empty severity
+// clears it. Changing to the current severity is a no-op.
+func (s *PlaybookRunServiceImpl) ChangeSeverity(playbookRunID, userID, severity string) error {
+	return retryOnVersionConflict(func() error {
+		return s.changeSeverity(playbookRunID, userID, severity)
+	})
+}
+
+// changeSeverity applies ChangeSeverity once, see retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) changeSeverity(playbookRunID, userID, severity string) error {
+	playbookRunToModify, err := s.store.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		return err
+	}
+
+	newSeverity := ""
+	if severity != "" {
+		if len(playbookRunToModify.SeverityLevels) == 0 {
+			return errors.Wrap(ErrMalformedPlaybookRun, "playbook run has no severity levels")
+		}
+
+		newSeverity = FindSeverityLevel(playbookRunToModify.SeverityLevels, severity)
+		if newSeverity == "" {
+			return errors.Wrapf(ErrMalformedPlaybookRun, "severity must be one of: %s",
+				strings.Join(playbookRunToModify.SeverityLevels, ", "))
+		}
+	}
+
+	oldSeverity := playbookRunToModify.Severity
+	if oldSeverity == newSeverity {
+		return nil
+	}
+
+	playbookRunToModify.Severity = newSeverity
+	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
+		return errors.Wrapf(err, "failed to update playbook run")
+	}
+
+	mainChannelID := playbookRunToModify.ChannelID
+	modifyMessage := fmt.Sprintf("changed the severity from **%s** to **%s**.",
+		severityDisplayName(oldSeverity), severityDisplayName(newSeverity))
+	post, err := s.modificationMessage(userID, mainChannelID, modifyMessage)
+	if err != nil {
+		return err
+	}
+
+	event := &TimelineEvent{
+		PlaybookRunID: playbookRunID,
+		CreateAt:      post.CreateAt,
+		EventAt:       post.CreateAt,
+		EventType:     SeverityChanged,
+		Summary:       fmt.Sprintf("%s to %s", severityDisplayName(oldSeverity), severityDisplayName(newSeverity)),
+		PostID:        post.Id,
+		SubjectUserID: userID,
+	}
+
+	if _, err = s.store.CreateTimelineEvent(event); err != nil {
+		return errors.Wrap(err, "failed to create timeline event")
+	}
+
 	if err = s.sendPlaybookRunToClient(playbookRunID); err != nil {
 		return err
 	}
@@ -1084,9 +1416,45 @@ func (s *PlaybookRunServiceImpl) ChangeOwner(playbookRunID, userID, ownerID stri
 	return nil
 }
 
+// severityDisplayName returns the severity as shown to users, naming the absence of one.
+func severityDisplayName(severity string) string {
+	if severity == "" {
+		return "none"
+	}
+
+	return severity
+}
+
 // ModifyCheckedState checks or unchecks the specified checklist item. Idempotent, will not perform
-// any action if the checklist item is already in the given checked state
-func (s *PlaybookRunServiceImpl) ModifyCheckedState(playbookRunID, userID, newState string, checklistNumber, itemNumber int) error {
+// any action if the checklist item is already in the given checked state. Only the owner of the
+// run can override the open prerequisites of an item to close it. Items are skipped with
+// SkipChecklistItem instead, as skipping requires a reason.
+func (s *PlaybookRunServiceImpl) ModifyCheckedState(playbookRunID, userID, newState string, checklistNumber, itemNumber int, override bool) error {
+	if newState == ChecklistItemStateSkipped {
+		return errors.Wrap(ErrMalformedPlaybookRun, "a reason is required to skip a checklist item")
+	}
+
+	return retryOnVersionConflict(func() error {
+		return s.modifyCheckedState(playbookRunID, userID, newState, checklistNumber, itemNumber, override, "")
+	})
+}
+
+// SkipChecklistItem marks the specified checklist item as skipped, as it did not apply to the run
+// for the given reason. Idempotent, will not perform any action if the item is already skipped.
+func (s *PlaybookRunServiceImpl) SkipChecklistItem(playbookRunID, userID string, checklistNumber, itemNumber int, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.Wrap(ErrMalformedPlaybookRun, "a reason is required to skip a checklist item")
+	}
+
+	return retryOnVersionConflict(func() error {
+		return s.modifyCheckedState(playbookRunID, userID, ChecklistItemStateSkipped, checklistNumber, itemNumber, false, reason)
+	})
+}
+
+// modifyCheckedState moves the specified checklist item to newState, recording the reason the
+// item is skipped for, if it is.
+func (s *PlaybookRunServiceImpl) modifyCheckedState(playbookRunID, userID, newState string, checklistNumber, itemNumber int, override bool, skipReason string) error {
 	playbookRunToModify, err := s.checklistItemParamsVerify(playbookRunID, userID, checklistNumber, itemNumber)
 	if err != nil {
 		return err
@@ -1101,27 +1469,55 @@ func (s *PlaybookRunServiceImpl) ModifyCheckedState(playbookRunID, userID, newSt
 		return nil
 	}
 
+	overridden := false
+	if newState == ChecklistItemStateClosed {
+		if prerequisites := openPrerequisites(playbookRunToModify.Checklists, itemToCheck); len(prerequisites) > 0 {
+			if !override {
+				return errors.Wrapf(ErrChecklistItemBlocked, "item '%s' depends on open item '%s'", itemToCheck.Title, prerequisites[0].Title)
+			}
+			if playbookRunToModify.OwnerUserID != userID {
+				return errors.Wrap(ErrNoPermissions, "only the owner can override the prerequisites of an item")
+			}
+			overridden = true
+		}
+	}
+
 	// Send modification message before the actual modification because we need the postID
 	// from the notification message.
 	mainChannelID := playbookRunToModify.ChannelID
 	modifyMessage := fmt.Sprintf("checked off checklist item **%v**", stripmd.Strip(itemToCheck.Title))
+	if overridden {
+		modifyMessage += ", overriding its open prerequisites"
+	}
 	if newState == ChecklistItemStateOpen {
 		modifyMessage = fmt.Sprintf("unchecked checklist item **%v**", stripmd.Strip(itemToCheck.Title))
 	}
+	if newState == ChecklistItemStateSkipped {
+		modifyMessage = fmt.Sprintf("skipped checklist item **%v**: %s", stripmd.Strip(itemToCheck.Title), skipReason)
+	}
 	post, err := s.modificationMessage(userID, mainChannelID, modifyMessage)
 	if err != nil {
 		return err
 	}
 
+	previousState := itemToCheck.State
 	itemToCheck.State = newState
 	itemToCheck.StateModified = model.GetMillis()
 	itemToCheck.StateModifiedPostID = post.Id
+	itemToCheck.SkipReason = skipReason
 	playbookRunToModify.Checklists[checklistNumber].Items[itemNumber] = itemToCheck
 
+	// Closing the item may start the next stage, and with it the deadlines of its items.
+	playbookRunToModify.SetActiveStage()
+	dueAts := setChecklistItemDueDates(playbookRunToModify, itemToCheck.StateModified)
+
 	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
+		s.retractModificationMessage(post, err)
 		return errors.Wrapf(err, "failed to update playbook run, is now in inconsistent state")
 	}
 
+	s.scheduleChecklistItemDueAlerts(playbookRunID, dueAts)
+
 	s.telemetry.ModifyCheckedState(playbookRunID, userID, itemToCheck, playbookRunToModify.OwnerUserID == userID)
 
 	event := &TimelineEvent{
@@ -1138,6 +1534,17 @@ func (s *PlaybookRunServiceImpl) ModifyCheckedState(playbookRunID, userID, newSt
 		return errors.Wrap(err, "failed to create timeline event")
 	}
 
+	s.queueWebhookEvent(playbookRunToModify, WebhookEventChecklistItemStateChanged, userID, WebhookEventDetails{
+		ChecklistItem: &WebhookChecklistItem{
+			ChecklistNum:       checklistNumber,
+			ItemNum:            itemNumber,
+			PreviousState:      previousState,
+			PreviousAssigneeID: itemToCheck.AssigneeID,
+		},
+	})
+
+	s.runTriggeredChecklistCommands(playbookRunToModify)
+
 	if err = s.sendPlaybookRunToClient(playbookRunID); err != nil {
 		return err
 	}
@@ -1162,12 +1569,19 @@ func (s *PlaybookRunServiceImpl) ToggleCheckedState(playbookRunID, userID string
 		newState = ChecklistItemStateClosed
 	}
 
-	return s.ModifyCheckedState(playbookRunID, userID, newState, checklistNumber, itemNumber)
+	return s.ModifyCheckedState(playbookRunID, userID, newState, checklistNumber, itemNumber, false)
 }
 
 // SetAssignee sets the assignee for the specified checklist item
 // Idempotent, will not perform any actions if the checklist item is already assigned to assigneeID
 func (s *PlaybookRunServiceImpl) SetAssignee(playbookRunID, userID, assigneeID string, checklistNumber, itemNumber int) error {
+	return retryOnVersionConflict(func() error {
+		return s.setAssignee(playbookRunID, userID, assigneeID, checklistNumber, itemNumber)
+	})
+}
+
+// setAssignee applies SetAssignee once, see retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) setAssignee(playbookRunID, userID, assigneeID string, checklistNumber, itemNumber int) error {
 	playbookRunToModify, err := s.checklistItemParamsVerify(playbookRunID, userID, checklistNumber, itemNumber)
 	if err != nil {
 		return err
@@ -1211,12 +1625,14 @@ func (s *PlaybookRunServiceImpl) SetAssignee(playbookRunID, userID, assigneeID s
 		return err
 	}
 
+	previousAssigneeID := itemToCheck.AssigneeID
 	itemToCheck.AssigneeID = assigneeID
 	itemToCheck.AssigneeModified = model.GetMillis()
 	itemToCheck.AssigneeModifiedPostID = post.Id
 	playbookRunToModify.Checklists[checklistNumber].Items[itemNumber] = itemToCheck
 
 	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
+		s.retractModificationMessage(post, err)
 		return errors.Wrapf(err, "failed to update playbook run; it is now in an inconsistent state")
 	}
 
@@ -1236,6 +1652,15 @@ func (s *PlaybookRunServiceImpl) SetAssignee(playbookRunID, userID, assigneeID s
 		return errors.Wrap(err, "failed to create timeline event")
 	}
 
+	s.queueWebhookEvent(playbookRunToModify, WebhookEventChecklistItemAssigneeChanged, userID, WebhookEventDetails{
+		ChecklistItem: &WebhookChecklistItem{
+			ChecklistNum:       checklistNumber,
+			ItemNum:            itemNumber,
+			PreviousState:      itemToCheck.State,
+			PreviousAssigneeID: previousAssigneeID,
+		},
+	})
+
 	if err = s.sendPlaybookRunToClient(playbookRunID); err != nil {
 		return err
 	}
@@ -1277,9 +1702,20 @@ func (s *PlaybookRunServiceImpl) RunChecklistItemSlashCommand(playbookRunID, use
 		return "", errors.Wrap(err, "failed to run slash command")
 	}
 
-	// Record the last (successful) run time.
-	playbookRun.Checklists[checklistNumber].Items[itemNumber].CommandLastRun = model.GetMillis()
-	if err = s.store.UpdatePlaybookRun(playbookRun); err != nil {
+	// Record the last (successful) run time, in the run as it is now that the command ran.
+	lastRun := model.GetMillis()
+	err = retryOnVersionConflict(func() error {
+		if playbookRun, err = s.store.GetPlaybookRun(playbookRunID); err != nil {
+			return errors.Wrap(err, "failed to retrieve playbook run")
+		}
+		if !IsValidChecklistItemIndex(playbookRun.Checklists, checklistNumber, itemNumber) {
+			return nil
+		}
+
+		playbookRun.Checklists[checklistNumber].Items[itemNumber].CommandLastRun = lastRun
+		return s.store.UpdatePlaybookRun(playbookRun)
+	})
+	if err != nil {
 		return "", errors.Wrapf(err, "failed to update playbook run recording run of slash command")
 	}
 
@@ -1308,17 +1744,48 @@ func (s *PlaybookRunServiceImpl) RunChecklistItemSlashCommand(playbookRunID, use
 
 // AddChecklistItem adds an item to the specified checklist
 func (s *PlaybookRunServiceImpl) AddChecklistItem(playbookRunID, userID string, checklistNumber int, checklistItem ChecklistItem) error {
+	return retryOnVersionConflict(func() error {
+		return s.addChecklistItem(playbookRunID, userID, checklistNumber, checklistItem)
+	})
+}
+
+// addChecklistItem applies AddChecklistItem once, see retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) addChecklistItem(playbookRunID, userID string, checklistNumber int, checklistItem ChecklistItem) error {
 	playbookRunToModify, err := s.checklistParamsVerify(playbookRunID, userID, checklistNumber)
 	if err != nil {
 		return err
 	}
 
+	if err = checklistItem.ValidateDue(); err != nil {
+		return errors.Wrapf(err, "invalid checklist item due date")
+	}
+
+	if err = checklistItem.ValidateCommandTrigger(playbookRunToModify.Workflow()); err != nil {
+		return errors.Wrapf(err, "invalid checklist item command trigger")
+	}
+
+	if checklistItem.ID == "" {
+		checklistItem.ID = model.NewId()
+	}
+
 	playbookRunToModify.Checklists[checklistNumber].Items = append(playbookRunToModify.Checklists[checklistNumber].Items, checklistItem)
+	if err = ValidateChecklistItemDependencies(playbookRunToModify.Checklists); err != nil {
+		return errors.Wrapf(err, "invalid checklist item dependencies")
+	}
+
+	playbookRunToModify.SetActiveStage()
+	dueAts := setChecklistItemDueDates(playbookRunToModify, model.GetMillis())
+	if checklistItem.DueAt != 0 {
+		dueAts = append(dueAts, checklistItem.DueAt)
+	}
 
 	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
 		return errors.Wrapf(err, "failed to update playbook run")
 	}
 
+	s.scheduleChecklistItemDueAlerts(playbookRunID, dueAts)
+	s.runTriggeredChecklistCommands(playbookRunToModify)
+
 	s.poster.PublishWebsocketEventToChannel(playbookRunUpdatedWSEvent, playbookRunToModify, playbookRunToModify.ChannelID)
 	s.telemetry.AddTask(playbookRunID, userID, checklistItem)
 
@@ -1327,6 +1794,13 @@ func (s *PlaybookRunServiceImpl) AddChecklistItem(playbookRunID, userID string,
 
 // RemoveChecklistItem removes the item at the given index from the given checklist
 func (s *PlaybookRunServiceImpl) RemoveChecklistItem(playbookRunID, userID string, checklistNumber, itemNumber int) error {
+	return retryOnVersionConflict(func() error {
+		return s.removeChecklistItem(playbookRunID, userID, checklistNumber, itemNumber)
+	})
+}
+
+// removeChecklistItem applies RemoveChecklistItem once, see retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) removeChecklistItem(playbookRunID, userID string, checklistNumber, itemNumber int) error {
 	playbookRunToModify, err := s.checklistItemParamsVerify(playbookRunID, userID, checklistNumber, itemNumber)
 	if err != nil {
 		return err
@@ -1338,10 +1812,19 @@ func (s *PlaybookRunServiceImpl) RemoveChecklistItem(playbookRunID, userID strin
 		playbookRunToModify.Checklists[checklistNumber].Items[itemNumber+1:]...,
 	)
 
+	removeChecklistItemDependencies(playbookRunToModify.Checklists, checklistItem.ID)
+
+	// Removing the last open item of a stage starts the next one.
+	playbookRunToModify.SetActiveStage()
+	dueAts := setChecklistItemDueDates(playbookRunToModify, model.GetMillis())
+
 	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
 		return errors.Wrapf(err, "failed to update playbook run")
 	}
 
+	s.scheduleChecklistItemDueAlerts(playbookRunID, dueAts)
+	s.runTriggeredChecklistCommands(playbookRunToModify)
+
 	s.poster.PublishWebsocketEventToChannel(playbookRunUpdatedWSEvent, playbookRunToModify, playbookRunToModify.ChannelID)
 	s.telemetry.RemoveTask(playbookRunID, userID, checklistItem)
 
@@ -1350,6 +1833,13 @@ func (s *PlaybookRunServiceImpl) RemoveChecklistItem(playbookRunID, userID strin
 
 // EditChecklistItem changes the title of a specified checklist item
 func (s *PlaybookRunServiceImpl) EditChecklistItem(playbookRunID, userID string, checklistNumber, itemNumber int, newTitle, newCommand, newDescription string) error {
+	return retryOnVersionConflict(func() error {
+		return s.editChecklistItem(playbookRunID, userID, checklistNumber, itemNumber, newTitle, newCommand, newDescription)
+	})
+}
+
+// editChecklistItem applies EditChecklistItem once, see retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) editChecklistItem(playbookRunID, userID string, checklistNumber, itemNumber int, newTitle, newCommand, newDescription string) error {
 	playbookRunToModify, err := s.checklistItemParamsVerify(playbookRunID, userID, checklistNumber, itemNumber)
 	if err != nil {
 		return err
@@ -1372,6 +1862,13 @@ func (s *PlaybookRunServiceImpl) EditChecklistItem(playbookRunID, userID string,
 
 // MoveChecklistItem moves a checklist item to a new location
 func (s *PlaybookRunServiceImpl) MoveChecklistItem(playbookRunID, userID string, checklistNumber, itemNumber, newLocation int) error {
+	return retryOnVersionConflict(func() error {
+		return s.moveChecklistItem(playbookRunID, userID, checklistNumber, itemNumber, newLocation)
+	})
+}
+
+// moveChecklistItem applies MoveChecklistItem once, see retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) moveChecklistItem(playbookRunID, userID string, checklistNumber, itemNumber, newLocation int) error {
 	playbookRunToModify, err := s.checklistItemParamsVerify(playbookRunID, userID, checklistNumber, itemNumber)
 	if err != nil {
 		return err
@@ -1473,6 +1970,73 @@ func (s *PlaybookRunServiceImpl) modificationMessage(userID, channelID, message
 	return post, nil
 }
 
+// maxPlaybookRunUpdateAttempts is how many times a change to a playbook run is attempted while it
+// conflicts with concurrent updates of the run.
+const maxPlaybookRunUpdateAttempts = 3
+
+// WithExpectedVersion implements PlaybookRunService.WithExpectedVersion: the returned service
+// stores its first update of a playbook run only if the run was read at the expected version, and
+// the store only applies it if the run is still at that version.
+func (s *PlaybookRunServiceImpl) WithExpectedVersion(version int64) PlaybookRunService {
+	withExpectedVersion := *s
+	withExpectedVersion.store = &expectedVersionStore{PlaybookRunStore: s.store, version: version}
+
+	return &withExpectedVersion
+}
+
+// expectedVersionStore is a PlaybookRunStore checking that the first playbook run it updates is at
+// the expected version. Its conflicts are reported as ErrPlaybookRunPreconditionFailed, so that
+// retryOnVersionConflict does not retry the change with the run read anew.
+type expectedVersionStore struct {
+	PlaybookRunStore
+	version int64
+	checked bool
+}
+
+func (s *expectedVersionStore) UpdatePlaybookRun(playbookRun *PlaybookRun) error {
+	if s.checked {
+		return s.PlaybookRunStore.UpdatePlaybookRun(playbookRun)
+	}
+	s.checked = true
+
+	if playbookRun.Version != s.version {
+		return errors.Wrapf(ErrPlaybookRunPreconditionFailed, "playbook run with id '%s' is at version %d, not %d", playbookRun.ID, playbookRun.Version, s.version)
+	}
+
+	err := s.PlaybookRunStore.UpdatePlaybookRun(playbookRun)
+	if errors.Is(err, ErrPlaybookRunVersionConflict) {
+		return errors.Wrapf(ErrPlaybookRunPreconditionFailed, "playbook run with id '%s' is no longer at version %d", playbookRun.ID, s.version)
+	}
+
+	return err
+}
+
+// retryOnVersionConflict applies the change to a playbook run again while it fails because the run
+// was updated concurrently, see PlaybookRunStore.UpdatePlaybookRun. The change must read the run
+// anew each time.
+func retryOnVersionConflict(change func() error) error {
+	var err error
+	for attempt := 0; attempt < maxPlaybookRunUpdateAttempts; attempt++ {
+		if err = change(); !errors.Is(err, ErrPlaybookRunVersionConflict) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// retractModificationMessage deletes the message informing of a change to a playbook run when the
+// update of the run conflicted, as the change did not happen and is retried or given up.
+func (s *PlaybookRunServiceImpl) retractModificationMessage(post *model.Post, updateErr error) {
+	if !errors.Is(updateErr, ErrPlaybookRunVersionConflict) && !errors.Is(updateErr, ErrPlaybookRunPreconditionFailed) {
+		return
+	}
+
+	if err := s.pluginAPI.Post.DeletePost(post.Id); err != nil {
+		s.logger.Warnf("failed to delete the message of a conflicting change to a playbook run: %v", err)
+	}
+}
+
 func (s *PlaybookRunServiceImpl) checklistItemParamsVerify(playbookRunID, userID string, checklistNumber, itemNumber int) (*PlaybookRun, error) {
 	playbookRunToModify, err := s.checklistParamsVerify(playbookRunID, userID, checklistNumber)
 	if err != nil {
@@ -1553,6 +2117,10 @@ func (s *PlaybookRunServiceImpl) UserHasJoinedChannel(userID, channelID, actorID
 		return
 	}
 
+	s.queueWebhookEvent(playbookRun, WebhookEventParticipantJoined, actorID, WebhookEventDetails{
+		ParticipantUserID: userID,
+	})
+
 	if playbookRun.CategorizeChannelEnabled {
 		err = s.createOrUpdatePlaybookRunSidebarCategory(userID, channelID, channel.TeamId)
 		if err != nil {
@@ -1693,6 +2261,15 @@ func (s *PlaybookRunServiceImpl) UserHasLeftChannel(userID, channelID, actorID s
 	}
 
 	_ = s.sendPlaybookRunToClient(playbookRunID)
+
+	playbookRun, err := s.store.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		return
+	}
+
+	s.queueWebhookEvent(playbookRun, WebhookEventParticipantLeft, actorID, WebhookEventDetails{
+		ParticipantUserID: userID,
+	})
 }
 
 func (s *PlaybookRunServiceImpl) hasPermissionToModifyPlaybookRun(playbookRun *PlaybookRun, userID string) bool {
@@ -1781,10 +2358,15 @@ func (s *PlaybookRunServiceImpl) newPlaybookRunDialog(teamID, ownerID, postID, c
 		return nil, errors.Wrapf(err, "failed to fetch owner user")
 	}
 
-	state, err := json.Marshal(DialogState{
+	dialogState := DialogState{
 		PostID:   postID,
 		ClientID: clientID,
-	})
+	}
+	if len(playbooks) == 1 && len(playbooks[0].CustomFields) > 0 {
+		dialogState.CustomFieldsPlaybookID = playbooks[0].ID
+	}
+
+	state, err := json.Marshal(dialogState)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to marshal DialogState")
 	}
@@ -1815,32 +2397,144 @@ func (s *PlaybookRunServiceImpl) newPlaybookRunDialog(teamID, ownerID, postID, c
 		defaultOption = options[0].Value
 	}
 
+	elements := []model.DialogElement{
+		{
+			DisplayName: "Playbook",
+			Name:        DialogFieldPlaybookIDKey,
+			Type:        "select",
+			Options:     options,
+			Default:     defaultOption,
+		},
+		{
+			DisplayName: "Run name",
+			Name:        DialogFieldNameKey,
+			Type:        "text",
+			MinLength:   2,
+			MaxLength:   64,
+		},
+	}
+
+	// The playbook is only known in advance when there is a single one to choose from.
+	if dialogState.CustomFieldsPlaybookID != "" {
+		for _, field := range playbooks[0].CustomFields {
+			elements = append(elements, newCustomFieldDialogElement(field))
+		}
+	}
+
 	return &model.Dialog{
 		Title:            "Run playbook",
 		IntroductionText: introText,
-		Elements: []model.DialogElement{
-			{
-				DisplayName: "Playbook",
-				Name:        DialogFieldPlaybookIDKey,
-				Type:        "select",
-				Options:     options,
-				Default:     defaultOption,
-			},
-			{
-				DisplayName: "Run name",
-				Name:        DialogFieldNameKey,
-				Type:        "text",
-				MinLength:   2,
-				MaxLength:   64,
-			},
-		},
-		SubmitLabel:    "Start run",
-		NotifyOnCancel: false,
-		State:          string(state),
+		Elements:         elements,
+		SubmitLabel:      "Start run",
+		NotifyOnCancel:   false,
+		State:            string(state),
 	}, nil
 }
 
-func (s *PlaybookRunServiceImpl) newUpdatePlaybookRunDialog(description, message, broadcastChannelID, status string, reminderTimer time.Duration) (*model.Dialog, error) {
+// customFieldValuesDisplay returns the given values of the field as shown to users, referring to
+// users by username.
+func (s *PlaybookRunServiceImpl) customFieldValuesDisplay(field CustomField, values []string) string {
+	if field.Type != CustomFieldTypeUser {
+		return strings.Join(values, ", ")
+	}
+
+	var display []string
+	for _, userID := range values {
+		user, err := s.pluginAPI.User.Get(userID)
+		if err != nil {
+			display = append(display, userID)
+			continue
+		}
+		display = append(display, "@"+user.Username)
+	}
+
+	return strings.Join(display, ", ")
+}
+
+// sameCustomFieldValues returns true if a and b hold the same values, in any order.
+func sameCustomFieldValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	sortedA := append([]string(nil), a...)
+	sortedB := append([]string(nil), b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newCustomFieldDialogElement returns the dialog element used to enter the value of the given
+// custom field.
+func newCustomFieldDialogElement(field CustomField) model.DialogElement {
+	element := model.DialogElement{
+		DisplayName: field.Name,
+		Name:        DialogFieldCustomFieldKeyPrefix + field.ID,
+		Type:        "text",
+		Optional:    !field.Required,
+		MaxLength:   MaxCustomFieldValueLength,
+	}
+
+	switch field.Type {
+	case CustomFieldTypeNumber:
+		element.SubType = "number"
+	case CustomFieldTypeURL:
+		element.SubType = "url"
+	case CustomFieldTypeSelect:
+		element.Type = "select"
+		element.MaxLength = 0
+		for _, option := range field.Options {
+			element.Options = append(element.Options, &model.PostActionOptions{
+				Text:  option,
+				Value: option,
+			})
+		}
+	case CustomFieldTypeMultiSelect:
+		// Dialogs have no multi-select element, so values are entered as a comma-separated list.
+		element.HelpText = fmt.Sprintf("Separate values with commas. Options: %s", strings.Join(field.Options, ", "))
+		element.MaxLength = 0
+	case CustomFieldTypeUser:
+		element.Type = "select"
+		element.DataSource = "users"
+		element.MaxLength = 0
+	}
+
+	return element
+}
+
+// CustomFieldValuesFromDialog returns the values entered in the custom field elements of the
+// create playbook run dialog, keyed by custom field ID.
+func CustomFieldValuesFromDialog(fields []CustomField, submission map[string]interface{}) CustomFieldValues {
+	values := CustomFieldValues{}
+	for _, field := range fields {
+		rawValue, ok := submission[DialogFieldCustomFieldKeyPrefix+field.ID]
+		if !ok || rawValue == nil {
+			continue
+		}
+
+		// Number elements may be submitted as numbers rather than strings.
+		value := strings.TrimSpace(fmt.Sprint(rawValue))
+		if value == "" {
+			continue
+		}
+
+		if field.Type == CustomFieldTypeMultiSelect {
+			values[field.ID] = strings.Split(value, ",")
+		} else {
+			values[field.ID] = []string{value}
+		}
+	}
+
+	return values
+}
+
+func (s *PlaybookRunServiceImpl) newUpdatePlaybookRunDialog(description, message, broadcastChannelID, status string, nextStatuses []string, reminderTimer time.Duration) (*model.Dialog, error) {
 	introductionText := "Provide an update to the stakeholders."
 
 	broadcastChannel, err := s.pluginAPI.Channel.Get(broadcastChannelID)
@@ -1885,23 +2579,12 @@ func (s *PlaybookRunServiceImpl) newUpdatePlaybookRunDialog(description, message
 		},
 	}
 
-	statusOptions := []*model.PostActionOptions{
-		{
-			Text:  "Reported",
-			Value: "Reported",
-		},
-		{
-			Text:  "Active",
-			Value: "Active",
-		},
-		{
-			Text:  "Resolved",
-			Value: "Resolved",
-		},
-		{
-			Text:  "Archived",
-			Value: "Archived",
-		},
+	statusOptions := []*model.PostActionOptions{}
+	for _, nextStatus := range nextStatuses {
+		statusOptions = append(statusOptions, &model.PostActionOptions{
+			Text:  nextStatus,
+			Value: nextStatus,
+		})
 	}
 
 	if s.configService.IsConfiguredForDevelopmentAndTesting() {
@@ -2092,6 +2775,8 @@ func (s *PlaybookRunServiceImpl) PublishRetrospective(playbookRunID, text, publi
 	}
 	s.telemetry.PublishRetrospective(playbookRunToPublish, publisherID)
 
+	s.queueWebhookEvent(playbookRunToPublish, WebhookEventRetrospectivePublished, publisherID, WebhookEventDetails{})
+
 	return nil
 }
 
@@ -2136,6 +2821,8 @@ func (s *PlaybookRunServiceImpl) CancelRetrospective(playbookRunID, cancelerID s
 		s.logger.Errorf("failed send websocket event; error: %s", err.Error())
 	}
 
+	s.queueWebhookEvent(playbookRunToCancel, WebhookEventRetrospectiveCanceled, cancelerID, WebhookEventDetails{})
+
 	return nil
 }
 
@@ -15,6 +15,7 @@ import (
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/telemetry"
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -24,6 +25,7 @@ import (
 	mock_config "github.com/mattermost/mattermost-plugin-incident-collaboration/server/config/mocks"
 
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/cluster"
 )
 
 func TestCreatePlaybookRun(t *testing.T) {
@@ -273,7 +275,7 @@ func TestCreatePlaybookRun(t *testing.T) {
 			DetailsURL string `json:"details_url"`
 		}
 
-		webhookChan := make(chan webhookPayload)
+		webhookChan := make(chan webhookPayload, 1)
 
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			body, err := ioutil.ReadAll(r.Body)
@@ -299,51 +301,1165 @@ func TestCreatePlaybookRun(t *testing.T) {
 		store.EXPECT().CreateTimelineEvent(gomock.AssignableToTypeOf(&app.TimelineEvent{}))
 		store.EXPECT().UpdatePlaybookRun(gomock.Any()).Return(nil)
 
+		var delivery *app.WebhookDelivery
+		store.EXPECT().CreateWebhookDelivery(gomock.AssignableToTypeOf(&app.WebhookDelivery{})).
+			DoAndReturn(func(d *app.WebhookDelivery) (*app.WebhookDelivery, error) {
+				d.ID = model.NewId()
+				delivery = d
+				return d, nil
+			})
+		var jobKey string
+		scheduler.EXPECT().ScheduleOnce(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(key string, runAt time.Time) (*cluster.JobOnce, error) {
+				jobKey = key
+				return nil, nil
+			})
+
 		configService.EXPECT().GetManifest().Return(&model.Manifest{Id: "com.mattermost.plugin-incident-management"}).Times(2)
 		configService.EXPECT().GetConfiguration().Return(&config.Configuration{BotUserID: "bot_user_id"}).AnyTimes()
 
 		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), "channel_id")
-		poster.EXPECT().PostMessage("channel_id", gomock.Any()).Return(&model.Post{Id: "testId"}, nil)
+		poster.EXPECT().PostMessage("channel_id", gomock.Any()).Return(&model.Post{Id: "testId"}, nil)
+
+		mattermostConfig := &model.Config{}
+		mattermostConfig.SetDefaults()
+		siteURL := "http://example.com"
+		mattermostConfig.ServiceSettings.SiteURL = &siteURL
+		pluginAPI.On("GetConfig").Return(mattermostConfig)
+		pluginAPI.On("CreateChannel", mock.Anything).Return(&model.Channel{Id: "channel_id", TeamId: "team_id"}, nil)
+		pluginAPI.On("AddUserToChannel", "channel_id", "user_id", "bot_user_id").Return(nil, nil)
+		pluginAPI.On("UpdateChannelMemberRoles", "channel_id", "user_id", mock.Anything).Return(nil, nil)
+		pluginAPI.On("CreateTeamMember", "team_id", "bot_user_id").Return(nil, nil)
+		pluginAPI.On("AddChannelMember", "channel_id", "bot_user_id").Return(nil, nil)
+		pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Username: "username"}, nil)
+		pluginAPI.On("GetTeam", teamID).Return(&model.Team{Id: teamID, Name: "ad-1"}, nil)
+		pluginAPI.On("GetChannel", mock.Anything).Return(&model.Channel{Id: "channel_id", Name: "channel-name"}, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		createdPlaybookRun, err := s.CreatePlaybookRun(playbookRun, nil, "user_id", true)
+		require.NoError(t, err)
+
+		require.Equal(t, app.WebhookDeliveryPending, delivery.Status)
+		require.Equal(t, app.WebhookEventPlaybookRunCreated, delivery.EventType)
+
+		// The webhook is sent by the scheduled delivery job.
+		store.EXPECT().GetWebhookDelivery(delivery.ID).Return(delivery, nil)
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+		store.EXPECT().UpdateWebhookDelivery(delivery).Return(nil)
+		s.HandleReminder(jobKey)
+
+		require.Equal(t, app.WebhookDeliverySucceeded, delivery.Status)
+		require.Equal(t, 1, delivery.Attempts)
+		require.Equal(t, http.StatusOK, delivery.LastStatusCode)
+
+		select {
+		case payload := <-webhookChan:
+			require.Equal(t, *createdPlaybookRun, payload.PlaybookRun)
+			require.Equal(t,
+				"http://example.com/ad-1/channels/channel-name",
+				payload.ChannelURL)
+			require.Equal(t,
+				"http://example.com/ad-1/com.mattermost.plugin-incident-management/runs/"+createdPlaybookRun.ID,
+				payload.DetailsURL)
+
+		case <-time.After(time.Second * 5):
+			require.Fail(t, "did not receive webhook")
+		}
+
+		pluginAPI.AssertExpectations(t)
+	})
+}
+
+func TestUpdateStatus(t *testing.T) {
+	t.Run("webhook is sent on status update", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		type webhookPayload struct {
+			app.PlaybookRun
+			ChannelURL   string                  `json:"channel_url"`
+			DetailsURL   string                  `json:"details_url"`
+			StatusUpdate app.StatusUpdateOptions `json:"status_update"`
+		}
+
+		webhookChan := make(chan webhookPayload, 1)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			var p webhookPayload
+			err = json.Unmarshal(body, &p)
+			require.NoError(t, err)
+
+			webhookChan <- p
+		}))
+
+		playbookRunID := model.NewId()
+		teamID := model.NewId()
+		playbookRun := &app.PlaybookRun{
+			ID:                       playbookRunID,
+			Name:                     "Name",
+			TeamID:                   teamID,
+			ChannelID:                "channel_id",
+			BroadcastChannelID:       "broadcast_channel_id",
+			OwnerUserID:              "user_id",
+			CurrentStatus:            app.StatusReported,
+			CreateAt:                 1620018358404,
+			WebhookOnStatusUpdateURL: server.URL,
+		}
+		statusUpdateOptions := app.StatusUpdateOptions{
+			Status:      app.StatusActive,
+			Description: "latest-description",
+			Message:     "latest-message",
+			Reminder:    0,
+		}
+		siteURL := "http://example.com"
+		channelID := "channel_id"
+
+		store.EXPECT().CreateTimelineEvent(gomock.AssignableToTypeOf(&app.TimelineEvent{}))
+		store.EXPECT().UpdatePlaybookRun(gomock.AssignableToTypeOf(&app.PlaybookRun{})).Return(nil)
+		store.EXPECT().UpdateStatus(gomock.AssignableToTypeOf(&app.SQLStatusPost{})).Return(nil)
+		store.EXPECT().GetPlaybookRun(gomock.Any()).Return(playbookRun, nil).Times(3)
+
+		var delivery *app.WebhookDelivery
+		store.EXPECT().CreateWebhookDelivery(gomock.AssignableToTypeOf(&app.WebhookDelivery{})).
+			DoAndReturn(func(d *app.WebhookDelivery) (*app.WebhookDelivery, error) {
+				d.ID = model.NewId()
+				delivery = d
+				return d, nil
+			})
+		var jobKey string
+		scheduler.EXPECT().ScheduleOnce(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(key string, runAt time.Time) (*cluster.JobOnce, error) {
+				jobKey = key
+				return nil, nil
+			})
+
+		configService.EXPECT().GetManifest().Return(&model.Manifest{Id: "com.mattermost.plugin-incident-management"}).Times(2)
+
+		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), channelID)
+		poster.EXPECT().PostMessage("broadcast_channel_id", gomock.Any()).Return(&model.Post{}, nil)
+
+		scheduler.EXPECT().Cancel(playbookRun.ID).Times(2)
+		scheduler.EXPECT().Cancel(playbookRun.ID + "_next")
+
+		mattermostConfig := &model.Config{}
+		mattermostConfig.SetDefaults()
+		mattermostConfig.ServiceSettings.SiteURL = &siteURL
+		pluginAPI.On("CreatePost", mock.Anything).Return(&model.Post{}, nil)
+		pluginAPI.On("GetChannel", channelID).Return(&model.Channel{Id: channelID, Name: "channel_name"}, nil)
+		pluginAPI.On("GetTeam", teamID).Return(&model.Team{Id: teamID, Name: "team_name"}, nil)
+		pluginAPI.On("GetUser", "user_id").Return(&model.User{}, nil)
+		pluginAPI.On("GetConfig").Return(&model.Config{ServiceSettings: model.ServiceSettings{SiteURL: &siteURL}})
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		err := s.UpdateStatus(playbookRun.ID, "user_id", statusUpdateOptions)
+		require.NoError(t, err)
+
+		require.Equal(t, app.WebhookDeliveryPending, delivery.Status)
+		require.Equal(t, app.WebhookEventStatusUpdated, delivery.EventType)
+
+		// The webhook is sent by the scheduled delivery job.
+		store.EXPECT().GetWebhookDelivery(delivery.ID).Return(delivery, nil)
+		store.EXPECT().UpdateWebhookDelivery(delivery).Return(nil)
+		s.HandleReminder(jobKey)
+
+		require.Equal(t, app.WebhookDeliverySucceeded, delivery.Status)
+		require.Equal(t, 1, delivery.Attempts)
+		require.Equal(t, http.StatusOK, delivery.LastStatusCode)
+
+		select {
+		case payload := <-webhookChan:
+			require.Equal(t, *playbookRun, payload.PlaybookRun)
+			require.Equal(t,
+				"http://example.com/team_name/channels/channel_name",
+				payload.ChannelURL)
+			require.Equal(t,
+				fmt.Sprintf("http://example.com/team_name/com.mattermost.plugin-incident-management/runs/%s", playbookRunID),
+				payload.DetailsURL)
+
+		case <-time.After(time.Second * 5):
+			require.Fail(t, "did not receive webhook on status update")
+		}
+	})
+
+	t.Run("transition not allowed by the status workflow", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{
+			ID:            model.NewId(),
+			ChannelID:     "channel_id",
+			CurrentStatus: "Triage",
+			StatusWorkflow: app.StatusWorkflow{
+				States: []app.StatusState{
+					{Name: "Triage", Transitions: []string{"Mitigating"}},
+					{Name: "Mitigating", Transitions: []string{"Done"}},
+					{Name: "Done", Closed: true},
+				},
+			},
+		}
+
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		err := s.UpdateStatus(playbookRun.ID, "user_id", app.StatusUpdateOptions{
+			Status:      "Done",
+			Description: "description",
+			Message:     "message",
+		})
+		require.ErrorIs(t, err, app.ErrMalformedPlaybookRun)
+		pluginAPI.AssertNotCalled(t, "CreatePost", mock.Anything)
+	})
+
+	t.Run("conflicting update is retried and its status post deleted", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{
+			ID:                 model.NewId(),
+			TeamID:             "team_id",
+			ChannelID:          "channel_id",
+			BroadcastChannelID: "broadcast_channel_id",
+			CurrentStatus:      app.StatusReported,
+		}
+
+		// Each read gets its own copy of the run, as from the database.
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).
+			DoAndReturn(func(string) (*app.PlaybookRun, error) { return playbookRun.Clone(), nil }).
+			AnyTimes()
+		gomock.InOrder(
+			store.EXPECT().UpdatePlaybookRun(gomock.Any()).Return(errors.Wrap(app.ErrPlaybookRunVersionConflict, "playbook run is no longer at version 0")),
+			store.EXPECT().UpdatePlaybookRun(gomock.Any()).Return(nil),
+		)
+		store.EXPECT().UpdateStatus(gomock.AssignableToTypeOf(&app.SQLStatusPost{})).
+			DoAndReturn(func(statusPost *app.SQLStatusPost) error {
+				require.Equal(t, "post_id", statusPost.PostID)
+				return nil
+			})
+		store.EXPECT().CreateTimelineEvent(gomock.AssignableToTypeOf(&app.TimelineEvent{})).
+			DoAndReturn(func(event *app.TimelineEvent) (*app.TimelineEvent, error) {
+				require.Equal(t, "post_id", event.PostID)
+				return event, nil
+			})
+		scheduler.EXPECT().Cancel(gomock.Any()).AnyTimes()
+		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), "channel_id")
+		pluginAPI.On("CreatePost", mock.Anything).Return(&model.Post{Id: "conflicting_post_id"}, nil).Once()
+		pluginAPI.On("CreatePost", mock.Anything).Return(&model.Post{Id: "post_id"}, nil).Once()
+		pluginAPI.On("DeletePost", "conflicting_post_id").Return(nil)
+		pluginAPI.On("GetChannel", "channel_id").Return(&model.Channel{Id: "channel_id", Name: "channel_name"}, nil)
+		pluginAPI.On("GetTeam", "team_id").Return(&model.Team{Id: "team_id", Name: "team_name"}, nil)
+		pluginAPI.On("GetUser", "user_id").Return(&model.User{}, nil)
+		poster.EXPECT().PostMessage("broadcast_channel_id", gomock.Any()).Return(&model.Post{}, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		err := s.UpdateStatus(playbookRun.ID, "user_id", app.StatusUpdateOptions{
+			Status:      app.StatusActive,
+			Description: "description",
+			Message:     "message",
+		})
+		require.NoError(t, err)
+		pluginAPI.AssertCalled(t, "DeletePost", "conflicting_post_id")
+	})
+}
+
+func TestUpdatePlaybookRun(t *testing.T) {
+	t.Run("renames the channel and records each change in the timeline", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{
+			ID:            model.NewId(),
+			Name:          "Old name",
+			Description:   "Old description",
+			ChannelID:     "channel_id",
+			CurrentStatus: app.StatusActive,
+		}
+
+		var summaries []string
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil).Times(3)
+		store.EXPECT().UpdatePlaybookRun(gomock.AssignableToTypeOf(&app.PlaybookRun{})).Return(nil)
+		store.EXPECT().CreateTimelineEvent(gomock.AssignableToTypeOf(&app.TimelineEvent{})).
+			DoAndReturn(func(event *app.TimelineEvent) (*app.TimelineEvent, error) {
+				require.Equal(t, app.RunUpdated, event.EventType)
+				require.Equal(t, "user_id", event.SubjectUserID)
+				summaries = append(summaries, event.Summary)
+				return event, nil
+			}).Times(2)
+		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), "channel_id")
+		pluginAPI.On("GetChannel", "channel_id").Return(&model.Channel{Id: "channel_id", DisplayName: "Old name"}, nil)
+		pluginAPI.On("UpdateChannel", &model.Channel{Id: "channel_id", DisplayName: "New name"}).Return(&model.Channel{}, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		updatedPlaybookRun, err := s.UpdatePlaybookRun(playbookRun.ID, "user_id", app.UpdateOptions{
+			Name:          model.NewString(" New name "),
+			Description:   model.NewString("Old description"),
+			MessageOnJoin: model.NewString("Welcome!"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "New name", updatedPlaybookRun.Name)
+		require.Equal(t, "Welcome!", updatedPlaybookRun.MessageOnJoin)
+		require.Equal(t, []string{
+			"renamed the run from **Old name** to **New name**",
+			"updated the welcome message",
+		}, summaries)
+	})
+
+	t.Run("conflicting update is retried and the channel renamed once stored", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		newPlaybookRun := func() *app.PlaybookRun {
+			return &app.PlaybookRun{ID: "playbook_run_id", Name: "Old name", ChannelID: "channel_id", CurrentStatus: app.StatusActive}
+		}
+		store.EXPECT().GetPlaybookRun("playbook_run_id").DoAndReturn(func(string) (*app.PlaybookRun, error) {
+			return newPlaybookRun(), nil
+		}).Times(4)
+		gomock.InOrder(
+			store.EXPECT().UpdatePlaybookRun(gomock.Any()).Return(app.ErrPlaybookRunVersionConflict),
+			store.EXPECT().UpdatePlaybookRun(gomock.Any()).Return(nil),
+		)
+		store.EXPECT().CreateTimelineEvent(gomock.Any()).Return(&app.TimelineEvent{}, nil)
+		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), "channel_id")
+
+		channel := &model.Channel{Id: "channel_id", DisplayName: "Old name"}
+		var displayNames []string
+		pluginAPI.On("GetChannel", "channel_id").Return(channel, nil)
+		pluginAPI.On("UpdateChannel", channel).Return(channel, nil).Run(func(args mock.Arguments) {
+			displayNames = append(displayNames, args.Get(0).(*model.Channel).DisplayName)
+		})
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		_, err := s.UpdatePlaybookRun("playbook_run_id", "user_id", app.UpdateOptions{Name: model.NewString("New name")})
+		require.NoError(t, err)
+		require.Equal(t, []string{"New name", "Old name", "New name"}, displayNames)
+	})
+
+	t.Run("failed update restores the channel name", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{ID: "playbook_run_id", Name: "Old name", ChannelID: "channel_id", CurrentStatus: app.StatusActive}
+		store.EXPECT().GetPlaybookRun("playbook_run_id").Return(playbookRun, nil)
+		store.EXPECT().UpdatePlaybookRun(gomock.Any()).Return(errors.New("db unavailable"))
+
+		channel := &model.Channel{Id: "channel_id", DisplayName: "Old name"}
+		pluginAPI.On("GetChannel", "channel_id").Return(channel, nil)
+		pluginAPI.On("UpdateChannel", channel).Return(channel, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		_, err := s.UpdatePlaybookRun("playbook_run_id", "user_id", app.UpdateOptions{Name: model.NewString("New name")})
+		require.Error(t, err)
+		require.Equal(t, "Old name", channel.DisplayName)
+		pluginAPI.AssertNumberOfCalls(t, "UpdateChannel", 2)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{
+			ID:          model.NewId(),
+			Name:        "Name",
+			Description: "Description",
+			ChannelID:   "channel_id",
+		}
+
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		updatedPlaybookRun, err := s.UpdatePlaybookRun(playbookRun.ID, "user_id", app.UpdateOptions{
+			Name:        model.NewString("Name"),
+			Description: model.NewString("Description"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, playbookRun, updatedPlaybookRun)
+	})
+
+	t.Run("invalid channel display name", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{
+			ID:        model.NewId(),
+			Name:      "Name",
+			ChannelID: "channel_id",
+		}
+
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+		pluginAPI.On("GetChannel", "channel_id").Return(&model.Channel{Id: "channel_id", DisplayName: "Name"}, nil)
+		pluginAPI.On("UpdateChannel", mock.Anything).Return(nil, &model.AppError{Id: "model.channel.is_valid.2_or_more.app_error"})
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		_, err := s.UpdatePlaybookRun(playbookRun.ID, "user_id", app.UpdateOptions{
+			Name: model.NewString("N"),
+		})
+		require.Equal(t, app.ErrChannelDisplayNameInvalid, err)
+	})
+
+	t.Run("reschedules the pending retrospective reminder", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{
+			ID:                                   model.NewId(),
+			Name:                                 "Name",
+			ChannelID:                            "channel_id",
+			CurrentStatus:                        app.StatusResolved,
+			RetrospectiveReminderIntervalSeconds: 60,
+		}
+
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil).Times(3)
+		store.EXPECT().UpdatePlaybookRun(gomock.AssignableToTypeOf(&app.PlaybookRun{})).Return(nil)
+		store.EXPECT().CreateTimelineEvent(gomock.AssignableToTypeOf(&app.TimelineEvent{}))
+		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), "channel_id")
+		scheduler.EXPECT().Cancel(app.RetrospectivePrefix + playbookRun.ID).Times(2)
+		scheduler.EXPECT().Cancel(app.RetrospectivePrefix + playbookRun.ID + "_next")
+		scheduler.EXPECT().ScheduleOnce(app.RetrospectivePrefix+playbookRun.ID, gomock.Any()).Return(nil, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		_, err := s.UpdatePlaybookRun(playbookRun.ID, "user_id", app.UpdateOptions{
+			RetrospectiveReminderIntervalSeconds: model.NewInt64(3600),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("sets custom field values and records them in the timeline", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		responderID := model.NewId()
+		playbookRun := &app.PlaybookRun{
+			ID:        model.NewId(),
+			Name:      "Name",
+			ChannelID: "channel_id",
+			CustomFields: []app.CustomField{
+				{ID: "regionfieldid", Name: "Region", Type: app.CustomFieldTypeSelect, Options: []string{"eu", "us"}},
+				{ID: "responderfieldid", Name: "Responder", Type: app.CustomFieldTypeUser},
+				{ID: "notesfieldid", Name: "Notes", Type: app.CustomFieldTypeText},
+			},
+			CustomFieldValues: app.CustomFieldValues{
+				"regionfieldid": {"us"},
+				"notesfieldid":  {"Some notes"},
+			},
+		}
+
+		expectedValues := app.CustomFieldValues{
+			"regionfieldid":    {"eu"},
+			"responderfieldid": {responderID},
+		}
+
+		var summaries []string
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil).Times(3)
+		store.EXPECT().UpdatePlaybookRun(gomock.AssignableToTypeOf(&app.PlaybookRun{})).Return(nil)
+		store.EXPECT().UpdateCustomFieldValues(playbookRun.ID, expectedValues).Return(nil)
+		store.EXPECT().CreateTimelineEvent(gomock.AssignableToTypeOf(&app.TimelineEvent{})).
+			DoAndReturn(func(event *app.TimelineEvent) (*app.TimelineEvent, error) {
+				summaries = append(summaries, event.Summary)
+				return event, nil
+			}).Times(3)
+		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), "channel_id")
+		pluginAPI.On("GetUser", responderID).Return(&model.User{Id: responderID, Username: "responder"}, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		updatedPlaybookRun, err := s.UpdatePlaybookRun(playbookRun.ID, "user_id", app.UpdateOptions{
+			CustomFieldValues: app.CustomFieldValues{
+				"regionfieldid":    {" eu"},
+				"responderfieldid": {responderID},
+				"notesfieldid":     {},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, expectedValues, updatedPlaybookRun.CustomFieldValues)
+		require.Equal(t, []string{
+			"set **Region** to **eu**",
+			"set **Responder** to **@responder**",
+			"cleared **Notes**",
+		}, summaries)
+	})
+
+	t.Run("invalid custom field values", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{
+			ID:        model.NewId(),
+			Name:      "Name",
+			ChannelID: "channel_id",
+			CustomFields: []app.CustomField{
+				{ID: "regionfieldid", Name: "Region", Type: app.CustomFieldTypeSelect, Options: []string{"eu", "us"}, Required: true},
+			},
+			CustomFieldValues: app.CustomFieldValues{"regionfieldid": {"us"}},
+		}
+
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil).Times(3)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		_, err := s.UpdatePlaybookRun(playbookRun.ID, "user_id", app.UpdateOptions{
+			CustomFieldValues: app.CustomFieldValues{"regionfieldid": {"apac"}},
+		})
+		require.ErrorIs(t, err, app.ErrMalformedPlaybookRun)
+
+		_, err = s.UpdatePlaybookRun(playbookRun.ID, "user_id", app.UpdateOptions{
+			CustomFieldValues: app.CustomFieldValues{"unknownfieldid": {"a"}},
+		})
+		require.ErrorIs(t, err, app.ErrMalformedPlaybookRun)
+
+		_, err = s.UpdatePlaybookRun(playbookRun.ID, "user_id", app.UpdateOptions{
+			CustomFieldValues: app.CustomFieldValues{"regionfieldid": {}},
+		})
+		require.ErrorIs(t, err, app.ErrMalformedPlaybookRun)
+		require.Contains(t, err.Error(), "required")
+	})
+}
+
+func TestChangeSeverity(t *testing.T) {
+	t.Run("changes the severity and records it in the timeline", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{
+			ID:             model.NewId(),
+			ChannelID:      "channel_id",
+			Severity:       "SEV-3",
+			SeverityLevels: []string{"SEV-1", "SEV-2", "SEV-3"},
+		}
+
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil).Times(2)
+		store.EXPECT().UpdatePlaybookRun(gomock.AssignableToTypeOf(&app.PlaybookRun{})).
+			DoAndReturn(func(updated *app.PlaybookRun) error {
+				require.Equal(t, "SEV-1", updated.Severity)
+				require.Equal(t, 3, updated.SeverityRank())
+				return nil
+			})
+		store.EXPECT().CreateTimelineEvent(gomock.AssignableToTypeOf(&app.TimelineEvent{})).
+			DoAndReturn(func(event *app.TimelineEvent) (*app.TimelineEvent, error) {
+				require.Equal(t, app.SeverityChanged, event.EventType)
+				require.Equal(t, "SEV-3 to SEV-1", event.Summary)
+				require.Equal(t, "post_id", event.PostID)
+				return event, nil
+			})
+		poster.EXPECT().PostMessage("channel_id", "username changed the severity from **SEV-3** to **SEV-1**.").
+			Return(&model.Post{Id: "post_id"}, nil)
+		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), "channel_id")
+		pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Username: "username"}, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		err := s.ChangeSeverity(playbookRun.ID, "user_id", "sev-1")
+		require.NoError(t, err)
+	})
+
+	t.Run("conflicting update is retried", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{
+			ID:             model.NewId(),
+			ChannelID:      "channel_id",
+			Severity:       "SEV-3",
+			SeverityLevels: []string{"SEV-1", "SEV-2", "SEV-3"},
+		}
+
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).
+			DoAndReturn(func(string) (*app.PlaybookRun, error) { return playbookRun.Clone(), nil }).
+			Times(3)
+		gomock.InOrder(
+			store.EXPECT().UpdatePlaybookRun(gomock.Any()).Return(errors.Wrap(app.ErrPlaybookRunVersionConflict, "playbook run is no longer at version 0")),
+			store.EXPECT().UpdatePlaybookRun(gomock.Any()).Return(nil),
+		)
+		store.EXPECT().CreateTimelineEvent(gomock.AssignableToTypeOf(&app.TimelineEvent{})).Return(&app.TimelineEvent{}, nil)
+		poster.EXPECT().PostMessage("channel_id", "username changed the severity from **SEV-3** to **SEV-1**.").
+			Return(&model.Post{Id: "post_id"}, nil)
+		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), "channel_id")
+		pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Username: "username"}, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		err := s.ChangeSeverity(playbookRun.ID, "user_id", "SEV-1")
+		require.NoError(t, err)
+	})
+
+	t.Run("same severity is a no-op", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{
+			ID:             model.NewId(),
+			ChannelID:      "channel_id",
+			Severity:       "SEV-2",
+			SeverityLevels: []string{"SEV-1", "SEV-2"},
+		}
+
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		err := s.ChangeSeverity(playbookRun.ID, "user_id", "SEV-2")
+		require.NoError(t, err)
+	})
+
+	t.Run("unknown severity", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{
+			ID:             model.NewId(),
+			ChannelID:      "channel_id",
+			SeverityLevels: []string{"SEV-1", "SEV-2"},
+		}
+
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		err := s.ChangeSeverity(playbookRun.ID, "user_id", "SEV-9")
+		require.ErrorIs(t, err, app.ErrMalformedPlaybookRun)
+	})
+
+	t.Run("run without severity levels", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{
+			ID:        model.NewId(),
+			ChannelID: "channel_id",
+		}
+
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		err := s.ChangeSeverity(playbookRun.ID, "user_id", "SEV-1")
+		require.ErrorIs(t, err, app.ErrMalformedPlaybookRun)
+	})
+}
+
+func TestAddChecklistItem(t *testing.T) {
+	setup := func(t *testing.T) (*plugintest.API, *mock_app.MockPlaybookRunStore, *mock_bot.MockPoster, *mock_bot.MockLogger, *mock_app.MockJobOnceScheduler, *app.PlaybookRunServiceImpl) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		configService.EXPECT().GetConfiguration().Return(&config.Configuration{BotUserID: "bot_user_id"}).AnyTimes()
+		pluginAPI.On("HasPermissionToChannel", "user_id", "channel_id", model.PERMISSION_READ_CHANNEL).Return(true)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{})
+		return pluginAPI, store, poster, logger, scheduler, s
+	}
+
+	newRun := func() *app.PlaybookRun {
+		return &app.PlaybookRun{
+			ID:            model.NewId(),
+			TeamID:        "team_id",
+			ChannelID:     "channel_id",
+			OwnerUserID:   "owner_id",
+			CurrentStatus: app.StatusReported,
+			Checklists:    []app.Checklist{{Title: "Triage"}},
+		}
+	}
+
+	item := app.ChecklistItem{
+		Title:          "Page",
+		Command:        "/page on-call",
+		CommandTrigger: app.ChecklistItemCommandTriggerRunStart,
+		CommandRunAs:   app.ChecklistItemCommandRunAsBot,
+	}
+
+	expectCommandsJob := func(scheduler *mock_app.MockJobOnceScheduler, playbookRun *app.PlaybookRun) *string {
+		var jobKey string
+		scheduler.EXPECT().ScheduleOnce(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(key string, runAt time.Time) (*cluster.JobOnce, error) {
+				triggeredAt := playbookRun.Checklists[0].Items[0].CommandTriggeredAt
+				require.Equal(t, fmt.Sprintf("%s%s_%d", app.ChecklistCommandsPrefix, playbookRun.ID, triggeredAt), key)
+				jobKey = key
+				return nil, nil
+			})
+		return &jobKey
+	}
+
+	t.Run("triggered command runs once and is recorded in the timeline", func(t *testing.T) {
+		pluginAPI, store, poster, _, scheduler, s := setup(t)
+		playbookRun := newRun()
+
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil).Times(3)
+		store.EXPECT().UpdatePlaybookRun(playbookRun).Return(nil).Times(3)
+		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), "channel_id").Times(2)
+		jobKey := expectCommandsJob(scheduler, playbookRun)
+		store.EXPECT().CreateTimelineEvent(gomock.AssignableToTypeOf(&app.TimelineEvent{})).
+			DoAndReturn(func(event *app.TimelineEvent) (*app.TimelineEvent, error) {
+				require.Equal(t, app.RanSlashCommand, event.EventType)
+				require.Equal(t, "ran the slash command: `/page on-call`, triggered automatically", event.Summary)
+				require.Equal(t, "bot_user_id", event.SubjectUserID)
+				return event, nil
+			})
+		pluginAPI.On("ExecuteSlashCommand", &model.CommandArgs{
+			Command:   "/page on-call",
+			UserId:    "bot_user_id",
+			TeamId:    "team_id",
+			ChannelId: "channel_id",
+		}).Return(&model.CommandResponse{}, nil).Once()
+
+		err := s.AddChecklistItem(playbookRun.ID, "user_id", 0, item)
+		require.NoError(t, err)
+
+		added := playbookRun.Checklists[0].Items[0]
+		require.NotZero(t, added.CommandTriggeredAt)
+		require.NotEmpty(t, added.ID)
+		pluginAPI.AssertNotCalled(t, "ExecuteSlashCommand", mock.Anything)
+
+		s.HandleReminder(*jobKey)
+
+		require.NotZero(t, playbookRun.Checklists[0].Items[0].CommandLastRun)
+		pluginAPI.AssertExpectations(t)
+	})
+
+	t.Run("failed command is reported in the channel", func(t *testing.T) {
+		pluginAPI, store, poster, logger, scheduler, s := setup(t)
+		playbookRun := newRun()
+
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil).Times(2)
+		store.EXPECT().UpdatePlaybookRun(playbookRun).Return(nil).Times(2)
+		jobKey := expectCommandsJob(scheduler, playbookRun)
+		pluginAPI.On("ExecuteSlashCommand", mock.Anything).Return(nil, errors.New("unknown command")).Once()
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any())
+		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), "channel_id")
+		poster.EXPECT().PostMessage("channel_id", "Failed to run the slash command `%s` of the checklist item **%s**.", "/page on-call", "Page").
+			Return(&model.Post{}, nil)
+
+		err := s.AddChecklistItem(playbookRun.ID, "user_id", 0, item)
+		require.NoError(t, err)
+		s.HandleReminder(*jobKey)
+
+		added := playbookRun.Checklists[0].Items[0]
+		require.NotZero(t, added.CommandTriggeredAt, "failed commands are not retried")
+		require.Zero(t, added.CommandLastRun)
+	})
+
+	t.Run("run of a command is recorded in the run as updated while it ran", func(t *testing.T) {
+		pluginAPI, store, poster, _, _, s := setup(t)
+
+		newTriggeredRun := func(playbookRunID string, version int64) *app.PlaybookRun {
+			triggered := item
+			triggered.ID = "item_id"
+			triggered.CommandTriggeredAt = 1234
+			playbookRun := newRun()
+			playbookRun.ID = playbookRunID
+			playbookRun.Version = version
+			playbookRun.Checklists[0].Items = []app.ChecklistItem{triggered}
+			return playbookRun
+		}
+		stale := newTriggeredRun(model.NewId(), 1)
+		conflicting := newTriggeredRun(stale.ID, 2)
+		current := newTriggeredRun(stale.ID, 3)
+		current.Checklists[0].Items[0].State = app.ChecklistItemStateClosed
+
+		gomock.InOrder(
+			store.EXPECT().GetPlaybookRun(stale.ID).Return(stale, nil),
+			store.EXPECT().GetPlaybookRun(stale.ID).Return(conflicting, nil),
+			store.EXPECT().UpdatePlaybookRun(conflicting).Return(app.ErrPlaybookRunVersionConflict),
+			store.EXPECT().GetPlaybookRun(stale.ID).Return(current, nil),
+			store.EXPECT().UpdatePlaybookRun(current).Return(nil),
+		)
+		store.EXPECT().CreateTimelineEvent(gomock.Any()).Return(&app.TimelineEvent{}, nil)
+		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", current, "channel_id")
+		pluginAPI.On("ExecuteSlashCommand", mock.Anything).Return(&model.CommandResponse{}, nil).Once()
+
+		s.HandleReminder(fmt.Sprintf("%s%s_%d", app.ChecklistCommandsPrefix, stale.ID, 1234))
+
+		require.NotZero(t, current.Checklists[0].Items[0].CommandLastRun)
+		require.Equal(t, app.ChecklistItemStateClosed, current.Checklists[0].Items[0].State)
+		require.Zero(t, stale.Checklists[0].Items[0].CommandLastRun)
+		pluginAPI.AssertNumberOfCalls(t, "ExecuteSlashCommand", 1)
+	})
+
+	t.Run("invalid command trigger", func(t *testing.T) {
+		_, store, _, _, _, s := setup(t)
+		playbookRun := newRun()
+
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+
+		invalid := item
+		invalid.CommandTrigger = app.ChecklistItemCommandTriggerStatus
+		invalid.CommandTriggerStatus = "Unknown"
+		err := s.AddChecklistItem(playbookRun.ID, "user_id", 0, invalid)
+		require.Error(t, err)
+	})
+}
+
+func TestChecklistSubItems(t *testing.T) {
+	setup := func(t *testing.T) (*plugintest.API, *mock_app.MockPlaybookRunStore, *mock_bot.MockPoster, *app.PlaybookRunServiceImpl, *app.PlaybookRun) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{
+			ID:        model.NewId(),
+			ChannelID: "channel_id",
+			Checklists: []app.Checklist{{Items: []app.ChecklistItem{{
+				Title:    "Roll back",
+				SubItems: []app.ChecklistSubItem{{ID: "sub_item_id", Title: "Revert the migration"}},
+			}}}},
+		}
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil).AnyTimes()
+		pluginAPI.On("HasPermissionToChannel", "user_id", "channel_id", model.PERMISSION_READ_CHANNEL).Return(true)
+		pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Username: "username"}, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{})
+		return pluginAPI, store, poster, s, playbookRun
+	}
+
+	t.Run("checks off a sub-item and records it in the timeline", func(t *testing.T) {
+		_, store, poster, s, playbookRun := setup(t)
+
+		poster.EXPECT().PostMessage("channel_id", "username checked off **Revert the migration** of checklist item **Roll back**").
+			Return(&model.Post{Id: "post_id"}, nil)
+		store.EXPECT().UpdatePlaybookRun(playbookRun).Return(nil)
+		store.EXPECT().CreateTimelineEvent(gomock.AssignableToTypeOf(&app.TimelineEvent{})).
+			DoAndReturn(func(event *app.TimelineEvent) (*app.TimelineEvent, error) {
+				require.Equal(t, app.TaskStateModified, event.EventType)
+				require.Equal(t, "post_id", event.PostID)
+				return event, nil
+			})
+		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), "channel_id")
+
+		err := s.ModifyChecklistSubItemState(playbookRun.ID, "user_id", app.ChecklistItemStateClosed, 0, 0, 0)
+		require.NoError(t, err)
+
+		subItem := playbookRun.Checklists[0].Items[0].SubItems[0]
+		require.Equal(t, app.ChecklistItemStateClosed, subItem.State)
+		require.NotZero(t, subItem.StateModified)
+		require.Equal(t, app.ChecklistItemStateOpen, playbookRun.Checklists[0].Items[0].State, "the item keeps its own state")
+
+		err = s.ModifyChecklistSubItemState(playbookRun.ID, "user_id", app.ChecklistItemStateClosed, 0, 0, 0)
+		require.NoError(t, err, "setting the same state is a no-op")
+	})
+
+	t.Run("invalid sub-item", func(t *testing.T) {
+		_, _, _, s, playbookRun := setup(t)
+
+		err := s.ModifyChecklistSubItemState(playbookRun.ID, "user_id", app.ChecklistItemStateClosed, 0, 0, 1)
+		require.Error(t, err)
+	})
+
+	t.Run("comments start a thread of the item", func(t *testing.T) {
+		pluginAPI, store, poster, s, playbookRun := setup(t)
+
+		poster.EXPECT().PostMessage("channel_id", "Comments on the checklist item **%s**", "Roll back").
+			Return(&model.Post{Id: "root_post_id"}, nil)
+		pluginAPI.On("CreatePost", mock.AnythingOfType("*model.Post")).
+			Return(func(post *model.Post) *model.Post {
+				require.Equal(t, "user_id", post.UserId)
+				require.Equal(t, "root_post_id", post.RootId)
+				return &model.Post{Id: model.NewId(), UserId: post.UserId, RootId: post.RootId, Message: post.Message}
+			}, nil)
+		store.EXPECT().UpdatePlaybookRun(playbookRun).Return(nil).Times(2)
+		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), "channel_id").Times(2)
+
+		comment, err := s.AddChecklistItemComment(playbookRun.ID, "user_id", 0, 0, "Waiting on the DBA.")
+		require.NoError(t, err)
+		require.Equal(t, "user_id", comment.UserID)
+		require.NotEmpty(t, comment.PostID)
+
+		_, err = s.AddChecklistItemComment(playbookRun.ID, "user_id", 0, 0, "Done.")
+		require.NoError(t, err)
+
+		item := playbookRun.Checklists[0].Items[0]
+		require.Equal(t, "root_post_id", item.CommentsPostID)
+		require.Len(t, item.Comments, 2)
+		require.Equal(t, "Done.", item.Comments[1].Message)
+	})
+}
+
+func TestSkipChecklistItem(t *testing.T) {
+	setup := func(t *testing.T) (*mock_app.MockPlaybookRunStore, *mock_bot.MockPoster, *app.PlaybookRunServiceImpl, *app.PlaybookRun) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{
+			ID:        model.NewId(),
+			ChannelID: "channel_id",
+			Checklists: []app.Checklist{
+				{Title: "Triage", Items: []app.ChecklistItem{{Title: "Page the DBA"}}},
+				{Title: "Resolve", Items: []app.ChecklistItem{{Title: "Roll back"}}},
+			},
+		}
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil).AnyTimes()
+		pluginAPI.On("HasPermissionToChannel", "user_id", "channel_id", model.PERMISSION_READ_CHANNEL).Return(true)
+		pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Username: "username"}, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{})
+		return store, poster, s, playbookRun
+	}
+
+	t.Run("records the reason in the timeline and starts the next stage", func(t *testing.T) {
+		store, poster, s, playbookRun := setup(t)
+
+		poster.EXPECT().PostMessage("channel_id", "username skipped checklist item **Page the DBA**: The DBA is on call already").
+			Return(&model.Post{Id: "post_id"}, nil)
+		store.EXPECT().UpdatePlaybookRun(playbookRun).Return(nil)
+		store.EXPECT().CreateTimelineEvent(gomock.AssignableToTypeOf(&app.TimelineEvent{})).
+			DoAndReturn(func(event *app.TimelineEvent) (*app.TimelineEvent, error) {
+				require.Equal(t, app.TaskStateModified, event.EventType)
+				require.Equal(t, "skipped checklist item **Page the DBA**: The DBA is on call already", event.Summary)
+				return event, nil
+			})
+		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), "channel_id")
+
+		err := s.SkipChecklistItem(playbookRun.ID, "user_id", 0, 0, " The DBA is on call already ")
+		require.NoError(t, err)
+
+		item := playbookRun.Checklists[0].Items[0]
+		require.Equal(t, app.ChecklistItemStateSkipped, item.State)
+		require.Equal(t, "The DBA is on call already", item.SkipReason)
+		require.Equal(t, 1, playbookRun.ActiveStage, "skipped items count as done")
+	})
+
+	t.Run("requires a reason", func(t *testing.T) {
+		_, _, s, playbookRun := setup(t)
+
+		err := s.SkipChecklistItem(playbookRun.ID, "user_id", 0, 0, "  ")
+		require.True(t, errors.Is(err, app.ErrMalformedPlaybookRun))
+
+		err = s.ModifyCheckedState(playbookRun.ID, "user_id", app.ChecklistItemStateSkipped, 0, 0, false)
+		require.True(t, errors.Is(err, app.ErrMalformedPlaybookRun))
+	})
+}
+
+func TestModifyCheckedStateVersionConflict(t *testing.T) {
+	setup := func(t *testing.T) (*plugintest.API, *mock_app.MockPlaybookRunStore, *mock_bot.MockPoster, *app.PlaybookRunServiceImpl, *app.PlaybookRun) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{
+			ID:         model.NewId(),
+			ChannelID:  "channel_id",
+			Checklists: []app.Checklist{{Title: "Triage", Items: []app.ChecklistItem{{Title: "Page the DBA"}}}},
+		}
+		// Each read gets its own copy of the run, as from the database.
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).
+			DoAndReturn(func(string) (*app.PlaybookRun, error) { return playbookRun.Clone(), nil }).
+			AnyTimes()
+		pluginAPI.On("HasPermissionToChannel", "user_id", "channel_id", model.PERMISSION_READ_CHANNEL).Return(true)
+		pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Username: "username"}, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{})
+		return pluginAPI, store, poster, s, playbookRun
+	}
+	conflict := errors.Wrap(app.ErrPlaybookRunVersionConflict, "playbook run is no longer at version 0")
+
+	t.Run("retries the change and retracts its message", func(t *testing.T) {
+		pluginAPI, store, poster, s, playbookRun := setup(t)
+
+		poster.EXPECT().PostMessage("channel_id", "username checked off checklist item **Page the DBA**").
+			Return(&model.Post{Id: "conflicting_post_id"}, nil)
+		poster.EXPECT().PostMessage("channel_id", "username checked off checklist item **Page the DBA**").
+			Return(&model.Post{Id: "post_id"}, nil)
+		pluginAPI.On("DeletePost", "conflicting_post_id").Return(nil)
+		gomock.InOrder(
+			store.EXPECT().UpdatePlaybookRun(gomock.Any()).Return(conflict),
+			store.EXPECT().UpdatePlaybookRun(gomock.Any()).Return(nil),
+		)
+		store.EXPECT().CreateTimelineEvent(gomock.AssignableToTypeOf(&app.TimelineEvent{})).
+			DoAndReturn(func(event *app.TimelineEvent) (*app.TimelineEvent, error) {
+				require.Equal(t, "post_id", event.PostID)
+				return event, nil
+			})
+		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), "channel_id")
 
-		mattermostConfig := &model.Config{}
-		mattermostConfig.SetDefaults()
-		siteURL := "http://example.com"
-		mattermostConfig.ServiceSettings.SiteURL = &siteURL
-		pluginAPI.On("GetConfig").Return(mattermostConfig)
-		pluginAPI.On("CreateChannel", mock.Anything).Return(&model.Channel{Id: "channel_id", TeamId: "team_id"}, nil)
-		pluginAPI.On("AddUserToChannel", "channel_id", "user_id", "bot_user_id").Return(nil, nil)
-		pluginAPI.On("UpdateChannelMemberRoles", "channel_id", "user_id", mock.Anything).Return(nil, nil)
-		pluginAPI.On("CreateTeamMember", "team_id", "bot_user_id").Return(nil, nil)
-		pluginAPI.On("AddChannelMember", "channel_id", "bot_user_id").Return(nil, nil)
-		pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Username: "username"}, nil)
-		pluginAPI.On("GetTeam", teamID).Return(&model.Team{Id: teamID, Name: "ad-1"}, nil)
-		pluginAPI.On("GetChannel", mock.Anything).Return(&model.Channel{Id: "channel_id", Name: "channel-name"}, nil)
+		err := s.ModifyCheckedState(playbookRun.ID, "user_id", app.ChecklistItemStateClosed, 0, 0, false)
+		require.NoError(t, err)
+		pluginAPI.AssertCalled(t, "DeletePost", "conflicting_post_id")
+	})
 
-		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+	t.Run("gives up after repeated conflicts", func(t *testing.T) {
+		pluginAPI, store, poster, s, playbookRun := setup(t)
 
-		createdPlaybookRun, err := s.CreatePlaybookRun(playbookRun, nil, "user_id", true)
-		require.NoError(t, err)
+		poster.EXPECT().PostMessage("channel_id", "username checked off checklist item **Page the DBA**").
+			Return(&model.Post{Id: "post_id"}, nil).Times(3)
+		pluginAPI.On("DeletePost", "post_id").Return(nil)
+		store.EXPECT().UpdatePlaybookRun(gomock.Any()).Return(conflict).Times(3)
 
-		select {
-		case payload := <-webhookChan:
-			require.Equal(t, *createdPlaybookRun, payload.PlaybookRun)
-			require.Equal(t,
-				"http://example.com/ad-1/channels/channel-name",
-				payload.ChannelURL)
-			require.Equal(t,
-				"http://example.com/ad-1/com.mattermost.plugin-incident-management/runs/"+createdPlaybookRun.ID,
-				payload.DetailsURL)
+		err := s.ModifyCheckedState(playbookRun.ID, "user_id", app.ChecklistItemStateClosed, 0, 0, false)
+		require.True(t, errors.Is(err, app.ErrPlaybookRunVersionConflict))
+		pluginAPI.AssertNumberOfCalls(t, "DeletePost", 3)
+	})
 
-		case <-time.After(time.Second * 5):
-			require.Fail(t, "did not receive webhook")
-		}
+	t.Run("with an expected version, does not retry the change", func(t *testing.T) {
+		pluginAPI, store, poster, s, playbookRun := setup(t)
 
-		pluginAPI.AssertExpectations(t)
+		poster.EXPECT().PostMessage("channel_id", "username checked off checklist item **Page the DBA**").
+			Return(&model.Post{Id: "post_id"}, nil)
+		pluginAPI.On("DeletePost", "post_id").Return(nil)
+		store.EXPECT().UpdatePlaybookRun(gomock.Any()).Return(conflict)
+
+		err := s.WithExpectedVersion(0).ModifyCheckedState(playbookRun.ID, "user_id", app.ChecklistItemStateClosed, 0, 0, false)
+		require.True(t, errors.Is(err, app.ErrPlaybookRunPreconditionFailed))
+		pluginAPI.AssertCalled(t, "DeletePost", "post_id")
+	})
+
+	t.Run("with an expected version, does not change a run read at another version", func(t *testing.T) {
+		pluginAPI, _, poster, s, playbookRun := setup(t)
+		playbookRun.Version = 4
+
+		poster.EXPECT().PostMessage("channel_id", "username checked off checklist item **Page the DBA**").
+			Return(&model.Post{Id: "post_id"}, nil)
+		pluginAPI.On("DeletePost", "post_id").Return(nil)
+
+		err := s.WithExpectedVersion(3).ModifyCheckedState(playbookRun.ID, "user_id", app.ChecklistItemStateClosed, 0, 0, false)
+		require.True(t, errors.Is(err, app.ErrPlaybookRunPreconditionFailed))
 	})
 }
 
-func TestUpdateStatus(t *testing.T) {
-	t.Run("webhook is sent on status update", func(t *testing.T) {
+func TestApplyChecklistOperations(t *testing.T) {
+	setup := func(t *testing.T) (*mock_app.MockPlaybookRunStore, *mock_bot.MockPoster, *app.PlaybookRunServiceImpl, *app.PlaybookRun) {
 		controller := gomock.NewController(t)
 		pluginAPI := &plugintest.API{}
 		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
@@ -351,90 +1467,128 @@ func TestUpdateStatus(t *testing.T) {
 		poster := mock_bot.NewMockPoster(controller)
 		logger := mock_bot.NewMockLogger(controller)
 		configService := mock_config.NewMockService(controller)
-		telemetryService := &telemetry.NoopTelemetry{}
 		scheduler := mock_app.NewMockJobOnceScheduler(controller)
 
-		type webhookPayload struct {
-			app.PlaybookRun
-			ChannelURL   string                  `json:"channel_url"`
-			DetailsURL   string                  `json:"details_url"`
-			StatusUpdate app.StatusUpdateOptions `json:"status_update"`
+		playbookRun := &app.PlaybookRun{
+			ID:          model.NewId(),
+			OwnerUserID: "user_id",
+			ChannelID:   "channel_id",
+			Checklists: []app.Checklist{
+				{Title: "Triage", Items: []app.ChecklistItem{{Title: "Page the DBA"}, {Title: "Notify customers"}}},
+				{Title: "Resolve", Items: []app.ChecklistItem{{Title: "Roll back"}}},
+			},
 		}
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil).AnyTimes()
+		pluginAPI.On("HasPermissionToChannel", "user_id", "channel_id", model.PERMISSION_READ_CHANNEL).Return(true)
+		pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Username: "username"}, nil)
+		pluginAPI.On("GetUser", "assignee_id").Return(&model.User{Id: "assignee_id", Username: "assignee"}, nil)
 
-		webhookChan := make(chan webhookPayload)
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{})
+		return store, poster, s, playbookRun
+	}
 
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			body, err := ioutil.ReadAll(r.Body)
-			require.NoError(t, err)
+	t.Run("applies all operations with a single update and message", func(t *testing.T) {
+		store, poster, s, playbookRun := setup(t)
+
+		poster.EXPECT().PostMessage("channel_id", "username updated the checklists:\n"+
+			"* checked off checklist item **Page the DBA**\n"+
+			"* skipped checklist item **Notify customers**: Internal only\n"+
+			"* changed assignee of checklist item **Roll back** from **No Assignee** to **@assignee**\n"+
+			"* edited checklist item **Roll back the deploy**\n"+
+			"* added checklist item **Write the postmortem**\n"+
+			"* moved checklist item **Write the postmortem**").
+			Return(&model.Post{Id: "post_id"}, nil)
+		store.EXPECT().UpdatePlaybookRun(playbookRun).Return(nil)
+		var events []*app.TimelineEvent
+		store.EXPECT().CreateTimelineEvent(gomock.AssignableToTypeOf(&app.TimelineEvent{})).
+			DoAndReturn(func(event *app.TimelineEvent) (*app.TimelineEvent, error) {
+				events = append(events, event)
+				return event, nil
+			}).Times(3)
+		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", playbookRun, "channel_id")
+
+		err := s.ApplyChecklistOperations(playbookRun.ID, "user_id", []app.ChecklistOperation{
+			{Type: app.ChecklistOperationSetState, ChecklistNum: 0, ItemNum: 0, NewState: app.ChecklistItemStateClosed},
+			{Type: app.ChecklistOperationSetState, ChecklistNum: 0, ItemNum: 1, NewState: app.ChecklistItemStateSkipped, Reason: "Internal only"},
+			{Type: app.ChecklistOperationSetAssignee, ChecklistNum: 1, ItemNum: 0, AssigneeID: "assignee_id"},
+			{Type: app.ChecklistOperationEdit, ChecklistNum: 1, ItemNum: 0, Title: "Roll back the deploy"},
+			{Type: app.ChecklistOperationAdd, ChecklistNum: 1, Item: &app.ChecklistItem{Title: "Write the postmortem"}},
+			{Type: app.ChecklistOperationMove, ChecklistNum: 1, ItemNum: 1, NewLocation: 0},
+		})
+		require.NoError(t, err)
 
-			var p webhookPayload
-			err = json.Unmarshal(body, &p)
-			require.NoError(t, err)
+		triage, resolve := playbookRun.Checklists[0], playbookRun.Checklists[1]
+		require.Equal(t, app.ChecklistItemStateClosed, triage.Items[0].State)
+		require.Equal(t, "post_id", triage.Items[0].StateModifiedPostID)
+		require.Equal(t, app.ChecklistItemStateSkipped, triage.Items[1].State)
+		require.Equal(t, "Internal only", triage.Items[1].SkipReason)
+		require.Equal(t, "Write the postmortem", resolve.Items[0].Title)
+		require.NotEmpty(t, resolve.Items[0].ID)
+		require.Equal(t, "Roll back the deploy", resolve.Items[1].Title)
+		require.Equal(t, "assignee_id", resolve.Items[1].AssigneeID)
+		require.Equal(t, "post_id", resolve.Items[1].AssigneeModifiedPostID)
+		require.Equal(t, 1, playbookRun.ActiveStage)
+
+		require.Len(t, events, 3)
+		require.Equal(t, app.TaskStateModified, events[0].EventType)
+		require.Equal(t, app.AssigneeChanged, events[2].EventType)
+		require.Equal(t, "post_id", events[2].PostID)
+	})
 
-			webhookChan <- p
-		}))
+	t.Run("applies no operation if one fails", func(t *testing.T) {
+		_, _, s, playbookRun := setup(t)
 
-		playbookRunID := model.NewId()
-		teamID := model.NewId()
-		playbookRun := &app.PlaybookRun{
-			ID:                       playbookRunID,
-			Name:                     "Name",
-			TeamID:                   teamID,
-			ChannelID:                "channel_id",
-			BroadcastChannelID:       "broadcast_channel_id",
-			OwnerUserID:              "user_id",
-			CurrentStatus:            app.StatusReported,
-			CreateAt:                 1620018358404,
-			WebhookOnStatusUpdateURL: server.URL,
-		}
-		statusUpdateOptions := app.StatusUpdateOptions{
-			Status:      app.StatusActive,
-			Description: "latest-description",
-			Message:     "latest-message",
-			Reminder:    0,
-		}
-		siteURL := "http://example.com"
-		channelID := "channel_id"
+		err := s.ApplyChecklistOperations(playbookRun.ID, "user_id", []app.ChecklistOperation{
+			{Type: app.ChecklistOperationSetState, ChecklistNum: 0, ItemNum: 0, NewState: app.ChecklistItemStateClosed},
+			{Type: app.ChecklistOperationSetState, ChecklistNum: 0, ItemNum: 1, NewState: app.ChecklistItemStateSkipped},
+		})
+		require.True(t, errors.Is(err, app.ErrMalformedPlaybookRun))
+		require.Equal(t, app.ChecklistItemStateOpen, playbookRun.Checklists[0].Items[0].State)
 
-		store.EXPECT().CreateTimelineEvent(gomock.AssignableToTypeOf(&app.TimelineEvent{}))
-		store.EXPECT().UpdatePlaybookRun(gomock.AssignableToTypeOf(&app.PlaybookRun{})).Return(nil)
-		store.EXPECT().UpdateStatus(gomock.AssignableToTypeOf(&app.SQLStatusPost{})).Return(nil)
-		store.EXPECT().GetPlaybookRun(gomock.Any()).Return(playbookRun, nil).Times(2)
+		err = s.ApplyChecklistOperations(playbookRun.ID, "user_id", []app.ChecklistOperation{
+			{Type: app.ChecklistOperationMove, ChecklistNum: 0, ItemNum: 2},
+		})
+		require.True(t, errors.Is(err, app.ErrMalformedPlaybookRun))
 
-		configService.EXPECT().GetManifest().Return(&model.Manifest{Id: "com.mattermost.plugin-incident-management"}).Times(2)
+		err = s.ApplyChecklistOperations(playbookRun.ID, "user_id", []app.ChecklistOperation{{Type: "delete"}})
+		require.True(t, errors.Is(err, app.ErrMalformedPlaybookRun))
+	})
 
-		poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), channelID)
-		poster.EXPECT().PostMessage("broadcast_channel_id", gomock.Any()).Return(&model.Post{}, nil)
+	t.Run("items are added open and unassigned", func(t *testing.T) {
+		_, _, s, playbookRun := setup(t)
 
-		scheduler.EXPECT().Cancel(playbookRun.ID)
+		err := s.ApplyChecklistOperations(playbookRun.ID, "user_id", []app.ChecklistOperation{
+			{Type: app.ChecklistOperationAdd, ChecklistNum: 1, Item: &app.ChecklistItem{Title: "Write the postmortem", State: app.ChecklistItemStateClosed}},
+		})
+		require.True(t, errors.Is(err, app.ErrMalformedPlaybookRun))
 
-		mattermostConfig := &model.Config{}
-		mattermostConfig.SetDefaults()
-		mattermostConfig.ServiceSettings.SiteURL = &siteURL
-		pluginAPI.On("CreatePost", mock.Anything).Return(&model.Post{}, nil)
-		pluginAPI.On("GetChannel", channelID).Return(&model.Channel{Id: channelID, Name: "channel_name"}, nil)
-		pluginAPI.On("GetTeam", teamID).Return(&model.Team{Id: teamID, Name: "team_name"}, nil)
-		pluginAPI.On("GetUser", "user_id").Return(&model.User{}, nil)
-		pluginAPI.On("GetConfig").Return(&model.Config{ServiceSettings: model.ServiceSettings{SiteURL: &siteURL}})
+		err = s.ApplyChecklistOperations(playbookRun.ID, "user_id", []app.ChecklistOperation{
+			{Type: app.ChecklistOperationAdd, ChecklistNum: 1, Item: &app.ChecklistItem{Title: "Write the postmortem", AssigneeID: "assignee_id"}},
+		})
+		require.True(t, errors.Is(err, app.ErrMalformedPlaybookRun))
+		require.Len(t, playbookRun.Checklists[1].Items, 1)
+	})
 
-		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+	t.Run("edited items keep a valid command trigger", func(t *testing.T) {
+		_, _, s, playbookRun := setup(t)
+		playbookRun.Checklists[1].Items[0].Command = "/rollback"
+		playbookRun.Checklists[1].Items[0].CommandTrigger = app.ChecklistItemCommandTriggerStageStart
 
-		err := s.UpdateStatus(playbookRun.ID, "user_id", statusUpdateOptions)
-		require.NoError(t, err)
+		err := s.ApplyChecklistOperations(playbookRun.ID, "user_id", []app.ChecklistOperation{
+			{Type: app.ChecklistOperationEdit, ChecklistNum: 1, ItemNum: 0, Title: "Roll back"},
+		})
+		require.True(t, errors.Is(err, app.ErrMalformedPlaybookRun))
+		require.Equal(t, "/rollback", playbookRun.Checklists[1].Items[0].Command)
+	})
 
-		select {
-		case payload := <-webhookChan:
-			require.Equal(t, *playbookRun, payload.PlaybookRun)
-			require.Equal(t,
-				"http://example.com/team_name/channels/channel_name",
-				payload.ChannelURL)
-			require.Equal(t,
-				fmt.Sprintf("http://example.com/team_name/com.mattermost.plugin-incident-management/runs/%s", playbookRunID),
-				payload.DetailsURL)
+	t.Run("operations that change nothing", func(t *testing.T) {
+		_, _, s, playbookRun := setup(t)
 
-		case <-time.After(time.Second * 5):
-			require.Fail(t, "did not receive webhook on status update")
-		}
+		err := s.ApplyChecklistOperations(playbookRun.ID, "user_id", []app.ChecklistOperation{
+			{Type: app.ChecklistOperationSetState, ChecklistNum: 0, ItemNum: 0, NewState: app.ChecklistItemStateOpen},
+			{Type: app.ChecklistOperationMove, ChecklistNum: 0, ItemNum: 1, NewLocation: 1},
+		})
+		require.NoError(t, err)
 	})
 }
 
@@ -671,3 +1825,209 @@ func TestUserHasJoinedChannel(t *testing.T) {
 		s.UserHasJoinedChannel(userID, channelID, actorID)
 	})
 }
+
+func TestStartPlaybookRunFromAlert(t *testing.T) {
+	playbook := app.Playbook{
+		ID:                  "playbook_id",
+		Title:               "My Playbook",
+		TeamID:              "team_id",
+		DefaultOwnerEnabled: true,
+		DefaultOwnerID:      "owner_id",
+	}
+
+	t.Run("repeated alert is posted to the active playbook run", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{ID: "playbook_run_id", ChannelID: "channel_id"}
+
+		pluginAPI.On("KVSetWithOptions", mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return(true, nil)
+		store.EXPECT().GetActivePlaybookRunIDForAlert("playbook_id", "db-1").Return("playbook_run_id", nil)
+		store.EXPECT().GetPlaybookRun("playbook_run_id").Return(playbookRun, nil)
+		poster.EXPECT().PostMessage("channel_id", "The alert that started this run fired again.").Return(&model.Post{}, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		run, created, err := s.StartPlaybookRunFromAlert(playbook, app.InboundAlert{Name: "Database down", Fingerprint: "db-1"})
+		require.NoError(t, err)
+		require.False(t, created)
+		require.Equal(t, playbookRun, run)
+		pluginAPI.AssertCalled(t, "KVSetWithOptions", mock.AnythingOfType("string"), []byte{1}, mock.Anything)
+	})
+
+	t.Run("resolved alert is posted to the active playbook run", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{ID: "playbook_run_id", ChannelID: "channel_id"}
+
+		pluginAPI.On("KVSetWithOptions", mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return(true, nil)
+		store.EXPECT().GetActivePlaybookRunIDForAlert("playbook_id", "db-1").Return("playbook_run_id", nil)
+		store.EXPECT().GetPlaybookRun("playbook_run_id").Return(playbookRun, nil)
+		poster.EXPECT().PostMessage("channel_id", "The alert that started this run has been resolved.").Return(&model.Post{}, nil)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		run, created, err := s.StartPlaybookRunFromAlert(playbook, app.InboundAlert{Fingerprint: "db-1", Resolved: true})
+		require.NoError(t, err)
+		require.False(t, created)
+		require.Equal(t, playbookRun, run)
+	})
+
+	t.Run("resolved alert without an active playbook run is ignored", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		pluginAPI.On("KVSetWithOptions", mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return(true, nil)
+		store.EXPECT().GetActivePlaybookRunIDForAlert("playbook_id", "db-1").Return("", app.ErrNotFound)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		run, created, err := s.StartPlaybookRunFromAlert(playbook, app.InboundAlert{Fingerprint: "db-1", Resolved: true})
+		require.NoError(t, err)
+		require.False(t, created)
+		require.Nil(t, run)
+	})
+
+	t.Run("playbook without a default owner", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		_, _, err := s.StartPlaybookRunFromAlert(app.Playbook{ID: "playbook_id"}, app.InboundAlert{Name: "Database down"})
+		require.ErrorIs(t, err, app.ErrMalformedPlaybookRun)
+	})
+}
+
+func TestUpdateStatusFromWebhook(t *testing.T) {
+	webhook := app.InboundWebhook{Enabled: true, Token: "9g64ig7q9pds8yjz8rsgd6e36e"}
+
+	t.Run("duplicate event is ignored", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		store.EXPECT().GetPlaybookRun("playbook_run_id").Return(&app.PlaybookRun{ID: "playbook_run_id", CurrentStatus: app.StatusActive}, nil)
+		store.EXPECT().CreateInboundWebhookEvent("playbook_run_id", "event_id").Return(errors.Wrap(app.ErrDuplicateEntry, "duplicate key"))
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		applied, err := s.UpdateStatusFromWebhook("playbook_run_id", webhook, app.InboundStatusUpdate{EventID: "event_id", Message: "cleared", Resolve: true})
+		require.NoError(t, err)
+		require.False(t, applied)
+	})
+
+	t.Run("duplicate event is ignored after the run moved past its status", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		playbookRun := &app.PlaybookRun{
+			ID:            "playbook_run_id",
+			CurrentStatus: "Cleared",
+			StatusWorkflow: app.StatusWorkflow{States: []app.StatusState{
+				{Name: "Firing", Transitions: []string{"Cleared"}},
+				{Name: "Cleared", Resolved: true},
+			}},
+		}
+		store.EXPECT().GetPlaybookRun("playbook_run_id").Return(playbookRun, nil)
+		store.EXPECT().CreateInboundWebhookEvent("playbook_run_id", "event_id").Return(errors.Wrap(app.ErrDuplicateEntry, "duplicate key"))
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		applied, err := s.UpdateStatusFromWebhook("playbook_run_id", webhook, app.InboundStatusUpdate{EventID: "event_id", Message: "firing", Status: "Firing"})
+		require.NoError(t, err)
+		require.False(t, applied)
+	})
+
+	t.Run("invalid status forgets the event", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		gomock.InOrder(
+			store.EXPECT().GetPlaybookRun("playbook_run_id").Return(&app.PlaybookRun{ID: "playbook_run_id", CurrentStatus: app.StatusActive}, nil),
+			store.EXPECT().CreateInboundWebhookEvent("playbook_run_id", "event_id").Return(nil),
+			store.EXPECT().DeleteInboundWebhookEvent("playbook_run_id", "event_id").Return(nil),
+		)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		applied, err := s.UpdateStatusFromWebhook("playbook_run_id", webhook, app.InboundStatusUpdate{EventID: "event_id", Message: "cleared", Status: "Unknown"})
+		require.ErrorIs(t, err, app.ErrMalformedPlaybookRun)
+		require.False(t, applied)
+	})
+
+	t.Run("failed update forgets the event", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		telemetryService := &telemetry.NoopTelemetry{}
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		gomock.InOrder(
+			store.EXPECT().GetPlaybookRun("playbook_run_id").Return(&app.PlaybookRun{ID: "playbook_run_id", CurrentStatus: app.StatusActive}, nil),
+			store.EXPECT().CreateInboundWebhookEvent("playbook_run_id", "event_id").Return(nil),
+			store.EXPECT().GetPlaybookRun("playbook_run_id").Return(nil, errors.New("db unavailable")),
+			store.EXPECT().DeleteInboundWebhookEvent("playbook_run_id", "event_id").Return(nil),
+		)
+		configService.EXPECT().GetConfiguration().Return(&config.Configuration{BotUserID: "bot_user_id"})
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService)
+
+		applied, err := s.UpdateStatusFromWebhook("playbook_run_id", webhook, app.InboundStatusUpdate{EventID: "event_id", Message: "cleared", Resolve: true})
+		require.Error(t, err)
+		require.False(t, applied)
+	})
+}
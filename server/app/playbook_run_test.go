@@ -2,6 +2,7 @@ package app
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/mattermost/mattermost-server/v5/model"
@@ -15,6 +16,15 @@ func TestPlaybookRun_MarshalJSON(t *testing.T) {
 	require.NotContains(t, string(result), "null", "update MarshalJSON to initialize nil slices")
 }
 
+func TestPlaybookRun_SeverityRank(t *testing.T) {
+	levels := []string{"SEV-1", "SEV-2", "SEV-3"}
+
+	require.Equal(t, 3, (&PlaybookRun{Severity: "SEV-1", SeverityLevels: levels}).SeverityRank())
+	require.Equal(t, 1, (&PlaybookRun{Severity: "SEV-3", SeverityLevels: levels}).SeverityRank())
+	require.Equal(t, 0, (&PlaybookRun{Severity: "", SeverityLevels: levels}).SeverityRank())
+	require.Equal(t, 0, (&PlaybookRun{Severity: "SEV-9", SeverityLevels: levels}).SeverityRank())
+}
+
 func TestPlaybookRun_LastResovedAt(t *testing.T) {
 	for name, tc := range map[string]struct {
 		inc      PlaybookRun
@@ -155,6 +165,29 @@ func TestPlaybookRun_LastResovedAt(t *testing.T) {
 			},
 			expected: 0,
 		},
+		"resolution with custom workflow": {
+			inc: PlaybookRun{
+				StatusWorkflow: testStatusWorkflow(),
+				StatusPosts: []StatusPost{
+					{
+						DeleteAt: 0,
+						CreateAt: 123,
+						Status:   "Mitigating",
+					},
+					{
+						DeleteAt: 0,
+						CreateAt: 223,
+						Status:   "Monitoring",
+					},
+					{
+						DeleteAt: 0,
+						CreateAt: 323,
+						Status:   "Done",
+					},
+				},
+			},
+			expected: 223,
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			require.Equal(t, tc.expected, tc.inc.ResolvedAt())
@@ -162,6 +195,17 @@ func TestPlaybookRun_LastResovedAt(t *testing.T) {
 	}
 }
 
+func TestPlaybookRun_IsActive(t *testing.T) {
+	require.True(t, (&PlaybookRun{CurrentStatus: StatusActive}).IsActive())
+	require.False(t, (&PlaybookRun{CurrentStatus: StatusResolved}).IsActive())
+	require.False(t, (&PlaybookRun{CurrentStatus: StatusArchived}).IsActive())
+
+	workflow := testStatusWorkflow()
+	require.True(t, (&PlaybookRun{CurrentStatus: "Mitigating", StatusWorkflow: workflow}).IsActive())
+	require.False(t, (&PlaybookRun{CurrentStatus: "Monitoring", StatusWorkflow: workflow}).IsActive())
+	require.True(t, (&PlaybookRun{CurrentStatus: StatusResolved, StatusWorkflow: workflow}).IsActive(), "statuses outside the workflow are not resolved")
+}
+
 func TestPlaybookRunFilterOptions_Clone(t *testing.T) {
 	options := PlaybookRunFilterOptions{
 		TeamID:     "team_id",
@@ -244,6 +288,37 @@ func TestPlaybookRunFilterOptions_Validate(t *testing.T) {
 		require.Equal(t, SortByCreateAt, validOptions.Sort)
 	})
 
+	t.Run("custom field value without custom field id", func(t *testing.T) {
+		options := PlaybookRunFilterOptions{
+			CustomFieldValue: "eu",
+		}
+
+		_, err := options.Validate()
+		require.Error(t, err)
+	})
+
+	t.Run("invalid custom field id", func(t *testing.T) {
+		options := PlaybookRunFilterOptions{
+			CustomFieldID:    "invalid",
+			CustomFieldValue: "eu",
+		}
+
+		_, err := options.Validate()
+		require.Error(t, err)
+	})
+
+	t.Run("custom field filter", func(t *testing.T) {
+		options := PlaybookRunFilterOptions{
+			CustomFieldID:    model.NewId(),
+			CustomFieldValue: " eu ",
+		}
+
+		validOptions, err := options.Validate()
+		require.NoError(t, err)
+		require.Equal(t, options.CustomFieldID, validOptions.CustomFieldID)
+		require.Equal(t, "eu", validOptions.CustomFieldValue)
+	})
+
 	t.Run("invalid sort direction", func(t *testing.T) {
 		options := PlaybookRunFilterOptions{
 			TeamID:    model.NewId(),
@@ -336,3 +411,112 @@ func TestPlaybookRunFilterOptions_Validate(t *testing.T) {
 		require.Equal(t, options, validOptions)
 	})
 }
+
+func TestChecklistItemFilterOptions_Validate(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		validOptions, err := ChecklistItemFilterOptions{}.Validate()
+		require.NoError(t, err)
+		require.Equal(t, SortByDueAt, validOptions.Sort)
+		require.Equal(t, DirectionAsc, validOptions.Direction)
+	})
+
+	t.Run("valid, but wrong case sort and direction", func(t *testing.T) {
+		validOptions, err := ChecklistItemFilterOptions{
+			Sort:      SortField("Assigned_AT"),
+			Direction: SortDirection("desc"),
+		}.Validate()
+		require.NoError(t, err)
+		require.Equal(t, SortByAssignedAt, validOptions.Sort)
+		require.Equal(t, DirectionDesc, validOptions.Direction)
+	})
+
+	t.Run("invalid sort option", func(t *testing.T) {
+		_, err := ChecklistItemFilterOptions{Sort: SortByName}.Validate()
+		require.Error(t, err)
+	})
+
+	t.Run("invalid team id", func(t *testing.T) {
+		_, err := ChecklistItemFilterOptions{TeamID: "invalid"}.Validate()
+		require.Error(t, err)
+	})
+
+	t.Run("invalid state", func(t *testing.T) {
+		_, err := ChecklistItemFilterOptions{
+			States: []string{ChecklistItemStateOpen, "done"},
+		}.Validate()
+		require.Error(t, err)
+	})
+}
+
+func TestUpdateOptions_Validate(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		validOptions, err := UpdateOptions{}.Validate()
+		require.NoError(t, err)
+		require.Equal(t, UpdateOptions{}, validOptions)
+	})
+
+	t.Run("name is trimmed", func(t *testing.T) {
+		validOptions, err := UpdateOptions{Name: model.NewString("  Outage \t")}.Validate()
+		require.NoError(t, err)
+		require.Equal(t, "Outage", *validOptions.Name)
+	})
+
+	t.Run("blank name", func(t *testing.T) {
+		_, err := UpdateOptions{Name: model.NewString(" \t ")}.Validate()
+		require.ErrorIs(t, err, ErrMalformedPlaybookRun)
+	})
+
+	t.Run("name too long", func(t *testing.T) {
+		_, err := UpdateOptions{Name: model.NewString(strings.Repeat("a", model.CHANNEL_DISPLAY_NAME_MAX_RUNES+1))}.Validate()
+		require.ErrorIs(t, err, ErrMalformedPlaybookRun)
+	})
+
+	t.Run("invalid broadcast channel id", func(t *testing.T) {
+		_, err := UpdateOptions{BroadcastChannelID: model.NewString("invalid")}.Validate()
+		require.ErrorIs(t, err, ErrMalformedPlaybookRun)
+	})
+
+	t.Run("empty broadcast channel id", func(t *testing.T) {
+		validOptions, err := UpdateOptions{BroadcastChannelID: model.NewString("")}.Validate()
+		require.NoError(t, err)
+		require.Equal(t, "", *validOptions.BroadcastChannelID)
+	})
+
+	t.Run("invalid creation webhook URL", func(t *testing.T) {
+		_, err := UpdateOptions{WebhookOnCreationURL: model.NewString("not a url")}.Validate()
+		require.ErrorIs(t, err, ErrMalformedPlaybookRun)
+	})
+
+	t.Run("unsupported status update webhook protocol", func(t *testing.T) {
+		_, err := UpdateOptions{WebhookOnStatusUpdateURL: model.NewString("ftp://example.com/hook")}.Validate()
+		require.ErrorIs(t, err, ErrMalformedPlaybookRun)
+	})
+
+	t.Run("empty webhook URL", func(t *testing.T) {
+		validOptions, err := UpdateOptions{WebhookOnStatusUpdateURL: model.NewString("")}.Validate()
+		require.NoError(t, err)
+		require.Equal(t, "", *validOptions.WebhookOnStatusUpdateURL)
+	})
+
+	t.Run("negative retrospective reminder interval", func(t *testing.T) {
+		_, err := UpdateOptions{RetrospectiveReminderIntervalSeconds: model.NewInt64(-1)}.Validate()
+		require.ErrorIs(t, err, ErrMalformedPlaybookRun)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		options := UpdateOptions{
+			Name:                                 model.NewString("Outage"),
+			Description:                          model.NewString("description"),
+			BroadcastChannelID:                   model.NewString(model.NewId()),
+			ReminderMessageTemplate:              model.NewString("template"),
+			WebhookOnCreationURL:                 model.NewString("https://example.com/create"),
+			WebhookOnStatusUpdateURL:             model.NewString("http://example.com/update"),
+			MessageOnJoin:                        model.NewString("welcome"),
+			RetrospectiveReminderIntervalSeconds: model.NewInt64(3600),
+		}
+
+		validOptions, err := options.Validate()
+		require.NoError(t, err)
+		require.Equal(t, options, validOptions)
+	})
+}
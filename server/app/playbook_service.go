@@ -2,6 +2,9 @@ package app
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
@@ -41,8 +44,10 @@ func NewPlaybookService(store PlaybookStore, poster bot.Poster, telemetry Playbo
 }
 
 func (s *playbookService) Create(playbook Playbook, userID string) (string, error) {
+	playbook.CreatorID = userID
 	playbook.CreateAt = model.GetMillis()
 	playbook.UpdateAt = playbook.CreateAt
+	playbook.Revision = 1
 
 	newID, err := s.store.Create(playbook)
 	if err != nil {
@@ -50,6 +55,18 @@ func (s *playbookService) Create(playbook Playbook, userID string) (string, erro
 	}
 	playbook.ID = newID
 
+	snapshot := PlaybookSnapshot(playbook)
+	if err = s.store.CreateRevision(PlaybookRevision{
+		PlaybookID: newID,
+		Revision:   playbook.Revision,
+		UserID:     userID,
+		CreateAt:   playbook.CreateAt,
+		Changes:    []PlaybookChange{},
+		Playbook:   &snapshot,
+	}); err != nil {
+		s.api.Log.Warn("failed to save the first revision of the playbook", "playbookID", newID, "error", err.Error())
+	}
+
 	s.telemetry.CreatePlaybook(playbook, userID)
 
 	s.poster.PublishWebsocketEventToTeam(playbookCreatedWSEvent, map[string]interface{}{
@@ -76,9 +93,46 @@ func (s *playbookService) GetNumPlaybooksForTeam(teamID string) (int, error) {
 }
 
 func (s *playbookService) Update(playbook Playbook, userID string) error {
+	old, err := s.store.Get(playbook.ID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get playbook with id '%s'", playbook.ID)
+	}
+
+	if old.Revision == 0 {
+		// Save the playbook as it was before revisions were recorded, so that it can be restored.
+		baseline := PlaybookSnapshot(old)
+		baseline.Revision = 1
+		err = s.store.CreateRevision(PlaybookRevision{
+			PlaybookID: old.ID,
+			Revision:   baseline.Revision,
+			CreateAt:   old.UpdateAt,
+			Changes:    []PlaybookChange{},
+			Playbook:   &baseline,
+		})
+		if err != nil && !errors.Is(err, ErrPlaybookRevisionConflict) {
+			return errors.Wrapf(err, "failed to save the baseline revision of playbook with id '%s'", old.ID)
+		}
+		old.Revision = baseline.Revision
+	}
+
+	playbook.CreatorID = old.CreatorID
 	playbook.UpdateAt = model.GetMillis()
+	playbook.Revision = old.Revision + 1
+
+	changes, err := DiffPlaybooks(old, playbook)
+	if err != nil {
+		return errors.Wrap(err, "failed to compare playbook revisions")
+	}
 
-	if err := s.store.Update(playbook); err != nil {
+	snapshot := PlaybookSnapshot(playbook)
+	if err = s.store.UpdateWithRevision(playbook, PlaybookRevision{
+		PlaybookID: playbook.ID,
+		Revision:   playbook.Revision,
+		UserID:     userID,
+		CreateAt:   playbook.UpdateAt,
+		Changes:    changes,
+		Playbook:   &snapshot,
+	}); err != nil {
 		return err
 	}
 
@@ -105,6 +159,236 @@ func (s *playbookService) Delete(playbook Playbook, userID string) error {
 	return nil
 }
 
+func (s *playbookService) Undelete(playbook Playbook, userID string) error {
+	if playbook.ID == "" {
+		return errors.New("can't undelete a playbook without an ID")
+	}
+
+	if err := s.store.Undelete(playbook.ID); err != nil {
+		return err
+	}
+
+	s.telemetry.UndeletePlaybook(playbook, userID)
+
+	s.poster.PublishWebsocketEventToTeam(playbookCreatedWSEvent, map[string]interface{}{
+		"teamID": playbook.TeamID,
+	}, playbook.TeamID)
+
+	return nil
+}
+
+func (s *playbookService) GetRevisions(playbookID string) ([]PlaybookRevision, error) {
+	return s.store.GetRevisions(playbookID)
+}
+
+func (s *playbookService) GetRevision(playbookID string, revision int64) (PlaybookRevision, error) {
+	return s.store.GetRevision(playbookID, revision)
+}
+
+func (s *playbookService) Export(playbook Playbook) PlaybookExport {
+	return ExportPlaybook(playbook, s.api)
+}
+
+func (s *playbookService) Import(export PlaybookExport, teamID, userID string) (PlaybookImportResult, error) {
+	playbook, warnings := ImportPlaybook(export, teamID, userID, s.api)
+
+	return s.createWithWarnings(playbook, warnings, userID)
+}
+
+func (s *playbookService) Duplicate(playbook Playbook, teamID, userID string) (PlaybookImportResult, error) {
+	duplicate, warnings := DuplicatePlaybook(playbook, teamID, userID, s.api)
+
+	return s.createWithWarnings(duplicate, warnings, userID)
+}
+
+// createWithWarnings creates a playbook built from another, on behalf of the user, checking that
+// the user may create it as for any new playbook.
+func (s *playbookService) createWithWarnings(playbook Playbook, warnings []string, userID string) (PlaybookImportResult, error) {
+	if err := CreatePlaybook(userID, playbook, s.configService, s.api, s); isPermissionError(err) {
+		return PlaybookImportResult{}, errors.Wrap(ErrPermission, err.Error())
+	} else if err != nil {
+		return PlaybookImportResult{}, errors.Wrap(err, "failed to check that the playbook can be created")
+	}
+
+	if err := ValidatePlaybook(&playbook); err != nil {
+		return PlaybookImportResult{}, errors.Wrap(ErrMalformedPlaybook, err.Error())
+	}
+
+	AssignCustomFieldIDs(playbook.CustomFields)
+	AssignInboundWebhookToken(&playbook)
+
+	id, err := s.Create(playbook, userID)
+	if err != nil {
+		return PlaybookImportResult{}, errors.Wrap(err, "failed to create playbook")
+	}
+
+	return PlaybookImportResult{ID: id, Warnings: warnings}, nil
+}
+
+func (s *playbookService) Sync(teamID, userID string, files map[string][]byte, dryRun bool) (PlaybookSyncResult, error) {
+	// Concurrent syncs of a team, possibly on different servers, would create duplicate playbooks.
+	unlock, err := lockClusterMutex(s.api, "sync", teamID)
+	if err != nil {
+		return PlaybookSyncResult{}, err
+	}
+	defer unlock()
+
+	paths, exports, err := parsePlaybookSyncFiles(files)
+	if err != nil {
+		return PlaybookSyncResult{}, err
+	}
+
+	syncedIDs, err := s.store.GetSyncedPlaybookIDs(teamID)
+	if err != nil {
+		return PlaybookSyncResult{}, errors.Wrapf(err, "failed to get synced playbooks of team %s", teamID)
+	}
+
+	// Every file is checked before any playbook is changed, so that an invalid file leaves all the
+	// playbooks as they were.
+	type plannedSync struct {
+		synced   SyncedPlaybook
+		playbook Playbook
+		token    string // The inbound webhook token generated for the playbook, if any
+	}
+	planned := []plannedSync{}
+
+	for _, filePath := range paths {
+		playbook, warnings := importPlaybookSettings(exports[filePath], teamID, userID, s.api)
+		playbook.SyncPath = filePath
+		if err = ValidatePlaybook(&playbook); err != nil {
+			return PlaybookSyncResult{}, errors.Wrapf(ErrMalformedPlaybook, "%s: %s", filePath, err.Error())
+		}
+
+		synced := SyncedPlaybook{
+			Path:     filePath,
+			Title:    playbook.Title,
+			Action:   PlaybookSyncActionNone,
+			Changes:  []PlaybookChange{},
+			Warnings: warnings,
+		}
+
+		playbookID, ok := syncedIDs[filePath]
+		if !ok {
+			if err = PlaybookLicensedFeatures(playbook, s.configService, s); isPermissionError(err) {
+				return PlaybookSyncResult{}, errors.Wrapf(ErrPermission, "%s: %s", filePath, err.Error())
+			} else if err != nil {
+				return PlaybookSyncResult{}, errors.Wrapf(err, "failed to check the license of the playbook synced from %s", filePath)
+			}
+
+			AssignCustomFieldIDs(playbook.CustomFields)
+			AssignInboundWebhookToken(&playbook)
+			synced.Action = PlaybookSyncActionCreate
+			planned = append(planned, plannedSync{synced: synced, playbook: playbook, token: playbook.InboundWebhook.Token})
+			continue
+		}
+
+		var current Playbook
+		if current, err = s.store.Get(playbookID); err != nil {
+			return PlaybookSyncResult{}, errors.Wrapf(err, "failed to get playbook synced from %s", filePath)
+		}
+
+		updated := syncedPlaybookUpdate(current, playbook)
+		AssignCustomFieldIDs(updated.CustomFields)
+		AssignInboundWebhookToken(&updated)
+
+		token := ""
+		if updated.InboundWebhook.Token != current.InboundWebhook.Token {
+			token = updated.InboundWebhook.Token
+		}
+
+		var changes []PlaybookChange
+		if changes, err = DiffPlaybooks(current, updated); err != nil {
+			return PlaybookSyncResult{}, errors.Wrapf(err, "failed to compare playbook synced from %s", filePath)
+		}
+
+		synced.PlaybookID = playbookID
+		if len(changes) > 0 {
+			synced.Action = PlaybookSyncActionUpdate
+			synced.Changes = changes
+		}
+		planned = append(planned, plannedSync{synced: synced, playbook: updated, token: token})
+	}
+
+	for filePath, playbookID := range syncedIDs {
+		if _, ok := exports[filePath]; ok {
+			continue
+		}
+
+		var current Playbook
+		if current, err = s.store.Get(playbookID); err != nil {
+			return PlaybookSyncResult{}, errors.Wrapf(err, "failed to get playbook synced from %s", filePath)
+		}
+
+		planned = append(planned, plannedSync{
+			synced: SyncedPlaybook{
+				Path:       filePath,
+				PlaybookID: playbookID,
+				Title:      current.Title,
+				Action:     PlaybookSyncActionDelete,
+				Changes:    []PlaybookChange{},
+				Warnings:   []string{},
+			},
+			playbook: current,
+		})
+	}
+
+	sort.Slice(planned, func(i, j int) bool {
+		return planned[i].synced.Path < planned[j].synced.Path
+	})
+
+	result := PlaybookSyncResult{DryRun: dryRun, Playbooks: []SyncedPlaybook{}}
+	for _, p := range planned {
+		if !dryRun {
+			switch p.synced.Action {
+			case PlaybookSyncActionCreate:
+				if p.synced.PlaybookID, err = s.Create(p.playbook, userID); err != nil {
+					return PlaybookSyncResult{}, errors.Wrapf(err, "failed to create playbook synced from %s", p.synced.Path)
+				}
+			case PlaybookSyncActionUpdate:
+				if err = s.Update(p.playbook, userID); err != nil {
+					return PlaybookSyncResult{}, errors.Wrapf(err, "failed to update playbook synced from %s", p.synced.Path)
+				}
+			case PlaybookSyncActionDelete:
+				if err = s.Delete(p.playbook, userID); err != nil {
+					return PlaybookSyncResult{}, errors.Wrapf(err, "failed to delete playbook synced from %s", p.synced.Path)
+				}
+			}
+
+			// The generated tokens are only returned once saved, as those of a dry run are not.
+			p.synced.InboundWebhookToken = p.token
+		}
+
+		result.Playbooks = append(result.Playbooks, p.synced)
+	}
+
+	return result, nil
+}
+
+func (s *playbookService) SyncFromDirectory(teamID, userID string, dryRun bool) (PlaybookSyncResult, error) {
+	dir := s.configService.GetConfiguration().PlaybooksSyncDirectory
+	if dir == "" {
+		return PlaybookSyncResult{}, ErrPlaybookSyncNotConfigured
+	}
+
+	team, err := s.api.Team.Get(teamID)
+	if err != nil {
+		return PlaybookSyncResult{}, errors.Wrapf(err, "failed to get team %s", teamID)
+	}
+
+	// A missing directory is not synced as an empty one, which would delete all synced playbooks.
+	teamDir := filepath.Join(dir, team.Name)
+	if info, errStat := os.Stat(teamDir); errStat != nil || !info.IsDir() {
+		return PlaybookSyncResult{}, errors.Wrapf(ErrNotFound, "no playbooks directory %s for team %s", teamDir, team.Name)
+	}
+
+	files, err := ReadPlaybookSyncDirectory(teamDir)
+	if err != nil {
+		return PlaybookSyncResult{}, err
+	}
+
+	return s.Sync(teamID, userID, files, dryRun)
+}
+
 func (s *playbookService) MessageHasBeenPosted(sessionID string, post *model.Post) {
 	if s.keywordsThreadIgnorer.IsIgnored(post.RootId, post.UserId) {
 		return
@@ -0,0 +1,244 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MaxPlaybookSyncFileSize is the size limit of each file synced as a playbook.
+const MaxPlaybookSyncFileSize = 1024 * 1024
+
+// PlaybookSyncAction is what syncing does to a playbook.
+type PlaybookSyncAction string
+
+const (
+	PlaybookSyncActionCreate PlaybookSyncAction = "create"
+	PlaybookSyncActionUpdate PlaybookSyncAction = "update"
+	PlaybookSyncActionDelete PlaybookSyncAction = "delete"
+	PlaybookSyncActionNone   PlaybookSyncAction = "none"
+)
+
+// PlaybookSyncResult is the outcome of syncing the playbooks of a team with a set of files, or
+// what it would be for a dry run.
+type PlaybookSyncResult struct {
+	DryRun    bool             `json:"dry_run"`
+	Playbooks []SyncedPlaybook `json:"playbooks"` // Ordered by path
+}
+
+// SyncedPlaybook is the outcome of syncing a playbook with its file.
+type SyncedPlaybook struct {
+	Path       string             `json:"path"`
+	PlaybookID string             `json:"playbook_id"` // Empty for playbooks a dry run would create
+	Title      string             `json:"title"`
+	Action     PlaybookSyncAction `json:"action"`
+	Changes    []PlaybookChange   `json:"changes"`  // Of updated playbooks
+	Warnings   []string           `json:"warnings"` // The settings of the file that could not be carried over
+
+	// InboundWebhookToken is the token generated by the sync for the playbook's inbound webhook,
+	// if any. Tokens are write-only, so this is the only time it is returned.
+	InboundWebhookToken string `json:"inbound_webhook_token,omitempty"`
+}
+
+// Counts returns the number of playbooks created, updated and deleted by the sync.
+func (r PlaybookSyncResult) Counts() (created, updated, deleted int) {
+	for _, playbook := range r.Playbooks {
+		switch playbook.Action {
+		case PlaybookSyncActionCreate:
+			created++
+		case PlaybookSyncActionUpdate:
+			updated++
+		case PlaybookSyncActionDelete:
+			deleted++
+		}
+	}
+
+	return created, updated, deleted
+}
+
+// isPlaybookSyncFile returns true if the file is synced as a playbook, i.e. if it is a YAML or
+// JSON file.
+func isPlaybookSyncFile(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
+
+// playbookSyncFileFormat returns the format of a file synced as a playbook, from its extension.
+func playbookSyncFileFormat(name string) PlaybookExportFormat {
+	if strings.ToLower(path.Ext(name)) == ".json" {
+		return PlaybookExportFormatJSON
+	}
+
+	return PlaybookExportFormatYAML
+}
+
+// ReadPlaybookSyncDirectory returns the playbook files of the directory and of its subdirectories,
+// by their slash-separated path relative to the directory. Hidden files and directories, such as
+// .git, are skipped.
+func ReadPlaybookSyncDirectory(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath != dir && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !isPlaybookSyncFile(info.Name()) {
+			return nil
+		}
+		if info.Size() > MaxPlaybookSyncFileSize {
+			return errors.Errorf("file %s is larger than %d bytes", filePath, MaxPlaybookSyncFileSize)
+		}
+
+		relativePath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(relativePath)] = data
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read playbooks directory %s", dir)
+	}
+
+	return files, nil
+}
+
+// ReadPlaybookSyncArchive returns the playbook files of the zip archive, by their path in the
+// archive. Hidden files and directories are skipped.
+func ReadPlaybookSyncArchive(data []byte) (map[string][]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open playbooks archive")
+	}
+
+	files := map[string][]byte{}
+	for _, file := range reader.File {
+		name := path.Clean(strings.TrimPrefix(file.Name, "/"))
+		if file.FileInfo().IsDir() || !isPlaybookSyncFile(name) || isHiddenPath(name) {
+			continue
+		}
+		if name == ".." || strings.HasPrefix(name, "../") {
+			return nil, errors.Errorf("file %s is outside of the archive", file.Name)
+		}
+		if file.UncompressedSize64 > MaxPlaybookSyncFileSize {
+			return nil, errors.Errorf("file %s is larger than %d bytes", name, MaxPlaybookSyncFileSize)
+		}
+
+		fileData, errRead := readZipFile(file)
+		if errRead != nil {
+			return nil, errors.Wrapf(errRead, "failed to read file %s of playbooks archive", name)
+		}
+		files[name] = fileData
+	}
+
+	return files, nil
+}
+
+// isHiddenPath returns true if a file or directory of the slash-separated path is hidden.
+func isHiddenPath(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") && part != ".." {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readZipFile reads the file of the archive, checking its size as it is read, as the sizes in the
+// headers of the archive cannot be trusted.
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(reader, MaxPlaybookSyncFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxPlaybookSyncFileSize {
+		return nil, errors.Errorf("file is larger than %d bytes", MaxPlaybookSyncFileSize)
+	}
+
+	return data, nil
+}
+
+// parsePlaybookSyncFiles decodes the files, in the order of their paths.
+func parsePlaybookSyncFiles(files map[string][]byte) ([]string, map[string]PlaybookExport, error) {
+	paths := make([]string, 0, len(files))
+	for filePath := range files {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+
+	exports := make(map[string]PlaybookExport, len(files))
+	for _, filePath := range paths {
+		export, err := UnmarshalPlaybookExport(files[filePath], playbookSyncFileFormat(filePath))
+		if err != nil {
+			return nil, nil, errors.Wrapf(ErrMalformedPlaybook, "%s: %s", filePath, err.Error())
+		}
+		exports[filePath] = export
+	}
+
+	return paths, exports, nil
+}
+
+// syncedPlaybookUpdate returns the current playbook updated to the settings of its file. The
+// identity of the playbook, its timestamps and its secrets are kept, as are the IDs of its custom
+// fields, matched by name, and of its checklists, matched by position.
+func syncedPlaybookUpdate(current, synced Playbook) Playbook {
+	updated := synced.Clone()
+	updated.ID = current.ID
+	updated.TeamID = current.TeamID
+	updated.CreateAt = current.CreateAt
+	updated.UpdateAt = current.UpdateAt
+	updated.DeleteAt = current.DeleteAt
+	updated.Revision = current.Revision
+	updated.WebhookSecret = current.WebhookSecret
+
+	// The token is dropped when the file disables the webhook, so that enabling it again rotates it.
+	if updated.InboundWebhook.Enabled {
+		updated.InboundWebhook.Token = current.InboundWebhook.Token
+	}
+
+	for i := range updated.CustomFields {
+		for _, field := range current.CustomFields {
+			if strings.EqualFold(field.Name, updated.CustomFields[i].Name) {
+				updated.CustomFields[i].ID = field.ID
+				break
+			}
+		}
+	}
+
+	for i := range updated.Checklists {
+		if i < len(current.Checklists) {
+			updated.Checklists[i].ID = current.Checklists[i].ID
+		}
+	}
+
+	return updated
+}
@@ -0,0 +1,159 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPlaybookSyncDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "playbooks")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for name, content := range map[string]string{
+		"outage.yaml":         "title: Outage\n",
+		"security/breach.yml": "title: Breach\n",
+		"security/leak.json":  `{"title": "Leak"}`,
+		"README.md":           "# Playbooks\n",
+		".git/config.yaml":    "title: Hidden\n",
+		".hidden.yaml":        "title: Hidden\n",
+	} {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0700))
+		require.NoError(t, ioutil.WriteFile(filePath, []byte(content), 0600))
+	}
+
+	files, err := ReadPlaybookSyncDirectory(dir)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{
+		"outage.yaml":         []byte("title: Outage\n"),
+		"security/breach.yml": []byte("title: Breach\n"),
+		"security/leak.json":  []byte(`{"title": "Leak"}`),
+	}, files)
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := ReadPlaybookSyncDirectory(filepath.Join(dir, "missing"))
+		require.Error(t, err)
+	})
+}
+
+func TestReadPlaybookSyncArchive(t *testing.T) {
+	makeArchive := func(t *testing.T, files map[string]string) []byte {
+		var buf bytes.Buffer
+		writer := zip.NewWriter(&buf)
+		for name, content := range files {
+			w, err := writer.Create(name)
+			require.NoError(t, err)
+			_, err = w.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+
+		return buf.Bytes()
+	}
+
+	t.Run("playbook files", func(t *testing.T) {
+		files, err := ReadPlaybookSyncArchive(makeArchive(t, map[string]string{
+			"outage.yaml":         "title: Outage\n",
+			"security/breach.yml": "title: Breach\n",
+			"README.md":           "# Playbooks\n",
+			".github/ci.yaml":     "on: push\n",
+		}))
+		require.NoError(t, err)
+		require.Equal(t, map[string][]byte{
+			"outage.yaml":         []byte("title: Outage\n"),
+			"security/breach.yml": []byte("title: Breach\n"),
+		}, files)
+	})
+
+	t.Run("file outside of the archive", func(t *testing.T) {
+		_, err := ReadPlaybookSyncArchive(makeArchive(t, map[string]string{
+			"../outage.yaml": "title: Outage\n",
+		}))
+		require.Error(t, err)
+	})
+
+	t.Run("file too large", func(t *testing.T) {
+		_, err := ReadPlaybookSyncArchive(makeArchive(t, map[string]string{
+			"outage.yaml": "title: " + strings.Repeat("a", MaxPlaybookSyncFileSize) + "\n",
+		}))
+		require.Error(t, err)
+	})
+
+	t.Run("not an archive", func(t *testing.T) {
+		_, err := ReadPlaybookSyncArchive([]byte("title: Outage\n"))
+		require.Error(t, err)
+	})
+}
+
+func TestParsePlaybookSyncFiles(t *testing.T) {
+	t.Run("ordered by path", func(t *testing.T) {
+		paths, exports, err := parsePlaybookSyncFiles(map[string][]byte{
+			"security/leak.json": []byte(`{"version": 1, "title": "Leak"}`),
+			"outage.yaml":        []byte("version: 1\ntitle: Outage\n"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"outage.yaml", "security/leak.json"}, paths)
+		require.Equal(t, "Outage", exports["outage.yaml"].Title)
+		require.Equal(t, "Leak", exports["security/leak.json"].Title)
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		_, _, err := parsePlaybookSyncFiles(map[string][]byte{
+			"outage.yaml": []byte("version: [1\n"),
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "outage.yaml")
+	})
+}
+
+func TestSyncedPlaybookUpdate(t *testing.T) {
+	current := Playbook{
+		ID:             "playbookid",
+		Title:          "Outage",
+		TeamID:         "teamid",
+		CreateAt:       100,
+		UpdateAt:       200,
+		Revision:       2,
+		Checklists:     []Checklist{{ID: "checklistid", Title: "Triage"}},
+		WebhookSecret:  "secret",
+		CustomFields:   []CustomField{{ID: "fieldid", Name: "Region", Type: CustomFieldTypeText}},
+		InboundWebhook: InboundWebhook{Enabled: true, Token: "token"},
+		SyncPath:       "outage.yaml",
+	}
+
+	synced := Playbook{
+		Title:          "Major outage",
+		TeamID:         "teamid",
+		Checklists:     []Checklist{{Title: "Triage"}, {Title: "Resolve"}},
+		CustomFields:   []CustomField{{Name: "region", Type: CustomFieldTypeText}, {Name: "Customer", Type: CustomFieldTypeText}},
+		InboundWebhook: InboundWebhook{Enabled: true},
+		SyncPath:       "outage.yaml",
+	}
+
+	updated := syncedPlaybookUpdate(current, synced)
+	require.Equal(t, Playbook{
+		ID:             "playbookid",
+		Title:          "Major outage",
+		TeamID:         "teamid",
+		CreateAt:       100,
+		UpdateAt:       200,
+		Revision:       2,
+		Checklists:     []Checklist{{ID: "checklistid", Title: "Triage"}, {Title: "Resolve"}},
+		WebhookSecret:  "secret",
+		CustomFields:   []CustomField{{ID: "fieldid", Name: "region", Type: CustomFieldTypeText}, {Name: "Customer", Type: CustomFieldTypeText}},
+		InboundWebhook: InboundWebhook{Enabled: true, Token: "token"},
+		SyncPath:       "outage.yaml",
+	}, updated)
+
+	synced.InboundWebhook.Enabled = false
+	updated = syncedPlaybookUpdate(current, synced)
+	require.Empty(t, updated.InboundWebhook.Token, "disabling the webhook drops its token")
+}
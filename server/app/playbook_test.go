@@ -2,6 +2,7 @@ package app
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -81,6 +82,84 @@ func TestPlaybook_MarshalJSON(t *testing.T) {
 	}
 }
 
+func TestPlaybook_ValidateSeverityLevels(t *testing.T) {
+	for name, tc := range map[string]struct {
+		levels          []string
+		defaultSeverity string
+		wantErr         bool
+	}{
+		"no levels":                     {levels: nil},
+		"valid levels and default":      {levels: []string{"SEV-1", "SEV-2"}, defaultSeverity: "SEV-2"},
+		"blank level":                   {levels: []string{"SEV-1", " "}, wantErr: true},
+		"level with comma":              {levels: []string{"SEV-1,SEV-2"}, wantErr: true},
+		"level too long":                {levels: []string{strings.Repeat("a", MaxSeverityLevelLength+1)}, wantErr: true},
+		"duplicated level ignores case": {levels: []string{"High", "high"}, wantErr: true},
+		"default not in levels":         {levels: []string{"SEV-1"}, defaultSeverity: "SEV-2", wantErr: true},
+		"default with different case":   {levels: []string{"SEV-1"}, defaultSeverity: "sev-1", wantErr: true},
+		"default without levels":        {defaultSeverity: "SEV-1", wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := Playbook{SeverityLevels: tc.levels, DefaultSeverity: tc.defaultSeverity}.ValidateSeverityLevels()
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPlaybook_ValidateWebhookSecret(t *testing.T) {
+	for name, tc := range map[string]struct {
+		secret  string
+		wantErr bool
+	}{
+		"no secret":           {secret: ""},
+		"valid secret":        {secret: "s3cr3t"},
+		"longest secret":      {secret: strings.Repeat("a", MaxWebhookSecretLength)},
+		"secret too long":     {secret: strings.Repeat("a", MaxWebhookSecretLength+1), wantErr: true},
+		"leading whitespace":  {secret: " s3cr3t", wantErr: true},
+		"trailing whitespace": {secret: "s3cr3t\n", wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := Playbook{WebhookSecret: tc.secret}.ValidateWebhookSecret()
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestKeepPlaybookSecrets(t *testing.T) {
+	current := Playbook{WebhookSecret: "s3cr3t", InboundWebhook: InboundWebhook{Token: "9g64ig7q9pds8yjz8rsgd6e36e"}}
+
+	t.Run("redacted secrets are kept", func(t *testing.T) {
+		playbook := current
+		RedactPlaybookSecrets(&playbook)
+		require.Empty(t, playbook.WebhookSecret)
+		require.Empty(t, playbook.InboundWebhook.Token)
+
+		KeepPlaybookSecrets(&playbook, current)
+		require.Equal(t, current, playbook)
+	})
+
+	t.Run("new secrets replace the current ones", func(t *testing.T) {
+		playbook := Playbook{WebhookSecret: "other", HasWebhookSecret: true}
+		KeepPlaybookSecrets(&playbook, current)
+		require.Equal(t, "other", playbook.WebhookSecret)
+		require.False(t, playbook.HasWebhookSecret)
+	})
+
+	t.Run("secrets without their flags are cleared", func(t *testing.T) {
+		playbook := Playbook{}
+		KeepPlaybookSecrets(&playbook, current)
+		require.Empty(t, playbook.WebhookSecret)
+		require.Empty(t, playbook.InboundWebhook.Token)
+	})
+}
+
 func TestPlaybookFilterOptions_Clone(t *testing.T) {
 	options := PlaybookFilterOptions{
 		Page:      1,
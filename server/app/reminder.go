@@ -14,16 +14,40 @@ import (
 
 const RetrospectivePrefix = "retro_"
 
+// recurringReminderSuffix is appended to the key of every other occurrence of a recurring
+// reminder: jobs can't be rescheduled within themselves with the same key, so occurrences
+// alternate between the reminder's key and this second one.
+const recurringReminderSuffix = "_next"
+
+// nextReminderKey returns the key of the occurrence of a recurring reminder following the one
+// with the given key.
+func nextReminderKey(key string) string {
+	if strings.HasSuffix(key, recurringReminderSuffix) {
+		return strings.TrimSuffix(key, recurringReminderSuffix)
+	}
+
+	return key + recurringReminderSuffix
+}
+
 // HandleReminder is the handler for all reminder events.
 func (s *PlaybookRunServiceImpl) HandleReminder(key string) {
 	if strings.HasPrefix(key, RetrospectivePrefix) {
-		s.handleReminderToFillRetro(strings.TrimPrefix(key, RetrospectivePrefix))
+		s.handleReminderToFillRetro(key)
+	} else if strings.HasPrefix(key, WebhookDeliveryPrefix) {
+		s.handleWebhookDelivery(key)
+	} else if strings.HasPrefix(key, ChecklistItemDuePrefix) {
+		s.handleChecklistItemDue(key)
+	} else if strings.HasPrefix(key, DigestPrefix) {
+		s.handleDigest(key)
+	} else if strings.HasPrefix(key, ChecklistCommandsPrefix) {
+		s.handleChecklistCommands(key)
 	} else {
 		s.handleStatusUpdateReminder(key)
 	}
 }
 
-func (s *PlaybookRunServiceImpl) handleReminderToFillRetro(playbookRunID string) {
+func (s *PlaybookRunServiceImpl) handleReminderToFillRetro(key string) {
+	playbookRunID := strings.TrimSuffix(strings.TrimPrefix(key, RetrospectivePrefix), recurringReminderSuffix)
 	playbookRunToRemind, err := s.GetPlaybookRun(playbookRunID)
 	if err != nil {
 		s.logger.Errorf(errors.Wrapf(err, "handleReminderToFillRetro failed to get playbook run id: %s", playbookRunID).Error())
@@ -36,8 +60,12 @@ func (s *PlaybookRunServiceImpl) handleReminderToFillRetro(playbookRunID string)
 	}
 
 	// If we are not in the resolved state then don't remind
-	if playbookRunToRemind.CurrentStatus != StatusResolved &&
-		playbookRunToRemind.CurrentStatus != StatusArchived {
+	if playbookRunToRemind.IsActive() {
+		return
+	}
+
+	// The reminder may have been disabled since it was scheduled.
+	if playbookRunToRemind.RetrospectiveReminderIntervalSeconds == 0 {
 		return
 	}
 
@@ -46,23 +74,27 @@ func (s *PlaybookRunServiceImpl) handleReminderToFillRetro(playbookRunID string)
 		return
 	}
 
-	// Jobs can't be rescheduled within themselves with the same key. As a temporary workaround do it in a delayed goroutine
-	go func() {
-		time.Sleep(time.Second * 2)
-		if err = s.SetReminder(RetrospectivePrefix+playbookRunID, time.Duration(playbookRunToRemind.RetrospectiveReminderIntervalSeconds)*time.Second); err != nil {
-			s.logger.Errorf(errors.Wrap(err, "failed to reocurr retrospective reminder").Error())
-			return
-		}
-	}()
+	if err = s.SetReminder(nextReminderKey(key), time.Duration(playbookRunToRemind.RetrospectiveReminderIntervalSeconds)*time.Second); err != nil {
+		s.logger.Errorf(errors.Wrap(err, "failed to reocurr retrospective reminder").Error())
+	}
 }
 
-func (s *PlaybookRunServiceImpl) handleStatusUpdateReminder(playbookRunID string) {
+// handleStatusUpdateReminder asks the owner for a status update, replacing the previous reminder
+// post if any, escalates the reminders they missed so far, and schedules the next reminder. The
+// reminders recur until a status update is posted, which cancels them.
+func (s *PlaybookRunServiceImpl) handleStatusUpdateReminder(key string) {
+	playbookRunID := strings.TrimSuffix(key, recurringReminderSuffix)
 	playbookRunToModify, err := s.GetPlaybookRun(playbookRunID)
 	if err != nil {
 		s.logger.Errorf(errors.Wrapf(err, "HandleReminder failed to get playbook run id: %s", playbookRunID).Error())
 		return
 	}
 
+	// The run may have been closed, or its reminders turned off, since the reminder was scheduled.
+	if playbookRunToModify.PreviousReminder == 0 || playbookRunToModify.Workflow().IsClosed(playbookRunToModify.CurrentStatus) {
+		return
+	}
+
 	owner, err := s.pluginAPI.User.Get(playbookRunToModify.OwnerUserID)
 	if err != nil {
 		s.logger.Errorf(errors.Wrapf(err, "HandleReminder failed to get owner for id: %s", playbookRunToModify.OwnerUserID).Error())
@@ -101,9 +133,55 @@ func (s *PlaybookRunServiceImpl) handleStatusUpdateReminder(playbookRunID string
 		return
 	}
 
-	playbookRunToModify.ReminderPostID = post.Id
-	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
-		s.logger.Errorf(errors.Wrapf(err, "error updating with reminder post id, playbook run id: %s", playbookRunToModify.ID).Error())
+	if playbookRunToModify.ReminderPostID != "" {
+		if err = s.pluginAPI.Post.DeletePost(playbookRunToModify.ReminderPostID); err != nil {
+			s.logger.Warnf("failed to delete previous reminder post for playbook run id %s: %v", playbookRunToModify.ID, err)
+		}
+	}
+
+	// A status update may be posted, or the run closed, while reminding, so the reminder is
+	// recorded in the run as read anew after a conflict, unless it no longer needs reminding.
+	lastStatusUpdateAt := playbookRunToModify.LastStatusUpdateAt
+	missed := 0
+	stop := false
+	attempts := 0
+	err = retryOnVersionConflict(func() error {
+		if attempts++; attempts > 1 {
+			var err error
+			if playbookRunToModify, err = s.store.GetPlaybookRun(playbookRunID); err != nil {
+				return errors.Wrapf(err, "failed to get playbook run")
+			}
+		}
+
+		stop = playbookRunToModify.LastStatusUpdateAt != lastStatusUpdateAt ||
+			playbookRunToModify.PreviousReminder == 0 ||
+			playbookRunToModify.Workflow().IsClosed(playbookRunToModify.CurrentStatus)
+		if stop {
+			return nil
+		}
+
+		missed = playbookRunToModify.ReminderCount
+		playbookRunToModify.ReminderPostID = post.Id
+		playbookRunToModify.ReminderCount++
+		return s.store.UpdatePlaybookRun(playbookRunToModify)
+	})
+
+	if stop {
+		if err = s.pluginAPI.Post.DeletePost(post.Id); err != nil {
+			s.logger.Warnf("failed to delete reminder post for playbook run id %s: %v", playbookRunID, err)
+		}
+		return
+	}
+
+	// The reminder could not be recorded, so its escalation is skipped, but the reminders go on.
+	if err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "error updating with reminder post id, playbook run id: %s", playbookRunID).Error())
+	} else {
+		s.escalateMissedReminders(playbookRunToModify, owner, missed)
+	}
+
+	if err = s.SetReminder(nextReminderKey(key), playbookRunToModify.PreviousReminder); err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "failed to schedule the next reminder for playbook run id: %s", playbookRunID).Error())
 	}
 }
 
@@ -117,28 +195,51 @@ func (s *PlaybookRunServiceImpl) SetReminder(playbookRunID string, fromNow time.
 	return nil
 }
 
-// RemoveReminder removes the pending reminder for the given playbook run, if any.
+// RemoveReminder removes the pending reminder for the given playbook run, if any, along with its
+// next occurrence.
 func (s *PlaybookRunServiceImpl) RemoveReminder(playbookRunID string) {
+	// Canceling a running occurrence waits for it to finish, and it may have scheduled the
+	// other key in the meantime, so the first key is canceled again.
+	s.scheduler.Cancel(playbookRunID)
+	s.scheduler.Cancel(nextReminderKey(playbookRunID))
 	s.scheduler.Cancel(playbookRunID)
 }
 
 // RemoveReminderPost removes the reminder post in the channel for the given playbook run, if any.
 func (s *PlaybookRunServiceImpl) RemoveReminderPost(playbookRunID string) error {
+	return retryOnVersionConflict(func() error {
+		return s.removeReminderPost(playbookRunID)
+	})
+}
+
+// removeReminderPost applies RemoveReminderPost once, see retryOnVersionConflict.
+func (s *PlaybookRunServiceImpl) removeReminderPost(playbookRunID string) error {
 	playbookRunToModify, err := s.store.GetPlaybookRun(playbookRunID)
 	if err != nil {
 		return errors.Wrapf(err, "failed to retrieve playbook run")
 	}
 
-	return s.removeReminderPost(playbookRunToModify)
+	reminderPostID := playbookRunToModify.ReminderPostID
+	if reminderPostID == "" {
+		return nil
+	}
+
+	playbookRunToModify.ReminderPostID = ""
+	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
+		return errors.Wrapf(err, "failed to update playbook run after removing reminder post id")
+	}
+
+	return s.deleteReminderPost(reminderPostID)
 }
 
-// removeReminderPost removes the reminder post in the channel for the given playbook run, if any.
-func (s *PlaybookRunServiceImpl) removeReminderPost(playbookRunToModify *PlaybookRun) error {
-	if playbookRunToModify.ReminderPostID == "" {
+// deleteReminderPost deletes the reminder post with the given ID, unless it is empty or the post
+// was already deleted.
+func (s *PlaybookRunServiceImpl) deleteReminderPost(reminderPostID string) error {
+	if reminderPostID == "" {
 		return nil
 	}
 
-	post, err := s.pluginAPI.Post.GetPost(playbookRunToModify.ReminderPostID)
+	post, err := s.pluginAPI.Post.GetPost(reminderPostID)
 	if err != nil {
 		return errors.Wrapf(err, "failed to retrieve reminder post")
 	}
@@ -147,14 +248,9 @@ func (s *PlaybookRunServiceImpl) removeReminderPost(playbookRunToModify *Playboo
 		return nil
 	}
 
-	if err = s.pluginAPI.Post.DeletePost(playbookRunToModify.ReminderPostID); err != nil {
+	if err = s.pluginAPI.Post.DeletePost(reminderPostID); err != nil {
 		return errors.Wrapf(err, "failed to delete reminder post")
 	}
 
-	playbookRunToModify.ReminderPostID = ""
-	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
-		return errors.Wrapf(err, "failed to update playbook run after removing reminder post id")
-	}
-
 	return nil
 }
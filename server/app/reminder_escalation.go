@@ -0,0 +1,155 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// MaxMissedReminders is the largest number of missed status update reminders an escalation step
+// can wait for.
+const MaxMissedReminders = 100
+
+// ReminderEscalation describes how a playbook run's owner missing status update reminders is
+// escalated. Reminders keep being posted until a status update is, and a step is skipped when
+// its number of missed reminders is 0.
+type ReminderEscalation struct {
+	// BackupAfterMissed is the number of missed reminders after which the backup user and the
+	// members of the backup group are sent a DM.
+	BackupAfterMissed int    `json:"backup_after_missed"`
+	BackupUserID      string `json:"backup_user_id"`
+	BackupGroupID     string `json:"backup_group_id"`
+
+	// BroadcastAfterMissed is the number of missed reminders after which a message is posted
+	// in the run's broadcast channel.
+	BroadcastAfterMissed int `json:"broadcast_after_missed"`
+}
+
+// IsEmpty returns true if no escalation step is configured.
+func (e ReminderEscalation) IsEmpty() bool {
+	return e == ReminderEscalation{}
+}
+
+// Validate checks that the escalation steps wait for a number of missed reminders between 0 and
+// MaxMissedReminders, and that the backup step has someone to notify.
+func (e ReminderEscalation) Validate() error {
+	if e.BackupAfterMissed < 0 || e.BackupAfterMissed > MaxMissedReminders {
+		return errors.Errorf("backup_after_missed must be between 0 and %d", MaxMissedReminders)
+	}
+	if e.BroadcastAfterMissed < 0 || e.BroadcastAfterMissed > MaxMissedReminders {
+		return errors.Errorf("broadcast_after_missed must be between 0 and %d", MaxMissedReminders)
+	}
+
+	if e.BackupUserID != "" && !model.IsValidId(e.BackupUserID) {
+		return errors.New("backup_user_id must be a valid id")
+	}
+	if e.BackupGroupID != "" && !model.IsValidId(e.BackupGroupID) {
+		return errors.New("backup_group_id must be a valid id")
+	}
+	if e.BackupAfterMissed > 0 && e.BackupUserID == "" && e.BackupGroupID == "" {
+		return errors.New("a backup user or group is required to escalate to the backup")
+	}
+
+	return nil
+}
+
+// escalateMissedReminders runs the escalation steps of the playbook run due after the given
+// number of missed status update reminders, recording each of them in the timeline.
+func (s *PlaybookRunServiceImpl) escalateMissedReminders(playbookRun *PlaybookRun, owner *model.User, missed int) {
+	escalation := playbookRun.ReminderEscalation
+	if missed == 0 || escalation.IsEmpty() {
+		return
+	}
+
+	runName := fmt.Sprintf("**%s**", playbookRun.Name)
+	if channel, err := s.pluginAPI.Channel.Get(playbookRun.ChannelID); err == nil {
+		runName = fmt.Sprintf("~%s", channel.Name)
+	}
+
+	if escalation.BackupAfterMissed == missed {
+		message := fmt.Sprintf("@%s has missed %d status update reminders for %s. As their backup, please follow up on the run.",
+			owner.Username, missed, runName)
+		for _, userID := range s.backupUserIDs(escalation, playbookRun.OwnerUserID) {
+			if err := s.poster.DM(userID, &model.Post{Message: message}); err != nil {
+				s.logger.Errorf(errors.Wrapf(err, "failed to escalate missed reminders to user id: %s", userID).Error())
+			}
+		}
+		s.recordReminderEscalation(playbookRun, fmt.Sprintf("Notified the backup after %d missed status updates", missed))
+	}
+
+	if escalation.BroadcastAfterMissed == missed && playbookRun.BroadcastChannelID != "" {
+		message := fmt.Sprintf("%s has had no status update for %d reminders in a row.", runName, missed)
+		if _, err := s.poster.PostMessage(playbookRun.BroadcastChannelID, "%s", message); err != nil {
+			s.logger.Errorf(errors.Wrapf(err, "failed to escalate missed reminders to the broadcast channel of playbook run id: %s", playbookRun.ID).Error())
+		}
+		s.recordReminderEscalation(playbookRun, fmt.Sprintf("Posted in the broadcast channel after %d missed status updates", missed))
+	}
+}
+
+// backupUserIDs returns the backup user and the members of the backup group, leaving out the
+// owner and the members of a group that does not allow references.
+func (s *PlaybookRunServiceImpl) backupUserIDs(escalation ReminderEscalation, ownerID string) []string {
+	userIDs := []string{}
+	seen := map[string]bool{ownerID: true}
+	add := func(userID string) {
+		if !seen[userID] {
+			seen[userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	if escalation.BackupUserID != "" {
+		add(escalation.BackupUserID)
+	}
+
+	if escalation.BackupGroupID == "" {
+		return userIDs
+	}
+
+	group, err := s.pluginAPI.Group.Get(escalation.BackupGroupID)
+	if err != nil {
+		s.pluginAPI.Log.Warn("failed to query backup group", "group_id", escalation.BackupGroupID)
+		return userIDs
+	}
+	if !group.AllowReference {
+		s.pluginAPI.Log.Warn("backup group does not allow references", "group_id", escalation.BackupGroupID)
+		return userIDs
+	}
+
+	perPage := 1000
+	for page := 0; ; page++ {
+		var users []*model.User
+		users, err = s.pluginAPI.Group.GetMemberUsers(escalation.BackupGroupID, page, perPage)
+		if err != nil {
+			s.pluginAPI.Log.Warn("failed to query backup group", "group_id", escalation.BackupGroupID, "err", err)
+			break
+		}
+		for _, user := range users {
+			add(user.Id)
+		}
+
+		if len(users) < perPage {
+			break
+		}
+	}
+
+	return userIDs
+}
+
+// recordReminderEscalation adds an escalation step of the playbook run to its timeline.
+func (s *PlaybookRunServiceImpl) recordReminderEscalation(playbookRun *PlaybookRun, summary string) {
+	now := model.GetMillis()
+	event := &TimelineEvent{
+		PlaybookRunID: playbookRun.ID,
+		CreateAt:      now,
+		EventAt:       now,
+		EventType:     ReminderEscalated,
+		Summary:       summary,
+		SubjectUserID: playbookRun.OwnerUserID,
+	}
+
+	if _, err := s.store.CreateTimelineEvent(event); err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "failed to create timeline event for playbook run id: %s", playbookRun.ID).Error())
+	}
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReminderEscalationValidate(t *testing.T) {
+	userID := model.NewId()
+	groupID := model.NewId()
+
+	tests := []struct {
+		name       string
+		escalation ReminderEscalation
+		wantErr    bool
+	}{
+		{"no escalation", ReminderEscalation{}, false},
+		{"backup user", ReminderEscalation{BackupAfterMissed: 2, BackupUserID: userID}, false},
+		{"backup group", ReminderEscalation{BackupAfterMissed: 2, BackupGroupID: groupID}, false},
+		{"broadcast only", ReminderEscalation{BroadcastAfterMissed: 3}, false},
+		{"backup without anyone to notify", ReminderEscalation{BackupAfterMissed: 2}, true},
+		{"negative missed reminders", ReminderEscalation{BroadcastAfterMissed: -1}, true},
+		{"too many missed reminders", ReminderEscalation{BackupAfterMissed: MaxMissedReminders + 1, BackupUserID: userID}, true},
+		{"invalid backup user", ReminderEscalation{BackupAfterMissed: 2, BackupUserID: "user"}, true},
+		{"invalid backup group", ReminderEscalation{BackupAfterMissed: 2, BackupGroupID: "group"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.escalation.Validate()
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package app_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/app"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/telemetry"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	mock_app "github.com/mattermost/mattermost-plugin-incident-collaboration/server/app/mocks"
+	mock_bot "github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot/mocks"
+	mock_config "github.com/mattermost/mattermost-plugin-incident-collaboration/server/config/mocks"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/cluster"
+)
+
+func TestHandleChecklistItemDue(t *testing.T) {
+	const dueAt = int64(1625000000000)
+
+	setup := func(t *testing.T) (*mock_app.MockPlaybookRunStore, *mock_bot.MockPoster, *app.PlaybookRunServiceImpl) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		pluginAPI.On("GetChannel", "channel_id").Return(&model.Channel{Id: "channel_id", Name: "outage"}, nil)
+		pluginAPI.On("GetChannel", mock.Anything).Return(nil, &model.AppError{})
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{})
+		return store, poster, s
+	}
+
+	key := func(playbookRunID string) string {
+		return app.ChecklistItemDuePrefix + playbookRunID + "_" + strconv.FormatInt(dueAt, 10)
+	}
+
+	t.Run("owner and assignee are alerted about open items", func(t *testing.T) {
+		store, poster, s := setup(t)
+
+		playbookRun := &app.PlaybookRun{
+			ID:            model.NewId(),
+			Name:          "Outage",
+			ChannelID:     "channel_id",
+			OwnerUserID:   "owner_id",
+			CurrentStatus: app.StatusReported,
+			Checklists: []app.Checklist{{Items: []app.ChecklistItem{
+				{Title: "Page", DueAt: dueAt, AssigneeID: "assignee_id"},
+				{Title: "Triage", DueAt: dueAt, AssigneeID: "owner_id"},
+				{Title: "Fix", DueAt: dueAt, State: app.ChecklistItemStateClosed},
+				{Title: "Report", DueAt: dueAt + 1000},
+			}}},
+		}
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+
+		var messages []string
+		poster.EXPECT().DM(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(userID string, post *model.Post) error {
+				messages = append(messages, userID+": "+post.Message)
+				return nil
+			}).Times(3)
+
+		s.HandleReminder(key(playbookRun.ID))
+
+		require.Equal(t, []string{
+			"owner_id: The checklist item **Page** of ~outage is overdue.",
+			"assignee_id: The checklist item **Page** of ~outage is overdue.",
+			"owner_id: The checklist item **Triage** of ~outage is overdue.",
+		}, messages)
+	})
+
+	t.Run("finished runs are not alerted about", func(t *testing.T) {
+		store, _, s := setup(t)
+
+		playbookRun := &app.PlaybookRun{
+			ID:            model.NewId(),
+			ChannelID:     "channel_id",
+			OwnerUserID:   "owner_id",
+			CurrentStatus: app.StatusResolved,
+			Checklists:    []app.Checklist{{Items: []app.ChecklistItem{{Title: "Page", DueAt: dueAt}}}},
+		}
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+
+		s.HandleReminder(key(playbookRun.ID))
+	})
+}
+
+func TestHandleStatusUpdateReminder(t *testing.T) {
+	setup := func(t *testing.T) (*mock_app.MockPlaybookRunStore, *mock_bot.MockPoster, *mock_app.MockJobOnceScheduler, *plugintest.API, *mock_bot.MockLogger, *app.PlaybookRunServiceImpl) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		pluginAPI.On("GetUser", "owner_id").Return(&model.User{Id: "owner_id", Username: "owner"}, nil)
+		pluginAPI.On("GetChannel", "channel_id").Return(&model.Channel{Id: "channel_id", Name: "outage"}, nil)
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		configService.EXPECT().GetManifest().Return(&model.Manifest{Id: "playbooks"}).AnyTimes()
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{})
+		return store, poster, scheduler, pluginAPI, logger, s
+	}
+
+	newPlaybookRun := func() *app.PlaybookRun {
+		return &app.PlaybookRun{
+			ID:                 model.NewId(),
+			Name:               "Outage",
+			ChannelID:          "channel_id",
+			BroadcastChannelID: "broadcast_channel_id",
+			OwnerUserID:        "owner_id",
+			CurrentStatus:      app.StatusActive,
+			PreviousReminder:   15 * time.Minute,
+			ReminderEscalation: app.ReminderEscalation{
+				BackupAfterMissed:    2,
+				BackupUserID:         "backup_id",
+				BackupGroupID:        "group_id",
+				BroadcastAfterMissed: 3,
+			},
+		}
+	}
+
+	expectReminder := func(t *testing.T, store *mock_app.MockPlaybookRunStore, poster *mock_bot.MockPoster, scheduler *mock_app.MockJobOnceScheduler, playbookRun *app.PlaybookRun, nextKey string) {
+		reminderCount := playbookRun.ReminderCount
+
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+		poster.EXPECT().PostMessageWithAttachments("channel_id", gomock.Any(), "@%s, please provide a status update.", "owner").
+			Return(&model.Post{Id: "reminder_post_id"}, nil)
+		store.EXPECT().UpdatePlaybookRun(gomock.Any()).
+			DoAndReturn(func(updated *app.PlaybookRun) error {
+				require.Equal(t, "reminder_post_id", updated.ReminderPostID)
+				require.Equal(t, reminderCount+1, updated.ReminderCount)
+				return nil
+			})
+		scheduler.EXPECT().ScheduleOnce(nextKey, gomock.Any()).
+			DoAndReturn(func(key string, runAt time.Time) (*cluster.JobOnce, error) {
+				require.WithinDuration(t, time.Now().Add(15*time.Minute), runAt, time.Minute)
+				return nil, nil
+			})
+	}
+
+	t.Run("reminders recur, alternating between two keys", func(t *testing.T) {
+		store, poster, scheduler, pluginAPI, _, s := setup(t)
+		pluginAPI.On("DeletePost", "reminder_post_id").Return(nil)
+
+		playbookRun := newPlaybookRun()
+		expectReminder(t, store, poster, scheduler, playbookRun, playbookRun.ID+"_next")
+		s.HandleReminder(playbookRun.ID)
+
+		expectReminder(t, store, poster, scheduler, playbookRun, playbookRun.ID)
+		s.HandleReminder(playbookRun.ID + "_next")
+
+		require.Equal(t, 2, playbookRun.ReminderCount)
+	})
+
+	t.Run("the previous reminder post is replaced", func(t *testing.T) {
+		store, poster, scheduler, pluginAPI, _, s := setup(t)
+		pluginAPI.On("DeletePost", "previous_post_id").Return(nil)
+
+		playbookRun := newPlaybookRun()
+		playbookRun.ReminderPostID = "previous_post_id"
+		expectReminder(t, store, poster, scheduler, playbookRun, playbookRun.ID+"_next")
+
+		s.HandleReminder(playbookRun.ID)
+
+		pluginAPI.AssertCalled(t, "DeletePost", "previous_post_id")
+	})
+
+	t.Run("the backup is notified after the configured number of missed reminders", func(t *testing.T) {
+		store, poster, scheduler, pluginAPI, _, s := setup(t)
+		pluginAPI.On("GetGroup", "group_id").Return(&model.Group{Id: "group_id", AllowReference: true}, nil)
+		pluginAPI.On("GetGroupMemberUsers", "group_id", 0, 1000).
+			Return([]*model.User{{Id: "owner_id"}, {Id: "backup_id"}, {Id: "member_id"}}, nil)
+
+		playbookRun := newPlaybookRun()
+		playbookRun.ReminderCount = 2
+		expectReminder(t, store, poster, scheduler, playbookRun, playbookRun.ID+"_next")
+
+		var dmed []string
+		poster.EXPECT().DM(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(userID string, post *model.Post) error {
+				require.Equal(t, "@owner has missed 2 status update reminders for ~outage. As their backup, please follow up on the run.", post.Message)
+				dmed = append(dmed, userID)
+				return nil
+			}).Times(2)
+		store.EXPECT().CreateTimelineEvent(gomock.Any()).
+			DoAndReturn(func(event *app.TimelineEvent) (*app.TimelineEvent, error) {
+				require.Equal(t, app.ReminderEscalated, event.EventType)
+				require.Equal(t, "Notified the backup after 2 missed status updates", event.Summary)
+				require.Equal(t, "owner_id", event.SubjectUserID)
+				return event, nil
+			})
+
+		s.HandleReminder(playbookRun.ID)
+
+		require.Equal(t, []string{"backup_id", "member_id"}, dmed)
+	})
+
+	t.Run("the broadcast channel is posted in after the configured number of missed reminders", func(t *testing.T) {
+		store, poster, scheduler, _, _, s := setup(t)
+
+		playbookRun := newPlaybookRun()
+		playbookRun.ReminderCount = 3
+		expectReminder(t, store, poster, scheduler, playbookRun, playbookRun.ID+"_next")
+
+		poster.EXPECT().PostMessage("broadcast_channel_id", "%s", "~outage has had no status update for 3 reminders in a row.").
+			Return(&model.Post{}, nil)
+		store.EXPECT().CreateTimelineEvent(gomock.Any()).
+			DoAndReturn(func(event *app.TimelineEvent) (*app.TimelineEvent, error) {
+				require.Equal(t, app.ReminderEscalated, event.EventType)
+				require.Equal(t, "Posted in the broadcast channel after 3 missed status updates", event.Summary)
+				return event, nil
+			})
+
+		s.HandleReminder(playbookRun.ID)
+	})
+
+	t.Run("a conflicting update is retried on the run as read anew", func(t *testing.T) {
+		store, poster, scheduler, _, _, s := setup(t)
+
+		playbookRun := newPlaybookRun()
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+		poster.EXPECT().PostMessageWithAttachments("channel_id", gomock.Any(), "@%s, please provide a status update.", "owner").
+			Return(&model.Post{Id: "reminder_post_id"}, nil)
+
+		updated := newPlaybookRun()
+		updated.ID = playbookRun.ID
+		updated.Description = "updated"
+		gomock.InOrder(
+			store.EXPECT().UpdatePlaybookRun(gomock.Any()).Return(app.ErrPlaybookRunVersionConflict),
+			store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(updated, nil),
+			store.EXPECT().UpdatePlaybookRun(gomock.Any()).
+				DoAndReturn(func(run *app.PlaybookRun) error {
+					require.Equal(t, "updated", run.Description)
+					require.Equal(t, "reminder_post_id", run.ReminderPostID)
+					require.Equal(t, 1, run.ReminderCount)
+					return nil
+				}),
+		)
+		scheduler.EXPECT().ScheduleOnce(playbookRun.ID+"_next", gomock.Any()).Return(nil, nil)
+
+		s.HandleReminder(playbookRun.ID)
+	})
+
+	t.Run("a status update posted while reminding stops the reminders", func(t *testing.T) {
+		store, poster, _, pluginAPI, _, s := setup(t)
+		pluginAPI.On("DeletePost", "reminder_post_id").Return(nil)
+
+		playbookRun := newPlaybookRun()
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+		poster.EXPECT().PostMessageWithAttachments("channel_id", gomock.Any(), "@%s, please provide a status update.", "owner").
+			Return(&model.Post{Id: "reminder_post_id"}, nil)
+
+		updated := newPlaybookRun()
+		updated.ID = playbookRun.ID
+		updated.LastStatusUpdateAt = model.GetMillis()
+		store.EXPECT().UpdatePlaybookRun(gomock.Any()).Return(app.ErrPlaybookRunVersionConflict)
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(updated, nil)
+
+		s.HandleReminder(playbookRun.ID)
+
+		pluginAPI.AssertCalled(t, "DeletePost", "reminder_post_id")
+	})
+
+	t.Run("the next reminder is scheduled even if the reminder is not recorded", func(t *testing.T) {
+		store, poster, scheduler, _, logger, s := setup(t)
+
+		playbookRun := newPlaybookRun()
+		playbookRun.ReminderCount = 3
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+		poster.EXPECT().PostMessageWithAttachments("channel_id", gomock.Any(), "@%s, please provide a status update.", "owner").
+			Return(&model.Post{Id: "reminder_post_id"}, nil)
+		store.EXPECT().UpdatePlaybookRun(gomock.Any()).Return(errors.New("database is down"))
+		logger.EXPECT().Errorf(gomock.Any())
+		scheduler.EXPECT().ScheduleOnce(playbookRun.ID+"_next", gomock.Any()).Return(nil, nil)
+
+		s.HandleReminder(playbookRun.ID)
+	})
+
+	t.Run("closed runs are not reminded about", func(t *testing.T) {
+		store, _, _, _, _, s := setup(t)
+
+		playbookRun := newPlaybookRun()
+		playbookRun.CurrentStatus = app.StatusArchived
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+
+		s.HandleReminder(playbookRun.ID + "_next")
+	})
+}
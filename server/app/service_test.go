@@ -7,6 +7,7 @@ import (
 	"github.com/golang/mock/gomock"
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/app"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/config"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/telemetry"
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
@@ -525,6 +526,223 @@ func TestMessageHasBeenPosted(t *testing.T) {
 	})
 }
 
+func TestUpdatePlaybookRevisions(t *testing.T) {
+	t.Run("saves the changes as the next revision", func(t *testing.T) {
+		s, store, _, _ := getMockPlaybookService(t)
+
+		old := app.Playbook{ID: "playbookid", Title: "Outage", TeamID: "teamid", Revision: 3}
+		updated := old
+		updated.Title = "Major outage"
+
+		store.EXPECT().Get("playbookid").Return(old, nil)
+		store.EXPECT().
+			UpdateWithRevision(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(playbook app.Playbook, revision app.PlaybookRevision) error {
+				require.Equal(t, int64(4), playbook.Revision)
+				require.Equal(t, int64(4), revision.Revision)
+				require.Equal(t, "userid", revision.UserID)
+				require.Len(t, revision.Changes, 1)
+				require.Equal(t, "title", revision.Changes[0].Field)
+				require.Equal(t, "Major outage", revision.Playbook.Title)
+				return nil
+			})
+
+		err := s.Update(updated, "userid")
+		require.NoError(t, err)
+	})
+
+	t.Run("saves a baseline revision for playbooks without revisions", func(t *testing.T) {
+		s, store, _, _ := getMockPlaybookService(t)
+
+		old := app.Playbook{ID: "playbookid", Title: "Outage", TeamID: "teamid", UpdateAt: 100, WebhookSecret: "secret"}
+		updated := old
+		updated.Title = "Major outage"
+
+		store.EXPECT().Get("playbookid").Return(old, nil)
+		store.EXPECT().
+			CreateRevision(gomock.Any()).
+			DoAndReturn(func(revision app.PlaybookRevision) error {
+				require.Equal(t, int64(1), revision.Revision)
+				require.Empty(t, revision.UserID)
+				require.Equal(t, int64(100), revision.CreateAt)
+				require.Equal(t, "Outage", revision.Playbook.Title)
+				require.Empty(t, revision.Playbook.WebhookSecret)
+				return nil
+			})
+		store.EXPECT().
+			UpdateWithRevision(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(playbook app.Playbook, revision app.PlaybookRevision) error {
+				require.Equal(t, int64(2), revision.Revision)
+				require.Equal(t, "secret", playbook.WebhookSecret)
+				return nil
+			})
+
+		err := s.Update(updated, "userid")
+		require.NoError(t, err)
+	})
+
+	t.Run("concurrent update", func(t *testing.T) {
+		s, store, _, _ := getMockPlaybookService(t)
+
+		old := app.Playbook{ID: "playbookid", Title: "Outage", TeamID: "teamid", Revision: 3}
+
+		store.EXPECT().Get("playbookid").Return(old, nil)
+		store.EXPECT().
+			UpdateWithRevision(gomock.Any(), gomock.Any()).
+			Return(errors.Wrap(app.ErrPlaybookRevisionConflict, "revision 4 already exists"))
+
+		err := s.Update(old, "userid")
+		require.True(t, errors.Is(err, app.ErrPlaybookRevisionConflict))
+	})
+}
+
+func TestSyncPlaybooks(t *testing.T) {
+	setup := func(t *testing.T) (app.PlaybookService, *mock_playbook.MockPlaybookStore, *plugintest.API) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		pluginAPI.On("KVSetWithOptions", mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return(true, nil)
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_playbook.NewMockPlaybookStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		poster.EXPECT().PublishWebsocketEventToTeam(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+		configService := mock_config.NewMockService(controller)
+		configService.EXPECT().IsAtLeastE20Licensed().Return(true).AnyTimes()
+		keywordsIgnorer := mock_playbook.NewMockKeywordsThreadIgnorer(controller)
+
+		return app.NewPlaybookService(store, poster, &telemetry.NoopTelemetry{}, client, configService, keywordsIgnorer), store, pluginAPI
+	}
+
+	files := map[string][]byte{
+		"new.yaml":       []byte("version: 1\ntitle: New\n"),
+		"changed.yaml":   []byte("version: 1\ntitle: Changed\n"),
+		"unchanged.yaml": []byte("version: 1\ntitle: Unchanged\n"),
+	}
+	changed := app.Playbook{ID: "changedid", Title: "Old title", TeamID: "teamid", Revision: 2, SyncPath: "changed.yaml"}
+	unchanged := app.Playbook{ID: "unchangedid", Title: "Unchanged", TeamID: "teamid", Revision: 1, SyncPath: "unchanged.yaml"}
+	removed := app.Playbook{ID: "removedid", Title: "Removed", TeamID: "teamid", Revision: 1, SyncPath: "removed.yaml"}
+
+	expectSyncedPlaybooks := func(store *mock_playbook.MockPlaybookStore) {
+		store.EXPECT().GetSyncedPlaybookIDs("teamid").Return(map[string]string{
+			"changed.yaml":   "changedid",
+			"unchanged.yaml": "unchangedid",
+			"removed.yaml":   "removedid",
+		}, nil)
+		store.EXPECT().Get("changedid").Return(changed, nil).AnyTimes()
+		store.EXPECT().Get("unchangedid").Return(unchanged, nil).AnyTimes()
+		store.EXPECT().Get("removedid").Return(removed, nil).AnyTimes()
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		s, store, _ := setup(t)
+		expectSyncedPlaybooks(store)
+
+		result, err := s.Sync("teamid", "userid", files, true)
+		require.NoError(t, err)
+		require.True(t, result.DryRun)
+		require.Len(t, result.Playbooks, 4)
+
+		require.Equal(t, "changed.yaml", result.Playbooks[0].Path)
+		require.Equal(t, app.PlaybookSyncActionUpdate, result.Playbooks[0].Action)
+		require.Equal(t, "changedid", result.Playbooks[0].PlaybookID)
+		require.Len(t, result.Playbooks[0].Changes, 1)
+		require.Equal(t, "title", result.Playbooks[0].Changes[0].Field)
+
+		require.Equal(t, "new.yaml", result.Playbooks[1].Path)
+		require.Equal(t, app.PlaybookSyncActionCreate, result.Playbooks[1].Action)
+		require.Empty(t, result.Playbooks[1].PlaybookID)
+
+		require.Equal(t, "removed.yaml", result.Playbooks[2].Path)
+		require.Equal(t, app.PlaybookSyncActionDelete, result.Playbooks[2].Action)
+		require.Equal(t, "Removed", result.Playbooks[2].Title)
+
+		require.Equal(t, "unchanged.yaml", result.Playbooks[3].Path)
+		require.Equal(t, app.PlaybookSyncActionNone, result.Playbooks[3].Action)
+	})
+
+	t.Run("sync", func(t *testing.T) {
+		s, store, _ := setup(t)
+		expectSyncedPlaybooks(store)
+
+		store.EXPECT().
+			UpdateWithRevision(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(playbook app.Playbook, revision app.PlaybookRevision) error {
+				require.Equal(t, "changedid", playbook.ID)
+				require.Equal(t, "Changed", playbook.Title)
+				require.Equal(t, "changed.yaml", playbook.SyncPath)
+				return nil
+			})
+		store.EXPECT().
+			Create(gomock.Any()).
+			DoAndReturn(func(playbook app.Playbook) (string, error) {
+				require.Equal(t, "New", playbook.Title)
+				require.Equal(t, "teamid", playbook.TeamID)
+				require.Equal(t, "new.yaml", playbook.SyncPath)
+				require.Empty(t, playbook.MemberIDs, "the user syncing is not added as a member")
+				return "newid", nil
+			})
+		store.EXPECT().CreateRevision(gomock.Any()).Return(nil)
+		store.EXPECT().Delete("removedid").Return(nil)
+
+		result, err := s.Sync("teamid", "userid", files, false)
+		require.NoError(t, err)
+		require.False(t, result.DryRun)
+		require.Equal(t, "newid", result.Playbooks[1].PlaybookID)
+
+		created, updated, deleted := result.Counts()
+		require.Equal(t, 1, created)
+		require.Equal(t, 1, updated)
+		require.Equal(t, 1, deleted)
+	})
+
+	t.Run("generated inbound webhook tokens are returned once saved", func(t *testing.T) {
+		s, store, pluginAPI := setup(t)
+		pluginAPI.On("GetUserByUsername", "alice").Return(&model.User{Id: "aliceid", Username: "alice"}, nil)
+		pluginAPI.On("GetUserByUsername", "bob").Return(&model.User{Id: "bobid", Username: "bob"}, nil)
+		pluginAPI.On("GetUser", "bobid").Return(&model.User{Id: "bobid", Username: "bob"}, nil)
+		pluginAPI.On("GetTeamMember", "teamid", mock.Anything).Return(&model.TeamMember{TeamId: "teamid"}, nil)
+
+		alerts := map[string][]byte{
+			"alerts.yaml": []byte("version: 1\ntitle: Alerts\ndefault_owner: alice\ndefault_owner_enabled: true\n" +
+				"inbound_webhook:\n  enabled: true\n  service_user: bob\n"),
+		}
+		store.EXPECT().GetSyncedPlaybookIDs("teamid").Return(map[string]string{}, nil).Times(2)
+
+		result, err := s.Sync("teamid", "userid", alerts, true)
+		require.NoError(t, err)
+		require.Empty(t, result.Playbooks[0].InboundWebhookToken)
+
+		var token string
+		store.EXPECT().
+			Create(gomock.Any()).
+			DoAndReturn(func(playbook app.Playbook) (string, error) {
+				require.NotEmpty(t, playbook.InboundWebhook.Token)
+				require.Empty(t, playbook.InboundWebhook.ServiceUserID, "only bots post the inbound status updates")
+				token = playbook.InboundWebhook.Token
+				return "alertsid", nil
+			})
+		store.EXPECT().CreateRevision(gomock.Any()).Return(nil)
+
+		result, err = s.Sync("teamid", "userid", alerts, false)
+		require.NoError(t, err)
+		require.Equal(t, token, result.Playbooks[0].InboundWebhookToken)
+		require.Equal(t, []string{"inbound webhook service user: user @bob is not a bot"}, result.Playbooks[0].Warnings)
+	})
+
+	t.Run("malformed file changes nothing", func(t *testing.T) {
+		s, store, _ := setup(t)
+		expectSyncedPlaybooks(store)
+
+		malformed := map[string][]byte{
+			"changed.yaml": []byte("version: 1\ntitle: Changed\n"),
+			"invalid.yaml": []byte("version: 1\ntitle: Invalid\nseverity_levels: [High]\ndefault_severity: Low\n"),
+		}
+
+		_, err := s.Sync("teamid", "userid", malformed, false)
+		require.True(t, errors.Is(err, app.ErrMalformedPlaybook))
+		require.Contains(t, err.Error(), "invalid.yaml")
+	})
+}
+
 func getMockPlaybookService(t *testing.T) (app.PlaybookService, *mock_playbook.MockPlaybookStore, *plugintest.API, *mock_playbook.MockKeywordsThreadIgnorer) {
 	controller := gomock.NewController(t)
 	pluginAPI := &plugintest.API{}
@@ -536,3 +754,38 @@ func getMockPlaybookService(t *testing.T) (app.PlaybookService, *mock_playbook.M
 	keywordsIgnorer := mock_playbook.NewMockKeywordsThreadIgnorer(controller)
 	return app.NewPlaybookService(store, poster, telemetryService, client, configService, keywordsIgnorer), store, pluginAPI, keywordsIgnorer
 }
+
+func TestImportPlaybook(t *testing.T) {
+	setup := func(t *testing.T) (app.PlaybookService, *plugintest.API) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_playbook.NewMockPlaybookStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		configService := mock_config.NewMockService(controller)
+		configService.EXPECT().IsAtLeastE20Licensed().Return(true).AnyTimes()
+		configService.EXPECT().GetConfiguration().Return(&config.Configuration{}).AnyTimes()
+		keywordsIgnorer := mock_playbook.NewMockKeywordsThreadIgnorer(controller)
+
+		return app.NewPlaybookService(store, poster, &telemetry.NoopTelemetry{}, client, configService, keywordsIgnorer), pluginAPI
+	}
+
+	export := app.PlaybookExport{Version: app.PlaybookExportVersion, Title: "Imported"}
+
+	t.Run("guest", func(t *testing.T) {
+		s, pluginAPI := setup(t)
+		pluginAPI.On("GetUser", "userid").Return(&model.User{Id: "userid", Roles: model.SYSTEM_GUEST_ROLE_ID}, nil)
+
+		_, err := s.Import(export, "teamid", "userid")
+		require.ErrorIs(t, err, app.ErrPermission)
+	})
+
+	t.Run("failing to check permissions is not a permission error", func(t *testing.T) {
+		s, pluginAPI := setup(t)
+		pluginAPI.On("GetUser", "userid").Return(nil, &model.AppError{Message: "database unavailable"})
+
+		_, err := s.Import(export, "teamid", "userid")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, app.ErrPermission)
+	})
+}
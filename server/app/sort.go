@@ -36,6 +36,15 @@ const (
 
 	// SortByLastStatusUpdateAt sorts by when the playbook run was last updated.
 	SortByLastStatusUpdateAt SortField = "last_status_update_at"
+
+	// SortBySeverity sorts by the severity of a playbook run, relative to its severity levels.
+	SortBySeverity SortField = "severity"
+
+	// SortByDueAt sorts by the due date of a checklist item.
+	SortByDueAt SortField = "due_at"
+
+	// SortByAssignedAt sorts by when a checklist item was assigned to its assignee, i.e. by its age.
+	SortByAssignedAt SortField = "assigned_at"
 )
 
 // SortDirection is the type used to specify the ascending or descending order of returned results.
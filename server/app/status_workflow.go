@@ -0,0 +1,183 @@
+package app
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// MaxStatusNameLength is the maximum number of characters in the name of a status.
+const MaxStatusNameLength = 64
+
+// StatusState is a named status in a status workflow.
+type StatusState struct {
+	// Name is shown to users and stored as the run's current status.
+	Name string `json:"name"`
+
+	// Transitions lists the names of the statuses a run may move to from this one. Staying in
+	// the same status is always allowed.
+	Transitions []string `json:"transitions"`
+
+	// Resolved marks statuses in which the run is considered finished: the run stops counting
+	// as in progress and the retrospective reminder is triggered.
+	Resolved bool `json:"resolved"`
+
+	// Closed marks statuses in which the run is put away for good: status update reminders are
+	// no longer scheduled and the channel is exported if so configured. Closed implies resolved.
+	Closed bool `json:"closed"`
+}
+
+// StatusWorkflow describes the statuses a playbook run can go through. Runs start in the first
+// status of the workflow.
+type StatusWorkflow struct {
+	States []StatusState `json:"states"`
+}
+
+// DefaultStatusWorkflow returns the workflow used by playbooks that do not define their own:
+// Reported, Active, Resolved and Archived, with every transition allowed.
+func DefaultStatusWorkflow() StatusWorkflow {
+	names := []string{StatusReported, StatusActive, StatusResolved, StatusArchived}
+
+	workflow := StatusWorkflow{}
+	for _, name := range names {
+		var transitions []string
+		for _, other := range names {
+			if other != name {
+				transitions = append(transitions, other)
+			}
+		}
+
+		workflow.States = append(workflow.States, StatusState{
+			Name:        name,
+			Transitions: transitions,
+			Resolved:    name == StatusResolved || name == StatusArchived,
+			Closed:      name == StatusArchived,
+		})
+	}
+
+	return workflow
+}
+
+// IsEmpty returns true if the workflow defines no statuses.
+func (w StatusWorkflow) IsEmpty() bool {
+	return len(w.States) == 0
+}
+
+// Clone duplicates the given workflow.
+func (w StatusWorkflow) Clone() StatusWorkflow {
+	newWorkflow := StatusWorkflow{}
+	for _, state := range w.States {
+		state.Transitions = append([]string(nil), state.Transitions...)
+		newWorkflow.States = append(newWorkflow.States, state)
+	}
+
+	return newWorkflow
+}
+
+// State returns the status with the given name, and false if the workflow has no such status.
+func (w StatusWorkflow) State(name string) (StatusState, bool) {
+	for _, state := range w.States {
+		if state.Name == name {
+			return state, true
+		}
+	}
+
+	return StatusState{}, false
+}
+
+// InitialStatus returns the name of the status new runs start in.
+func (w StatusWorkflow) InitialStatus() string {
+	if w.IsEmpty() {
+		return ""
+	}
+
+	return w.States[0].Name
+}
+
+// IsResolved returns true if the given status is flagged as resolved.
+func (w StatusWorkflow) IsResolved(name string) bool {
+	state, ok := w.State(name)
+	return ok && (state.Resolved || state.Closed)
+}
+
+// IsClosed returns true if the given status is flagged as closed.
+func (w StatusWorkflow) IsClosed(name string) bool {
+	state, ok := w.State(name)
+	return ok && state.Closed
+}
+
+// CanTransition returns true if a run in status from may move to status to. Runs in a status
+// unknown to the workflow (e.g., one that was removed since) may move to any status.
+func (w StatusWorkflow) CanTransition(from, to string) bool {
+	if _, ok := w.State(to); !ok {
+		return false
+	}
+	if from == to {
+		return true
+	}
+
+	state, ok := w.State(from)
+	if !ok {
+		return true
+	}
+	for _, transition := range state.Transitions {
+		if transition == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NextStatuses returns the statuses a run in status from may move to, including from itself,
+// in workflow order.
+func (w StatusWorkflow) NextStatuses(from string) []string {
+	var statuses []string
+	for _, state := range w.States {
+		if w.CanTransition(from, state.Name) {
+			statuses = append(statuses, state.Name)
+		}
+	}
+
+	return statuses
+}
+
+// Validate returns an error if the workflow is not well formed: every status must have a unique,
+// non-empty name, transitions must refer to statuses of the workflow, and the initial status
+// cannot be resolved.
+func (w StatusWorkflow) Validate() error {
+	if w.IsEmpty() {
+		return errors.New("status workflow must have at least one status")
+	}
+
+	seen := make(map[string]bool)
+	for _, state := range w.States {
+		if strings.TrimSpace(state.Name) != state.Name || state.Name == "" {
+			return errors.Errorf("status name '%s' must not be empty or have surrounding whitespace", state.Name)
+		}
+		if utf8.RuneCountInString(state.Name) > MaxStatusNameLength {
+			return errors.Errorf("status name '%s' must be at most %d characters", state.Name, MaxStatusNameLength)
+		}
+
+		key := strings.ToLower(state.Name)
+		if seen[key] {
+			return errors.Errorf("status '%s' is defined more than once", state.Name)
+		}
+		seen[key] = true
+	}
+
+	for _, state := range w.States {
+		for _, transition := range state.Transitions {
+			if _, ok := w.State(transition); !ok {
+				return errors.Errorf("status '%s' has a transition to unknown status '%s'", state.Name, transition)
+			}
+		}
+	}
+
+	if w.IsResolved(w.InitialStatus()) {
+		return errors.Errorf("initial status '%s' must not be resolved or closed", w.InitialStatus())
+	}
+
+	return nil
+}
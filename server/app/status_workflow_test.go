@@ -0,0 +1,125 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testStatusWorkflow() StatusWorkflow {
+	return StatusWorkflow{
+		States: []StatusState{
+			{Name: "Triage", Transitions: []string{"Mitigating"}},
+			{Name: "Mitigating", Transitions: []string{"Monitoring", "Triage"}},
+			{Name: "Monitoring", Transitions: []string{"Mitigating", "Done"}, Resolved: true},
+			{Name: "Done", Closed: true},
+		},
+	}
+}
+
+func TestDefaultStatusWorkflow(t *testing.T) {
+	workflow := DefaultStatusWorkflow()
+	require.NoError(t, workflow.Validate())
+	require.Equal(t, StatusReported, workflow.InitialStatus())
+
+	require.False(t, workflow.IsResolved(StatusReported))
+	require.False(t, workflow.IsResolved(StatusActive))
+	require.True(t, workflow.IsResolved(StatusResolved))
+	require.True(t, workflow.IsResolved(StatusArchived))
+	require.False(t, workflow.IsClosed(StatusResolved))
+	require.True(t, workflow.IsClosed(StatusArchived))
+
+	for _, from := range []string{StatusReported, StatusActive, StatusResolved, StatusArchived} {
+		require.Equal(t, []string{StatusReported, StatusActive, StatusResolved, StatusArchived}, workflow.NextStatuses(from))
+	}
+}
+
+func TestStatusWorkflow_CanTransition(t *testing.T) {
+	workflow := testStatusWorkflow()
+
+	for name, tc := range map[string]struct {
+		from     string
+		to       string
+		expected bool
+	}{
+		"allowed transition":               {from: "Triage", to: "Mitigating", expected: true},
+		"transition not listed":            {from: "Triage", to: "Monitoring", expected: false},
+		"same status":                      {from: "Done", to: "Done", expected: true},
+		"closed status without transition": {from: "Done", to: "Triage", expected: false},
+		"unknown target status":            {from: "Triage", to: "Resolved", expected: false},
+		"unknown current status":           {from: "Active", to: "Monitoring", expected: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, workflow.CanTransition(tc.from, tc.to))
+		})
+	}
+}
+
+func TestStatusWorkflow_NextStatuses(t *testing.T) {
+	workflow := testStatusWorkflow()
+
+	require.Equal(t, []string{"Triage", "Mitigating"}, workflow.NextStatuses("Triage"))
+	require.Equal(t, []string{"Triage", "Mitigating", "Monitoring"}, workflow.NextStatuses("Mitigating"))
+	require.Equal(t, []string{"Done"}, workflow.NextStatuses("Done"))
+}
+
+func TestStatusWorkflow_IsResolved(t *testing.T) {
+	workflow := testStatusWorkflow()
+
+	require.False(t, workflow.IsResolved("Mitigating"))
+	require.True(t, workflow.IsResolved("Monitoring"))
+	require.False(t, workflow.IsClosed("Monitoring"))
+	require.True(t, workflow.IsResolved("Done"), "closed statuses are resolved")
+	require.True(t, workflow.IsClosed("Done"))
+	require.False(t, workflow.IsResolved("Unknown"))
+}
+
+func TestStatusWorkflow_Validate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		workflow    StatusWorkflow
+		expectedErr string
+	}{
+		"valid": {
+			workflow: testStatusWorkflow(),
+		},
+		"empty": {
+			workflow:    StatusWorkflow{},
+			expectedErr: "at least one status",
+		},
+		"blank name": {
+			workflow:    StatusWorkflow{States: []StatusState{{Name: " "}}},
+			expectedErr: "must not be empty",
+		},
+		"name with surrounding whitespace": {
+			workflow:    StatusWorkflow{States: []StatusState{{Name: "Open "}}},
+			expectedErr: "must not be empty or have surrounding whitespace",
+		},
+		"name too long": {
+			workflow:    StatusWorkflow{States: []StatusState{{Name: strings.Repeat("a", MaxStatusNameLength+1)}}},
+			expectedErr: "at most",
+		},
+		"duplicate names ignoring case": {
+			workflow:    StatusWorkflow{States: []StatusState{{Name: "Open"}, {Name: "open"}}},
+			expectedErr: "more than once",
+		},
+		"transition to unknown status": {
+			workflow:    StatusWorkflow{States: []StatusState{{Name: "Open", Transitions: []string{"Closed"}}}},
+			expectedErr: "unknown status 'Closed'",
+		},
+		"resolved initial status": {
+			workflow:    StatusWorkflow{States: []StatusState{{Name: "Done", Resolved: true}}},
+			expectedErr: "initial status",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.workflow.Validate()
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
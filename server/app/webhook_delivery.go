@@ -0,0 +1,289 @@
+package app
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// WebhookDeliveryPrefix prefixes the keys of the scheduled jobs that attempt webhook deliveries.
+const WebhookDeliveryPrefix = "webhook_"
+
+// WebhookSignatureHeader is the header carrying the signature of the webhook requests of playbook
+// runs whose playbook has a webhook secret. Its value is built by SignWebhookPayload.
+const WebhookSignatureHeader = "X-Playbooks-Signature"
+
+// MaxWebhookDeliveryAttempts is the number of attempts after which a webhook delivery is given up
+// on and marked as failed.
+const MaxWebhookDeliveryAttempts = 8
+
+const (
+	webhookRetryBaseDelay = 30 * time.Second
+	webhookRetryMaxDelay  = time.Hour
+
+	maxWebhookDeliveryErrorLength = 1024
+)
+
+// WebhookDeliveryStatus is the state of a webhook delivery.
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending is the status of a delivery that has not succeeded yet but will be attempted again.
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+
+	// WebhookDeliverySucceeded is the status of a delivery whose last attempt received a 2xx response.
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+
+	// WebhookDeliveryFailed is the status of a delivery that failed MaxWebhookDeliveryAttempts times.
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is an outgoing webhook request for a playbook run, along with the outcome of
+// the attempts made to deliver it.
+type WebhookDelivery struct {
+	ID             string                `json:"id"`
+	PlaybookRunID  string                `json:"playbook_run_id"`
+	EventType      WebhookEventType      `json:"event_type"`
+	URL            string                `json:"url"`
+	Payload        string                `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	LastStatusCode int                   `json:"last_status_code"`
+	LastLatencyMs  int64                 `json:"last_latency_ms"`
+	LastError      string                `json:"last_error"`
+	NextAttemptAt  int64                 `json:"next_attempt_at"`
+	CreateAt       int64                 `json:"create_at"`
+	UpdateAt       int64                 `json:"update_at"`
+}
+
+// WebhookRetryDelay returns how long to wait before attempting a delivery again after the given
+// number of failed attempts: the delay doubles after every attempt, up to an hour.
+func WebhookRetryDelay(attempts int) time.Duration {
+	delay := webhookRetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookRetryMaxDelay {
+			return webhookRetryMaxDelay
+		}
+	}
+
+	return delay
+}
+
+// SignWebhookPayload returns the value of the WebhookSignatureHeader of a request with the given
+// body sent at the given time: "t=<timestamp>,v1=<signature>", where timestamp is the number of
+// seconds since the Unix epoch and signature is the hex-encoded HMAC-SHA256, keyed with secret, of
+// the timestamp, a period and the body. Receivers can check it with client.VerifyWebhookSignature.
+func SignWebhookPayload(secret string, timestamp time.Time, payload []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t + "."))
+	mac.Write(payload)
+
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookDeliveryJobKey returns the key of the job making the given attempt of a delivery. Every
+// attempt has its own key, since a job cannot reschedule itself with the same key.
+func webhookDeliveryJobKey(deliveryID string, attempt int) string {
+	return WebhookDeliveryPrefix + deliveryID + "_" + strconv.Itoa(attempt)
+}
+
+// queueWebhook stores a delivery of the given payload to url and schedules its first attempt.
+func (s *PlaybookRunServiceImpl) queueWebhook(playbookRunID string, eventType WebhookEventType, url string, payload []byte) (*WebhookDelivery, error) {
+	now := model.GetMillis()
+	delivery, err := s.store.CreateWebhookDelivery(&WebhookDelivery{
+		PlaybookRunID: playbookRunID,
+		EventType:     eventType,
+		URL:           url,
+		Payload:       string(payload),
+		Status:        WebhookDeliveryPending,
+		NextAttemptAt: now,
+		CreateAt:      now,
+		UpdateAt:      now,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to store webhook delivery")
+	}
+
+	if _, err = s.scheduler.ScheduleOnce(webhookDeliveryJobKey(delivery.ID, 1), time.Now()); err != nil {
+		return nil, errors.Wrap(err, "failed to schedule webhook delivery")
+	}
+
+	return delivery, nil
+}
+
+// handleWebhookDelivery makes the next attempt of a pending delivery, scheduling another one with
+// exponential backoff if it fails.
+func (s *PlaybookRunServiceImpl) handleWebhookDelivery(key string) {
+	deliveryID := strings.TrimPrefix(key, WebhookDeliveryPrefix)
+	attempt := 0
+	if i := strings.Index(deliveryID, "_"); i >= 0 {
+		attempt, _ = strconv.Atoi(deliveryID[i+1:])
+		deliveryID = deliveryID[:i]
+	}
+
+	delivery, err := s.store.GetWebhookDelivery(deliveryID)
+	if err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "handleWebhookDelivery failed to get webhook delivery id: %s", deliveryID).Error())
+		return
+	}
+
+	// The delivery may have been completed by an attempt scheduled more than once.
+	if delivery.Status != WebhookDeliveryPending {
+		return
+	}
+
+	playbookRun, err := s.store.GetPlaybookRun(delivery.PlaybookRunID)
+	if err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "handleWebhookDelivery failed to get playbook run id: %s", delivery.PlaybookRunID).Error())
+		return
+	}
+
+	start := time.Now()
+	statusCode, err := s.postWebhook(delivery.URL, delivery.Payload, playbookRun.WebhookSecret)
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	delivery.LastLatencyMs = time.Since(start).Milliseconds()
+	delivery.LastError = ""
+	delivery.NextAttemptAt = 0
+	delivery.UpdateAt = model.GetMillis()
+
+	switch {
+	case err == nil:
+		delivery.Status = WebhookDeliverySucceeded
+	case delivery.Attempts >= MaxWebhookDeliveryAttempts:
+		delivery.Status = WebhookDeliveryFailed
+		delivery.LastError = truncateWebhookError(err)
+	default:
+		delivery.LastError = truncateWebhookError(err)
+		delivery.NextAttemptAt = model.GetMillisForTime(start.Add(WebhookRetryDelay(delivery.Attempts)))
+	}
+
+	if err = s.store.UpdateWebhookDelivery(delivery); err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "failed to update webhook delivery id: %s", delivery.ID).Error())
+
+		// The stored delivery is still pending: attempt it again rather than leaving it pending
+		// forever, unless this attempt succeeded.
+		if delivery.Status != WebhookDeliverySucceeded {
+			s.scheduleWebhookDeliveryRetry(delivery.ID, attempt+1, start.Add(WebhookRetryDelay(delivery.Attempts)))
+		}
+		return
+	}
+
+	switch delivery.Status {
+	case WebhookDeliveryPending:
+		s.logger.Warnf("webhook delivery %s to %s failed, attempt %d of %d: %s", delivery.ID, delivery.URL, delivery.Attempts, MaxWebhookDeliveryAttempts, delivery.LastError)
+		s.scheduleWebhookDeliveryRetry(delivery.ID, attempt+1, model.GetTimeForMillis(delivery.NextAttemptAt))
+	case WebhookDeliveryFailed:
+		s.logger.Warnf("webhook delivery %s to %s failed after %d attempts: %s", delivery.ID, delivery.URL, delivery.Attempts, delivery.LastError)
+		s.postWebhookDeliveryFailure(playbookRun.ChannelID, delivery)
+	}
+}
+
+// scheduleWebhookDeliveryRetry schedules the given attempt of a delivery. Attempts are numbered
+// after the job keys rather than the stored attempts, which lag behind when storing an attempt
+// failed, so that no job reschedules its own key.
+func (s *PlaybookRunServiceImpl) scheduleWebhookDeliveryRetry(deliveryID string, attempt int, runAt time.Time) {
+	if _, err := s.scheduler.ScheduleOnce(webhookDeliveryJobKey(deliveryID, attempt), runAt); err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "failed to schedule retry of webhook delivery id: %s", deliveryID).Error())
+	}
+}
+
+// postWebhook sends a POST request with the given JSON body, signed with secret unless it is
+// empty, returning the response status code. It blocks until a response is received.
+func (s *PlaybookRunServiceImpl) postWebhook(url, payload, secret string) (int, error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader([]byte(payload)))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(secret, time.Now(), []byte(payload)))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, errors.Errorf("response code is %d; expected a status code in the 2xx range", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (s *PlaybookRunServiceImpl) postWebhookDeliveryFailure(channelID string, delivery *WebhookDelivery) {
+	var announcement string
+	switch delivery.EventType {
+	case WebhookEventPlaybookRunCreated:
+		announcement = "Playbook run creation announcement"
+	case WebhookEventStatusUpdated:
+		announcement = "Playbook run update announcement"
+	default:
+		announcement = fmt.Sprintf("Delivery of the %s event", delivery.EventType)
+	}
+
+	_, _ = s.poster.PostMessage(channelID,
+		"%s through the outgoing webhook failed after %d attempts. Contact your System Admin for more information.",
+		announcement, delivery.Attempts)
+}
+
+// truncateWebhookError returns the error message cut to at most maxWebhookDeliveryErrorLength
+// bytes, without splitting a character.
+func truncateWebhookError(err error) string {
+	message := err.Error()
+	if len(message) <= maxWebhookDeliveryErrorLength {
+		return message
+	}
+
+	end := maxWebhookDeliveryErrorLength
+	for end > 0 && !utf8.RuneStart(message[end]) {
+		end--
+	}
+
+	return message[:end]
+}
+
+// GetWebhookDeliveries returns the webhook deliveries of a playbook run, most recent first. An
+// empty status returns deliveries in any status.
+func (s *PlaybookRunServiceImpl) GetWebhookDeliveries(playbookRunID string, status WebhookDeliveryStatus) ([]WebhookDelivery, error) {
+	deliveries, err := s.store.GetWebhookDeliveries(playbookRunID, status)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get webhook deliveries for playbook run id: %s", playbookRunID)
+	}
+
+	return deliveries, nil
+}
+
+// RedeliverWebhook queues a new delivery of the payload of a failed delivery, and returns it.
+func (s *PlaybookRunServiceImpl) RedeliverWebhook(playbookRunID, deliveryID string) (*WebhookDelivery, error) {
+	delivery, err := s.store.GetWebhookDelivery(deliveryID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get webhook delivery id: %s", deliveryID)
+	}
+
+	if delivery.PlaybookRunID != playbookRunID {
+		return nil, errors.Wrapf(ErrNotFound, "webhook delivery %s does not belong to playbook run %s", deliveryID, playbookRunID)
+	}
+
+	if delivery.Status != WebhookDeliveryFailed {
+		return nil, errors.Wrapf(ErrMalformedPlaybookRun, "only failed webhook deliveries can be redelivered; delivery %s is %s", deliveryID, delivery.Status)
+	}
+
+	return s.queueWebhook(delivery.PlaybookRunID, delivery.EventType, delivery.URL, []byte(delivery.Payload))
+}
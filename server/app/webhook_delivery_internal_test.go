@@ -0,0 +1,27 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateWebhookError(t *testing.T) {
+	t.Run("short error is kept", func(t *testing.T) {
+		require.Equal(t, "connection refused", truncateWebhookError(errors.New("connection refused")))
+	})
+
+	t.Run("long error is truncated", func(t *testing.T) {
+		message := truncateWebhookError(errors.New(strings.Repeat("a", 2*maxWebhookDeliveryErrorLength)))
+		require.Len(t, message, maxWebhookDeliveryErrorLength)
+	})
+
+	t.Run("long error is truncated on a character boundary", func(t *testing.T) {
+		message := truncateWebhookError(errors.New("a" + strings.Repeat("é", maxWebhookDeliveryErrorLength)))
+		require.True(t, utf8.ValidString(message))
+		require.Len(t, message, maxWebhookDeliveryErrorLength-1)
+	})
+}
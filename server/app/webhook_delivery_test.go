@@ -0,0 +1,307 @@
+package app_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	icClient "github.com/mattermost/mattermost-plugin-incident-collaboration/client"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/app"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/telemetry"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	mock_app "github.com/mattermost/mattermost-plugin-incident-collaboration/server/app/mocks"
+	mock_bot "github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot/mocks"
+	mock_config "github.com/mattermost/mattermost-plugin-incident-collaboration/server/config/mocks"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/cluster"
+)
+
+func TestWebhookRetryDelay(t *testing.T) {
+	require.Equal(t, 30*time.Second, app.WebhookRetryDelay(1))
+	require.Equal(t, time.Minute, app.WebhookRetryDelay(2))
+	require.Equal(t, 8*time.Minute, app.WebhookRetryDelay(5))
+	require.Equal(t, time.Hour, app.WebhookRetryDelay(8))
+	require.Equal(t, time.Hour, app.WebhookRetryDelay(100))
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	payload := []byte(`{"id": "run"}`)
+	signature := app.SignWebhookPayload("s3cr3t", time.Unix(1625000000, 0), payload)
+
+	require.Equal(t, "t=1625000000,v1=", signature[:len("t=1625000000,v1=")])
+	require.Equal(t, signature, app.SignWebhookPayload("s3cr3t", time.Unix(1625000000, 0), payload))
+	require.NotEqual(t, signature, app.SignWebhookPayload("other", time.Unix(1625000000, 0), payload))
+	require.NotEqual(t, signature, app.SignWebhookPayload("s3cr3t", time.Unix(1625000001, 0), payload))
+}
+
+func TestHandleWebhookDelivery(t *testing.T) {
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failingServer.Close()
+
+	setup := func(t *testing.T) (*mock_app.MockPlaybookRunStore, *mock_bot.MockPoster, *mock_app.MockJobOnceScheduler, *app.PlaybookRunServiceImpl) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any()).AnyTimes()
+		logger.EXPECT().Errorf(gomock.Any()).AnyTimes()
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{})
+		return store, poster, scheduler, s
+	}
+
+	t.Run("failed attempt is retried with backoff", func(t *testing.T) {
+		store, _, scheduler, s := setup(t)
+
+		delivery := &app.WebhookDelivery{
+			ID:            model.NewId(),
+			PlaybookRunID: model.NewId(),
+			EventType:     app.WebhookEventStatusUpdated,
+			URL:           failingServer.URL,
+			Payload:       "{}",
+			Status:        app.WebhookDeliveryPending,
+			Attempts:      2,
+		}
+
+		store.EXPECT().GetWebhookDelivery(delivery.ID).Return(delivery, nil)
+		store.EXPECT().GetPlaybookRun(delivery.PlaybookRunID).Return(&app.PlaybookRun{ID: delivery.PlaybookRunID}, nil)
+		store.EXPECT().UpdateWebhookDelivery(delivery).Return(nil)
+
+		var retryKey string
+		var retryAt time.Time
+		scheduler.EXPECT().ScheduleOnce(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(key string, runAt time.Time) (*cluster.JobOnce, error) {
+				retryKey = key
+				retryAt = runAt
+				return nil, nil
+			})
+
+		before := time.Now()
+		s.HandleReminder(app.WebhookDeliveryPrefix + delivery.ID + "_3")
+
+		require.Equal(t, app.WebhookDeliveryPending, delivery.Status)
+		require.Equal(t, 3, delivery.Attempts)
+		require.Equal(t, http.StatusServiceUnavailable, delivery.LastStatusCode)
+		require.Contains(t, delivery.LastError, "503")
+		require.Equal(t, app.WebhookDeliveryPrefix+delivery.ID+"_4", retryKey)
+		require.WithinDuration(t, before.Add(app.WebhookRetryDelay(3)), retryAt, 5*time.Second)
+		require.Equal(t, model.GetMillisForTime(retryAt), delivery.NextAttemptAt)
+	})
+
+	t.Run("failed attempt is retried when it cannot be stored", func(t *testing.T) {
+		store, _, scheduler, s := setup(t)
+
+		delivery := &app.WebhookDelivery{
+			ID:            model.NewId(),
+			PlaybookRunID: model.NewId(),
+			EventType:     app.WebhookEventStatusUpdated,
+			URL:           failingServer.URL,
+			Payload:       "{}",
+			Status:        app.WebhookDeliveryPending,
+			Attempts:      2,
+		}
+
+		store.EXPECT().GetWebhookDelivery(delivery.ID).Return(delivery, nil)
+		store.EXPECT().GetPlaybookRun(delivery.PlaybookRunID).Return(&app.PlaybookRun{ID: delivery.PlaybookRunID}, nil)
+		store.EXPECT().UpdateWebhookDelivery(delivery).Return(errors.New("database unavailable"))
+		scheduler.EXPECT().ScheduleOnce(app.WebhookDeliveryPrefix+delivery.ID+"_4", gomock.Any())
+
+		s.HandleReminder(app.WebhookDeliveryPrefix + delivery.ID + "_3")
+	})
+
+	t.Run("delivery fails after the maximum number of attempts", func(t *testing.T) {
+		store, poster, _, s := setup(t)
+
+		delivery := &app.WebhookDelivery{
+			ID:            model.NewId(),
+			PlaybookRunID: model.NewId(),
+			EventType:     app.WebhookEventPlaybookRunCreated,
+			URL:           failingServer.URL,
+			Payload:       "{}",
+			Status:        app.WebhookDeliveryPending,
+			Attempts:      app.MaxWebhookDeliveryAttempts - 1,
+		}
+
+		store.EXPECT().GetWebhookDelivery(delivery.ID).Return(delivery, nil)
+		store.EXPECT().UpdateWebhookDelivery(delivery).Return(nil)
+		store.EXPECT().GetPlaybookRun(delivery.PlaybookRunID).Return(&app.PlaybookRun{ID: delivery.PlaybookRunID, ChannelID: "channel_id"}, nil)
+		poster.EXPECT().PostMessage("channel_id", gomock.Any(), "Playbook run creation announcement", app.MaxWebhookDeliveryAttempts)
+
+		s.HandleReminder(app.WebhookDeliveryPrefix + delivery.ID + "_8")
+
+		require.Equal(t, app.WebhookDeliveryFailed, delivery.Status)
+		require.Equal(t, app.MaxWebhookDeliveryAttempts, delivery.Attempts)
+		require.Zero(t, delivery.NextAttemptAt)
+	})
+
+	t.Run("request is signed with the webhook secret", func(t *testing.T) {
+		store, _, _, s := setup(t)
+
+		type signedRequest struct {
+			signature string
+			body      []byte
+		}
+		requests := make(chan signedRequest, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			requests <- signedRequest{signature: r.Header.Get(icClient.WebhookSignatureHeader), body: body}
+		}))
+		defer server.Close()
+
+		delivery := &app.WebhookDelivery{
+			ID:            model.NewId(),
+			PlaybookRunID: model.NewId(),
+			EventType:     app.WebhookEventStatusUpdated,
+			URL:           server.URL,
+			Payload:       `{"id": "run"}`,
+			Status:        app.WebhookDeliveryPending,
+		}
+
+		store.EXPECT().GetWebhookDelivery(delivery.ID).Return(delivery, nil)
+		store.EXPECT().GetPlaybookRun(delivery.PlaybookRunID).Return(&app.PlaybookRun{ID: delivery.PlaybookRunID, WebhookSecret: "s3cr3t"}, nil)
+		store.EXPECT().UpdateWebhookDelivery(delivery).Return(nil)
+
+		s.HandleReminder(app.WebhookDeliveryPrefix + delivery.ID + "_1")
+		require.Equal(t, app.WebhookDeliverySucceeded, delivery.Status)
+
+		request := <-requests
+		require.Equal(t, delivery.Payload, string(request.body))
+		require.NoError(t, icClient.VerifyWebhookSignature("s3cr3t", request.signature, request.body, icClient.DefaultWebhookTolerance))
+		require.ErrorIs(t, icClient.VerifyWebhookSignature("other", request.signature, request.body, icClient.DefaultWebhookTolerance), icClient.ErrInvalidWebhookSignature)
+	})
+
+	t.Run("request is not signed without a webhook secret", func(t *testing.T) {
+		store, _, _, s := setup(t)
+
+		signatures := make(chan []string, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			signatures <- r.Header.Values(app.WebhookSignatureHeader)
+		}))
+		defer server.Close()
+
+		delivery := &app.WebhookDelivery{
+			ID:            model.NewId(),
+			PlaybookRunID: model.NewId(),
+			EventType:     app.WebhookEventStatusUpdated,
+			URL:           server.URL,
+			Payload:       "{}",
+			Status:        app.WebhookDeliveryPending,
+		}
+
+		store.EXPECT().GetWebhookDelivery(delivery.ID).Return(delivery, nil)
+		store.EXPECT().GetPlaybookRun(delivery.PlaybookRunID).Return(&app.PlaybookRun{ID: delivery.PlaybookRunID}, nil)
+		store.EXPECT().UpdateWebhookDelivery(delivery).Return(nil)
+
+		s.HandleReminder(app.WebhookDeliveryPrefix + delivery.ID + "_1")
+		require.Empty(t, <-signatures)
+	})
+
+	t.Run("completed delivery is not attempted again", func(t *testing.T) {
+		store, _, _, s := setup(t)
+
+		delivery := &app.WebhookDelivery{
+			ID:       model.NewId(),
+			URL:      failingServer.URL,
+			Status:   app.WebhookDeliverySucceeded,
+			Attempts: 1,
+		}
+
+		store.EXPECT().GetWebhookDelivery(delivery.ID).Return(delivery, nil)
+
+		s.HandleReminder(app.WebhookDeliveryPrefix + delivery.ID + "_2")
+
+		require.Equal(t, 1, delivery.Attempts)
+	})
+}
+
+func TestRedeliverWebhook(t *testing.T) {
+	setup := func(t *testing.T) (*mock_app.MockPlaybookRunStore, *mock_app.MockJobOnceScheduler, *app.PlaybookRunServiceImpl) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{})
+		return store, scheduler, s
+	}
+
+	playbookRunID := model.NewId()
+	failedDelivery := app.WebhookDelivery{
+		ID:             model.NewId(),
+		PlaybookRunID:  playbookRunID,
+		EventType:      app.WebhookEventStatusUpdated,
+		URL:            "https://example.com/webhook",
+		Payload:        `{"id": "run"}`,
+		Status:         app.WebhookDeliveryFailed,
+		Attempts:       app.MaxWebhookDeliveryAttempts,
+		LastStatusCode: http.StatusServiceUnavailable,
+	}
+
+	t.Run("failed delivery is queued again", func(t *testing.T) {
+		store, scheduler, s := setup(t)
+
+		delivery := failedDelivery
+		store.EXPECT().GetWebhookDelivery(delivery.ID).Return(&delivery, nil)
+		store.EXPECT().CreateWebhookDelivery(gomock.AssignableToTypeOf(&app.WebhookDelivery{})).
+			DoAndReturn(func(d *app.WebhookDelivery) (*app.WebhookDelivery, error) {
+				d.ID = model.NewId()
+				return d, nil
+			})
+		scheduler.EXPECT().ScheduleOnce(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(key string, runAt time.Time) (*cluster.JobOnce, error) {
+				require.True(t, strings.HasPrefix(key, app.WebhookDeliveryPrefix))
+				return nil, nil
+			})
+
+		redelivery, err := s.RedeliverWebhook(playbookRunID, delivery.ID)
+		require.NoError(t, err)
+		require.NotEqual(t, delivery.ID, redelivery.ID)
+		require.Equal(t, app.WebhookDeliveryPending, redelivery.Status)
+		require.Zero(t, redelivery.Attempts)
+		require.Equal(t, delivery.EventType, redelivery.EventType)
+		require.Equal(t, delivery.URL, redelivery.URL)
+		require.Equal(t, delivery.Payload, redelivery.Payload)
+	})
+
+	t.Run("pending delivery", func(t *testing.T) {
+		store, _, s := setup(t)
+
+		delivery := failedDelivery
+		delivery.Status = app.WebhookDeliveryPending
+		store.EXPECT().GetWebhookDelivery(delivery.ID).Return(&delivery, nil)
+
+		_, err := s.RedeliverWebhook(playbookRunID, delivery.ID)
+		require.ErrorIs(t, err, app.ErrMalformedPlaybookRun)
+	})
+
+	t.Run("delivery of another playbook run", func(t *testing.T) {
+		store, _, s := setup(t)
+
+		delivery := failedDelivery
+		store.EXPECT().GetWebhookDelivery(delivery.ID).Return(&delivery, nil)
+
+		_, err := s.RedeliverWebhook(model.NewId(), delivery.ID)
+		require.ErrorIs(t, err, app.ErrNotFound)
+	})
+}
@@ -0,0 +1,240 @@
+package app
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// WebhookEventVersion is the version of the WebhookEvent envelope. It changes only when a change
+// would break existing receivers; new fields may be added to the current version.
+const WebhookEventVersion = 1
+
+// MaxWebhookSubscriptions is the maximum number of webhook subscriptions of a playbook.
+const MaxWebhookSubscriptions = 16
+
+// MaxWebhookSubscriptionURLs is the maximum number of URLs of a single webhook subscription.
+const MaxWebhookSubscriptionURLs = 8
+
+// WebhookEventType is the event of a playbook run that triggered a webhook.
+type WebhookEventType string
+
+const (
+	WebhookEventPlaybookRunCreated           WebhookEventType = "playbook_run_created"
+	WebhookEventStatusUpdated                WebhookEventType = "status_updated"
+	WebhookEventOwnerChanged                 WebhookEventType = "owner_changed"
+	WebhookEventChecklistItemStateChanged    WebhookEventType = "checklist_item_state_changed"
+	WebhookEventChecklistItemAssigneeChanged WebhookEventType = "checklist_item_assignee_changed"
+	WebhookEventTimelineEventAdded           WebhookEventType = "timeline_event_added"
+	WebhookEventParticipantJoined            WebhookEventType = "participant_joined"
+	WebhookEventParticipantLeft              WebhookEventType = "participant_left"
+	WebhookEventRetrospectivePublished       WebhookEventType = "retrospective_published"
+	WebhookEventRetrospectiveCanceled        WebhookEventType = "retrospective_canceled"
+)
+
+// WebhookEventTypes lists the events a webhook subscription can include.
+var WebhookEventTypes = []WebhookEventType{
+	WebhookEventPlaybookRunCreated,
+	WebhookEventStatusUpdated,
+	WebhookEventOwnerChanged,
+	WebhookEventChecklistItemStateChanged,
+	WebhookEventChecklistItemAssigneeChanged,
+	WebhookEventTimelineEventAdded,
+	WebhookEventParticipantJoined,
+	WebhookEventParticipantLeft,
+	WebhookEventRetrospectivePublished,
+	WebhookEventRetrospectiveCanceled,
+}
+
+// WebhookSubscription sends a WebhookEvent to each of its URLs whenever one of its events happens
+// in a run of the playbook. Runs copy the playbook's subscriptions when they start.
+type WebhookSubscription struct {
+	Events []WebhookEventType `json:"events"`
+	URLs   []string           `json:"urls"`
+}
+
+// CloneWebhookSubscriptions duplicates the given webhook subscriptions.
+func CloneWebhookSubscriptions(subscriptions []WebhookSubscription) []WebhookSubscription {
+	if subscriptions == nil {
+		return nil
+	}
+
+	newSubscriptions := make([]WebhookSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		subscription.Events = append([]WebhookEventType(nil), subscription.Events...)
+		subscription.URLs = append([]string(nil), subscription.URLs...)
+		newSubscriptions = append(newSubscriptions, subscription)
+	}
+
+	return newSubscriptions
+}
+
+// ValidateWebhookSubscriptions checks that every subscription has at least one known event and
+// at least one absolute HTTP(S) URL, without duplicates, and trims the whitespace around the URLs.
+func ValidateWebhookSubscriptions(subscriptions []WebhookSubscription) error {
+	if len(subscriptions) > MaxWebhookSubscriptions {
+		return errors.Errorf("a playbook can have at most %d webhook subscriptions", MaxWebhookSubscriptions)
+	}
+
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+
+		if len(subscription.Events) == 0 {
+			return errors.Errorf("webhook subscription %d has no events", i)
+		}
+
+		events := make(map[WebhookEventType]bool, len(subscription.Events))
+		for _, event := range subscription.Events {
+			if !isWebhookEventType(event) {
+				return errors.Errorf("webhook subscription %d has an unknown event %q", i, event)
+			}
+			if events[event] {
+				return errors.Errorf("webhook subscription %d has the event %q more than once", i, event)
+			}
+			events[event] = true
+		}
+
+		if len(subscription.URLs) == 0 {
+			return errors.Errorf("webhook subscription %d has no URLs", i)
+		}
+		if len(subscription.URLs) > MaxWebhookSubscriptionURLs {
+			return errors.Errorf("webhook subscription %d has more than %d URLs", i, MaxWebhookSubscriptionURLs)
+		}
+
+		urls := make(map[string]bool, len(subscription.URLs))
+		for j, webhookURL := range subscription.URLs {
+			webhookURL = strings.TrimSpace(webhookURL)
+			if webhookURL == "" {
+				return errors.Errorf("webhook subscription %d has an empty URL", i)
+			}
+			if err := validateWebhookURL(webhookURL); err != nil {
+				return errors.Wrapf(err, "webhook subscription %d has an invalid URL %q", i, webhookURL)
+			}
+			if urls[webhookURL] {
+				return errors.Errorf("webhook subscription %d has the URL %q more than once", i, webhookURL)
+			}
+			urls[webhookURL] = true
+			subscription.URLs[j] = webhookURL
+		}
+	}
+
+	return nil
+}
+
+func isWebhookEventType(eventType WebhookEventType) bool {
+	for _, known := range WebhookEventTypes {
+		if eventType == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebhookSubscriptionURLs returns the URLs subscribed to the given event, each one only once.
+func WebhookSubscriptionURLs(subscriptions []WebhookSubscription, eventType WebhookEventType) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, subscription := range subscriptions {
+		for _, event := range subscription.Events {
+			if event != eventType {
+				continue
+			}
+
+			for _, webhookURL := range subscription.URLs {
+				if !seen[webhookURL] {
+					seen[webhookURL] = true
+					urls = append(urls, webhookURL)
+				}
+			}
+			break
+		}
+	}
+
+	return urls
+}
+
+// WebhookEvent is the body of the requests sent to webhook subscriptions. Its fields are stable
+// within a Version.
+type WebhookEvent struct {
+	Version     int                 `json:"version"`
+	ID          string              `json:"id"` // Identical across the attempts to deliver an event, so that receivers can ignore duplicates
+	Type        WebhookEventType    `json:"type"`
+	CreateAt    int64               `json:"create_at"`
+	UserID      string              `json:"user_id"` // The user who triggered the event, if any
+	PlaybookRun *PlaybookRun        `json:"playbook_run"`
+	ChannelURL  string              `json:"channel_url"`
+	DetailsURL  string              `json:"details_url"`
+	Details     WebhookEventDetails `json:"details"`
+}
+
+// WebhookEventDetails describes what happened in a WebhookEvent, beyond the playbook run's new
+// state. Only the fields relevant to the event's type are set.
+type WebhookEventDetails struct {
+	PreviousOwnerUserID string                `json:"previous_owner_user_id,omitempty"`
+	ChecklistItem       *WebhookChecklistItem `json:"checklist_item,omitempty"`
+	TimelineEvent       *TimelineEvent        `json:"timeline_event,omitempty"`
+	ParticipantUserID   string                `json:"participant_user_id,omitempty"`
+}
+
+// WebhookChecklistItem locates the checklist item of a WebhookEvent in the playbook run's
+// checklists, and holds its previous state and assignee.
+type WebhookChecklistItem struct {
+	ChecklistNum       int    `json:"checklist_num"`
+	ItemNum            int    `json:"item_num"`
+	PreviousState      string `json:"previous_state"`
+	PreviousAssigneeID string `json:"previous_assignee_id"`
+}
+
+// queueWebhookEvent queues a delivery of an event to every URL of the playbook run's webhook
+// subscriptions that include it. Errors are logged rather than returned, so that a webhook never
+// fails the action that triggered it.
+func (s *PlaybookRunServiceImpl) queueWebhookEvent(playbookRun *PlaybookRun, eventType WebhookEventType, userID string, details WebhookEventDetails) {
+	urls := WebhookSubscriptionURLs(playbookRun.WebhookSubscriptions, eventType)
+	if len(urls) == 0 {
+		return
+	}
+
+	body, err := s.webhookEventPayload(playbookRun, eventType, userID, details)
+	if err != nil {
+		s.logger.Warnf("failed to build the %s webhook event of playbook run %s: %s", eventType, playbookRun.ID, err.Error())
+		return
+	}
+
+	for _, webhookURL := range urls {
+		if _, err := s.queueWebhook(playbookRun.ID, eventType, webhookURL, body); err != nil {
+			s.logger.Warnf("failed to queue the %s webhook event of playbook run %s to %s: %s", eventType, playbookRun.ID, webhookURL, err.Error())
+		}
+	}
+}
+
+func (s *PlaybookRunServiceImpl) webhookEventPayload(playbookRun *PlaybookRun, eventType WebhookEventType, userID string, details WebhookEventDetails) ([]byte, error) {
+	siteURL := s.pluginAPI.Configuration.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		return nil, errors.New("siteURL not set")
+	}
+
+	team, err := s.pluginAPI.Team.Get(playbookRun.TeamID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get team id: %s", playbookRun.TeamID)
+	}
+
+	channel, err := s.pluginAPI.Channel.Get(playbookRun.ChannelID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get channel id: %s", playbookRun.ChannelID)
+	}
+
+	return json.Marshal(WebhookEvent{
+		Version:     WebhookEventVersion,
+		ID:          model.NewId(),
+		Type:        eventType,
+		CreateAt:    model.GetMillis(),
+		UserID:      userID,
+		PlaybookRun: playbookRun,
+		ChannelURL:  getChannelURL(*siteURL, team.Name, channel.Name),
+		DetailsURL:  getDetailsURL(*siteURL, team.Name, s.configService.GetManifest().Id, playbookRun.ID),
+		Details:     details,
+	})
+}
@@ -0,0 +1,174 @@
+package app_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	icClient "github.com/mattermost/mattermost-plugin-incident-collaboration/client"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/app"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/telemetry"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/require"
+
+	mock_app "github.com/mattermost/mattermost-plugin-incident-collaboration/server/app/mocks"
+	mock_bot "github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot/mocks"
+	mock_config "github.com/mattermost/mattermost-plugin-incident-collaboration/server/config/mocks"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/cluster"
+)
+
+func TestValidateWebhookSubscriptions(t *testing.T) {
+	for name, tc := range map[string]struct {
+		subscriptions []app.WebhookSubscription
+		wantErr       bool
+	}{
+		"no subscriptions": {},
+		"valid subscriptions": {subscriptions: []app.WebhookSubscription{
+			{Events: []app.WebhookEventType{app.WebhookEventOwnerChanged}, URLs: []string{"https://example.com/a", "http://example.com/b"}},
+			{Events: []app.WebhookEventType{app.WebhookEventOwnerChanged, app.WebhookEventParticipantJoined}, URLs: []string{"https://example.com/a"}},
+		}},
+		"no events": {subscriptions: []app.WebhookSubscription{
+			{URLs: []string{"https://example.com"}},
+		}, wantErr: true},
+		"unknown event": {subscriptions: []app.WebhookSubscription{
+			{Events: []app.WebhookEventType{"channel_renamed"}, URLs: []string{"https://example.com"}},
+		}, wantErr: true},
+		"duplicated event": {subscriptions: []app.WebhookSubscription{
+			{Events: []app.WebhookEventType{app.WebhookEventOwnerChanged, app.WebhookEventOwnerChanged}, URLs: []string{"https://example.com"}},
+		}, wantErr: true},
+		"no URLs": {subscriptions: []app.WebhookSubscription{
+			{Events: []app.WebhookEventType{app.WebhookEventOwnerChanged}},
+		}, wantErr: true},
+		"blank URL": {subscriptions: []app.WebhookSubscription{
+			{Events: []app.WebhookEventType{app.WebhookEventOwnerChanged}, URLs: []string{" "}},
+		}, wantErr: true},
+		"relative URL": {subscriptions: []app.WebhookSubscription{
+			{Events: []app.WebhookEventType{app.WebhookEventOwnerChanged}, URLs: []string{"example.com/webhook"}},
+		}, wantErr: true},
+		"unsupported protocol": {subscriptions: []app.WebhookSubscription{
+			{Events: []app.WebhookEventType{app.WebhookEventOwnerChanged}, URLs: []string{"ftp://example.com"}},
+		}, wantErr: true},
+		"duplicated URL": {subscriptions: []app.WebhookSubscription{
+			{Events: []app.WebhookEventType{app.WebhookEventOwnerChanged}, URLs: []string{"https://example.com", " https://example.com"}},
+		}, wantErr: true},
+		"too many URLs": {subscriptions: []app.WebhookSubscription{
+			{Events: []app.WebhookEventType{app.WebhookEventOwnerChanged}, URLs: func() []string {
+				var urls []string
+				for i := 0; i <= app.MaxWebhookSubscriptionURLs; i++ {
+					urls = append(urls, "https://example.com/"+strings.Repeat("a", i))
+				}
+				return urls
+			}()},
+		}, wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := app.ValidateWebhookSubscriptions(tc.subscriptions)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("URLs are trimmed", func(t *testing.T) {
+		subscriptions := []app.WebhookSubscription{
+			{Events: []app.WebhookEventType{app.WebhookEventOwnerChanged}, URLs: []string{" https://example.com\n"}},
+		}
+		require.NoError(t, app.ValidateWebhookSubscriptions(subscriptions))
+		require.Equal(t, []string{"https://example.com"}, subscriptions[0].URLs)
+	})
+}
+
+func TestWebhookSubscriptionURLs(t *testing.T) {
+	subscriptions := []app.WebhookSubscription{
+		{Events: []app.WebhookEventType{app.WebhookEventOwnerChanged}, URLs: []string{"https://example.com/a", "https://example.com/b"}},
+		{Events: []app.WebhookEventType{app.WebhookEventParticipantJoined, app.WebhookEventOwnerChanged}, URLs: []string{"https://example.com/b", "https://example.com/c"}},
+	}
+
+	require.Equal(t, []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"}, app.WebhookSubscriptionURLs(subscriptions, app.WebhookEventOwnerChanged))
+	require.Equal(t, []string{"https://example.com/b", "https://example.com/c"}, app.WebhookSubscriptionURLs(subscriptions, app.WebhookEventParticipantJoined))
+	require.Empty(t, app.WebhookSubscriptionURLs(subscriptions, app.WebhookEventParticipantLeft))
+	require.Empty(t, app.WebhookSubscriptionURLs(nil, app.WebhookEventOwnerChanged))
+}
+
+func TestWebhookEventOnChangeOwner(t *testing.T) {
+	controller := gomock.NewController(t)
+	pluginAPI := &plugintest.API{}
+	client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+	store := mock_app.NewMockPlaybookRunStore(controller)
+	poster := mock_bot.NewMockPoster(controller)
+	logger := mock_bot.NewMockLogger(controller)
+	configService := mock_config.NewMockService(controller)
+	scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+	siteURL := "http://example.com"
+	teamID := model.NewId()
+	playbookRun := &app.PlaybookRun{
+		ID:          model.NewId(),
+		TeamID:      teamID,
+		ChannelID:   "channel_id",
+		OwnerUserID: "old_owner_id",
+		WebhookSubscriptions: []app.WebhookSubscription{
+			{Events: []app.WebhookEventType{app.WebhookEventOwnerChanged}, URLs: []string{"https://example.com/a", "https://example.com/b"}},
+			{Events: []app.WebhookEventType{app.WebhookEventParticipantJoined}, URLs: []string{"https://example.com/c"}},
+		},
+	}
+
+	store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil).Times(2)
+	store.EXPECT().UpdatePlaybookRun(playbookRun).Return(nil)
+	store.EXPECT().CreateTimelineEvent(gomock.AssignableToTypeOf(&app.TimelineEvent{}))
+	poster.EXPECT().PostMessage("channel_id", gomock.Any()).Return(&model.Post{Id: model.NewId()}, nil)
+	poster.EXPECT().PublishWebsocketEventToChannel("playbook_run_updated", gomock.Any(), "channel_id")
+	configService.EXPECT().GetManifest().Return(&model.Manifest{Id: "com.mattermost.plugin-incident-management"})
+
+	var deliveries []*app.WebhookDelivery
+	store.EXPECT().CreateWebhookDelivery(gomock.AssignableToTypeOf(&app.WebhookDelivery{})).
+		DoAndReturn(func(d *app.WebhookDelivery) (*app.WebhookDelivery, error) {
+			d.ID = model.NewId()
+			deliveries = append(deliveries, d)
+			return d, nil
+		}).Times(2)
+	scheduler.EXPECT().ScheduleOnce(gomock.Any(), gomock.Any()).Return(&cluster.JobOnce{}, nil).Times(2)
+
+	pluginAPI.On("GetUser", "old_owner_id").Return(&model.User{Id: "old_owner_id", Username: "old_owner"}, nil)
+	pluginAPI.On("GetUser", "new_owner_id").Return(&model.User{Id: "new_owner_id", Username: "new_owner"}, nil)
+	pluginAPI.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Username: "user"}, nil)
+	pluginAPI.On("GetConfig").Return(&model.Config{ServiceSettings: model.ServiceSettings{SiteURL: &siteURL}})
+	pluginAPI.On("GetTeam", teamID).Return(&model.Team{Id: teamID, Name: "team_name"}, nil)
+	pluginAPI.On("GetChannel", "channel_id").Return(&model.Channel{Id: "channel_id", Name: "channel_name"}, nil)
+
+	s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, &telemetry.NoopTelemetry{})
+
+	before := model.GetMillis()
+	err := s.ChangeOwner(playbookRun.ID, "user_id", "new_owner_id")
+	require.NoError(t, err)
+
+	require.Len(t, deliveries, 2)
+	require.Equal(t, "https://example.com/a", deliveries[0].URL)
+	require.Equal(t, "https://example.com/b", deliveries[1].URL)
+	require.Equal(t, deliveries[0].Payload, deliveries[1].Payload)
+
+	var event icClient.WebhookEvent
+	for _, delivery := range deliveries {
+		require.Equal(t, app.WebhookEventOwnerChanged, delivery.EventType)
+		require.Equal(t, app.WebhookDeliveryPending, delivery.Status)
+		require.NoError(t, json.Unmarshal([]byte(delivery.Payload), &event))
+	}
+
+	require.Equal(t, icClient.WebhookEventVersion, event.Version)
+	require.Len(t, event.ID, 26)
+	require.Equal(t, icClient.WebhookEventOwnerChanged, event.Type)
+	require.GreaterOrEqual(t, event.CreateAt, before)
+	require.Equal(t, "user_id", event.UserID)
+	require.Equal(t, playbookRun.ID, event.PlaybookRun.ID)
+	require.Equal(t, "new_owner_id", event.PlaybookRun.OwnerUserID)
+	require.Equal(t, "http://example.com/team_name/channels/channel_name", event.ChannelURL)
+	require.Equal(t, "old_owner_id", event.Details.PreviousOwnerUserID)
+	require.Nil(t, event.Details.ChecklistItem)
+	require.Nil(t, event.Details.TimelineEvent)
+}
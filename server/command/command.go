@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 
@@ -24,13 +25,20 @@ const helpText = "###### Mattermost Incident Collaboration Plugin - Slash Comman
 	"* `/playbook start` - Run a playbook. \n" +
 	"* `/playbook end` - Close the playbook run in this channel. \n" +
 	"* `/playbook update` - Provide a status update. \n" +
-	"* `/playbook check [checklist #] [item #]` - check/uncheck the checklist item. \n" +
+	"* `/playbook check [checklist #] [item #] [sub-item #]` - check/uncheck the checklist item, or one of its sub-items. \n" +
+	"* `/playbook checkcomment [checklist #] [item #] [comment text]` - comment on a checklist item. \n" +
 	"* `/playbook checkadd [checklist #] [item text]` - add a checklist item. \n" +
 	"* `/playbook checkremove [checklist #] [item #]` - remove a checklist item. \n" +
 	"* `/playbook owner [@username]` - Show or change the current owner. \n" +
+	"* `/playbook severity [level]` - Show or change the current severity. \n" +
 	"* `/playbook list` - List all your playbook runs. \n" +
+	"* `/playbook tasks [due|age]` - List the open checklist items assigned to you, by due date or age. \n" +
+	"* `/playbook digest [on|off]` - Turn on or off the daily digest of your overdue runs and open checklist items. \n" +
 	"* `/playbook info` - Show a summary of the current playbook run. \n" +
 	"* `/playbook timeline` - Show the timeline for the current playbook run. \n" +
+	"* `/playbook export [playbook ID] [json|yaml]` - Export a playbook, to be imported into another team or server. \n" +
+	"* `/playbook import [export]` - Create a playbook in this team from an export. \n" +
+	"* `/playbook sync [dry-run]` - Sync the playbooks of this team from the server's playbooks directory. \n" +
 	"\n" +
 	"Learn more [in our documentation](https://mattermost.com/pl/default-incident-response-app-documentation). \n" +
 	""
@@ -52,7 +60,7 @@ func getCommand(addTestCommands bool) *model.Command {
 		DisplayName:      "Playbook",
 		Description:      "Incident Collaboration Plugin",
 		AutoComplete:     true,
-		AutoCompleteDesc: "Available commands: start, end, update, restart, check, list, owner, info",
+		AutoCompleteDesc: "Available commands: start, end, update, restart, check, list, owner, severity, info",
 		AutoCompleteHint: "[command]",
 		AutocompleteData: getAutocompleteData(addTestCommands),
 	}
@@ -60,7 +68,7 @@ func getCommand(addTestCommands bool) *model.Command {
 
 func getAutocompleteData(addTestCommands bool) *model.AutocompleteData {
 	command := model.NewAutocompleteData("playbook", "[command]",
-		"Available commands: start, end, update, restart, check, checkadd, checkremove, list, owner, info, timeline")
+		"Available commands: start, end, update, restart, check, checkadd, checkremove, checkcomment, list, owner, severity, info, timeline, export, import, sync")
 
 	start := model.NewAutocompleteData("start", "", "Starts a new playbook run")
 	command.AddCommand(start)
@@ -96,23 +104,69 @@ func getAutocompleteData(addTestCommands bool) *model.AutocompleteData {
 		"List of checklist items is loading",
 		"api/v0/runs/checklist-autocomplete-item", true)
 
+	itemComment := model.NewAutocompleteData("checkcomment", "[checklist item] [comment text]",
+		"Comment on a checklist item")
+	itemComment.AddDynamicListArgument(
+		"List of checklist items is loading",
+		"api/v0/runs/checklist-autocomplete-item", true)
+
 	command.AddCommand(itemAdd)
 	command.AddCommand(itemRemove)
+	command.AddCommand(itemComment)
 
 	list := model.NewAutocompleteData("list", "", "Lists all your playbook runs")
 	command.AddCommand(list)
 
+	tasks := model.NewAutocompleteData("tasks", "[due|age]",
+		"Lists the open checklist items assigned to you in all your playbook runs")
+	tasks.AddStaticListArgument("Sort the items by due date or by age", false, []model.AutocompleteListItem{
+		{Item: "due", HelpText: "Sort by due date, soonest first"},
+		{Item: "age", HelpText: "Sort by when the items were assigned to you, oldest first"},
+	})
+	command.AddCommand(tasks)
+
+	digest := model.NewAutocompleteData("digest", "[on|off]",
+		"Turns on or off the daily digest of your overdue runs and open checklist items")
+	digest.AddStaticListArgument("Whether to receive the daily digest", true, []model.AutocompleteListItem{
+		{Item: "on", HelpText: "Receive the digest every morning"},
+		{Item: "off", HelpText: "Stop receiving the digest"},
+	})
+	command.AddCommand(digest)
+
 	owner := model.NewAutocompleteData("owner", "[@username]",
 		"Show or change the current owner")
 	owner.AddTextArgument("The desired new owner.", "[@username]", "")
 	command.AddCommand(owner)
 
+	severity := model.NewAutocompleteData("severity", "[level]",
+		"Show or change the current severity")
+	severity.AddTextArgument("The desired new severity, or none to clear it.", "[level]", "")
+	command.AddCommand(severity)
+
 	info := model.NewAutocompleteData("info", "", "Shows a summary of the current playbook run")
 	command.AddCommand(info)
 
 	timeline := model.NewAutocompleteData("timeline", "", "Shows the timeline for the current playbook run")
 	command.AddCommand(timeline)
 
+	export := model.NewAutocompleteData("export", "[playbook ID] [json|yaml]", "Exports a playbook")
+	export.AddDynamicListArgument("List of playbooks is loading", "api/v0/playbooks/autocomplete", true)
+	export.AddStaticListArgument("Format of the export", false, []model.AutocompleteListItem{
+		{Item: string(app.PlaybookExportFormatJSON)},
+		{Item: string(app.PlaybookExportFormatYAML)},
+	})
+	command.AddCommand(export)
+
+	importCommand := model.NewAutocompleteData("import", "[export]", "Creates a playbook in this team from an export")
+	importCommand.AddTextArgument("The JSON or YAML export of a playbook.", "[export]", "")
+	command.AddCommand(importCommand)
+
+	syncCommand := model.NewAutocompleteData("sync", "[dry-run]", "Syncs the playbooks of this team from the server's playbooks directory")
+	syncCommand.AddStaticListArgument("Report the changes without making them", false, []model.AutocompleteListItem{
+		{Item: "dry-run"},
+	})
+	command.AddCommand(syncCommand)
+
 	if addTestCommands {
 		test := model.NewAutocompleteData("test", "", "Commands for testing and debugging.")
 
@@ -297,7 +351,7 @@ func (r *Runner) actionStartPlaybook(args []string) {
 }
 
 func (r *Runner) actionCheck(args []string) {
-	if len(args) != 2 {
+	if len(args) != 2 && len(args) != 3 {
 		r.postCommandResponse(helpText)
 		return
 	}
@@ -324,12 +378,46 @@ func (r *Runner) actionCheck(args []string) {
 		return
 	}
 
+	if len(args) == 3 {
+		r.actionCheckSubItem(playbookRunID, checklist, item, args[2])
+		return
+	}
+
 	err = r.playbookRunService.ToggleCheckedState(playbookRunID, r.args.UserId, checklist, item)
 	if err != nil {
 		r.warnUserAndLogErrorf("Error checking/unchecking item: %v", err)
 	}
 }
 
+func (r *Runner) actionCheckSubItem(playbookRunID string, checklist, item int, subItemArg string) {
+	subItem, err := strconv.Atoi(subItemArg)
+	if err != nil {
+		r.postCommandResponse("Error parsing the third argument. Must be a number.")
+		return
+	}
+
+	playbookRun, err := r.playbookRunService.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving playbook run: %v", err)
+		return
+	}
+
+	if !app.IsValidChecklistSubItemIndex(playbookRun.Checklists, checklist, item, subItem) {
+		r.postCommandResponse("Invalid sub-item.")
+		return
+	}
+
+	newState := app.ChecklistItemStateClosed
+	if playbookRun.Checklists[checklist].Items[item].SubItems[subItem].State == app.ChecklistItemStateClosed {
+		newState = app.ChecklistItemStateOpen
+	}
+
+	err = r.playbookRunService.ModifyChecklistSubItemState(playbookRunID, r.args.UserId, newState, checklist, item, subItem)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error checking/unchecking sub-item: %v", err)
+	}
+}
+
 func (r *Runner) actionAddChecklistItem(args []string) {
 	if len(args) < 1 {
 		r.postCommandResponse("Need to provide arguments")
@@ -405,6 +493,40 @@ func (r *Runner) actionRemoveChecklistItem(args []string) {
 	}
 }
 
+func (r *Runner) actionCommentChecklistItem(args []string) {
+	if len(args) < 3 {
+		r.postCommandResponse("Command expects three arguments: the checklist number, the item number and the comment.")
+		return
+	}
+
+	checklist, err := strconv.Atoi(args[0])
+	if err != nil {
+		r.postCommandResponse("Error parsing the first argument. Must be a number.")
+		return
+	}
+
+	item, err := strconv.Atoi(args[1])
+	if err != nil {
+		r.postCommandResponse("Error parsing the second argument. Must be a number.")
+		return
+	}
+
+	playbookRunID, err := r.playbookRunService.GetPlaybookRunIDForChannel(r.args.ChannelId)
+	if err != nil {
+		if errors.Is(err, app.ErrNotFound) {
+			r.postCommandResponse("This command only works when run from a playbook run channel.")
+			return
+		}
+		r.warnUserAndLogErrorf("Error retrieving playbook run: %v", err)
+		return
+	}
+
+	message := strings.Join(args[2:], " ")
+	if _, err = r.playbookRunService.AddChecklistItemComment(playbookRunID, r.args.UserId, checklist, item, message); err != nil {
+		r.warnUserAndLogErrorf("Error commenting on item: %v", err)
+	}
+}
+
 func (r *Runner) actionOwner(args []string) {
 	switch len(args) {
 	case 0:
@@ -489,6 +611,53 @@ func (r *Runner) actionChangeOwner(args []string) {
 	}
 }
 
+func (r *Runner) actionSeverity(args []string) {
+	playbookRunID, err := r.playbookRunService.GetPlaybookRunIDForChannel(r.args.ChannelId)
+	if errors.Is(err, app.ErrNotFound) {
+		r.postCommandResponse("This command only works when run from a playbook run channel.")
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving playbook run for channel %s: %v", r.args.ChannelId, err)
+		return
+	}
+
+	currentPlaybookRun, err := r.playbookRunService.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving playbook run: %v", err)
+		return
+	}
+
+	if len(currentPlaybookRun.SeverityLevels) == 0 {
+		r.postCommandResponse("This playbook run has no severity levels. They can be configured in the playbook it was started from.")
+		return
+	}
+
+	levels := strings.Join(currentPlaybookRun.SeverityLevels, ", ")
+
+	if len(args) == 0 {
+		if currentPlaybookRun.Severity == "" {
+			r.postCommandResponse(fmt.Sprintf("This playbook run has no severity. Available levels: %s.", levels))
+			return
+		}
+		r.postCommandResponse(fmt.Sprintf("**%s** is the current severity for this playbook run. Available levels: %s.",
+			currentPlaybookRun.Severity, levels))
+		return
+	}
+
+	severity := strings.Join(args, " ")
+	if strings.EqualFold(severity, "none") {
+		severity = ""
+	} else if app.FindSeverityLevel(currentPlaybookRun.SeverityLevels, severity) == "" {
+		r.postCommandResponse(fmt.Sprintf("Unknown severity %s. Available levels: %s.", severity, levels))
+		return
+	}
+
+	if err = r.playbookRunService.ChangeSeverity(currentPlaybookRun.ID, r.args.UserId, severity); err != nil {
+		r.warnUserAndLogErrorf("Failed to change severity to %s: %v", severity, err)
+		return
+	}
+}
+
 func (r *Runner) actionList() {
 	team, err := r.pluginAPI.Team.Get(r.args.TeamId)
 	if err != nil {
@@ -515,13 +684,13 @@ func (r *Runner) actionList() {
 	}
 
 	options := app.PlaybookRunFilterOptions{
-		TeamID:    r.args.TeamId,
-		MemberID:  r.args.UserId,
-		Page:      0,
-		PerPage:   maxPlaybookRunsToList,
-		Sort:      app.SortByCreateAt,
-		Direction: app.DirectionDesc,
-		Statuses:  []string{app.StatusReported, app.StatusActive, app.StatusResolved},
+		TeamID:        r.args.TeamId,
+		MemberID:      r.args.UserId,
+		Page:          0,
+		PerPage:       maxPlaybookRunsToList,
+		Sort:          app.SortByCreateAt,
+		Direction:     app.DirectionDesc,
+		ExcludeClosed: true,
 	}
 
 	result, err := r.playbookRunService.GetPlaybookRuns(requesterInfo, options)
@@ -568,6 +737,94 @@ func (r *Runner) actionList() {
 	r.poster.EphemeralPost(r.args.UserId, r.args.ChannelId, post)
 }
 
+func (r *Runner) actionTasks(args []string) {
+	sort := app.SortByDueAt
+	if len(args) > 0 {
+		switch args[0] {
+		case "due":
+		case "age":
+			sort = app.SortByAssignedAt
+		default:
+			r.postCommandResponse("Command expects an optional sort of due or age, e.g. `/playbook tasks age`.")
+			return
+		}
+	}
+
+	requesterInfo, err := app.GetRequesterInfo(r.args.UserId, r.pluginAPI)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error resolving permissions: %v", err)
+		return
+	}
+
+	options, err := app.ChecklistItemFilterOptions{
+		AssigneeID:     r.args.UserId,
+		States:         []string{app.ChecklistItemStateOpen, app.ChecklistItemStateInProgress},
+		ActiveRunsOnly: true,
+		Sort:           sort,
+		Direction:      app.DirectionAsc,
+	}.Validate()
+	if err != nil {
+		r.warnUserAndLogErrorf("Error validating the options: %v", err)
+		return
+	}
+
+	items, err := r.playbookRunService.GetChecklistItems(requesterInfo, options)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving your tasks: %v", err)
+		return
+	}
+
+	if len(items) == 0 {
+		r.postCommandResponse("You have no open checklist items assigned to you.")
+		return
+	}
+
+	message := "###### Your open checklist items\n"
+	now := model.GetMillis()
+	for _, item := range items {
+		icon := ":white_large_square: "
+		if item.State == app.ChecklistItemStateInProgress {
+			icon = ":hourglass_flowing_sand: "
+		}
+		timestamp := ""
+		switch {
+		case item.IsOverdue(now):
+			icon = ":red_circle: "
+			timestamp = " **(overdue since " + timeutils.GetTimeForMillis(item.DueAt).Format("Jan 2 15:04") + ")**"
+		case item.DueAt != 0:
+			timestamp = " (due " + timeutils.GetTimeForMillis(item.DueAt).Format("Jan 2 15:04") + ")"
+		}
+
+		age := ""
+		if item.AssigneeModified != 0 {
+			age = ", assigned " + timeutils.DurationString(timeutils.GetTimeForMillis(item.AssigneeModified), timeutils.GetTimeForMillis(now)) + " ago"
+		}
+
+		message += fmt.Sprintf("%s%s%s in ~%s%s\n", icon, item.Title, timestamp, item.ChannelName, age)
+	}
+
+	r.poster.EphemeralPost(r.args.UserId, r.args.ChannelId, &model.Post{Message: message})
+}
+
+func (r *Runner) actionDigest(args []string) {
+	if len(args) != 1 || (args[0] != "on" && args[0] != "off") {
+		r.postCommandResponse("Command expects one argument, on or off, e.g. `/playbook digest on`.")
+		return
+	}
+
+	enabled := args[0] == "on"
+	if err := r.playbookRunService.SetDigestEnabled(r.args.UserId, enabled); err != nil {
+		r.warnUserAndLogErrorf("Error updating your digest settings: %v", err)
+		return
+	}
+
+	if enabled {
+		r.postCommandResponse(fmt.Sprintf("You will receive a daily digest of your overdue runs and open checklist items at %d:00 in your timezone.", app.DigestHour))
+		return
+	}
+	r.postCommandResponse("You will no longer receive the daily digest.")
+}
+
 func (r *Runner) actionInfo() {
 	playbookRunID, err := r.playbookRunService.GetPlaybookRunIDForChannel(r.args.ChannelId)
 	if errors.Is(err, app.ErrNotFound) {
@@ -603,25 +860,52 @@ func (r *Runner) actionInfo() {
 	}
 
 	tasks := ""
+	now := model.GetMillis()
 	for _, checklist := range playbookRun.Checklists {
 		for _, item := range checklist.Items {
 			icon := ":white_large_square: "
 			timestamp := ""
-			if item.State == app.ChecklistItemStateClosed {
+			switch {
+			case item.State == app.ChecklistItemStateClosed:
 				icon = ":white_check_mark: "
 				timestamp = " (" + timeutils.GetTimeForMillis(item.StateModified).Format("15:04 PM") + ")"
+			case item.State == app.ChecklistItemStateSkipped:
+				icon = ":fast_forward: "
+				timestamp = " (skipped: " + item.SkipReason + ")"
+			case item.IsOverdue(now):
+				icon = ":red_circle: "
+				timestamp = " **(overdue since " + timeutils.GetTimeForMillis(item.DueAt).Format("15:04 PM") + ")**"
+			case item.DueAt != 0:
+				timestamp = " (due " + timeutils.GetTimeForMillis(item.DueAt).Format("15:04 PM") + ")"
+			}
+
+			comments := ""
+			if len(item.Comments) > 0 {
+				comments = fmt.Sprintf(" :speech_balloon: %d", len(item.Comments))
 			}
 
-			tasks += icon + item.Title + timestamp + "\n"
+			tasks += icon + item.Title + timestamp + comments + "\n"
+			for _, subItem := range item.SubItems {
+				subIcon := ":white_large_square: "
+				if subItem.State == app.ChecklistItemStateClosed {
+					subIcon = ":white_check_mark: "
+				}
+				tasks += "    " + subIcon + subItem.Title + "\n"
+			}
 		}
 	}
+	fields := []*model.SlackAttachmentField{
+		{Title: "Name:", Value: fmt.Sprintf("**%s**", strings.Trim(playbookRun.Name, " "))},
+		{Title: "Duration:", Value: timeutils.DurationString(timeutils.GetTimeForMillis(playbookRun.CreateAt), time.Now())},
+		{Title: "Owner:", Value: fmt.Sprintf("@%s", owner.Username)},
+	}
+	if playbookRun.Severity != "" {
+		fields = append(fields, &model.SlackAttachmentField{Title: "Severity:", Value: playbookRun.Severity})
+	}
+	fields = append(fields, &model.SlackAttachmentField{Title: "Tasks:", Value: tasks})
+
 	attachment := &model.SlackAttachment{
-		Fields: []*model.SlackAttachmentField{
-			{Title: "Name:", Value: fmt.Sprintf("**%s**", strings.Trim(playbookRun.Name, " "))},
-			{Title: "Duration:", Value: timeutils.DurationString(timeutils.GetTimeForMillis(playbookRun.CreateAt), time.Now())},
-			{Title: "Owner:", Value: fmt.Sprintf("@%s", owner.Username)},
-			{Title: "Tasks:", Value: tasks},
-		},
+		Fields: fields,
 	}
 
 	post := &model.Post{
@@ -758,6 +1042,190 @@ func (r *Runner) actionTimeline() {
 	r.poster.EphemeralPost(r.args.UserId, r.args.ChannelId, &model.Post{Message: message})
 }
 
+func (r *Runner) actionExport(args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		r.postCommandResponse("Usage: `/playbook export <playbook ID> [json|yaml]`")
+		return
+	}
+
+	formatName := ""
+	if len(args) == 2 {
+		formatName = args[1]
+	}
+	format, err := app.ParsePlaybookExportFormat(formatName)
+	if err != nil {
+		r.postCommandResponse(err.Error())
+		return
+	}
+
+	playbook, err := r.playbookService.Get(args[0])
+	if errors.Is(err, app.ErrNotFound) {
+		r.postCommandResponse(fmt.Sprintf("Playbook %s not found.", args[0]))
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving playbook: %v", err)
+		return
+	}
+
+	if err = app.PlaybookAccess(r.args.UserId, playbook, r.pluginAPI); err != nil {
+		r.postCommandResponse("You do not have access to this playbook.")
+		return
+	}
+
+	data, err := app.MarshalPlaybookExport(r.playbookService.Export(playbook), format)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error exporting playbook: %v", err)
+		return
+	}
+
+	message := fmt.Sprintf("```%s\n%s\n```", format, strings.TrimSuffix(string(data), "\n"))
+	if utf8.RuneCountInString(message) > model.POST_MESSAGE_MAX_RUNES_V2 {
+		r.postCommandResponse(fmt.Sprintf("The export of this playbook is too long for a message. Download it from `/plugins/%s/api/v0/playbooks/%s/export?format=%s` instead.", r.configService.GetManifest().Id, playbook.ID, format))
+		return
+	}
+
+	r.postCommandResponse(message)
+}
+
+// actionImport creates a playbook from the export following the command, which may span several
+// lines and be wrapped in a code block.
+func (r *Runner) actionImport() {
+	text := strings.TrimSpace(r.args.Command)
+	text = strings.TrimSpace(strings.TrimPrefix(text, "/playbook"))
+	text = strings.TrimSpace(strings.TrimPrefix(text, "import"))
+
+	if strings.HasPrefix(text, "```") {
+		// Drop the opening fence, with its language, and the closing one.
+		if i := strings.Index(text, "\n"); i >= 0 {
+			text = text[i+1:]
+		} else {
+			text = ""
+		}
+		text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+	}
+
+	if text == "" {
+		r.postCommandResponse("Usage: `/playbook import <export>`, with the JSON or YAML export of a playbook.")
+		return
+	}
+
+	format := app.PlaybookExportFormatYAML
+	if strings.HasPrefix(text, "{") {
+		format = app.PlaybookExportFormatJSON
+	}
+
+	export, err := app.UnmarshalPlaybookExport([]byte(text), format)
+	if err != nil {
+		r.postCommandResponse(fmt.Sprintf("Unable to read the playbook export: %s", err.Error()))
+		return
+	}
+
+	result, err := r.playbookService.Import(export, r.args.TeamId, r.args.UserId)
+	if errors.Is(err, app.ErrPermission) {
+		r.postCommandResponse("You do not have permission to create this playbook in this team.")
+		return
+	} else if errors.Is(err, app.ErrMalformedPlaybook) {
+		r.postCommandResponse(fmt.Sprintf("The playbook export is not valid: %s", err.Error()))
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error importing playbook: %v", err)
+		return
+	}
+
+	team, err := r.pluginAPI.Team.Get(r.args.TeamId)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving team: %v", err)
+		return
+	}
+
+	url := fmt.Sprintf("/%s/%s/playbooks/%s", team.Name, r.configService.GetManifest().Id, result.ID)
+	message := fmt.Sprintf("Playbook [%s](%s) created.", export.Title, url)
+	if len(result.Warnings) > 0 {
+		message += " Some of its settings could not be carried over:\n"
+		for _, warning := range result.Warnings {
+			message += fmt.Sprintf("- %s\n", warning)
+		}
+	}
+
+	r.postCommandResponse(message)
+}
+
+// actionSync syncs the playbooks of the team from its directory in the configured sync directory.
+func (r *Runner) actionSync(args []string) {
+	if !app.IsAdmin(r.args.UserId, r.pluginAPI) {
+		r.postCommandResponse("Syncing playbooks is restricted to system administrators.")
+		return
+	}
+
+	dryRun := len(args) > 0 && args[0] == "dry-run"
+	if len(args) > 1 || (len(args) == 1 && !dryRun) {
+		r.postCommandResponse("Usage: `/playbook sync [dry-run]`")
+		return
+	}
+
+	result, err := r.playbookService.SyncFromDirectory(r.args.TeamId, r.args.UserId, dryRun)
+	if errors.Is(err, app.ErrPlaybookSyncNotConfigured) {
+		r.postCommandResponse("No playbooks directory is configured. Set it in the plugin's settings to sync playbooks.")
+		return
+	} else if errors.Is(err, app.ErrNotFound) || errors.Is(err, app.ErrMalformedPlaybook) || errors.Is(err, app.ErrPermission) {
+		r.postCommandResponse(fmt.Sprintf("Unable to sync playbooks: %s", err.Error()))
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error syncing playbooks: %v", err)
+		return
+	}
+
+	r.postCommandResponse(syncResultMessage(result))
+}
+
+// syncResultMessage lists the playbooks changed by a sync, with a summary of the changes.
+func syncResultMessage(result app.PlaybookSyncResult) string {
+	message := "Playbooks synced:\n"
+	verbs := map[app.PlaybookSyncAction]string{
+		app.PlaybookSyncActionCreate: "Created",
+		app.PlaybookSyncActionUpdate: "Updated",
+		app.PlaybookSyncActionDelete: "Deleted",
+	}
+	if result.DryRun {
+		message = "Dry run, no playbook was changed:\n"
+		verbs = map[app.PlaybookSyncAction]string{
+			app.PlaybookSyncActionCreate: "Would create",
+			app.PlaybookSyncActionUpdate: "Would update",
+			app.PlaybookSyncActionDelete: "Would delete",
+		}
+	}
+
+	unchanged := 0
+	for _, playbook := range result.Playbooks {
+		switch playbook.Action {
+		case app.PlaybookSyncActionCreate:
+			message += fmt.Sprintf("- %s **%s** from `%s`\n", verbs[playbook.Action], playbook.Title, playbook.Path)
+		case app.PlaybookSyncActionUpdate:
+			fields := []string{}
+			for _, change := range playbook.Changes {
+				fields = append(fields, change.Field)
+			}
+			message += fmt.Sprintf("- %s **%s** from `%s`: %s\n", verbs[playbook.Action], playbook.Title, playbook.Path, strings.Join(fields, ", "))
+		case app.PlaybookSyncActionDelete:
+			message += fmt.Sprintf("- %s **%s**, as `%s` was removed\n", verbs[playbook.Action], playbook.Title, playbook.Path)
+		default:
+			unchanged++
+		}
+
+		for _, warning := range playbook.Warnings {
+			message += fmt.Sprintf("  - `%s`: %s\n", playbook.Path, warning)
+		}
+		if playbook.InboundWebhookToken != "" {
+			message += fmt.Sprintf("  - Inbound webhook token, shown only once: `%s`\n", playbook.InboundWebhookToken)
+		}
+	}
+
+	created, updated, deleted := result.Counts()
+	message += fmt.Sprintf("\n%d created, %d updated, %d deleted, %d unchanged.", created, updated, deleted, unchanged)
+
+	return message
+}
+
 func (r *Runner) summaryMessage(event app.TimelineEvent) string {
 	var username string
 	user, err := r.pluginAPI.User.Get(event.SubjectUserID)
@@ -785,6 +1253,10 @@ func (r *Runner) summaryMessage(event app.TimelineEvent) string {
 		return "@" + username + " published retrospective"
 	case app.CanceledRetrospective:
 		return "@" + username + " canceled retrospective"
+	case app.RunUpdated:
+		return "@" + username + " " + event.Summary
+	case app.SeverityChanged:
+		return "@" + username + " changed severity from " + event.Summary
 	default:
 		return event.Summary
 	}
@@ -999,12 +1471,12 @@ And... yes, of course, we have emojis
 		return
 	}
 
-	if err := r.playbookRunService.ModifyCheckedState(playbookRun.ID, r.args.UserId, app.ChecklistItemStateClosed, 0, 0); err != nil {
+	if err := r.playbookRunService.ModifyCheckedState(playbookRun.ID, r.args.UserId, app.ChecklistItemStateClosed, 0, 0, false); err != nil {
 		r.postCommandResponse("Unable to modify checked state: " + err.Error())
 		return
 	}
 
-	if err := r.playbookRunService.ModifyCheckedState(playbookRun.ID, r.args.UserId, app.ChecklistItemStateOpen, 0, 2); err != nil {
+	if err := r.playbookRunService.ModifyCheckedState(playbookRun.ID, r.args.UserId, app.ChecklistItemStateOpen, 0, 2, false); err != nil {
 		r.postCommandResponse("Unable to modify checked state: " + err.Error())
 		return
 	}
@@ -1777,18 +2249,32 @@ func (r *Runner) Execute() error {
 		r.actionAddChecklistItem(parameters)
 	case "checkremove":
 		r.actionRemoveChecklistItem(parameters)
+	case "checkcomment":
+		r.actionCommentChecklistItem(parameters)
 	case "restart":
 		r.actionRestart()
 	case "owner":
 		r.actionOwner(parameters)
+	case "severity":
+		r.actionSeverity(parameters)
 	case "list":
 		r.actionList()
+	case "tasks":
+		r.actionTasks(parameters)
+	case "digest":
+		r.actionDigest(parameters)
 	case "info":
 		r.actionInfo()
 	case "add":
 		r.actionAdd(parameters)
 	case "timeline":
 		r.actionTimeline()
+	case "export":
+		r.actionExport(parameters)
+	case "import":
+		r.actionImport()
+	case "sync":
+		r.actionSync(parameters)
 	case "nuke-db":
 		r.actionNukeDB(parameters)
 	case "test":
@@ -21,6 +21,10 @@ type Configuration struct {
 	// EnableExperimentalFeatures determines if experimental features are enabled.
 	EnableExperimentalFeatures bool
 
+	// PlaybooksSyncDirectory is the server directory playbooks are synced from, with one
+	// subdirectory of playbook files per team, named after the team.
+	PlaybooksSyncDirectory string
+
 	// ** The following are NOT stored on the server
 	// AdminUserIDs contains a list of user IDs that are allowed
 	// to administer plugin functions, even if not Mattermost sysadmins.
@@ -175,6 +175,7 @@ func (p *Plugin) OnActivate() error {
 		p.bot,
 		p.config,
 	)
+	api.NewInboundWebhookHandler(p.handler.WebhooksRouter, p.playbookRunService, p.playbookService, p.bot, p.config)
 	api.NewStatsHandler(p.handler.APIRouter, pluginAPIClient, p.bot, statsStore, p.playbookService)
 	api.NewBotHandler(p.handler.APIRouter, pluginAPIClient, p.bot, p.bot, p.config)
 	api.NewTelemetryHandler(p.handler.APIRouter, p.playbookRunService, pluginAPIClient, p.bot, telemetryClient, p.playbookService, telemetryClient, telemetryClient)
@@ -0,0 +1,312 @@
+package sqlstore
+
+import (
+	"encoding/json"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/app"
+	"github.com/pkg/errors"
+)
+
+// sqlChecklist is a row of IR_Checklist, a checklist of a playbook run.
+type sqlChecklist struct {
+	IncidentID string
+	ID         string
+	Position   int
+	Title      string
+}
+
+// sqlChecklistItem is a row of IR_ChecklistItem, an item of a checklist of a playbook run.
+type sqlChecklistItem struct {
+	app.ChecklistItem
+	IncidentID            string
+	ChecklistID           string
+	Position              int
+	ConcatenatedDependsOn string
+	SubItemsJSON          string
+	CommentsJSON          string
+}
+
+// sqlPlaybookRunChecklistItem is a row of IR_ChecklistItem joined with its checklist.
+type sqlPlaybookRunChecklistItem struct {
+	sqlChecklistItem
+	ChecklistPosition int
+	ChecklistTitle    string
+}
+
+// sqlChecklistItemWithRun is a row of IR_ChecklistItem joined with its checklist and playbook run.
+type sqlChecklistItemWithRun struct {
+	sqlPlaybookRunChecklistItem
+	PlaybookRunName string
+	TeamID          string
+	ChannelID       string
+	ChannelName     string
+}
+
+// checklistItemColumns are the columns of IR_ChecklistItem, in the order toSQLChecklistItemValues
+// returns their values.
+var checklistItemColumns = []string{
+	"IncidentID", "ChecklistID", "ID", "Position", "Title", "State", "StateModified", "StateModifiedPostID",
+	"AssigneeID", "AssigneeModified", "AssigneeModifiedPostID", "Command", "CommandLastRun", "Description",
+	"SkipReason", "DueAt", "DueAfterSeconds", "DueRelativeTo", "ConcatenatedDependsOn", "CommandTrigger",
+	"CommandTriggerStatus", "CommandRunAs", "CommandTriggeredAt", "SubItemsJSON", "CommentsJSON", "CommentsPostID",
+}
+
+func (s *playbookRunStore) checklistItemsSelect() sq.SelectBuilder {
+	columns := make([]string, 0, len(checklistItemColumns)+2)
+	for _, column := range checklistItemColumns {
+		columns = append(columns, "ci."+column)
+	}
+	columns = append(columns, "cl.Position AS ChecklistPosition", "cl.Title AS ChecklistTitle")
+
+	return s.queryBuilder.
+		Select(columns...).
+		From("IR_ChecklistItem AS ci").
+		Join("IR_Checklist AS cl ON (cl.IncidentID = ci.IncidentID AND cl.ID = ci.ChecklistID)")
+}
+
+// GetChecklistItems returns the checklist items selected by options, of the playbook runs the
+// requester can view.
+func (s *playbookRunStore) GetChecklistItems(requesterInfo app.RequesterInfo, options app.ChecklistItemFilterOptions) ([]app.PlaybookRunChecklistItem, error) {
+	query := s.checklistItemsSelect().
+		Columns("c.DisplayName AS PlaybookRunName", "i.TeamID", "i.ChannelID", "COALESCE(c.Name, '') AS ChannelName").
+		Join("IR_Incident AS i ON (i.ID = ci.IncidentID)").
+		Join("Channels AS c ON (c.Id = i.ChannelId)").
+		Where(s.buildPermissionsExpr(requesterInfo)).
+		Where(buildTeamLimitExpr(requesterInfo.UserID, options.TeamID))
+
+	if len(options.PlaybookRunIDs) != 0 {
+		query = query.Where(sq.Eq{"ci.IncidentID": options.PlaybookRunIDs})
+	}
+
+	if options.AssigneeID != "" {
+		query = query.Where(sq.Eq{"ci.AssigneeID": options.AssigneeID})
+	}
+
+	if len(options.States) != 0 {
+		query = query.Where(sq.Eq{"ci.State": options.States})
+	}
+
+	if options.ActiveRunsOnly {
+		query = query.Where(sq.Eq{"i.CurrentStatusResolved": false})
+	}
+
+	direction := "ASC"
+	if options.Direction == app.DirectionDesc {
+		direction = "DESC"
+	}
+
+	switch options.Sort {
+	case app.SortByDueAt, "":
+		query = query.OrderBy("CASE WHEN ci.DueAt = 0 THEN 1 ELSE 0 END", "ci.DueAt "+direction)
+	case app.SortByAssignedAt:
+		query = query.OrderBy("ci.AssigneeModified " + direction)
+	default:
+		return nil, errors.Errorf("unsupported sort parameter '%s'", options.Sort)
+	}
+	query = query.OrderBy("i.CreateAt", "ci.IncidentID", "cl.Position", "ci.Position")
+
+	var rawItems []sqlChecklistItemWithRun
+	if err := s.store.selectBuilder(s.store.db, &rawItems, query); err != nil {
+		return nil, errors.Wrap(err, "failed to get checklist items")
+	}
+
+	items := make([]app.PlaybookRunChecklistItem, 0, len(rawItems))
+	for _, rawItem := range rawItems {
+		item, err := toChecklistItem(rawItem.sqlChecklistItem)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, app.PlaybookRunChecklistItem{
+			ChecklistItem:   item,
+			PlaybookRunID:   rawItem.IncidentID,
+			PlaybookRunName: rawItem.PlaybookRunName,
+			TeamID:          rawItem.TeamID,
+			ChannelID:       rawItem.ChannelID,
+			ChannelName:     rawItem.ChannelName,
+			ChecklistNum:    rawItem.ChecklistPosition,
+			ChecklistTitle:  rawItem.ChecklistTitle,
+			ItemNum:         rawItem.Position,
+		})
+	}
+
+	return items, nil
+}
+
+// getChecklistsForPlaybookRuns returns the checklists of the given playbook runs, by playbook run ID.
+func (s *playbookRunStore) getChecklistsForPlaybookRuns(q sqlx.Queryer, playbookRunIDs []string) (map[string][]app.Checklist, error) {
+	var rawChecklists []sqlChecklist
+	err := s.store.selectBuilder(q, &rawChecklists, s.queryBuilder.
+		Select("cl.IncidentID", "cl.ID", "cl.Position", "cl.Title").
+		From("IR_Checklist AS cl").
+		Where(sq.Eq{"cl.IncidentID": playbookRunIDs}).
+		OrderBy("cl.IncidentID", "cl.Position"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get checklists")
+	}
+
+	var rawItems []sqlPlaybookRunChecklistItem
+	err = s.store.selectBuilder(q, &rawItems, s.checklistItemsSelect().
+		Where(sq.Eq{"ci.IncidentID": playbookRunIDs}).
+		OrderBy("ci.IncidentID", "cl.Position", "ci.Position"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get checklist items")
+	}
+
+	checklists := make(map[string][]app.Checklist)
+	for _, rawChecklist := range rawChecklists {
+		checklists[rawChecklist.IncidentID] = append(checklists[rawChecklist.IncidentID], app.Checklist{
+			ID:    rawChecklist.ID,
+			Title: rawChecklist.Title,
+		})
+	}
+
+	for _, rawItem := range rawItems {
+		var item app.ChecklistItem
+		item, err = toChecklistItem(rawItem.sqlChecklistItem)
+		if err != nil {
+			return nil, err
+		}
+
+		runChecklists := checklists[rawItem.IncidentID]
+		if rawItem.ChecklistPosition < 0 || rawItem.ChecklistPosition >= len(runChecklists) {
+			return nil, errors.Errorf("checklist item '%s' of playbook run '%s' is in a missing checklist", rawItem.ID, rawItem.IncidentID)
+		}
+		runChecklists[rawItem.ChecklistPosition].Items = append(runChecklists[rawItem.ChecklistPosition].Items, item)
+	}
+
+	return checklists, nil
+}
+
+// replaceChecklists replaces the checklists of a playbook run with the given ones.
+func (s *playbookRunStore) replaceChecklists(e execer, playbookRunID string, checklists []app.Checklist) error {
+	if _, err := s.store.execBuilder(e, sq.
+		Delete("IR_ChecklistItem").
+		Where(sq.Eq{"IncidentID": playbookRunID})); err != nil {
+		return errors.Wrapf(err, "failed to delete checklist items for playbook run with id '%s'", playbookRunID)
+	}
+
+	if _, err := s.store.execBuilder(e, sq.
+		Delete("IR_Checklist").
+		Where(sq.Eq{"IncidentID": playbookRunID})); err != nil {
+		return errors.Wrapf(err, "failed to delete checklists for playbook run with id '%s'", playbookRunID)
+	}
+
+	return insertChecklists(s.store, e, playbookRunID, checklists)
+}
+
+// insertChecklists stores the checklists of a playbook run, whose checklists and items must all
+// have IDs unique in the run, see populateChecklistIDs.
+func insertChecklists(sqlStore *SQLStore, e execer, playbookRunID string, checklists []app.Checklist) error {
+	if len(checklists) == 0 {
+		return nil
+	}
+
+	checklistsInsert := sq.
+		Insert("IR_Checklist").
+		Columns("IncidentID", "ID", "Position", "Title")
+	itemsInsert := sq.
+		Insert("IR_ChecklistItem").
+		Columns(checklistItemColumns...)
+	numItems := 0
+	for i, checklist := range checklists {
+		checklistsInsert = checklistsInsert.Values(playbookRunID, checklist.ID, i, checklist.Title)
+
+		for j, item := range checklist.Items {
+			values, err := toSQLChecklistItemValues(playbookRunID, checklist.ID, j, item)
+			if err != nil {
+				return err
+			}
+			itemsInsert = itemsInsert.Values(values...)
+			numItems++
+		}
+	}
+
+	if _, err := sqlStore.execBuilder(e, checklistsInsert); err != nil {
+		return errors.Wrapf(err, "failed to store checklists for playbook run with id '%s'", playbookRunID)
+	}
+
+	if numItems == 0 {
+		return nil
+	}
+
+	if _, err := sqlStore.execBuilder(e, itemsInsert); err != nil {
+		return errors.Wrapf(err, "failed to store checklist items for playbook run with id '%s'", playbookRunID)
+	}
+
+	return nil
+}
+
+// toSQLChecklistItemValues returns the values of the checklistItemColumns for the item.
+func toSQLChecklistItemValues(playbookRunID, checklistID string, position int, item app.ChecklistItem) ([]interface{}, error) {
+	subItemsJSON, err := subItemsToJSON(item.SubItems)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal sub-items json for checklist item id: '%s'", item.ID)
+	}
+
+	commentsJSON, err := commentsToJSON(item.Comments)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal comments json for checklist item id: '%s'", item.ID)
+	}
+
+	return []interface{}{
+		playbookRunID, checklistID, item.ID, position, item.Title, item.State, item.StateModified, item.StateModifiedPostID,
+		item.AssigneeID, item.AssigneeModified, item.AssigneeModifiedPostID, item.Command, item.CommandLastRun, item.Description,
+		item.SkipReason, item.DueAt, item.DueAfterSeconds, item.DueRelativeTo, strings.Join(item.DependsOn, ","), item.CommandTrigger,
+		item.CommandTriggerStatus, item.CommandRunAs, item.CommandTriggeredAt, subItemsJSON, commentsJSON, item.CommentsPostID,
+	}, nil
+}
+
+func toChecklistItem(rawItem sqlChecklistItem) (app.ChecklistItem, error) {
+	item := rawItem.ChecklistItem
+
+	item.DependsOn = []string(nil)
+	if rawItem.ConcatenatedDependsOn != "" {
+		item.DependsOn = strings.Split(rawItem.ConcatenatedDependsOn, ",")
+	}
+
+	if rawItem.SubItemsJSON != "" {
+		if err := json.Unmarshal([]byte(rawItem.SubItemsJSON), &item.SubItems); err != nil {
+			return app.ChecklistItem{}, errors.Wrapf(err, "failed to unmarshal sub-items json for checklist item id: '%s'", rawItem.ID)
+		}
+	}
+
+	if rawItem.CommentsJSON != "" {
+		if err := json.Unmarshal([]byte(rawItem.CommentsJSON), &item.Comments); err != nil {
+			return app.ChecklistItem{}, errors.Wrapf(err, "failed to unmarshal comments json for checklist item id: '%s'", rawItem.ID)
+		}
+	}
+
+	return item, nil
+}
+
+// subItemsToJSON marshals the given sub-items, storing no sub-items as an empty string.
+func subItemsToJSON(subItems []app.ChecklistSubItem) (string, error) {
+	if len(subItems) == 0 {
+		return "", nil
+	}
+
+	subItemsJSON, err := json.Marshal(subItems)
+	if err != nil {
+		return "", err
+	}
+
+	return string(subItemsJSON), nil
+}
+
+// commentsToJSON marshals the given comments, storing no comments as an empty string.
+func commentsToJSON(comments []app.ChecklistItemComment) (string, error) {
+	if len(comments) == 0 {
+		return "", nil
+	}
+
+	commentsJSON, err := json.Marshal(comments)
+	if err != nil {
+		return "", err
+	}
+
+	return string(commentsJSON), nil
+}
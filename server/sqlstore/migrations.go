@@ -980,4 +980,696 @@ var migrations = []Migration{
 			return nil
 		},
 	},
+	{
+		fromVersion: semver.MustParse("0.24.0"),
+		toVersion:   semver.MustParse("0.25.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "ConcatenatedSeverityLevels", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column ConcatenatedSeverityLevels to table IR_Playbook")
+				}
+				if _, err := e.Exec("UPDATE IR_Playbook SET ConcatenatedSeverityLevels = '' WHERE ConcatenatedSeverityLevels IS NULL"); err != nil {
+					return errors.Wrapf(err, "failed setting default value in column ConcatenatedSeverityLevels of table IR_Playbook")
+				}
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "DefaultSeverity", "VARCHAR(64) DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column DefaultSeverity to table IR_Playbook")
+				}
+
+				if err := addColumnToMySQLTable(e, "IR_Incident", "ConcatenatedSeverityLevels", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column ConcatenatedSeverityLevels to table IR_Incident")
+				}
+				if _, err := e.Exec("UPDATE IR_Incident SET ConcatenatedSeverityLevels = '' WHERE ConcatenatedSeverityLevels IS NULL"); err != nil {
+					return errors.Wrapf(err, "failed setting default value in column ConcatenatedSeverityLevels of table IR_Incident")
+				}
+				if err := addColumnToMySQLTable(e, "IR_Incident", "Severity", "VARCHAR(64) DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column Severity to table IR_Incident")
+				}
+				if err := addColumnToMySQLTable(e, "IR_Incident", "SeverityRank", "INT DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column SeverityRank to table IR_Incident")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Playbook", "ConcatenatedSeverityLevels", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column ConcatenatedSeverityLevels to table IR_Playbook")
+				}
+				if err := addColumnToPGTable(e, "IR_Playbook", "DefaultSeverity", "VARCHAR(64) DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column DefaultSeverity to table IR_Playbook")
+				}
+
+				if err := addColumnToPGTable(e, "IR_Incident", "ConcatenatedSeverityLevels", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column ConcatenatedSeverityLevels to table IR_Incident")
+				}
+				if err := addColumnToPGTable(e, "IR_Incident", "Severity", "VARCHAR(64) DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column Severity to table IR_Incident")
+				}
+				if err := addColumnToPGTable(e, "IR_Incident", "SeverityRank", "INT DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column SeverityRank to table IR_Incident")
+				}
+			}
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.25.0"),
+		toVersion:   semver.MustParse("0.26.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "StatusWorkflowJSON", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column StatusWorkflowJSON to table IR_Playbook")
+				}
+				if _, err := e.Exec("UPDATE IR_Playbook SET StatusWorkflowJSON = '' WHERE StatusWorkflowJSON IS NULL"); err != nil {
+					return errors.Wrapf(err, "failed setting default value in column StatusWorkflowJSON of table IR_Playbook")
+				}
+
+				if err := addColumnToMySQLTable(e, "IR_Incident", "StatusWorkflowJSON", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column StatusWorkflowJSON to table IR_Incident")
+				}
+				if _, err := e.Exec("UPDATE IR_Incident SET StatusWorkflowJSON = '' WHERE StatusWorkflowJSON IS NULL"); err != nil {
+					return errors.Wrapf(err, "failed setting default value in column StatusWorkflowJSON of table IR_Incident")
+				}
+				if err := addColumnToMySQLTable(e, "IR_Incident", "CurrentStatusResolved", "BOOLEAN DEFAULT FALSE"); err != nil {
+					return errors.Wrapf(err, "failed adding column CurrentStatusResolved to table IR_Incident")
+				}
+				if err := addColumnToMySQLTable(e, "IR_Incident", "CurrentStatusClosed", "BOOLEAN DEFAULT FALSE"); err != nil {
+					return errors.Wrapf(err, "failed adding column CurrentStatusClosed to table IR_Incident")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Playbook", "StatusWorkflowJSON", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column StatusWorkflowJSON to table IR_Playbook")
+				}
+
+				if err := addColumnToPGTable(e, "IR_Incident", "StatusWorkflowJSON", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column StatusWorkflowJSON to table IR_Incident")
+				}
+				if err := addColumnToPGTable(e, "IR_Incident", "CurrentStatusResolved", "BOOLEAN DEFAULT FALSE"); err != nil {
+					return errors.Wrapf(err, "failed adding column CurrentStatusResolved to table IR_Incident")
+				}
+				if err := addColumnToPGTable(e, "IR_Incident", "CurrentStatusClosed", "BOOLEAN DEFAULT FALSE"); err != nil {
+					return errors.Wrapf(err, "failed adding column CurrentStatusClosed to table IR_Incident")
+				}
+			}
+
+			// Existing runs all use the default status workflow.
+			if _, err := e.Exec("UPDATE IR_Incident SET CurrentStatusResolved = TRUE WHERE CurrentStatus IN ('Resolved', 'Archived')"); err != nil {
+				return errors.Wrapf(err, "failed setting CurrentStatusResolved of table IR_Incident")
+			}
+			if _, err := e.Exec("UPDATE IR_Incident SET CurrentStatusClosed = TRUE WHERE CurrentStatus = 'Archived'"); err != nil {
+				return errors.Wrapf(err, "failed setting CurrentStatusClosed of table IR_Incident")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.26.0"),
+		toVersion:   semver.MustParse("0.27.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "CustomFieldsJSON", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column CustomFieldsJSON to table IR_Playbook")
+				}
+				if _, err := e.Exec("UPDATE IR_Playbook SET CustomFieldsJSON = '' WHERE CustomFieldsJSON IS NULL"); err != nil {
+					return errors.Wrapf(err, "failed setting default value in column CustomFieldsJSON of table IR_Playbook")
+				}
+
+				if err := addColumnToMySQLTable(e, "IR_Incident", "CustomFieldsJSON", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column CustomFieldsJSON to table IR_Incident")
+				}
+				if _, err := e.Exec("UPDATE IR_Incident SET CustomFieldsJSON = '' WHERE CustomFieldsJSON IS NULL"); err != nil {
+					return errors.Wrapf(err, "failed setting default value in column CustomFieldsJSON of table IR_Incident")
+				}
+
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_CustomFieldValue
+					(
+						IncidentID VARCHAR(26) NOT NULL REFERENCES IR_Incident(ID),
+						FieldID    VARCHAR(26) NOT NULL,
+						Value      VARCHAR(512) NOT NULL,
+						UNIQUE INDEX IR_CustomFieldValue_IncidentID_FieldID_Value (IncidentID, FieldID, Value),
+						INDEX IR_CustomFieldValue_FieldID_Value (FieldID, Value)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_CustomFieldValue")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Playbook", "CustomFieldsJSON", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column CustomFieldsJSON to table IR_Playbook")
+				}
+
+				if err := addColumnToPGTable(e, "IR_Incident", "CustomFieldsJSON", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column CustomFieldsJSON to table IR_Incident")
+				}
+
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_CustomFieldValue
+					(
+						IncidentID TEXT NOT NULL REFERENCES IR_Incident(ID),
+						FieldID    TEXT NOT NULL,
+						Value      VARCHAR(512) NOT NULL
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_CustomFieldValue")
+				}
+
+				if _, err := e.Exec(createUniquePGIndex("IR_CustomFieldValue_IncidentID_FieldID_Value", "IR_CustomFieldValue", "IncidentID, FieldID, Value")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_CustomFieldValue_IncidentID_FieldID_Value")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_CustomFieldValue_FieldID_Value", "IR_CustomFieldValue", "FieldID, Value")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_CustomFieldValue_FieldID_Value")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.27.0"),
+		toVersion:   semver.MustParse("0.28.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_WebhookDelivery
+					(
+						ID             VARCHAR(26)   PRIMARY KEY,
+						IncidentID     VARCHAR(26)   NOT NULL REFERENCES IR_Incident(ID),
+						EventType      VARCHAR(64)   NOT NULL DEFAULT '',
+						URL            TEXT          NOT NULL,
+						Payload        MEDIUMTEXT    NOT NULL,
+						Status         VARCHAR(32)   NOT NULL DEFAULT '',
+						Attempts       INT           NOT NULL DEFAULT 0,
+						LastStatusCode INT           NOT NULL DEFAULT 0,
+						LastLatencyMs  BIGINT        NOT NULL DEFAULT 0,
+						LastError      VARCHAR(1024) NOT NULL DEFAULT '',
+						NextAttemptAt  BIGINT        NOT NULL DEFAULT 0,
+						CreateAt       BIGINT        NOT NULL,
+						UpdateAt       BIGINT        NOT NULL DEFAULT 0,
+						INDEX IR_WebhookDelivery_IncidentID_CreateAt (IncidentID, CreateAt)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_WebhookDelivery")
+				}
+			} else {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_WebhookDelivery
+					(
+						ID             TEXT   PRIMARY KEY,
+						IncidentID     TEXT   NOT NULL REFERENCES IR_Incident(ID),
+						EventType      TEXT   NOT NULL DEFAULT '',
+						URL            TEXT   NOT NULL DEFAULT '',
+						Payload        TEXT   NOT NULL DEFAULT '',
+						Status         TEXT   NOT NULL DEFAULT '',
+						Attempts       INT    NOT NULL DEFAULT 0,
+						LastStatusCode INT    NOT NULL DEFAULT 0,
+						LastLatencyMs  BIGINT NOT NULL DEFAULT 0,
+						LastError      TEXT   NOT NULL DEFAULT '',
+						NextAttemptAt  BIGINT NOT NULL DEFAULT 0,
+						CreateAt       BIGINT NOT NULL,
+						UpdateAt       BIGINT NOT NULL DEFAULT 0
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_WebhookDelivery")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_WebhookDelivery_IncidentID_CreateAt", "IR_WebhookDelivery", "IncidentID, CreateAt")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_WebhookDelivery_IncidentID_CreateAt")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.28.0"),
+		toVersion:   semver.MustParse("0.29.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "WebhookSecret", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column WebhookSecret to table IR_Playbook")
+				}
+				if _, err := e.Exec("UPDATE IR_Playbook SET WebhookSecret = '' WHERE WebhookSecret IS NULL"); err != nil {
+					return errors.Wrapf(err, "failed setting default value in column WebhookSecret of table IR_Playbook")
+				}
+
+				if err := addColumnToMySQLTable(e, "IR_Incident", "WebhookSecret", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column WebhookSecret to table IR_Incident")
+				}
+				if _, err := e.Exec("UPDATE IR_Incident SET WebhookSecret = '' WHERE WebhookSecret IS NULL"); err != nil {
+					return errors.Wrapf(err, "failed setting default value in column WebhookSecret of table IR_Incident")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Playbook", "WebhookSecret", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column WebhookSecret to table IR_Playbook")
+				}
+
+				if err := addColumnToPGTable(e, "IR_Incident", "WebhookSecret", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column WebhookSecret to table IR_Incident")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.29.0"),
+		toVersion:   semver.MustParse("0.30.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "WebhookSubscriptionsJSON", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column WebhookSubscriptionsJSON to table IR_Playbook")
+				}
+				if _, err := e.Exec("UPDATE IR_Playbook SET WebhookSubscriptionsJSON = '' WHERE WebhookSubscriptionsJSON IS NULL"); err != nil {
+					return errors.Wrapf(err, "failed setting default value in column WebhookSubscriptionsJSON of table IR_Playbook")
+				}
+
+				if err := addColumnToMySQLTable(e, "IR_Incident", "WebhookSubscriptionsJSON", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column WebhookSubscriptionsJSON to table IR_Incident")
+				}
+				if _, err := e.Exec("UPDATE IR_Incident SET WebhookSubscriptionsJSON = '' WHERE WebhookSubscriptionsJSON IS NULL"); err != nil {
+					return errors.Wrapf(err, "failed setting default value in column WebhookSubscriptionsJSON of table IR_Incident")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Playbook", "WebhookSubscriptionsJSON", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column WebhookSubscriptionsJSON to table IR_Playbook")
+				}
+
+				if err := addColumnToPGTable(e, "IR_Incident", "WebhookSubscriptionsJSON", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column WebhookSubscriptionsJSON to table IR_Incident")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.30.0"),
+		toVersion:   semver.MustParse("0.31.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "InboundWebhookJSON", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column InboundWebhookJSON to table IR_Playbook")
+				}
+				if _, err := e.Exec("UPDATE IR_Playbook SET InboundWebhookJSON = '' WHERE InboundWebhookJSON IS NULL"); err != nil {
+					return errors.Wrapf(err, "failed setting default value in column InboundWebhookJSON of table IR_Playbook")
+				}
+
+				if err := addColumnToMySQLTable(e, "IR_Incident", "AlertFingerprint", "VARCHAR(256) NOT NULL DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column AlertFingerprint to table IR_Incident")
+				}
+				if _, err := e.Exec(`ALTER TABLE IR_Incident ADD INDEX IR_Incident_PlaybookID_AlertFingerprint (PlaybookID, AlertFingerprint)`); err != nil {
+					me, ok := err.(*mysql.MySQLError)
+					if !ok || me.Number != 1061 { // not a Duplicate key name error
+						return errors.Wrapf(err, "failed creating index IR_Incident_PlaybookID_AlertFingerprint")
+					}
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Playbook", "InboundWebhookJSON", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column InboundWebhookJSON to table IR_Playbook")
+				}
+
+				if err := addColumnToPGTable(e, "IR_Incident", "AlertFingerprint", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column AlertFingerprint to table IR_Incident")
+				}
+				if _, err := e.Exec(createPGIndex("IR_Incident_PlaybookID_AlertFingerprint", "IR_Incident", "PlaybookID, AlertFingerprint")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_Incident_PlaybookID_AlertFingerprint")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.31.0"),
+		toVersion:   semver.MustParse("0.32.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_InboundWebhookEvent
+					(
+						IncidentID VARCHAR(26)  NOT NULL REFERENCES IR_Incident(ID),
+						EventID    VARCHAR(256) NOT NULL,
+						CreateAt   BIGINT       NOT NULL,
+						UNIQUE INDEX IR_InboundWebhookEvent_IncidentID_EventID (IncidentID, EventID)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_InboundWebhookEvent")
+				}
+			} else {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_InboundWebhookEvent
+					(
+						IncidentID TEXT   NOT NULL REFERENCES IR_Incident(ID),
+						EventID    TEXT   NOT NULL,
+						CreateAt   BIGINT NOT NULL
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_InboundWebhookEvent")
+				}
+
+				if _, err := e.Exec(createUniquePGIndex("IR_InboundWebhookEvent_IncidentID_EventID", "IR_InboundWebhookEvent", "IncidentID, EventID")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_InboundWebhookEvent_IncidentID_EventID")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.32.0"),
+		toVersion:   semver.MustParse("0.33.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_PlaybookRevision
+					(
+						PlaybookID   VARCHAR(26) NOT NULL REFERENCES IR_Playbook(ID),
+						Revision     BIGINT      NOT NULL,
+						UserID       VARCHAR(26) NOT NULL DEFAULT '',
+						CreateAt     BIGINT      NOT NULL,
+						PlaybookJSON MEDIUMTEXT  NOT NULL,
+						ChangesJSON  MEDIUMTEXT  NOT NULL,
+						PRIMARY KEY (PlaybookID, Revision)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_PlaybookRevision")
+				}
+
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "Revision", "BIGINT NOT NULL DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column Revision to table IR_Playbook")
+				}
+
+				if err := addColumnToMySQLTable(e, "IR_Incident", "PlaybookRevision", "BIGINT NOT NULL DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column PlaybookRevision to table IR_Incident")
+				}
+			} else {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_PlaybookRevision
+					(
+						PlaybookID   TEXT   NOT NULL REFERENCES IR_Playbook(ID),
+						Revision     BIGINT NOT NULL,
+						UserID       TEXT   NOT NULL DEFAULT '',
+						CreateAt     BIGINT NOT NULL,
+						PlaybookJSON JSON   NOT NULL,
+						ChangesJSON  JSON   NOT NULL,
+						PRIMARY KEY (PlaybookID, Revision)
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_PlaybookRevision")
+				}
+
+				if err := addColumnToPGTable(e, "IR_Playbook", "Revision", "BIGINT NOT NULL DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column Revision to table IR_Playbook")
+				}
+
+				if err := addColumnToPGTable(e, "IR_Incident", "PlaybookRevision", "BIGINT NOT NULL DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column PlaybookRevision to table IR_Incident")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.33.0"),
+		toVersion:   semver.MustParse("0.34.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "SyncPath", "VARCHAR(512) NOT NULL DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column SyncPath to table IR_Playbook")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Playbook", "SyncPath", "TEXT NOT NULL DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column SyncPath to table IR_Playbook")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.34.0"),
+		toVersion:   semver.MustParse("0.35.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if err := addColumnToMySQLTable(e, "IR_Incident", "Version", "BIGINT NOT NULL DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column Version to table IR_Incident")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Incident", "Version", "BIGINT NOT NULL DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column Version to table IR_Incident")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.35.0"),
+		toVersion:   semver.MustParse("0.36.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_Checklist
+					(
+						IncidentID VARCHAR(26) NOT NULL REFERENCES IR_Incident(ID),
+						ID         VARCHAR(26) NOT NULL,
+						Position   INT         NOT NULL,
+						Title      TEXT        NOT NULL,
+						PRIMARY KEY (IncidentID, ID)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_Checklist")
+				}
+
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_ChecklistItem
+					(
+						IncidentID             VARCHAR(26)   NOT NULL REFERENCES IR_Incident(ID),
+						ChecklistID            VARCHAR(26)   NOT NULL,
+						ID                     VARCHAR(26)   NOT NULL,
+						Position               INT           NOT NULL,
+						Title                  TEXT          NOT NULL,
+						State                  VARCHAR(32)   NOT NULL DEFAULT '',
+						StateModified          BIGINT        NOT NULL DEFAULT 0,
+						StateModifiedPostID    VARCHAR(26)   NOT NULL DEFAULT '',
+						AssigneeID             VARCHAR(26)   NOT NULL DEFAULT '',
+						AssigneeModified       BIGINT        NOT NULL DEFAULT 0,
+						AssigneeModifiedPostID VARCHAR(26)   NOT NULL DEFAULT '',
+						Command                TEXT          NOT NULL,
+						CommandLastRun         BIGINT        NOT NULL DEFAULT 0,
+						Description            TEXT          NOT NULL,
+						SkipReason             TEXT          NOT NULL,
+						DueAt                  BIGINT        NOT NULL DEFAULT 0,
+						DueAfterSeconds        BIGINT        NOT NULL DEFAULT 0,
+						DueRelativeTo          VARCHAR(32)   NOT NULL DEFAULT '',
+						ConcatenatedDependsOn  TEXT          NOT NULL,
+						CommandTrigger         VARCHAR(32)   NOT NULL DEFAULT '',
+						CommandTriggerStatus   VARCHAR(1024) NOT NULL DEFAULT '',
+						CommandRunAs           VARCHAR(32)   NOT NULL DEFAULT '',
+						CommandTriggeredAt     BIGINT        NOT NULL DEFAULT 0,
+						SubItemsJSON           MEDIUMTEXT    NOT NULL,
+						CommentsJSON           MEDIUMTEXT    NOT NULL,
+						CommentsPostID         VARCHAR(26)   NOT NULL DEFAULT '',
+						PRIMARY KEY (IncidentID, ID),
+						INDEX IR_ChecklistItem_AssigneeID_State (AssigneeID, State)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_ChecklistItem")
+				}
+			} else {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_Checklist
+					(
+						IncidentID TEXT NOT NULL REFERENCES IR_Incident(ID),
+						ID         TEXT NOT NULL,
+						Position   INT  NOT NULL,
+						Title      TEXT NOT NULL DEFAULT '',
+						PRIMARY KEY (IncidentID, ID)
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_Checklist")
+				}
+
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_ChecklistItem
+					(
+						IncidentID             TEXT   NOT NULL REFERENCES IR_Incident(ID),
+						ChecklistID            TEXT   NOT NULL,
+						ID                     TEXT   NOT NULL,
+						Position               INT    NOT NULL,
+						Title                  TEXT   NOT NULL DEFAULT '',
+						State                  TEXT   NOT NULL DEFAULT '',
+						StateModified          BIGINT NOT NULL DEFAULT 0,
+						StateModifiedPostID    TEXT   NOT NULL DEFAULT '',
+						AssigneeID             TEXT   NOT NULL DEFAULT '',
+						AssigneeModified       BIGINT NOT NULL DEFAULT 0,
+						AssigneeModifiedPostID TEXT   NOT NULL DEFAULT '',
+						Command                TEXT   NOT NULL DEFAULT '',
+						CommandLastRun         BIGINT NOT NULL DEFAULT 0,
+						Description            TEXT   NOT NULL DEFAULT '',
+						SkipReason             TEXT   NOT NULL DEFAULT '',
+						DueAt                  BIGINT NOT NULL DEFAULT 0,
+						DueAfterSeconds        BIGINT NOT NULL DEFAULT 0,
+						DueRelativeTo          TEXT   NOT NULL DEFAULT '',
+						ConcatenatedDependsOn  TEXT   NOT NULL DEFAULT '',
+						CommandTrigger         TEXT   NOT NULL DEFAULT '',
+						CommandTriggerStatus   TEXT   NOT NULL DEFAULT '',
+						CommandRunAs           TEXT   NOT NULL DEFAULT '',
+						CommandTriggeredAt     BIGINT NOT NULL DEFAULT 0,
+						SubItemsJSON           TEXT   NOT NULL DEFAULT '',
+						CommentsJSON           TEXT   NOT NULL DEFAULT '',
+						CommentsPostID         TEXT   NOT NULL DEFAULT '',
+						PRIMARY KEY (IncidentID, ID)
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_ChecklistItem")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_ChecklistItem_AssigneeID_State", "IR_ChecklistItem", "AssigneeID, State")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_ChecklistItem_AssigneeID_State")
+				}
+			}
+
+			getPlaybookRunsQuery := sqlStore.builder.
+				Select("ID", "ChecklistsJSON").
+				From("IR_Incident")
+
+			var playbookRuns []struct {
+				ID             string
+				ChecklistsJSON json.RawMessage
+			}
+			if err := sqlStore.selectBuilder(e, &playbookRuns, getPlaybookRunsQuery); err != nil {
+				return errors.Wrapf(err, "failed getting playbook runs to move their checklists")
+			}
+
+			for _, playbookRun := range playbookRuns {
+				if len(playbookRun.ChecklistsJSON) == 0 {
+					continue
+				}
+
+				var checklists []app.Checklist
+				if err := json.Unmarshal(playbookRun.ChecklistsJSON, &checklists); err != nil {
+					return errors.Wrapf(err, "failed to unmarshal checklists json for playbook run id: '%s'", playbookRun.ID)
+				}
+
+				if err := insertChecklists(sqlStore, e, playbookRun.ID, populateChecklistIDs(checklists)); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.36.0"),
+		toVersion:   semver.MustParse("0.37.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_UserInfo
+					(
+						ID            VARCHAR(26) PRIMARY KEY,
+						DigestEnabled BOOLEAN     NOT NULL DEFAULT FALSE,
+						LastDigestAt  BIGINT      NOT NULL DEFAULT 0
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_UserInfo")
+				}
+			} else {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_UserInfo
+					(
+						ID            TEXT    PRIMARY KEY,
+						DigestEnabled BOOLEAN NOT NULL DEFAULT FALSE,
+						LastDigestAt  BIGINT  NOT NULL DEFAULT 0
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_UserInfo")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.37.0"),
+		toVersion:   semver.MustParse("0.38.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "ReminderEscalationJSON", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column ReminderEscalationJSON to table IR_Playbook")
+				}
+				if _, err := e.Exec("UPDATE IR_Playbook SET ReminderEscalationJSON = '' WHERE ReminderEscalationJSON IS NULL"); err != nil {
+					return errors.Wrapf(err, "failed setting default value in column ReminderEscalationJSON of table IR_Playbook")
+				}
+
+				if err := addColumnToMySQLTable(e, "IR_Incident", "ReminderEscalationJSON", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column ReminderEscalationJSON to table IR_Incident")
+				}
+				if _, err := e.Exec("UPDATE IR_Incident SET ReminderEscalationJSON = '' WHERE ReminderEscalationJSON IS NULL"); err != nil {
+					return errors.Wrapf(err, "failed setting default value in column ReminderEscalationJSON of table IR_Incident")
+				}
+				if err := addColumnToMySQLTable(e, "IR_Incident", "ReminderCount", "BIGINT NOT NULL DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column ReminderCount to table IR_Incident")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Playbook", "ReminderEscalationJSON", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column ReminderEscalationJSON to table IR_Playbook")
+				}
+
+				if err := addColumnToPGTable(e, "IR_Incident", "ReminderEscalationJSON", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column ReminderEscalationJSON to table IR_Incident")
+				}
+				if err := addColumnToPGTable(e, "IR_Incident", "ReminderCount", "BIGINT NOT NULL DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column ReminderCount to table IR_Incident")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.38.0"),
+		toVersion:   semver.MustParse("0.39.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DATABASE_DRIVER_MYSQL {
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "CreatorID", "VARCHAR(26) NOT NULL DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column CreatorID to table IR_Playbook")
+				}
+				if _, err := e.Exec(`
+					UPDATE IR_Playbook p
+					JOIN IR_PlaybookRevision pr ON pr.PlaybookID = p.ID AND pr.Revision = 1
+					SET p.CreatorID = pr.UserID
+					WHERE p.CreatorID = ''
+				`); err != nil {
+					return errors.Wrapf(err, "failed setting column CreatorID of table IR_Playbook from the first revisions")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Playbook", "CreatorID", "TEXT NOT NULL DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column CreatorID to table IR_Playbook")
+				}
+				if _, err := e.Exec(`
+					UPDATE IR_Playbook p
+					SET CreatorID = pr.UserID
+					FROM IR_PlaybookRevision pr
+					WHERE pr.PlaybookID = p.ID AND pr.Revision = 1 AND p.CreatorID = ''
+				`); err != nil {
+					return errors.Wrapf(err, "failed setting column CreatorID of table IR_Playbook from the first revisions")
+				}
+			}
+
+			// Playbooks created before revisions were recorded, or whose first revision is the
+			// baseline saved on their first update, have no known creator. Their creator was
+			// their only member when they were created, so fall back to a sole member.
+			if _, err := e.Exec(`
+				UPDATE IR_Playbook
+				SET CreatorID = (SELECT MIN(pm.MemberID) FROM IR_PlaybookMember pm WHERE pm.PlaybookID = IR_Playbook.ID)
+				WHERE CreatorID = ''
+				AND (SELECT COUNT(*) FROM IR_PlaybookMember pm WHERE pm.PlaybookID = IR_Playbook.ID) = 1
+			`); err != nil {
+				return errors.Wrapf(err, "failed setting column CreatorID of table IR_Playbook from the members")
+			}
+
+			return nil
+		},
+	},
 }
@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/app"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
 	"github.com/mattermost/mattermost-server/v5/model"
@@ -20,6 +22,12 @@ type sqlPlaybook struct {
 	ConcatenatedInvitedUserIDs    string
 	ConcatenatedInvitedGroupIDs   string
 	ConcatenatedSignalAnyKeywords string
+	ConcatenatedSeverityLevels    string
+	StatusWorkflowJSON            string
+	ReminderEscalationJSON        string
+	CustomFieldsJSON              string
+	WebhookSubscriptionsJSON      string
+	InboundWebhookJSON            string
 }
 
 // playbookStore is a sql store for playbooks. Use NewPlaybookStore to create it.
@@ -92,8 +100,8 @@ func applyPlaybookFilterOptionsSort(builder sq.SelectBuilder, options app.Playbo
 // NewPlaybookStore creates a new store for playbook service.
 func NewPlaybookStore(pluginAPI PluginAPIClient, log bot.Logger, sqlStore *SQLStore) app.PlaybookStore {
 	playbookSelect := sqlStore.builder.
-		Select("ID", "Title", "Description", "TeamID", "CreatePublicIncident AS CreatePublicPlaybookRun", "CreateAt",
-			"UpdateAt", "DeleteAt", "NumStages", "NumSteps", "BroadcastChannelID",
+		Select("ID", "Title", "Description", "TeamID", "CreatePublicIncident AS CreatePublicPlaybookRun", "CreatorID", "CreateAt",
+			"UpdateAt", "DeleteAt", "Revision", "NumStages", "NumSteps", "BroadcastChannelID",
 			"COALESCE(ReminderMessageTemplate, '') ReminderMessageTemplate", "ReminderTimerDefaultSeconds",
 			"ConcatenatedInvitedUserIDs", "ConcatenatedInvitedGroupIDs", "InviteUsersEnabled",
 			"DefaultCommanderID AS DefaultOwnerID", "DefaultCommanderEnabled AS DefaultOwnerEnabled",
@@ -106,7 +114,14 @@ func NewPlaybookStore(pluginAPI PluginAPIClient, log bot.Logger, sqlStore *SQLSt
 			"WebhookOnStatusUpdateEnabled",
 			"ExportChannelOnArchiveEnabled",
 			"ConcatenatedSignalAnyKeywords", "SignalAnyKeywordsEnabled",
-			"CategorizeChannelEnabled").
+			"CategorizeChannelEnabled",
+			"COALESCE(ConcatenatedSeverityLevels, '') ConcatenatedSeverityLevels", "COALESCE(DefaultSeverity, '') DefaultSeverity",
+			"COALESCE(StatusWorkflowJSON, '') StatusWorkflowJSON",
+			"COALESCE(ReminderEscalationJSON, '') ReminderEscalationJSON",
+			"COALESCE(CustomFieldsJSON, '') CustomFieldsJSON",
+			"COALESCE(WebhookSubscriptionsJSON, '') WebhookSubscriptionsJSON",
+			"COALESCE(WebhookSecret, '') WebhookSecret",
+			"COALESCE(InboundWebhookJSON, '') InboundWebhookJSON", "SyncPath").
 		From("IR_Playbook")
 
 	memberIDsSelect := sqlStore.builder.
@@ -150,9 +165,11 @@ func (p *playbookStore) Create(playbook app.Playbook) (id string, err error) {
 			"Description":                          rawPlaybook.Description,
 			"TeamID":                               rawPlaybook.TeamID,
 			"CreatePublicIncident":                 rawPlaybook.CreatePublicPlaybookRun,
+			"CreatorID":                            rawPlaybook.CreatorID,
 			"CreateAt":                             rawPlaybook.CreateAt,
 			"UpdateAt":                             rawPlaybook.UpdateAt,
 			"DeleteAt":                             rawPlaybook.DeleteAt,
+			"Revision":                             rawPlaybook.Revision,
 			"ChecklistsJSON":                       rawPlaybook.ChecklistsJSON,
 			"NumStages":                            len(rawPlaybook.Checklists),
 			"NumSteps":                             getSteps(rawPlaybook.Playbook),
@@ -178,6 +195,15 @@ func (p *playbookStore) Create(playbook app.Playbook) (id string, err error) {
 			"ConcatenatedSignalAnyKeywords":        rawPlaybook.ConcatenatedSignalAnyKeywords,
 			"SignalAnyKeywordsEnabled":             rawPlaybook.SignalAnyKeywordsEnabled,
 			"CategorizeChannelEnabled":             rawPlaybook.CategorizeChannelEnabled,
+			"ConcatenatedSeverityLevels":           rawPlaybook.ConcatenatedSeverityLevels,
+			"DefaultSeverity":                      rawPlaybook.DefaultSeverity,
+			"StatusWorkflowJSON":                   rawPlaybook.StatusWorkflowJSON,
+			"ReminderEscalationJSON":               rawPlaybook.ReminderEscalationJSON,
+			"CustomFieldsJSON":                     rawPlaybook.CustomFieldsJSON,
+			"WebhookSubscriptionsJSON":             rawPlaybook.WebhookSubscriptionsJSON,
+			"WebhookSecret":                        rawPlaybook.WebhookSecret,
+			"InboundWebhookJSON":                   rawPlaybook.InboundWebhookJSON,
+			"SyncPath":                             rawPlaybook.SyncPath,
 		}))
 	if err != nil {
 		return "", errors.Wrap(err, "failed to store new playbook")
@@ -293,10 +319,18 @@ func (p *playbookStore) GetPlaybooksForTeam(requesterInfo app.RequesterInfo, tea
 		Select("ID", "Title", "Description", "TeamID", "CreatePublicIncident AS CreatePublicPlaybookRun", "CreateAt",
 			"DeleteAt", "NumStages", "NumSteps").
 		From("IR_Playbook AS p").
-		Where(sq.Eq{"DeleteAt": 0}).
 		Where(sq.Eq{"TeamID": teamID}).
 		Where(permissionsAndFilter)
 
+	queryForTotal := p.store.builder.
+		Select("COUNT(*)").
+		From("IR_Playbook AS p").
+		Where(sq.Eq{"TeamID": teamID}).
+		Where(permissionsAndFilter)
+
+	queryForResults = p.applyPlaybookFilterOptions(queryForResults, opts)
+	queryForTotal = p.applyPlaybookFilterOptions(queryForTotal, opts)
+
 	queryForResults, err := applyPlaybookFilterOptionsSort(queryForResults, opts)
 	if err != nil {
 		return app.GetPlaybooksResults{}, errors.Wrap(err, "failed to apply sort options")
@@ -310,13 +344,6 @@ func (p *playbookStore) GetPlaybooksForTeam(requesterInfo app.RequesterInfo, tea
 		return app.GetPlaybooksResults{}, errors.Wrap(err, "failed to get playbooks")
 	}
 
-	queryForTotal := p.store.builder.
-		Select("COUNT(*)").
-		From("IR_Playbook AS p").
-		Where(sq.Eq{"DeleteAt": 0}).
-		Where(sq.Eq{"TeamID": teamID}).
-		Where(permissionsAndFilter)
-
 	var total int
 	if err = p.store.getBuilder(p.store.db, &total, queryForTotal); err != nil {
 		return app.GetPlaybooksResults{}, errors.Wrap(err, "failed to get total count")
@@ -335,6 +362,56 @@ func (p *playbookStore) GetPlaybooksForTeam(requesterInfo app.RequesterInfo, tea
 	}, nil
 }
 
+// applyPlaybookFilterOptions filters the playbooks of the query, aliased as p, by the options
+// other than sort and pagination.
+func (p *playbookStore) applyPlaybookFilterOptions(builder sq.SelectBuilder, options app.PlaybookFilterOptions) sq.SelectBuilder {
+	if !options.IncludeArchived {
+		builder = builder.Where(sq.Eq{"p.DeleteAt": 0})
+	}
+
+	if options.SearchTerm != "" {
+		titleColumn := "p.Title"
+		descriptionColumn := "p.Description"
+		searchString := escapeLikePattern(options.SearchTerm)
+
+		// Postgres performs a case-sensitive search, so we need to lowercase
+		// both the column contents and the search string
+		if p.store.db.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+			titleColumn = "LOWER(p.Title)"
+			descriptionColumn = "LOWER(p.Description)"
+			searchString = strings.ToLower(searchString)
+		}
+
+		builder = builder.Where(sq.Or{
+			sq.Like{titleColumn: fmt.Sprint("%", searchString, "%")},
+			sq.Like{descriptionColumn: fmt.Sprint("%", searchString, "%")},
+		})
+	}
+
+	if options.MemberID != "" {
+		builder = builder.Where(sq.Expr(`EXISTS(SELECT 1
+				FROM IR_PlaybookMember as pm
+				WHERE pm.PlaybookID = p.ID
+				AND pm.MemberID = ?)`, options.MemberID))
+	}
+
+	if options.CreatorID != "" {
+		builder = builder.Where(sq.Eq{"p.CreatorID": options.CreatorID})
+	}
+
+	if options.KeywordsEnabled != nil {
+		builder = builder.Where(sq.Eq{"p.SignalAnyKeywordsEnabled": *options.KeywordsEnabled})
+	}
+
+	return builder
+}
+
+// escapeLikePattern escapes the wildcards of the term so that it is matched literally by LIKE,
+// with the default escape character of both MySQL and Postgres.
+func escapeLikePattern(term string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(term)
+}
+
 func (p *playbookStore) GetNumPlaybooksForTeam(teamID string) (int, error) {
 	query := p.store.builder.
 		Select("COUNT(*)").
@@ -431,6 +508,32 @@ func (p *playbookStore) GetPlaybookIDsForUser(userID string, teamID string) ([]s
 	return playbookIDs, nil
 }
 
+// GetSyncedPlaybookIDs retrieves the IDs of the playbooks of the team that are synced from files,
+// by the path of their file.
+func (p *playbookStore) GetSyncedPlaybookIDs(teamID string) (map[string]string, error) {
+	var playbooks []struct {
+		ID       string
+		SyncPath string
+	}
+
+	err := p.store.selectBuilder(p.store.db, &playbooks, p.store.builder.
+		Select("ID", "SyncPath").
+		From("IR_Playbook").
+		Where(sq.Eq{"DeleteAt": 0}).
+		Where(sq.Eq{"TeamID": teamID}).
+		Where(sq.NotEq{"SyncPath": ""}))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrapf(err, "failed to get synced playbooks for team '%s'", teamID)
+	}
+
+	playbookIDs := make(map[string]string, len(playbooks))
+	for _, playbook := range playbooks {
+		playbookIDs[playbook.SyncPath] = playbook.ID
+	}
+
+	return playbookIDs, nil
+}
+
 // Update updates a playbook
 func (p *playbookStore) Update(playbook app.Playbook) (err error) {
 	if playbook.ID == "" {
@@ -448,7 +551,53 @@ func (p *playbookStore) Update(playbook app.Playbook) (err error) {
 	}
 	defer p.store.finalizeTransaction(tx)
 
-	_, err = p.store.execBuilder(tx, sq.
+	if err = p.updatePlaybook(tx, rawPlaybook); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+// UpdateWithRevision updates a playbook and saves its new revision in the same transaction.
+func (p *playbookStore) UpdateWithRevision(playbook app.Playbook, revision app.PlaybookRevision) (err error) {
+	if playbook.ID == "" {
+		return errors.New("id should not be empty")
+	}
+
+	rawPlaybook, err := toSQLPlaybook(playbook)
+	if err != nil {
+		return err
+	}
+
+	tx, err := p.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer p.store.finalizeTransaction(tx)
+
+	// Saving the revision first fails on concurrent updates, before overwriting the playbook.
+	if err = p.insertRevision(tx, revision); err != nil {
+		return err
+	}
+
+	if err = p.updatePlaybook(tx, rawPlaybook); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+// updatePlaybook updates the columns and members of a playbook.
+func (p *playbookStore) updatePlaybook(q queryExecer, rawPlaybook *sqlPlaybook) error {
+	_, err := p.store.execBuilder(q, sq.
 		Update("IR_Playbook").
 		SetMap(map[string]interface{}{
 			"Title":                                rawPlaybook.Title,
@@ -457,6 +606,7 @@ func (p *playbookStore) Update(playbook app.Playbook) (err error) {
 			"CreatePublicIncident":                 rawPlaybook.CreatePublicPlaybookRun,
 			"UpdateAt":                             rawPlaybook.UpdateAt,
 			"DeleteAt":                             rawPlaybook.DeleteAt,
+			"Revision":                             rawPlaybook.Revision,
 			"ChecklistsJSON":                       rawPlaybook.ChecklistsJSON,
 			"NumStages":                            len(rawPlaybook.Checklists),
 			"NumSteps":                             getSteps(rawPlaybook.Playbook),
@@ -482,6 +632,15 @@ func (p *playbookStore) Update(playbook app.Playbook) (err error) {
 			"ConcatenatedSignalAnyKeywords":        rawPlaybook.ConcatenatedSignalAnyKeywords,
 			"SignalAnyKeywordsEnabled":             rawPlaybook.SignalAnyKeywordsEnabled,
 			"CategorizeChannelEnabled":             rawPlaybook.CategorizeChannelEnabled,
+			"ConcatenatedSeverityLevels":           rawPlaybook.ConcatenatedSeverityLevels,
+			"DefaultSeverity":                      rawPlaybook.DefaultSeverity,
+			"StatusWorkflowJSON":                   rawPlaybook.StatusWorkflowJSON,
+			"ReminderEscalationJSON":               rawPlaybook.ReminderEscalationJSON,
+			"CustomFieldsJSON":                     rawPlaybook.CustomFieldsJSON,
+			"WebhookSubscriptionsJSON":             rawPlaybook.WebhookSubscriptionsJSON,
+			"WebhookSecret":                        rawPlaybook.WebhookSecret,
+			"InboundWebhookJSON":                   rawPlaybook.InboundWebhookJSON,
+			"SyncPath":                             rawPlaybook.SyncPath,
 		}).
 		Where(sq.Eq{"ID": rawPlaybook.ID}))
 
@@ -489,14 +648,10 @@ func (p *playbookStore) Update(playbook app.Playbook) (err error) {
 		return errors.Wrapf(err, "failed to update playbook with id '%s'", rawPlaybook.ID)
 	}
 
-	if err = p.replacePlaybookMembers(tx, rawPlaybook.Playbook); err != nil {
+	if err = p.replacePlaybookMembers(q, rawPlaybook.Playbook); err != nil {
 		return errors.Wrapf(err, "failed to replace playbook members for playbook with id '%s'", rawPlaybook.ID)
 	}
 
-	if err = tx.Commit(); err != nil {
-		return errors.Wrap(err, "could not commit transaction")
-	}
-
 	return nil
 }
 
@@ -518,6 +673,155 @@ func (p *playbookStore) Delete(id string) error {
 	return nil
 }
 
+// Undelete restores a deleted playbook. Its update time is bumped so that the playbooks cached
+// with their keywords are refreshed.
+func (p *playbookStore) Undelete(id string) error {
+	if id == "" {
+		return errors.New("ID cannot be empty")
+	}
+
+	_, err := p.store.execBuilder(p.store.db, sq.
+		Update("IR_Playbook").
+		Set("DeleteAt", 0).
+		Set("UpdateAt", model.GetMillis()).
+		Where(sq.Eq{"ID": id}))
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to undelete playbook with id '%s'", id)
+	}
+
+	return nil
+}
+
+// CreateRevision saves a revision of a playbook.
+func (p *playbookStore) CreateRevision(revision app.PlaybookRevision) error {
+	return p.insertRevision(p.store.db, revision)
+}
+
+// insertRevision saves a revision of a playbook, returning ErrPlaybookRevisionConflict if a
+// revision with the same number already exists.
+func (p *playbookStore) insertRevision(q queryExecer, revision app.PlaybookRevision) error {
+	if revision.PlaybookID == "" || revision.Playbook == nil {
+		return errors.New("revision should have a playbook ID and a snapshot")
+	}
+
+	playbookJSON, err := json.Marshal(revision.Playbook)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal revision %d of playbook with id '%s'", revision.Revision, revision.PlaybookID)
+	}
+
+	changes := revision.Changes
+	if changes == nil {
+		changes = []app.PlaybookChange{}
+	}
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal changes of revision %d of playbook with id '%s'", revision.Revision, revision.PlaybookID)
+	}
+
+	_, err = p.store.execBuilder(q, sq.
+		Insert("IR_PlaybookRevision").
+		SetMap(map[string]interface{}{
+			"PlaybookID":   revision.PlaybookID,
+			"Revision":     revision.Revision,
+			"UserID":       revision.UserID,
+			"CreateAt":     revision.CreateAt,
+			"PlaybookJSON": playbookJSON,
+			"ChangesJSON":  changesJSON,
+		}))
+
+	if err != nil {
+		if p.store.db.DriverName() == model.DATABASE_DRIVER_MYSQL {
+			me, ok := err.(*mysql.MySQLError)
+			if ok && me.Number == 1062 {
+				return errors.Wrap(app.ErrPlaybookRevisionConflict, err.Error())
+			}
+		} else {
+			pe, ok := err.(*pq.Error)
+			if ok && pe.Code == "23505" {
+				return errors.Wrap(app.ErrPlaybookRevisionConflict, err.Error())
+			}
+		}
+
+		return errors.Wrapf(err, "failed to store revision %d of playbook with id '%s'", revision.Revision, revision.PlaybookID)
+	}
+
+	return nil
+}
+
+// sqlPlaybookRevision is a row of IR_PlaybookRevision.
+type sqlPlaybookRevision struct {
+	PlaybookID   string
+	Revision     int64
+	UserID       string
+	CreateAt     int64
+	PlaybookJSON json.RawMessage
+	ChangesJSON  json.RawMessage
+}
+
+// GetRevisions retrieves the revisions of a playbook, most recent first, without their snapshots.
+func (p *playbookStore) GetRevisions(playbookID string) ([]app.PlaybookRevision, error) {
+	var rawRevisions []sqlPlaybookRevision
+	err := p.store.selectBuilder(p.store.db, &rawRevisions, p.queryBuilder.
+		Select("PlaybookID", "Revision", "UserID", "CreateAt", "ChangesJSON").
+		From("IR_PlaybookRevision").
+		Where(sq.Eq{"PlaybookID": playbookID}).
+		OrderBy("Revision DESC"))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrapf(err, "failed to get revisions of playbook with id '%s'", playbookID)
+	}
+
+	revisions := make([]app.PlaybookRevision, 0, len(rawRevisions))
+	for _, rawRevision := range rawRevisions {
+		revision, errConvert := toPlaybookRevision(rawRevision)
+		if errConvert != nil {
+			return nil, errConvert
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, nil
+}
+
+// GetRevision retrieves a revision of a playbook, with its snapshot.
+func (p *playbookStore) GetRevision(playbookID string, revision int64) (app.PlaybookRevision, error) {
+	var rawRevision sqlPlaybookRevision
+	err := p.store.getBuilder(p.store.db, &rawRevision, p.queryBuilder.
+		Select("PlaybookID", "Revision", "UserID", "CreateAt", "PlaybookJSON", "ChangesJSON").
+		From("IR_PlaybookRevision").
+		Where(sq.Eq{"PlaybookID": playbookID, "Revision": revision}))
+	if err == sql.ErrNoRows {
+		return app.PlaybookRevision{}, errors.Wrapf(app.ErrNotFound, "revision %d does not exist for playbook with id '%s'", revision, playbookID)
+	} else if err != nil {
+		return app.PlaybookRevision{}, errors.Wrapf(err, "failed to get revision %d of playbook with id '%s'", revision, playbookID)
+	}
+
+	return toPlaybookRevision(rawRevision)
+}
+
+func toPlaybookRevision(rawRevision sqlPlaybookRevision) (app.PlaybookRevision, error) {
+	revision := app.PlaybookRevision{
+		PlaybookID: rawRevision.PlaybookID,
+		Revision:   rawRevision.Revision,
+		UserID:     rawRevision.UserID,
+		CreateAt:   rawRevision.CreateAt,
+	}
+
+	if err := json.Unmarshal(rawRevision.ChangesJSON, &revision.Changes); err != nil {
+		return app.PlaybookRevision{}, errors.Wrapf(err, "failed to unmarshal changes of revision %d of playbook with id '%s'", revision.Revision, revision.PlaybookID)
+	}
+
+	if len(rawRevision.PlaybookJSON) > 0 {
+		var playbook app.Playbook
+		if err := json.Unmarshal(rawRevision.PlaybookJSON, &playbook); err != nil {
+			return app.PlaybookRevision{}, errors.Wrapf(err, "failed to unmarshal revision %d of playbook with id '%s'", revision.Revision, revision.PlaybookID)
+		}
+		revision.Playbook = &playbook
+	}
+
+	return revision, nil
+}
+
 // replacePlaybookMembers replaces the members of a playbook
 func (p *playbookStore) replacePlaybookMembers(q queryExecer, playbook app.Playbook) error {
 	// Delete existing members who are not in the new playbook.MemberIDs list
@@ -586,12 +890,43 @@ func toSQLPlaybook(playbook app.Playbook) (*sqlPlaybook, error) {
 		return nil, errors.Wrapf(err, "failed to marshal checklist json for playbook id: '%s'", playbook.ID)
 	}
 
+	statusWorkflowJSON, err := statusWorkflowToJSON(playbook.StatusWorkflow)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal status workflow json for playbook id: '%s'", playbook.ID)
+	}
+
+	reminderEscalationJSON, err := reminderEscalationToJSON(playbook.ReminderEscalation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal reminder escalation json for playbook id: '%s'", playbook.ID)
+	}
+
+	customFieldsJSON, err := customFieldsToJSON(playbook.CustomFields)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal custom fields json for playbook id: '%s'", playbook.ID)
+	}
+
+	webhookSubscriptionsJSON, err := webhookSubscriptionsToJSON(playbook.WebhookSubscriptions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal webhook subscriptions json for playbook id: '%s'", playbook.ID)
+	}
+
+	inboundWebhookJSON, err := inboundWebhookToJSON(playbook.InboundWebhook)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal inbound webhook json for playbook id: '%s'", playbook.ID)
+	}
+
 	return &sqlPlaybook{
 		Playbook:                      playbook,
 		ChecklistsJSON:                checklistsJSON,
 		ConcatenatedInvitedUserIDs:    strings.Join(playbook.InvitedUserIDs, ","),
 		ConcatenatedInvitedGroupIDs:   strings.Join(playbook.InvitedGroupIDs, ","),
 		ConcatenatedSignalAnyKeywords: strings.Join(playbook.SignalAnyKeywords, ","),
+		ConcatenatedSeverityLevels:    strings.Join(playbook.SeverityLevels, ","),
+		StatusWorkflowJSON:            statusWorkflowJSON,
+		ReminderEscalationJSON:        reminderEscalationJSON,
+		CustomFieldsJSON:              customFieldsJSON,
+		WebhookSubscriptionsJSON:      webhookSubscriptionsJSON,
+		InboundWebhookJSON:            inboundWebhookJSON,
 	}, nil
 }
 
@@ -617,5 +952,69 @@ func toPlaybook(rawPlaybook sqlPlaybook) (app.Playbook, error) {
 	if rawPlaybook.ConcatenatedSignalAnyKeywords != "" {
 		p.SignalAnyKeywords = strings.Split(rawPlaybook.ConcatenatedSignalAnyKeywords, ",")
 	}
+
+	p.SeverityLevels = []string(nil)
+	if rawPlaybook.ConcatenatedSeverityLevels != "" {
+		p.SeverityLevels = strings.Split(rawPlaybook.ConcatenatedSeverityLevels, ",")
+	}
+
+	statusWorkflow, err := statusWorkflowFromJSON(rawPlaybook.StatusWorkflowJSON)
+	if err != nil {
+		return app.Playbook{}, errors.Wrapf(err, "failed to unmarshal status workflow json for playbook id: '%s'", p.ID)
+	}
+	p.StatusWorkflow = statusWorkflow
+
+	reminderEscalation, err := reminderEscalationFromJSON(rawPlaybook.ReminderEscalationJSON)
+	if err != nil {
+		return app.Playbook{}, errors.Wrapf(err, "failed to unmarshal reminder escalation json for playbook id: '%s'", p.ID)
+	}
+	p.ReminderEscalation = reminderEscalation
+
+	customFields, err := customFieldsFromJSON(rawPlaybook.CustomFieldsJSON)
+	if err != nil {
+		return app.Playbook{}, errors.Wrapf(err, "failed to unmarshal custom fields json for playbook id: '%s'", p.ID)
+	}
+	p.CustomFields = customFields
+
+	webhookSubscriptions, err := webhookSubscriptionsFromJSON(rawPlaybook.WebhookSubscriptionsJSON)
+	if err != nil {
+		return app.Playbook{}, errors.Wrapf(err, "failed to unmarshal webhook subscriptions json for playbook id: '%s'", p.ID)
+	}
+	p.WebhookSubscriptions = webhookSubscriptions
+
+	inboundWebhook, err := inboundWebhookFromJSON(rawPlaybook.InboundWebhookJSON)
+	if err != nil {
+		return app.Playbook{}, errors.Wrapf(err, "failed to unmarshal inbound webhook json for playbook id: '%s'", p.ID)
+	}
+	p.InboundWebhook = inboundWebhook
+
 	return p, nil
 }
+
+// inboundWebhookToJSON marshals the given inbound webhook, storing an unconfigured webhook as an
+// empty string.
+func inboundWebhookToJSON(webhook app.InboundWebhook) (string, error) {
+	if webhook == (app.InboundWebhook{}) {
+		return "", nil
+	}
+
+	inboundWebhookJSON, err := json.Marshal(webhook)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal inbound webhook json")
+	}
+
+	return string(inboundWebhookJSON), nil
+}
+
+func inboundWebhookFromJSON(inboundWebhookJSON string) (app.InboundWebhook, error) {
+	var webhook app.InboundWebhook
+	if inboundWebhookJSON == "" {
+		return webhook, nil
+	}
+
+	if err := json.Unmarshal([]byte(inboundWebhookJSON), &webhook); err != nil {
+		return app.InboundWebhook{}, err
+	}
+
+	return webhook, nil
+}
@@ -27,20 +27,26 @@ const (
 
 type sqlPlaybookRun struct {
 	app.PlaybookRun
-	ChecklistsJSON              json.RawMessage
 	ConcatenatedInvitedUserIDs  string
 	ConcatenatedInvitedGroupIDs string
+	ConcatenatedSeverityLevels  string
+	StatusWorkflowJSON          string
+	ReminderEscalationJSON      string
+	CustomFieldsJSON            string
+	WebhookSubscriptionsJSON    string
 }
 
 // playbookRunStore holds the information needed to fulfill the methods in the store interface.
 type playbookRunStore struct {
-	pluginAPI            PluginAPIClient
-	log                  bot.Logger
-	store                *SQLStore
-	queryBuilder         sq.StatementBuilderType
-	playbookRunSelect    sq.SelectBuilder
-	statusPostsSelect    sq.SelectBuilder
-	timelineEventsSelect sq.SelectBuilder
+	pluginAPI               PluginAPIClient
+	log                     bot.Logger
+	store                   *SQLStore
+	queryBuilder            sq.StatementBuilderType
+	playbookRunSelect       sq.SelectBuilder
+	statusPostsSelect       sq.SelectBuilder
+	timelineEventsSelect    sq.SelectBuilder
+	customFieldValuesSelect sq.SelectBuilder
+	webhookDeliveriesSelect sq.SelectBuilder
 }
 
 // Ensure playbookRunStore implements the app.PlaybookRunStore interface.
@@ -51,6 +57,12 @@ type playbookRunStatusPosts []struct {
 	app.StatusPost
 }
 
+type playbookRunCustomFieldValues []struct {
+	PlaybookRunID string
+	FieldID       string
+	Value         string
+}
+
 func applyPlaybookRunFilterOptionsSort(builder sq.SelectBuilder, options app.PlaybookRunFilterOptions) (sq.SelectBuilder, error) {
 	var sort string
 	switch options.Sort {
@@ -70,6 +82,8 @@ func applyPlaybookRunFilterOptionsSort(builder sq.SelectBuilder, options app.Pla
 		sort = "CurrentStatus"
 	case app.SortByLastStatusUpdateAt:
 		sort = "LastStatusUpdateAt"
+	case app.SortBySeverity:
+		sort = "SeverityRank"
 	case "":
 		// Default to a stable sort if none explicitly provided.
 		sort = "ID"
@@ -114,11 +128,16 @@ func NewPlaybookRunStore(pluginAPI PluginAPIClient, log bot.Logger, sqlStore *SQ
 	playbookRunSelect := sqlStore.builder.
 		Select("i.ID", "c.DisplayName AS Name", "i.Description", "i.CommanderUserID AS OwnerUserID", "i.TeamID", "i.ChannelID",
 			"i.CreateAt", "i.EndAt", "i.DeleteAt", "i.PostID", "i.PlaybookID", "i.ReporterUserID", "i.CurrentStatus", "i.LastStatusUpdateAt",
-			"i.ChecklistsJSON", "COALESCE(i.ReminderPostID, '') ReminderPostID", "i.PreviousReminder", "i.BroadcastChannelID",
+			"COALESCE(i.ReminderPostID, '') ReminderPostID", "i.PreviousReminder", "i.ReminderCount", "i.BroadcastChannelID",
 			"COALESCE(ReminderMessageTemplate, '') ReminderMessageTemplate", "ConcatenatedInvitedUserIDs", "ConcatenatedInvitedGroupIDs", "DefaultCommanderID AS DefaultOwnerID",
 			"AnnouncementChannelID", "WebhookOnCreationURL", "Retrospective", "MessageOnJoin", "RetrospectivePublishedAt", "RetrospectiveReminderIntervalSeconds",
 			"RetrospectiveWasCanceled", "WebhookOnStatusUpdateURL", "ExportChannelOnArchiveEnabled",
-			"CategorizeChannelEnabled").
+			"CategorizeChannelEnabled", "COALESCE(i.Severity, '') Severity",
+			"COALESCE(i.ConcatenatedSeverityLevels, '') ConcatenatedSeverityLevels", "COALESCE(i.StatusWorkflowJSON, '') StatusWorkflowJSON",
+			"COALESCE(i.ReminderEscalationJSON, '') ReminderEscalationJSON",
+			"COALESCE(i.CustomFieldsJSON, '') CustomFieldsJSON", "COALESCE(i.WebhookSecret, '') WebhookSecret",
+			"COALESCE(i.WebhookSubscriptionsJSON, '') WebhookSubscriptionsJSON", "COALESCE(i.AlertFingerprint, '') AlertFingerprint",
+			"i.PlaybookRevision", "i.ActiveStage", "COALESCE(i.ActiveStageTitle, '') ActiveStageTitle", "i.Version").
 		From("IR_Incident AS i").
 		Join("Channels AS c ON (c.Id = i.ChannelId)")
 
@@ -154,14 +173,26 @@ func NewPlaybookRunStore(pluginAPI PluginAPIClient, log bot.Logger, sqlStore *SQ
 		).
 		From("IR_TimelineEvent as te")
 
+	customFieldValuesSelect := sqlStore.builder.
+		Select("cfv.IncidentID AS PlaybookRunID", "cfv.FieldID", "cfv.Value").
+		From("IR_CustomFieldValue AS cfv").
+		OrderBy("cfv.Value")
+
+	webhookDeliveriesSelect := sqlStore.builder.
+		Select("wd.ID", "wd.IncidentID AS PlaybookRunID", "wd.EventType", "wd.URL", "wd.Payload", "wd.Status",
+			"wd.Attempts", "wd.LastStatusCode", "wd.LastLatencyMs", "wd.LastError", "wd.NextAttemptAt", "wd.CreateAt", "wd.UpdateAt").
+		From("IR_WebhookDelivery AS wd")
+
 	return &playbookRunStore{
-		pluginAPI:            pluginAPI,
-		log:                  log,
-		store:                sqlStore,
-		queryBuilder:         sqlStore.builder,
-		playbookRunSelect:    playbookRunSelect,
-		statusPostsSelect:    statusPostsSelect,
-		timelineEventsSelect: timelineEventsSelect,
+		pluginAPI:               pluginAPI,
+		log:                     log,
+		store:                   sqlStore,
+		queryBuilder:            sqlStore.builder,
+		playbookRunSelect:       playbookRunSelect,
+		statusPostsSelect:       statusPostsSelect,
+		timelineEventsSelect:    timelineEventsSelect,
+		customFieldValuesSelect: customFieldValuesSelect,
+		webhookDeliveriesSelect: webhookDeliveriesSelect,
 	}
 }
 
@@ -195,6 +226,11 @@ func (s *playbookRunStore) GetPlaybookRuns(requesterInfo app.RequesterInfo, opti
 		queryForTotal = queryForTotal.Where(sq.Eq{"i.CurrentStatus": options.Statuses})
 	}
 
+	if options.ExcludeClosed {
+		queryForResults = queryForResults.Where(sq.Eq{"i.CurrentStatusClosed": false})
+		queryForTotal = queryForTotal.Where(sq.Eq{"i.CurrentStatusClosed": false})
+	}
+
 	if options.OwnerID != "" {
 		queryForResults = queryForResults.Where(sq.Eq{"i.CommanderUserID": options.OwnerID})
 		queryForTotal = queryForTotal.Where(sq.Eq{"i.CommanderUserID": options.OwnerID})
@@ -218,6 +254,25 @@ func (s *playbookRunStore) GetPlaybookRuns(requesterInfo app.RequesterInfo, opti
 		queryForTotal = queryForTotal.Where(sq.Eq{"i.PlaybookID": options.PlaybookID})
 	}
 
+	if options.Severity != "" {
+		queryForResults = queryForResults.Where(sq.Eq{"i.Severity": options.Severity})
+		queryForTotal = queryForTotal.Where(sq.Eq{"i.Severity": options.Severity})
+	}
+
+	if options.CustomFieldID != "" {
+		customFieldClause := s.queryBuilder.
+			Select("1").
+			Prefix("EXISTS(").
+			From("IR_CustomFieldValue AS cfv").
+			Where("cfv.IncidentID = i.ID").
+			Where(sq.Eq{"cfv.FieldID": options.CustomFieldID}).
+			Where(sq.Eq{"cfv.Value": options.CustomFieldValue}).
+			Suffix(")")
+
+		queryForResults = queryForResults.Where(customFieldClause)
+		queryForTotal = queryForTotal.Where(customFieldClause)
+	}
+
 	// TODO: do we need to sanitize (replace any '%'s in the search term)?
 	if options.SearchTerm != "" {
 		column := "c.DisplayName"
@@ -294,12 +349,26 @@ func (s *playbookRunStore) GetPlaybookRuns(requesterInfo app.RequesterInfo, opti
 		return nil, err
 	}
 
+	customFieldValues, err := s.getCustomFieldValuesForPlaybookRuns(tx, playbookRunIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	checklists, err := s.getChecklistsForPlaybookRuns(tx, playbookRunIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, errors.Wrap(err, "could not commit transaction")
 	}
 
+	for i, playbookRun := range playbookRuns {
+		playbookRuns[i].Checklists = checklists[playbookRun.ID]
+	}
 	addStatusPostsToPlaybookRuns(statusPosts, playbookRuns)
 	addTimelineEventsToPlaybookRuns(timelineEvents, playbookRuns)
+	addCustomFieldValuesToPlaybookRuns(customFieldValues, playbookRuns)
 
 	return &app.GetPlaybookRunsResults{
 		TotalCount: total,
@@ -325,8 +394,14 @@ func (s *playbookRunStore) CreatePlaybookRun(playbookRun *app.PlaybookRun) (*app
 		return nil, err
 	}
 
+	tx, err := s.store.db.Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not begin transaction")
+	}
+	defer s.store.finalizeTransaction(tx)
+
 	// When adding an PlaybookRun column #2: add to the SetMap
-	_, err = s.store.execBuilder(s.store.db, sq.
+	_, err = s.store.execBuilder(tx, sq.
 		Insert("IR_Incident").
 		SetMap(map[string]interface{}{
 			"ID":                                   rawPlaybookRun.ID,
@@ -340,9 +415,9 @@ func (s *playbookRunStore) CreatePlaybookRun(playbookRun *app.PlaybookRun) (*app
 			"EndAt":                                rawPlaybookRun.EndAt,
 			"PostID":                               rawPlaybookRun.PostID,
 			"PlaybookID":                           rawPlaybookRun.PlaybookID,
-			"ChecklistsJSON":                       rawPlaybookRun.ChecklistsJSON,
 			"ReminderPostID":                       rawPlaybookRun.ReminderPostID,
 			"PreviousReminder":                     rawPlaybookRun.PreviousReminder,
+			"ReminderCount":                        rawPlaybookRun.ReminderCount,
 			"BroadcastChannelID":                   rawPlaybookRun.BroadcastChannelID,
 			"ReminderMessageTemplate":              rawPlaybookRun.ReminderMessageTemplate,
 			"CurrentStatus":                        rawPlaybookRun.CurrentStatus,
@@ -360,17 +435,44 @@ func (s *playbookRunStore) CreatePlaybookRun(playbookRun *app.PlaybookRun) (*app
 			"WebhookOnStatusUpdateURL":             rawPlaybookRun.WebhookOnStatusUpdateURL,
 			"ExportChannelOnArchiveEnabled":        rawPlaybookRun.ExportChannelOnArchiveEnabled,
 			"CategorizeChannelEnabled":             rawPlaybookRun.CategorizeChannelEnabled,
+			"Severity":                             rawPlaybookRun.Severity,
+			"SeverityRank":                         rawPlaybookRun.SeverityRank(),
+			"ConcatenatedSeverityLevels":           rawPlaybookRun.ConcatenatedSeverityLevels,
+			"StatusWorkflowJSON":                   rawPlaybookRun.StatusWorkflowJSON,
+			"ReminderEscalationJSON":               rawPlaybookRun.ReminderEscalationJSON,
+			"CurrentStatusResolved":                !rawPlaybookRun.IsActive(),
+			"CurrentStatusClosed":                  rawPlaybookRun.Workflow().IsClosed(rawPlaybookRun.CurrentStatus),
+			"CustomFieldsJSON":                     rawPlaybookRun.CustomFieldsJSON,
+			"WebhookSecret":                        rawPlaybookRun.WebhookSecret,
+			"WebhookSubscriptionsJSON":             rawPlaybookRun.WebhookSubscriptionsJSON,
+			"AlertFingerprint":                     rawPlaybookRun.AlertFingerprint,
+			"PlaybookRevision":                     rawPlaybookRun.PlaybookRevision,
+			"ActiveStage":                          rawPlaybookRun.ActiveStage,
+			"ActiveStageTitle":                     rawPlaybookRun.ActiveStageTitle,
+			"Version":                              rawPlaybookRun.Version,
 			// Preserved for backwards compatibility with v1.2
-			"ActiveStage":      0,
-			"ActiveStageTitle": "",
-			"IsActive":         true,
-			"DeleteAt":         0,
+			"IsActive": true,
+			"DeleteAt": 0,
+			// No longer read: the checklists are stored in IR_Checklist and IR_ChecklistItem
+			"ChecklistsJSON": "[]",
 		}))
 
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to store new playbook run")
 	}
 
+	if err = insertChecklists(s.store, tx, playbookRun.ID, rawPlaybookRun.Checklists); err != nil {
+		return nil, err
+	}
+
+	if err = s.insertCustomFieldValues(tx, playbookRun.ID, playbookRun.CustomFieldValues); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "could not commit transaction")
+	}
+
 	return playbookRun, nil
 }
 
@@ -388,18 +490,25 @@ func (s *playbookRunStore) UpdatePlaybookRun(playbookRun *app.PlaybookRun) error
 		return err
 	}
 
+	tx, err := s.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer s.store.finalizeTransaction(tx)
+
 	// When adding an PlaybookRun column #3: add to this SetMap (if it is a column that can be updated)
-	_, err = s.store.execBuilder(s.store.db, sq.
+	result, err := s.store.execBuilder(tx, sq.
 		Update("IR_Incident").
 		SetMap(map[string]interface{}{
 			"Name":                                 "",
 			"Description":                          rawPlaybookRun.Description,
 			"CommanderUserID":                      rawPlaybookRun.OwnerUserID,
 			"LastStatusUpdateAt":                   rawPlaybookRun.LastStatusUpdateAt,
-			"ChecklistsJSON":                       rawPlaybookRun.ChecklistsJSON,
 			"ReminderPostID":                       rawPlaybookRun.ReminderPostID,
 			"PreviousReminder":                     rawPlaybookRun.PreviousReminder,
+			"ReminderCount":                        rawPlaybookRun.ReminderCount,
 			"BroadcastChannelID":                   rawPlaybookRun.BroadcastChannelID,
+			"ReminderMessageTemplate":              rawPlaybookRun.ReminderMessageTemplate,
 			"EndAt":                                rawPlaybookRun.ResolvedAt(),
 			"ConcatenatedInvitedUserIDs":           rawPlaybookRun.ConcatenatedInvitedUserIDs,
 			"ConcatenatedInvitedGroupIDs":          rawPlaybookRun.ConcatenatedInvitedGroupIDs,
@@ -413,13 +522,88 @@ func (s *playbookRunStore) UpdatePlaybookRun(playbookRun *app.PlaybookRun) error
 			"RetrospectiveWasCanceled":             rawPlaybookRun.RetrospectiveWasCanceled,
 			"WebhookOnStatusUpdateURL":             rawPlaybookRun.WebhookOnStatusUpdateURL,
 			"ExportChannelOnArchiveEnabled":        rawPlaybookRun.ExportChannelOnArchiveEnabled,
+			"Severity":                             rawPlaybookRun.Severity,
+			"SeverityRank":                         rawPlaybookRun.SeverityRank(),
+			"ConcatenatedSeverityLevels":           rawPlaybookRun.ConcatenatedSeverityLevels,
+			"ActiveStage":                          rawPlaybookRun.ActiveStage,
+			"ActiveStageTitle":                     rawPlaybookRun.ActiveStageTitle,
+			"Version":                              rawPlaybookRun.Version + 1,
 		}).
-		Where(sq.Eq{"ID": rawPlaybookRun.ID}))
+		Where(sq.Eq{"ID": rawPlaybookRun.ID, "Version": rawPlaybookRun.Version}))
 
 	if err != nil {
 		return errors.Wrapf(err, "failed to update playbook run with id '%s'", rawPlaybookRun.ID)
 	}
 
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrapf(err, "failed to check the update of playbook run with id '%s'", rawPlaybookRun.ID)
+	}
+	if rowsAffected == 0 {
+		return errors.Wrapf(app.ErrPlaybookRunVersionConflict, "playbook run with id '%s' is no longer at version %d", rawPlaybookRun.ID, rawPlaybookRun.Version)
+	}
+
+	if err = s.replaceChecklists(tx, rawPlaybookRun.ID, rawPlaybookRun.Checklists); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	playbookRun.Version++
+
+	return nil
+}
+
+// UpdateCustomFieldValues replaces the custom field values of a playbook run.
+func (s *playbookRunStore) UpdateCustomFieldValues(playbookRunID string, values app.CustomFieldValues) error {
+	if playbookRunID == "" {
+		return errors.New("needs playbook run ID")
+	}
+
+	tx, err := s.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer s.store.finalizeTransaction(tx)
+
+	_, err = s.store.execBuilder(tx, sq.
+		Delete("IR_CustomFieldValue").
+		Where(sq.Eq{"IncidentID": playbookRunID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete custom field values for playbook run with id '%s'", playbookRunID)
+	}
+
+	if err = s.insertCustomFieldValues(tx, playbookRunID, values); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+func (s *playbookRunStore) insertCustomFieldValues(e execer, playbookRunID string, values app.CustomFieldValues) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	insert := sq.
+		Insert("IR_CustomFieldValue").
+		Columns("IncidentID", "FieldID", "Value")
+	for fieldID, fieldValues := range values {
+		for _, value := range fieldValues {
+			insert = insert.Values(playbookRunID, fieldID, value)
+		}
+	}
+
+	if _, err := s.store.execBuilder(e, insert); err != nil {
+		return errors.Wrapf(err, "failed to store custom field values for playbook run with id '%s'", playbookRunID)
+	}
+
 	return nil
 }
 
@@ -450,8 +634,10 @@ func (s *playbookRunStore) UpdateStatus(statusPost *app.SQLStatusPost) error {
 	if _, err := s.store.execBuilder(s.store.db, sq.
 		Update("IR_Incident").
 		SetMap(map[string]interface{}{
-			"CurrentStatus": statusPost.Status,
-			"EndAt":         statusPost.EndAt,
+			"CurrentStatus":         statusPost.Status,
+			"CurrentStatusResolved": statusPost.Resolved,
+			"CurrentStatusClosed":   statusPost.Closed,
+			"EndAt":                 statusPost.EndAt,
 		}).
 		Where(sq.Eq{"ID": statusPost.PlaybookRunID})); err != nil {
 		return errors.Wrap(err, "failed to update current status")
@@ -582,16 +768,35 @@ func (s *playbookRunStore) GetPlaybookRun(playbookRunID string) (*app.PlaybookRu
 		return nil, err
 	}
 
+	customFieldValues, err := s.getCustomFieldValuesForPlaybookRuns(tx, []string{playbookRunID})
+	if err != nil {
+		return nil, err
+	}
+
+	checklists, err := s.getChecklistsForPlaybookRuns(tx, []string{playbookRunID})
+	if err != nil {
+		return nil, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, errors.Wrap(err, "could not commit transaction")
 	}
 
+	playbookRun.Checklists = checklists[playbookRunID]
+
 	for _, p := range statusPosts {
 		playbookRun.StatusPosts = append(playbookRun.StatusPosts, p.StatusPost)
 	}
 
 	playbookRun.TimelineEvents = append(playbookRun.TimelineEvents, timelineEvents...)
 
+	for _, v := range customFieldValues {
+		if playbookRun.CustomFieldValues == nil {
+			playbookRun.CustomFieldValues = app.CustomFieldValues{}
+		}
+		playbookRun.CustomFieldValues[v.FieldID] = append(playbookRun.CustomFieldValues[v.FieldID], v.Value)
+	}
+
 	return playbookRun, nil
 }
 
@@ -610,6 +815,17 @@ func (s *playbookRunStore) getTimelineEventsForPlaybookRun(q sqlx.Queryer, playb
 	return timelineEvents, nil
 }
 
+func (s *playbookRunStore) getCustomFieldValuesForPlaybookRuns(q sqlx.Queryer, playbookRunIDs []string) (playbookRunCustomFieldValues, error) {
+	var customFieldValues playbookRunCustomFieldValues
+
+	err := s.store.selectBuilder(q, &customFieldValues, s.customFieldValuesSelect.Where(sq.Eq{"cfv.IncidentID": playbookRunIDs}))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrap(err, "failed to get custom field values")
+	}
+
+	return customFieldValues, nil
+}
+
 // GetTimelineEvent returns the timeline event by id for the given playbook run.
 func (s *playbookRunStore) GetTimelineEvent(playbookRunID, eventID string) (*app.TimelineEvent, error) {
 	var event app.TimelineEvent
@@ -627,6 +843,142 @@ func (s *playbookRunStore) GetTimelineEvent(playbookRunID, eventID string) (*app
 	return &event, nil
 }
 
+// CreateWebhookDelivery inserts the webhook delivery into the DB and returns it with its new ID.
+func (s *playbookRunStore) CreateWebhookDelivery(delivery *app.WebhookDelivery) (*app.WebhookDelivery, error) {
+	if delivery.PlaybookRunID == "" {
+		return nil, errors.New("needs playbook run ID")
+	}
+	if delivery.URL == "" {
+		return nil, errors.New("needs URL")
+	}
+	if delivery.CreateAt == 0 {
+		delivery.CreateAt = model.GetMillis()
+	}
+	delivery.ID = model.NewId()
+
+	_, err := s.store.execBuilder(s.store.db, sq.
+		Insert("IR_WebhookDelivery").
+		SetMap(map[string]interface{}{
+			"ID":             delivery.ID,
+			"IncidentID":     delivery.PlaybookRunID,
+			"EventType":      string(delivery.EventType),
+			"URL":            delivery.URL,
+			"Payload":        delivery.Payload,
+			"Status":         string(delivery.Status),
+			"Attempts":       delivery.Attempts,
+			"LastStatusCode": delivery.LastStatusCode,
+			"LastLatencyMs":  delivery.LastLatencyMs,
+			"LastError":      delivery.LastError,
+			"NextAttemptAt":  delivery.NextAttemptAt,
+			"CreateAt":       delivery.CreateAt,
+			"UpdateAt":       delivery.UpdateAt,
+		}))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to insert webhook delivery")
+	}
+
+	return delivery, nil
+}
+
+// UpdateWebhookDelivery updates the status and the outcome of the last attempt of a webhook delivery.
+func (s *playbookRunStore) UpdateWebhookDelivery(delivery *app.WebhookDelivery) error {
+	if delivery.ID == "" {
+		return errors.New("needs webhook delivery ID")
+	}
+
+	_, err := s.store.execBuilder(s.store.db, sq.
+		Update("IR_WebhookDelivery").
+		SetMap(map[string]interface{}{
+			"Status":         string(delivery.Status),
+			"Attempts":       delivery.Attempts,
+			"LastStatusCode": delivery.LastStatusCode,
+			"LastLatencyMs":  delivery.LastLatencyMs,
+			"LastError":      delivery.LastError,
+			"NextAttemptAt":  delivery.NextAttemptAt,
+			"UpdateAt":       delivery.UpdateAt,
+		}).
+		Where(sq.Eq{"ID": delivery.ID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update webhook delivery with id '%s'", delivery.ID)
+	}
+
+	return nil
+}
+
+// GetWebhookDelivery returns the webhook delivery with the given ID.
+func (s *playbookRunStore) GetWebhookDelivery(deliveryID string) (*app.WebhookDelivery, error) {
+	var delivery app.WebhookDelivery
+
+	err := s.store.getBuilder(s.store.db, &delivery, s.webhookDeliveriesSelect.Where(sq.Eq{"wd.ID": deliveryID}))
+	if err == sql.ErrNoRows {
+		return nil, errors.Wrapf(app.ErrNotFound, "webhook delivery with id (%s) does not exist", deliveryID)
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to get webhook delivery with id (%s)", deliveryID)
+	}
+
+	return &delivery, nil
+}
+
+// GetWebhookDeliveries returns the webhook deliveries of a playbook run, most recent first,
+// optionally restricted to the given status.
+func (s *playbookRunStore) GetWebhookDeliveries(playbookRunID string, status app.WebhookDeliveryStatus) ([]app.WebhookDelivery, error) {
+	query := s.webhookDeliveriesSelect.
+		Where(sq.Eq{"wd.IncidentID": playbookRunID}).
+		OrderBy("wd.CreateAt DESC", "wd.ID")
+	if status != "" {
+		query = query.Where(sq.Eq{"wd.Status": string(status)})
+	}
+
+	deliveries := []app.WebhookDelivery{}
+	if err := s.store.selectBuilder(s.store.db, &deliveries, query); err != nil {
+		return nil, errors.Wrapf(err, "failed to get webhook deliveries for playbook run with id (%s)", playbookRunID)
+	}
+
+	return deliveries, nil
+}
+
+// CreateInboundWebhookEvent records that the inbound status update with the given event ID was
+// applied to the playbook run. Returns ErrDuplicateEntry if it already was.
+func (s *playbookRunStore) CreateInboundWebhookEvent(playbookRunID, eventID string) error {
+	_, err := s.store.execBuilder(s.store.db, sq.
+		Insert("IR_InboundWebhookEvent").
+		SetMap(map[string]interface{}{
+			"IncidentID": playbookRunID,
+			"EventID":    eventID,
+			"CreateAt":   model.GetMillis(),
+		}))
+
+	if err != nil {
+		if s.store.db.DriverName() == model.DATABASE_DRIVER_MYSQL {
+			me, ok := err.(*mysql.MySQLError)
+			if ok && me.Number == 1062 {
+				return errors.Wrap(app.ErrDuplicateEntry, err.Error())
+			}
+		} else {
+			pe, ok := err.(*pq.Error)
+			if ok && pe.Code == "23505" {
+				return errors.Wrap(app.ErrDuplicateEntry, err.Error())
+			}
+		}
+
+		return errors.Wrapf(err, "failed to record inbound webhook event '%s' of playbook run with id (%s)", eventID, playbookRunID)
+	}
+
+	return nil
+}
+
+// DeleteInboundWebhookEvent forgets the inbound status update with the given event ID.
+func (s *playbookRunStore) DeleteInboundWebhookEvent(playbookRunID, eventID string) error {
+	_, err := s.store.execBuilder(s.store.db, sq.
+		Delete("IR_InboundWebhookEvent").
+		Where(sq.Eq{"IncidentID": playbookRunID, "EventID": eventID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete inbound webhook event '%s' of playbook run with id (%s)", eventID, playbookRunID)
+	}
+
+	return nil
+}
+
 // GetPlaybookRunIDForChannel gets the playbook run ID associated with the given channel ID.
 func (s *playbookRunStore) GetPlaybookRunIDForChannel(channelID string) (string, error) {
 	query := s.queryBuilder.
@@ -645,6 +997,29 @@ func (s *playbookRunStore) GetPlaybookRunIDForChannel(channelID string) (string,
 	return id, nil
 }
 
+// GetActivePlaybookRunIDForAlert returns the ID of the most recent active run of the playbook
+// started by an alert with the given fingerprint.
+func (s *playbookRunStore) GetActivePlaybookRunIDForAlert(playbookID, fingerprint string) (string, error) {
+	query := s.queryBuilder.
+		Select("i.ID").
+		From("IR_Incident i").
+		Where(sq.Eq{"i.PlaybookID": playbookID}).
+		Where(sq.Eq{"i.AlertFingerprint": fingerprint}).
+		Where(sq.Eq{"i.CurrentStatusResolved": false}).
+		OrderBy("i.CreateAt DESC").
+		Limit(1)
+
+	var id string
+	err := s.store.getBuilder(s.store.db, &id, query)
+	if err == sql.ErrNoRows {
+		return "", errors.Wrapf(app.ErrNotFound, "playbook with id (%s) does not have an active run for the alert", playbookID)
+	} else if err != nil {
+		return "", errors.Wrapf(err, "failed to get the active playbook run for an alert of playbook with id '%s'", playbookID)
+	}
+
+	return id, nil
+}
+
 // GetAllPlaybookRunMembersCount returns the count of all members of an playbook run's channel
 // since the beginning of the playbook run, excluding bots.
 func (s *playbookRunStore) GetAllPlaybookRunMembersCount(channelID string) (int64, error) {
@@ -685,6 +1060,102 @@ func (s *playbookRunStore) GetOwners(requesterInfo app.RequesterInfo, options ap
 	return owners, nil
 }
 
+// GetOverdueStatusUpdateRuns returns the active playbook runs that userID owns or is a member
+// of, whose status update reminder went off before now, in milliseconds, without a status update.
+func (s *playbookRunStore) GetOverdueStatusUpdateRuns(userID string, now int64) ([]app.OverdueStatusUpdateRun, error) {
+	membershipClause := s.queryBuilder.
+		Select("1").
+		Prefix("EXISTS(").
+		From("ChannelMembers AS cm").
+		Where("cm.ChannelId = i.ChannelID").
+		Where(sq.Eq{"cm.UserId": userID}).
+		Suffix(")")
+
+	query := s.queryBuilder.
+		Select("i.ID AS PlaybookRunID", "c.DisplayName AS Name", "i.TeamID", "COALESCE(c.Name, '') AS ChannelName",
+			"i.LastStatusUpdateAt", "i.PreviousReminder").
+		From("IR_Incident AS i").
+		Join("Channels AS c ON (c.Id = i.ChannelId)").
+		Where(sq.Eq{"i.CurrentStatusResolved": false}).
+		Where(sq.Gt{"i.PreviousReminder": 0}).
+		// PreviousReminder is in nanoseconds.
+		Where(sq.LtOrEq{"i.LastStatusUpdateAt + i.PreviousReminder / 1000000": now}).
+		Where(sq.Or{sq.Eq{"i.CommanderUserID": userID}, membershipClause}).
+		OrderBy("i.LastStatusUpdateAt", "i.ID")
+
+	runs := []app.OverdueStatusUpdateRun{}
+	if err := s.store.selectBuilder(s.store.db, &runs, query); err != nil {
+		return nil, errors.Wrapf(err, "failed to get playbook runs overdue for a status update for user id '%s'", userID)
+	}
+
+	return runs, nil
+}
+
+// GetUserInfo returns the settings of the user. Returns ErrNotFound if they have none yet.
+func (s *playbookRunStore) GetUserInfo(userID string) (app.UserInfo, error) {
+	var info app.UserInfo
+
+	err := s.store.getBuilder(s.store.db, &info, s.queryBuilder.
+		Select("ID", "DigestEnabled", "LastDigestAt").
+		From("IR_UserInfo").
+		Where(sq.Eq{"ID": userID}))
+	if err == sql.ErrNoRows {
+		return app.UserInfo{}, errors.Wrapf(app.ErrNotFound, "user info for id '%s' does not exist", userID)
+	} else if err != nil {
+		return app.UserInfo{}, errors.Wrapf(err, "failed to get user info for id '%s'", userID)
+	}
+
+	return info, nil
+}
+
+// UpsertUserInfo creates or replaces the settings of the user.
+func (s *playbookRunStore) UpsertUserInfo(info app.UserInfo) error {
+	if info.ID == "" {
+		return errors.New("needs user ID")
+	}
+
+	// Same as setSystemValue: MySQL has native support for upsert, while Postgres reports the
+	// row as affected by the update even when nothing changed.
+	if s.store.db.DriverName() == model.DATABASE_DRIVER_MYSQL {
+		_, err := s.store.execBuilder(s.store.db, sq.
+			Insert("IR_UserInfo").
+			Columns("ID", "DigestEnabled", "LastDigestAt").
+			Values(info.ID, info.DigestEnabled, info.LastDigestAt).
+			Suffix("ON DUPLICATE KEY UPDATE DigestEnabled = ?, LastDigestAt = ?", info.DigestEnabled, info.LastDigestAt))
+		if err != nil {
+			return errors.Wrapf(err, "failed to upsert user info for id '%s'", info.ID)
+		}
+
+		return nil
+	}
+
+	result, err := s.store.execBuilder(s.store.db, sq.
+		Update("IR_UserInfo").
+		SetMap(map[string]interface{}{
+			"DigestEnabled": info.DigestEnabled,
+			"LastDigestAt":  info.LastDigestAt,
+		}).
+		Where(sq.Eq{"ID": info.ID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update user info for id '%s'", info.ID)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	_, err = s.store.execBuilder(s.store.db, sq.
+		Insert("IR_UserInfo").
+		Columns("ID", "DigestEnabled", "LastDigestAt").
+		Values(info.ID, info.DigestEnabled, info.LastDigestAt))
+	if err != nil {
+		return errors.Wrapf(err, "failed to insert user info for id '%s'", info.ID)
+	}
+
+	return nil
+}
+
 // NukeDB removes all playbook run related data.
 func (s *playbookRunStore) NukeDB() (err error) {
 	tx, err := s.store.db.Beginx()
@@ -693,7 +1164,7 @@ func (s *playbookRunStore) NukeDB() (err error) {
 	}
 	defer s.store.finalizeTransaction(tx)
 
-	if _, err := tx.Exec("DROP TABLE IF EXISTS IR_PlaybookMember,  IR_StatusPosts, IR_Incident, IR_Playbook, IR_System, IR_TimelineEvent"); err != nil {
+	if _, err := tx.Exec("DROP TABLE IF EXISTS IR_PlaybookMember,  IR_StatusPosts, IR_CustomFieldValue, IR_ChecklistItem, IR_Checklist, IR_WebhookDelivery, IR_InboundWebhookEvent, IR_PlaybookRevision, IR_Incident, IR_Playbook, IR_System, IR_TimelineEvent, IR_UserInfo"); err != nil {
 		return errors.Wrap(err, "could not delete all IR tables")
 	}
 
@@ -823,9 +1294,6 @@ func buildTeamLimitExpr(userID, teamID string) sq.Sqlizer {
 
 func (s *playbookRunStore) toPlaybookRun(rawPlaybookRun sqlPlaybookRun) (*app.PlaybookRun, error) {
 	playbookRun := rawPlaybookRun.PlaybookRun
-	if err := json.Unmarshal(rawPlaybookRun.ChecklistsJSON, &playbookRun.Checklists); err != nil {
-		return nil, errors.Wrapf(err, "failed to unmarshal checklists json for playbook run id: %s", rawPlaybookRun.ID)
-	}
 
 	playbookRun.InvitedUserIDs = []string(nil)
 	if rawPlaybookRun.ConcatenatedInvitedUserIDs != "" {
@@ -837,54 +1305,207 @@ func (s *playbookRunStore) toPlaybookRun(rawPlaybookRun sqlPlaybookRun) (*app.Pl
 		playbookRun.InvitedGroupIDs = strings.Split(rawPlaybookRun.ConcatenatedInvitedGroupIDs, ",")
 	}
 
+	playbookRun.SeverityLevels = []string(nil)
+	if rawPlaybookRun.ConcatenatedSeverityLevels != "" {
+		playbookRun.SeverityLevels = strings.Split(rawPlaybookRun.ConcatenatedSeverityLevels, ",")
+	}
+
+	statusWorkflow, err := statusWorkflowFromJSON(rawPlaybookRun.StatusWorkflowJSON)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal status workflow json for playbook run id: %s", rawPlaybookRun.ID)
+	}
+	playbookRun.StatusWorkflow = statusWorkflow
+
+	reminderEscalation, err := reminderEscalationFromJSON(rawPlaybookRun.ReminderEscalationJSON)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal reminder escalation json for playbook run id: %s", rawPlaybookRun.ID)
+	}
+	playbookRun.ReminderEscalation = reminderEscalation
+
+	customFields, err := customFieldsFromJSON(rawPlaybookRun.CustomFieldsJSON)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal custom fields json for playbook run id: %s", rawPlaybookRun.ID)
+	}
+	playbookRun.CustomFields = customFields
+
+	webhookSubscriptions, err := webhookSubscriptionsFromJSON(rawPlaybookRun.WebhookSubscriptionsJSON)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal webhook subscriptions json for playbook run id: %s", rawPlaybookRun.ID)
+	}
+	playbookRun.WebhookSubscriptions = webhookSubscriptions
+
 	return &playbookRun, nil
 }
 
 func toSQLPlaybookRun(playbookRun app.PlaybookRun) (*sqlPlaybookRun, error) {
-	newChecklists := populateChecklistIDs(playbookRun.Checklists)
-	checklistsJSON, err := checklistsToJSON(newChecklists)
+	playbookRun.Checklists = populateChecklistIDs(playbookRun.Checklists)
+
+	statusWorkflowJSON, err := statusWorkflowToJSON(playbookRun.StatusWorkflow)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal status workflow json for playbook run id: '%s'", playbookRun.ID)
+	}
+
+	reminderEscalationJSON, err := reminderEscalationToJSON(playbookRun.ReminderEscalation)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to marshal checklist json for playbook run id: '%s'", playbookRun.ID)
+		return nil, errors.Wrapf(err, "failed to marshal reminder escalation json for playbook run id: '%s'", playbookRun.ID)
+	}
+
+	customFieldsJSON, err := customFieldsToJSON(playbookRun.CustomFields)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal custom fields json for playbook run id: '%s'", playbookRun.ID)
+	}
+
+	webhookSubscriptionsJSON, err := webhookSubscriptionsToJSON(playbookRun.WebhookSubscriptions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal webhook subscriptions json for playbook run id: '%s'", playbookRun.ID)
 	}
 
 	return &sqlPlaybookRun{
 		PlaybookRun:                 playbookRun,
-		ChecklistsJSON:              checklistsJSON,
 		ConcatenatedInvitedUserIDs:  strings.Join(playbookRun.InvitedUserIDs, ","),
 		ConcatenatedInvitedGroupIDs: strings.Join(playbookRun.InvitedGroupIDs, ","),
+		ConcatenatedSeverityLevels:  strings.Join(playbookRun.SeverityLevels, ","),
+		StatusWorkflowJSON:          statusWorkflowJSON,
+		ReminderEscalationJSON:      reminderEscalationJSON,
+		CustomFieldsJSON:            customFieldsJSON,
+		WebhookSubscriptionsJSON:    webhookSubscriptionsJSON,
 	}, nil
 }
 
-// populateChecklistIDs returns a cloned slice with ids entered for checklists and checklist items.
+// populateChecklistIDs returns a cloned slice with ids entered for checklists and checklist items,
+// replacing the ids already used by another checklist or item, as they key their rows in the run.
 func populateChecklistIDs(checklists []app.Checklist) []app.Checklist {
 	if len(checklists) == 0 {
 		return nil
 	}
 
+	checklistIDs := make(map[string]bool)
+	itemIDs := make(map[string]bool)
 	newChecklists := make([]app.Checklist, len(checklists))
 	for i, c := range checklists {
 		newChecklists[i] = c.Clone()
-		if newChecklists[i].ID == "" {
+		if newChecklists[i].ID == "" || checklistIDs[newChecklists[i].ID] {
 			newChecklists[i].ID = model.NewId()
 		}
+		checklistIDs[newChecklists[i].ID] = true
+
 		for j, item := range newChecklists[i].Items {
-			if item.ID == "" {
+			if item.ID == "" || itemIDs[item.ID] {
 				newChecklists[i].Items[j].ID = model.NewId()
 			}
+			itemIDs[newChecklists[i].Items[j].ID] = true
 		}
 	}
 
 	return newChecklists
 }
 
-// A playbook run needs to assign unique ids to its checklist items
-func checklistsToJSON(checklists []app.Checklist) (json.RawMessage, error) {
-	checklistsJSON, err := json.Marshal(checklists)
+// statusWorkflowToJSON marshals the given workflow, storing an empty workflow as an empty string
+// so that it keeps falling back to the default workflow.
+func statusWorkflowToJSON(workflow app.StatusWorkflow) (string, error) {
+	if workflow.IsEmpty() {
+		return "", nil
+	}
+
+	statusWorkflowJSON, err := json.Marshal(workflow)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to marshal checklist json")
+		return "", errors.Wrap(err, "failed to marshal status workflow json")
+	}
+
+	return string(statusWorkflowJSON), nil
+}
+
+func statusWorkflowFromJSON(statusWorkflowJSON string) (app.StatusWorkflow, error) {
+	var workflow app.StatusWorkflow
+	if statusWorkflowJSON == "" {
+		return workflow, nil
+	}
+
+	if err := json.Unmarshal([]byte(statusWorkflowJSON), &workflow); err != nil {
+		return app.StatusWorkflow{}, err
 	}
 
-	return checklistsJSON, nil
+	return workflow, nil
+}
+
+// reminderEscalationToJSON marshals the given escalation, storing no escalation as an empty string.
+func reminderEscalationToJSON(escalation app.ReminderEscalation) (string, error) {
+	if escalation.IsEmpty() {
+		return "", nil
+	}
+
+	reminderEscalationJSON, err := json.Marshal(escalation)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal reminder escalation json")
+	}
+
+	return string(reminderEscalationJSON), nil
+}
+
+func reminderEscalationFromJSON(reminderEscalationJSON string) (app.ReminderEscalation, error) {
+	var escalation app.ReminderEscalation
+	if reminderEscalationJSON == "" {
+		return escalation, nil
+	}
+
+	if err := json.Unmarshal([]byte(reminderEscalationJSON), &escalation); err != nil {
+		return app.ReminderEscalation{}, err
+	}
+
+	return escalation, nil
+}
+
+// customFieldsToJSON marshals the given custom fields, storing no fields as an empty string.
+func customFieldsToJSON(fields []app.CustomField) (string, error) {
+	if len(fields) == 0 {
+		return "", nil
+	}
+
+	customFieldsJSON, err := json.Marshal(fields)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal custom fields json")
+	}
+
+	return string(customFieldsJSON), nil
+}
+
+func customFieldsFromJSON(customFieldsJSON string) ([]app.CustomField, error) {
+	if customFieldsJSON == "" {
+		return nil, nil
+	}
+
+	var fields []app.CustomField
+	if err := json.Unmarshal([]byte(customFieldsJSON), &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+func webhookSubscriptionsToJSON(subscriptions []app.WebhookSubscription) (string, error) {
+	if len(subscriptions) == 0 {
+		return "", nil
+	}
+
+	webhookSubscriptionsJSON, err := json.Marshal(subscriptions)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal webhook subscriptions json")
+	}
+
+	return string(webhookSubscriptionsJSON), nil
+}
+
+func webhookSubscriptionsFromJSON(webhookSubscriptionsJSON string) ([]app.WebhookSubscription, error) {
+	if webhookSubscriptionsJSON == "" {
+		return nil, nil
+	}
+
+	var subscriptions []app.WebhookSubscription
+	if err := json.Unmarshal([]byte(webhookSubscriptionsJSON), &subscriptions); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
 }
 
 func addStatusPostsToPlaybookRuns(statusIDs playbookRunStatusPosts, playbookRuns []app.PlaybookRun) {
@@ -907,6 +1528,19 @@ func addTimelineEventsToPlaybookRuns(timelineEvents []app.TimelineEvent, playboo
 	}
 }
 
+func addCustomFieldValuesToPlaybookRuns(customFieldValues playbookRunCustomFieldValues, playbookRuns []app.PlaybookRun) {
+	iToValues := make(map[string]app.CustomFieldValues)
+	for _, v := range customFieldValues {
+		if iToValues[v.PlaybookRunID] == nil {
+			iToValues[v.PlaybookRunID] = app.CustomFieldValues{}
+		}
+		iToValues[v.PlaybookRunID][v.FieldID] = append(iToValues[v.PlaybookRunID][v.FieldID], v.Value)
+	}
+	for i, playbookRun := range playbookRuns {
+		playbookRuns[i].CustomFieldValues = iToValues[playbookRun.ID]
+	}
+}
+
 // queryActiveBetweenTimes will modify the query only if one (or both) of start and end are non-zero.
 // If both are non-zero, return the playbook runs active between those two times.
 // If start is zero, return the playbook run active before the end (not active after the end).
@@ -929,7 +1563,7 @@ func queryActive(query sq.SelectBuilder, start int64, end int64) sq.SelectBuilde
 		sq.And{
 			sq.Or{
 				sq.GtOrEq{"i.EndAt": start},
-				sq.Eq{"i.EndAt": 0},
+				sq.Eq{"i.CurrentStatusResolved": false},
 			},
 			sq.Lt{"i.CreateAt": end},
 		})
@@ -80,6 +80,8 @@ func TestGetPlaybookRuns(t *testing.T) {
 	channel08 := model.Channel{Id: model.NewId(), Type: "P", CreateAt: 555, DeleteAt: 0}
 	channel09 := model.Channel{Id: model.NewId(), Type: "P", CreateAt: 556, DeleteAt: 0}
 
+	regionField := app.CustomField{ID: model.NewId(), Name: "Region", Type: app.CustomFieldTypeSelect, Options: []string{"eu", "us"}}
+
 	inc01 := *NewBuilder(nil).
 		WithName("pr 1 - wheel cat aliens wheelbarrow").
 		WithDescription("this is a description, not very long, but it can be up to 2048 bytes").
@@ -89,6 +91,11 @@ func TestGetPlaybookRuns(t *testing.T) {
 		WithCreateAt(123).
 		WithChecklists([]int{8}).
 		WithPlaybookID("playbook1").
+		WithSeverity([]string{"SEV-1", "SEV-2"}, "SEV-2").
+		WithCustomFieldValues([]app.CustomField{regionField}, app.CustomFieldValues{regionField.ID: {"eu"}}).
+		WithWebhookSubscriptions([]app.WebhookSubscription{
+			{Events: []app.WebhookEventType{app.WebhookEventOwnerChanged}, URLs: []string{"https://example.com/events"}},
+		}).
 		ToPlaybookRun()
 
 	inc02 := *NewBuilder(nil).
@@ -99,6 +106,9 @@ func TestGetPlaybookRuns(t *testing.T) {
 		WithCreateAt(199).
 		WithChecklists([]int{7}).
 		WithPlaybookID("playbook1").
+		WithSeverity([]string{"SEV-1", "SEV-2"}, "SEV-1").
+		WithCustomFieldValues([]app.CustomField{regionField}, app.CustomFieldValues{regionField.ID: {"us"}}).
+		WithReminderEscalation(app.ReminderEscalation{BackupAfterMissed: 2, BackupUserID: owner1.UserID, BroadcastAfterMissed: 3}, 1).
 		ToPlaybookRun()
 
 	inc03 := *NewBuilder(nil).
@@ -572,6 +582,51 @@ func TestGetPlaybookRuns(t *testing.T) {
 			},
 			ExpectedErr: nil,
 		},
+		{
+			Name: "team1 - exclude closed - admin",
+			RequesterInfo: app.RequesterInfo{
+				UserID:  lucy.ID,
+				IsAdmin: true,
+			},
+			Options: app.PlaybookRunFilterOptions{
+				TeamID:        team1id,
+				Sort:          app.SortByCreateAt,
+				Direction:     app.DirectionAsc,
+				ExcludeClosed: true,
+				Page:          0,
+				PerPage:       1000,
+			},
+			Want: app.GetPlaybookRunsResults{
+				TotalCount: 3,
+				PageCount:  1,
+				HasMore:    false,
+				Items:      []app.PlaybookRun{inc01, inc02, inc05},
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Name: "team1 - filter by custom field value - admin",
+			RequesterInfo: app.RequesterInfo{
+				UserID:  lucy.ID,
+				IsAdmin: true,
+			},
+			Options: app.PlaybookRunFilterOptions{
+				TeamID:           team1id,
+				Sort:             app.SortByCreateAt,
+				Direction:        app.DirectionAsc,
+				CustomFieldID:    regionField.ID,
+				CustomFieldValue: "eu",
+				Page:             0,
+				PerPage:          1000,
+			},
+			Want: app.GetPlaybookRunsResults{
+				TotalCount: 1,
+				PageCount:  1,
+				HasMore:    false,
+				Items:      []app.PlaybookRun{inc01},
+			},
+			ExpectedErr: nil,
+		},
 		{
 			Name: "team1 - search for horse - admin",
 			RequesterInfo: app.RequesterInfo{
@@ -947,6 +1002,50 @@ func TestGetPlaybookRuns(t *testing.T) {
 			},
 			ExpectedErr: nil,
 		},
+		{
+			Name: "team1 - severity SEV-1 - admin",
+			RequesterInfo: app.RequesterInfo{
+				UserID:  lucy.ID,
+				IsAdmin: true,
+			},
+			Options: app.PlaybookRunFilterOptions{
+				TeamID:    team1id,
+				Severity:  "SEV-1",
+				Sort:      app.SortByCreateAt,
+				Direction: app.DirectionDesc,
+				Page:      0,
+				PerPage:   1000,
+			},
+			Want: app.GetPlaybookRunsResults{
+				TotalCount: 1,
+				PageCount:  1,
+				HasMore:    false,
+				Items:      []app.PlaybookRun{inc02},
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Name: "team1 - playbook1 - sort by severity desc - admin",
+			RequesterInfo: app.RequesterInfo{
+				UserID:  lucy.ID,
+				IsAdmin: true,
+			},
+			Options: app.PlaybookRunFilterOptions{
+				TeamID:     team1id,
+				PlaybookID: "playbook1",
+				Sort:       app.SortBySeverity,
+				Direction:  app.DirectionDesc,
+				Page:       0,
+				PerPage:    1000,
+			},
+			Want: app.GetPlaybookRunsResults{
+				TotalCount: 2,
+				PageCount:  1,
+				HasMore:    false,
+				Items:      []app.PlaybookRun{inc02, inc01},
+			},
+			ExpectedErr: nil,
+		},
 	}
 
 	for _, driverName := range driverNames {
@@ -1259,6 +1358,30 @@ func TestUpdatePlaybookRun(t *testing.T) {
 				require.Equal(t, expected, actual)
 			})
 		}
+
+		t.Run("concurrent updates conflict", func(t *testing.T) {
+			returned, err := playbookRunStore.CreatePlaybookRun(NewBuilder(t).WithChecklists([]int{2}).ToPlaybookRun())
+			require.NoError(t, err)
+			createPlaybookRunChannel(t, store, returned)
+
+			first, err := playbookRunStore.GetPlaybookRun(returned.ID)
+			require.NoError(t, err)
+			second, err := playbookRunStore.GetPlaybookRun(returned.ID)
+			require.NoError(t, err)
+
+			first.Checklists[0].Items[0].State = app.ChecklistItemStateClosed
+			require.NoError(t, playbookRunStore.UpdatePlaybookRun(first))
+			require.Equal(t, int64(1), first.Version)
+
+			second.Checklists[0].Items[1].State = app.ChecklistItemStateClosed
+			err = playbookRunStore.UpdatePlaybookRun(second)
+			require.True(t, errors.Is(err, app.ErrPlaybookRunVersionConflict))
+			require.Equal(t, int64(0), second.Version)
+
+			actual, err := playbookRunStore.GetPlaybookRun(returned.ID)
+			require.NoError(t, err)
+			require.Equal(t, first, actual)
+		})
 	}
 }
 
@@ -1437,6 +1560,298 @@ func TestGetPlaybookRunIDForChannel(t *testing.T) {
 	}
 }
 
+func TestGetActivePlaybookRunIDForAlert(t *testing.T) {
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		_, store := setupSQLStore(t, db)
+		playbookRunStore := setupPlaybookRunStore(t, db)
+		setupChannelsTable(t, db)
+
+		playbookID := model.NewId()
+		otherPlaybookID := model.NewId()
+
+		resolved := NewBuilder(t).WithPlaybookID(playbookID).WithAlertFingerprint("db-1").WithCurrentStatus("Resolved").ToPlaybookRun()
+		active := NewBuilder(t).WithPlaybookID(playbookID).WithAlertFingerprint("db-1").ToPlaybookRun()
+		otherFingerprint := NewBuilder(t).WithPlaybookID(playbookID).WithAlertFingerprint("db-2").WithCurrentStatus("Resolved").ToPlaybookRun()
+		otherPlaybook := NewBuilder(t).WithPlaybookID(otherPlaybookID).WithAlertFingerprint("db-2").ToPlaybookRun()
+
+		var created []*app.PlaybookRun
+		for _, playbookRun := range []*app.PlaybookRun{resolved, active, otherFingerprint, otherPlaybook} {
+			returned, err := playbookRunStore.CreatePlaybookRun(playbookRun)
+			require.NoError(t, err)
+			createPlaybookRunChannel(t, store, playbookRun)
+			created = append(created, returned)
+		}
+
+		t.Run("retrieve the active playbook run of the alert", func(t *testing.T) {
+			id, err := playbookRunStore.GetActivePlaybookRunIDForAlert(playbookID, "db-1")
+			require.NoError(t, err)
+			require.Equal(t, created[1].ID, id)
+
+			returned, err := playbookRunStore.GetPlaybookRun(id)
+			require.NoError(t, err)
+			require.Equal(t, "db-1", returned.AlertFingerprint)
+		})
+
+		t.Run("ignore resolved playbook runs and those of other playbooks", func(t *testing.T) {
+			id, err := playbookRunStore.GetActivePlaybookRunIDForAlert(playbookID, "db-2")
+			require.ErrorIs(t, err, app.ErrNotFound)
+			require.Equal(t, "", id)
+		})
+	}
+}
+
+func TestGetChecklistItems(t *testing.T) {
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		_, store := setupSQLStore(t, db)
+		playbookRunStore := setupPlaybookRunStore(t, db)
+		setupChannelsTable(t, db)
+
+		teamID := model.NewId()
+		assigneeID := model.NewId()
+		requesterInfo := app.RequesterInfo{UserID: assigneeID, IsAdmin: true}
+
+		first := NewBuilder(t).WithTeamID(teamID).WithChecklists([]int{2, 1}).ToPlaybookRun()
+		first.Checklists[0].Items[1].AssigneeID = assigneeID
+		first.Checklists[0].Items[1].AssigneeModified = 200
+		first.Checklists[1].Items[0].AssigneeID = assigneeID
+		first.Checklists[1].Items[0].AssigneeModified = 100
+		first.Checklists[1].Items[0].State = app.ChecklistItemStateClosed
+		first.Checklists[1].Items[0].DependsOn = []string{first.Checklists[0].Items[1].ID}
+		first.Checklists[1].Items[0].SubItems = []app.ChecklistSubItem{{ID: model.NewId(), Title: "Sub-item", AssigneeID: assigneeID}}
+
+		second := NewBuilder(t).WithTeamID(teamID).WithChecklists([]int{1}).ToPlaybookRun()
+		second.Checklists[0].Items[0].AssigneeID = assigneeID
+		second.Checklists[0].Items[0].AssigneeModified = 300
+		second.Checklists[0].Items[0].DueAt = 1000
+
+		ended := NewBuilder(t).WithTeamID(teamID).WithChecklists([]int{1}).WithCurrentStatus("Resolved").ToPlaybookRun()
+		ended.Checklists[0].Items[0].AssigneeID = assigneeID
+
+		otherTeam := NewBuilder(t).WithChecklists([]int{1}).ToPlaybookRun()
+		otherTeam.Checklists[0].Items[0].AssigneeID = assigneeID
+
+		for _, playbookRun := range []*app.PlaybookRun{first, second, ended, otherTeam} {
+			_, err := playbookRunStore.CreatePlaybookRun(playbookRun)
+			require.NoError(t, err)
+			createPlaybookRunChannel(t, store, playbookRun)
+		}
+
+		itemIDs := func(items []app.PlaybookRunChecklistItem) []string {
+			ids := []string{}
+			for _, item := range items {
+				ids = append(ids, item.ID)
+			}
+			return ids
+		}
+
+		t.Run("keep the checklists of a playbook run", func(t *testing.T) {
+			actual, err := playbookRunStore.GetPlaybookRun(first.ID)
+			require.NoError(t, err)
+			require.Equal(t, first.Checklists, actual.Checklists)
+		})
+
+		t.Run("filter by assignee and state, sorted by due date", func(t *testing.T) {
+			items, err := playbookRunStore.GetChecklistItems(requesterInfo, app.ChecklistItemFilterOptions{
+				TeamID:         teamID,
+				AssigneeID:     assigneeID,
+				States:         []string{app.ChecklistItemStateOpen, app.ChecklistItemStateInProgress},
+				ActiveRunsOnly: true,
+				Sort:           app.SortByDueAt,
+			})
+			require.NoError(t, err)
+			require.Equal(t, []app.PlaybookRunChecklistItem{
+				{
+					ChecklistItem:   second.Checklists[0].Items[0],
+					PlaybookRunID:   second.ID,
+					PlaybookRunName: second.Name,
+					TeamID:          teamID,
+					ChannelID:       second.ChannelID,
+					ChecklistNum:    0,
+					ChecklistTitle:  second.Checklists[0].Title,
+					ItemNum:         0,
+				},
+				{
+					ChecklistItem:   first.Checklists[0].Items[1],
+					PlaybookRunID:   first.ID,
+					PlaybookRunName: first.Name,
+					TeamID:          teamID,
+					ChannelID:       first.ChannelID,
+					ChecklistNum:    0,
+					ChecklistTitle:  first.Checklists[0].Title,
+					ItemNum:         1,
+				},
+			}, items)
+		})
+
+		t.Run("sorted by assignment", func(t *testing.T) {
+			items, err := playbookRunStore.GetChecklistItems(requesterInfo, app.ChecklistItemFilterOptions{
+				TeamID:         teamID,
+				AssigneeID:     assigneeID,
+				ActiveRunsOnly: true,
+				Sort:           app.SortByAssignedAt,
+				Direction:      app.DirectionDesc,
+			})
+			require.NoError(t, err)
+			require.Equal(t, []string{
+				second.Checklists[0].Items[0].ID,
+				first.Checklists[0].Items[1].ID,
+				first.Checklists[1].Items[0].ID,
+			}, itemIDs(items))
+		})
+
+		t.Run("include ended playbook runs", func(t *testing.T) {
+			items, err := playbookRunStore.GetChecklistItems(requesterInfo, app.ChecklistItemFilterOptions{
+				TeamID:         teamID,
+				PlaybookRunIDs: []string{ended.ID},
+			})
+			require.NoError(t, err)
+			require.Equal(t, []string{ended.Checklists[0].Items[0].ID}, itemIDs(items))
+		})
+
+		t.Run("updates replace the checklists", func(t *testing.T) {
+			updated, err := playbookRunStore.GetPlaybookRun(second.ID)
+			require.NoError(t, err)
+			updated.Checklists[0].Items = nil
+			require.NoError(t, playbookRunStore.UpdatePlaybookRun(updated))
+
+			items, err := playbookRunStore.GetChecklistItems(requesterInfo, app.ChecklistItemFilterOptions{
+				TeamID:         teamID,
+				PlaybookRunIDs: []string{second.ID},
+			})
+			require.NoError(t, err)
+			require.Empty(t, items)
+		})
+	}
+}
+
+func TestPopulateChecklistIDs(t *testing.T) {
+	itemID := model.NewId()
+	checklists := []app.Checklist{
+		{ID: "checklist", Items: []app.ChecklistItem{{ID: itemID}, {}}},
+		{ID: "checklist", Items: []app.ChecklistItem{{ID: itemID}}},
+	}
+
+	populated := populateChecklistIDs(checklists)
+
+	require.Equal(t, "checklist", populated[0].ID)
+	require.NotEqual(t, "checklist", populated[1].ID)
+	require.Equal(t, itemID, populated[0].Items[0].ID)
+	require.NotEmpty(t, populated[0].Items[1].ID)
+	require.NotEqual(t, itemID, populated[1].Items[0].ID)
+	require.Equal(t, itemID, checklists[1].Items[0].ID, "the given checklists are left unchanged")
+}
+
+func TestInboundWebhookEvents(t *testing.T) {
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		_, store := setupSQLStore(t, db)
+		playbookRunStore := setupPlaybookRunStore(t, db)
+		setupChannelsTable(t, db)
+
+		playbookRun := NewBuilder(t).ToPlaybookRun()
+		returned, err := playbookRunStore.CreatePlaybookRun(playbookRun)
+		require.NoError(t, err)
+		createPlaybookRunChannel(t, store, playbookRun)
+
+		otherPlaybookRun := NewBuilder(t).ToPlaybookRun()
+		otherReturned, err := playbookRunStore.CreatePlaybookRun(otherPlaybookRun)
+		require.NoError(t, err)
+		createPlaybookRunChannel(t, store, otherPlaybookRun)
+
+		t.Run("record an event once per playbook run", func(t *testing.T) {
+			err = playbookRunStore.CreateInboundWebhookEvent(returned.ID, "event-1")
+			require.NoError(t, err)
+
+			err = playbookRunStore.CreateInboundWebhookEvent(returned.ID, "event-1")
+			require.ErrorIs(t, err, app.ErrDuplicateEntry)
+
+			err = playbookRunStore.CreateInboundWebhookEvent(otherReturned.ID, "event-1")
+			require.NoError(t, err)
+		})
+
+		t.Run("record a forgotten event again", func(t *testing.T) {
+			err = playbookRunStore.CreateInboundWebhookEvent(returned.ID, "event-2")
+			require.NoError(t, err)
+
+			err = playbookRunStore.DeleteInboundWebhookEvent(returned.ID, "event-2")
+			require.NoError(t, err)
+
+			err = playbookRunStore.CreateInboundWebhookEvent(returned.ID, "event-2")
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestGetOverdueStatusUpdateRuns(t *testing.T) {
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		_, store := setupSQLStore(t, db)
+		playbookRunStore := setupPlaybookRunStore(t, db)
+		setupChannelsTable(t, db)
+		setupChannelMembersTable(t, db)
+
+		userID := model.NewId()
+		now := model.GetMillis()
+
+		newRun := func(lastStatusUpdateAt int64, reminder time.Duration) *PlaybookRunBuilder {
+			builder := NewBuilder(t)
+			builder.playbookRun.LastStatusUpdateAt = lastStatusUpdateAt
+			builder.playbookRun.PreviousReminder = reminder
+			return builder
+		}
+
+		owned := newRun(now-2*time.Hour.Milliseconds(), time.Hour).WithOwnerUserID(userID).ToPlaybookRun()
+		member := newRun(now-3*time.Hour.Milliseconds(), time.Hour).ToPlaybookRun()
+		notYetDue := newRun(now-30*time.Minute.Milliseconds(), time.Hour).WithOwnerUserID(userID).ToPlaybookRun()
+		noReminder := newRun(now-2*time.Hour.Milliseconds(), 0).WithOwnerUserID(userID).ToPlaybookRun()
+		ended := newRun(now-2*time.Hour.Milliseconds(), time.Hour).WithOwnerUserID(userID).WithCurrentStatus("Resolved").ToPlaybookRun()
+		someoneElses := newRun(now-2*time.Hour.Milliseconds(), time.Hour).ToPlaybookRun()
+
+		for _, playbookRun := range []*app.PlaybookRun{owned, member, notYetDue, noReminder, ended, someoneElses} {
+			_, err := playbookRunStore.CreatePlaybookRun(playbookRun)
+			require.NoError(t, err)
+			createPlaybookRunChannel(t, store, playbookRun)
+		}
+		addUsersToChannels(t, store, []userInfo{{ID: userID}}, []string{member.ChannelID})
+
+		runs, err := playbookRunStore.GetOverdueStatusUpdateRuns(userID, now)
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		require.Equal(t, member.ID, runs[0].PlaybookRunID)
+		require.Equal(t, owned.ID, runs[1].PlaybookRunID)
+		require.Equal(t, owned.TeamID, runs[1].TeamID)
+		require.Equal(t, now-time.Hour.Milliseconds(), runs[1].StatusUpdateDueAt())
+	}
+}
+
+func TestUserInfo(t *testing.T) {
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		playbookRunStore := setupPlaybookRunStore(t, db)
+
+		userID := model.NewId()
+
+		_, err := playbookRunStore.GetUserInfo(userID)
+		require.ErrorIs(t, err, app.ErrNotFound)
+
+		err = playbookRunStore.UpsertUserInfo(app.UserInfo{ID: userID, DigestEnabled: true})
+		require.NoError(t, err)
+
+		info, err := playbookRunStore.GetUserInfo(userID)
+		require.NoError(t, err)
+		require.Equal(t, app.UserInfo{ID: userID, DigestEnabled: true}, info)
+
+		err = playbookRunStore.UpsertUserInfo(app.UserInfo{ID: userID, DigestEnabled: true, LastDigestAt: 1000})
+		require.NoError(t, err)
+
+		info, err = playbookRunStore.GetUserInfo(userID)
+		require.NoError(t, err)
+		require.Equal(t, app.UserInfo{ID: userID, DigestEnabled: true, LastDigestAt: 1000}, info)
+	}
+}
+
 func TestGetOwners(t *testing.T) {
 	team1id := model.NewId()
 	team2id := model.NewId()
@@ -2006,12 +2421,45 @@ func (ib *PlaybookRunBuilder) WithChannel(channel *model.Channel) *PlaybookRunBu
 	return ib
 }
 
+func (ib *PlaybookRunBuilder) WithSeverity(levels []string, severity string) *PlaybookRunBuilder {
+	ib.playbookRun.SeverityLevels = levels
+	ib.playbookRun.Severity = severity
+
+	return ib
+}
+
+func (ib *PlaybookRunBuilder) WithCustomFieldValues(fields []app.CustomField, values app.CustomFieldValues) *PlaybookRunBuilder {
+	ib.playbookRun.CustomFields = fields
+	ib.playbookRun.CustomFieldValues = values
+
+	return ib
+}
+
+func (ib *PlaybookRunBuilder) WithWebhookSubscriptions(subscriptions []app.WebhookSubscription) *PlaybookRunBuilder {
+	ib.playbookRun.WebhookSubscriptions = subscriptions
+
+	return ib
+}
+
+func (ib *PlaybookRunBuilder) WithReminderEscalation(escalation app.ReminderEscalation, reminderCount int) *PlaybookRunBuilder {
+	ib.playbookRun.ReminderEscalation = escalation
+	ib.playbookRun.ReminderCount = reminderCount
+
+	return ib
+}
+
 func (ib *PlaybookRunBuilder) WithPlaybookID(id string) *PlaybookRunBuilder {
 	ib.playbookRun.PlaybookID = id
 
 	return ib
 }
 
+func (ib *PlaybookRunBuilder) WithAlertFingerprint(fingerprint string) *PlaybookRunBuilder {
+	ib.playbookRun.AlertFingerprint = fingerprint
+
+	return ib
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -965,6 +965,133 @@ func TestGetPlaybooksForTeam(t *testing.T) {
 	}
 }
 
+func TestGetPlaybooksForTeamFilters(t *testing.T) {
+	team1id := model.NewId()
+	team2id := model.NewId()
+
+	jon := userInfo{
+		ID:   model.NewId(),
+		Name: "jon",
+	}
+
+	outage := NewPBBuilder().
+		WithTitle("Database outage").
+		WithTeamID(team1id).
+		WithKeywords([]string{"database"}).
+		WithMembers([]userInfo{jon}).
+		WithCreatorID(jon.ID).
+		ToPlaybook()
+
+	breach := NewPBBuilder().
+		WithTitle("Security breach").
+		WithDescription("When 100% of the data leaked").
+		WithTeamID(team1id).
+		ToPlaybook()
+
+	archived := NewPBBuilder().
+		WithTitle("Old outage").
+		WithTeamID(team1id).
+		ToPlaybook()
+
+	otherTeam := NewPBBuilder().
+		WithTitle("Network outage").
+		WithTeamID(team2id).
+		ToPlaybook()
+
+	enabled := true
+	disabled := false
+
+	tests := []struct {
+		name     string
+		options  app.PlaybookFilterOptions
+		expected []string
+	}{
+		{
+			name:     "no filters",
+			options:  app.PlaybookFilterOptions{},
+			expected: []string{"Database outage", "Security breach"},
+		},
+		{
+			name:     "search term in title, ignoring case",
+			options:  app.PlaybookFilterOptions{SearchTerm: "OUTAGE"},
+			expected: []string{"Database outage"},
+		},
+		{
+			name:     "search term in description",
+			options:  app.PlaybookFilterOptions{SearchTerm: "data leak"},
+			expected: []string{"Security breach"},
+		},
+		{
+			name:     "search term with wildcards matched literally",
+			options:  app.PlaybookFilterOptions{SearchTerm: "0%"},
+			expected: []string{"Security breach"},
+		},
+		{
+			name:     "member",
+			options:  app.PlaybookFilterOptions{MemberID: jon.ID},
+			expected: []string{"Database outage"},
+		},
+		{
+			name:     "creator",
+			options:  app.PlaybookFilterOptions{CreatorID: jon.ID},
+			expected: []string{"Database outage"},
+		},
+		{
+			name:     "keywords enabled",
+			options:  app.PlaybookFilterOptions{KeywordsEnabled: &enabled},
+			expected: []string{"Database outage"},
+		},
+		{
+			name:     "keywords disabled",
+			options:  app.PlaybookFilterOptions{KeywordsEnabled: &disabled},
+			expected: []string{"Security breach"},
+		},
+		{
+			name:     "include archived",
+			options:  app.PlaybookFilterOptions{IncludeArchived: true},
+			expected: []string{"Database outage", "Old outage", "Security breach"},
+		},
+		{
+			name:     "include archived with search term",
+			options:  app.PlaybookFilterOptions{IncludeArchived: true, SearchTerm: "outage"},
+			expected: []string{"Database outage", "Old outage"},
+		},
+	}
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		playbookStore := setupPlaybookStore(t, db)
+
+		_, err := playbookStore.Create(outage)
+		require.NoError(t, err)
+		_, err = playbookStore.Create(breach)
+		require.NoError(t, err)
+		archivedID, err := playbookStore.Create(archived)
+		require.NoError(t, err)
+		require.NoError(t, playbookStore.Delete(archivedID))
+		_, err = playbookStore.Create(otherTeam)
+		require.NoError(t, err)
+
+		for _, testCase := range tests {
+			t.Run(driverName+" - "+testCase.name, func(t *testing.T) {
+				options := testCase.options
+				options.Sort = app.SortByTitle
+				options.PerPage = 1000
+
+				actual, err := playbookStore.GetPlaybooksForTeam(app.RequesterInfo{UserID: jon.ID, TeamID: team1id}, team1id, options)
+				require.NoError(t, err)
+
+				titles := []string{}
+				for _, playbook := range actual.Items {
+					titles = append(titles, playbook.Title)
+				}
+				require.Equal(t, testCase.expected, titles)
+				require.Equal(t, len(testCase.expected), actual.TotalCount)
+			})
+		}
+	}
+}
+
 func TestUpdatePlaybook(t *testing.T) {
 	bob := userInfo{
 		ID:   model.NewId(),
@@ -1171,6 +1298,165 @@ func TestUpdatePlaybook(t *testing.T) {
 	}
 }
 
+func TestPlaybookRevisions(t *testing.T) {
+	team1id := model.NewId()
+
+	andrew := userInfo{
+		ID:   model.NewId(),
+		Name: "Andrew",
+	}
+
+	pb := NewPBBuilder().
+		WithTitle("playbook").
+		WithTeamID(team1id).
+		WithCreateAt(500).
+		WithChecklists([]int{1, 2}).
+		WithMembers([]userInfo{andrew}).
+		ToPlaybook()
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		playbookStore := setupPlaybookStore(t, db)
+
+		t.Run(driverName+" - save and get revisions", func(t *testing.T) {
+			created := pb.Clone()
+			created.Revision = 1
+			id, err := playbookStore.Create(created)
+			require.NoError(t, err)
+			created.ID = id
+
+			err = playbookStore.CreateRevision(app.PlaybookRevision{
+				PlaybookID: id,
+				Revision:   1,
+				UserID:     andrew.ID,
+				CreateAt:   500,
+				Changes:    []app.PlaybookChange{},
+				Playbook:   &created,
+			})
+			require.NoError(t, err)
+
+			updated := created.Clone()
+			updated.Title = "renamed playbook"
+			updated.Revision = 2
+			changes := []app.PlaybookChange{{Field: "title", Old: []byte(`"playbook"`), New: []byte(`"renamed playbook"`)}}
+			err = playbookStore.UpdateWithRevision(updated, app.PlaybookRevision{
+				PlaybookID: id,
+				Revision:   2,
+				UserID:     andrew.ID,
+				CreateAt:   600,
+				Changes:    changes,
+				Playbook:   &updated,
+			})
+			require.NoError(t, err)
+
+			actual, err := playbookStore.Get(id)
+			require.NoError(t, err)
+			require.Equal(t, int64(2), actual.Revision)
+			require.Equal(t, "renamed playbook", actual.Title)
+
+			revisions, err := playbookStore.GetRevisions(id)
+			require.NoError(t, err)
+			require.Len(t, revisions, 2)
+			require.Equal(t, int64(2), revisions[0].Revision)
+			require.Equal(t, int64(1), revisions[1].Revision)
+			require.Equal(t, changes, revisions[0].Changes)
+			require.Nil(t, revisions[0].Playbook)
+
+			revision, err := playbookStore.GetRevision(id, 1)
+			require.NoError(t, err)
+			require.Equal(t, andrew.ID, revision.UserID)
+			require.Equal(t, int64(500), revision.CreateAt)
+			require.NotNil(t, revision.Playbook)
+			require.Equal(t, "playbook", revision.Playbook.Title)
+		})
+
+		t.Run(driverName+" - concurrent update", func(t *testing.T) {
+			created := pb.Clone()
+			created.Revision = 1
+			id, err := playbookStore.Create(created)
+			require.NoError(t, err)
+			created.ID = id
+
+			revision := app.PlaybookRevision{PlaybookID: id, Revision: 1, CreateAt: 500, Playbook: &created}
+			err = playbookStore.CreateRevision(revision)
+			require.NoError(t, err)
+
+			updated := created.Clone()
+			updated.Title = "renamed playbook"
+			err = playbookStore.UpdateWithRevision(updated, revision)
+			require.True(t, errors.Is(err, app.ErrPlaybookRevisionConflict))
+
+			actual, err := playbookStore.Get(id)
+			require.NoError(t, err)
+			require.Equal(t, "playbook", actual.Title)
+		})
+
+		t.Run(driverName+" - revision not found", func(t *testing.T) {
+			_, err := playbookStore.GetRevision(model.NewId(), 1)
+			require.True(t, errors.Is(err, app.ErrNotFound))
+		})
+	}
+}
+
+func TestGetSyncedPlaybookIDs(t *testing.T) {
+	team1id := model.NewId()
+	team2id := model.NewId()
+
+	synced := NewPBBuilder().
+		WithTitle("synced playbook").
+		WithTeamID(team1id).
+		ToPlaybook()
+	synced.SyncPath = "outage.yaml"
+
+	syncedDeleted := NewPBBuilder().
+		WithTitle("deleted synced playbook").
+		WithTeamID(team1id).
+		ToPlaybook()
+	syncedDeleted.SyncPath = "removed.yaml"
+
+	syncedOtherTeam := NewPBBuilder().
+		WithTitle("synced playbook of another team").
+		WithTeamID(team2id).
+		ToPlaybook()
+	syncedOtherTeam.SyncPath = "outage.yaml"
+
+	notSynced := NewPBBuilder().
+		WithTitle("playbook").
+		WithTeamID(team1id).
+		ToPlaybook()
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		playbookStore := setupPlaybookStore(t, db)
+
+		syncedID, err := playbookStore.Create(synced)
+		require.NoError(t, err)
+		deletedID, err := playbookStore.Create(syncedDeleted)
+		require.NoError(t, err)
+		require.NoError(t, playbookStore.Delete(deletedID))
+		_, err = playbookStore.Create(syncedOtherTeam)
+		require.NoError(t, err)
+		_, err = playbookStore.Create(notSynced)
+		require.NoError(t, err)
+
+		t.Run(driverName+" - synced playbooks of the team", func(t *testing.T) {
+			playbookIDs, err := playbookStore.GetSyncedPlaybookIDs(team1id)
+			require.NoError(t, err)
+			require.Equal(t, map[string]string{"outage.yaml": syncedID}, playbookIDs)
+
+			actual, err := playbookStore.Get(syncedID)
+			require.NoError(t, err)
+			require.Equal(t, "outage.yaml", actual.SyncPath)
+		})
+
+		t.Run(driverName+" - team without synced playbooks", func(t *testing.T) {
+			playbookIDs, err := playbookStore.GetSyncedPlaybookIDs(model.NewId())
+			require.NoError(t, err)
+			require.Empty(t, playbookIDs)
+		})
+	}
+}
+
 func TestDeletePlaybook(t *testing.T) {
 	team1id := model.NewId()
 
@@ -1228,6 +1514,52 @@ func TestDeletePlaybook(t *testing.T) {
 	}
 }
 
+func TestUndeletePlaybook(t *testing.T) {
+	team1id := model.NewId()
+
+	pb := NewPBBuilder().
+		WithTitle("playbook").
+		WithTeamID(team1id).
+		WithCreateAt(600).
+		ToPlaybook()
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		playbookStore := setupPlaybookStore(t, db)
+
+		t.Run(driverName+" - id empty", func(t *testing.T) {
+			err := playbookStore.Undelete("")
+			require.Error(t, err)
+			require.EqualError(t, err, "ID cannot be empty")
+		})
+
+		t.Run(driverName+" - delete and undelete playbook", func(t *testing.T) {
+			id, err := playbookStore.Create(pb)
+			require.NoError(t, err)
+
+			err = playbookStore.Delete(id)
+			require.NoError(t, err)
+
+			numPlaybooks, err := playbookStore.GetNumPlaybooksForTeam(team1id)
+			require.NoError(t, err)
+			require.Zero(t, numPlaybooks)
+
+			before := model.GetMillis()
+			err = playbookStore.Undelete(id)
+			require.NoError(t, err)
+
+			actual, err := playbookStore.Get(id)
+			require.NoError(t, err)
+			require.Zero(t, actual.DeleteAt)
+			require.GreaterOrEqual(t, actual.UpdateAt, before)
+
+			numPlaybooks, err = playbookStore.GetNumPlaybooksForTeam(team1id)
+			require.NoError(t, err)
+			require.Equal(t, 1, numPlaybooks)
+		})
+	}
+}
+
 func TestGetPlaybooksForKeywords(t *testing.T) {
 	team1id := model.NewId()
 	team2id := model.NewId()
@@ -1663,6 +1995,12 @@ func (p *PlaybookBuilder) WithCreatePublic(public bool) *PlaybookBuilder {
 	return p
 }
 
+func (p *PlaybookBuilder) WithCreatorID(id string) *PlaybookBuilder {
+	p.CreatorID = id
+
+	return p
+}
+
 func (p *PlaybookBuilder) WithCreateAt(createAt int64) *PlaybookBuilder {
 	p.CreateAt = createAt
 
@@ -8,6 +8,7 @@ import (
 	"github.com/pkg/errors"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/app"
 	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/bot"
 	"github.com/mattermost/mattermost-server/v5/model"
 )
@@ -48,7 +49,7 @@ func (s *StatsStore) TotalInProgressPlaybookRuns(filters *StatsFilters) int {
 	query := s.store.builder.
 		Select("COUNT(i.ID)").
 		From("IR_Incident as i").
-		Where("i.EndAt = 0")
+		Where(sq.Eq{"i.CurrentStatusResolved": false})
 
 	query = applyFilters(query, filters)
 
@@ -66,7 +67,7 @@ func (s *StatsStore) TotalActiveParticipants(filters *StatsFilters) int {
 		Select("COUNT(DISTINCT cm.UserId)").
 		From("ChannelMembers as cm").
 		Join("IR_Incident AS i ON i.ChannelId = cm.ChannelId").
-		Where("i.EndAt = 0")
+		Where(sq.Eq{"i.CurrentStatusResolved": false})
 
 	query = applyFilters(query, filters)
 
@@ -114,7 +115,7 @@ func (s *StatsStore) MovingWindowQueryActive(query sq.SelectBuilder, numDays int
 	for i := 0; i < numDays; i++ {
 		modifiedQuery := query.Where(
 			sq.Expr(
-				`i.CreateAt < ? AND (i.EndAt > ? OR i.EndAt = 0)`,
+				`i.CreateAt < ? AND (i.EndAt > ? OR i.CurrentStatusResolved = FALSE)`,
 				now-(int64(i)*dayInMS),
 				now-(int64(i+1)*dayInMS),
 			),
@@ -207,7 +208,7 @@ func (s *StatsStore) ActiveRunsPerDayLastXDays(x int, filters *StatsFilters) ([]
                 CAST(
                      SUM(
                          CASE
-                             WHEN (i.EndAt >= ? OR i.EndAt = 0) AND i.CreateAt < ?
+                             WHEN (i.EndAt >= ? OR i.CurrentStatusResolved = FALSE) AND i.CreateAt < ?
                                  THEN 1
                              ELSE 0
                          END)
@@ -216,7 +217,7 @@ func (s *StatsStore) ActiveRunsPerDayLastXDays(x int, filters *StatsFilters) ([]
 		} else {
 			q = q.Column(`
                 SUM(CASE
-                        WHEN (i.EndAt >= ? OR i.EndAt = 0) AND i.CreateAt < ?
+                        WHEN (i.EndAt >= ? OR i.CurrentStatusResolved = FALSE) AND i.CreateAt < ?
                             THEN 1
                         ELSE 0
                     END)
@@ -269,7 +270,7 @@ func (s *StatsStore) ActiveParticipantsPerDayLastXDays(x int, filters *StatsFilt
 		q = q.Column(`
                 COUNT(DISTINCT
                       (CASE
-                           WHEN (i.EndAt >= ? OR i.EndAt = 0) AND
+                           WHEN (i.EndAt >= ? OR i.CurrentStatusResolved = FALSE) AND
                                 i.CreateAt < ? AND
                                 (cmh.LeaveTime >= ? OR cmh.LeaveTime is NULL) AND
                                 cmh.JoinTime < ?
@@ -302,6 +303,103 @@ func (s *StatsStore) ActiveParticipantsPerDayLastXDays(x int, filters *StatsFilt
 	return counts, daysAsStrings
 }
 
+// ChecklistItemSkipRate is the number of runs with a checklist item, by the titles of its
+// checklist and of itself, and how many of them skipped it.
+type ChecklistItemSkipRate struct {
+	ChecklistTitle string  `json:"checklist_title"`
+	ItemTitle      string  `json:"item_title"`
+	Runs           int     `json:"runs"`
+	Skipped        int     `json:"skipped"`
+	SkipRate       float64 `json:"skip_rate"`
+}
+
+// ChecklistItemSkipRates returns how often each checklist item of the filtered runs was skipped,
+// in the order the items first appear in the runs.
+func (s *StatsStore) ChecklistItemSkipRates(filters *StatsFilters) []ChecklistItemSkipRate {
+	query := s.store.builder.
+		Select("ci.IncidentID", "ci.ChecklistID", "cl.Title AS ChecklistTitle", "ci.Title", "ci.State").
+		From("IR_Incident as i").
+		Join("IR_ChecklistItem AS ci ON (ci.IncidentID = i.ID)").
+		Join("IR_Checklist AS cl ON (cl.IncidentID = ci.IncidentID AND cl.ID = ci.ChecklistID)").
+		OrderBy("i.CreateAt", "i.ID", "cl.Position", "ci.Position")
+
+	query = applyFilters(query, filters)
+
+	var rawItems []struct {
+		IncidentID     string
+		ChecklistID    string
+		ChecklistTitle string
+		Title          string
+		State          string
+	}
+	if err := s.store.selectBuilder(s.store.db, &rawItems, query); err != nil {
+		s.log.Warnf("Error retrieving stat checklist item skip rates %w", err)
+		return []ChecklistItemSkipRate{}
+	}
+
+	// The items come sorted by run and then by checklist, so each run's checklists are rebuilt
+	// by appending to the last run and checklist while they stay the same.
+	var runsChecklists [][]app.Checklist
+	lastRunID, lastChecklistID := "", ""
+	for _, rawItem := range rawItems {
+		if rawItem.IncidentID != lastRunID {
+			runsChecklists = append(runsChecklists, nil)
+			lastRunID, lastChecklistID = rawItem.IncidentID, ""
+		}
+
+		checklists := runsChecklists[len(runsChecklists)-1]
+		if rawItem.ChecklistID != lastChecklistID {
+			checklists = append(checklists, app.Checklist{ID: rawItem.ChecklistID, Title: rawItem.ChecklistTitle})
+			lastChecklistID = rawItem.ChecklistID
+		}
+
+		checklist := &checklists[len(checklists)-1]
+		checklist.Items = append(checklist.Items, app.ChecklistItem{Title: rawItem.Title, State: rawItem.State})
+		runsChecklists[len(runsChecklists)-1] = checklists
+	}
+
+	return checklistItemSkipRates(runsChecklists)
+}
+
+// checklistItemSkipRates counts, for each checklist item of the runs' checklists, the runs it
+// appears in and the runs that skipped it. Items are told apart by their and their checklist's
+// titles, since the runs of a playbook may have added, removed or reordered items.
+func checklistItemSkipRates(runsChecklists [][]app.Checklist) []ChecklistItemSkipRate {
+	type key struct{ checklist, item string }
+
+	rates := []ChecklistItemSkipRate{}
+	indices := make(map[key]int)
+	for _, checklists := range runsChecklists {
+		seen := make(map[key]bool)
+		for _, checklist := range checklists {
+			for _, item := range checklist.Items {
+				k := key{checklist.Title, item.Title}
+				if seen[k] {
+					continue
+				}
+				seen[k] = true
+
+				index, ok := indices[k]
+				if !ok {
+					index = len(rates)
+					indices[k] = index
+					rates = append(rates, ChecklistItemSkipRate{ChecklistTitle: checklist.Title, ItemTitle: item.Title})
+				}
+				rates[index].Runs++
+				if item.State == app.ChecklistItemStateSkipped {
+					rates[index].Skipped++
+				}
+			}
+		}
+	}
+
+	for i := range rates {
+		rates[i].SkipRate = float64(rates[i].Skipped) / float64(rates[i].Runs)
+	}
+
+	return rates
+}
+
 func (s *StatsStore) performQueryForXCols(q sq.SelectBuilder, x int) ([]int, error) {
 	sqlString, args, err := q.ToSql()
 	if err != nil {
@@ -258,3 +258,36 @@ func TestTotalReportedPlaybookRuns(t *testing.T) {
 		})*/
 	}
 }
+
+func TestChecklistItemSkipRates(t *testing.T) {
+	rates := checklistItemSkipRates([][]app.Checklist{
+		{
+			{Title: "Triage", Items: []app.ChecklistItem{
+				{Title: "Page", State: app.ChecklistItemStateSkipped},
+				{Title: "Identify", State: app.ChecklistItemStateClosed},
+			}},
+		},
+		{
+			{Title: "Triage", Items: []app.ChecklistItem{
+				{Title: "Page", State: app.ChecklistItemStateClosed},
+				{Title: "Identify", State: app.ChecklistItemStateSkipped},
+			}},
+			{Title: "Resolve", Items: []app.ChecklistItem{
+				{Title: "Page", State: app.ChecklistItemStateSkipped},
+			}},
+		},
+		{
+			{Title: "Triage", Items: []app.ChecklistItem{
+				{Title: "Page", State: app.ChecklistItemStateSkipped},
+			}},
+		},
+	})
+
+	require.Equal(t, []ChecklistItemSkipRate{
+		{ChecklistTitle: "Triage", ItemTitle: "Page", Runs: 3, Skipped: 2, SkipRate: 2.0 / 3},
+		{ChecklistTitle: "Triage", ItemTitle: "Identify", Runs: 2, Skipped: 1, SkipRate: 0.5},
+		{ChecklistTitle: "Resolve", ItemTitle: "Page", Runs: 1, Skipped: 1, SkipRate: 1},
+	}, rates)
+
+	require.Empty(t, checklistItemSkipRates(nil))
+}
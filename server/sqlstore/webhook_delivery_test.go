@@ -0,0 +1,93 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-plugin-incident-collaboration/server/app"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaybookRunStore_WebhookDeliveries(t *testing.T) {
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		iStore := setupPlaybookRunStore(t, db)
+		_, store := setupSQLStore(t, db)
+		setupChannelsTable(t, db)
+		setupPostsTable(t, db)
+
+		t.Run("create, update and list webhook deliveries", func(t *testing.T) {
+			createAt := model.GetMillis()
+			inc01 := NewBuilder(nil).
+				WithName("playbook run 1").
+				WithCreateAt(createAt).
+				ToPlaybookRun()
+
+			playbookRun, err := iStore.CreatePlaybookRun(inc01)
+			require.NoError(t, err)
+
+			createPlaybookRunChannel(t, store, inc01)
+
+			created := &app.WebhookDelivery{
+				PlaybookRunID: playbookRun.ID,
+				EventType:     app.WebhookEventPlaybookRunCreated,
+				URL:           "https://example.com/created",
+				Payload:       `{"id": "created"}`,
+				Status:        app.WebhookDeliveryPending,
+				NextAttemptAt: createAt,
+				CreateAt:      createAt,
+				UpdateAt:      createAt,
+			}
+			_, err = iStore.CreateWebhookDelivery(created)
+			require.NoError(t, err)
+			require.NotEmpty(t, created.ID)
+
+			statusUpdated := &app.WebhookDelivery{
+				PlaybookRunID: playbookRun.ID,
+				EventType:     app.WebhookEventStatusUpdated,
+				URL:           "https://example.com/updated",
+				Payload:       `{"id": "updated"}`,
+				Status:        app.WebhookDeliveryPending,
+				NextAttemptAt: createAt + 1,
+				CreateAt:      createAt + 1,
+				UpdateAt:      createAt + 1,
+			}
+			_, err = iStore.CreateWebhookDelivery(statusUpdated)
+			require.NoError(t, err)
+
+			statusUpdated.Status = app.WebhookDeliveryFailed
+			statusUpdated.Attempts = app.MaxWebhookDeliveryAttempts
+			statusUpdated.LastStatusCode = 503
+			statusUpdated.LastLatencyMs = 120
+			statusUpdated.LastError = "response code is 503; expected a status code in the 2xx range"
+			statusUpdated.NextAttemptAt = 0
+			statusUpdated.UpdateAt = createAt + 2
+			err = iStore.UpdateWebhookDelivery(statusUpdated)
+			require.NoError(t, err)
+
+			retDelivery, err := iStore.GetWebhookDelivery(statusUpdated.ID)
+			require.NoError(t, err)
+			require.Equal(t, *statusUpdated, *retDelivery)
+
+			deliveries, err := iStore.GetWebhookDeliveries(playbookRun.ID, "")
+			require.NoError(t, err)
+			require.Equal(t, []app.WebhookDelivery{*statusUpdated, *created}, deliveries)
+
+			deliveries, err = iStore.GetWebhookDeliveries(playbookRun.ID, app.WebhookDeliveryFailed)
+			require.NoError(t, err)
+			require.Equal(t, []app.WebhookDelivery{*statusUpdated}, deliveries)
+
+			deliveries, err = iStore.GetWebhookDeliveries(model.NewId(), "")
+			require.NoError(t, err)
+			require.Empty(t, deliveries)
+		})
+
+		t.Run("get unknown webhook delivery", func(t *testing.T) {
+			_, err := iStore.GetWebhookDelivery(model.NewId())
+			require.ErrorIs(t, err, app.ErrNotFound)
+		})
+	}
+}
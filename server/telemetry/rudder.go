@@ -44,9 +44,10 @@ const (
 	actionSetAssigneeForTask  = "set_assignee_for_task"
 	actionRunTaskSlashCommand = "run_task_slash_command"
 
-	eventPlaybook = "playbook"
-	actionUpdate  = "update"
-	actionDelete  = "delete"
+	eventPlaybook  = "playbook"
+	actionUpdate   = "update"
+	actionDelete   = "delete"
+	actionUndelete = "undelete"
 
 	eventFrontend = "frontend"
 
@@ -338,6 +339,13 @@ func (t *RudderTelemetry) DeletePlaybook(playbook app.Playbook, userID string) {
 	t.track(eventPlaybook, properties)
 }
 
+// UndeletePlaybook tracks the restoration of a deleted playbook.
+func (t *RudderTelemetry) UndeletePlaybook(playbook app.Playbook, userID string) {
+	properties := playbookProperties(playbook, userID)
+	properties["Action"] = actionUndelete
+	t.track(eventPlaybook, properties)
+}
+
 // FrontendTelemetryForPlaybook tracks an event originating from the frontend
 func (t *RudderTelemetry) FrontendTelemetryForPlaybook(playbook app.Playbook, userID, action string) {
 	properties := playbookProperties(playbook, userID)
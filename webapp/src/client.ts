@@ -20,6 +20,8 @@ import {
     isPlaybookRun,
     isMetadata,
     Metadata,
+    FetchTasksParams,
+    Task,
 } from 'src/types/playbook_run';
 
 import {setTriggerId} from 'src/actions';
@@ -27,6 +29,7 @@ import {OwnerInfo} from 'src/types/backstage';
 import {
     ChecklistItem,
     ChecklistItemState,
+    ChecklistOperation,
     FetchPlaybooksNoChecklistReturn,
     Playbook,
     PlaybookNoChecklist,
@@ -202,6 +205,16 @@ export async function fetchOwnersInTeam(teamId: string): Promise<OwnerInfo[]> {
     return data as OwnerInfo[];
 }
 
+export async function fetchTasks(params: FetchTasksParams): Promise<Task[]> {
+    const queryParams = qs.stringify(params, {addQueryPrefix: true, indices: false});
+
+    let data = await doGet(`${apiUrl}/runs/tasks${queryParams}`);
+    if (!data) {
+        data = [];
+    }
+    return data as Task[];
+}
+
 export async function setOwner(playbookRunId: string, ownerId: string) {
     const body = `{"owner_id": "${ownerId}"}`;
     try {
@@ -221,14 +234,44 @@ export async function setAssignee(playbookRunId: string, checklistNum: number, i
     }
 }
 
-export async function setChecklistItemState(playbookRunID: string, checklistNum: number, itemNum: number, newState: ChecklistItemState) {
+export async function setChecklistItemState(playbookRunID: string, checklistNum: number, itemNum: number, newState: ChecklistItemState, override = false) {
     return doPut(`${apiUrl}/runs/${playbookRunID}/checklists/${checklistNum}/item/${itemNum}/state`,
         JSON.stringify({
             new_state: newState,
+            override,
         }),
     );
 }
 
+export async function bulkUpdateChecklists(playbookRunID: string, operations: ChecklistOperation[]) {
+    return doPost(`${apiUrl}/runs/${playbookRunID}/checklists/bulk`,
+        JSON.stringify({operations}),
+    );
+}
+
+export async function skipChecklistItem(playbookRunID: string, checklistNum: number, itemNum: number, reason: string) {
+    return doPut(`${apiUrl}/runs/${playbookRunID}/checklists/${checklistNum}/item/${itemNum}/state`,
+        JSON.stringify({
+            new_state: ChecklistItemState.Skipped,
+            reason,
+        }),
+    );
+}
+
+export async function setChecklistSubItemState(playbookRunID: string, checklistNum: number, itemNum: number, subItemNum: number, newState: ChecklistItemState) {
+    return doPut(`${apiUrl}/runs/${playbookRunID}/checklists/${checklistNum}/item/${itemNum}/subitems/${subItemNum}/state`,
+        JSON.stringify({
+            new_state: newState,
+        }),
+    );
+}
+
+export async function clientAddChecklistItemComment(playbookRunID: string, checklistNum: number, itemNum: number, message: string) {
+    return doPost(`${apiUrl}/runs/${playbookRunID}/checklists/${checklistNum}/item/${itemNum}/comments`,
+        JSON.stringify({message}),
+    );
+}
+
 export async function clientAddChecklistItem(playbookRunID: string, checklistNum: number, checklistItem: ChecklistItem) {
     const data = await doPut(`${apiUrl}/runs/${playbookRunID}/checklists/${checklistNum}/add`,
         JSON.stringify(checklistItem),
@@ -527,6 +527,8 @@ const PlaybookEdit = (props: Props) => {
                     className='mr-4'
                     data-testid='save_playbook'
                     onClick={onSave}
+                    disabled={Boolean(playbook.sync_path)}
+                    title={playbook.sync_path ? `This playbook is synced from ${playbook.sync_path}, change that file instead` : undefined}
                 >
                     <span>
                         {'Save'}
@@ -128,6 +128,10 @@ const tasksCompletedTotal = (checklists: Checklist[]) => {
 
     for (const cl of checklists) {
         for (const item of cl.items) {
+            // Skipped items do not count towards the completion of the run.
+            if (item.state === ChecklistItemState.Skipped) {
+                continue;
+            }
             total++;
             if (item.state === ChecklistItemState.Closed) {
                 completed++;
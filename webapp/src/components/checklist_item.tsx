@@ -2,6 +2,7 @@
 // See LICENSE.txt for license information.
 
 import React, {useRef, useState} from 'react';
+import moment from 'moment';
 import ReactDOM from 'react-dom';
 import {getChannelsNameMapInCurrentTeam} from 'mattermost-redux/selectors/entities/channels';
 import {getCurrentRelativeTeamUrl, getCurrentTeam} from 'mattermost-redux/selectors/entities/teams';
@@ -19,6 +20,7 @@ import {
     clientRemoveChecklistItem, clientRunChecklistItemSlashCommand,
     setAssignee,
     clientEditChecklistItem,
+    setChecklistSubItemState,
 } from 'src/client';
 import Spinner from 'src/components/assets/icons/spinner';
 import {ChecklistItemButton} from 'src/components/checklist_item_input';
@@ -29,7 +31,7 @@ import {formatText, messageHtmlToComponent} from 'src/components/shared';
 import ConfirmModal from 'src/components/widgets/confirmation_modal';
 import {useClickOutsideRef, useProfilesInCurrentChannel, useTimeout} from 'src/hooks';
 import {ChannelNamesMap} from 'src/types/backstage';
-import {ChecklistItem, ChecklistItemState} from 'src/types/playbook';
+import {ChecklistItem, ChecklistItemState, isOverdue} from 'src/types/playbook';
 import TextWithTooltipWhenEllipsis from 'src/components/widgets/text_with_tooltip_when_ellipsis';
 
 import CommandInput from './command_input';
@@ -97,6 +99,57 @@ const ExtrasRow = styled.div`
     }
 `;
 
+interface DueDateProps {
+    overdue: boolean;
+}
+
+const DueDate = styled.div<DueDateProps>`
+    font-size: 12px;
+    white-space: nowrap;
+
+    ${({overdue}) => overdue && `
+        && {
+            color: var(--error-text);
+            border-color: rgba(var(--error-text-color-rgb), 0.16);
+            background: rgba(var(--error-text-color-rgb), 0.08);
+            font-weight: 600;
+        }
+    `}
+`;
+
+const Blocked = styled.div`
+    font-size: 12px;
+    white-space: nowrap;
+    color: rgba(var(--center-channel-color-rgb), 0.64);
+`;
+
+const Comments = styled.div`
+    font-size: 12px;
+    white-space: nowrap;
+    color: rgba(var(--center-channel-color-rgb), 0.64);
+`;
+
+const Skipped = styled.div`
+    font-size: 12px;
+    color: rgba(var(--center-channel-color-rgb), 0.64);
+`;
+
+const SubItems = styled.div`
+    margin: 4px 0 0 32px;
+`;
+
+const SubItem = styled.label`
+    display: flex;
+    align-items: center;
+    margin: 2px 0;
+    font-size: 12px;
+    font-weight: normal;
+
+    input {
+        margin: 0 8px 0 0;
+    }
+`;
+
 const SmallProfile = styled(Profile)`
     > .image {
         width: 16px;
@@ -358,6 +411,9 @@ export const ChecklistItemDetails = (props: ChecklistItemDetailsProps): React.Re
     const assignee_id = props.checklistItem.assignee_id; // to make typescript happy
 
     const title = props.checklistItem.title;
+    const dueAt = props.checklistItem.due_at || 0;
+    const overdue = isOverdue(props.checklistItem, Date.now());
+    const dueAtText = moment(dueAt).calendar(undefined, {sameDay: 'LT'}); //eslint-disable-line no-undefined
 
     const resetAssignee = () => {
         onAssigneeChange();
@@ -447,6 +503,41 @@ export const ChecklistItemDetails = (props: ChecklistItemDetailsProps): React.Re
                         userId={props.checklistItem.assignee_id}
                     />
                     }
+                    {props.checklistItem.blocked &&
+                    <Blocked
+                        title={'This task depends on tasks that are not done yet'}
+                        data-testid='checklist-item-blocked'
+                    >
+                        <i className='icon-lock-outline icon-12'/>
+                        {'Blocked'}
+                    </Blocked>
+                    }
+                    {props.checklistItem.state === ChecklistItemState.Skipped &&
+                    <Skipped
+                        title={'This task was skipped'}
+                        data-testid='checklist-item-skipped'
+                    >
+                        {'Skipped: ' + (props.checklistItem.skip_reason || '')}
+                    </Skipped>
+                    }
+                    {props.checklistItem.comments && props.checklistItem.comments.length > 0 &&
+                    <Comments
+                        title={'Comments on this task'}
+                        data-testid='checklist-item-comments'
+                    >
+                        <i className='icon-message-text-outline icon-12'/>
+                        {props.checklistItem.comments.length}
+                    </Comments>
+                    }
+                    {dueAt !== 0 &&
+                    <DueDate
+                        overdue={overdue}
+                        title={moment(dueAt).format('LLL')}
+                        data-testid='checklist-item-due-date'
+                    >
+                        {(overdue ? 'Overdue since ' : 'Due ') + dueAtText}
+                    </DueDate>
+                    }
                     {
                         props.checklistItem.command !== '' &&
                         <div ref={commandRef}>
@@ -473,6 +564,29 @@ export const ChecklistItemDetails = (props: ChecklistItemDetailsProps): React.Re
                         </div>
                     }
                 </ExtrasRow>
+                {props.checklistItem.sub_items && props.checklistItem.sub_items.length > 0 &&
+                <SubItems data-testid='checklist-item-sub-items'>
+                    {props.checklistItem.sub_items.map((subItem, subItemNum) => (
+                        <SubItem key={subItem.id || subItemNum}>
+                            <input
+                                type='checkbox'
+                                checked={subItem.state === ChecklistItemState.Closed}
+                                onChange={() => setChecklistSubItemState(
+                                    props.playbookRunId,
+                                    props.checklistNum,
+                                    props.itemNum,
+                                    subItemNum,
+                                    subItem.state === ChecklistItemState.Closed ? ChecklistItemState.Open : ChecklistItemState.Closed,
+                                )}
+                            />
+                            {subItem.title}
+                            {subItem.assignee_id &&
+                            <SmallProfile userId={subItem.assignee_id}/>
+                            }
+                        </SubItem>
+                    ))}
+                </SubItems>
+                }
             </ItemContainer>
             <ConfirmModal
                 show={showDeleteConfirm}
@@ -7,7 +7,7 @@ import styled from 'styled-components';
 
 import {getCurrentTeam} from 'mattermost-redux/selectors/entities/teams';
 
-import {PlaybookRun, playbookRunCurrentStatus} from 'src/types/playbook_run';
+import {PlaybookRun, playbookRunIsClosed} from 'src/types/playbook_run';
 
 import {Footer, StyledFooterButton} from 'src/components/rhs/rhs_shared';
 import {updateStatus} from 'src/actions';
@@ -29,7 +29,7 @@ const RHSFooter = (props: Props) => {
     const playbookRun = useSelector(currentPlaybookRun);
 
     let text = 'Update status';
-    if (playbookRunCurrentStatus(props.playbookRun) === 'Archived') {
+    if (playbookRunIsClosed(props.playbookRun)) {
         text = 'Reopen';
     }
 
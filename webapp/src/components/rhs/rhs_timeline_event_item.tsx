@@ -180,6 +180,22 @@ const RHSTimelineEventItem = (props: Props) => {
         summaryTitle = 'Retrospective canceled by ' + props.event.subject_display_name;
         testid = TimelineEventType.CanceledRetrospective;
         break;
+    case TimelineEventType.RunUpdated:
+        iconClass = 'icon icon-pencil-outline';
+        summaryTitle = props.event.subject_display_name + ' ' + props.event.summary;
+        summaryTitle = summaryTitle.replace(/\*\*/g, '"');
+        testid = TimelineEventType.RunUpdated;
+        break;
+    case TimelineEventType.SeverityChanged:
+        iconClass = 'icon icon-alert-outline';
+        summaryTitle = props.event.subject_display_name + ' changed severity from ' + props.event.summary;
+        testid = TimelineEventType.SeverityChanged;
+        break;
+    case TimelineEventType.ReminderEscalated:
+        iconClass = 'icon icon-bell-outline';
+        summaryTitle = props.event.summary;
+        testid = TimelineEventType.ReminderEscalated;
+        break;
     }
 
     return (
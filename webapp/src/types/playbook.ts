@@ -3,6 +3,8 @@
 
 export interface Playbook {
     id?: string;
+    revision?: number;
+    creator_id?: string;
     title: string;
     description: string;
     team_id: string;
@@ -23,6 +25,11 @@ export interface Playbook {
     webhook_on_creation_enabled: boolean;
     webhook_on_status_update_url: string;
     webhook_on_status_update_enabled: boolean;
+
+    // Write-only: always empty when read, and kept on update while has_webhook_secret is true.
+    webhook_secret: string;
+    has_webhook_secret: boolean;
+
     message_on_join: string;
     message_on_join_enabled: boolean;
     retrospective_reminder_interval_seconds: number;
@@ -31,6 +38,90 @@ export interface Playbook {
     signal_any_keywords_enabled: boolean;
     signal_any_keywords: string[];
     categorize_channel_enabled: boolean;
+    severity_levels: string[];
+    default_severity: string;
+    status_workflow: StatusWorkflow;
+    reminder_escalation: ReminderEscalation;
+    custom_fields: CustomField[];
+    webhook_subscriptions: WebhookSubscription[];
+    inbound_webhook: InboundWebhook;
+
+    // Keeps the write-only inbound webhook token on update while its token is empty.
+    has_inbound_webhook_token: boolean;
+
+    // The file the playbook is synced from, if any. Synced playbooks cannot be edited.
+    sync_path?: string;
+}
+
+// An empty list of states means the default workflow is used.
+export interface StatusWorkflow {
+    states: StatusState[];
+}
+
+export interface StatusState {
+    name: string;
+    transitions: string[];
+    resolved: boolean;
+    closed: boolean;
+}
+
+// A step is skipped when its number of missed status update reminders is 0.
+export interface ReminderEscalation {
+    backup_after_missed: number;
+    backup_user_id: string;
+    backup_group_id: string;
+    broadcast_after_missed: number;
+}
+
+export type CustomFieldType = 'text' | 'number' | 'select' | 'multiselect' | 'user' | 'url';
+
+export interface CustomField {
+    id: string;
+    name: string;
+    type: CustomFieldType;
+
+    // The allowed values of select and multiselect fields.
+    options: string[];
+    required: boolean;
+}
+
+// Custom field values keyed by custom field ID; only multiselect fields have more than one value.
+export type CustomFieldValues = Record<string, string[]>;
+
+export type WebhookEventType =
+    'playbook_run_created' |
+    'status_updated' |
+    'owner_changed' |
+    'checklist_item_state_changed' |
+    'checklist_item_assignee_changed' |
+    'timeline_event_added' |
+    'participant_joined' |
+    'participant_left' |
+    'retrospective_published' |
+    'retrospective_canceled';
+
+// Sends the subscribed events of a playbook's runs to each of the URLs.
+export interface WebhookSubscription {
+    events: WebhookEventType[];
+    urls: string[];
+}
+
+export type InboundWebhookFormat = 'generic' | 'alertmanager';
+
+// Lets monitoring systems start runs by posting alerts to /webhooks/playbooks/{id}, and update
+// their status through /webhooks/runs/{id}/status. The fields are dot-separated paths into the
+// alert; empty fields default to the format's paths.
+export interface InboundWebhook {
+    enabled: boolean;
+    token: string;
+    format: InboundWebhookFormat;
+    name_field: string;
+    description_field: string;
+    severity_field: string;
+    fingerprint_field: string;
+
+    // A bot posting the status updates instead of the plugin's bot.
+    service_user_id: string;
 }
 
 export interface PlaybookNoChecklist {
@@ -64,11 +155,13 @@ export enum ChecklistItemState {
     Open = '',
     InProgress = 'in_progress',
     Closed = 'closed',
+    Skipped = 'skipped',
 }
 
 export interface ChecklistItem {
     title: string;
     description: string;
+    skip_reason?: string;
     state: ChecklistItemState;
     state_modified?: number;
     state_modified_post_id?: string;
@@ -77,6 +170,57 @@ export interface ChecklistItem {
     assignee_modified_post_id?: string;
     command: string;
     command_last_run: number;
+    due_at?: number;
+    due_after_seconds?: number;
+    due_relative_to?: '' | 'run' | 'stage';
+    depends_on?: string[];
+    blocked?: boolean;
+    command_trigger?: '' | 'run_start' | 'stage_start' | 'status';
+    command_trigger_status?: string;
+    command_run_as?: '' | 'owner' | 'bot';
+    command_triggered_at?: number;
+    sub_items?: ChecklistSubItem[];
+    comments?: ChecklistItemComment[];
+    comments_post_id?: string;
+}
+
+export interface ChecklistSubItem {
+    id: string;
+    title: string;
+    state: ChecklistItemState;
+    state_modified?: number;
+    assignee_id?: string;
+    assignee_modified?: number;
+}
+
+export interface ChecklistItemComment {
+    post_id: string;
+    user_id: string;
+    message: string;
+    create_at: number;
+}
+
+export interface ChecklistOperation {
+    type: 'set_state' | 'set_assignee' | 'edit' | 'move' | 'add';
+    checklist_num: number;
+    item_num?: number;
+    new_state?: ChecklistItemState;
+    reason?: string;
+    override?: boolean;
+    assignee_id?: string;
+    title?: string;
+    command?: string;
+    description?: string;
+    new_location?: number;
+    item?: ChecklistItem;
+}
+
+export function isDone(item: ChecklistItem): boolean {
+    return item.state === ChecklistItemState.Closed || item.state === ChecklistItemState.Skipped;
+}
+
+export function isOverdue(item: ChecklistItem, now: number): boolean {
+    return Boolean(item.due_at) && (item.due_at as number) <= now && !isDone(item);
 }
 
 export function emptyPlaybook(): Playbook {
@@ -101,6 +245,8 @@ export function emptyPlaybook(): Playbook {
         webhook_on_creation_enabled: false,
         webhook_on_status_update_url: '',
         webhook_on_status_update_enabled: false,
+        webhook_secret: '',
+        has_webhook_secret: false,
         message_on_join: defaultMessageOnJoin,
         message_on_join_enabled: false,
         retrospective_reminder_interval_seconds: 0,
@@ -109,6 +255,28 @@ export function emptyPlaybook(): Playbook {
         signal_any_keywords: [],
         signal_any_keywords_enabled: false,
         categorize_channel_enabled: false,
+        severity_levels: [],
+        default_severity: '',
+        status_workflow: {states: []},
+        reminder_escalation: {
+            backup_after_missed: 0,
+            backup_user_id: '',
+            backup_group_id: '',
+            broadcast_after_missed: 0,
+        },
+        custom_fields: [],
+        webhook_subscriptions: [],
+        inbound_webhook: {
+            enabled: false,
+            token: '',
+            format: 'generic',
+            name_field: '',
+            description_field: '',
+            severity_field: '',
+            fingerprint_field: '',
+            service_user_id: '',
+        },
+        has_inbound_webhook_token: false,
     };
 }
 
@@ -2,7 +2,7 @@
 // See LICENSE.txt for license information.
 
 import {TimelineEvent, TimelineEventType} from 'src/types/rhs';
-import {Checklist, isChecklist} from 'src/types/playbook';
+import {Checklist, ChecklistItem, CustomField, CustomFieldValues, isChecklist, ReminderEscalation, StatusWorkflow, WebhookSubscription} from 'src/types/playbook';
 
 export interface PlaybookRun {
     id: string;
@@ -19,16 +19,27 @@ export interface PlaybookRun {
     active_stage_title: string;
     post_id: string;
     playbook_id: string;
+    playbook_revision: number;
     checklists: Checklist[];
     status_posts: StatusPost[];
     current_status: PlaybookRunStatus;
     reminder_post_id: string;
+    reminder_count: number;
+    reminder_escalation: ReminderEscalation;
     broadcast_channel_id: string;
     timeline_events: TimelineEvent[];
     retrospective: string;
     retrospective_published_at: number;
     retrospective_was_canceled: boolean;
     retrospective_reminder_interval_seconds: number;
+    severity: string;
+    severity_levels: string[];
+    status_workflow: StatusWorkflow;
+    custom_fields: CustomField[];
+    custom_field_values: CustomFieldValues;
+    webhook_subscriptions: WebhookSubscription[];
+    alert_fingerprint: string;
+    version: number;
 }
 
 export interface StatusPost {
@@ -132,9 +143,18 @@ export function playbookRunCurrentStatus(playbookRun: PlaybookRun): PlaybookRunS
     return playbookRun.current_status;
 }
 
-export function playbookRunIsActive(playbookRun: PlaybookRun): boolean {
+export function playbookRunIsClosed(playbookRun: PlaybookRun): boolean {
     const currentStatus = playbookRunCurrentStatus(playbookRun);
-    return currentStatus !== PlaybookRunStatus.Archived;
+    const states = playbookRun.status_workflow?.states ?? [];
+    if (states.length === 0) {
+        return currentStatus === PlaybookRunStatus.Archived;
+    }
+
+    return Boolean(states.find((state) => state.name === currentStatus)?.closed);
+}
+
+export function playbookRunIsActive(playbookRun: PlaybookRun): boolean {
+    return !playbookRunIsClosed(playbookRun);
 }
 
 export interface FetchPlaybookRunsParams {
@@ -149,12 +169,32 @@ export interface FetchPlaybookRunsParams {
     member_id?: string;
     disabled?: boolean;
     playbook_id?: string;
+    custom_field_id?: string;
+    custom_field_value?: string;
     active_gte?: number;
     active_lt?: number;
     started_gte?: number;
     started_lt?: number;
 }
 
+export interface Task extends ChecklistItem {
+    playbook_run_id: string;
+    playbook_run_name: string;
+    team_id: string;
+    channel_id: string;
+    channel_name: string;
+    checklist_num: number;
+    checklist_title: string;
+    item_num: number;
+}
+
+export interface FetchTasksParams {
+    team_id?: string;
+    state?: string[];
+    sort?: 'due_at' | 'assigned_at';
+    direction?: string;
+}
+
 export interface FetchPlaybookRunsParamsTime {
     active_gte?: number;
     active_lt?: number;
@@ -23,6 +23,9 @@ export enum TimelineEventType {
     UserJoinedLeft = 'user_joined_left',
     PublishedRetrospective = 'published_retrospective',
     CanceledRetrospective = 'canceled_retrospective',
+    RunUpdated = 'run_updated',
+    SeverityChanged = 'severity_changed',
+    ReminderEscalated = 'reminder_escalated',
 }
 
 export interface TimelineEvent {
@@ -25,6 +25,15 @@ export interface PlaybookStats {
     active_runs_per_day_times: number[][]
     active_participants_per_day: number[]
     active_participants_per_day_labels: string[]
+    checklist_item_skip_rates: ChecklistItemSkipRate[]
+}
+
+export interface ChecklistItemSkipRate {
+    checklist_title: string
+    item_title: string
+    runs: number
+    skipped: number
+    skip_rate: number
 }
 
 export const EmptyPlaybookStats = {
@@ -40,4 +49,5 @@ export const EmptyPlaybookStats = {
     active_runs_per_day_times: [[0, 0], [0, 0], [0, 0], [0, 0], [0, 0], [0, 0], [0, 0], [0, 0], [0, 0], [0, 0], [0, 0], [0, 0]],
     active_participants_per_day: [0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0],
     active_participants_per_day_labels: ['', '', '', '', '', '', '', '', '', '', '', '', '', ''],
+    checklist_item_skip_rates: [],
 };